@@ -0,0 +1,142 @@
+//go:build !native
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/control"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/window"
+)
+
+// Exit codes for command-line subcommands
+const (
+	exitOK         = 0
+	exitFailed     = 1
+	exitUsage      = 2
+	exitNotRunning = 3
+)
+
+// runCommand handles a subcommand by sending it to the running instance, returning the exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case control.CmdRun:
+		fs := flag.NewFlagSet(control.CmdRun, flag.ContinueOnError)
+		local := fs.Bool("local", false, "Run the action in this process if no instance is running")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: gopher-automate run [-local] <action>")
+			return exitUsage
+		}
+		code := send(control.Request{Command: control.CmdRun, Args: fs.Args()})
+		if code == exitNotRunning && *local {
+			return runLocal(fs.Arg(0))
+		}
+		return code
+
+	case control.CmdListActions:
+		return send(control.Request{Command: control.CmdListActions})
+
+	case control.CmdSwitchMenu:
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: gopher-automate switch-menu <device> <menu>")
+			return exitUsage
+		}
+		return send(control.Request{Command: control.CmdSwitchMenu, Args: args[1:]})
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q (expected run, list-actions or switch-menu)\n", args[0])
+		return exitUsage
+	}
+}
+
+// send delivers a request to the running instance and prints the result
+func send(req control.Request) int {
+	resp, err := control.Send(req)
+	if errors.Is(err, control.ErrNotRunning) {
+		fmt.Fprintln(os.Stderr, err)
+		return exitNotRunning
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+
+	for _, line := range resp.Output {
+		fmt.Println(line)
+	}
+	if !resp.OK {
+		fmt.Fprintln(os.Stderr, resp.Error)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runLocal executes an action in this process when no instance is running
+func runLocal(ref string) int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		return exitFailed
+	}
+
+	midiManager := midi.NewManager()
+	defer midiManager.Close()
+
+	eng := engine.New(cfg, midiManager, cfg.GetActionStore())
+	if err := eng.RunNamedAndWait(ref); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// claimInstance makes this process the single running instance. If another instance
+// already runs, it is asked to show its window and false is returned. Any other
+// failure only disables the command line, so startup continues without a server.
+func claimInstance(showExisting bool) (*control.Server, bool) {
+	server, err := control.Claim()
+	if errors.Is(err, control.ErrAlreadyRunning) {
+		if showExisting {
+			resp, err := control.Send(control.Request{Command: control.CmdShow})
+			if err == nil && !resp.OK {
+				err = errors.New(resp.Error)
+			}
+			if err != nil {
+				slog.Warn("Failed to show the running instance", "err", err)
+			}
+		}
+		return nil, false
+	}
+	if err != nil {
+		slog.Warn("Command-line control unavailable", "err", err)
+	}
+	return server, true
+}
+
+// serveControl answers command-line requests until the returned stop function is called
+func serveControl(server *control.Server, handler control.Handler) func() {
+	if server == nil {
+		return func() {}
+	}
+	server.Serve(handler)
+	return func() { _ = server.Close() }
+}
+
+// windowControl answers control requests for the desktop app, which can also show its window
+type windowControl struct {
+	*engine.Engine
+	mainWindow *window.MainWindow
+}
+
+// ShowWindow brings the main window to the front on the UI thread
+func (c windowControl) ShowWindow() {
+	fyne.Do(c.mainWindow.Show)
+}
@@ -0,0 +1,58 @@
+//go:build !native
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/control"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/httpapi"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// portWatchInterval is how often the MIDI port list is polled for hot-plug changes
+const portWatchInterval = 2 * time.Second
+
+// runHeadless drives the configured devices without any UI until SIGINT/SIGTERM
+func runHeadless(cfg *config.Config, midiManager midi.Ports, server *control.Server) {
+	eng := engine.New(cfg, midiManager, cfg.GetActionStore())
+	if config.DeviceInitInterrupted() {
+		// Without a window to retry from, clear the mark so the next start tries again
+		// once the config is fixed
+		slog.Warn("Last session stopped during device initialization, starting in safe mode without devices; fix the device settings in the config file and restart")
+		if err := config.EndDeviceInit(); err != nil {
+			slog.Warn("Failed to clear device initialization mark", "err", err)
+		}
+	} else {
+		eng.InitializeDevicesAtStartup()
+	}
+
+	// Track devices being plugged in and removed, for their connect hooks
+	stopPortWatch := midiManager.WatchPorts(portWatchInterval, eng.PortsChanged)
+	defer stopPortWatch()
+
+	stopControl := serveControl(server, eng)
+	defer stopControl()
+
+	api := httpapi.New(eng)
+	if err := api.Apply(cfg.HTTPAPI); err != nil {
+		slog.Error("HTTP API unavailable", "err", err)
+	}
+	defer api.Stop()
+
+	slog.Info("Running headless, press Ctrl+C to stop", "devices", len(cfg.Devices))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	sig := <-signals
+	slog.Info("Shutting down", "signal", sig)
+
+	// Stop listeners and clear pads before the MIDI manager closes its ports
+	eng.Shutdown()
+}
@@ -14,6 +14,7 @@ const (
 	ActionTypeShellCommand ActionType = "shell"
 	ActionTypeSleep        ActionType = "sleep"
 	ActionTypeMidi         ActionType = "midi"
+	ActionTypeWaitForMidi  ActionType = "wait_midi" // Pauses until a matching MIDI message arrives
 )
 
 // Action represents an executable action
@@ -22,9 +23,40 @@ type Action struct {
 	Name              string     `json:"name"`
 	Type              ActionType `json:"type"`
 	Code              string     `json:"code"`
-	ParentGroupID     string     `json:"parent_group_id"`     // Empty if root-level
-	Order             int        `json:"order"`               // For sorting within parent
-	WaitForCompletion bool       `json:"wait_for_completion"` // Block next action until this one finishes
+	ParentGroupID     string     `json:"parent_group_id"`            // Empty if root-level
+	Order             int        `json:"order"`                      // For sorting within parent
+	WaitForCompletion bool       `json:"wait_for_completion"`        // Block next action until this one finishes
+	Favorite          bool       `json:"favorite,omitempty"`         // Listed in the tray's Run Action menu
+	ColorTag          string     `json:"color_tag,omitempty"`        // One of ColorTags, shown as a dot in the action list
+	AllowDangerous    bool       `json:"allow_dangerous,omitempty"`  // Runs from pads even if the code matches a danger pattern
+	CooldownSeconds   int        `json:"cooldown_seconds,omitempty"` // Triggers within this long of the last run are skipped; 0 for none
+
+	// RequireConfirmation makes pads run the action only when pressed a second time
+	// within a few seconds; the first press arms the pad
+	RequireConfirmation bool `json:"require_confirmation,omitempty"`
+
+	// Args come from what triggered this run (a pad's ActionArgs) and are never saved
+	Args string `json:"-"`
+	// Trigger is what the run is for, passed like Args; empty for most runs
+	Trigger Trigger `json:"-"`
+}
+
+// Trigger names the device and menu a run is for, e.g. a device's menu hook
+type Trigger struct {
+	Hook   string // "enter" or "exit", or "startup" or "shutdown" for the app's own actions
+	Device string // Device name
+	Menu   string // Menu name
+}
+
+// env lists the trigger's environment variables, see HookEnv
+func (t Trigger) env() []string {
+	var env []string
+	for _, v := range []struct{ name, value string }{{HookEnv, t.Hook}, {DeviceEnv, t.Device}, {MenuEnv, t.Menu}} {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+	return env
 }
 
 // ActionGroup is a named folder containing actions and other groups
@@ -33,8 +65,17 @@ type ActionGroup struct {
 	Name          string `json:"name"`
 	ParentGroupID string `json:"parent_group_id"` // Allows nested groups, empty if root-level
 	Order         int    `json:"order"`           // For sorting within parent
+	Favorite      bool   `json:"favorite,omitempty"`
+	ColorTag      string `json:"color_tag,omitempty"` // One of ColorTags
+
+	// Runs of the group stop after this long, counted from its first action, and skip
+	// what is left; 0 for no limit. Nested groups stay within their ancestors' budgets.
+	TimeBudgetSeconds int `json:"time_budget_seconds,omitempty"`
 }
 
+// ColorTags are the colors actions and groups can be tagged with, in display order
+var ColorTags = []string{"red", "orange", "yellow", "green", "blue", "purple", "gray"}
+
 // NewAction creates a new action with a generated ID
 func NewAction(name string, actionType ActionType) *Action {
 	return &Action{
@@ -56,6 +97,9 @@ func NewActionGroup(name string) *ActionGroup {
 type ActionStore struct {
 	Actions []Action
 	Groups  []ActionGroup
+
+	dirty    bool   // Edited since loaded or last marked saved
+	onChange func() // Set by OnChange
 }
 
 // NewActionStore creates an empty action store
@@ -66,17 +110,58 @@ func NewActionStore() *ActionStore {
 	}
 }
 
+// OnChange sets a function called after every edit, and when the store is marked saved
+func (s *ActionStore) OnChange(fn func()) {
+	s.onChange = fn
+}
+
+// Dirty reports whether the store was edited since it was loaded or last marked saved
+func (s *ActionStore) Dirty() bool {
+	return s.dirty
+}
+
+// MarkSaved records that the store matches what is on disk
+func (s *ActionStore) MarkSaved() {
+	s.dirty = false
+	if s.onChange != nil {
+		s.onChange()
+	}
+}
+
+// Replace swaps in another set of actions and groups, e.g. reloaded from disk, as saved
+func (s *ActionStore) Replace(actions []Action, groups []ActionGroup) {
+	s.Actions = actions
+	s.Groups = groups
+	if s.Actions == nil {
+		s.Actions = []Action{}
+	}
+	if s.Groups == nil {
+		s.Groups = []ActionGroup{}
+	}
+	s.MarkSaved()
+}
+
+// changed records an edit
+func (s *ActionStore) changed() {
+	s.dirty = true
+	if s.onChange != nil {
+		s.onChange()
+	}
+}
+
 // AddAction adds an action to the store
 func (s *ActionStore) AddAction(action *Action) {
 	// Set order to be last in its parent
 	action.Order = s.getNextOrder(action.ParentGroupID)
 	s.Actions = append(s.Actions, *action)
+	s.changed()
 }
 
 // AddGroup adds a group to the store
 func (s *ActionStore) AddGroup(group *ActionGroup) {
 	group.Order = s.getNextOrder(group.ParentGroupID)
 	s.Groups = append(s.Groups, *group)
+	s.changed()
 }
 
 // getNextOrder returns the next order value for items in a parent
@@ -115,22 +200,28 @@ func (s *ActionStore) GetGroup(id string) *ActionGroup {
 	return nil
 }
 
-// UpdateAction updates an existing action
+// UpdateAction updates an existing action. Writing back an unchanged copy isn't an edit.
 func (s *ActionStore) UpdateAction(action *Action) bool {
 	for i := range s.Actions {
 		if s.Actions[i].ID == action.ID {
-			s.Actions[i] = *action
+			if s.Actions[i] != *action {
+				s.Actions[i] = *action
+				s.changed()
+			}
 			return true
 		}
 	}
 	return false
 }
 
-// UpdateGroup updates an existing group
+// UpdateGroup updates an existing group. Writing back an unchanged copy isn't an edit.
 func (s *ActionStore) UpdateGroup(group *ActionGroup) bool {
 	for i := range s.Groups {
 		if s.Groups[i].ID == group.ID {
-			s.Groups[i] = *group
+			if s.Groups[i] != *group {
+				s.Groups[i] = *group
+				s.changed()
+			}
 			return true
 		}
 	}
@@ -142,6 +233,7 @@ func (s *ActionStore) RemoveAction(id string) bool {
 	for i := range s.Actions {
 		if s.Actions[i].ID == id {
 			s.Actions = append(s.Actions[:i], s.Actions[i+1:]...)
+			s.changed()
 			return true
 		}
 	}
@@ -157,12 +249,29 @@ func (s *ActionStore) RemoveGroup(id string) bool {
 	for i := range s.Groups {
 		if s.Groups[i].ID == id {
 			s.Groups = append(s.Groups[:i], s.Groups[i+1:]...)
+			s.changed()
 			return true
 		}
 	}
 	return false
 }
 
+// GroupContents returns the IDs of a group, the groups nested in it and every action inside them
+func (s *ActionStore) GroupContents(id string) []string {
+	ids := []string{id}
+	for _, a := range s.Actions {
+		if a.ParentGroupID == id {
+			ids = append(ids, a.ID)
+		}
+	}
+	for _, g := range s.Groups {
+		if g.ParentGroupID == id {
+			ids = append(ids, s.GroupContents(g.ID)...)
+		}
+	}
+	return ids
+}
+
 // removeChildrenOfGroup removes all actions and groups that are children of the given group
 func (s *ActionStore) removeChildrenOfGroup(parentID string) {
 	// Find child groups first
@@ -215,6 +324,7 @@ func (s *ActionStore) MoveAction(actionID, newParentID string, newOrder int) boo
 	s.shiftOrdersAfterRemove(oldParentID, oldOrder)
 	s.shiftOrdersAfterInsert(newParentID, newOrder, actionID)
 
+	s.changed()
 	return s.UpdateAction(action)
 }
 
@@ -241,6 +351,7 @@ func (s *ActionStore) MoveGroup(groupID, newParentID string, newOrder int) bool
 	s.shiftOrdersAfterRemove(oldParentID, oldOrder)
 	s.shiftOrdersAfterInsert(newParentID, newOrder, groupID)
 
+	s.changed()
 	return s.UpdateGroup(group)
 }
 
@@ -256,6 +367,7 @@ func (s *ActionStore) MoveActionUp(actionID string) bool {
 		if s.Actions[i].ParentGroupID == action.ParentGroupID && s.Actions[i].Order == action.Order-1 {
 			s.Actions[i].Order++
 			action.Order--
+			s.changed()
 			return s.UpdateAction(action)
 		}
 	}
@@ -263,6 +375,7 @@ func (s *ActionStore) MoveActionUp(actionID string) bool {
 		if s.Groups[i].ParentGroupID == action.ParentGroupID && s.Groups[i].Order == action.Order-1 {
 			s.Groups[i].Order++
 			action.Order--
+			s.changed()
 			return s.UpdateAction(action)
 		}
 	}
@@ -286,6 +399,7 @@ func (s *ActionStore) MoveActionDown(actionID string) bool {
 		if s.Actions[i].ParentGroupID == action.ParentGroupID && s.Actions[i].Order == action.Order+1 {
 			s.Actions[i].Order--
 			action.Order++
+			s.changed()
 			return s.UpdateAction(action)
 		}
 	}
@@ -293,6 +407,7 @@ func (s *ActionStore) MoveActionDown(actionID string) bool {
 		if s.Groups[i].ParentGroupID == action.ParentGroupID && s.Groups[i].Order == action.Order+1 {
 			s.Groups[i].Order--
 			action.Order++
+			s.changed()
 			return s.UpdateAction(action)
 		}
 	}
@@ -311,6 +426,7 @@ func (s *ActionStore) MoveGroupUp(groupID string) bool {
 		if s.Actions[i].ParentGroupID == group.ParentGroupID && s.Actions[i].Order == group.Order-1 {
 			s.Actions[i].Order++
 			group.Order--
+			s.changed()
 			return s.UpdateGroup(group)
 		}
 	}
@@ -318,6 +434,7 @@ func (s *ActionStore) MoveGroupUp(groupID string) bool {
 		if s.Groups[i].ParentGroupID == group.ParentGroupID && s.Groups[i].Order == group.Order-1 {
 			s.Groups[i].Order++
 			group.Order--
+			s.changed()
 			return s.UpdateGroup(group)
 		}
 	}
@@ -341,6 +458,7 @@ func (s *ActionStore) MoveGroupDown(groupID string) bool {
 		if s.Actions[i].ParentGroupID == group.ParentGroupID && s.Actions[i].Order == group.Order+1 {
 			s.Actions[i].Order--
 			group.Order++
+			s.changed()
 			return s.UpdateGroup(group)
 		}
 	}
@@ -348,6 +466,7 @@ func (s *ActionStore) MoveGroupDown(groupID string) bool {
 		if s.Groups[i].ParentGroupID == group.ParentGroupID && s.Groups[i].Order == group.Order+1 {
 			s.Groups[i].Order--
 			group.Order++
+			s.changed()
 			return s.UpdateGroup(group)
 		}
 	}
@@ -479,3 +598,22 @@ func (s *ActionStore) GetSortedTree(parentID string, depth int) []TreeItem {
 func (s *ActionStore) GetFlatList() []TreeItem {
 	return s.GetSortedTree("", 0)
 }
+
+// GetFavorites returns the favorite actions and groups in sorted tree order
+func (s *ActionStore) GetFavorites() []TreeItem {
+	var favorites []TreeItem
+	for _, item := range s.GetFlatList() {
+		if (item.IsGroup && item.Group.Favorite) || (!item.IsGroup && item.Action.Favorite) {
+			favorites = append(favorites, item)
+		}
+	}
+	return favorites
+}
+
+// MoveToEnd moves an action or group to the end of a parent group, empty for root
+func (s *ActionStore) MoveToEnd(id, parentID string) bool {
+	if s.GetAction(id) != nil {
+		return s.MoveAction(id, parentID, s.getNextOrder(parentID))
+	}
+	return s.MoveGroup(id, parentID, s.getNextOrder(parentID))
+}
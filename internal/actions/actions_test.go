@@ -0,0 +1,26 @@
+package actions
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGroupContents(t *testing.T) {
+	s := NewActionStore()
+	s.Groups = []ActionGroup{{ID: "outer"}, {ID: "inner", ParentGroupID: "outer"}, {ID: "other"}}
+	s.Actions = []Action{
+		{ID: "a1", ParentGroupID: "outer"},
+		{ID: "a2", ParentGroupID: "inner"},
+		{ID: "a3", ParentGroupID: "other"},
+		{ID: "a4"},
+	}
+
+	got := s.GroupContents("outer")
+	slices.Sort(got)
+	if want := []string{"a1", "a2", "inner", "outer"}; !slices.Equal(got, want) {
+		t.Errorf("GroupContents(outer) = %v, want %v", got, want)
+	}
+	if got := s.GroupContents("empty"); !slices.Equal(got, []string{"empty"}) {
+		t.Errorf("GroupContents(empty) = %v, want just the group", got)
+	}
+}
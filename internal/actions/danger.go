@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultDangerPatterns are the regular expressions shell actions are checked against
+// when the config doesn't list its own
+var DefaultDangerPatterns = []string{
+	`\brm\s+(-\S+\s+)*-[a-zA-Z]*[rR][a-zA-Z]*\s+(-\S+\s+)*(/|~/?|\$HOME/?)\*?(\s|[;&|)]|$)`, // rm -rf / or ~
+	`(?i)\bdiskutil\s+(erase\w*|zeroDisk|randomDisk|secureErase|partitionDisk)\b`,
+	`\b(shutdown|reboot|halt|poweroff)\b`,
+	`>\s*/dev/(sd|hd|nvme|disk|mmcblk)\w*`,
+	`\bdd\b.*\bof=/dev/(sd|hd|nvme|disk|rdisk|mmcblk)`,
+	`\bmkfs(\.\w+)?\b`,
+	`(?i)\b(Format-Volume|Clear-Disk|Stop-Computer|Restart-Computer)\b`,
+}
+
+// DangerMatches returns the patterns a shell action's code matches, ignoring comments
+// and quoted strings so that e.g. echo "shutdown" isn't flagged. Other action types and
+// patterns that aren't valid regular expressions never match.
+func DangerMatches(action *Action, patterns []string) []string {
+	if action == nil || action.Type != ActionTypeShellCommand {
+		return nil
+	}
+	code := stripCommentsAndStrings(action.Code)
+
+	var matches []string
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err == nil && re.MatchString(code) {
+			matches = append(matches, pattern)
+		}
+	}
+	return matches
+}
+
+// ValidateDangerPatterns checks that every pattern is a valid regular expression
+func ValidateDangerPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// stripCommentsAndStrings blanks out # comments and the contents of quoted strings,
+// keeping line breaks so commands on separate lines stay apart
+func stripCommentsAndStrings(code string) string {
+	var b strings.Builder
+	var quote rune // The open quote, 0 outside strings
+	inComment, escaped := false, false
+	prev := '\n'
+
+	for _, r := range code {
+		switch {
+		case r == '\n':
+			inComment, escaped = false, false
+			if quote == 0 {
+				b.WriteRune(r)
+			}
+		case inComment:
+		case escaped:
+			escaped = false
+			if quote == 0 {
+				b.WriteRune(r)
+			}
+		case r == '\\' && quote != '\'':
+			escaped = true
+			if quote == 0 {
+				b.WriteRune(r)
+			}
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				b.WriteRune(' ')
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '#' && (prev == '\n' || prev == ' ' || prev == '\t' || prev == ';'):
+			inComment = true
+		default:
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package actions
+
+import "testing"
+
+func TestDangerMatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		dangerous bool
+	}{
+		{"rm -rf /", "rm -rf /", true},
+		{"rm -rf home", "rm -rf ~", true},
+		{"rm -fr $HOME", "rm -fr $HOME/", true},
+		{"rm with options around", "rm -v -rf --no-preserve-root /", true},
+		{"rm -rf / then more", "rm -rf /; echo done", true},
+		{"rm -rf /*", "rm -rf /*", true},
+		{"diskutil", "diskutil eraseDisk JHFS+ Empty disk2", true},
+		{"shutdown", "sudo shutdown -h now", true},
+		{"reboot on a later line", "echo bye\nreboot", true},
+		{"redirect to disk", "cat image > /dev/sda", true},
+		{"dd", "dd if=image.iso of=/dev/disk4 bs=1m", true},
+		{"mkfs", "mkfs.ext4 /dev/sdb1", true},
+		{"PowerShell", "Stop-Computer -Force", true},
+
+		{"rm -rf of a folder", "rm -rf /tmp/build", false},
+		{"rm -rf relative", "rm -rf ./out", false},
+		{"rm without recursion", "rm -f /etc/thing", false},
+		{"comment", "# shutdown the build server\necho hi", false},
+		{"comment after a command", "echo hi # then reboot", false},
+		{"double quoted", `echo "rm -rf /"`, false},
+		{"single quoted", `say 'time to shutdown'`, false},
+		{"escaped quote stays in the string", `echo "don\"t shutdown"`, false},
+		{"hash inside a word", "echo a#reboot", true}, // Not a comment to the shell either
+		{"redirect to a file", "echo hi > /dev/null", false},
+		{"word containing a pattern", "./shutdownhelper --dry-run", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := &Action{Type: ActionTypeShellCommand, Code: tt.code}
+			matches := DangerMatches(action, DefaultDangerPatterns)
+			if got := len(matches) > 0; got != tt.dangerous {
+				t.Errorf("DangerMatches(%q) = %v, want dangerous %v", tt.code, matches, tt.dangerous)
+			}
+		})
+	}
+}
+
+func TestDangerMatchesOnlyShell(t *testing.T) {
+	for _, typ := range []ActionType{ActionTypeAppleScript, ActionTypeSleep, ActionTypeMidi} {
+		if matches := DangerMatches(&Action{Type: typ, Code: "shutdown"}, DefaultDangerPatterns); matches != nil {
+			t.Errorf("%s action matched %v", typ, matches)
+		}
+	}
+	if matches := DangerMatches(nil, DefaultDangerPatterns); matches != nil {
+		t.Errorf("nil action matched %v", matches)
+	}
+}
+
+func TestDangerPatternsCustom(t *testing.T) {
+	action := &Action{Type: ActionTypeShellCommand, Code: "git push --force"}
+	patterns := []string{`(`, `\bgit\s+push\s+--force\b`} // The invalid one never matches
+	if matches := DangerMatches(action, patterns); len(matches) != 1 || matches[0] != patterns[1] {
+		t.Errorf("matches = %v, want the git pattern", matches)
+	}
+	if err := ValidateDangerPatterns(patterns); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+	if err := ValidateDangerPatterns(DefaultDangerPatterns); err != nil {
+		t.Errorf("default patterns: %v", err)
+	}
+}
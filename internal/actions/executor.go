@@ -1,8 +1,13 @@
 package actions
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
 
+	"github.com/PixPMusic/gopher-automate/internal/metrics"
 	"github.com/PixPMusic/gopher-automate/internal/midi"
 )
 
@@ -11,21 +16,49 @@ type Executor struct {
 	handlers map[ActionType]ActionHandler
 }
 
-// NewExecutor creates a new action executor
-func NewExecutor(midiManager *midi.Manager) *Executor {
-	return &Executor{
-		handlers: map[ActionType]ActionHandler{
-			ActionTypeAppleScript:  &AppleScriptHandler{},
-			ActionTypeShellCommand: &ShellHandler{},
-			ActionTypeSleep:        &SleepHandler{},
-			ActionTypeMidi:         NewMidiHandler(midiManager),
-		},
+// NewExecutor creates an executor with a handler for every registered action type
+func NewExecutor(midiManager midi.Ports) *Executor {
+	deps := Deps{MIDI: midiManager}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	handlers := make(map[ActionType]ActionHandler, len(registry))
+	for t, r := range registry {
+		handlers[t] = r.factory(deps)
 	}
+	return &Executor{handlers: handlers}
+}
+
+// Types returns the registered action types that can run on this platform, in
+// registration order
+func (e *Executor) Types() []ActionType {
+	var types []ActionType
+	for _, t := range HandlerTypes() {
+		if handler, ok := e.handlers[t]; ok && handler.IsSupported() {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Validate checks an action's code with the handler of its type
+func (e *Executor) Validate(t ActionType, code string) error {
+	handler, ok := e.handlers[t]
+	if !ok {
+		return fmt.Errorf("unknown action type: %s", t)
+	}
+	return handler.Validate(code)
 }
 
 // Execute runs an action based on its type
-// Returns output and error (error if type not supported on current platform)
-func (e *Executor) Execute(action *Action) (string, error) {
+// Returns output and error (error if type not supported on current platform).
+// A handler that panics fails the action instead of the app.
+func (e *Executor) Execute(action *Action) (output string, err error) {
+	return e.ExecuteContext(context.Background(), action)
+}
+
+// ExecuteContext is Execute for callers that want to stop the action or follow its
+// progress (see WithProgress). Handlers that don't support either ignore ctx.
+func (e *Executor) ExecuteContext(ctx context.Context, action *Action) (output string, err error) {
 	if action == nil {
 		return "", fmt.Errorf("action is nil")
 	}
@@ -35,6 +68,23 @@ func (e *Executor) Execute(action *Action) (string, error) {
 		return "", fmt.Errorf("unknown action type: %s", action.Type)
 	}
 
+	defer func(start time.Time) {
+		if r := recover(); r != nil {
+			slog.Error("Action handler panicked", "action", action.Name, "panic", r, "stack", string(debug.Stack()))
+			output, err = "", fmt.Errorf("%s handler panicked: %v", action.Type, r)
+		}
+		metrics.Record(metrics.ActionLatency, start, err)
+	}(time.Now())
+	if h, ok := handler.(envHandler); ok {
+		var env []string
+		if action.Args != "" {
+			env = []string{ArgsEnv + "=" + action.Args}
+		}
+		return h.ExecuteEnv(ctx, action.Code, append(env, action.Trigger.env()...))
+	}
+	if h, ok := handler.(contextHandler); ok {
+		return h.ExecuteContext(ctx, action.Code)
+	}
 	return handler.Execute(action.Code)
 }
 
@@ -0,0 +1,37 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+)
+
+// actionTypePanic is run by a handler that panics, the way a buggy contributed type would
+const actionTypePanic ActionType = "test_panic"
+
+type panicHandler struct{}
+
+func (panicHandler) Execute(string) (string, error) {
+	var m map[string]int
+	m["boom"]++ // Nil map write
+	return "", nil
+}
+func (panicHandler) Validate(string) error { return nil }
+func (panicHandler) IsSupported() bool     { return true }
+
+func init() {
+	RegisterHandler(actionTypePanic, HandlerMeta{Name: "Panic"}, func(Deps) ActionHandler { return panicHandler{} })
+}
+
+func TestExecuteRecoversFromPanic(t *testing.T) {
+	e := NewExecutor(nil)
+
+	output, err := e.Execute(&Action{Name: "Buggy", Type: actionTypePanic})
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Fatalf("Execute = %q, %v; want a panic error", output, err)
+	}
+
+	// The executor keeps working afterwards
+	if _, err := e.Execute(&Action{Name: "Nap", Type: ActionTypeSleep, Code: "0"}); err != nil {
+		t.Errorf("next action failed: %v", err)
+	}
+}
@@ -1,5 +1,10 @@
 package actions
 
+import (
+	"context"
+	"time"
+)
+
 // ActionHandler defines the interface for executing and validating actions
 type ActionHandler interface {
 	// Execute runs the code and returns output or error
@@ -11,3 +16,46 @@ type ActionHandler interface {
 	// IsSupported returns true if the handler can run on the current platform
 	IsSupported() bool
 }
+
+// ArgsEnv is the environment variable an action's trigger arguments are passed in
+const ArgsEnv = "GOPHER_AUTOMATE_ARGS"
+
+// Environment variables describing what a run is for, see Trigger
+const (
+	HookEnv   = "GOPHER_AUTOMATE_HOOK"   // "enter", "exit", "startup" or "shutdown"
+	DeviceEnv = "GOPHER_AUTOMATE_DEVICE" // Device name
+	MenuEnv   = "GOPHER_AUTOMATE_MENU"   // Name of the menu entered or left
+)
+
+// envHandler is implemented by handlers that run a process, which can be given extra
+// environment variables ("NAME=value") and is killed when the context ends
+type envHandler interface {
+	ExecuteEnv(ctx context.Context, code string, env []string) (string, error)
+}
+
+// contextHandler is implemented by handlers that can be stopped through the context
+// and report progress to a callback set with WithProgress
+type contextHandler interface {
+	ExecuteContext(ctx context.Context, code string) (string, error)
+}
+
+// Progress is reported by handlers that know how far along they are
+type Progress struct {
+	Fraction  float64       // 0 to 1
+	Remaining time.Duration // Estimated time left
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes handlers report their progress to report.
+// report is called on the goroutine running the action.
+func WithProgress(ctx context.Context, report func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// reportProgress passes progress to the callback set with WithProgress, if any
+func reportProgress(ctx context.Context, p Progress) {
+	if report, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		report(p)
+	}
+}
@@ -2,7 +2,9 @@ package actions
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -16,16 +18,28 @@ func (h *AppleScriptHandler) IsSupported() bool {
 }
 
 func (h *AppleScriptHandler) Execute(code string) (string, error) {
+	return h.ExecuteEnv(context.Background(), code, nil)
+}
+
+// ExecuteEnv runs the script with env added to the app's environment, where
+// "system attribute" reads it, killing it when ctx ends
+func (h *AppleScriptHandler) ExecuteEnv(ctx context.Context, code string, env []string) (string, error) {
 	if !h.IsSupported() {
 		return "", fmt.Errorf("AppleScript is only supported on macOS")
 	}
 
-	cmd := exec.Command("osascript", "-e", code)
+	cmd := exec.CommandContext(ctx, "osascript", "-e", code)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", ctx.Err() // Killed because the run was stopped or ran out of time
+	}
 	if err != nil {
 		errMsg := stderr.String()
 		if errMsg != "" {
@@ -2,6 +2,7 @@ package actions
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	internalmidi "github.com/PixPMusic/gopher-automate/internal/midi"
@@ -10,7 +11,7 @@ import (
 
 // MidiHandler handles MIDI message sending
 type MidiHandler struct {
-	midiManager *internalmidi.Manager
+	midiManager internalmidi.Ports
 }
 
 // MidiActionData structure for JSON storage in Code field
@@ -24,7 +25,7 @@ type MidiActionData struct {
 	SysEx      string `json:"sysex"`       // Hex string "F0 01 ... F7"
 }
 
-func NewMidiHandler(m *internalmidi.Manager) *MidiHandler {
+func NewMidiHandler(m internalmidi.Ports) *MidiHandler {
 	return &MidiHandler{midiManager: m}
 }
 
@@ -51,13 +52,13 @@ func (h *MidiHandler) Execute(code string) (string, error) {
 	if data.DeviceName == "" {
 		return "", fmt.Errorf("no device specified")
 	}
+	if err := data.checkRange(); err != nil {
+		return "", err
+	}
 
 	// Prepare message
 	var msg midi.Message
-	channel := uint8(data.Channel - 1) // 0-based
-	if channel > 15 {
-		channel = 0
-	}
+	channel := uint8(max(data.Channel, 1) - 1) // 0-based
 
 	switch data.MsgType {
 	case "note_on":
@@ -77,22 +78,8 @@ func (h *MidiHandler) Execute(code string) (string, error) {
 		return "", fmt.Errorf("unknown message type: %s", data.MsgType)
 	}
 
-	// Send message
-	// we need a SendTo equivalent. midi.Manager has GetOutPort.
-	outPort, err := h.midiManager.GetOutPort(data.DeviceName)
-	if err != nil {
-		return "", fmt.Errorf("failed to get port '%s': %v", data.DeviceName, err)
-	}
-	if outPort == nil {
-		return "", fmt.Errorf("port '%s' not found", data.DeviceName)
-	}
-
-	send, err := midi.SendTo(outPort)
-	if err != nil {
-		return "", fmt.Errorf("failed to create sender: %v", err)
-	}
-
-	if err := send(msg); err != nil {
+	// Send message, paced with the port's other output if it has a send limit
+	if err := h.midiManager.Send(data.DeviceName, msg); err != nil {
 		return "", fmt.Errorf("send failed: %v", err)
 	}
 
@@ -107,5 +94,33 @@ func (h *MidiHandler) Validate(code string) error {
 	if data.DeviceName == "" {
 		return fmt.Errorf("device required")
 	}
+	return data.checkRange()
+}
+
+// checkRange reports the values out of range among those the message type sends, so
+// hand-edited actions can't send malformed bytes. Channel 0 is taken as unset and means
+// channel 1.
+func (d *MidiActionData) checkRange() error {
+	if d.Channel < 0 || d.Channel > 16 {
+		return fmt.Errorf("channel %d is out of range 1-16", d.Channel)
+	}
+	switch d.MsgType {
+	case "note_on":
+		return errors.Join(checkMidiValue("note", d.Note), checkMidiValue("velocity", d.Velocity))
+	case "note_off":
+		return checkMidiValue("note", d.Note)
+	case "cc":
+		return errors.Join(checkMidiValue("controller", d.Note), checkMidiValue("value", d.Velocity))
+	case "pc":
+		return checkMidiValue("program", d.Program)
+	}
+	return nil
+}
+
+// checkMidiValue checks a 7-bit data byte
+func checkMidiValue(name string, value int) error {
+	if value < 0 || value > 127 {
+		return fmt.Errorf("%s %d is out of range 0-127", name, value)
+	}
 	return nil
 }
@@ -0,0 +1,114 @@
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/midi/miditest"
+)
+
+func TestMidiHandlerExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []byte // bytes sent to "Synth", nil when nothing may be sent
+		err  string
+	}{
+		{"note on", `{"device_name": "Synth", "msg_type": "note_on", "channel": 1, "note": 60, "velocity": 100}`,
+			[]byte{0x90, 60, 100}, ""},
+		{"note on channel 16", `{"device_name": "Synth", "msg_type": "note_on", "channel": 16, "note": 0, "velocity": 127}`,
+			[]byte{0x9F, 0, 127}, ""},
+		{"unset channel is 1", `{"device_name": "Synth", "msg_type": "note_on", "note": 64, "velocity": 1}`,
+			[]byte{0x90, 64, 1}, ""},
+		{"note off", `{"device_name": "Synth", "msg_type": "note_off", "channel": 3, "note": 127}`,
+			[]byte{0x82, 127, 0}, ""},
+		{"control change", `{"device_name": "Synth", "msg_type": "cc", "channel": 2, "note": 7, "velocity": 90}`,
+			[]byte{0xB1, 7, 90}, ""},
+		{"program change", `{"device_name": "Synth", "msg_type": "pc", "channel": 10, "program": 5}`,
+			[]byte{0xC9, 5}, ""},
+		{"channel out of range", `{"device_name": "Synth", "msg_type": "note_on", "channel": 17, "note": 60}`,
+			nil, "channel 17 is out of range"},
+		{"note out of range", `{"device_name": "Synth", "msg_type": "note_on", "note": 128}`,
+			nil, "note"},
+		{"program out of range", `{"device_name": "Synth", "msg_type": "pc", "program": -1}`,
+			nil, "program"},
+		{"no device", `{"msg_type": "note_on", "note": 60}`, nil, "no device specified"},
+		{"unknown type", `{"device_name": "Synth", "msg_type": "aftertouch"}`, nil, "unknown message type"},
+		{"sysex", `{"device_name": "Synth", "msg_type": "sysex", "sysex": "F0 01 F7"}`, nil, "sysex"},
+		{"bad json", `{"device_name":`, nil, "invalid MIDI action data"},
+		{"unknown port", `{"device_name": "Gone", "msg_type": "note_on", "note": 60, "velocity": 1}`, nil, "send failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := miditest.NewManager(nil, []string{"Synth"})
+			_, err := NewMidiHandler(fake).Execute(tt.code)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			sent := fake.SentTo("Synth")
+			if tt.want == nil {
+				if len(sent) != 0 {
+					t.Fatalf("sent % X, want nothing", sent)
+				}
+				return
+			}
+			if len(sent) != 1 || !bytes.Equal(sent[0], tt.want) {
+				t.Fatalf("sent % X, want [% X]", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestMidiValidateBoundaries(t *testing.T) {
+	fields := []struct {
+		msgType, field string
+	}{
+		{"note_on", "note"}, {"note_on", "velocity"}, {"note_off", "note"},
+		{"cc", "note"}, {"cc", "velocity"}, {"pc", "program"},
+	}
+	for _, f := range fields {
+		for _, tt := range []struct {
+			value int
+			ok    bool
+		}{{0, true}, {127, true}, {128, false}, {-5, false}} {
+			code := fmt.Sprintf(`{"device_name": "Synth", "msg_type": %q, "channel": 1, %q: %d}`, f.msgType, f.field, tt.value)
+			if err := NewMidiHandler(nil).Validate(code); (err == nil) != tt.ok {
+				t.Errorf("%s %s %d: err = %v, want ok=%v", f.msgType, f.field, tt.value, err, tt.ok)
+			}
+		}
+	}
+
+	for _, tt := range []struct {
+		channel int
+		ok      bool
+	}{{0, true}, {1, true}, {16, true}, {17, false}, {-1, false}} {
+		code := fmt.Sprintf(`{"device_name": "Synth", "msg_type": "note_on", "channel": %d}`, tt.channel)
+		if err := NewMidiHandler(nil).Validate(code); (err == nil) != tt.ok {
+			t.Errorf("channel %d: err = %v, want ok=%v", tt.channel, err, tt.ok)
+		}
+	}
+
+	// Values the message type doesn't send aren't checked
+	if err := NewMidiHandler(nil).Validate(`{"device_name": "Synth", "msg_type": "pc", "note": 200, "program": 1}`); err != nil {
+		t.Errorf("program change with a stray note: %v", err)
+	}
+}
+
+func TestWaitMidiValidateBoundaries(t *testing.T) {
+	for _, tt := range []struct {
+		min, max int
+		ok       bool
+	}{{0, 127, true}, {0, 0, true}, {-5, 0, false}, {0, 128, false}} {
+		code := fmt.Sprintf(`{"device_name": "Keys", "msg_type": "cc", "channel": 1, "min_value": %d, "max_value": %d}`, tt.min, tt.max)
+		if err := NewWaitMidiHandler(nil).Validate(code); (err == nil) != tt.ok {
+			t.Errorf("range %d-%d: err = %v, want ok=%v", tt.min, tt.max, err, tt.ok)
+		}
+	}
+}
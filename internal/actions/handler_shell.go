@@ -2,12 +2,25 @@ package actions
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 )
 
+// validateTimeout bounds a syntax check; PowerShell can take seconds to start
+const validateTimeout = 15 * time.Second
+
+// powerShellParseScript reads a script from stdin and prints its parse errors, one per line
+const powerShellParseScript = `$errors = $null
+[void][System.Management.Automation.Language.Parser]::ParseInput([Console]::In.ReadToEnd(), [ref]$null, [ref]$errors)
+foreach ($e in $errors) { "line $($e.Extent.StartLineNumber): $($e.Message)" }
+if ($errors) { exit 1 }`
+
 // ShellHandler handles Shell Command execution logic
 type ShellHandler struct{}
 
@@ -17,12 +30,22 @@ func (h *ShellHandler) IsSupported() bool {
 }
 
 func (h *ShellHandler) Execute(code string) (string, error) {
+	return h.ExecuteEnv(context.Background(), code, nil)
+}
+
+// ExecuteEnv runs the command with env added to the app's environment, killing it
+// when ctx ends
+func (h *ShellHandler) ExecuteEnv(ctx context.Context, code string, env []string) (string, error) {
 	var cmd *exec.Cmd
 
 	switch runtime.GOOS {
 	case "windows":
 		// Use PowerShell on Windows
-		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", code)
+		shell := powerShell()
+		if shell == "" {
+			return "", fmt.Errorf("PowerShell not found")
+		}
+		cmd = exec.CommandContext(ctx, shell, "-NoProfile", "-NonInteractive", "-Command", code)
 	case "darwin", "linux":
 		// Use default shell (typically bash or zsh) on Unix-like systems
 		shell := "/bin/bash"
@@ -32,16 +55,23 @@ func (h *ShellHandler) Execute(code string) (string, error) {
 				shell = "/bin/zsh"
 			}
 		}
-		cmd = exec.Command(shell, "-c", code)
+		cmd = exec.CommandContext(ctx, shell, "-c", code)
 	default:
 		return "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
 
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", ctx.Err() // Killed because the run was stopped or ran out of time
+	}
 	if err != nil {
 		errMsg := stderr.String()
 		if errMsg != "" {
@@ -58,29 +88,46 @@ func (h *ShellHandler) Validate(code string) error {
 		return fmt.Errorf("empty command")
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+
 	var cmd *exec.Cmd
 
 	switch runtime.GOOS {
 	case "windows":
-		// PowerShell syntax check using -NoExecute (parse only)
-		// This doesn't actually exist in PowerShell, so we'll just do basic checks
 		if strings.Contains(code, "\x00") {
 			return fmt.Errorf("command contains null bytes")
 		}
-		return nil
+		shell := powerShell()
+		if shell == "" {
+			return nil // Nothing to parse with, so don't block saving or running
+		}
+		// Parse with PowerShell's own parser; the code goes through stdin to avoid quoting it
+		cmd = exec.CommandContext(ctx, shell, "-NoProfile", "-NonInteractive", "-Command", powerShellParseScript)
+		cmd.Stdin = strings.NewReader(code)
 	case "darwin", "linux":
 		// Use bash -n for syntax checking (parses but doesn't execute)
-		cmd = exec.Command("/bin/bash", "-n", "-c", code)
+		cmd = exec.CommandContext(ctx, "/bin/bash", "-n", "-c", code)
 	default:
 		return nil // Skip validation on unknown platforms
 	}
 
-	var stderr bytes.Buffer
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("validation timed out after %s", validateTimeout)
+	}
 	if err != nil {
 		errMsg := stderr.String()
+		if runtime.GOOS == "windows" {
+			errMsg = stdout.String() // Parse errors are printed, stderr only has PowerShell's own failures
+			if strings.TrimSpace(errMsg) == "" {
+				errMsg = stderr.String()
+			}
+		}
 		if errMsg != "" {
 			return fmt.Errorf("syntax error: %s", strings.TrimSpace(errMsg))
 		}
@@ -103,3 +150,14 @@ func (h *ShellHandler) GetShellName() string {
 		return "shell"
 	}
 }
+
+// powerShell returns the PowerShell executable to use, preferring Windows PowerShell
+// over PowerShell 7 (pwsh), or "" if neither is installed
+func powerShell() string {
+	for _, name := range []string{"powershell", "pwsh"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
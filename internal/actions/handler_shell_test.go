@@ -0,0 +1,33 @@
+//go:build linux || darwin
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShellExecuteEnvStopsWithContext(t *testing.T) {
+	h := &ShellHandler{}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := h.ExecuteEnv(ctx, "sleep 5", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("command ran %s past its deadline", elapsed)
+	}
+}
+
+func TestShellExecuteEnv(t *testing.T) {
+	h := &ShellHandler{}
+	output, err := h.ExecuteEnv(context.Background(), `printf %s "$`+ArgsEnv+`"`, []string{ArgsEnv + "=hello"})
+	if err != nil || output != "hello" {
+		t.Errorf("ExecuteEnv = %q, %v; want hello", output, err)
+	}
+}
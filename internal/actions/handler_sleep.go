@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -13,13 +14,38 @@ func (h *SleepHandler) IsSupported() bool {
 	return true
 }
 
+// sleepTick is how often a sleep reports its progress
+const sleepTick = 250 * time.Millisecond
+
 func (h *SleepHandler) Execute(code string) (string, error) {
+	return h.ExecuteContext(context.Background(), code)
+}
+
+// ExecuteContext sleeps in ticks, reporting the time left after each, until the
+// duration is up or ctx is cancelled
+func (h *SleepHandler) ExecuteContext(ctx context.Context, code string) (string, error) {
 	seconds, err := h.parseDuration(code)
 	if err != nil {
 		return "", err
 	}
 
-	time.Sleep(time.Duration(seconds * float64(time.Second)))
+	total := time.Duration(seconds * float64(time.Second))
+	end := time.Now().Add(total)
+	ticker := time.NewTicker(sleepTick)
+	defer ticker.Stop()
+	for {
+		remaining := time.Until(end)
+		if remaining <= 0 {
+			break
+		}
+		reportProgress(ctx, Progress{Fraction: 1 - float64(remaining)/float64(total), Remaining: remaining})
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("sleep stopped after %.2f of %.2f seconds: %w", (total - remaining).Seconds(), seconds, ctx.Err())
+		case <-ticker.C:
+		case <-time.After(remaining):
+		}
+	}
 	return fmt.Sprintf("Slept for %.2f seconds", seconds), nil
 }
 
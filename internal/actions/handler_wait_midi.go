@@ -0,0 +1,132 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	internalmidi "github.com/PixPMusic/gopher-automate/internal/midi"
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// WaitMidiHandler pauses until a matching MIDI message arrives
+type WaitMidiHandler struct {
+	midiManager internalmidi.Ports
+}
+
+// WaitMidiData is what a wait-for-MIDI action stores in its Code, as JSON. The message
+// fields say what to wait for: DeviceName is the input port, Note the note or CC number,
+// Program the program and SysEx the bytes the SysEx starts with.
+type WaitMidiData struct {
+	MidiActionData
+	MinValue       int     `json:"min_value"`       // Note on velocity or CC value range, inclusive
+	MaxValue       int     `json:"max_value"`       // 0 for no upper limit
+	TimeoutSeconds float64 `json:"timeout_seconds"` // 0 waits until the run is stopped
+}
+
+func NewWaitMidiHandler(m internalmidi.Ports) *WaitMidiHandler {
+	return &WaitMidiHandler{midiManager: m}
+}
+
+func (h *WaitMidiHandler) IsSupported() bool {
+	return true
+}
+
+func (h *WaitMidiHandler) Execute(code string) (string, error) {
+	return h.ExecuteContext(context.Background(), code)
+}
+
+// ExecuteContext waits for the message, returning its value (the SysEx bytes in hex) as output
+func (h *WaitMidiHandler) ExecuteContext(ctx context.Context, code string) (string, error) {
+	data, prefix, err := parseWaitMidi(code)
+	if err != nil {
+		return "", err
+	}
+	if data.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(data.TimeoutSeconds*float64(time.Second)))
+		defer cancel()
+	}
+
+	msg, err := h.midiManager.WaitForMessage(ctx, data.DeviceName, func(msg midi.Message) bool {
+		_, ok := data.match(msg, prefix)
+		return ok
+	})
+	if errors.Is(err, context.DeadlineExceeded) && data.TimeoutSeconds > 0 {
+		return "", fmt.Errorf("no matching %s on %s within %gs", data.MsgType, data.DeviceName, data.TimeoutSeconds)
+	}
+	if err != nil {
+		return "", err
+	}
+	value, _ := data.match(msg, prefix)
+	return value, nil
+}
+
+// match reports whether msg is the message waited for, and its value
+func (d *WaitMidiData) match(msg midi.Message, prefix []byte) (string, bool) {
+	var channel, number, value uint8
+	var sysex []byte
+	switch {
+	case d.MsgType == "sysex" && msg.GetSysEx(&sysex):
+		return strings.ToUpper(hex.EncodeToString(sysex)), bytes.HasPrefix(sysex, prefix)
+	case d.MsgType == "note_on" && msg.GetNoteStart(&channel, &number, &value),
+		d.MsgType == "cc" && msg.GetControlChange(&channel, &number, &value):
+		if int(number) != d.Note || !d.valueInRange(int(value)) {
+			return "", false
+		}
+	case d.MsgType == "note_off" && msg.GetNoteEnd(&channel, &number):
+		if int(number) != d.Note {
+			return "", false
+		}
+	case d.MsgType == "pc" && msg.GetProgramChange(&channel, &number):
+		if int(number) != d.Program {
+			return "", false
+		}
+		value = number
+	default:
+		return "", false
+	}
+	return strconv.Itoa(int(value)), int(channel) == max(d.Channel, 1)-1
+}
+
+// valueInRange checks a velocity or CC value against MinValue and MaxValue
+func (d *WaitMidiData) valueInRange(v int) bool {
+	return v >= d.MinValue && (d.MaxValue == 0 || v <= d.MaxValue)
+}
+
+// parseWaitMidi reads a wait-for-MIDI action's code and its SysEx prefix
+func parseWaitMidi(code string) (WaitMidiData, []byte, error) {
+	var data WaitMidiData
+	if err := json.Unmarshal([]byte(code), &data); err != nil {
+		return data, nil, fmt.Errorf("invalid wait for MIDI data: %v", err)
+	}
+	if data.DeviceName == "" {
+		return data, nil, fmt.Errorf("no device specified")
+	}
+	if err := errors.Join(data.checkRange(), checkMidiValue("minimum value", data.MinValue),
+		checkMidiValue("maximum value", data.MaxValue)); err != nil {
+		return data, nil, err
+	}
+	var prefix []byte
+	if data.MsgType == "sysex" {
+		// Spaces and the F0/F7 framing are optional
+		digits := strings.Join(strings.Fields(data.SysEx), "")
+		digits = strings.TrimSuffix(strings.TrimPrefix(strings.ToUpper(digits), "F0"), "F7")
+		var err error
+		if prefix, err = hex.DecodeString(digits); err != nil {
+			return data, nil, fmt.Errorf("invalid SysEx bytes: %v", err)
+		}
+	}
+	return data, prefix, nil
+}
+
+func (h *WaitMidiHandler) Validate(code string) error {
+	_, _, err := parseWaitMidi(code)
+	return err
+}
@@ -0,0 +1,73 @@
+package actions
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MacroStep is a pad press captured while recording a macro
+type MacroStep struct {
+	ActionID string    // Action or group the pad runs
+	At       time.Time // When the press was handled
+}
+
+// Limits for the Sleep actions a macro gets between presses
+const (
+	macroDelayStep = 100 * time.Millisecond // Delays are rounded to this
+	macroMaxDelay  = 10 * time.Second       // Longer pauses are shortened to this
+)
+
+// AddMacro adds a group named name that runs the recorded presses in order. Each
+// press gets copies of its action (or of its group's actions), and with keepDelays
+// the pauses between presses become Sleep actions. Presses whose action no longer
+// exists are left out; nil is returned if none is left.
+func (s *ActionStore) AddMacro(name string, steps []MacroStep, keepDelays bool) *ActionGroup {
+	var recorded [][]Action
+	var delays []time.Duration // Before each entry of recorded
+	for i, step := range steps {
+		var copies []Action
+		if action := s.GetAction(step.ActionID); action != nil {
+			copies = []Action{*action}
+		} else if group := s.GetGroup(step.ActionID); group != nil {
+			for _, item := range s.GetSortedTree(group.ID, 0) {
+				if !item.IsGroup {
+					copies = append(copies, *item.Action)
+				}
+			}
+		}
+		if len(copies) == 0 {
+			continue
+		}
+		delay := time.Duration(0)
+		if i > 0 {
+			delay = min(steps[i].At.Sub(steps[i-1].At).Round(macroDelayStep), macroMaxDelay)
+		}
+		recorded = append(recorded, copies)
+		delays = append(delays, delay)
+	}
+	if len(recorded) == 0 {
+		return nil
+	}
+
+	group := NewActionGroup(name)
+	s.AddGroup(group)
+	for i, copies := range recorded {
+		if keepDelays && i > 0 && delays[i] > 0 {
+			seconds := strconv.FormatFloat(delays[i].Seconds(), 'f', -1, 64)
+			sleep := NewAction("Sleep "+seconds+"s", ActionTypeSleep)
+			sleep.Code = seconds
+			sleep.WaitForCompletion = true
+			sleep.ParentGroupID = group.ID
+			s.AddAction(sleep)
+		}
+		for _, action := range copies {
+			action.ID = uuid.New().String()
+			action.ParentGroupID = group.ID
+			action.Favorite = false
+			s.AddAction(&action)
+		}
+	}
+	return group
+}
@@ -0,0 +1,134 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// Action types beyond the built-in ones are added with RegisterHandler, from an init
+// function in a package linked into the binary:
+//
+//	func init() {
+//		actions.RegisterHandler("ticket", actions.HandlerMeta{
+//			Name: "Open ticket",
+//			Fields: []actions.Field{
+//				{Key: "queue", Label: "Queue", Kind: actions.FieldSelect, Options: []string{"ops", "dev"}},
+//				{Key: "title", Label: "Title", Kind: actions.FieldText},
+//			},
+//		}, func(actions.Deps) actions.ActionHandler { return &ticketHandler{} })
+//	}
+//
+// The Actions tab edits such types with a form built from Fields and stores the values
+// in the action's code as a JSON object, which DecodeFields reads back.
+//
+// RegisterHandler, HandlerMeta, Field, Deps, ActionHandler and the code format stay
+// compatible within a major version. Fields may be added to the structs, so build
+// them with keyed literals; the optional handler interfaces (contexts, environment,
+// progress) are internal and may change.
+
+// FieldKind is the input a Field is edited with
+type FieldKind string
+
+const (
+	FieldText   FieldKind = "text"
+	FieldNumber FieldKind = "number" // Stored as its decimal text
+	FieldSelect FieldKind = "select" // One of Options
+)
+
+// Field is one input of the form the Actions tab shows for a registered type
+type Field struct {
+	Key     string // Name in the stored JSON object
+	Label   string
+	Kind    FieldKind
+	Options []string // For FieldSelect
+	Default string   // Value of new actions
+}
+
+// HandlerMeta describes an action type to the UI
+type HandlerMeta struct {
+	Name string // Shown in the type selector; the built-in types are translated instead
+
+	// Fields make up the type's editor. The built-in types have editors of their own
+	// and leave this empty.
+	Fields []Field
+}
+
+// Deps is what the app hands handler factories
+type Deps struct {
+	MIDI midi.Ports
+}
+
+// registration is a registered action type
+type registration struct {
+	meta    HandlerMeta
+	factory func(Deps) ActionHandler
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[ActionType]registration{}
+	registered []ActionType // In registration order, which is the type selector's order
+)
+
+// The built-in types, in the order the type selector lists them
+func init() {
+	RegisterHandler(ActionTypeAppleScript, HandlerMeta{Name: "AppleScript"}, func(Deps) ActionHandler { return &AppleScriptHandler{} })
+	RegisterHandler(ActionTypeShellCommand, HandlerMeta{Name: "Shell"}, func(Deps) ActionHandler { return &ShellHandler{} })
+	RegisterHandler(ActionTypeSleep, HandlerMeta{Name: "Sleep"}, func(Deps) ActionHandler { return &SleepHandler{} })
+	RegisterHandler(ActionTypeMidi, HandlerMeta{Name: "MIDI"}, func(d Deps) ActionHandler { return NewMidiHandler(d.MIDI) })
+	RegisterHandler(ActionTypeWaitForMidi, HandlerMeta{Name: "Wait for MIDI"}, func(d Deps) ActionHandler { return NewWaitMidiHandler(d.MIDI) })
+}
+
+// RegisterHandler adds an action type, run by the handlers factory returns. Executors
+// created before it is called don't know the type, so call it from an init function.
+// Registering a type twice panics.
+func RegisterHandler(t ActionType, meta HandlerMeta, factory func(Deps) ActionHandler) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[t]; ok {
+		panic(fmt.Sprintf("actions: handler for %q registered twice", t))
+	}
+	registry[t] = registration{meta: meta, factory: factory}
+	registered = append(registered, t)
+}
+
+// HandlerTypes returns every registered action type, in registration order
+func HandlerTypes() []ActionType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]ActionType{}, registered...)
+}
+
+// Meta returns what was registered about an action type
+func Meta(t ActionType) (HandlerMeta, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[t]
+	return r.meta, ok
+}
+
+// DecodeFields reads the field values a form-edited action stores in its code. Missing
+// fields get their default; code that isn't a JSON object is an error.
+func DecodeFields(code string, fields []Field) (map[string]string, error) {
+	values := map[string]string{}
+	if code != "" {
+		if err := json.Unmarshal([]byte(code), &values); err != nil {
+			return nil, fmt.Errorf("invalid field values: %w", err)
+		}
+	}
+	for _, f := range fields {
+		if _, ok := values[f.Key]; !ok {
+			values[f.Key] = f.Default
+		}
+	}
+	return values, nil
+}
+
+// EncodeFields is the code a form-edited action stores for values
+func EncodeFields(values map[string]string) string {
+	data, _ := json.Marshal(values) // A map of strings always encodes
+	return string(data)
+}
@@ -0,0 +1,116 @@
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// actionTypeCustom is the sample contributed type: it greets someone a number of times,
+// in a language picked from a list
+const actionTypeCustom ActionType = "test_custom"
+
+var customFields = []Field{
+	{Key: "name", Label: "Name", Kind: FieldText},
+	{Key: "times", Label: "Times", Kind: FieldNumber, Default: "1"},
+	{Key: "lang", Label: "Language", Kind: FieldSelect, Options: []string{"en", "de"}, Default: "en"},
+}
+
+type customHandler struct{}
+
+func (customHandler) Execute(code string) (string, error) {
+	values, err := DecodeFields(code, customFields)
+	if err != nil {
+		return "", err
+	}
+	var n int
+	if _, err := fmt.Sscan(values["times"], &n); err != nil {
+		return "", fmt.Errorf("times: %w", err)
+	}
+	greeting := map[string]string{"en": "Hello", "de": "Hallo"}[values["lang"]]
+	return strings.Repeat(greeting+" "+values["name"]+"\n", n), nil
+}
+func (customHandler) Validate(code string) error {
+	_, err := DecodeFields(code, customFields)
+	return err
+}
+func (customHandler) IsSupported() bool { return true }
+
+func init() {
+	RegisterHandler(actionTypeCustom, HandlerMeta{Name: "Greet", Fields: customFields},
+		func(Deps) ActionHandler { return customHandler{} })
+}
+
+func TestRegisteredHandlerRuns(t *testing.T) {
+	e := NewExecutor(nil)
+	code := EncodeFields(map[string]string{"name": "Ada", "times": "2", "lang": "de"})
+	output, err := e.Execute(&Action{Name: "Greet", Type: actionTypeCustom, Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hallo Ada\nHallo Ada\n"; output != want {
+		t.Errorf("output = %q, want %q", output, want)
+	}
+
+	if _, err := e.Execute(&Action{Name: "Broken", Type: actionTypeCustom, Code: "not json"}); err == nil {
+		t.Error("code that isn't a JSON object ran")
+	}
+}
+
+func TestHandlerTypes(t *testing.T) {
+	types := HandlerTypes()
+	builtIn := []ActionType{ActionTypeAppleScript, ActionTypeShellCommand, ActionTypeSleep, ActionTypeMidi, ActionTypeWaitForMidi}
+	if len(types) < len(builtIn) || !slices.Equal(types[:len(builtIn)], builtIn) {
+		t.Errorf("types = %v, want the built-in ones first in selector order", types)
+	}
+	if !slices.Contains(types, actionTypeCustom) {
+		t.Errorf("types = %v, without the registered %q", types, actionTypeCustom)
+	}
+
+	meta, ok := Meta(actionTypeCustom)
+	if !ok || meta.Name != "Greet" || len(meta.Fields) != len(customFields) {
+		t.Errorf("Meta = %+v, %v", meta, ok)
+	}
+	if meta, ok := Meta(ActionTypeShellCommand); !ok || len(meta.Fields) != 0 {
+		t.Errorf("shell Meta = %+v, %v; want a built-in type without fields", meta, ok)
+	}
+	if _, ok := Meta("nonexistent"); ok {
+		t.Error("Meta found an unregistered type")
+	}
+}
+
+func TestRegisterHandlerTwicePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a type twice didn't panic")
+		}
+	}()
+	RegisterHandler(actionTypeCustom, HandlerMeta{}, func(Deps) ActionHandler { return customHandler{} })
+}
+
+func TestDecodeFields(t *testing.T) {
+	values, err := DecodeFields(`{"name":"Ada","extra":"kept"}`, customFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"name": "Ada", "times": "1", "lang": "en", "extra": "kept"}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q, want %q", key, values[key], value)
+		}
+	}
+
+	if values, err := DecodeFields("", customFields); err != nil || values["times"] != "1" || values["name"] != "" {
+		t.Errorf("empty code = %v, %v; want the defaults", values, err)
+	}
+	if _, err := DecodeFields(`["a"]`, customFields); err == nil {
+		t.Error("a JSON array decoded")
+	}
+
+	// EncodeFields and DecodeFields round-trip
+	round, err := DecodeFields(EncodeFields(want), customFields)
+	if err != nil || len(round) != len(want) {
+		t.Errorf("round trip = %v, %v", round, err)
+	}
+}
@@ -1,11 +1,22 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
 	"github.com/google/uuid"
 )
 
@@ -13,9 +24,11 @@ import (
 type DeviceType string
 
 const (
-	DeviceTypeClassic  DeviceType = "classic"  // Launchpad S
-	DeviceTypeColorful DeviceType = "colorful" // Launchpad Mini Mk3
-	DeviceTypeGeneric  DeviceType = "generic"  // Generic MIDI for inter-app communication
+	DeviceTypeClassic   DeviceType = "classic"   // Launchpad S
+	DeviceTypeColorful  DeviceType = "colorful"  // Launchpad Mini Mk3
+	DeviceTypeGeneric   DeviceType = "generic"   // Generic MIDI for inter-app communication
+	DeviceTypeSimulated DeviceType = "simulated" // The on-screen Virtual Launchpad
+	DeviceTypePro       DeviceType = "pro"       // Launchpad Pro Mk3, with pads around the 9x9 grid
 )
 
 // PadColorConfig stores RGB colors for a pad (all values 0-127)
@@ -44,97 +57,135 @@ type PadColorConfig struct {
 	LinkButtonClassic  bool `json:"link_button_classic"`
 	LinkPressedClassic bool `json:"link_pressed_classic"`
 
+	// Initialized flags - when true, the classic color was set (by hand or derived once)
+	// and stays as it is, even all zeros; see LinkClassicDefaults
+	ClassicInitialized        bool `json:"classic_initialized,omitempty"`
+	ClassicPressedInitialized bool `json:"classic_pressed_initialized,omitempty"`
+
+	// Override flags - when true, the pad keeps its own color even if it is all zeros
+	// (off) instead of showing the layout default, see MenuLayout.EffectiveColor
+	OverrideStatic  bool `json:"override_static,omitempty"`
+	OverridePressed bool `json:"override_pressed,omitempty"`
+
 	// ActionID is the ID of the action to execute when this pad is pressed
 	ActionID string `json:"action_id,omitempty"`
-}
 
-// CalculateClassicColor converts full RGB to the classic device's approximation.
-// Returns the RGB values that would be displayed on a classic (red/green only) device.
-// This uses the same algorithm as the MIDI package for consistency.
-func CalculateClassicColor(r, g, b uint8) (uint8, uint8, uint8) {
-	// Blue leans toward green since that's closer on the spectrum
-	effectiveR := int(r) + int(b)/4
-	effectiveG := int(g) + (int(b)*3)/4
+	// ActionArgs is passed to the pad's action in the actions.ArgsEnv environment variable
+	ActionArgs string `json:"action_args,omitempty"`
 
-	if effectiveR > 127 {
-		effectiveR = 127
-	}
-	if effectiveG > 127 {
-		effectiveG = 127
-	}
+	// DebounceMs overrides Config.PadDebounceMs for this pad when set
+	DebounceMs int `json:"debounce_ms,omitempty"`
 
-	// Map to 4 intensity levels (0-3) and back to preview values
-	redLevel := colorTo4Level(uint8(effectiveR))
-	greenLevel := colorTo4Level(uint8(effectiveG))
+	// Thru plays a note on another MIDI device while this pad is held
+	Thru PadThruConfig `json:"thru,omitzero"`
 
-	// Convert back to 0-127 for preview (0->0, 1->42, 2->85, 3->127)
-	previewR := redLevel * 42
-	previewG := greenLevel * 42
-	if redLevel == 3 {
-		previewR = 127
-	}
-	if greenLevel == 3 {
-		previewG = 127
-	}
+	// Busy color shown while the pad's action runs, overriding Config.BusyFeedback when set
+	BusyR uint8 `json:"busy_r,omitempty"`
+	BusyG uint8 `json:"busy_g,omitempty"`
+	BusyB uint8 `json:"busy_b,omitempty"`
 
-	return previewR, previewG, 0 // Classic has no blue
+	// LightMode is how the pad shows its static color
+	LightMode LightMode `json:"light_mode,omitempty"`
+
+	// Symbol marks the pad in the menu editor's color codes instead of its hue code;
+	// nothing is sent to devices
+	Symbol string `json:"symbol,omitempty"`
 }
 
-// CalculateClassicLevel converts full RGB to 0-3 intensity levels for classic device.
-// Returns (redLevel, greenLevel) where each is 0-3.
-func CalculateClassicLevel(r, g, b uint8) (uint8, uint8) {
-	effectiveR := int(r) + int(b)/4
-	effectiveG := int(g) + (int(b)*3)/4
+// LightMode is how a pad shows its static color
+type LightMode string
 
-	if effectiveR > 127 {
-		effectiveR = 127
-	}
-	if effectiveG > 127 {
-		effectiveG = 127
-	}
+const (
+	LightModeStatic LightMode = ""      // Steady
+	LightModeFlash  LightMode = "flash" // Blinking, by the device itself (Launchpad S); steady elsewhere
+)
+
+// LightModes are the light modes in the order the menu editor lists them
+var LightModes = []LightMode{LightModeStatic, LightModeFlash}
 
-	return colorTo4Level(uint8(effectiveR)), colorTo4Level(uint8(effectiveG))
+// HasOwnStatic reports whether the pad shows its own static color rather than the layout default
+func (p PadColorConfig) HasOwnStatic() bool {
+	return p.OverrideStatic || p.R > 0 || p.G > 0 || p.B > 0 || p.ClassicR > 0 || p.ClassicG > 0 || p.ClassicB > 0
 }
 
-// LevelTo127 converts a 0-3 level to 0-127 value for storage/MIDI
-func LevelTo127(level uint8) uint8 {
-	switch level {
-	case 0:
-		return 0
-	case 1:
-		return 42
-	case 2:
-		return 85
-	default:
-		return 127
+// HasOwnPressed reports whether the pad shows its own pressed color rather than the layout default
+func (p PadColorConfig) HasOwnPressed() bool {
+	return p.OverridePressed || p.PressedR > 0 || p.PressedG > 0 || p.PressedB > 0 ||
+		p.ClassicPressedR > 0 || p.ClassicPressedG > 0 || p.ClassicPressedB > 0
+}
+
+// StaticFor returns the static color sent to a device of type t: the classic color to
+// classic devices, the RGB color to the others
+func (p PadColorConfig) StaticFor(t DeviceType) (r, g, b uint8) {
+	if t == DeviceTypeClassic {
+		return p.ClassicR, p.ClassicG, p.ClassicB
 	}
+	return p.R, p.G, p.B
 }
 
-// Level127To4 converts a 0-127 value back to 0-3 level for UI sliders
-func Level127To4(value uint8) uint8 {
-	if value < 21 {
-		return 0
-	} else if value < 64 {
-		return 1
-	} else if value < 106 {
-		return 2
+// PressedFor returns the pressed color sent to a device of type t, like StaticFor
+func (p PadColorConfig) PressedFor(t DeviceType) (r, g, b uint8) {
+	if t == DeviceTypeClassic {
+		return p.ClassicPressedR, p.ClassicPressedG, p.ClassicPressedB
 	}
-	return 3
+	return p.PressedR, p.PressedG, p.PressedB
 }
 
-// colorTo4Level converts 0-127 color value to 0-3 intensity for Launchpad S
-func colorTo4Level(value uint8) uint8 {
-	if value < 32 {
-		return 0
-	} else if value < 64 {
-		return 1
-	} else if value < 96 {
-		return 2
+// BusyColor returns the color the pad shows while its action runs: its own busy
+// color if set, otherwise the default
+func (p PadColorConfig) BusyColor(defaults BusyFeedbackConfig) (r, g, b uint8) {
+	if p.BusyR > 0 || p.BusyG > 0 || p.BusyB > 0 {
+		return p.BusyR, p.BusyG, p.BusyB
 	}
-	return 3
+	return defaults.R, defaults.G, defaults.B
+}
+
+// ExpandPadArgs fills in an action argument template for one pad: {{pad.row}} and
+// {{pad.col}} become its position and {{pad.index}} its 1-based place among the pads
+// being assigned
+func ExpandPadArgs(template string, row, col, index int) string {
+	return strings.NewReplacer(
+		"{{pad.row}}", strconv.Itoa(row),
+		"{{pad.col}}", strconv.Itoa(col),
+		"{{pad.index}}", strconv.Itoa(index),
+	).Replace(template)
+}
+
+// FeedbackScope says which devices showing the same layout light up a pad press
+type FeedbackScope string
+
+const (
+	FeedbackMirrored FeedbackScope = ""      // Every device showing the layout, e.g. linked surfaces
+	FeedbackLocal    FeedbackScope = "local" // Only the device pressed, e.g. performers sharing a layout
+)
+
+// PadThruMode says whether a pad forwards its presses as MIDI notes
+type PadThruMode string
+
+const (
+	PadThruOff     PadThruMode = ""        // Presses only run the pad's action
+	PadThruReplace PadThruMode = "replace" // Presses play the note instead of running the action
+	PadThruAlso    PadThruMode = "also"    // Presses play the note and run the action
+)
+
+// PadThruSameNote as PadThruConfig.Note sends the pad's own programmer mode note (11-99)
+const PadThruSameNote = -1
+
+// PadThruConfig makes a pad act like a plain MIDI controller pad, e.g. a drum pad into a DAW
+type PadThruConfig struct {
+	Mode     PadThruMode `json:"mode,omitempty"`
+	DeviceID string      `json:"device_id,omitempty"` // Generic device whose output port receives the notes
+	Channel  int         `json:"channel"`             // 0-15
+	Note     int         `json:"note"`                // 0-127, or PadThruSameNote
+}
+
+// Enabled reports whether the pad forwards notes to a device
+func (t PadThruConfig) Enabled() bool {
+	return t.Mode != PadThruOff && t.DeviceID != ""
 }
 
-// MenuLayout stores the 9x9 grid of pad colors
+// MenuLayout stores the 9x9 grid of pad colors. Fields are plain values so Clone can
+// copy a layout by assignment; a slice, map or pointer field needs copying in Clone.
 type MenuLayout struct {
 	ID           string               `json:"id"`
 	Name         string               `json:"name"`
@@ -142,11 +193,42 @@ type MenuLayout struct {
 	LeftColors   [8]PadColorConfig    `json:"left_colors"`   // Pro: Left column (Rows 1-8)
 	BottomColors [8]PadColorConfig    `json:"bottom_colors"` // Pro: Bottom row (Cols 1-8)
 
-	// Pro+ (MK3) Additions
-	TopLeftColor         PadColorConfig    `json:"top_left_color"`         // (0,0)
-	BottomLeftColor      PadColorConfig    `json:"bottom_left_color"`      // (9,0)
-	BottomRightColor     PadColorConfig    `json:"bottom_right_color"`     // (9,9)
-	ExtendedBottomColors [8]PadColorConfig `json:"extended_bottom_colors"` // Row 10 (Cols 1-8)
+	// Pro+ (MK3) Additions. ShapeOf tells which device types have these pads and the
+	// Pro ones above; the top-right corner has no field, being Colors[0][8].
+	TopLeftColor         PadColorConfig    `json:"top_left_color,omitzero"`     // (0,0)
+	BottomLeftColor      PadColorConfig    `json:"bottom_left_color,omitzero"`  // (9,0)
+	BottomRightColor     PadColorConfig    `json:"bottom_right_color,omitzero"` // (9,9)
+	ExtendedBottomColors [8]PadColorConfig `json:"extended_bottom_colors"`      // Row 10 (Cols 1-8)
+
+	// Colors of grid pads that don't set their own: the static fields of DefaultStatic
+	// and the pressed fields of DefaultPressed
+	DefaultStatic  PadColorConfig `json:"default_static,omitzero"`
+	DefaultPressed PadColorConfig `json:"default_pressed,omitzero"`
+
+	// FallbackActionID runs when a pad without an action is pressed, with the pad's
+	// "row col" as its arguments
+	FallbackActionID string `json:"fallback_action_id,omitempty"`
+
+	// OnActivateActionID runs when devices switch to the layout, once for all devices
+	// switching together
+	OnActivateActionID string `json:"on_activate_action_id,omitempty"`
+}
+
+// EffectiveColor returns a grid pad's config with the colors it doesn't set itself
+// taken from the layout defaults. Use it wherever a pad's color is shown.
+func (m *MenuLayout) EffectiveColor(row, col int) PadColorConfig {
+	p := m.Colors[row][col]
+	if !p.HasOwnStatic() {
+		d := m.DefaultStatic
+		p.R, p.G, p.B = d.R, d.G, d.B
+		p.ClassicR, p.ClassicG, p.ClassicB = d.ClassicR, d.ClassicG, d.ClassicB
+	}
+	if !p.HasOwnPressed() {
+		d := m.DefaultPressed
+		p.PressedR, p.PressedG, p.PressedB = d.PressedR, d.PressedG, d.PressedB
+		p.ClassicPressedR, p.ClassicPressedG, p.ClassicPressedB = d.ClassicPressedR, d.ClassicPressedG, d.ClassicPressedB
+	}
+	return p
 }
 
 // NewMenuLayout creates a new menu layout with all pads off
@@ -159,6 +241,124 @@ func NewMenuLayout() MenuLayout {
 	return layout
 }
 
+// Clone returns an independent copy of the layout, every pad area included, with a new ID
+func (m *MenuLayout) Clone() MenuLayout {
+	clone := *m
+	clone.ID = uuid.New().String()
+	return clone
+}
+
+// EnsureDefaultLinking links and converts classic colors for pads saved before classic
+// colors existed (or never set), so classic devices don't show them as black
+func (m *MenuLayout) EnsureDefaultLinking() {
+	for r := range m.Colors {
+		for c := range m.Colors[r] {
+			m.Colors[r][c].LinkClassicDefaults()
+		}
+	}
+}
+
+// LinkClassicDefaults derives the pad's classic colors from its colorful ones and links
+// them, where a classic color was never set, so classic devices don't show it as
+// black. Classic colors set to black on purpose are kept. It reports whether anything
+// changed.
+func (p *PadColorConfig) LinkClassicDefaults() bool {
+	changed := false
+	if !p.ClassicInitialized && p.ClassicR == 0 && p.ClassicG == 0 && p.ClassicB == 0 && (p.R > 0 || p.G > 0 || p.B > 0) {
+		rLevel, gLevel := padcolor.ClassicLevels(p.R, p.G, p.B)
+		p.ClassicR, p.ClassicG = padcolor.LevelValue(rLevel), padcolor.LevelValue(gLevel)
+		p.LinkButtonClassic, p.ClassicInitialized = true, true
+		changed = true
+	}
+	if !p.ClassicPressedInitialized && p.ClassicPressedR == 0 && p.ClassicPressedG == 0 && p.ClassicPressedB == 0 &&
+		(p.PressedR > 0 || p.PressedG > 0 || p.PressedB > 0) {
+		rLevel, gLevel := padcolor.ClassicLevels(p.PressedR, p.PressedG, p.PressedB)
+		p.ClassicPressedR, p.ClassicPressedG = padcolor.LevelValue(rLevel), padcolor.LevelValue(gLevel)
+		p.LinkPressedClassic, p.ClassicPressedInitialized = true, true
+		changed = true
+	}
+	return changed
+}
+
+// PadPosition identifies a pad on the 9x9 grid
+type PadPosition struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Orientation is how far a device is turned clockwise from upright, in degrees
+type Orientation int
+
+// Orientations are the supported orientations, in the order the device editor lists them
+var Orientations = []Orientation{0, 90, 180, 270}
+
+// ToDevice maps a logical pad, as the menu editor shows it, to the device's own
+// coordinates for the pad in that place
+func (o Orientation) ToDevice(row, col int) (int, int) {
+	switch o {
+	case 90:
+		return 8 - col, row
+	case 180:
+		return 8 - row, 8 - col
+	case 270:
+		return col, 8 - row
+	}
+	return row, col
+}
+
+// FromDevice maps a pad in the device's own coordinates to its logical position,
+// undoing ToDevice
+func (o Orientation) FromDevice(row, col int) (int, int) {
+	switch o {
+	case 90:
+		return col, 8 - row
+	case 180:
+		return 8 - row, 8 - col
+	case 270:
+		return 8 - col, row
+	}
+	return row, col
+}
+
+// PageSelectConfig places a device's page-select pads: one pad per page, starting at
+// Start and running along the row (or down the column when Vertical is set)
+type PageSelectConfig struct {
+	Start    PadPosition `json:"start"`
+	Vertical bool        `json:"vertical,omitempty"`
+
+	// Indicator colors for the current page and the other pages
+	ActiveR   uint8 `json:"active_r"`
+	ActiveG   uint8 `json:"active_g"`
+	ActiveB   uint8 `json:"active_b"`
+	InactiveR uint8 `json:"inactive_r"`
+	InactiveG uint8 `json:"inactive_g"`
+	InactiveB uint8 `json:"inactive_b"`
+}
+
+// NewPageSelectConfig returns page-select pads along the top row, lit green for the current page
+func NewPageSelectConfig() PageSelectConfig {
+	return PageSelectConfig{
+		ActiveG:   127,
+		InactiveR: 20,
+		InactiveG: 20,
+		InactiveB: 20,
+	}
+}
+
+// PadFor returns the position of the page-select pad for a page index
+func (p PageSelectConfig) PadFor(page int) PadPosition {
+	if p.Vertical {
+		return PadPosition{Row: p.Start.Row + page, Col: p.Start.Col}
+	}
+	return PadPosition{Row: p.Start.Row, Col: p.Start.Col + page}
+}
+
+// DefaultBrightness is the LED brightness percentage used when none is configured
+const DefaultBrightness = 100
+
+// DefaultPadDebounceMs is how soon a repeated press of a pad is ignored, for contacts that bounce
+const DefaultPadDebounceMs = 30
+
 // DeviceConfig holds configuration for a single MIDI device
 type DeviceConfig struct {
 	ID       string     `json:"id"`        // Unique identifier
@@ -167,14 +367,270 @@ type DeviceConfig struct {
 	OutPort  string     `json:"out_port"`  // MIDI output port name
 	Type     DeviceType `json:"type"`      // Classic or Colorful
 	MainMenu string     `json:"main_menu"` // Menu assignment (placeholder)
+
+	// Port patterns find the ports on machines that name them differently, see ResolvePort
+	InPortMatch  string `json:"in_port_match,omitempty"`
+	OutPortMatch string `json:"out_port_match,omitempty"`
+
+	// Advanced options
+	Disabled          bool `json:"disabled,omitempty"`             // Skip this device when activating, listening and sending
+	Brightness        int  `json:"brightness"`                     // LED brightness percentage (1-100)
+	AllowSharedOutput bool `json:"allow_shared_output,omitempty"`  // Acknowledges intentionally sharing OutPort with other devices (mirroring)
+	RestoreModeOnExit bool `json:"restore_mode_on_exit,omitempty"` // Put the device back in its earlier mode on quit or removal
+
+	// Send limit, for MIDI interfaces that drop messages sent too fast
+	SendRateLimit float64 `json:"send_rate_limit,omitempty"` // Messages per millisecond written to OutPort, 0 for unlimited
+	SendBurst     int     `json:"send_burst,omitempty"`      // Messages written at once before the rate applies, 0 for 1
+
+	// LED output, for devices lit (partly or fully) by other software
+	SendPressedFeedback  bool          `json:"send_pressed_feedback"`            // Echo pressed/released colors on pad presses
+	PressedFeedbackScope FeedbackScope `json:"pressed_feedback_scope,omitempty"` // Which devices show this device's presses, and it theirs
+	SendStaticLayout     bool          `json:"send_static_layout"`               // Send menu layouts to the device
+
+	// Shift layer: while ShiftPad is held, the device shows and responds to ShiftMenu
+	ShiftMenu string      `json:"shift_menu,omitempty"` // Menu ID, empty disables the shift layer
+	ShiftPad  PadPosition `json:"shift_pad"`
+
+	// Paging: the device switches between Pages using the page-select pads
+	Pages       []string         `json:"pages,omitempty"` // Menu IDs, in page order
+	PageSelect  PageSelectConfig `json:"page_select"`
+	PersistPage bool             `json:"persist_page,omitempty"` // Restore LastMenu on startup
+	LastMenu    string           `json:"last_menu,omitempty"`    // Menu ID last switched to at runtime
+
+	// Orientation is how the device is turned in the rig. Layouts, the shift pad and
+	// page-select pads stay in logical (upright) positions.
+	Orientation Orientation `json:"orientation,omitempty"`
+
+	// Hooks: actions or groups run once the device has stayed connected for a moment,
+	// and when it is removed or the app quits
+	OnConnectActionID    string `json:"on_connect_action_id,omitempty"`
+	OnDisconnectActionID string `json:"on_disconnect_action_id,omitempty"`
+
+	// Menu hooks: actions or groups run, by menu ID, when the device leaves a menu and
+	// when it enters one. Exit hooks run before enter hooks, one at a time per device.
+	OnEnterActionID map[string]string `json:"on_enter_action_id,omitempty"`
+	OnExitActionID  map[string]string `json:"on_exit_action_id,omitempty"`
+
+	// LayoutActionPerDevice runs layout activation actions for this device on its own,
+	// even when other devices switch to the same layout with it
+	LayoutActionPerDevice bool `json:"layout_action_per_device,omitempty"`
+
+	// InputFilter drops incoming messages before they are read as pad presses
+	InputFilter InputFilterConfig `json:"input_filter,omitzero"`
+
+	// UseTopRow overlays the global top-row bindings over row 0 of every menu
+	UseTopRow bool `json:"use_top_row,omitempty"`
+}
+
+// InputFilterConfig selects which incoming messages a Launchpad device reacts to
+type InputFilterConfig struct {
+	Channels    uint16 `json:"channels,omitempty"`     // Bit n allows MIDI channel n+1; 0 allows every channel
+	IgnoreCC    bool   `json:"ignore_cc,omitempty"`    // Drop Control Changes (top row and side buttons)
+	IgnoreNotes bool   `json:"ignore_notes,omitempty"` // Drop Note On/Off (the grid)
+	LogFiltered bool   `json:"log_filtered,omitempty"` // Log dropped messages at debug level
+}
+
+// DefaultInputFilter returns the filter new devices of a type start with. Launchpads in
+// programmer mode only send on channel 1; other channels come from DAW or custom modes.
+func DefaultInputFilter(t DeviceType) InputFilterConfig {
+	if t == DeviceTypeGeneric {
+		return InputFilterConfig{}
+	}
+	return InputFilterConfig{Channels: 1}
+}
+
+// AllowsChannel reports whether the filter lets messages on a 0-based channel through
+func (f InputFilterConfig) AllowsChannel(channel int) bool {
+	return f.Channels == 0 || f.Channels&(1<<channel) != 0
+}
+
+// UnmarshalJSON fills in defaults for fields missing from configs saved by older versions
+func (d *DeviceConfig) UnmarshalJSON(data []byte) error {
+	type plain DeviceConfig // Avoids recursing into this method
+	device := plain{
+		SendPressedFeedback: true,
+		SendStaticLayout:    true,
+	}
+	if err := json.Unmarshal(data, &device); err != nil {
+		return err
+	}
+	*d = DeviceConfig(device)
+	return nil
+}
+
+// HasShiftLayer reports whether the device has a shift menu configured
+func (d DeviceConfig) HasShiftLayer() bool {
+	return d.ShiftMenu != ""
+}
+
+// HasPages reports whether the device has more than its main menu to page through
+func (d DeviceConfig) HasPages() bool {
+	return len(d.Pages) > 0
+}
+
+// PageAt returns the page selected by the pad at row/col, if it is a page-select pad
+func (d DeviceConfig) PageAt(row, col int) (int, bool) {
+	for i := range d.Pages {
+		if pad := d.PageSelect.PadFor(i); pad.Row == row && pad.Col == col {
+			return i, true
+		}
+	}
+	return 0, false
 }
 
 // NewDeviceConfig creates a new device config with a generated ID
 func NewDeviceConfig() DeviceConfig {
 	return DeviceConfig{
-		ID:   uuid.New().String(),
-		Name: "New Device",
-		Type: DeviceTypeClassic,
+		ID:         uuid.New().String(),
+		Name:       "New Device",
+		Type:       DeviceTypeClassic,
+		Brightness: DefaultBrightness,
+		PageSelect: NewPageSelectConfig(),
+
+		InputFilter: DefaultInputFilter(DeviceTypeClassic),
+
+		SendPressedFeedback: true,
+		SendStaticLayout:    true,
+	}
+}
+
+// DeviceDefaults are the options new devices start with
+type DeviceDefaults struct {
+	Brightness          int  `json:"brightness"`
+	SendPressedFeedback bool `json:"send_pressed_feedback"`
+	SendStaticLayout    bool `json:"send_static_layout"`
+}
+
+// NewDeviceDefaults returns the built-in defaults for new devices
+func NewDeviceDefaults() DeviceDefaults {
+	return DeviceDefaults{
+		Brightness:          DefaultBrightness,
+		SendPressedFeedback: true,
+		SendStaticLayout:    true,
+	}
+}
+
+// NewDevice creates a device config with the user's device defaults applied
+func (c *Config) NewDevice() DeviceConfig {
+	device := NewDeviceConfig()
+	if c.DeviceDefaults.Brightness > 0 {
+		device.Brightness = c.DeviceDefaults.Brightness
+	}
+	device.SendPressedFeedback = c.DeviceDefaults.SendPressedFeedback
+	device.SendStaticLayout = c.DeviceDefaults.SendStaticLayout
+	return device
+}
+
+// Defaults for the HTTP API
+const (
+	DefaultHTTPBindAddress = "127.0.0.1"
+	DefaultHTTPPort        = 8765
+)
+
+// HTTPAPIConfig controls the optional HTTP API for triggering actions remotely
+type HTTPAPIConfig struct {
+	Enabled     bool   `json:"enabled"`
+	BindAddress string `json:"bind_address"` // 127.0.0.1 for this machine only, 0.0.0.0 for the network
+	Port        int    `json:"port"`
+	Token       string `json:"token"` // Required as "Authorization: Bearer <token>"
+}
+
+// NewHTTPAPIConfig returns the HTTP API settings used until the user changes them
+func NewHTTPAPIConfig() HTTPAPIConfig {
+	return HTTPAPIConfig{
+		BindAddress: DefaultHTTPBindAddress,
+		Port:        DefaultHTTPPort,
+	}
+}
+
+// Addr returns the host:port the HTTP API listens on
+func (h HTTPAPIConfig) Addr() string {
+	return net.JoinHostPort(h.BindAddress, strconv.Itoa(h.Port))
+}
+
+// BusyFeedbackConfig lights a pad while the action it started runs, then flashes
+// green or red for success or failure before its color comes back
+type BusyFeedbackConfig struct {
+	Enabled bool  `json:"enabled"`
+	R       uint8 `json:"r"` // Default busy color (0-127)
+	G       uint8 `json:"g"`
+	B       uint8 `json:"b"`
+
+	// FlashRejected flashes a pad yellow when its action was skipped by its cooldown,
+	// with or without Enabled
+	FlashRejected bool `json:"flash_rejected,omitempty"`
+}
+
+// NewBusyFeedbackConfig returns the busy feedback settings used until the user changes them
+func NewBusyFeedbackConfig() BusyFeedbackConfig {
+	return BusyFeedbackConfig{R: 127, G: 64}
+}
+
+// StartupInitConfig is how devices are brought up at launch. At login some systems
+// list USB MIDI devices only after a while, so missing devices are tried again.
+type StartupInitConfig struct {
+	DelaySeconds int `json:"delay_seconds"` // Wait before the first attempt
+	RetrySeconds int `json:"retry_seconds"` // Keep trying devices whose ports are missing this long, 0 never retries
+}
+
+// VisibilityConfig is when the menu editor warns that pads are hard to see on hardware
+type VisibilityConfig struct {
+	MinBrightness uint8 `json:"min_brightness"` // Lit pads sent dimmer than this (0-127) to colorful devices look off
+	MinDistance   uint8 `json:"min_distance"`   // Neighbors this far apart (0-127) should differ on classic devices
+}
+
+// NewVisibilityConfig returns the visibility thresholds used until the user changes them
+func NewVisibilityConfig() VisibilityConfig {
+	return VisibilityConfig{MinBrightness: 8, MinDistance: 24}
+}
+
+// NewStartupInitConfig returns the startup settings used until the user changes them
+func NewStartupInitConfig() StartupInitConfig {
+	return StartupInitConfig{RetrySeconds: 60}
+}
+
+// TopRowBinding is what one pad of the global top row does. It runs an action, group
+// or scene, or switches the device (or its group) to a menu.
+type TopRowBinding struct {
+	ActionID string `json:"action_id,omitempty"`
+	MenuID   string `json:"menu_id,omitempty"`
+	R        uint8  `json:"r"` // Color (0-127); classic devices show the nearest levels
+	G        uint8  `json:"g"`
+	B        uint8  `json:"b"`
+}
+
+// Bound reports whether the pad does anything, and so replaces the menu's own pad
+func (b TopRowBinding) Bound() bool {
+	return b.ActionID != "" || b.MenuID != ""
+}
+
+// TopRowBindings are the global top-row pads, by column, shown on devices with UseTopRow
+type TopRowBindings [9]TopRowBinding
+
+// At returns the binding that replaces a menu's pad at row/col, if there is one
+func (t TopRowBindings) At(row, col int) (TopRowBinding, bool) {
+	if row != 0 || col < 0 || col >= len(t) || !t[col].Bound() {
+		return TopRowBinding{}, false
+	}
+	return t[col], true
+}
+
+// DeviceGroup treats several devices as one logical surface: members share the group's
+// menu, are enabled together and follow each other's page and shift changes
+type DeviceGroup struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	MainMenu  string   `json:"main_menu"`          // Menu applied to every member
+	Disabled  bool     `json:"disabled,omitempty"` // Applied to every member
+	DeviceIDs []string `json:"device_ids"`
+}
+
+// NewDeviceGroup creates a new, empty device group with a generated ID
+func NewDeviceGroup() DeviceGroup {
+	return DeviceGroup{
+		ID:        uuid.New().String(),
+		Name:      "New Group",
+		DeviceIDs: []string{},
 	}
 }
 
@@ -199,17 +655,76 @@ func NewMessageMapping() MessageMapping {
 	}
 }
 
+// SceneDevice is what a scene does to a device or every member of a device group
+type SceneDevice struct {
+	TargetID   string `json:"target_id"`            // Device or device group
+	MenuID     string `json:"menu_id,omitempty"`    // Menu to switch to, empty keeps the current one
+	Brightness int    `json:"brightness,omitempty"` // 1-100 until devices are next initialized, 0 keeps it
+}
+
+// Scene switches devices to menus and brightnesses, then runs an action or group, all as
+// one unit that pads and message mappings can be bound to like an action
+type Scene struct {
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Devices  []SceneDevice `json:"devices,omitempty"`
+	ActionID string        `json:"action_id,omitempty"` // Action or group run last, never another scene
+}
+
+// NewScene creates an empty scene with a generated ID
+func NewScene(name string) Scene {
+	return Scene{
+		ID:   uuid.New().String(),
+		Name: name,
+	}
+}
+
 // Config holds application configuration
 type Config struct {
 	FirstLaunchCompleted   bool                  `json:"first_launch_completed"`
 	OpenAtStartup          bool                  `json:"open_at_startup"`
+	StartupArgs            []string              `json:"startup_args,omitempty"` // Extra arguments when launched at login, e.g. --headless
 	SuppressUnsavedWarning bool                  `json:"suppress_unsaved_warning"`
+	IgnoredStartupCommand  []string              `json:"ignored_startup_command,omitempty"` // Outdated login item the user chose to keep
+	Language               string                `json:"language,omitempty"`                // UI language code, empty follows the system
 	Devices                []DeviceConfig        `json:"devices"`
 	Menus                  []MenuLayout          `json:"menus"`
 	CurrentMenuID          string                `json:"current_menu_id"`
 	Actions                []actions.Action      `json:"actions"`
 	ActionGroups           []actions.ActionGroup `json:"action_groups"`
 	MessageMappings        []MessageMapping      `json:"message_mappings"`
+	AllowedOverlaps        []string              `json:"allowed_mapping_overlaps,omitempty"` // Mapping pairs meant to match the same messages, see MappingPairKey
+	DeviceGroups           []DeviceGroup         `json:"device_groups,omitempty"`
+	IgnoredPorts           []string              `json:"ignored_ports,omitempty"`       // Ports not to offer as new devices
+	LogLevel               string                `json:"log_level,omitempty"`           // debug, info (default), warn or error
+	LogMIDITraffic         bool                  `json:"log_midi_traffic,omitempty"`    // Log every MIDI message at debug level
+	VirtualOutPort         bool                  `json:"virtual_out_port,omitempty"`    // Create a virtual output port other apps can receive from
+	PadDebounceMs          int                   `json:"pad_debounce_ms"`               // Presses of a pad this soon after the last one don't run its action, 0 disables
+	CodeEditorRows         int                   `json:"code_editor_rows,omitempty"`    // Script editor height in lines, 0 uses the default
+	CodeEditorWrap         bool                  `json:"code_editor_wrap,omitempty"`    // Soft-wrap long lines in the script editor
+	PadSize                int                   `json:"pad_size,omitempty"`            // Menu editor pad size in pixels, 0 fits the window height
+	MinHitTarget           int                   `json:"min_hit_target,omitempty"`      // Smallest pad and button height in pixels, 0 for the theme default
+	CompactColorPanel      bool                  `json:"compact_color_panel,omitempty"` // Menu editor shows static and pressed colors as tabs
+	PadColorCodes          bool                  `json:"pad_color_codes,omitempty"`     // Menu editor labels pads with their hue code or symbol
+	PatternFills           bool                  `json:"pattern_fills,omitempty"`       // Menu editor draws a pattern per hue over the pads
+	DeviceDefaults         DeviceDefaults        `json:"device_defaults"`
+	HTTPAPI                HTTPAPIConfig         `json:"http_api"`
+	DangerPatterns         []string              `json:"danger_patterns"` // Shell actions matching these regular expressions need AllowDangerous
+	BusyFeedback           BusyFeedbackConfig    `json:"busy_feedback"`
+	StartupInit            StartupInitConfig     `json:"startup_init"`
+	Visibility             VisibilityConfig      `json:"visibility"`
+	Scenes                 []Scene               `json:"scenes,omitempty"`
+	StartupActionID        string                `json:"startup_action_id,omitempty"`  // Runs at launch once devices are initialized
+	ShutdownActionID       string                `json:"shutdown_action_id,omitempty"` // Runs at quit, before the devices are released
+	TopRow                 TopRowBindings        `json:"top_row,omitzero"`
+	DisableUsageStats      bool                  `json:"disable_usage_stats,omitempty"` // Don't count pad presses and action runs
+
+	// Revision counts the saves of the config file, so Save can tell it was saved by
+	// someone else since this copy was loaded
+	Revision int64 `json:"revision,omitempty"`
+
+	base      []byte                   // The file as loaded or last saved, what Save merges from
+	mergeHook func(conflicts []string) // See SetMergeHook
 }
 
 // configDir returns the platform-appropriate config directory
@@ -221,6 +736,18 @@ func configDir() (string, error) {
 	return filepath.Join(configHome, "gopher-automate"), nil
 }
 
+// Dir returns the directory holding the config file and other app state, creating it if needed
+func Dir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
 // ConfigPath returns the full path to the config file
 func ConfigPath() (string, error) {
 	dir, err := configDir()
@@ -247,13 +774,36 @@ func Load() (*Config, error) {
 			Devices:              []DeviceConfig{},
 			Menus:                []MenuLayout{defaultMenu},
 			CurrentMenuID:        defaultMenu.ID,
+			PadDebounceMs:        DefaultPadDebounceMs,
+			DeviceDefaults:       NewDeviceDefaults(),
+			HTTPAPI:              NewHTTPAPIConfig(),
+			DangerPatterns:       slices.Clone(actions.DefaultDangerPatterns),
+			BusyFeedback:         NewBusyFeedbackConfig(),
+			StartupInit:          NewStartupInitConfig(),
+			Visibility:           NewVisibilityConfig(),
 		}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg.base = data
+	return cfg, nil
+}
+
+// SchemaVersion is the version of the saved config format, recorded in exported profiles.
+// Parse upgrades anything older; bump it when an upgrade step is added there.
+const SchemaVersion = 2
 
-	var cfg Config
+// Parse decodes a saved config, filling in what older versions didn't save
+func Parse(data []byte) (*Config, error) {
+	// Settings missing from older configs keep their built-in defaults
+	cfg := Config{PadDebounceMs: DefaultPadDebounceMs, DeviceDefaults: NewDeviceDefaults(), HTTPAPI: NewHTTPAPIConfig(),
+		DangerPatterns: slices.Clone(actions.DefaultDangerPatterns), BusyFeedback: NewBusyFeedbackConfig(),
+		StartupInit: NewStartupInitConfig(), Visibility: NewVisibilityConfig()}
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
@@ -262,6 +812,42 @@ func Load() (*Config, error) {
 	if cfg.Devices == nil {
 		cfg.Devices = []DeviceConfig{}
 	}
+
+	// Devices saved before brightness existed default to full brightness
+	for i := range cfg.Devices {
+		if cfg.Devices[i].Brightness <= 0 {
+			cfg.Devices[i].Brightness = DefaultBrightness
+		}
+		// ...and devices saved before paging existed get the default indicator colors
+		if ps := cfg.Devices[i].PageSelect; ps.ActiveR == 0 && ps.ActiveG == 0 && ps.ActiveB == 0 &&
+			ps.InactiveR == 0 && ps.InactiveG == 0 && ps.InactiveB == 0 {
+			defaults := NewPageSelectConfig()
+			cfg.Devices[i].PageSelect.ActiveG = defaults.ActiveG
+			cfg.Devices[i].PageSelect.InactiveR = defaults.InactiveR
+			cfg.Devices[i].PageSelect.InactiveG = defaults.InactiveG
+			cfg.Devices[i].PageSelect.InactiveB = defaults.InactiveB
+		}
+	}
+	// Drop group members that no longer exist
+	for i := range cfg.DeviceGroups {
+		cfg.DeviceGroups[i].DeviceIDs = slices.DeleteFunc(cfg.DeviceGroups[i].DeviceIDs, func(id string) bool {
+			return cfg.GetDevice(id) == nil
+		})
+	}
+
+	// Version 2: classic colors saved before they could be black on purpose count as set
+	// unless they are black, which meant never set
+	for i := range cfg.Menus {
+		cfg.Menus[i].forEachPad(func(_ PadArea, _, _ int, pad *PadColorConfig) {
+			if pad.ClassicR > 0 || pad.ClassicG > 0 || pad.ClassicB > 0 {
+				pad.ClassicInitialized = true
+			}
+			if pad.ClassicPressedR > 0 || pad.ClassicPressedG > 0 || pad.ClassicPressedB > 0 {
+				pad.ClassicPressedInitialized = true
+			}
+		})
+	}
+
 	if cfg.Menus == nil || len(cfg.Menus) == 0 {
 		defaultMenu := NewMenuLayout()
 		cfg.Menus = []MenuLayout{defaultMenu}
@@ -271,24 +857,119 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
-// Save writes the config to disk
+// saveMu keeps the app's saves from interleaving between reading the file and writing it
+var saveMu sync.Mutex
+
+// Save writes the config to disk. If the file was saved by someone else since c was
+// loaded or last saved (its Revision is newer, or it was edited by hand), their changes
+// are merged in rather than overwritten, and c is updated to the merged config; settings changed both ways keep
+// c's value and are logged as conflicts. A config that wasn't loaded from the file,
+// e.g. an imported profile, replaces it.
 func (c *Config) Save() error {
+	saveMu.Lock()
+	merged, conflicts, err := c.save()
+	saveMu.Unlock()
+	if merged && c.mergeHook != nil {
+		c.mergeHook(conflicts) // Unlocked, so it may save
+	}
+	return err
+}
+
+// save is Save without calling the merge hook; the caller holds saveMu. It reports
+// whether changes saved meanwhile were merged into c, and the conflicts.
+func (c *Config) save() (merged bool, conflicts []string, err error) {
 	configPath, err := ConfigPath()
 	if err != nil {
-		return err
+		return false, nil, err
 	}
 
 	dir := filepath.Dir(configPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+		return false, nil, err
 	}
 
+	if disk, err := os.ReadFile(configPath); err == nil {
+		var onDisk struct {
+			Revision int64 `json:"revision"`
+		}
+		_ = json.Unmarshal(disk, &onDisk) // An unreadable file is overwritten, as before
+		// Edits by hand leave the revision alone but change the file
+		if c.base != nil && (onDisk.Revision > c.Revision || !bytes.Equal(disk, c.base)) {
+			if conflicts, err = c.merge(disk); err != nil {
+				return false, nil, fmt.Errorf("failed to merge with the config saved meanwhile: %w", err)
+			}
+			merged = true
+		}
+		c.Revision = max(c.Revision, onDisk.Revision)
+	}
+
+	c.Revision++
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
-		return err
+		return merged, conflicts, err
+	}
+
+	// User-only, as it holds the HTTP API token. A file saved before keeps its mode on
+	// rewrite, so it is narrowed first, before the token is written into it.
+	if err := os.Chmod(configPath, 0600); err != nil && !os.IsNotExist(err) {
+		return merged, conflicts, err
+	}
+	setKnown(data) // First, so the watcher never sees this save as an outside edit
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return merged, conflicts, err
+	}
+	c.base = data
+	return merged, conflicts, nil
+}
+
+// merge updates c with the changes made to the file since c's base, see Save
+func (c *Config) merge(disk []byte) ([]string, error) {
+	mine, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	data, conflicts, err := mergeJSON(c.base, mine, disk)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := Parse(data)
+	if err != nil {
+		return nil, err
 	}
+	for _, path := range conflicts {
+		slog.Warn("Config changed both here and by someone else; keeping this change", "setting", path)
+	}
+	slog.Info("Merged config changes saved meanwhile", "conflicts", len(conflicts))
+	merged.mergeHook = c.mergeHook
+	*c = *merged
+	return conflicts, nil
+}
 
-	return os.WriteFile(configPath, data, 0644)
+// SetMergeHook sets a function called after Save merged changes saved by someone else
+// into c, with the settings changed both ways, so whatever shows c can catch up
+func (c *Config) SetMergeHook(fn func(conflicts []string)) {
+	c.mergeHook = fn
+}
+
+// Replace makes c a copy of other, keeping c's merge hook
+func (c *Config) Replace(other *Config) {
+	hook := c.mergeHook
+	*c = *other
+	c.mergeHook = hook
+}
+
+// Update applies a change to the config as saved, and saves it, leaving out the unsaved
+// edits of every Config in memory. It is how a part of the config is saved on its own.
+func Update(apply func(saved *Config)) error {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	saved, err := Load()
+	if err != nil {
+		return err
+	}
+	apply(saved)
+	_, _, err = saved.save()
+	return err
 }
 
 // GetCurrentMenu returns the current menu layout
@@ -304,13 +985,297 @@ func (c *Config) GetCurrentMenu() *MenuLayout {
 	return nil
 }
 
+// GetMenu returns the menu layout with the given ID, or nil if not found
+func (c *Config) GetMenu(id string) *MenuLayout {
+	for i := range c.Menus {
+		if c.Menus[i].ID == id {
+			return &c.Menus[i]
+		}
+	}
+	return nil
+}
+
+// GetMenuByName returns the menu layout with the given name, or nil if not found
+func (c *Config) GetMenuByName(name string) *MenuLayout {
+	for i := range c.Menus {
+		if c.Menus[i].Name == name {
+			return &c.Menus[i]
+		}
+	}
+	return nil
+}
+
+// GetDevice returns a device by ID, or nil if not found
+func (c *Config) GetDevice(id string) *DeviceConfig {
+	for i := range c.Devices {
+		if c.Devices[i].ID == id {
+			return &c.Devices[i]
+		}
+	}
+	return nil
+}
+
+// GetMessageMapping returns a message mapping by ID, or nil if not found
+func (c *Config) GetMessageMapping(id string) *MessageMapping {
+	for i := range c.MessageMappings {
+		if c.MessageMappings[i].ID == id {
+			return &c.MessageMappings[i]
+		}
+	}
+	return nil
+}
+
+// MappingOverlap is a pair of message mappings that both match some messages
+type MappingOverlap struct {
+	A, B MessageMapping
+}
+
+// SameAction reports whether both mappings run the same thing, so a message matching
+// them runs it twice
+func (o MappingOverlap) SameAction() bool {
+	return o.A.ActionID == o.B.ActionID
+}
+
+// Key identifies the pair, see MappingPairKey
+func (o MappingOverlap) Key() string {
+	return MappingPairKey(o.A.ID, o.B.ID)
+}
+
+// MappingPairKey identifies a pair of mappings by their IDs, in either order
+func MappingPairKey(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "+" + b
+}
+
+// MappingsOverlap reports whether some message matches both mappings: same type and
+// number, on a channel both accept
+func MappingsOverlap(a, b MessageMapping) bool {
+	return a.MessageType == b.MessageType && a.Number == b.Number &&
+		(a.Channel == -1 || b.Channel == -1 || a.Channel == b.Channel)
+}
+
+// FindMappingOverlaps returns every pair of mappings with an action that match some of
+// the same messages, except pairs allowed with AllowMappingOverlap
+func (c *Config) FindMappingOverlaps() []MappingOverlap {
+	var overlaps []MappingOverlap
+	for i, a := range c.MessageMappings {
+		for _, b := range c.MessageMappings[i+1:] {
+			if a.ActionID == "" || b.ActionID == "" || !MappingsOverlap(a, b) {
+				continue
+			}
+			if !slices.Contains(c.AllowedOverlaps, MappingPairKey(a.ID, b.ID)) {
+				overlaps = append(overlaps, MappingOverlap{A: a, B: b})
+			}
+		}
+	}
+	return overlaps
+}
+
+// AllowMappingOverlap records that a pair of mappings is meant to match the same messages
+func (c *Config) AllowMappingOverlap(o MappingOverlap) {
+	if key := o.Key(); !slices.Contains(c.AllowedOverlaps, key) {
+		c.AllowedOverlaps = append(c.AllowedOverlaps, key)
+	}
+}
+
+// PruneAllowedOverlaps forgets allowed pairs that no longer overlap, e.g. because a
+// mapping was deleted or changed, so they are checked again should they overlap later
+func (c *Config) PruneAllowedOverlaps() {
+	c.AllowedOverlaps = slices.DeleteFunc(c.AllowedOverlaps, func(key string) bool {
+		for i, a := range c.MessageMappings {
+			for _, b := range c.MessageMappings[i+1:] {
+				if MappingPairKey(a.ID, b.ID) == key {
+					return !MappingsOverlap(a, b)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// GetScene returns a scene by ID, or nil if not found
+func (c *Config) GetScene(id string) *Scene {
+	for i := range c.Scenes {
+		if c.Scenes[i].ID == id {
+			return &c.Scenes[i]
+		}
+	}
+	return nil
+}
+
+// ValidateDevice checks a device against the rest of the config before it is accepted.
+// The device is compared with every other device (by ID), so it can be an edited copy.
+func (c *Config) ValidateDevice(device DeviceConfig) error {
+	var problems []error
+
+	if strings.TrimSpace(device.Name) == "" {
+		problems = append(problems, errors.New("name is required"))
+	}
+
+	if device.MainMenu != "" && c.GetMenuByName(device.MainMenu) == nil {
+		problems = append(problems, fmt.Errorf("menu '%s' does not exist", device.MainMenu))
+	}
+
+	if device.SendRateLimit < 0 || device.SendBurst < 0 {
+		problems = append(problems, errors.New("send limit can't be negative"))
+	}
+
+	for _, pattern := range []string{device.InPortMatch, device.OutPortMatch} {
+		if _, err := MatchPorts(pattern, nil); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if !slices.Contains(Orientations, device.Orientation) {
+		problems = append(problems, fmt.Errorf("orientation must be one of 0, 90, 180 or 270 degrees, not %d", device.Orientation))
+	}
+
+	if device.HasShiftLayer() {
+		if c.GetMenu(device.ShiftMenu) == nil {
+			problems = append(problems, errors.New("shift menu does not exist"))
+		}
+		if device.ShiftPad.Row < 0 || device.ShiftPad.Row > 8 || device.ShiftPad.Col < 0 || device.ShiftPad.Col > 8 {
+			problems = append(problems, errors.New("shift pad is outside the grid"))
+		}
+	}
+
+	if device.HasPages() {
+		for i, id := range device.Pages {
+			if c.GetMenu(id) == nil {
+				problems = append(problems, fmt.Errorf("page %d menu does not exist", i+1))
+			}
+		}
+		if last := device.PageSelect.PadFor(len(device.Pages) - 1); device.PageSelect.Start.Row < 0 ||
+			device.PageSelect.Start.Col < 0 || last.Row > 8 || last.Col > 8 {
+			problems = append(problems, fmt.Errorf("%d page-select pads don't fit on the grid from the chosen start pad", len(device.Pages)))
+		}
+		if device.HasShiftLayer() {
+			if _, ok := device.PageAt(device.ShiftPad.Row, device.ShiftPad.Col); ok {
+				problems = append(problems, errors.New("shift pad overlaps a page-select pad"))
+			}
+		}
+	}
+
+	for _, other := range c.Devices {
+		if other.ID == device.ID {
+			continue
+		}
+		if device.InPort != "" && other.InPort == device.InPort {
+			problems = append(problems, fmt.Errorf("input port '%s' is already used by '%s'", device.InPort, other.Name))
+		}
+		if device.OutPort != "" && other.OutPort == device.OutPort && !(device.AllowSharedOutput && other.AllowSharedOutput) {
+			problems = append(problems, fmt.Errorf("output port '%s' is already used by '%s' (enable \"Allow shared output\" on both to mirror)", device.OutPort, other.Name))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// PortConflict describes a MIDI port assigned to more than one device
+type PortConflict struct {
+	Port      string
+	IsInput   bool
+	DeviceIDs []string
+	Names     []string
+}
+
+// String returns a human-readable description of the conflict
+func (p PortConflict) String() string {
+	kind := "Output"
+	if p.IsInput {
+		kind = "Input"
+	}
+	return fmt.Sprintf("%s port '%s' is used by %s", kind, p.Port, strings.Join(p.Names, ", "))
+}
+
+// FindPortConflicts returns every port shared by two or more devices.
+// Shared input ports are always conflicts; shared output ports are allowed when
+// every device on the port has AllowSharedOutput set.
+func (c *Config) FindPortConflicts() []PortConflict {
+	var conflicts []PortConflict
+
+	collect := func(isInput bool, portOf func(DeviceConfig) string) {
+		byPort := map[string][]DeviceConfig{}
+		var order []string
+		for _, d := range c.Devices {
+			port := portOf(d)
+			if port == "" {
+				continue
+			}
+			if _, seen := byPort[port]; !seen {
+				order = append(order, port)
+			}
+			byPort[port] = append(byPort[port], d)
+		}
+
+		for _, port := range order {
+			devices := byPort[port]
+			if len(devices) < 2 {
+				continue
+			}
+			if !isInput && allowSharedOutput(devices) {
+				continue
+			}
+			conflict := PortConflict{Port: port, IsInput: isInput}
+			for _, d := range devices {
+				conflict.DeviceIDs = append(conflict.DeviceIDs, d.ID)
+				conflict.Names = append(conflict.Names, d.Name)
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	collect(true, func(d DeviceConfig) string { return d.InPort })
+	collect(false, func(d DeviceConfig) string { return d.OutPort })
+	return conflicts
+}
+
+// allowSharedOutput reports whether every device acknowledged sharing its output port
+func allowSharedOutput(devices []DeviceConfig) bool {
+	for _, d := range devices {
+		if !d.AllowSharedOutput {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPortAssigned reports whether any device uses the port for input or output
+func (c *Config) IsPortAssigned(port string) bool {
+	for _, d := range c.Devices {
+		if d.InPort == port || d.OutPort == port {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPortIgnored reports whether the user asked not to be offered the port again
+func (c *Config) IsPortIgnored(port string) bool {
+	return slices.Contains(c.IgnoredPorts, port)
+}
+
+// IgnorePort stops the port from being offered as a new device
+func (c *Config) IgnorePort(port string) {
+	if !c.IsPortIgnored(port) {
+		c.IgnoredPorts = append(c.IgnoredPorts, port)
+	}
+}
+
 // AddDevice adds a new device to the config
 func (c *Config) AddDevice(device DeviceConfig) {
 	c.Devices = append(c.Devices, device)
 }
 
-// RemoveDevice removes a device by ID
+// RemoveDevice removes a device by ID, including from any group it belongs to
 func (c *Config) RemoveDevice(id string) {
+	for i := range c.DeviceGroups {
+		c.DeviceGroups[i].DeviceIDs = slices.DeleteFunc(c.DeviceGroups[i].DeviceIDs, func(member string) bool {
+			return member == id
+		})
+	}
 	for i, d := range c.Devices {
 		if d.ID == id {
 			c.Devices = append(c.Devices[:i], c.Devices[i+1:]...)
@@ -329,6 +1294,96 @@ func (c *Config) UpdateDevice(device DeviceConfig) {
 	}
 }
 
+// GetDeviceGroup returns a device group by ID, or nil if not found
+func (c *Config) GetDeviceGroup(id string) *DeviceGroup {
+	for i := range c.DeviceGroups {
+		if c.DeviceGroups[i].ID == id {
+			return &c.DeviceGroups[i]
+		}
+	}
+	return nil
+}
+
+// GroupOf returns the group a device belongs to, or nil if it isn't grouped
+func (c *Config) GroupOf(deviceID string) *DeviceGroup {
+	for i := range c.DeviceGroups {
+		if slices.Contains(c.DeviceGroups[i].DeviceIDs, deviceID) {
+			return &c.DeviceGroups[i]
+		}
+	}
+	return nil
+}
+
+// TargetDeviceIDs resolves a device or group ID to the devices it addresses
+func (c *Config) TargetDeviceIDs(id string) []string {
+	if group := c.GetDeviceGroup(id); group != nil {
+		return slices.Clone(group.DeviceIDs)
+	}
+	if c.GetDevice(id) != nil {
+		return []string{id}
+	}
+	return nil
+}
+
+// SyncedDeviceIDs returns the devices that mirror a device's runtime state: the
+// members of its group, or just the device itself
+func (c *Config) SyncedDeviceIDs(deviceID string) []string {
+	if group := c.GroupOf(deviceID); group != nil {
+		return slices.Clone(group.DeviceIDs)
+	}
+	return []string{deviceID}
+}
+
+// UpdateDeviceGroup adds or replaces a group, moving its members out of any other group
+// and applying the group's menu and enabled state to them
+func (c *Config) UpdateDeviceGroup(group DeviceGroup) {
+	for i := range c.DeviceGroups {
+		if c.DeviceGroups[i].ID == group.ID {
+			continue
+		}
+		c.DeviceGroups[i].DeviceIDs = slices.DeleteFunc(c.DeviceGroups[i].DeviceIDs, func(id string) bool {
+			return slices.Contains(group.DeviceIDs, id)
+		})
+	}
+
+	if existing := c.GetDeviceGroup(group.ID); existing != nil {
+		*existing = group
+	} else {
+		c.DeviceGroups = append(c.DeviceGroups, group)
+	}
+
+	for _, id := range group.DeviceIDs {
+		if device := c.GetDevice(id); device != nil {
+			device.MainMenu = group.MainMenu
+			device.Disabled = group.Disabled
+		}
+	}
+}
+
+// RemoveDeviceGroup removes a group by ID. Former members keep their current settings.
+func (c *Config) RemoveDeviceGroup(id string) {
+	c.DeviceGroups = slices.DeleteFunc(c.DeviceGroups, func(g DeviceGroup) bool {
+		return g.ID == id
+	})
+}
+
+// UpdateScene stores a scene, replacing the one with the same ID or adding it
+func (c *Config) UpdateScene(scene Scene) {
+	if existing := c.GetScene(scene.ID); existing != nil {
+		*existing = scene
+	} else {
+		c.Scenes = append(c.Scenes, scene)
+	}
+}
+
+// RemoveScene deletes a scene and unbinds the pads and mappings that ran it
+func (c *Config) RemoveScene(id string) {
+	c.Scenes = slices.DeleteFunc(c.Scenes, func(s Scene) bool {
+		return s.ID == id
+	})
+	c.ClearActionReferences(id)
+}
+
 // GetActionStore returns an ActionStore populated with config's actions and groups
 func (c *Config) GetActionStore() *actions.ActionStore {
 	store := actions.NewActionStore()
@@ -349,6 +1404,273 @@ func (c *Config) SyncActionStore(store *actions.ActionStore) {
 	c.ActionGroups = store.Groups
 }
 
+// PadArea names the part of a layout a pad is in
+type PadArea string
+
+const (
+	PadAreaGrid           PadArea = "grid"            // Colors, by row and column
+	PadAreaLeft           PadArea = "left"            // LeftColors, by row (1-8)
+	PadAreaBottom         PadArea = "bottom"          // BottomColors, by column (1-8)
+	PadAreaExtendedBottom PadArea = "extended_bottom" // ExtendedBottomColors, by column (1-8)
+	PadAreaTopLeft        PadArea = "top_left"
+	PadAreaBottomLeft     PadArea = "bottom_left"
+	PadAreaBottomRight    PadArea = "bottom_right"
+)
+
+// ClearPads turns off every pad of the layout, the Pro areas included
+func (m *MenuLayout) ClearPads() {
+	m.forEachPad(func(_ PadArea, _, _ int, pad *PadColorConfig) {
+		*pad = PadColorConfig{}
+	})
+}
+
+// forEachPad calls fn with every pad of the layout, the Pro areas included
+func (m *MenuLayout) forEachPad(fn func(area PadArea, row, col int, pad *PadColorConfig)) {
+	for r := range m.Colors {
+		for c := range m.Colors[r] {
+			fn(PadAreaGrid, r, c, &m.Colors[r][c])
+		}
+	}
+	for i := range 8 {
+		fn(PadAreaLeft, i+1, 0, &m.LeftColors[i])
+		fn(PadAreaBottom, 0, i+1, &m.BottomColors[i])
+		fn(PadAreaExtendedBottom, 0, i+1, &m.ExtendedBottomColors[i])
+	}
+	fn(PadAreaTopLeft, 0, 0, &m.TopLeftColor)
+	fn(PadAreaBottomLeft, 0, 0, &m.BottomLeftColor)
+	fn(PadAreaBottomRight, 0, 0, &m.BottomRightColor)
+}
+
+// PlanPad is a pad of a layout by its area and its row and column there, numbered as
+// forEachPad numbers them
+type PlanPad struct {
+	Area     PadArea
+	Row, Col int
+}
+
+// Pad returns the layout's config of the pad p, or nil for a pad the layout has no field for
+func (m *MenuLayout) Pad(p PlanPad) *PadColorConfig {
+	var pad *PadColorConfig
+	m.forEachPad(func(area PadArea, row, col int, c *PadColorConfig) {
+		if area == p.Area && row == p.Row && col == p.Col {
+			pad = c
+		}
+	})
+	return pad
+}
+
+// LayoutShape is how a device type's pads are laid out, as a plan of Rows by Cols cells
+type LayoutShape struct {
+	Rows, Cols int
+	pro        bool
+}
+
+var (
+	// standardShape is the 9x9 grid of the Launchpad S and Mini Mk3, top row and right
+	// column included
+	standardShape = LayoutShape{Rows: 9, Cols: 9}
+
+	// proShape is the Launchpad Pro Mk3: the 9x9 grid with the left column and the
+	// top-left corner beside it, and below it the bottom row between the bottom corners
+	// and the extended bottom row. The grid's top-right pad is the top-right corner, so
+	// there is no area for that corner.
+	proShape = LayoutShape{Rows: 11, Cols: 10, pro: true}
+)
+
+// ShapeOf returns the layout shape of a device type
+func ShapeOf(t DeviceType) LayoutShape {
+	if t == DeviceTypePro {
+		return proShape
+	}
+	return standardShape
+}
+
+// At returns the pad at a cell of the plan; ok is false for cells without a pad
+func (s LayoutShape) At(row, col int) (pad PlanPad, ok bool) {
+	switch {
+	case row < 0 || row >= s.Rows || col < 0 || col >= s.Cols:
+		return PlanPad{}, false
+	case !s.pro:
+		return PlanPad{Area: PadAreaGrid, Row: row, Col: col}, true
+	case row <= 8 && col >= 1:
+		return PlanPad{Area: PadAreaGrid, Row: row, Col: col - 1}, true
+	case row == 0:
+		return PlanPad{Area: PadAreaTopLeft}, true
+	case row <= 8:
+		return PlanPad{Area: PadAreaLeft, Row: row}, true
+	case row == 9 && col == 0:
+		return PlanPad{Area: PadAreaBottomLeft}, true
+	case row == 9 && col == 9:
+		return PlanPad{Area: PadAreaBottomRight}, true
+	case row == 9:
+		return PlanPad{Area: PadAreaBottom, Col: col}, true
+	case col >= 1 && col <= 8:
+		return PlanPad{Area: PadAreaExtendedBottom, Col: col}, true
+	}
+	return PlanPad{}, false
+}
+
+// Has reports whether the shape has pads in an area
+func (s LayoutShape) Has(area PadArea) bool {
+	for row := range s.Rows {
+		for col := range s.Cols {
+			if pad, ok := s.At(row, col); ok && pad.Area == area {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EditorShape is the shape the menu editor shows layouts in: the Pro's when an enabled
+// device has its pads, the 9x9 grid otherwise
+func (c *Config) EditorShape() LayoutShape {
+	for _, device := range c.Devices {
+		if !device.Disabled && device.Type == DeviceTypePro {
+			return proShape
+		}
+	}
+	return standardShape
+}
+
+// ActionReference is a pad or message mapping that triggers an action or action group
+type ActionReference struct {
+	ActionID string
+
+	// A pad: the layout, area and position
+	MenuID   string
+	MenuName string
+	Area     PadArea
+	Row, Col int
+
+	// A message mapping, when MenuID is empty
+	MappingID   string
+	MappingName string
+}
+
+// FindActionReferences returns the pads and message mappings that trigger any of the
+// given action or group IDs
+func (c *Config) FindActionReferences(ids ...string) []ActionReference {
+	return c.findActionReferences(func(id string) bool { return slices.Contains(ids, id) })
+}
+
+// ClearActionReferences unbinds the pads, layout fallback and activation actions, app
+// startup and shutdown actions, message mappings, scenes, top-row pads and device hooks
+// that trigger any of the given action, group or scene IDs
+func (c *Config) ClearActionReferences(ids ...string) {
+	for i := range c.Menus {
+		c.Menus[i].forEachPad(func(_ PadArea, _, _ int, pad *PadColorConfig) {
+			if slices.Contains(ids, pad.ActionID) {
+				pad.ActionID, pad.ActionArgs = "", ""
+			}
+		})
+		if slices.Contains(ids, c.Menus[i].FallbackActionID) {
+			c.Menus[i].FallbackActionID = ""
+		}
+		if slices.Contains(ids, c.Menus[i].OnActivateActionID) {
+			c.Menus[i].OnActivateActionID = ""
+		}
+	}
+	if slices.Contains(ids, c.StartupActionID) {
+		c.StartupActionID = ""
+	}
+	if slices.Contains(ids, c.ShutdownActionID) {
+		c.ShutdownActionID = ""
+	}
+	for i := range c.MessageMappings {
+		if slices.Contains(ids, c.MessageMappings[i].ActionID) {
+			c.MessageMappings[i].ActionID = ""
+		}
+	}
+	for i := range c.Scenes {
+		if slices.Contains(ids, c.Scenes[i].ActionID) {
+			c.Scenes[i].ActionID = ""
+		}
+	}
+	for i := range c.TopRow {
+		if slices.Contains(ids, c.TopRow[i].ActionID) {
+			c.TopRow[i].ActionID = ""
+		}
+	}
+	for i := range c.Devices {
+		device := &c.Devices[i]
+		if slices.Contains(ids, device.OnConnectActionID) {
+			device.OnConnectActionID = ""
+		}
+		if slices.Contains(ids, device.OnDisconnectActionID) {
+			device.OnDisconnectActionID = ""
+		}
+		maps.DeleteFunc(device.OnEnterActionID, func(_, id string) bool { return slices.Contains(ids, id) })
+		maps.DeleteFunc(device.OnExitActionID, func(_, id string) bool { return slices.Contains(ids, id) })
+	}
+}
+
+// OrphanedActionReferences returns the pads and message mappings bound to an action or
+// group that no longer exists
+func (c *Config) OrphanedActionReferences() []ActionReference {
+	exists := map[string]bool{}
+	for _, a := range c.Actions {
+		exists[a.ID] = true
+	}
+	for _, g := range c.ActionGroups {
+		exists[g.ID] = true
+	}
+	for _, scene := range c.Scenes {
+		exists[scene.ID] = true // Pads and mappings can run scenes too
+	}
+	return c.findActionReferences(func(id string) bool { return !exists[id] })
+}
+
+// findActionReferences returns the bound pads and mappings whose action ID matches
+func (c *Config) findActionReferences(match func(id string) bool) []ActionReference {
+	var refs []ActionReference
+	for i := range c.Menus {
+		menu := &c.Menus[i]
+		menu.forEachPad(func(area PadArea, row, col int, pad *PadColorConfig) {
+			if pad.ActionID != "" && match(pad.ActionID) {
+				refs = append(refs, ActionReference{
+					ActionID: pad.ActionID,
+					MenuID:   menu.ID,
+					MenuName: menu.Name,
+					Area:     area,
+					Row:      row,
+					Col:      col,
+				})
+			}
+		})
+	}
+	for _, m := range c.MessageMappings {
+		if m.ActionID != "" && match(m.ActionID) {
+			refs = append(refs, ActionReference{ActionID: m.ActionID, MappingID: m.ID, MappingName: m.Name})
+		}
+	}
+	return refs
+}
+
+// TopRowConflicts returns the columns where a menu binds an action to a row-0 pad that
+// the global top row replaces. Any menu can be switched to at runtime, so this applies
+// to every menu as soon as one device uses the top row.
+func (c *Config) TopRowConflicts(menuID string) []int {
+	menu := c.GetMenu(menuID)
+	if menu == nil || !c.UsesTopRow() {
+		return nil
+	}
+	var cols []int
+	for col := range c.TopRow {
+		if _, ok := c.TopRow.At(0, col); ok && menu.Colors[0][col].ActionID != "" {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// UsesTopRow reports whether any Launchpad device overlays the global top row
+func (c *Config) UsesTopRow() bool {
+	return slices.ContainsFunc(c.Devices, func(d DeviceConfig) bool {
+		return d.UseTopRow && d.Type != DeviceTypeGeneric
+	})
+}
+
 // GetAction returns an action by ID, or nil if not found
 func (c *Config) GetAction(id string) *actions.Action {
 	for i := range c.Actions {
@@ -0,0 +1,524 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// fill sets every field reachable from v to a distinct non-zero value, making slices
+// and maps of one element, so a field Clone leaves out or shares shows up
+func fill(v reflect.Value, n *int) {
+	*n++
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprint("v", *n))
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(*n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(*n%120 + 1))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(*n))
+	case reflect.Array:
+		for i := range v.Len() {
+			fill(v.Index(i), n)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i), n)
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), n)
+	case reflect.Map:
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(key, n)
+		fill(elem, n)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), n)
+	default:
+		panic(fmt.Sprintf("fill: no test value for a %s field", v.Kind()))
+	}
+}
+
+// shared returns the path of the first slice, map or pointer a and b both refer to
+func shared(a, b reflect.Value, path string) string {
+	switch a.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer:
+		if !a.IsNil() && a.Pointer() == b.Pointer() {
+			return path
+		}
+	}
+	switch a.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := range min(a.Len(), b.Len()) {
+			if p := shared(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); p != "" {
+				return p
+			}
+		}
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if p := shared(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name); p != "" {
+				return p
+			}
+		}
+	case reflect.Map:
+		for _, key := range a.MapKeys() {
+			if p := shared(a.MapIndex(key), b.MapIndex(key), fmt.Sprintf("%s[%v]", path, key)); p != "" {
+				return p
+			}
+		}
+	case reflect.Pointer:
+		if !a.IsNil() && !b.IsNil() {
+			return shared(a.Elem(), b.Elem(), path)
+		}
+	}
+	return ""
+}
+
+// TestMenuLayoutCloneCopiesEveryField fails when a field added to MenuLayout (or to
+// the pads in it) isn't copied by Clone, or is copied but still shared with the original
+func TestMenuLayoutCloneCopiesEveryField(t *testing.T) {
+	var m MenuLayout
+	fill(reflect.ValueOf(&m).Elem(), new(int))
+
+	clone := m.Clone()
+	if clone.ID == "" || clone.ID == m.ID {
+		t.Errorf("clone ID = %q, want a new one (original %q)", clone.ID, m.ID)
+	}
+	clone.ID = m.ID
+	if !reflect.DeepEqual(clone, m) {
+		t.Errorf("clone differs from the original:\n%+v\n%+v", clone, m)
+	}
+	if path := shared(reflect.ValueOf(clone), reflect.ValueOf(m), "MenuLayout"); path != "" {
+		t.Errorf("clone shares %s with the original", path)
+	}
+}
+
+func TestMenuLayoutCloneIsIndependent(t *testing.T) {
+	m := NewMenuLayout()
+	m.Colors[1][1] = PadColorConfig{R: 127, ActionID: "a", Thru: PadThruConfig{Mode: PadThruReplace, DeviceID: "d"}}
+	m.LeftColors[2].ActionID = "left"
+	m.TopLeftColor.G = 5
+
+	clone := m.Clone()
+	clone.forEachPad(func(_ PadArea, _, _ int, pad *PadColorConfig) {
+		pad.R, pad.ActionID, pad.Thru.DeviceID = 1, "changed", "changed"
+	})
+	clone.Name = "Copy"
+
+	if p := m.Colors[1][1]; p.R != 127 || p.ActionID != "a" || p.Thru.DeviceID != "d" {
+		t.Errorf("editing the clone changed the original's pad to %+v", p)
+	}
+	if m.LeftColors[2].ActionID != "left" || m.TopLeftColor.R != 0 || m.Name != "Main Menu" {
+		t.Errorf("editing the clone changed the original: %+v", m)
+	}
+}
+
+// referencingConfig binds action "gone" in every place an action can be triggered from
+func referencingConfig() *Config {
+	menu := NewMenuLayout()
+	menu.ID, menu.Name = "m", "Main"
+	menu.Colors[2][3] = PadColorConfig{ActionID: "gone", ActionArgs: "x"}
+	menu.Colors[4][4] = PadColorConfig{ActionID: "kept"}
+	menu.BottomColors[1].ActionID = "gone"
+	menu.FallbackActionID, menu.OnActivateActionID = "gone", "gone"
+
+	device := NewDeviceConfig()
+	device.OnConnectActionID, device.OnDisconnectActionID = "gone", "kept"
+	device.OnEnterActionID = map[string]string{"m": "gone", "other": "kept"}
+	device.OnExitActionID = map[string]string{"m": "gone"}
+
+	cfg := &Config{
+		Menus:            []MenuLayout{menu},
+		Devices:          []DeviceConfig{device},
+		StartupActionID:  "gone",
+		ShutdownActionID: "kept",
+		MessageMappings: []MessageMapping{
+			{ID: "mm1", Name: "Fader", ActionID: "gone"},
+			{ID: "mm2", Name: "Knob", ActionID: "kept"},
+		},
+		Scenes: []Scene{{ID: "s", Name: "Scene", ActionID: "gone"}},
+	}
+	cfg.TopRow[5].ActionID = "gone"
+	return cfg
+}
+
+func TestFindActionReferences(t *testing.T) {
+	cfg := referencingConfig()
+	want := []ActionReference{
+		{ActionID: "gone", MenuID: "m", MenuName: "Main", Area: PadAreaGrid, Row: 2, Col: 3},
+		{ActionID: "gone", MenuID: "m", MenuName: "Main", Area: PadAreaBottom, Row: 0, Col: 2},
+		{ActionID: "gone", MappingID: "mm1", MappingName: "Fader"},
+	}
+	if got := cfg.FindActionReferences("gone"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindActionReferences(gone) =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := cfg.FindActionReferences("gone", "kept"); len(got) != 5 {
+		t.Errorf("FindActionReferences(gone, kept) found %d, want 5", len(got))
+	}
+	if got := cfg.FindActionReferences("nothing"); len(got) != 0 {
+		t.Errorf("FindActionReferences(nothing) = %+v", got)
+	}
+}
+
+func TestClearActionReferences(t *testing.T) {
+	cfg := referencingConfig()
+	cfg.ClearActionReferences("gone")
+
+	if refs := cfg.FindActionReferences("gone"); len(refs) != 0 {
+		t.Errorf("references left: %+v", refs)
+	}
+	menu := cfg.Menus[0]
+	if p := menu.Colors[2][3]; p.ActionID != "" || p.ActionArgs != "" {
+		t.Errorf("pad kept %+v", p)
+	}
+	if menu.FallbackActionID != "" || menu.OnActivateActionID != "" {
+		t.Errorf("layout actions kept: %q, %q", menu.FallbackActionID, menu.OnActivateActionID)
+	}
+	if cfg.StartupActionID != "" || cfg.Scenes[0].ActionID != "" || cfg.TopRow[5].ActionID != "" {
+		t.Errorf("startup %q, scene %q, top row %q kept", cfg.StartupActionID, cfg.Scenes[0].ActionID, cfg.TopRow[5].ActionID)
+	}
+	device := cfg.Devices[0]
+	if device.OnConnectActionID != "" || len(device.OnExitActionID) != 0 ||
+		!reflect.DeepEqual(device.OnEnterActionID, map[string]string{"other": "kept"}) {
+		t.Errorf("device hooks left: %q, %v, %v", device.OnConnectActionID, device.OnEnterActionID, device.OnExitActionID)
+	}
+
+	// Everything bound to another action stays
+	if menu.Colors[4][4].ActionID != "kept" || cfg.ShutdownActionID != "kept" ||
+		device.OnDisconnectActionID != "kept" || cfg.MessageMappings[1].ActionID != "kept" {
+		t.Error("a reference to another action was cleared")
+	}
+}
+
+func TestOrphanedActionReferences(t *testing.T) {
+	cfg := referencingConfig()
+	cfg.Actions = []actions.Action{{ID: "kept"}}
+	if got := cfg.OrphanedActionReferences(); len(got) != 3 || got[0].ActionID != "gone" {
+		t.Errorf("orphans = %+v, want the 3 references to gone", got)
+	}
+
+	// Groups and scenes are triggered like actions
+	cfg.ActionGroups = []actions.ActionGroup{{ID: "gone"}}
+	if got := cfg.OrphanedActionReferences(); len(got) != 0 {
+		t.Errorf("orphans = %+v with gone a group, want none", got)
+	}
+	cfg.ActionGroups = nil
+	cfg.Scenes[0].ID = "gone"
+	if got := cfg.OrphanedActionReferences(); len(got) != 0 {
+		t.Errorf("orphans = %+v with gone a scene, want none", got)
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	// Where the logical top-left pad and the one right of it are on the device
+	tests := []struct {
+		o                  Orientation
+		topLeft, rightOfIt [2]int
+	}{
+		{0, [2]int{0, 0}, [2]int{0, 1}},
+		{90, [2]int{8, 0}, [2]int{7, 0}}, // Turned clockwise: the device's bottom-left is now top-left
+		{180, [2]int{8, 8}, [2]int{8, 7}},
+		{270, [2]int{0, 8}, [2]int{1, 8}},
+	}
+	for _, tt := range tests {
+		if row, col := tt.o.ToDevice(0, 0); [2]int{row, col} != tt.topLeft {
+			t.Errorf("%d: ToDevice(0, 0) = %d, %d; want %v", tt.o, row, col, tt.topLeft)
+		}
+		if row, col := tt.o.ToDevice(0, 1); [2]int{row, col} != tt.rightOfIt {
+			t.Errorf("%d: ToDevice(0, 1) = %d, %d; want %v", tt.o, row, col, tt.rightOfIt)
+		}
+	}
+}
+
+// TestOrientationInverse checks that FromDevice undoes ToDevice on every pad, that each
+// orientation is a permutation of the grid, and that turning by 90 adds up
+func TestOrientationInverse(t *testing.T) {
+	for _, o := range Orientations {
+		seen := map[[2]int]bool{}
+		for row := range 9 {
+			for col := range 9 {
+				deviceRow, deviceCol := o.ToDevice(row, col)
+				if deviceRow < 0 || deviceRow > 8 || deviceCol < 0 || deviceCol > 8 {
+					t.Fatalf("%d: ToDevice(%d, %d) = %d, %d, off the grid", o, row, col, deviceRow, deviceCol)
+				}
+				seen[[2]int{deviceRow, deviceCol}] = true
+				if r, c := o.FromDevice(deviceRow, deviceCol); r != row || c != col {
+					t.Errorf("%d: FromDevice(ToDevice(%d, %d)) = %d, %d", o, row, col, r, c)
+				}
+
+				// o is o/90 quarter turns
+				r, c := row, col
+				for range int(o) / 90 {
+					r, c = Orientation(90).ToDevice(r, c)
+				}
+				if r != deviceRow || c != deviceCol {
+					t.Errorf("%d: ToDevice(%d, %d) = %d, %d; quarter turns give %d, %d", o, row, col, deviceRow, deviceCol, r, c)
+				}
+			}
+		}
+		if len(seen) != 81 {
+			t.Errorf("%d maps the grid onto %d pads", o, len(seen))
+		}
+	}
+}
+
+func TestValidateDeviceOrientation(t *testing.T) {
+	cfg := &Config{}
+	device := NewDeviceConfig()
+	device.Name = "Launchpad"
+	for _, o := range Orientations {
+		device.Orientation = o
+		if err := cfg.ValidateDevice(device); err != nil {
+			t.Errorf("orientation %d: %v", o, err)
+		}
+	}
+	device.Orientation = 45
+	if err := cfg.ValidateDevice(device); err == nil {
+		t.Error("orientation 45 accepted")
+	}
+}
+
+func TestEffectiveColor(t *testing.T) {
+	menu := NewMenuLayout()
+	menu.DefaultStatic = PadColorConfig{G: 100, ClassicG: 3}
+	menu.DefaultPressed = PadColorConfig{PressedR: 127, ClassicPressedR: 3}
+	menu.Colors[0][0] = PadColorConfig{ActionID: "a"}                               // Inherits both
+	menu.Colors[0][1] = PadColorConfig{B: 50}                                       // Own static
+	menu.Colors[0][2] = PadColorConfig{ClassicPressedG: 2}                          // Own pressed, classic only
+	menu.Colors[0][3] = PadColorConfig{OverrideStatic: true, OverridePressed: true} // Off on purpose
+
+	tests := []struct {
+		col              int
+		r, g, b          uint8
+		classicG         uint8
+		pressedR         uint8
+		classicPressedRG [2]uint8
+	}{
+		{0, 0, 100, 0, 3, 127, [2]uint8{3, 0}},
+		{1, 0, 0, 50, 0, 127, [2]uint8{3, 0}},
+		{2, 0, 100, 0, 3, 0, [2]uint8{0, 2}},
+		{3, 0, 0, 0, 0, 0, [2]uint8{0, 0}},
+	}
+	for _, tt := range tests {
+		p := menu.EffectiveColor(0, tt.col)
+		if p.R != tt.r || p.G != tt.g || p.B != tt.b || p.ClassicG != tt.classicG {
+			t.Errorf("pad 0,%d static = %d,%d,%d classic G %d; want %d,%d,%d classic G %d",
+				tt.col, p.R, p.G, p.B, p.ClassicG, tt.r, tt.g, tt.b, tt.classicG)
+		}
+		if p.PressedR != tt.pressedR || [2]uint8{p.ClassicPressedR, p.ClassicPressedG} != tt.classicPressedRG {
+			t.Errorf("pad 0,%d pressed R %d classic %d,%d; want %d classic %v",
+				tt.col, p.PressedR, p.ClassicPressedR, p.ClassicPressedG, tt.pressedR, tt.classicPressedRG)
+		}
+	}
+	if menu.EffectiveColor(0, 0).ActionID != "a" {
+		t.Error("EffectiveColor lost the pad's action")
+	}
+	if menu.Colors[0][0].G != 0 {
+		t.Error("EffectiveColor stored the default in the pad")
+	}
+}
+
+func TestLayoutShape(t *testing.T) {
+	for _, deviceType := range []DeviceType{DeviceTypeClassic, DeviceTypeColorful, DeviceTypeGeneric, DeviceTypeSimulated} {
+		shape := ShapeOf(deviceType)
+		if shape.Rows != 9 || shape.Cols != 9 {
+			t.Errorf("%s: %dx%d, want 9x9", deviceType, shape.Rows, shape.Cols)
+		}
+		if pad, ok := shape.At(3, 5); !ok || pad != (PlanPad{Area: PadAreaGrid, Row: 3, Col: 5}) {
+			t.Errorf("%s: At(3, 5) = %+v, %v", deviceType, pad, ok)
+		}
+		for _, area := range []PadArea{PadAreaLeft, PadAreaBottom, PadAreaExtendedBottom, PadAreaTopLeft, PadAreaBottomLeft, PadAreaBottomRight} {
+			if shape.Has(area) {
+				t.Errorf("%s has %s pads", deviceType, area)
+			}
+		}
+	}
+
+	// The Pro plan holds every pad of a layout exactly once
+	shape := ShapeOf(DeviceTypePro)
+	seen := map[PlanPad]int{}
+	for row := range shape.Rows {
+		for col := range shape.Cols {
+			if pad, ok := shape.At(row, col); ok {
+				seen[pad]++
+			}
+		}
+	}
+	var layout MenuLayout
+	pads := 0
+	layout.forEachPad(func(area PadArea, row, col int, _ *PadColorConfig) {
+		pads++
+		if n := seen[PlanPad{Area: area, Row: row, Col: col}]; n != 1 {
+			t.Errorf("%s pad %d,%d is %d times on the Pro plan", area, row, col, n)
+		}
+	})
+	if len(seen) != pads {
+		t.Errorf("Pro plan has %d pads, the layout %d", len(seen), pads)
+	}
+
+	tests := []struct {
+		row, col int
+		want     PlanPad
+		ok       bool
+	}{
+		{0, 0, PlanPad{Area: PadAreaTopLeft}, true},
+		{0, 9, PlanPad{Area: PadAreaGrid, Row: 0, Col: 8}, true}, // The top-right corner is the logo of the 9x9 grid
+		{4, 0, PlanPad{Area: PadAreaLeft, Row: 4}, true},
+		{4, 1, PlanPad{Area: PadAreaGrid, Row: 4, Col: 0}, true},
+		{9, 0, PlanPad{Area: PadAreaBottomLeft}, true},
+		{9, 3, PlanPad{Area: PadAreaBottom, Col: 3}, true},
+		{9, 9, PlanPad{Area: PadAreaBottomRight}, true},
+		{10, 8, PlanPad{Area: PadAreaExtendedBottom, Col: 8}, true},
+		{10, 0, PlanPad{}, false},
+		{10, 9, PlanPad{}, false},
+		{11, 1, PlanPad{}, false},
+	}
+	for _, tt := range tests {
+		if got, ok := shape.At(tt.row, tt.col); got != tt.want || ok != tt.ok {
+			t.Errorf("Pro At(%d, %d) = %+v, %v; want %+v, %v", tt.row, tt.col, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEditorShape(t *testing.T) {
+	cfg := &Config{Devices: []DeviceConfig{{Type: DeviceTypeColorful}, {Type: DeviceTypePro, Disabled: true}}}
+	if shape := cfg.EditorShape(); shape != ShapeOf(DeviceTypeColorful) {
+		t.Errorf("with a disabled Pro: %+v, want the 9x9 grid", shape)
+	}
+	cfg.Devices[1].Disabled = false
+	if shape := cfg.EditorShape(); shape != ShapeOf(DeviceTypePro) {
+		t.Errorf("with a Pro: %+v, want the Pro plan", shape)
+	}
+}
+
+func TestMenuLayoutPad(t *testing.T) {
+	var layout MenuLayout
+	layout.Pad(PlanPad{Area: PadAreaBottomRight}).R = 1
+	layout.Pad(PlanPad{Area: PadAreaLeft, Row: 8}).R = 2
+	layout.Pad(PlanPad{Area: PadAreaGrid, Row: 2, Col: 3}).R = 3
+	if layout.BottomRightColor.R != 1 || layout.LeftColors[7].R != 2 || layout.Colors[2][3].R != 3 {
+		t.Errorf("Pad set the wrong fields: %+v %+v %+v", layout.BottomRightColor, layout.LeftColors[7], layout.Colors[2][3])
+	}
+	if pad := layout.Pad(PlanPad{Area: PadAreaLeft, Row: 0}); pad != nil {
+		t.Errorf("Pad of the left column's row 0 = %+v, want nil", pad)
+	}
+
+	clone := layout.Clone()
+	clone.ClearPads()
+	if clone.BottomRightColor.R != 0 || clone.LeftColors[7].R != 0 || layout.BottomRightColor.R != 1 {
+		t.Error("ClearPads left the corner pads or cleared the original's")
+	}
+}
+
+func TestLinkClassicDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		pad     PadColorConfig
+		changed bool
+		want    PadColorConfig
+	}{
+		{"never set", PadColorConfig{R: 127, G: 64},
+			true, PadColorConfig{R: 127, G: 64, ClassicR: 127, ClassicG: 85, LinkButtonClassic: true, ClassicInitialized: true}},
+		{"black on purpose", PadColorConfig{R: 127, ClassicInitialized: true},
+			false, PadColorConfig{R: 127, ClassicInitialized: true}},
+		{"set by hand", PadColorConfig{R: 127, ClassicG: 42},
+			false, PadColorConfig{R: 127, ClassicG: 42}},
+		{"off", PadColorConfig{},
+			false, PadColorConfig{}},
+		{"pressed never set", PadColorConfig{PressedG: 127, ClassicInitialized: true},
+			true, PadColorConfig{PressedG: 127, ClassicPressedG: 127, LinkPressedClassic: true, ClassicInitialized: true, ClassicPressedInitialized: true}},
+		{"pressed black on purpose", PadColorConfig{PressedG: 127, ClassicPressedInitialized: true},
+			false, PadColorConfig{PressedG: 127, ClassicPressedInitialized: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pad := tt.pad
+			if changed := pad.LinkClassicDefaults(); changed != tt.changed || pad != tt.want {
+				t.Errorf("got %+v, changed %v; want %+v, changed %v", pad, changed, tt.want, tt.changed)
+			}
+			// Deriving happens once
+			if pad.LinkClassicDefaults() {
+				t.Error("second call changed the pad again")
+			}
+		})
+	}
+}
+
+func TestParseMarksClassicColorsSet(t *testing.T) {
+	// Saved before the flags existed: pad 0,0 has a classic color of its own, pad 0,1 never had one
+	cfg, err := Parse([]byte(`{"menus": [{"id": "m", "name": "Main", "colors": [[
+		{"r": 127, "classic_r": 0, "classic_g": 42},
+		{"r": 127},
+		{}, {}, {}, {}, {}, {}, {}]]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu := &cfg.Menus[0]
+	if !menu.Colors[0][0].ClassicInitialized || menu.Colors[0][1].ClassicInitialized || menu.Colors[0][2].ClassicInitialized {
+		t.Fatalf("initialized flags = %v, %v, %v; want only the pad with a classic color",
+			menu.Colors[0][0].ClassicInitialized, menu.Colors[0][1].ClassicInitialized, menu.Colors[0][2].ClassicInitialized)
+	}
+
+	menu.EnsureDefaultLinking()
+	if p := menu.Colors[0][0]; p.ClassicR != 0 || p.ClassicG != 42 || p.LinkButtonClassic {
+		t.Errorf("pad with its own classic color changed: %+v", p)
+	}
+	if p := menu.Colors[0][1]; p.ClassicR != 127 || !p.LinkButtonClassic {
+		t.Errorf("pad without a classic color not derived: %+v", p)
+	}
+}
+
+// TestSaveIsUserOnly checks that the config file, which holds the HTTP API token, can
+// only be read by its owner, also when it was saved readable by others before
+func TestSaveIsUserOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't carry permissions on Windows")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.HTTPAPI.Token = "secret"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMode := func(when string) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("config file mode %v %s, want 0600", mode, when)
+		}
+	}
+	checkMode("when created")
+
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	checkMode("after saving over a file readable by others")
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// absent stands for a key missing from a JSON object, so it compares like a value
+var absent = &struct{ absent bool }{}
+
+// mergeJSON merges the changes made from base to theirs into mine, all saved configs.
+// Objects are merged key by key, lists of objects with an "id" by ID, and lists of
+// equal length (like the pad grid) item by item. A value both sides changed, to
+// different values, is a conflict: mine is kept, and its path returned.
+func mergeJSON(base, mine, theirs []byte) (merged []byte, conflicts []string, err error) {
+	var b, m, t any
+	for _, v := range []struct {
+		data []byte
+		into *any
+	}{{base, &b}, {mine, &m}, {theirs, &t}} {
+		dec := json.NewDecoder(bytes.NewReader(v.data))
+		dec.UseNumber() // Keeps numbers as written, so unchanged ones compare equal
+		if err := dec.Decode(v.into); err != nil {
+			return nil, nil, err
+		}
+	}
+	result := mergeValue("", b, m, t, &conflicts)
+	merged, err = json.MarshalIndent(result, "", "  ")
+	return merged, conflicts, err
+}
+
+func mergeValue(path string, base, mine, theirs any, conflicts *[]string) any {
+	switch {
+	case reflect.DeepEqual(mine, theirs), reflect.DeepEqual(base, theirs):
+		return mine
+	case reflect.DeepEqual(base, mine):
+		return theirs
+	}
+
+	// Changed both ways: look closer where the values have parts
+	bo, bok := base.(map[string]any)
+	mo, mok := mine.(map[string]any)
+	to, tok := theirs.(map[string]any)
+	if bok && mok && tok {
+		return mergeObjects(path, bo, mo, to, conflicts)
+	}
+	bl, bok := base.([]any)
+	if base == nil || base == absent {
+		bok = true // A list both sides added, or filled in since a null one
+	}
+	ml, mok := mine.([]any)
+	tl, tok := theirs.([]any)
+	if bok && mok && tok {
+		if withIDs(bl) && withIDs(ml) && withIDs(tl) && len(ml)+len(tl) > 0 {
+			return mergeByID(path, bl, ml, tl, conflicts)
+		}
+		if len(bl) == len(ml) && len(bl) == len(tl) {
+			merged := make([]any, len(ml))
+			for i := range ml {
+				merged[i] = mergeValue(fmt.Sprintf("%s[%d]", path, i), bl[i], ml[i], tl[i], conflicts)
+			}
+			return merged
+		}
+	}
+	*conflicts = append(*conflicts, strings.TrimPrefix(path, "."))
+	return mine
+}
+
+func mergeObjects(path string, base, mine, theirs map[string]any, conflicts *[]string) map[string]any {
+	get := func(o map[string]any, key string) any {
+		if v, ok := o[key]; ok {
+			return v
+		}
+		return absent
+	}
+	merged := map[string]any{}
+	for _, o := range []map[string]any{mine, theirs} {
+		for key := range o {
+			if _, done := merged[key]; done {
+				continue
+			}
+			if v := mergeValue(path+"."+key, get(base, key), get(mine, key), get(theirs, key), conflicts); v != absent {
+				merged[key] = v
+			}
+		}
+	}
+	// Keys both sides removed stay removed; a key only one side removed is handled above
+	return merged
+}
+
+// withIDs reports whether every item of a list is an object with a string "id", so an
+// empty list goes with any list of IDs
+func withIDs(list []any) bool {
+	for _, item := range list {
+		o, ok := item.(map[string]any)
+		if !ok {
+			return false
+		}
+		if _, ok := o["id"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeByID merges lists of objects by their "id", in mine's order with items only
+// theirs added at the end. An item one side removed and the other changed is a conflict.
+func mergeByID(path string, base, mine, theirs []any, conflicts *[]string) []any {
+	index := func(list []any) map[string]any {
+		byID := map[string]any{}
+		for _, item := range list {
+			byID[item.(map[string]any)["id"].(string)] = item
+		}
+		return byID
+	}
+	bi, mi, ti := index(base), index(mine), index(theirs)
+	itemPath := func(id string, item any) string {
+		if name, ok := item.(map[string]any)["name"].(string); ok && name != "" {
+			return fmt.Sprintf("%s[%s]", path, name)
+		}
+		return fmt.Sprintf("%s[%s]", path, id)
+	}
+	lookup := func(byID map[string]any, id string) any {
+		if v, ok := byID[id]; ok {
+			return v
+		}
+		return absent
+	}
+
+	var merged []any
+	var ids []string
+	for _, item := range mine {
+		ids = append(ids, item.(map[string]any)["id"].(string))
+	}
+	for _, item := range theirs {
+		if id := item.(map[string]any)["id"].(string); !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		b, m, t := lookup(bi, id), lookup(mi, id), lookup(ti, id)
+		named := m
+		if named == absent {
+			named = t
+		}
+		if v := mergeValue(itemPath(id, named), b, m, t, conflicts); v != absent {
+			merged = append(merged, v)
+		}
+	}
+	if merged == nil {
+		merged = []any{}
+	}
+	return merged
+}
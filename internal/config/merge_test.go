@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+func TestMergeJSON(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, mine, theirs string
+		want               string
+		conflicts          []string
+	}{
+		{"both added by ID",
+			`{"l":[{"id":"x"}]}`, `{"l":[{"id":"x"},{"id":"a"}]}`, `{"l":[{"id":"x"},{"id":"b"}]}`,
+			`{"l":[{"id":"x"},{"id":"a"},{"id":"b"}]}`, nil},
+		{"both added to an empty list",
+			`{"l":[]}`, `{"l":[{"id":"a"}]}`, `{"l":[{"id":"b"}]}`,
+			`{"l":[{"id":"a"},{"id":"b"}]}`, nil},
+		{"both added to a null list",
+			`{"l":null}`, `{"l":[{"id":"a"}]}`, `{"l":[{"id":"b"}]}`,
+			`{"l":[{"id":"a"},{"id":"b"}]}`, nil},
+		{"both added a list",
+			`{}`, `{"l":[{"id":"a"}]}`, `{"l":[{"id":"b"}]}`,
+			`{"l":[{"id":"a"},{"id":"b"}]}`, nil},
+		{"one emptied, the other added",
+			`{"l":[{"id":"x"}]}`, `{"l":[]}`, `{"l":[{"id":"x"},{"id":"b"}]}`,
+			`{"l":[{"id":"b"}]}`, nil},
+		{"edited and deleted",
+			`{"l":[{"id":"x","name":"X","v":1}]}`, `{"l":[{"id":"x","name":"X","v":2}]}`, `{"l":[]}`,
+			`{"l":[{"id":"x","name":"X","v":2}]}`, []string{"l[X]"}},
+		{"lists without IDs",
+			`{"l":[]}`, `{"l":[1]}`, `{"l":[2]}`,
+			`{"l":[1]}`, []string{"l"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts, err := mergeJSON([]byte(tt.base), []byte(tt.mine), []byte(tt.theirs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want any
+			if err := json.Unmarshal(merged, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := mustJSON(t, got), mustJSON(t, want); g != w {
+				t.Errorf("merged %s, want %s", g, w)
+			}
+			if !slices.Equal(conflicts, tt.conflicts) {
+				t.Errorf("conflicts %q, want %q", conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// twoWriters saves cfg as the config file and loads it twice, as two windows or
+// processes editing the same file
+func twoWriters(t *testing.T, cfg *Config) (a, b *Config) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	menu := NewMenuLayout() // Else each load makes up a different default menu
+	cfg.Menus, cfg.CurrentMenuID = []MenuLayout{menu}, menu.ID
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	a, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func deviceIDs(cfg *Config) []string {
+	var ids []string
+	for _, d := range cfg.Devices {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func TestSaveMergesInterleavedWriters(t *testing.T) {
+	t.Run("add and add", func(t *testing.T) {
+		a, b := twoWriters(t, &Config{Devices: []DeviceConfig{{ID: "x", Name: "X"}}})
+		a.Devices = append(a.Devices, DeviceConfig{ID: "a", Name: "A"})
+		b.Devices = append(b.Devices, DeviceConfig{ID: "b", Name: "B"})
+		if err := a.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Save(); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := deviceIDs(saved); !slices.Equal(ids, []string{"x", "b", "a"}) {
+			t.Errorf("saved devices %q, want x, b and a", ids)
+		}
+		if ids := deviceIDs(b); !slices.Equal(ids, []string{"x", "b", "a"}) {
+			t.Errorf("merged writer has devices %q, want x, b and a", ids)
+		}
+	})
+
+	t.Run("edit and delete", func(t *testing.T) {
+		a, b := twoWriters(t, &Config{Devices: []DeviceConfig{{ID: "x", Name: "X"}, {ID: "y", Name: "Y"}}})
+		a.Devices[0].Name = "Renamed"
+		b.Devices = b.Devices[1:]
+		var conflicts []string
+		b.SetMergeHook(func(c []string) { conflicts = c })
+		if err := a.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Save(); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Both changed the device: the last save wins, and says so
+		if ids := deviceIDs(saved); !slices.Equal(ids, []string{"y"}) {
+			t.Errorf("saved devices %q, want the deletion kept", ids)
+		}
+		if !slices.Equal(conflicts, []string{"devices[Renamed]"}) {
+			t.Errorf("conflicts %q, want the renamed device", conflicts)
+		}
+	})
+
+	t.Run("empty base", func(t *testing.T) {
+		// A new config saves no actions as null and no devices as []
+		a, b := twoWriters(t, &Config{})
+		a.Devices = append(a.Devices, DeviceConfig{ID: "a", Name: "A"})
+		a.Actions = append(a.Actions, actions.Action{ID: "act-a", Name: "A"})
+		b.Devices = append(b.Devices, DeviceConfig{ID: "b", Name: "B"})
+		b.Actions = append(b.Actions, actions.Action{ID: "act-b", Name: "B"})
+		var conflicts []string
+		b.SetMergeHook(func(c []string) { conflicts = c })
+		if err := a.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Save(); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := deviceIDs(saved); !slices.Equal(ids, []string{"b", "a"}) {
+			t.Errorf("saved devices %q, want b and a", ids)
+		}
+		var actionIDs []string
+		for _, act := range saved.Actions {
+			actionIDs = append(actionIDs, act.ID)
+		}
+		if !slices.Equal(actionIDs, []string{"act-b", "act-a"}) {
+			t.Errorf("saved actions %q, want act-b and act-a", actionIDs)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("conflicts %q, want none", conflicts)
+		}
+	})
+}
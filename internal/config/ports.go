@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrAmbiguousPort is matched by the errors of port patterns that match several ports
+var ErrAmbiguousPort = errors.New("port pattern is ambiguous")
+
+// MatchPorts returns the ports a port pattern matches: a regular expression between
+// slashes, otherwise a substring, both ignoring case. An empty pattern matches nothing.
+func MatchPorts(pattern string, ports []string) ([]string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, nil
+	}
+	match := func(port string) bool { return strings.Contains(strings.ToLower(port), strings.ToLower(pattern)) }
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("port pattern %s: %w", pattern, err)
+		}
+		match = re.MatchString
+	}
+	var matches []string
+	for _, port := range ports {
+		if match(port) {
+			matches = append(matches, port)
+		}
+	}
+	return matches, nil
+}
+
+// ResolvePort returns the port a device binds to among the listed ones: exact if it is
+// listed, otherwise the one port the pattern matches. Without a match it returns exact,
+// still missing; a pattern matching several ports is an ErrAmbiguousPort.
+func ResolvePort(exact, pattern string, ports []string) (string, error) {
+	if exact != "" && slices.Contains(ports, exact) {
+		return exact, nil
+	}
+	matches, err := MatchPorts(pattern, ports)
+	switch {
+	case err != nil:
+		return exact, err
+	case len(matches) > 1:
+		return exact, fmt.Errorf("%w: %s matches '%s'", ErrAmbiguousPort, pattern, strings.Join(matches, "', '"))
+	case len(matches) == 1:
+		return matches[0], nil
+	}
+	return exact, nil
+}
+
+// PortBinding is a device port bound by its pattern in place of the exact name
+type PortBinding struct {
+	DeviceID   string
+	DeviceName string
+	IsInput    bool
+	From, To   string
+}
+
+// BindPorts binds the ports of enabled devices that aren't listed to the ones their
+// patterns match, recording the bound names as the devices' ports. It returns the
+// bindings made and the problems of patterns that couldn't be resolved.
+func (c *Config) BindPorts(inPorts, outPorts []string) ([]PortBinding, error) {
+	var bound []PortBinding
+	var problems []error
+	for i := range c.Devices {
+		device := &c.Devices[i]
+		if device.Disabled {
+			continue
+		}
+		for _, p := range []struct {
+			port    *string
+			pattern string
+			ports   []string
+			isInput bool
+		}{
+			{&device.InPort, device.InPortMatch, inPorts, true},
+			{&device.OutPort, device.OutPortMatch, outPorts, false},
+		} {
+			resolved, err := ResolvePort(*p.port, p.pattern, p.ports)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", device.Name, err))
+				continue
+			}
+			if resolved != *p.port {
+				bound = append(bound, PortBinding{DeviceID: device.ID, DeviceName: device.Name, IsInput: p.isInput, From: *p.port, To: resolved})
+				*p.port = resolved
+			}
+		}
+	}
+	return bound, errors.Join(problems...)
+}
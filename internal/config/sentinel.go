@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// initSentinelName is the file that exists while devices are being initialized. Finding
+// it at startup means the last session crashed or hung during initialization.
+const initSentinelName = "initializing"
+
+// initSentinelPath returns the full path to the device initialization sentinel
+func initSentinelPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, initSentinelName), nil
+}
+
+// BeginDeviceInit marks device initialization as in progress until EndDeviceInit
+func BeginDeviceInit() error {
+	path, err := initSentinelPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)+"\n"), 0644)
+}
+
+// EndDeviceInit marks device initialization as finished
+func EndDeviceInit() error {
+	path, err := initSentinelPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DeviceInitInterrupted reports whether an earlier device initialization never finished
+func DeviceInitInterrupted() bool {
+	path, err := initSentinelPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestDeviceInitSentinel(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if DeviceInitInterrupted() {
+		t.Fatal("interrupted before any initialization")
+	}
+	if err := BeginDeviceInit(); err != nil {
+		t.Fatal(err)
+	}
+	if !DeviceInitInterrupted() {
+		t.Error("not interrupted while initializing, as after a crash")
+	}
+	if err := EndDeviceInit(); err != nil {
+		t.Fatal(err)
+	}
+	if DeviceInitInterrupted() {
+		t.Error("interrupted after initialization finished")
+	}
+	if err := EndDeviceInit(); err != nil {
+		t.Errorf("ending again: %v", err)
+	}
+}
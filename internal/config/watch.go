@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long the config file has to stay quiet after a change before it
+// is read, so a file still being written isn't mistaken for an edit
+const watchSettle = 300 * time.Millisecond
+
+// The content of the config file as this process last saved or accepted it. Changes
+// to the file that leave it like this are the app's own saves.
+var (
+	knownMu   sync.Mutex
+	knownHash [sha256.Size]byte
+)
+
+// setKnown records data as the config file content the app knows about
+func setKnown(data []byte) {
+	knownMu.Lock()
+	knownHash = sha256.Sum256(data)
+	knownMu.Unlock()
+}
+
+// Watch calls onChange, on its own goroutine, when the config file is changed by
+// something other than Save, e.g. by hand or a sync tool. The file content at the time
+// of the call counts as known, so declining to reload doesn't prompt again for it.
+// The directory is watched rather than the file, so editors that replace the file
+// are noticed too.
+func Watch(onChange func()) (stop func(), err error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		setKnown(data)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		var settle *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				if settle != nil {
+					settle.Stop()
+				}
+				settle = time.AfterFunc(watchSettle, func() {
+					if changedOnDisk(path) {
+						onChange()
+					}
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("Config file watcher error", "err", err)
+			}
+		}
+	}()
+	return func() { watcher.Close() }, nil
+}
+
+// changedOnDisk reports whether the config file differs from the content the app
+// knows about, and if so, records the new content as known
+func changedOnDisk(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false // Removed or mid-replace; a following event brings the new file
+	}
+	hash := sha256.Sum256(data)
+
+	knownMu.Lock()
+	defer knownMu.Unlock()
+	if hash == knownHash {
+		return false
+	}
+	knownHash = hash
+	return true
+}
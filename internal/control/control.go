@@ -0,0 +1,204 @@
+package control
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Commands understood by the control channel
+const (
+	CmdRun         = "run"          // Args: action or group name/ID
+	CmdListActions = "list-actions" // No args
+	CmdSwitchMenu  = "switch-menu"  // Args: device or group name/ID, menu name/ID
+	CmdShow        = "show"         // No args; brings the main window to the front
+)
+
+var (
+	// ErrNotRunning is returned by Send when no instance is listening
+	ErrNotRunning = errors.New("gopher-automate is not running")
+
+	// ErrAlreadyRunning is returned by Claim when another instance owns the endpoint
+	ErrAlreadyRunning = errors.New("gopher-automate is already running")
+)
+
+// requestTimeout is how long a connection may take to send its request before it is
+// dropped, so idle clients can't hold on to the server
+var requestTimeout = 5 * time.Second
+
+// Request is one command sent to a running instance, encoded as a single JSON line
+type Request struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Token   string   `json:"token,omitempty"` // Set by Send where the endpoint needs one, see listen
+}
+
+// Response is the reply to a Request, encoded as a single JSON line
+type Response struct {
+	OK     bool     `json:"ok"`
+	Error  string   `json:"error,omitempty"`
+	Output []string `json:"output,omitempty"`
+}
+
+// Handler performs control requests in the running instance
+type Handler interface {
+	RunNamed(ref string) error
+	ActionNames() []string
+	SwitchMenuNamed(target, menu string) error
+}
+
+// WindowHandler is implemented by handlers that have a window to show
+type WindowHandler interface {
+	ShowWindow()
+}
+
+// Server accepts control connections until it is closed
+type Server struct {
+	listener net.Listener
+	handler  Handler
+	cleanup  func()
+	token    string // Requests must carry it, unless empty
+	wg       sync.WaitGroup
+}
+
+// Claim takes ownership of the platform's control endpoint without serving it yet.
+// Returns ErrAlreadyRunning if a live instance answers on it; endpoints left behind
+// by a crashed instance are reclaimed. Connections made before Serve wait in the backlog.
+func Claim() (*Server, error) {
+	listener, token, cleanup, err := listen()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open control channel: %w", err)
+	}
+	return &Server{listener: listener, cleanup: cleanup, token: token}, nil
+}
+
+// Serve starts answering requests with the given handler
+func (s *Server) Serve(handler Handler) {
+	s.handler = handler
+	s.wg.Add(1)
+	go s.acceptLoop()
+}
+
+// Close stops accepting requests and removes the endpoint
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	s.cleanup()
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("Control channel stopped", "err", err)
+			}
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+// serve answers a single request on a connection
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(requestTimeout)); err != nil {
+		slog.Warn("Failed to set control request deadline", "err", err)
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		writeResponse(conn, Response{Error: "invalid request: " + err.Error()})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(s.token)) != 1 {
+		slog.Warn("Control request rejected: wrong token")
+		writeResponse(conn, Response{Error: "unauthorized"})
+		return
+	}
+	slog.Info("Control request", "request", describe(req))
+	writeResponse(conn, s.handle(req))
+}
+
+// handle dispatches a request to the handler
+func (s *Server) handle(req Request) Response {
+	switch req.Command {
+	case CmdRun:
+		if len(req.Args) != 1 {
+			return Response{Error: "usage: run <action>"}
+		}
+		if err := s.handler.RunNamed(req.Args[0]); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true}
+
+	case CmdListActions:
+		return Response{OK: true, Output: s.handler.ActionNames()}
+
+	case CmdSwitchMenu:
+		if len(req.Args) != 2 {
+			return Response{Error: "usage: switch-menu <device> <menu>"}
+		}
+		if err := s.handler.SwitchMenuNamed(req.Args[0], req.Args[1]); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true}
+
+	case CmdShow:
+		w, ok := s.handler.(WindowHandler)
+		if !ok {
+			return Response{Error: "running without a window"}
+		}
+		w.ShowWindow()
+		return Response{OK: true}
+
+	default:
+		return Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+}
+
+// writeResponse encodes a response as a single JSON line
+func writeResponse(conn net.Conn, resp Response) {
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		slog.Warn("Failed to write control response", "err", err)
+	}
+}
+
+// Send delivers a request to the running instance and returns its response.
+// Returns ErrNotRunning if nothing is listening.
+func Send(req Request) (Response, error) {
+	conn, token, err := dial()
+	if err != nil {
+		return Response{}, ErrNotRunning
+	}
+	defer conn.Close()
+
+	req.Token = token
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return Response{}, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !resp.OK && resp.Error == "" {
+		resp.Error = "request failed"
+	}
+	return resp, nil
+}
+
+// describe formats a request for logs
+func describe(req Request) string {
+	return strings.TrimSpace(req.Command + " " + strings.Join(req.Args, " "))
+}
@@ -0,0 +1,163 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeHandler records what the server asked of it
+type fakeHandler struct {
+	ran      []string
+	switched [][2]string
+	fail     error
+}
+
+func (h *fakeHandler) RunNamed(ref string) error {
+	h.ran = append(h.ran, ref)
+	return h.fail
+}
+
+func (h *fakeHandler) ActionNames() []string { return []string{"Toggle Mute", "Lights Off"} }
+
+func (h *fakeHandler) SwitchMenuNamed(target, menu string) error {
+	h.switched = append(h.switched, [2]string{target, menu})
+	return h.fail
+}
+
+// serveFake serves handler on a localhost listener requiring token, standing in for
+// the platform endpoint
+func serveFake(t *testing.T, handler Handler, token string) net.Addr {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{listener: listener, cleanup: func() {}, token: token}
+	s.Serve(handler)
+	t.Cleanup(func() { s.Close() })
+	return listener.Addr()
+}
+
+// roundTrip sends one request line and reads the response line
+func roundTrip(t *testing.T, addr net.Addr, req Request) Response {
+	t.Helper()
+	conn, err := net.Dial(addr.Network(), addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		t.Fatal(err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestServerCommands(t *testing.T) {
+	handler := &fakeHandler{}
+	addr := serveFake(t, handler, "")
+
+	tests := []struct {
+		name string
+		req  Request
+		want Response
+	}{
+		{"run", Request{Command: CmdRun, Args: []string{"Toggle Mute"}}, Response{OK: true}},
+		{"run without action", Request{Command: CmdRun}, Response{Error: "usage: run <action>"}},
+		{"list", Request{Command: CmdListActions}, Response{OK: true, Output: []string{"Toggle Mute", "Lights Off"}}},
+		{"switch", Request{Command: CmdSwitchMenu, Args: []string{"Launchpad", "Editing"}}, Response{OK: true}},
+		{"switch without menu", Request{Command: CmdSwitchMenu, Args: []string{"Launchpad"}}, Response{Error: "usage: switch-menu <device> <menu>"}},
+		{"show without window", Request{Command: CmdShow}, Response{Error: "running without a window"}},
+		{"unknown", Request{Command: "reboot"}, Response{Error: `unknown command "reboot"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundTrip(t, addr, tt.req)
+			if got.OK != tt.want.OK || got.Error != tt.want.Error || !slices.Equal(got.Output, tt.want.Output) {
+				t.Errorf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if !slices.Equal(handler.ran, []string{"Toggle Mute"}) {
+		t.Errorf("ran %q, want the one valid run", handler.ran)
+	}
+	if !slices.Equal(handler.switched, [][2]string{{"Launchpad", "Editing"}}) {
+		t.Errorf("switched %q, want the one valid switch", handler.switched)
+	}
+}
+
+func TestServerReportsHandlerErrors(t *testing.T) {
+	addr := serveFake(t, &fakeHandler{fail: errors.New(`no action named "Nope"`)}, "")
+	got := roundTrip(t, addr, Request{Command: CmdRun, Args: []string{"Nope"}})
+	if got.OK || got.Error != `no action named "Nope"` {
+		t.Errorf("response = %+v, want the handler's error", got)
+	}
+}
+
+func TestServerRejectsWrongToken(t *testing.T) {
+	handler := &fakeHandler{}
+	addr := serveFake(t, handler, "secret")
+
+	for _, token := range []string{"", "wrong", "secret-but-longer"} {
+		got := roundTrip(t, addr, Request{Command: CmdRun, Args: []string{"Toggle Mute"}, Token: token})
+		if got.OK || got.Error != "unauthorized" {
+			t.Errorf("token %q: response = %+v, want unauthorized", token, got)
+		}
+	}
+	if len(handler.ran) > 0 {
+		t.Fatalf("ran %q without the token", handler.ran)
+	}
+
+	if got := roundTrip(t, addr, Request{Command: CmdRun, Args: []string{"Toggle Mute"}, Token: "secret"}); !got.OK {
+		t.Errorf("response with the token = %+v, want OK", got)
+	}
+}
+
+func TestServerRejectsInvalidRequest(t *testing.T) {
+	addr := serveFake(t, &fakeHandler{}, "")
+	conn, err := net.Dial(addr.Network(), addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("not json\n"))
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.OK {
+		t.Errorf("response = %+v, want an error", resp)
+	}
+}
+
+func TestServerDropsIdleClients(t *testing.T) {
+	defer func(timeout time.Duration) { requestTimeout = timeout }(requestTimeout)
+	requestTimeout = 50 * time.Millisecond
+
+	addr := serveFake(t, &fakeHandler{}, "")
+	conn, err := net.Dial(addr.Network(), addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Send nothing; the server should give up and close the connection
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("connection not answered: %v", err)
+	}
+	var resp Response
+	if err := json.Unmarshal([]byte(line), &resp); err != nil || resp.OK {
+		t.Errorf("idle client got %q, want an error response", line)
+	}
+}
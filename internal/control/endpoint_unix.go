@@ -0,0 +1,56 @@
+//go:build !windows
+
+package control
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// socketPath returns the control socket, which lives beside the config so only the
+// user who owns the config can reach it
+func socketPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "control.sock"), nil
+}
+
+// listen opens the control socket. The socket's permissions keep other users out, so
+// requests need no token.
+func listen() (listener net.Listener, token string, cleanup func(), err error) {
+	path, err := socketPath()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// A socket left behind by a crashed instance would block the bind; a live one answers
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, "", nil, ErrAlreadyRunning
+	}
+	_ = os.Remove(path)
+
+	listener, err = net.Listen("unix", path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, "", nil, err
+	}
+	return listener, "", func() { _ = os.Remove(path) }, nil
+}
+
+func dial() (net.Conn, string, error) {
+	path, err := socketPath()
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := net.Dial("unix", path)
+	return conn, "", err
+}
@@ -0,0 +1,91 @@
+//go:build !windows
+
+package control
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// useTempConfigDir points the config directory, and so the control socket, at a
+// temporary directory. Unix socket paths are short, so it is made under /tmp.
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir, err := os.MkdirTemp("/tmp", "ga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+}
+
+func TestSendThroughSocket(t *testing.T) {
+	useTempConfigDir(t)
+	if _, err := Send(Request{Command: CmdListActions}); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Send with nothing listening = %v, want ErrNotRunning", err)
+	}
+
+	server, err := Claim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := &fakeHandler{}
+	server.Serve(handler)
+
+	resp, err := Send(Request{Command: CmdRun, Args: []string{"Toggle Mute"}})
+	if err != nil || !resp.OK {
+		t.Fatalf("Send = %+v, %v; want OK", resp, err)
+	}
+	if len(handler.ran) != 1 || handler.ran[0] != "Toggle Mute" {
+		t.Errorf("ran %q, want Toggle Mute", handler.ran)
+	}
+
+	path, err := socketPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	if _, err := Claim(); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second Claim = %v, want ErrAlreadyRunning", err)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket still there after Close: %v", err)
+	}
+	if _, err := Send(Request{Command: CmdListActions}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Send after Close = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestClaimReclaimsStaleSocket(t *testing.T) {
+	useTempConfigDir(t)
+	path, err := socketPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0600); err != nil { // Left by a crashed instance
+		t.Fatal(err)
+	}
+
+	server, err := Claim()
+	if err != nil {
+		t.Fatalf("Claim over a stale socket: %v", err)
+	}
+	server.Serve(&fakeHandler{})
+	defer server.Close()
+	if resp, err := Send(Request{Command: CmdListActions}); err != nil || !resp.OK {
+		t.Errorf("Send = %+v, %v; want OK", resp, err)
+	}
+}
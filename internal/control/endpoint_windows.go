@@ -0,0 +1,113 @@
+//go:build windows
+
+package control
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"golang.org/x/sys/windows"
+)
+
+// addrPath returns the file holding the control channel's localhost address and token.
+// The file lives beside the config and only its owner may read it, see restrictToUser.
+func addrPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "control.addr"), nil
+}
+
+// listen opens the control channel on a localhost port. Any local process can connect
+// to it, so requests must carry a random token that only the file tells.
+func listen() (listener net.Listener, token string, cleanup func(), err error) {
+	path, err := addrPath()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// The address file outlives a crashed instance; only a live one answers on it
+	if conn, _, err := dial(); err == nil {
+		conn.Close()
+		return nil, "", nil, ErrAlreadyRunning
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", nil, err
+	}
+	token = hex.EncodeToString(secret)
+
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if err := writeUserOnly(path, listener.Addr().String()+"\n"+token+"\n"); err != nil {
+		listener.Close()
+		return nil, "", nil, err
+	}
+	return listener, token, func() { _ = os.Remove(path) }, nil
+}
+
+func dial() (net.Conn, string, error) {
+	path, err := addrPath()
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	addr, token, ok := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if !ok {
+		return nil, "", errors.New("control address file has no token")
+	}
+	conn, err := net.Dial("tcp", strings.TrimSpace(addr))
+	return conn, strings.TrimSpace(token), err
+}
+
+// writeUserOnly replaces a file with one only the current user can open, then writes it
+func writeUserOnly(path, content string) error {
+	_ = os.Remove(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := restrictToUser(path); err != nil {
+		return err
+	}
+	_, err = f.WriteString(content)
+	return err
+}
+
+// restrictToUser replaces a file's ACL with one granting the current user alone
+// full access, without entries inherited from the folder
+func restrictToUser(path string) error {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return err
+	}
+	acl, err := windows.ACLFromEntries([]windows.EXPLICIT_ACCESS{{
+		AccessPermissions: windows.GENERIC_ALL,
+		AccessMode:        windows.SET_ACCESS,
+		Inheritance:       windows.NO_INHERITANCE,
+		Trustee: windows.TRUSTEE{
+			TrusteeForm:  windows.TRUSTEE_IS_SID,
+			TrusteeType:  windows.TRUSTEE_IS_USER,
+			TrusteeValue: windows.TrusteeValueFromSID(user.User.Sid),
+		},
+	}}, nil)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, acl, nil)
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// BudgetError is what a group run reports when a group ran out of its time budget
+type BudgetError struct {
+	Group  string // Name of the group whose budget ran out
+	Limit  time.Duration
+	Action string // Action that was running then, empty if the budget ran out between actions
+}
+
+func (e *BudgetError) Error() string {
+	if e.Action == "" {
+		return fmt.Sprintf("%s ran out of its %s time budget", e.Group, e.Limit)
+	}
+	return fmt.Sprintf("%s ran out of its %s time budget while running %s", e.Group, e.Limit, e.Action)
+}
+
+// groupBudget is the time budget of a group an action of a run is nested in
+type groupBudget struct {
+	groupID string
+	name    string
+	limit   time.Duration
+}
+
+// stepBudgets returns, for each action of a run of the group top, the budgets of the
+// groups it is nested in up to top. Groups above top don't count, since they aren't
+// being run. It returns nil if top is a lone action or no group has a budget.
+func (e *Engine) stepBudgets(top string, steps []actions.Action) [][]groupBudget {
+	if e.actionStore.GetGroup(top) == nil {
+		return nil // Not run as part of its groups, so none of them count
+	}
+	budgets := make([][]groupBudget, len(steps))
+	found := false
+	for i, step := range steps {
+		for id := step.ParentGroupID; id != ""; {
+			group := e.actionStore.GetGroup(id)
+			if group == nil {
+				break
+			}
+			if group.TimeBudgetSeconds > 0 {
+				budgets[i] = append(budgets[i], groupBudget{groupID: group.ID, name: group.Name, limit: time.Duration(group.TimeBudgetSeconds) * time.Second})
+				found = true
+			}
+			if id == top {
+				break
+			}
+			id = group.ParentGroupID
+		}
+	}
+	if !found {
+		return nil
+	}
+	return budgets
+}
+
+// budgetDeadline starts the clock of each budget in budgets that isn't running yet, so
+// a group's budget counts from its first action, and returns the earliest deadline
+// with its budget, which is nil if there are no budgets
+func budgetDeadline(budgets []groupBudget, started map[string]time.Time) (time.Time, *groupBudget) {
+	var deadline time.Time
+	var tightest *groupBudget
+	for i, b := range budgets {
+		if _, ok := started[b.groupID]; !ok {
+			started[b.groupID] = time.Now()
+		}
+		if d := started[b.groupID].Add(b.limit); tightest == nil || d.Before(deadline) {
+			deadline, tightest = d, &budgets[i]
+		}
+	}
+	return deadline, tightest
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// actionTypeSlow runs for the number of milliseconds in its code, or until cancelled
+const actionTypeSlow actions.ActionType = "engine_test_slow"
+
+type slowHandler struct{}
+
+func (slowHandler) Execute(code string) (string, error) {
+	return slowHandler{}.ExecuteContext(context.Background(), code)
+}
+
+func (slowHandler) ExecuteContext(ctx context.Context, code string) (string, error) {
+	ms, err := strconv.Atoi(code)
+	if err != nil {
+		return "", err
+	}
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (slowHandler) Validate(string) error { return nil }
+func (slowHandler) IsSupported() bool     { return true }
+
+func init() {
+	actions.RegisterHandler(actionTypeSlow, actions.HandlerMeta{Name: "Slow"},
+		func(actions.Deps) actions.ActionHandler { return slowHandler{} })
+}
+
+// slowStep is an action in a group that takes ms milliseconds, waited for by the next one
+func slowStep(name, group string, order, ms int) actions.Action {
+	return actions.Action{ID: name, Name: name, Type: actionTypeSlow, Code: strconv.Itoa(ms),
+		ParentGroupID: group, Order: order, WaitForCompletion: true}
+}
+
+// runGroup runs a group the way the Test button does and returns the events of the
+// actions that finished, by name, and what the run reported
+func runGroup(t *testing.T, r *testRig, id string) (map[string]StepEvent, error) {
+	t.Helper()
+	var mu sync.Mutex
+	finished := map[string]StepEvent{}
+	result := make(chan error, 1)
+	r.do(func() {
+		r.e.TestGroup(id, nil, func(event StepEvent) {
+			if event.Finished {
+				mu.Lock()
+				finished[event.Name] = event
+				mu.Unlock()
+			}
+		}, func(err error) { result <- err })
+	})
+	select {
+	case err := <-result:
+		mu.Lock()
+		defer mu.Unlock()
+		return finished, err
+	case <-time.After(10 * time.Second):
+		t.Fatal("group run didn't finish")
+		return nil, nil
+	}
+}
+
+func TestGroupTimeBudget(t *testing.T) {
+	r := newRig(t, testConfig())
+	r.do(func() {
+		store := r.e.actionStore
+		store.Groups = append(store.Groups, actions.ActionGroup{ID: "startup", Name: "Startup", TimeBudgetSeconds: 1})
+		store.Actions = append(store.Actions,
+			slowStep("fast", "startup", 0, 10),
+			slowStep("slow", "startup", 1, 5000),
+			slowStep("after", "startup", 2, 10))
+	})
+
+	start := time.Now()
+	finished, err := runGroup(t, r, "startup")
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("run took %s with a 1s budget", elapsed)
+	}
+
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Group != "Startup" || budgetErr.Action != "slow" || budgetErr.Limit != time.Second {
+		t.Fatalf("run reported %v, want Startup out of budget while running slow", err)
+	}
+	if e, ok := finished["fast"]; !ok || e.Err != nil {
+		t.Errorf("fast = %+v, want finished without error", e)
+	}
+	if e, ok := finished["slow"]; !ok || !errors.Is(e.Err, context.DeadlineExceeded) {
+		t.Errorf("slow = %+v, want cut off at the deadline", e)
+	}
+	if _, ok := finished["after"]; ok {
+		t.Error("action after the budget ran out still ran")
+	}
+	if s := r.e.Status().Snapshot(); s.LastActionName != "Startup" {
+		t.Errorf("status names %q, want the group out of budget", s.LastActionName)
+	}
+}
+
+// TestNestedGroupTimeBudget checks that a nested group's actions are held to the
+// tighter budget of the group around it
+func TestNestedGroupTimeBudget(t *testing.T) {
+	r := newRig(t, testConfig())
+	r.do(func() {
+		store := r.e.actionStore
+		store.Groups = append(store.Groups,
+			actions.ActionGroup{ID: "outer", Name: "Outer", TimeBudgetSeconds: 1},
+			actions.ActionGroup{ID: "inner", Name: "Inner", ParentGroupID: "outer", Order: 0, TimeBudgetSeconds: 30})
+		store.Actions = append(store.Actions, slowStep("slow", "inner", 0, 5000))
+	})
+
+	start := time.Now()
+	finished, err := runGroup(t, r, "outer")
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("run took %s with a 1s budget around it", elapsed)
+	}
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Group != "Outer" || budgetErr.Action != "slow" {
+		t.Errorf("run reported %v, want Outer out of budget while running slow", err)
+	}
+	if _, ok := finished["slow"]; !ok {
+		t.Error("no finish reported for the action cut off")
+	}
+}
+
+// TestGroupWithinBudget checks that a group finishing in time reports nothing
+func TestGroupWithinBudget(t *testing.T) {
+	r := newRig(t, testConfig())
+	r.do(func() {
+		store := r.e.actionStore
+		store.Groups = append(store.Groups, actions.ActionGroup{ID: "quick", Name: "Quick", TimeBudgetSeconds: 5})
+		store.Actions = append(store.Actions, slowStep("a", "quick", 0, 10), slowStep("b", "quick", 1, 10))
+	})
+
+	finished, err := runGroup(t, r, "quick")
+	if err != nil {
+		t.Errorf("run reported %v", err)
+	}
+	if len(finished) != 2 {
+		t.Errorf("finished %v, want both actions", finished)
+	}
+}
+
+// TestLoneActionIgnoresGroupBudget checks that an action run on its own isn't held to
+// the budget of the group it is in
+func TestLoneActionIgnoresGroupBudget(t *testing.T) {
+	r := newRig(t, testConfig())
+	result := make(chan error, 1)
+	r.do(func() {
+		store := r.e.actionStore
+		store.Groups = append(store.Groups, actions.ActionGroup{ID: "group", Name: "G", TimeBudgetSeconds: 1})
+		store.Actions = append(store.Actions, slowStep("solo", "group", 0, 1500))
+		r.e.run("solo", "", func(err error) { result <- err })
+	})
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("run reported %v, want none", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("run didn't finish")
+	}
+}
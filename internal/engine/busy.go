@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// busyFlashDuration is how long a pad flashes green or red when its action finishes,
+// or yellow when it was skipped by its cooldown
+const busyFlashDuration = 500 * time.Millisecond
+
+// Completion flash colors
+var (
+	busySucceeded = midi.PadColor{G: 127}
+	busyFailed    = midi.PadColor{R: 127}
+	busyRejected  = midi.PadColor{R: 127, G: 127}
+)
+
+// padFlash is a completion flash a pad is showing
+type padFlash struct {
+	generation int // Latest flash, so an older one doesn't end it early
+	color      midi.PadColor
+}
+
+// busyPad is a pad of a layout that can show its action running
+type busyPad struct {
+	menuID   string
+	row, col int
+}
+
+// runFromPad runs a pad's action. With busy feedback on, the pad shows its busy color
+// until everything the action started has finished, then flashes the outcome.
+func (e *Engine) runFromPad(pad busyPad, padColor config.PadColorConfig) {
+	if !e.cfg.BusyFeedback.Enabled {
+		var done func(error)
+		if e.cfg.BusyFeedback.FlashRejected {
+			done = func(err error) {
+				if isCooldown(err) {
+					e.dispatch(func() { e.flashPad(pad, busyRejected) })
+				}
+			}
+		}
+		e.run(padColor.ActionID, padColor.ActionArgs, done)
+		return
+	}
+
+	// Counted first: with an inline dispatcher a quick action could finish before run returns
+	e.busy[pad]++
+	if !e.run(padColor.ActionID, padColor.ActionArgs, func(err error) {
+		e.dispatch(func() { e.finishBusy(pad, err) })
+	}) {
+		e.busy[pad]--
+		if e.busy[pad] <= 0 {
+			delete(e.busy, pad)
+		}
+	}
+	// The busy color shows once the pad is released, which replaces the pressed color
+}
+
+// finishBusy ends one run started from the pad. When it was the last, the pad flashes
+// the outcome and then shows its own color again.
+func (e *Engine) finishBusy(pad busyPad, err error) {
+	if e.busy[pad]--; e.busy[pad] > 0 {
+		return // Pressed again meanwhile, still busy
+	}
+	delete(e.busy, pad)
+
+	flash := busySucceeded
+	switch {
+	case isCooldown(err) && !e.cfg.BusyFeedback.FlashRejected:
+		e.showRestingColor(pad)
+		return
+	case isCooldown(err):
+		flash = busyRejected
+	case err != nil:
+		flash = busyFailed
+	}
+	e.flashPad(pad, flash)
+}
+
+// flashPad lights a pad in color for busyFlashDuration, then shows its own color again
+func (e *Engine) flashPad(pad busyPad, color midi.PadColor) {
+	e.flashPadFor(pad, color, busyFlashDuration)
+}
+
+// flashPadFor lights a pad in color for d, then shows its own color again
+func (e *Engine) flashPadFor(pad busyPad, color midi.PadColor, d time.Duration) {
+	generation := e.busyFlash[pad].generation + 1
+	e.busyFlash[pad] = padFlash{generation: generation, color: color}
+	e.sendPadFeedback(pad.menuID, pad.row, pad.col, "", func(midi.DeviceType) midi.PadColor { return color })
+
+	time.AfterFunc(d, func() {
+		e.dispatch(func() {
+			if e.busyFlash[pad].generation != generation {
+				return // A later flash owns the pad
+			}
+			delete(e.busyFlash, pad)
+			e.showRestingColor(pad)
+		})
+	})
+}
+
+// showRestingColor sends a pad its resting color, see restingColor
+func (e *Engine) showRestingColor(pad busyPad) {
+	if menu := e.cfg.GetMenu(pad.menuID); menu != nil {
+		padColor := menu.EffectiveColor(pad.row, pad.col)
+		e.sendPadFeedback(pad.menuID, pad.row, pad.col, "", func(deviceType midi.DeviceType) midi.PadColor {
+			return e.restingColor(deviceType, pad, padColor)
+		})
+	}
+}
+
+// restingColor is the color a pad shows when not pressed: the color it is flashing,
+// its busy color while its action runs, its own color otherwise
+func (e *Engine) restingColor(deviceType midi.DeviceType, pad busyPad, padColor config.PadColorConfig) midi.PadColor {
+	if flash, ok := e.busyFlash[pad]; ok {
+		return flash.color
+	}
+	if e.busy[pad] > 0 {
+		return e.busyColor(deviceType, padColor)
+	}
+	return staticColor(deviceType, padColor)
+}
+
+// busyColor is the pad's busy color, reduced to red/green levels for classic devices
+func (e *Engine) busyColor(deviceType midi.DeviceType, padColor config.PadColorConfig) midi.PadColor {
+	r, g, b := padColor.BusyColor(e.cfg.BusyFeedback)
+	if deviceType == midi.DeviceTypeClassic {
+		red, green := padcolor.ClassicLevels(r, g, b)
+		return midi.PadColor{R: padcolor.LevelValue(red), G: padcolor.LevelValue(green)}
+	}
+	return midi.PadColor{R: r, G: g, B: b}
+}
+
+// applyBusyPads keeps busy pads lit in their busy color when a whole layout is sent
+func (e *Engine) applyBusyPads(device config.DeviceConfig, menu *config.MenuLayout, colors *[9][9]midi.PadColor) {
+	if !device.SendPressedFeedback {
+		return
+	}
+	deviceType := midi.DeviceType(device.Type)
+	for pad := range e.busy {
+		if pad.menuID == menu.ID {
+			colors[pad.row][pad.col] = e.busyColor(deviceType, menu.EffectiveColor(pad.row, pad.col)).Scaled(e.brightnessOf(device))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	gomidi "gitlab.com/gomidi/midi/v2"
+)
+
+// TestPressesWhileEditing presses pads from several listener goroutines while the
+// editor changes the same layout, as MIDI input and the menu editor do in the app.
+// It is meant for go test -race: input reaches the config only through the dispatcher.
+func TestPressesWhileEditing(t *testing.T) {
+	cfg := testConfig(colorfulDevice(), classicDevice())
+	cfg.PadDebounceMs = 0
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+
+	const presses = 200
+	var wg sync.WaitGroup
+	for i, port := range []string{colorfulIn, colorfulIn, classicIn, classicIn} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range presses {
+				row, col := 1+(n+i)%8, (n*3+i)%8
+				note := uint8((8-row)*10 + col + 11)
+				if port == classicIn {
+					note = uint8((row-1)*16 + col)
+				}
+				velocity := uint8(0)
+				if n%2 == 0 {
+					velocity = 127
+				}
+				r.fake.Inject(port, gomidi.NoteOn(0, note, velocity)) // The listener dispatches the press
+			}
+		}()
+	}
+
+	// The editor, meanwhile: recolors and rebinds pads of the layout being pressed,
+	// adds and removes layouts (moving Menus), and applies the saved layout
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := range presses {
+			r.do(func() {
+				menu := r.cfg.GetMenu("main")
+				pad := &menu.Colors[1+n%8][n%8]
+				pad.R, pad.PressedG = uint8(n%128), uint8((n*7)%128)
+				if n%3 == 0 {
+					pad.ActionID = ""
+				} else {
+					pad.ActionID = "note"
+				}
+				if n%10 == 0 {
+					extra := config.NewMenuLayout()
+					r.cfg.Menus = append(slices.Clone(r.cfg.Menus), extra)
+				} else if len(r.cfg.Menus) > 1 {
+					r.cfg.Menus = slices.Clone(r.cfg.Menus[:1])
+				}
+				r.e.QueueLayoutSends()
+			})
+		}
+	}()
+	wg.Wait()
+	r.do(r.e.FlushDeviceSends)
+	r.do(r.e.flushFeedback)
+
+	// Still working: a press lights the pad in the pressed color it has now
+	r.do(func() {
+		pad := &r.cfg.GetMenu("main").Colors[1][1]
+		pad.PressedG, pad.PressedR, pad.PressedB, pad.ActionID = 127, 0, 0, ""
+	})
+	if got := r.padFeedback(1, 1, true); got != "F0002029020D030352007F00F7\n" {
+		t.Errorf("press after the edits sent %q, want pad 1,1 green", got)
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// confirmWindow is how long a pad stays armed: a second press within it runs the
+// action, see pressConfirmed
+const confirmWindow = 3 * time.Second
+
+// armedColor is what an armed pad shows, blinking on devices that blink by themselves
+var armedColor = midi.PadColor{R: 127, Flash: true}
+
+// armedPad is the pad of a device waiting for the press that confirms its action
+type armedPad struct {
+	key padKey
+	pad busyPad
+	at  time.Time
+}
+
+// needsConfirmation reports whether an action, or any action of a group, only runs
+// from pads when pressed twice
+func (e *Engine) needsConfirmation(id string) bool {
+	if action := e.actionStore.GetAction(id); action != nil {
+		return action.RequireConfirmation
+	}
+	if group := e.actionStore.GetGroup(id); group != nil {
+		for _, action := range e.groupActions(group) {
+			if action.RequireConfirmation {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pressConfirmed handles a press of a pad whose action needs confirmation. The first
+// press arms the pad, lighting it in armedColor for confirmWindow; it reports true for
+// a second press within that window, which should run the action.
+func (e *Engine) pressConfirmed(key padKey, pad busyPad) bool {
+	now := e.now()
+	if armed, ok := e.armed[key.deviceID]; ok && armed.key == key && armed.pad == pad && now.Sub(armed.at) < confirmWindow {
+		e.disarm(key.deviceID)
+		return true
+	}
+
+	e.disarm(key.deviceID)
+	e.armed[key.deviceID] = armedPad{key: key, pad: pad, at: now}
+	e.flashPadFor(pad, armedColor, confirmWindow)
+	time.AfterFunc(confirmWindow, func() {
+		e.dispatch(func() {
+			if armed, ok := e.armed[key.deviceID]; ok && armed.at.Equal(now) {
+				delete(e.armed, key.deviceID)
+			}
+		})
+	})
+	return false
+}
+
+// disarmOthers disarms the device's armed pad when a different pad is pressed
+func (e *Engine) disarmOthers(key padKey) {
+	if armed, ok := e.armed[key.deviceID]; ok && armed.key != key {
+		e.disarm(key.deviceID)
+	}
+}
+
+// disarm forgets the device's armed pad and shows the pad's own color again
+func (e *Engine) disarm(deviceID string) {
+	armed, ok := e.armed[deviceID]
+	if !ok {
+		return
+	}
+	delete(e.armed, deviceID)
+	if flash, ok := e.busyFlash[armed.pad]; ok && flash.color == armedColor {
+		delete(e.busyFlash, armed.pad)
+		e.showRestingColor(armed.pad)
+	}
+}
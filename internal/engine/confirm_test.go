@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+func TestConfirmationWindow(t *testing.T) {
+	type press struct {
+		ms       int // Since the first press
+		row, col int
+	}
+	tests := []struct {
+		name    string
+		presses []press
+		runs    int
+		armed   bool // Pad 1,1 still armed at the end
+	}{
+		{"first press only arms", []press{{0, 1, 1}}, 0, true},
+		{"second press within the window", []press{{0, 1, 1}, {2999, 1, 1}}, 1, false},
+		{"second press too late arms again", []press{{0, 1, 1}, {3000, 1, 1}}, 0, true},
+		{"late press then a quick one", []press{{0, 1, 1}, {3000, 1, 1}, {3500, 1, 1}}, 1, false},
+		{"other pad disarms", []press{{0, 1, 1}, {100, 2, 3}, {200, 1, 1}}, 0, true},
+		{"third press arms again", []press{{0, 1, 1}, {100, 1, 1}, {200, 1, 1}}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(colorfulDevice())
+			cfg.PadDebounceMs = 0
+			cfg.Actions[0].RequireConfirmation = true
+			r := newRig(t, cfg)
+			start := time.Now()
+			clock := &fakeClock{t: start}
+			r.e.now = clock.now
+			r.do(r.e.InitializeDevices)
+
+			for _, p := range tt.presses {
+				clock.set(p.ms, start)
+				r.do(func() {
+					r.e.handlePadPress("lpx", p.row, p.col, true, 127)
+					r.e.handlePadPress("lpx", p.row, p.col, false, 0)
+				})
+			}
+
+			r.waitSent(t, synthOut, tt.runs)
+			time.Sleep(20 * time.Millisecond) // Runs that shouldn't happen would by now
+			if got := len(r.fake.SentTo(synthOut)); got != tt.runs {
+				t.Errorf("action ran %d times, want %d", got, tt.runs)
+			}
+			var armed bool
+			r.do(func() {
+				a, ok := r.e.armed["lpx"]
+				armed = ok && a.key == (padKey{"lpx", 1, 1})
+			})
+			if armed != tt.armed {
+				t.Errorf("pad armed = %v, want %v", armed, tt.armed)
+			}
+		})
+	}
+}
+
+// TestArmedPadFeedback checks that an armed pad shows the armed color instead of its
+// pressed color, and its own color again once confirmed
+func TestArmedPadFeedback(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.PadDebounceMs = 0
+	cfg.Actions[0].RequireConfirmation = true
+	cfg.Menus[0].Colors[1][1].R, cfg.Menus[0].Colors[1][1].B = 0, 127 // Blue, apart from the armed red
+	r := newRig(t, cfg)
+	clock := &fakeClock{t: time.Now()}
+	r.e.now = clock.now
+	r.do(r.e.InitializeDevices)
+	pad := busyPad{"main", 1, 1}
+
+	armedRed := "F0002029020D0303527F0000F7\n"
+	if got := r.padFeedback(1, 1, true); got != armedRed {
+		t.Errorf("arming press sent %q, want the armed red instead of the pressed green", got)
+	}
+	r.do(func() {
+		if flash := r.e.busyFlash[pad]; flash.color != armedColor {
+			t.Errorf("armed pad shows %+v, want the armed color", flash.color)
+		}
+	})
+	// The release keeps the armed color rather than the pad's own blue
+	if got := r.padFeedback(1, 1, false); got != armedRed {
+		t.Errorf("release of an armed pad sent %q, want it kept red", got)
+	}
+
+	clock.advance(time.Second)
+	r.padFeedback(1, 1, true)
+	r.waitSent(t, synthOut, 1)
+	r.do(func() {
+		if _, ok := r.e.busyFlash[pad]; ok {
+			t.Error("armed color still showing after the confirming press")
+		}
+		if _, ok := r.e.armed["lpx"]; ok {
+			t.Error("pad still armed after the confirming press")
+		}
+	})
+}
+
+func TestGroupNeedsConfirmation(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.ActionGroups = []actions.ActionGroup{{ID: "outer", Name: "Outer"}, {ID: "inner", Name: "Inner", ParentGroupID: "outer"}}
+	cfg.Actions = append(cfg.Actions,
+		actions.Action{ID: "plain", Name: "Plain", Type: actions.ActionTypeSleep, Code: "0", ParentGroupID: "outer"},
+		actions.Action{ID: "careful", Name: "Careful", Type: actions.ActionTypeSleep, Code: "0", ParentGroupID: "inner",
+			RequireConfirmation: true})
+	r := newRig(t, cfg)
+
+	r.do(func() {
+		for id, want := range map[string]bool{"note": false, "plain": false, "careful": true, "inner": true, "outer": true, "missing": false} {
+			if got := r.e.needsConfirmation(id); got != want {
+				t.Errorf("needsConfirmation(%q) = %v, want %v", id, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// CooldownError is what a run reports when an action was skipped because it ran too recently
+type CooldownError struct {
+	Action    string
+	Remaining time.Duration // Until the action may run again
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("%s is cooling down for another %s", e.Action, e.Remaining.Round(100*time.Millisecond))
+}
+
+// isCooldown reports whether err comes from an action skipped by its cooldown
+func isCooldown(err error) bool {
+	var cooldown *CooldownError
+	return errors.As(err, &cooldown)
+}
+
+// claimCooldown records a run of an action that has a cooldown, or returns a
+// CooldownError if it last ran less than its cooldown ago. Whatever triggered the
+// run, it comes through here, so concurrent triggers let exactly one run through.
+func (e *Engine) claimCooldown(action *actions.Action) error {
+	if action.CooldownSeconds <= 0 {
+		return nil
+	}
+	cooldown := time.Duration(action.CooldownSeconds) * time.Second
+	now := e.now()
+
+	e.cooldownMu.Lock()
+	last, ok := e.lastRun[action.ID]
+	remaining := last.Add(cooldown).Sub(now)
+	if !ok || remaining <= 0 {
+		e.lastRun[action.ID] = now
+	}
+	e.cooldownMu.Unlock()
+
+	if ok && remaining > 0 {
+		slog.Info("Action skipped, cooling down", "action", action.Name, "remaining", remaining)
+		usage.ActionSuppressed(action.ID)
+		return &CooldownError{Action: action.Name, Remaining: remaining}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+func TestClaimCooldown(t *testing.T) {
+	r := newRig(t, testConfig())
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r.e.now = clock.now
+	action := &actions.Action{ID: "skip", Name: "Skip track", CooldownSeconds: 2}
+
+	claim := func(want bool, remaining time.Duration) {
+		t.Helper()
+		err := r.e.claimCooldown(action)
+		var cooldown *CooldownError
+		switch {
+		case want && err != nil:
+			t.Errorf("at %s: %v, want the run let through", clock.t.Format("05.000"), err)
+		case !want && (!errors.As(err, &cooldown) || cooldown.Remaining != remaining):
+			t.Errorf("at %s: %v, want skipped with %s to go", clock.t.Format("05.000"), err, remaining)
+		}
+	}
+
+	claim(true, 0)
+	clock.advance(500 * time.Millisecond)
+	claim(false, 1500*time.Millisecond)
+	clock.advance(1499 * time.Millisecond)
+	claim(false, time.Millisecond)
+	clock.advance(time.Millisecond) // Exactly the cooldown since the run
+	claim(true, 0)
+	claim(false, 2*time.Second) // Skipped runs don't restart the cooldown, let-through ones do
+
+	// Actions without a cooldown are never skipped
+	free := &actions.Action{ID: "free", Name: "Free"}
+	for range 3 {
+		if err := r.e.claimCooldown(free); err != nil {
+			t.Errorf("action without cooldown skipped: %v", err)
+		}
+	}
+}
+
+// TestClaimCooldownConcurrent races triggers at the same instant, then again right at
+// the end of the cooldown: each time exactly one gets through
+func TestClaimCooldownConcurrent(t *testing.T) {
+	r := newRig(t, testConfig())
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r.e.now = clock.now
+	action := actions.Action{ID: "skip", Name: "Skip track", CooldownSeconds: 1}
+
+	race := func() int {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		through := 0
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				step := action // As each trigger runs its own copy
+				if r.e.claimCooldown(&step) == nil {
+					mu.Lock()
+					through++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		return through
+	}
+
+	if n := race(); n != 1 {
+		t.Errorf("%d of 50 concurrent triggers ran, want 1", n)
+	}
+	clock.advance(time.Second)
+	if n := race(); n != 1 {
+		t.Errorf("%d of 50 triggers at the cooldown boundary ran, want 1", n)
+	}
+}
+
+// TestCooldownFlashesRejectedPad checks a mashed pad: its action runs once, and the
+// skipped press flashes the pad yellow when set to
+func TestCooldownFlashesRejectedPad(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.Actions[0].CooldownSeconds = 60
+	cfg.BusyFeedback.FlashRejected = true
+	cfg.PadDebounceMs = 0 // The mashing is on purpose
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+
+	r.press(colorfulIn, 1, 1, true)
+	r.press(colorfulIn, 1, 1, false)
+	r.waitSent(t, synthOut, 1)
+	r.fake.Reset()
+
+	r.press(colorfulIn, 1, 1, true)
+	r.press(colorfulIn, 1, 1, false)
+	const yellow = "F0002029020D0303527F7F00F7" // Pad 1,1 is LED 82
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(r.wire(colorfulOut), yellow) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		r.do(r.e.flushFeedback)
+	}
+	if got := r.wire(colorfulOut); !strings.Contains(got, yellow) {
+		t.Errorf("rejected press sent\n%s\nwant the yellow flash %s", got, yellow)
+	}
+	if got := r.wire(synthOut); got != "" {
+		t.Errorf("synth got %q while cooling down", got)
+	}
+}
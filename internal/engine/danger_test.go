@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// dangerousAction adds a harmless shell action that matches the shutdown pattern and
+// touches marker when it runs
+func dangerousAction(r *testRig, marker string, allow bool) {
+	r.do(func() {
+		r.e.actionStore.Actions = append(r.e.actionStore.Actions, actions.Action{
+			ID: "danger", Name: "Danger", Type: actions.ActionTypeShellCommand,
+			Code: ": shutdown; touch " + marker, AllowDangerous: allow,
+		})
+	})
+}
+
+func TestDangerousActionBlocked(t *testing.T) {
+	r := newRig(t, testConfig())
+	marker := filepath.Join(t.TempDir(), "ran")
+	dangerousAction(r, marker, false)
+
+	r.do(func() { r.e.Run("danger") })
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("blocked action ran")
+	}
+	s := r.e.Status().Snapshot()
+	if s.LastActionName != "Danger" || s.LastActionError == nil || !strings.Contains(s.LastActionError.Error(), "blocked") {
+		t.Errorf("status = %q, %v; want Danger blocked", s.LastActionName, s.LastActionError)
+	}
+}
+
+func TestDangerousActionAllowed(t *testing.T) {
+	r := newRig(t, testConfig())
+	marker := filepath.Join(t.TempDir(), "ran")
+	dangerousAction(r, marker, true)
+
+	r.do(func() { r.e.Run("danger") })
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("allowed action didn't run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := r.e.Status().Snapshot().LastActionError; err != nil {
+		t.Errorf("allowed action failed: %v", err)
+	}
+}
+
+// TestDangerousStepBlocksGroup checks that a group stops before running anything when
+// one of its steps is blocked
+func TestDangerousStepBlocksGroup(t *testing.T) {
+	r := newRig(t, testConfig())
+	marker := filepath.Join(t.TempDir(), "ran")
+	dangerousAction(r, marker, false)
+	r.do(func() {
+		store := r.e.actionStore
+		store.Groups = append(store.Groups, actions.ActionGroup{ID: "group", Name: "Group"})
+		store.Actions[0].ParentGroupID, store.Actions[0].Order = "group", 0 // Plays the note
+		store.Actions[1].ParentGroupID, store.Actions[1].Order = "group", 1
+		r.e.Run("group")
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	if got := r.wire(synthOut); got != "" {
+		t.Errorf("synth got %q from a blocked group", got)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("blocked step ran")
+	}
+}
+
+// TestDangerousActionBlockedWhenWaited checks the paths that run an action to the end
+// themselves: the HTTP API for actions set to wait, and the command line without a
+// running instance
+func TestDangerousActionBlockedWhenWaited(t *testing.T) {
+	r := newRig(t, testConfig())
+	marker := filepath.Join(t.TempDir(), "ran")
+	dangerousAction(r, marker, false)
+	r.do(func() {
+		store := r.e.actionStore
+		store.Actions[1].WaitForCompletion = true
+		store.Groups = append(store.Groups, actions.ActionGroup{ID: "group", Name: "Group"})
+		store.Actions[0].ParentGroupID, store.Actions[0].Order = "group", 0 // Plays the note
+		store.Actions[1].ParentGroupID, store.Actions[1].Order = "group", 1
+	})
+
+	if result, err := r.e.RunAction("danger"); !errors.Is(err, ErrBlocked) || result.Waited {
+		t.Errorf("RunAction = %+v, %v; want ErrBlocked", result, err)
+	}
+	for _, ref := range []string{"Danger", "Group"} {
+		if err := r.e.RunNamedAndWait(ref); !errors.Is(err, ErrBlocked) {
+			t.Errorf("RunNamedAndWait(%q) = %v, want ErrBlocked", ref, err)
+		}
+	}
+
+	if got := r.wire(synthOut); got != "" {
+		t.Errorf("synth got %q from a blocked group", got)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("blocked action ran")
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock tests move by hand
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func (c *fakeClock) set(ms int, start time.Time) {
+	c.t = start.Add(time.Duration(ms) * time.Millisecond)
+}
+
+func TestDebounce(t *testing.T) {
+	type press struct {
+		ms       int // Since the first press
+		row, col int
+	}
+	tests := []struct {
+		name     string
+		globalMs int
+		padMs    int // Pad 2,3's own window
+		presses  []press
+		runs     int
+	}{
+		{"off", 0, 0, []press{{0, 1, 1}, {1, 1, 1}, {2, 1, 1}}, 3},
+		{"bounce ignored", 30, 0, []press{{0, 1, 1}, {5, 1, 1}, {29, 1, 1}}, 1},
+		{"after the window", 30, 0, []press{{0, 1, 1}, {30, 1, 1}, {61, 1, 1}}, 3},
+		// Each bounce restarts the window, so a contact chattering for a while runs once
+		{"window follows the last press", 30, 0, []press{{0, 1, 1}, {20, 1, 1}, {40, 1, 1}, {75, 1, 1}}, 2},
+		{"per pad", 30, 0, []press{{0, 1, 1}, {1, 2, 3}, {2, 1, 1}, {3, 2, 3}}, 2},
+		// Pad 2,3 ignores the press at 50ms, pad 1,1 with the global window doesn't
+		{"pad override", 30, 100, []press{{0, 2, 3}, {0, 1, 1}, {50, 2, 3}, {50, 1, 1}, {150, 2, 3}}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(colorfulDevice())
+			cfg.PadDebounceMs = tt.globalMs
+			cfg.Menus[0].Colors[2][3].ActionID = "note"
+			cfg.Menus[0].Colors[2][3].DebounceMs = tt.padMs
+			r := newRig(t, cfg)
+			start := time.Now()
+			clock := &fakeClock{t: start}
+			r.e.now = clock.now
+			r.do(r.e.InitializeDevices)
+			r.fake.Reset()
+
+			for _, p := range tt.presses {
+				clock.set(p.ms, start)
+				r.do(func() {
+					r.e.handlePadPress("lpx", p.row, p.col, true, 127)
+					r.e.handlePadPress("lpx", p.row, p.col, false, 0)
+				})
+			}
+			r.do(r.e.flushFeedback)
+
+			r.waitSent(t, synthOut, tt.runs)
+			time.Sleep(20 * time.Millisecond) // Runs that shouldn't happen would by now
+			if got := len(r.fake.SentTo(synthOut)); got != tt.runs {
+				t.Errorf("action ran %d times, want %d", got, tt.runs)
+			}
+		})
+	}
+}
+
+// TestDebounceKeepsFeedback checks that an ignored press still lights the pad
+func TestDebounceKeepsFeedback(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.PadDebounceMs = 50
+	r := newRig(t, cfg)
+	clock := &fakeClock{t: time.Now()}
+	r.e.now = clock.now
+	r.do(r.e.InitializeDevices)
+
+	if got := r.padFeedback(1, 1, true); got != "F0002029020D030352007F00F7\n" {
+		t.Errorf("first press sent %q", got)
+	}
+	r.waitSent(t, synthOut, 1)
+	clock.advance(5 * time.Millisecond)
+	if got := r.padFeedback(1, 1, true); got != "F0002029020D030352007F00F7\n" {
+		t.Errorf("bounced press sent %q, want the pressed color", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := len(r.fake.SentTo(synthOut)); got != 0 {
+		t.Errorf("bounced press ran the action %d times", got)
+	}
+}
@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/metrics"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// sendQuietPeriod is how long config changes have to stop coming before the device
+// updates they need are sent, so a burst of edits reaches the hardware once
+const sendQuietPeriod = 200 * time.Millisecond
+
+// deviceChange is what a device needs sent after config changes. Flags add up while
+// waiting, and a flush sends the least that covers them all.
+type deviceChange uint8
+
+const (
+	changeBrightness deviceChange = 1 << iota // The lit pads, rescaled
+	changeLayout                              // The whole grid
+	changeMode                                // Programmer mode, blank pads and the whole grid
+)
+
+// shownGrid is what a device was last sent in one grid push, see sendMenuToDevice
+type shownGrid struct {
+	menuID     string
+	brightness int
+	colors     [9][9]midi.PadColor // As sent, in the device's own coordinates
+}
+
+// queueDeviceSend marks a device as needing an update, sent by the next FlushDeviceSends.
+// Without an explicit flush, one runs after sendQuietPeriod without further changes.
+func (e *Engine) queueDeviceSend(deviceID string, change deviceChange) {
+	if _, ok := e.pendingSends[deviceID]; ok {
+		metrics.CoalescedSends.Add(1)
+	}
+	e.pendingSends[deviceID] |= change
+
+	if e.sendTimer != nil {
+		e.sendTimer.Stop()
+	}
+	e.sendTimer = time.AfterFunc(sendQuietPeriod, func() { e.dispatch(e.FlushDeviceSends) })
+}
+
+// QueueLayoutSends marks every device that shows a layout for a grid resend, e.g.
+// after a layout was saved
+func (e *Engine) QueueLayoutSends() {
+	for _, device := range e.cfg.Devices {
+		if device.OutPort == "" || (device.MainMenu == "" && !device.HasPages()) || device.Disabled || !device.SendStaticLayout {
+			continue
+		}
+		e.queueDeviceSend(device.ID, changeLayout)
+	}
+}
+
+// FlushDeviceSends sends the updates queued for devices now, in the order the devices
+// are configured
+func (e *Engine) FlushDeviceSends() {
+	if e.sendTimer != nil {
+		e.sendTimer.Stop()
+		e.sendTimer = nil
+	}
+	if len(e.pendingSends) == 0 {
+		return
+	}
+	pending := e.pendingSends
+	e.pendingSends = map[string]deviceChange{}
+	for _, device := range e.cfg.Devices {
+		if change, ok := pending[device.ID]; ok {
+			e.sendDeviceChange(device, change)
+		}
+	}
+}
+
+// sendDeviceChange sends a device what a change needs
+func (e *Engine) sendDeviceChange(device config.DeviceConfig, change deviceChange) {
+	if device.OutPort == "" || device.Disabled {
+		return
+	}
+	if change&changeMode != 0 {
+		if !e.initDevice(device) {
+			return
+		}
+		change |= changeLayout
+	}
+	if !device.SendStaticLayout {
+		return // Layout is drawn by other software
+	}
+
+	var err error
+	switch {
+	case device.MainMenu == "" && !device.HasPages():
+		if change&changeMode == 0 { // Initializing blanked it already
+			err = e.midiManager.ClearAllPads(device.OutPort, midi.DeviceType(device.Type))
+		}
+	case change&changeLayout == 0 && e.sendBrightness(device):
+	default:
+		err = e.SendGridToDevice(device)
+	}
+	if err != nil {
+		slog.Error("Failed to send layout", "device", device.Name, "err", err)
+	}
+}
+
+// initDevice puts a device in programmer mode and blanks its pads, reporting whether
+// it could be switched
+func (e *Engine) initDevice(device config.DeviceConfig) bool {
+	deviceType := midi.DeviceType(device.Type)
+	e.applySendLimit(device)
+	e.captureMode(device)
+	if err := e.midiManager.ActivateProgrammerMode(device.OutPort, deviceType); err != nil {
+		slog.Error("Failed to activate programmer mode", "device", device.Name, "err", err)
+		return false
+	}
+	slog.Info("Activated programmer mode", "device", device.Name)
+	e.initialized[device.ID] = true
+
+	// Blank the pads first, so nothing from an earlier layout lingers where the new one is dark
+	if err := e.midiManager.ClearAllPads(device.OutPort, deviceType); err != nil {
+		slog.Warn("Failed to clear pads", "device", device.Name, "err", err)
+	}
+	delete(e.shown, device.ID)
+	return true
+}
+
+// sendBrightness rescales a device's pads to its current brightness, sending only the
+// pads lit before or after, in one batch, and the pads around the grid on devices that
+// have them. It reports false, having sent nothing, when the device wasn't last sent
+// the menu it shows now and needs its whole grid.
+func (e *Engine) sendBrightness(device config.DeviceConfig) bool {
+	shown, ok := e.shown[device.ID]
+	menu := e.activeMenu(device)
+	if !ok || menu == nil || menu.ID != shown.menuID {
+		return false
+	}
+
+	brightness := e.brightnessOf(device)
+	colors := e.deviceGrid(device, menu, brightness)
+	deviceType := midi.DeviceType(device.Type)
+	hardware := midi.GetDevice(deviceType)
+	var updates []midi.PadUpdate
+	for row := range 9 {
+		for col := range 9 {
+			if (colors[row][col] == midi.PadColor{} && shown.colors[row][col] == midi.PadColor{}) || !hardware.CanDisplay(row, col) {
+				continue
+			}
+			updates = append(updates, midi.PadUpdate{Row: row, Col: col, Color: colors[row][col]})
+		}
+	}
+	err := e.midiManager.SetPadColors(device.OutPort, deviceType, updates)
+	if err == nil && config.ShapeOf(device.Type).Has(config.PadAreaLeft) {
+		err = e.midiManager.SetOuterPads(device.OutPort, deviceType, outerPads(device, menu, brightness))
+	}
+	if err != nil {
+		slog.Warn("Failed to set pad brightness", "device", device.Name, "err", err)
+		delete(e.shown, device.ID)
+		return true
+	}
+	e.shown[device.ID] = shownGrid{menuID: menu.ID, brightness: brightness, colors: colors}
+	return true
+}
+
+// reactivationChange is what a device needs sent when devices are initialized again:
+// everything if it isn't known to be in programmer mode, only its brightness if that is
+// all that changed since its last activation, otherwise its layout
+func (e *Engine) reactivationChange(before config.DeviceConfig, device config.DeviceConfig) deviceChange {
+	if !e.initialized[device.ID] {
+		return changeMode
+	}
+	shown, ok := e.shown[device.ID]
+	if menu := e.activeMenu(device); !ok || menu == nil || menu.ID != shown.menuID {
+		return changeLayout
+	}
+	before.Brightness = device.Brightness
+	if reflect.DeepEqual(before, device) && e.brightnessOf(device) != shown.brightness {
+		return changeBrightness
+	}
+	return changeLayout // Menus and the top row may have changed along with the device
+}
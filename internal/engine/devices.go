@@ -0,0 +1,499 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/metrics"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// testSweepStep is how long each color is shown during a device test
+const testSweepStep = 300 * time.Millisecond
+
+// modeQueryTimeout is how long a device has to report its mode before activation
+const modeQueryTimeout = 300 * time.Millisecond
+
+// InitializeDevices puts all devices in programmer mode, sends the current layout and starts listening.
+// Devices still in programmer mode from the last activation are only sent what changed.
+func (e *Engine) InitializeDevices() {
+	// A crash before EndDeviceInit makes the next launch start in safe mode
+	if err := config.BeginDeviceInit(); err != nil {
+		slog.Warn("Failed to mark device initialization", "err", err)
+	}
+
+	// Ports named differently on this machine are bound by the devices' port patterns
+	e.bindPorts(e.midiManager.ListInPorts(), e.midiManager.ListOutPorts())
+
+	// Release hardware that devices were bound to at the last activation but no longer are
+	previous := maps.Clone(e.activeDevices)
+	for id, old := range previous {
+		if current := e.cfg.GetDevice(id); current == nil || DeviceBindingChanged(old, *current) {
+			e.teardownDevice(old)
+		}
+	}
+	clear(e.activeDevices)
+	clear(e.pendingInit) // Everything is initialized below; what's still missing is marked again
+	for _, device := range e.cfg.Devices {
+		e.activeDevices[device.ID] = device
+	}
+
+	// Start every device from its main layer (or persisted page)
+	e.resetDeviceStates()
+
+	// Devices already in programmer mode are sent only what changed, together with
+	// updates still queued, so activating again doesn't make them flicker
+	for _, device := range e.cfg.Devices {
+		if device.OutPort == "" || device.Disabled {
+			continue
+		}
+		e.queueDeviceSend(device.ID, e.reactivationChange(previous[device.ID], device))
+	}
+	e.FlushDeviceSends()
+
+	// Start MIDI input listeners
+	e.StartListeners()
+
+	// Devices found connected get their connect hooks run; during startup the others
+	// are tried again
+	e.refreshConnections()
+	e.markPendingDevices()
+	ids := make([]string, len(e.cfg.Devices))
+	for i, device := range e.cfg.Devices {
+		ids[i] = device.ID
+	}
+	e.activateLayouts("devices initialized", ids...)
+
+	// Not deferred, so a panic above leaves the mark in place
+	if err := config.EndDeviceInit(); err != nil {
+		slog.Warn("Failed to clear device initialization mark", "err", err)
+	}
+}
+
+// DeviceBindingChanged reports whether an edit affects which hardware a device talks to, or how.
+// The old binding is torn down when such an edit is activated.
+func DeviceBindingChanged(before, after config.DeviceConfig) bool {
+	return before.InPort != after.InPort ||
+		before.OutPort != after.OutPort ||
+		before.Type != after.Type ||
+		before.Disabled != after.Disabled
+}
+
+// ActivateDevice puts a single device in programmer mode, sends its layout and starts listening
+func (e *Engine) ActivateDevice(device config.DeviceConfig) {
+	e.activeDevices[device.ID] = device
+	defer e.refreshConnections() // A newly enabled device gets its connect hook run
+	if device.Disabled {
+		return
+	}
+
+	if device.OutPort != "" {
+		e.applySendLimit(device)
+	}
+	if device.OutPort != "" && device.Type != config.DeviceTypeGeneric {
+		e.captureMode(device)
+		if err := e.midiManager.ActivateProgrammerMode(device.OutPort, midi.DeviceType(device.Type)); err != nil {
+			slog.Error("Failed to activate programmer mode", "device", device.Name, "err", err)
+		} else {
+			e.initialized[device.ID] = true
+			if device.MainMenu != "" || device.HasPages() {
+				if err := e.SendGridToDevice(device); err != nil {
+					slog.Error("Failed to send layout", "device", device.Name, "err", err)
+				}
+			}
+		}
+	}
+	e.startDeviceListener(device)
+	e.activateLayouts("device activated", device.ID)
+}
+
+// ReleaseDevice tears down whatever a device was activated with, e.g. before it is removed.
+// The activated settings are used, since they may differ from unsaved edits.
+func (e *Engine) ReleaseDevice(deviceID string) {
+	if active, ok := e.activeDevices[deviceID]; ok {
+		e.runDisconnectHook(active, nil)
+		e.forgetConnection(deviceID)
+		e.teardownDevice(active)
+		delete(e.activeDevices, deviceID)
+		for _, other := range e.activeDevices {
+			if other.OutPort == active.OutPort && other.OutPort != "" && !other.Disabled {
+				e.applySendLimit(other) // Still mirrored there
+			}
+		}
+	}
+	delete(e.pendingInit, deviceID)
+	delete(e.activeLayout, deviceID)
+	delete(e.pendingSends, deviceID)
+	delete(e.feedbackBatch, deviceID)
+}
+
+// teardownDevice stops a device's listener and blanks its pads, leaving the hardware idle,
+// and puts it back in its earlier mode if it is set to
+func (e *Engine) teardownDevice(device config.DeviceConfig) {
+	e.stopDeviceListener(device.ID)
+	mode := e.savedModes[device.ID]
+	delete(e.savedModes, device.ID)
+	delete(e.initialized, device.ID)
+	delete(e.shown, device.ID)
+
+	if device.OutPort == "" || device.Disabled || device.Type == config.DeviceTypeGeneric {
+		return
+	}
+	if err := e.midiManager.ClearAllPads(device.OutPort, midi.DeviceType(device.Type)); err != nil {
+		slog.Warn("Failed to clear pads", "device", device.Name, "err", err)
+	}
+	e.midiManager.SetSendLimit(device.OutPort, midi.SendLimit{}) // Writes what is queued before the mode changes
+	if device.RestoreModeOnExit {
+		if err := e.midiManager.RestoreDeviceMode(device.OutPort, midi.DeviceType(device.Type), mode); err != nil {
+			slog.Warn("Failed to restore device mode", "device", device.Name, "err", err)
+		}
+	}
+}
+
+// applySendLimit paces what is written to a device's output port as configured
+func (e *Engine) applySendLimit(device config.DeviceConfig) {
+	e.midiManager.SetSendLimit(device.OutPort, midi.SendLimit{Rate: device.SendRateLimit, Burst: device.SendBurst})
+}
+
+// captureMode asks a device set to restore its mode on exit which mode it is in, before
+// it is switched to programmer mode. Only the first activation asks; later ones would
+// find it in programmer mode already. Devices that can't be asked or don't answer go
+// back to their default mode.
+func (e *Engine) captureMode(device config.DeviceConfig) {
+	if !device.RestoreModeOnExit {
+		return
+	}
+	if _, ok := e.savedModes[device.ID]; ok {
+		return
+	}
+	mode, err := e.midiManager.QueryDeviceMode(device.InPort, device.OutPort, midi.DeviceType(device.Type), modeQueryTimeout)
+	if err != nil {
+		slog.Warn("Failed to read device mode", "device", device.Name, "err", err)
+	}
+	e.savedModes[device.ID] = mode
+}
+
+// StartListeners makes the running MIDI input listeners match the configured devices.
+// It is idempotent: listeners that already match are left alone, so calling it again
+// never makes an input fire twice.
+func (e *Engine) StartListeners() {
+	// A shared input port belongs to the first enabled device using it
+	owners := map[string]string{}
+	for _, device := range e.cfg.Devices {
+		if _, taken := owners[device.InPort]; !taken && device.InPort != "" && !device.Disabled {
+			owners[device.InPort] = device.ID
+		}
+	}
+
+	// Drop listeners whose device is gone, has moved or no longer owns the port
+	for port, l := range e.listeners {
+		device := e.cfg.GetDevice(l.deviceID)
+		if device == nil || owners[port] != l.deviceID || !l.matches(*device) {
+			e.stopListener(port)
+		}
+	}
+
+	for _, device := range e.cfg.Devices {
+		e.startDeviceListener(device)
+	}
+}
+
+// StopListeners stops all MIDI input listeners
+func (e *Engine) StopListeners() {
+	for port := range e.listeners {
+		e.stopListener(port)
+	}
+}
+
+// startDeviceListener makes a device's input listener match its settings, replacing a
+// listener it has on another port. Only one listener runs per input port, otherwise
+// every press would fire twice; the first device to claim a port keeps it.
+func (e *Engine) startDeviceListener(device config.DeviceConfig) {
+	if device.InPort == "" || device.Disabled {
+		e.stopDeviceListener(device.ID)
+		return
+	}
+
+	if l, ok := e.listeners[device.InPort]; ok {
+		if l.matches(device) {
+			return // Already listening
+		}
+		if l.deviceID != device.ID {
+			owner := l.deviceID
+			if other := e.cfg.GetDevice(l.deviceID); other != nil {
+				owner = other.Name
+			}
+			slog.Warn("Input port already in use, not listening", "device", device.Name, "port", device.InPort, "owner", owner)
+			return
+		}
+	}
+	e.stopDeviceListener(device.ID) // Replace a listener on the old port or with old settings
+
+	deviceType := midi.DeviceType(device.Type)
+	deviceID := device.ID
+
+	var stop func()
+	var err error
+
+	if device.Type == config.DeviceTypeGeneric {
+		// Generic devices use message mapping instead of pad layout
+		stop, err = e.midiManager.StartGenericListening(device.InPort, func(portName, msgType string, channel, number, value int) {
+			received := time.Now()
+			e.dispatch(func() {
+				metrics.Dispatch.Observe(time.Since(received))
+				defer e.recoverPanic("MIDI message from " + portName)
+				e.handleGenericMIDIMessage(portName, msgType, channel, number, value)
+			})
+		})
+	} else {
+		// Launchpad devices use pad layout
+		stop, err = e.midiManager.StartListening(device.InPort, deviceType, inputFilter(device.InputFilter), func(portName string, row, col int, isNoteOn bool, velocity uint8) {
+			if !isNoteOn {
+				e.releaseThru(padKey{deviceID, row, col}) // Right away, without waiting for dispatch
+			}
+			received := time.Now()
+			e.dispatch(func() {
+				metrics.Dispatch.Observe(time.Since(received))
+				defer e.recoverPanic("pad press on " + portName)
+				e.handlePadPress(deviceID, row, col, isNoteOn, velocity)
+			})
+		})
+	}
+
+	if err != nil {
+		slog.Error("Failed to start listener", "device", device.Name, "err", err)
+		return
+	}
+
+	if stop != nil {
+		e.listeners[device.InPort] = deviceListener{deviceID: device.ID, deviceType: device.Type, filter: device.InputFilter, stop: stop}
+		slog.Info("Started listening", "port", device.InPort, "device", device.Name)
+	}
+}
+
+// matches reports whether the listener was started for the device with its current settings
+func (l deviceListener) matches(device config.DeviceConfig) bool {
+	return l.deviceID == device.ID && l.deviceType == device.Type && l.filter == device.InputFilter
+}
+
+// inputFilter converts a device's configured input filter for the MIDI layer
+func inputFilter(f config.InputFilterConfig) midi.InputFilter {
+	return midi.InputFilter{Channels: f.Channels, IgnoreCC: f.IgnoreCC, IgnoreNotes: f.IgnoreNotes, LogFiltered: f.LogFiltered}
+}
+
+// stopDeviceListener stops a device's input listener, if it has one
+func (e *Engine) stopDeviceListener(deviceID string) {
+	e.releaseAllThru(deviceID) // Their releases would never arrive
+	for port, l := range e.listeners {
+		if l.deviceID == deviceID {
+			e.stopListener(port)
+		}
+	}
+}
+
+// stopListener stops the listener on an input port, if there is one
+func (e *Engine) stopListener(port string) {
+	l, ok := e.listeners[port]
+	if !ok {
+		return
+	}
+	delete(e.listeners, port)
+	l.stop()
+	slog.Info("Stopped listening", "port", port)
+}
+
+// SendGridToTarget pushes the current layout to a device, or to every member of a device group
+func (e *Engine) SendGridToTarget(targetID string) error {
+	var errs []error
+	for _, id := range e.cfg.TargetDeviceIDs(targetID) {
+		device := e.cfg.GetDevice(id)
+		if device == nil || device.Disabled || device.OutPort == "" {
+			continue
+		}
+		if err := e.SendGridToDevice(*device); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendGridToDevice pushes the menu the device is currently showing to its output port
+func (e *Engine) SendGridToDevice(device config.DeviceConfig) error {
+	if device.OutPort == "" {
+		return fmt.Errorf("no output port configured for %s", device.Name)
+	}
+	if device.MainMenu == "" && !device.HasPages() {
+		return fmt.Errorf("no menu assigned to %s", device.Name)
+	}
+	if !device.SendStaticLayout {
+		return nil // Layout is drawn by other software
+	}
+
+	// Find the menu this device is displaying (shift menu while held, current page or main menu)
+	menu := e.activeMenu(device)
+	if menu == nil {
+		return fmt.Errorf("menu for %s not found", device.Name)
+	}
+	return e.sendMenuToDevice(device, menu)
+}
+
+// sendMenuToDevice pushes a specific menu to the device in one bulk send
+func (e *Engine) sendMenuToDevice(device config.DeviceConfig, menu *config.MenuLayout) error {
+	brightness := e.brightnessOf(device)
+	colors := e.deviceGrid(device, menu, brightness)
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		for _, issue := range PadIssues(device, menu, brightness) {
+			slog.Debug("Pad not shown as configured", "menu", menu.Name, "device", device.Name,
+				"row", issue.Row, "col", issue.Col, "dropped", issue.Fit == midi.FitDropped)
+		}
+	}
+
+	if err := e.midiManager.SendGrid(device.OutPort, midi.DeviceType(device.Type), colors, outerPads(device, menu, brightness)); err != nil {
+		delete(e.shown, device.ID)
+		return err
+	}
+	e.shown[device.ID] = shownGrid{menuID: menu.ID, brightness: brightness, colors: colors}
+	slog.Debug("Sent layout", "menu", menu.Name, "device", device.Name)
+	return nil
+}
+
+// deviceGrid returns the colors a device is sent for a menu at a brightness, in the
+// device's own coordinates
+func (e *Engine) deviceGrid(device config.DeviceConfig, menu *config.MenuLayout, brightness int) [9][9]midi.PadColor {
+	// Ensure legacy/uninitialized colors are linked and converted before sending
+	menu.EnsureDefaultLinking()
+
+	deviceType := midi.DeviceType(device.Type)
+
+	var colors [9][9]midi.PadColor
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			colors[row][col] = staticColor(deviceType, menu.EffectiveColor(row, col)).Scaled(brightness)
+		}
+	}
+	e.applyBusyPads(device, menu, &colors)
+	e.applyTopRow(device, &colors)
+	e.applyPageIndicators(device, &colors)
+	return orientGrid(colors, device.Orientation)
+}
+
+// outerPads returns the colors a device is sent for the pads of a menu around the 9x9
+// grid: none for devices whose shape has no such pads. They are not turned with the
+// grid, having no counterpart on the other sides.
+func outerPads(device config.DeviceConfig, menu *config.MenuLayout, brightness int) midi.OuterPads {
+	var pads midi.OuterPads
+	if !config.ShapeOf(device.Type).Has(config.PadAreaLeft) {
+		return pads
+	}
+	deviceType := midi.DeviceType(device.Type)
+	color := func(c config.PadColorConfig) midi.PadColor {
+		return staticColor(deviceType, c).Scaled(brightness)
+	}
+	for i := range 8 {
+		pads.Left[i] = color(menu.LeftColors[i])
+		pads.Bottom[i] = color(menu.BottomColors[i])
+		pads.ExtendedBottom[i] = color(menu.ExtendedBottomColors[i])
+	}
+	pads.TopLeft = color(menu.TopLeftColor)
+	pads.BottomLeft = color(menu.BottomLeftColor)
+	pads.BottomRight = color(menu.BottomRightColor)
+	return pads
+}
+
+// staticColor is the color a pad shows at rest: its classic color for classic devices,
+// its button color for colorful devices, blinking if set to flash
+func staticColor(deviceType midi.DeviceType, c config.PadColorConfig) midi.PadColor {
+	r, g, b := c.StaticFor(config.DeviceType(deviceType))
+	return midi.PadColor{R: r, G: g, B: b, Flash: c.LightMode == config.LightModeFlash}
+}
+
+// PadIssue is a pad of a menu that a device drops or shows as a different color
+type PadIssue struct {
+	Row, Col int // As the menu editor shows it
+	Fit      midi.Fit
+}
+
+// PadIssues lists the pads of a menu that a device, lit at brightness, drops or shows
+// as a noticeably different color at rest
+func PadIssues(device config.DeviceConfig, menu *config.MenuLayout, brightness int) []PadIssue {
+	var issues []PadIssue
+	for row := range 9 {
+		for col := range 9 {
+			if fit := PadFit(device, menu.EffectiveColor(row, col), row, col, brightness); fit != midi.FitExact {
+				issues = append(issues, PadIssue{Row: row, Col: col, Fit: fit})
+			}
+		}
+	}
+	return issues
+}
+
+// PadFit reports how a device, lit at brightness, shows the static color of the pad at
+// row, col as the menu editor shows it. Classic colors linked to the RGB one are checked
+// against that RGB color, so blue comes out as approximated.
+func PadFit(device config.DeviceConfig, c config.PadColorConfig, row, col, brightness int) midi.Fit {
+	deviceType := midi.DeviceType(device.Type)
+	sent := staticColor(deviceType, c).Scaled(brightness)
+	intended := sent
+	if device.Type == config.DeviceTypeClassic && c.LinkButtonClassic {
+		intended = midi.PadColor{R: c.R, G: c.G, B: c.B}
+	}
+	deviceRow, deviceCol := device.Orientation.ToDevice(row, col)
+	return midi.CheckColor(midi.GetDevice(deviceType), deviceRow, deviceCol, intended, sent)
+}
+
+// orientGrid moves a grid of logical pad colors to where a device turned to o addresses them
+func orientGrid(colors [9][9]midi.PadColor, o config.Orientation) [9][9]midi.PadColor {
+	var oriented [9][9]midi.PadColor
+	for row := range 9 {
+		for col := range 9 {
+			deviceRow, deviceCol := o.ToDevice(row, col)
+			oriented[deviceRow][deviceCol] = colors[row][col]
+		}
+	}
+	return oriented
+}
+
+// TestDevice lights every pad through a short sequence of colors, then restores the
+// device's layout (or clears it). It blocks for the length of the sweep, so call it
+// off the goroutine that owns the config.
+func (e *Engine) TestDevice(device config.DeviceConfig) error {
+	if err := e.colorSweep(device); err != nil {
+		return err
+	}
+	if !device.SendStaticLayout || (device.MainMenu == "" && !device.HasPages()) {
+		return e.midiManager.ClearAllPads(device.OutPort, midi.DeviceType(device.Type))
+	}
+
+	// Restoring the layout reads the menus
+	var err error
+	e.dispatchWait(func() { err = e.SendGridToDevice(device) })
+	return err
+}
+
+// colorSweep fills the whole grid with each test color in turn
+func (e *Engine) colorSweep(device config.DeviceConfig) error {
+	deviceType := midi.DeviceType(device.Type)
+	sweep := []midi.PadColor{
+		{R: 127, G: 0, B: 0},
+		{R: 0, G: 127, B: 0},
+		{R: 0, G: 0, B: 127},
+		{R: 127, G: 127, B: 127},
+	}
+
+	for _, c := range sweep {
+		for row := 0; row < 9; row++ {
+			for col := 0; col < 9; col++ {
+				if err := e.midiManager.SetPadColor(device.OutPort, deviceType, row, col, c); err != nil {
+					return err
+				}
+			}
+		}
+		time.Sleep(testSweepStep)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/status"
+)
+
+// Engine is the non-UI runtime: it activates devices, pushes layouts, listens for
+// input and runs the actions pads and message mappings are bound to. The GUI and
+// headless mode share one implementation through it.
+type Engine struct {
+	cfg         *config.Config
+	midiManager midi.Ports
+	executor    *actions.Executor
+	actionStore *actions.ActionStore
+	status      *status.Bus
+
+	// dispatch runs work that touches the config on the goroutine that owns it;
+	// dispatchWait does the same and blocks until the work has run
+	dispatch     func(func())
+	dispatchWait func(func())
+	ownerMu      sync.Mutex // Serializes the default dispatchers
+
+	// MIDI input listeners and what was activated, touched only by the owning goroutine
+	listeners     map[string]deviceListener      // input port -> running listener
+	activeDevices map[string]config.DeviceConfig // device ID -> settings at the last activation
+	lastPress     map[padKey]time.Time           // When each pad was last pressed, for debouncing
+	overlapWarned map[string]time.Time           // Overlapping mapping pairs -> when last logged, see warnOverlaps
+	savedModes    map[string][]byte              // device ID -> mode before activation, see captureMode
+	now           func() time.Time               // Clock for debouncing
+
+	// Runtime device state, updated from MIDI listener goroutines
+	stateMu      sync.Mutex
+	shiftHeld    map[string]bool   // device ID -> shift pad currently held
+	selectedMenu map[string]string // device ID -> menu ID switched to at runtime (e.g. by page pads)
+	brightness   map[string]int    // device ID -> brightness set at runtime by a scene
+
+	// Saving the runtime state above to session.json, see restoreSession. The restored
+	// session waits for the next resetDeviceStates and is touched only by the owning
+	// goroutine; sessionMu guards the rest.
+	sessionMu       sync.Mutex
+	sessionSaving   bool
+	sessionTimer    *time.Timer
+	restoredSession *session
+
+	// Notes forwarded by MIDI thru pads that are still held. Releases are sent from the
+	// listener goroutine, so a busy config owner never leaves a note hanging.
+	thruMu   sync.Mutex
+	thruHeld map[padKey]thruNote
+
+	// Actions currently executing, by name
+	runMu   sync.Mutex
+	running map[string]int
+
+	// When actions with a cooldown last ran, by action ID, see claimCooldown
+	cooldownMu sync.Mutex
+	lastRun    map[string]time.Time
+
+	// Pads lit while their action runs, see runFromPad; touched only by the owning goroutine
+	busy      map[busyPad]int      // Runs still going
+	busyFlash map[busyPad]padFlash // Flashes still showing
+
+	// Pads waiting for the second press that runs their action, by device ID, see
+	// pressConfirmed; touched only by the owning goroutine
+	armed map[string]armedPad
+
+	// Layout fallback actions still running, by action ID, see runFallback; touched
+	// only by the owning goroutine
+	fallbackRunning map[string]bool
+
+	// Layout each device was last switched to, by device ID, see activateLayouts;
+	// touched only by the owning goroutine
+	activeLayout      map[string]string
+	activatingLayouts bool
+
+	// Menu hooks waiting to run, by device ID; a device is listed while one of its
+	// hooks runs. See queueMenuHook. Touched only by the owning goroutine.
+	menuHooks map[string][]menuHook
+
+	// Devices whose ports are present, and connect hooks waiting for them to stay so;
+	// touched only by the owning goroutine
+	connected     map[string]bool
+	connectTimers map[string]*time.Timer
+
+	// Devices missing at startup, activated when their ports appear; nil once the
+	// startup retries are over. See InitializeDevicesAtStartup.
+	pendingInit map[string]bool
+
+	// Device updates waiting for FlushDeviceSends, what each device was last sent and
+	// which devices are in programmer mode; touched only by the owning goroutine
+	pendingSends map[string]deviceChange
+	sendTimer    *time.Timer
+	shown        map[string]shownGrid
+	initialized  map[string]bool
+
+	// Pad feedback waiting for flushFeedback, by device ID and pad in the device's own
+	// coordinates; touched only by the owning goroutine
+	feedbackBatch map[string]map[[2]int]queuedFeedback
+	feedbackTimer *time.Timer
+
+	// Macro recording, see StartMacroRecording; touched only by the owning goroutine
+	recording    bool
+	recordSilent bool
+	recorded     []actions.MacroStep
+}
+
+// deviceListener is a running MIDI input listener and the device it feeds
+type deviceListener struct {
+	deviceID   string
+	deviceType config.DeviceType
+	filter     config.InputFilterConfig
+	stop       func()
+}
+
+// New creates an engine for the given config. actionStore is the live action set
+// pads and mappings resolve against (the GUI edits it in place).
+func New(cfg *config.Config, midiManager midi.Ports, actionStore *actions.ActionStore) *Engine {
+	e := &Engine{
+		cfg:             cfg,
+		midiManager:     midiManager,
+		executor:        actions.NewExecutor(midiManager),
+		actionStore:     actionStore,
+		status:          status.NewBus(),
+		listeners:       map[string]deviceListener{},
+		activeDevices:   map[string]config.DeviceConfig{},
+		lastPress:       map[padKey]time.Time{},
+		overlapWarned:   map[string]time.Time{},
+		savedModes:      map[string][]byte{},
+		now:             time.Now,
+		shiftHeld:       map[string]bool{},
+		selectedMenu:    map[string]string{},
+		brightness:      map[string]int{},
+		thruHeld:        map[padKey]thruNote{},
+		running:         map[string]int{},
+		busy:            map[busyPad]int{},
+		busyFlash:       map[busyPad]padFlash{},
+		armed:           map[string]armedPad{},
+		fallbackRunning: map[string]bool{},
+		activeLayout:    map[string]string{},
+		menuHooks:       map[string][]menuHook{},
+		lastRun:         map[string]time.Time{},
+		connected:       map[string]bool{},
+		connectTimers:   map[string]*time.Timer{},
+		pendingSends:    map[string]deviceChange{},
+		shown:           map[string]shownGrid{},
+		initialized:     map[string]bool{},
+		feedbackBatch:   map[string]map[[2]int]queuedFeedback{},
+	}
+	e.dispatch = e.serialize
+	e.dispatchWait = e.serialize
+	return e
+}
+
+// SetDispatcher sets how work that reads or changes the config reaches the goroutine
+// that owns it (fyne.Do and fyne.DoAndWait in the GUI). MIDI input goes through dispatch,
+// requests from the control socket and HTTP API through dispatchWait. By default both
+// run the work inline, one call at a time.
+func (e *Engine) SetDispatcher(dispatch, dispatchWait func(func())) {
+	e.dispatch = dispatch
+	e.dispatchWait = dispatchWait
+}
+
+// serialize runs fn inline, holding ownerMu so concurrent callers take turns with the config
+func (e *Engine) serialize(fn func()) {
+	e.ownerMu.Lock()
+	defer e.ownerMu.Unlock()
+	fn()
+}
+
+// Executor returns the executor actions run on
+func (e *Engine) Executor() *actions.Executor {
+	return e.executor
+}
+
+// Status returns the bus that publishes device and action health
+func (e *Engine) Status() *status.Bus {
+	return e.status
+}
+
+// Shutdown writes the session, runs the app's shutdown action and the devices' disconnect
+// hooks, then stops all listeners and clears the pads of every activated device
+func (e *Engine) Shutdown() {
+	e.flushSession()
+	if e.sendTimer != nil {
+		e.sendTimer.Stop() // The pads are cleared below anyway
+	}
+	if e.feedbackTimer != nil {
+		e.feedbackTimer.Stop()
+	}
+	e.runShutdownHooks()
+	for _, device := range e.activeDevices {
+		e.teardownDevice(device)
+	}
+	clear(e.activeDevices)
+	e.StopListeners()
+	slog.Info("Engine stopped")
+}
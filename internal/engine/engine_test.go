@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi/miditest"
+	gomidi "gitlab.com/gomidi/midi/v2"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Ports of the test rig: a colorful and a classic Launchpad, and a synth actions play on
+const (
+	colorfulIn  = "LPX In"
+	colorfulOut = "LPX Out"
+	classicIn   = "LPS In"
+	classicOut  = "LPS Out"
+	synthOut    = "Synth Out"
+)
+
+// testRig is an engine driving fake MIDI ports
+type testRig struct {
+	e    *Engine
+	cfg  *config.Config
+	fake *miditest.Manager
+}
+
+// newRig builds an engine over the config, with the config directory in a temporary
+// one so session and crash marks stay out of the user's
+func newRig(t *testing.T, cfg *config.Config) *testRig {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	fake := miditest.NewManager([]string{colorfulIn, classicIn}, []string{colorfulOut, classicOut, synthOut})
+	e := New(cfg, fake, cfg.GetActionStore())
+	t.Cleanup(func() { e.dispatchWait(e.StopListeners) })
+	return &testRig{e: e, cfg: cfg, fake: fake}
+}
+
+// testConfig returns a config with one layout, "Main", whose pad 1,1 is red with a
+// green pressed color and runs an action sending a note to the synth
+func testConfig(devices ...config.DeviceConfig) *config.Config {
+	cfg, err := config.Parse([]byte(`{}`))
+	if err != nil {
+		panic(err)
+	}
+	menu := config.NewMenuLayout()
+	menu.ID, menu.Name = "main", "Main"
+	menu.Colors[1][1] = config.PadColorConfig{R: 127, PressedG: 127, ClassicR: 127, ClassicPressedG: 127,
+		ClassicInitialized: true, ClassicPressedInitialized: true, ActionID: "note"}
+	menu.Colors[2][3] = config.PadColorConfig{B: 64, ClassicR: 64, ClassicG: 64, ClassicInitialized: true}
+	cfg.Menus = []config.MenuLayout{menu}
+	cfg.CurrentMenuID = menu.ID
+	cfg.Actions = []actions.Action{{
+		ID: "note", Name: "Play note", Type: actions.ActionTypeMidi,
+		Code: `{"device_name": "` + synthOut + `", "msg_type": "note_on", "channel": 1, "note": 60, "velocity": 100}`,
+	}}
+	cfg.Devices = devices
+	return cfg
+}
+
+// colorfulDevice is a Launchpad Mk3 on the colorful ports, showing Main
+func colorfulDevice() config.DeviceConfig {
+	d := config.NewDeviceConfig()
+	d.ID, d.Name, d.Type = "lpx", "Launchpad X", config.DeviceTypeColorful
+	d.InPort, d.OutPort, d.MainMenu = colorfulIn, colorfulOut, "Main"
+	d.InputFilter = config.DefaultInputFilter(d.Type)
+	return d
+}
+
+// classicDevice is a Launchpad S on the classic ports, showing Main
+func classicDevice() config.DeviceConfig {
+	d := config.NewDeviceConfig()
+	d.ID, d.Name = "lps", "Launchpad S"
+	d.InPort, d.OutPort, d.MainMenu = classicIn, classicOut, "Main"
+	return d
+}
+
+// do runs fn the way the app runs work on the engine, taking turns with MIDI input and timers
+func (r *testRig) do(fn func()) {
+	r.e.dispatchWait(fn)
+}
+
+// press injects a pad press or release on an input port, as a Launchpad in
+// programmer mode sends it, and sends the feedback it queued
+func (r *testRig) press(port string, row, col int, on bool) {
+	note := uint8((8-row)*10 + col + 11)
+	if port == classicIn {
+		note = uint8((row-1)*16 + col) // Launchpad S: row 1 is notes 0-8
+	}
+	velocity := uint8(0)
+	if on {
+		velocity = 127
+	}
+	r.fake.Inject(port, gomidi.NoteOn(0, note, velocity))
+	r.e.dispatchWait(r.e.flushFeedback)
+}
+
+// wire formats the messages sent to a port in hex, one per line
+func (r *testRig) wire(port string) string {
+	var b strings.Builder
+	for _, msg := range r.fake.SentTo(port) {
+		b.WriteString(strings.ToUpper(hex.EncodeToString(msg)))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// waitSent waits for at least n messages on a port, for actions running on their own goroutines
+func (r *testRig) waitSent(t *testing.T, port string, n int) []gomidi.Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sent := r.fake.SentTo(port)
+		if len(sent) >= n || time.Now().After(deadline) {
+			return sent
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// checkGolden compares got with testdata/<name>.golden, or rewrites it with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s: wire differs from the golden file\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
@@ -0,0 +1,322 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/metrics"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// groupActions returns a group's actions, nested groups included, in the order they
+// run. The store hands out copies, so the result is safe to use off the owning goroutine.
+func (e *Engine) groupActions(group *actions.ActionGroup) []actions.Action {
+	var steps []actions.Action
+	for _, item := range e.actionStore.GetSortedTree(group.ID, 0) {
+		if !item.IsGroup {
+			steps = append(steps, *item.Action)
+		}
+	}
+	return steps
+}
+
+// ErrStopped is what a group run reports when it was stopped before every action ran
+var ErrStopped = errors.New("stopped")
+
+// ErrBlocked is returned when an action was kept from running by a danger pattern
+var ErrBlocked = errors.New("blocked by a danger pattern")
+
+// StepEvent reports an action of a group run starting, making progress or finishing
+type StepEvent struct {
+	Index, Total int // Index counts from 0
+	Name         string
+	Finished     bool
+	Remaining    time.Duration // While running, for actions that report progress (sleeps)
+	Output       string        // Once finished
+	Err          error         // Once finished
+	Duration     time.Duration // Once finished
+}
+
+// stepHooks follow and control a runSteps call; nil fields are left out
+type stepHooks struct {
+	progress func(StepEvent) // Called on the running goroutines as actions start and finish
+	stop     <-chan struct{} // Closed to start no further actions and stop running ones
+	done     func(error)     // Called once every action has finished, with the first failure
+	budgets  [][]groupBudget // By step, the time budgets of the groups it is in, see stepBudgets
+}
+
+// runSteps runs actions in order. Those set to wait for completion block the next one;
+// the rest are started and left running. hooks.done is called on the running goroutine.
+func (e *Engine) runSteps(what string, steps []actions.Action, hooks stepHooks) {
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+	fail := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	defer e.recoverPanic(what)
+	panicked := true
+	defer func() {
+		if hooks.done == nil {
+			return
+		}
+		if panicked {
+			fail(fmt.Errorf("%s panicked", what))
+		}
+		wg.Wait()
+		hooks.done(firstErr)
+	}()
+
+	// Closing stop also cancels the actions still running, once started ones are done
+	ctx := context.Background()
+	if hooks.stop != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer func() {
+			go func() {
+				wg.Wait()
+				cancel()
+			}()
+		}()
+		go func() {
+			select {
+			case <-hooks.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	// A group out of budget is reported once, however many of its actions it stops
+	reported := map[string]bool{}
+	overBudget := func(b *groupBudget, action string) {
+		err := &BudgetError{Group: b.name, Limit: b.limit, Action: action}
+		fail(err)
+		errMu.Lock()
+		first := !reported[b.groupID]
+		reported[b.groupID] = true
+		errMu.Unlock()
+		if first {
+			slog.Error("Action group ran out of time", "group", b.name, "budget", b.limit, "action", action)
+			e.status.ActionFailed(b.name, err)
+		}
+	}
+
+	step := func(i int, ctx context.Context, budget *groupBudget) {
+		action := &steps[i]
+		event := StepEvent{Index: i, Total: len(steps), Name: action.Name}
+		stepCtx := ctx
+		if hooks.progress != nil {
+			hooks.progress(event)
+			stepCtx = actions.WithProgress(ctx, func(p actions.Progress) {
+				running := event
+				running.Remaining = p.Remaining
+				hooks.progress(running)
+			})
+		}
+		start := time.Now()
+		output, err := e.execute(stepCtx, action)
+		if budget != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			overBudget(budget, action.Name)
+			err = ctx.Err()
+		} else if errors.Is(err, context.Canceled) {
+			fail(ErrStopped)
+		} else if err != nil {
+			fail(err)
+		}
+		if hooks.progress != nil {
+			event.Finished, event.Output, event.Err, event.Duration = true, output, err, time.Since(start)
+			hooks.progress(event)
+		}
+	}
+
+	started := map[string]time.Time{} // When each group with a budget ran its first action
+	for i := range steps {
+		select {
+		case <-hooks.stop:
+			fail(ErrStopped)
+			panicked = false
+			return
+		default:
+		}
+
+		// Actions of a group out of budget are skipped; the rest of the run goes on
+		stepCtx, cancel := ctx, context.CancelFunc(func() {})
+		var budget *groupBudget
+		if hooks.budgets != nil {
+			var deadline time.Time
+			if deadline, budget = budgetDeadline(hooks.budgets[i], started); budget != nil {
+				if !time.Now().Before(deadline) {
+					overBudget(budget, "")
+					continue
+				}
+				stepCtx, cancel = context.WithDeadline(ctx, deadline)
+			}
+		}
+
+		if steps[i].WaitForCompletion {
+			step(i, stepCtx, budget)
+			cancel()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			defer e.recoverPanic(steps[i].Name)
+			step(i, stepCtx, budget)
+		}()
+	}
+	panicked = false
+}
+
+// execute runs a single action on the executor, tracking it as running and reporting failures
+func (e *Engine) execute(ctx context.Context, action *actions.Action) (string, error) {
+	if err := e.claimCooldown(action); err != nil {
+		return "", err
+	}
+
+	e.runMu.Lock()
+	e.running[action.Name]++
+	e.runMu.Unlock()
+
+	defer func() {
+		e.runMu.Lock()
+		if e.running[action.Name]--; e.running[action.Name] <= 0 {
+			delete(e.running, action.Name)
+		}
+		e.runMu.Unlock()
+	}()
+
+	output, err := e.executor.ExecuteContext(ctx, action)
+	failed := err != nil && ctx.Err() == nil // Not when stopped on purpose or out of time
+
+	usage.ActionRan(action.ID, failed)
+	if failed {
+		e.reportActionFailure(action, err)
+	}
+	return output, err
+}
+
+// RunningActions returns the names of the actions currently executing
+func (e *Engine) RunningActions() []string {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	names := make([]string, 0, len(e.running))
+	for name := range e.running {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// reportActionFailure logs a failed action and publishes it to the status bus
+func (e *Engine) reportActionFailure(action *actions.Action, err error) {
+	slog.Error("Action failed", "action", action.Name, "err", err)
+	e.status.ActionFailed(action.Name, err)
+}
+
+// recoverPanic keeps a panic in work started by input or an action from taking down
+// the app. Defer it at the top of such work: the panic is logged with its stack and
+// reported on the status bus as a failure of what.
+func (e *Engine) recoverPanic(what string) {
+	if r := recover(); r != nil {
+		err := fmt.Errorf("panic: %v", r)
+		slog.Error("Recovered from panic", "in", what, "err", err, "stack", string(debug.Stack()))
+		e.status.ActionFailed(what, err)
+		metrics.Errors.Add(1)
+	}
+}
+
+// Run resolves an ID to an action or action group and runs it without blocking the caller.
+// Call it on the goroutine that owns the config; what runs is a copy taken up front,
+// so editing the actions meanwhile doesn't race with it.
+func (e *Engine) Run(id string) {
+	e.RunWithArgs(id, "")
+}
+
+// RunWithArgs is Run passing args to the action, or to every action of the group
+func (e *Engine) RunWithArgs(id, args string) {
+	e.run(id, args, nil)
+}
+
+// run starts an action, group or scene by ID, passing it args. done, if not nil, is
+// called once everything it started has finished (see runSteps); it isn't called if
+// nothing runs. It reports whether anything was started.
+func (e *Engine) run(id, args string, done func(error)) bool {
+	return e.runFor(id, args, actions.Trigger{}, done)
+}
+
+// runFor is run also passing what the run is for
+func (e *Engine) runFor(id, args string, trigger actions.Trigger, done func(error)) bool {
+	if scene := e.cfg.GetScene(id); scene != nil {
+		return e.runScene(scene, args, trigger, done)
+	}
+	return e.startSteps(id, args, trigger, done)
+}
+
+// startSteps starts an action or group by ID, see run. Scenes aren't resolved, so
+// a scene's action can't start another scene.
+func (e *Engine) startSteps(id, args string, trigger actions.Trigger, done func(error)) bool {
+	var what string
+	var steps []actions.Action
+	if action := e.actionStore.GetAction(id); action != nil {
+		what, steps = action.Name, []actions.Action{*action}
+	} else if group := e.actionStore.GetGroup(id); group != nil {
+		what, steps = "action group", e.groupActions(group)
+	} else {
+		return false
+	}
+	budgets := e.stepBudgets(id, steps)
+
+	for i := range steps {
+		if e.blockDangerous(&steps[i]) {
+			return false // Running the rest without it could do more harm than good
+		}
+		steps[i].Args, steps[i].Trigger = args, trigger
+	}
+	go e.runSteps(what, steps, stepHooks{done: done, budgets: budgets})
+	return true
+}
+
+// TestGroup runs a group for the Test button, reporting each action as it starts and
+// finishes. Unlike Run it doesn't check danger patterns; the caller asks first. Closing
+// stop keeps the actions that haven't started from running, and done then gets
+// ErrStopped unless something failed before. It reports whether the group exists.
+func (e *Engine) TestGroup(id string, stop <-chan struct{}, progress func(StepEvent), done func(error)) bool {
+	group := e.actionStore.GetGroup(id)
+	if group == nil {
+		return false
+	}
+	steps := e.groupActions(group)
+	go e.runSteps(group.Name, steps, stepHooks{progress: progress, stop: stop, done: done, budgets: e.stepBudgets(id, steps)})
+	return true
+}
+
+// blockDangerous reports an action whose code matches a danger pattern, unless it is
+// allowed to run anyway. Nothing asks for confirmation when pads or other input run
+// actions, so a flagged action stays blocked until it is allowed in the action editor.
+func (e *Engine) blockDangerous(action *actions.Action) bool {
+	if action.AllowDangerous {
+		return false
+	}
+	matches := actions.DangerMatches(action, e.cfg.DangerPatterns)
+	if len(matches) == 0 {
+		return false
+	}
+	e.reportActionFailure(action, fmt.Errorf("blocked: the code matches the danger pattern %s; allow dangerous commands for this action to run it", matches[0]))
+	return true
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// feedbackBatchWindow is how long pad feedback gathers before it is sent, so the pads
+// of a chord light in one message per device instead of one each
+const feedbackBatchWindow = 4 * time.Millisecond
+
+// queuedFeedback is a pad color waiting for flushFeedback, and the menu it was for
+type queuedFeedback struct {
+	menuID string
+	color  midi.PadColor
+}
+
+// queueFeedback lights a pad of a device with the next feedback batch. A pad queued
+// twice in one batch, e.g. pressed and released, is sent once in its latest color.
+func (e *Engine) queueFeedback(deviceID, menuID string, row, col int, color midi.PadColor) {
+	pads := e.feedbackBatch[deviceID]
+	if pads == nil {
+		pads = map[[2]int]queuedFeedback{}
+		e.feedbackBatch[deviceID] = pads
+	}
+	pads[[2]int{row, col}] = queuedFeedback{menuID: menuID, color: color}
+
+	if e.feedbackTimer == nil {
+		e.feedbackTimer = time.AfterFunc(feedbackBatchWindow, func() { e.dispatch(e.flushFeedback) })
+	}
+}
+
+// flushFeedback sends the queued pad feedback, one batch per device in the order the
+// devices are configured. Pads queued for a menu the device no longer shows are dropped.
+func (e *Engine) flushFeedback() {
+	if e.feedbackTimer != nil {
+		e.feedbackTimer.Stop()
+		e.feedbackTimer = nil
+	}
+	if len(e.feedbackBatch) == 0 {
+		return
+	}
+	batch := e.feedbackBatch
+	e.feedbackBatch = map[string]map[[2]int]queuedFeedback{}
+	for _, device := range e.cfg.Devices {
+		pads, ok := batch[device.ID]
+		if !ok || device.OutPort == "" || device.Disabled {
+			continue
+		}
+		active := e.activeMenu(device)
+		var updates []midi.PadUpdate
+		for pad, queued := range pads {
+			if active != nil && active.ID == queued.menuID {
+				updates = append(updates, midi.PadUpdate{Row: pad[0], Col: pad[1], Color: queued.color})
+			}
+		}
+		slices.SortFunc(updates, func(a, b midi.PadUpdate) int {
+			return cmp.Or(cmp.Compare(a.Row, b.Row), cmp.Compare(a.Col, b.Col))
+		})
+		if err := e.midiManager.SetPadColorsPriority(device.OutPort, midi.DeviceType(device.Type), updates); err != nil {
+			slog.Warn("Failed to set pad colors", "device", device.Name, "err", err)
+		}
+	}
+}
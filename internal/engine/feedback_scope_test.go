@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+func TestPressedFeedbackScope(t *testing.T) {
+	tests := []struct {
+		name            string
+		colorful        config.FeedbackScope
+		classic         config.FeedbackScope
+		classicSeesLPX  bool // A press on the colorful device lights the classic one
+		colorfulSeesLPS bool // And the other way round
+	}{
+		{"mirrored", config.FeedbackMirrored, config.FeedbackMirrored, true, true},
+		{"pressed device local", config.FeedbackLocal, config.FeedbackMirrored, false, false},
+		{"other device local", config.FeedbackMirrored, config.FeedbackLocal, false, false},
+		{"both local", config.FeedbackLocal, config.FeedbackLocal, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colorful, classic := colorfulDevice(), classicDevice()
+			colorful.PressedFeedbackScope, classic.PressedFeedbackScope = tt.colorful, tt.classic
+			cfg := testConfig(colorful, classic)
+			cfg.PadDebounceMs = 0
+			r := newRig(t, cfg)
+			r.do(r.e.InitializeDevices)
+
+			r.fake.Reset()
+			r.press(colorfulIn, 2, 3, true)
+			if len(r.fake.SentTo(colorfulOut)) == 0 {
+				t.Error("the pressed colorful device wasn't lit")
+			}
+			if got := len(r.fake.SentTo(classicOut)) > 0; got != tt.classicSeesLPX {
+				t.Errorf("classic device lit by the colorful press: %v, want %v", got, tt.classicSeesLPX)
+			}
+			r.press(colorfulIn, 2, 3, false)
+
+			r.fake.Reset()
+			r.press(classicIn, 2, 3, true)
+			if len(r.fake.SentTo(classicOut)) == 0 {
+				t.Error("the pressed classic device wasn't lit")
+			}
+			if got := len(r.fake.SentTo(colorfulOut)) > 0; got != tt.colorfulSeesLPS {
+				t.Errorf("colorful device lit by the classic press: %v, want %v", got, tt.colorfulSeesLPS)
+			}
+		})
+	}
+}
+
+// TestFeedbackNotFromPressReachesLocalDevices checks that lights not caused by a press,
+// like busy flashes, reach every device whatever its scope
+func TestFeedbackNotFromPressReachesLocalDevices(t *testing.T) {
+	colorful, classic := colorfulDevice(), classicDevice()
+	colorful.PressedFeedbackScope, classic.PressedFeedbackScope = config.FeedbackLocal, config.FeedbackLocal
+	r := newRig(t, testConfig(colorful, classic))
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	r.do(func() {
+		r.e.flashPad(busyPad{menuID: "main", row: 2, col: 3}, midi.PadColor{R: 127})
+		r.e.flushFeedback()
+	})
+	if len(r.fake.SentTo(colorfulOut)) == 0 || len(r.fake.SentTo(classicOut)) == 0 {
+		t.Errorf("flash reached colorful: %d, classic: %d messages; want both",
+			len(r.fake.SentTo(colorfulOut)), len(r.fake.SentTo(classicOut)))
+	}
+}
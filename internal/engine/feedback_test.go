@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// chordPads are ten pads of Main pressed together, each playing the synth note
+var chordPads = [][2]int{{3, 0}, {3, 1}, {3, 2}, {3, 3}, {3, 4}, {3, 5}, {3, 6}, {3, 7}, {3, 8}, {4, 0}}
+
+func chordConfig() *config.Config {
+	cfg := testConfig(colorfulDevice(), classicDevice())
+	cfg.PadDebounceMs = 0
+	for _, p := range chordPads {
+		cfg.Menus[0].Colors[p[0]][p[1]] = config.PadColorConfig{G: 100, PressedR: 127, ClassicG: 127, ClassicPressedR: 127,
+			ClassicInitialized: true, ClassicPressedInitialized: true, ActionID: "note"}
+	}
+	return cfg
+}
+
+// chord presses or releases every chord pad on the colorful device at once, then sends
+// the feedback batch and returns how many messages each Launchpad got
+func (r *testRig) chord(on bool) (colorful, classic int) {
+	r.fake.Reset()
+	r.do(func() {
+		for _, p := range chordPads {
+			r.e.handlePadPress("lpx", p[0], p[1], on, 127)
+		}
+	})
+	r.do(r.e.flushFeedback)
+	return len(r.fake.SentTo(colorfulOut)), len(r.fake.SentTo(classicOut))
+}
+
+func TestChordFeedbackIsBatched(t *testing.T) {
+	r := newRig(t, chordConfig())
+	r.do(r.e.InitializeDevices)
+
+	// Press, then release: the colorful Launchpad gets one message per phase. The
+	// Launchpad S can't light several pads in one message, so it gets one per pad.
+	for _, phase := range []struct {
+		name string
+		on   bool
+	}{{"press", true}, {"release", false}} {
+		colorful, classic := r.chord(phase.on)
+		if colorful != 1 {
+			t.Errorf("%s: colorful device got %d messages, want 1:\n%s", phase.name, colorful, r.wire(colorfulOut))
+		}
+		if classic != len(chordPads) {
+			t.Errorf("%s: classic device got %d messages, want %d", phase.name, classic, len(chordPads))
+		}
+	}
+	if got := r.waitSent(t, synthOut, len(chordPads)); len(got) != len(chordPads) {
+		t.Errorf("chord ran %d actions, want %d", len(got), len(chordPads))
+	}
+}
+
+// TestChordBatchDropsRedundantUpdates checks that pads pressed and released within one
+// batch are sent once, in their released color
+func TestChordBatchDropsRedundantUpdates(t *testing.T) {
+	r := newRig(t, chordConfig())
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	r.do(func() {
+		for _, on := range []bool{true, false} {
+			for _, p := range chordPads {
+				r.e.handlePadPress("lpx", p[0], p[1], on, 127)
+			}
+		}
+	})
+	r.do(r.e.flushFeedback)
+
+	// One lighting message with an LED spec per pad, each in the resting green
+	wire := r.wire(colorfulOut)
+	if n := len(r.fake.SentTo(colorfulOut)); n != 1 {
+		t.Fatalf("colorful device got %d messages, want 1:\n%s", n, wire)
+	}
+	want := "F0002029020D03"
+	for _, p := range chordPads {
+		want += fmt.Sprintf("03%02X00%02X00", (8-p[0])*10+p[1]+11, padcolor.Gamma(100))
+	}
+	if want += "F7\n"; wire != want {
+		t.Errorf("colorful device got\n%swant\n%s", wire, want)
+	}
+	if n := len(r.fake.SentTo(classicOut)); n != len(chordPads) {
+		t.Errorf("classic device got %d messages, want one per pad", n)
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	gomidi "gitlab.com/gomidi/midi/v2"
+)
+
+// TestInputFilter feeds a Launchpad messages the way a DAW port or custom mode sends
+// them and checks that only the filtered-in ones press pads
+func TestInputFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel uint8
+		cc      bool // Top row CC instead of a grid note
+		setup   func(r *testRig)
+		pressed bool
+	}{
+		{"programmer mode channel", 0, false, nil, true},
+		{"DAW channel", 2, false, nil, false},
+		{"every channel allowed", 2, false, func(r *testRig) { r.cfg.Devices[0].InputFilter.Channels = 0 }, true},
+		{"notes ignored", 0, false, func(r *testRig) { r.cfg.Devices[0].InputFilter.IgnoreNotes = true }, false},
+		{"CC allowed", 0, true, nil, true},
+		{"CC ignored", 0, true, func(r *testRig) { r.cfg.Devices[0].InputFilter.IgnoreCC = true }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(colorfulDevice())
+			cfg.Menus[0].Colors[0][0].ActionID = "note" // Top row pad, sent as CC 91
+			r := newRig(t, cfg)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			r.do(r.e.InitializeDevices)
+
+			msg := gomidi.NoteOn(tt.channel, 82, 127) // Pad 1,1
+			if tt.cc {
+				msg = gomidi.ControlChange(tt.channel, 91, 127)
+			}
+			r.fake.Inject(colorfulIn, msg)
+			r.do(r.e.flushFeedback)
+
+			if tt.pressed {
+				r.waitSent(t, synthOut, 1)
+			} else {
+				time.Sleep(50 * time.Millisecond)
+			}
+			if got := len(r.fake.SentTo(synthOut)) > 0; got != tt.pressed {
+				t.Errorf("action ran = %v, want %v", got, tt.pressed)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// TestFlashingPadOnClassic checks that a layout push to a Launchpad S writes a flashing
+// pad with the flash flags and turns flashing on once, after the pads
+func TestFlashingPadOnClassic(t *testing.T) {
+	cfg := testConfig(classicDevice())
+	cfg.Menus[0].Colors[2][3].LightMode = config.LightModeFlash
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+
+	lines := strings.Split(strings.TrimSpace(r.wire(classicOut)), "\n")
+	if last := lines[len(lines)-1]; last != "B00028" {
+		t.Errorf("last message %s, want flashing turned on (B00028)", last)
+	}
+	if n := strings.Count(r.wire(classicOut), "B00028"); n != 1 {
+		t.Errorf("flashing turned on %d times", n)
+	}
+	// Pad 2,3 is note 19, amber at level 2 (64) with the flash flags (steady it is 90132E)
+	if !strings.Contains(r.wire(classicOut), "90132A\n") {
+		t.Errorf("pad 2,3 not sent flashing:\n%s", r.wire(classicOut))
+	}
+}
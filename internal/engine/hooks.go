@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+const (
+	// connectHookDelay is how long a device must stay connected before its connect hook
+	// runs, so a flapping port doesn't run it over and over
+	connectHookDelay = 3 * time.Second
+
+	// shutdownHookTimeout bounds how long Shutdown waits for the app's shutdown action
+	// and the disconnect hooks, so a hung script can't keep the app from quitting
+	shutdownHookTimeout = 5 * time.Second
+)
+
+// PortsChanged updates which devices are connected from the current port lists, starting
+// the connect hooks of devices that appeared. It may be called from any goroutine.
+func (e *Engine) PortsChanged(inPorts, outPorts []string) {
+	e.dispatch(func() { e.updateConnections(inPorts, outPorts) })
+}
+
+// refreshConnections is updateConnections with the ports the MIDI manager lists now
+func (e *Engine) refreshConnections() {
+	e.updateConnections(e.midiManager.ListInPorts(), e.midiManager.ListOutPorts())
+}
+
+// updateConnections tracks devices whose ports are all present. A device that just
+// connected gets its connect hook run once it has stayed connected for connectHookDelay.
+// Devices that show up under another name are bound by their port patterns first.
+func (e *Engine) updateConnections(inPorts, outPorts []string) {
+	rebound := e.bindPorts(inPorts, outPorts)
+	seen := map[string]bool{}
+	pendingReady := false
+	for _, device := range e.cfg.Devices {
+		seen[device.ID] = true
+		connected := !device.Disabled && (device.InPort != "" || device.OutPort != "") &&
+			portsPresent(device, inPorts, outPorts)
+		switch {
+		case connected && !e.connected[device.ID]:
+			e.connected[device.ID] = true
+			e.scheduleConnectHook(device.ID)
+			pendingReady = pendingReady || e.pendingInit[device.ID]
+		case !connected && e.connected[device.ID]:
+			e.forgetConnection(device.ID)
+		}
+	}
+	for id := range e.connected {
+		if !seen[id] {
+			e.forgetConnection(id)
+		}
+	}
+	// Active devices start over on their new ports; pending ones are activated below
+	for _, id := range rebound {
+		active, ok := e.activeDevices[id]
+		if device := e.cfg.GetDevice(id); ok && device != nil && !e.pendingInit[id] {
+			e.teardownDevice(active)
+			e.ActivateDevice(*device)
+		}
+	}
+	if pendingReady {
+		e.activatePendingDevices() // Plugged in during startup, before the next retry
+	}
+}
+
+// bindPorts binds the ports of devices missing under their exact names by their port
+// patterns, see config.BindPorts, and returns the IDs of the devices rebound
+func (e *Engine) bindPorts(inPorts, outPorts []string) []string {
+	bound, err := e.cfg.BindPorts(inPorts, outPorts)
+	if err != nil {
+		slog.Error("Failed to bind ports by pattern", "err", err)
+	}
+	var ids []string
+	for _, b := range bound {
+		slog.Info("Bound port by pattern", "device", b.DeviceName, "input", b.IsInput, "from", b.From, "to", b.To)
+		if !slices.Contains(ids, b.DeviceID) {
+			ids = append(ids, b.DeviceID)
+		}
+	}
+	return ids
+}
+
+// scheduleConnectHook runs a device's connect hook after connectHookDelay, unless the
+// device disconnects first
+func (e *Engine) scheduleConnectHook(deviceID string) {
+	var timer *time.Timer
+	timer = time.AfterFunc(connectHookDelay, func() {
+		e.dispatch(func() {
+			if e.connectTimers[deviceID] != timer {
+				return // Disconnected meanwhile, or a later connection owns the hook
+			}
+			delete(e.connectTimers, deviceID)
+			if device := e.cfg.GetDevice(deviceID); device != nil && device.OnConnectActionID != "" {
+				slog.Info("Running connect hook", "device", device.Name)
+				e.Run(device.OnConnectActionID)
+			}
+		})
+	})
+	e.connectTimers[deviceID] = timer
+}
+
+// forgetConnection marks a device disconnected, cancelling a pending connect hook
+func (e *Engine) forgetConnection(deviceID string) {
+	if timer, ok := e.connectTimers[deviceID]; ok {
+		timer.Stop()
+		delete(e.connectTimers, deviceID)
+	}
+	delete(e.connected, deviceID)
+	delete(e.initialized, deviceID) // Programmer mode is lost when unplugged
+}
+
+// runDisconnectHook runs a connected device's disconnect hook. wg, if not nil, is
+// released once the hook has finished.
+func (e *Engine) runDisconnectHook(device config.DeviceConfig, wg *sync.WaitGroup) {
+	if !e.connected[device.ID] || device.OnDisconnectActionID == "" {
+		return
+	}
+	slog.Info("Running disconnect hook", "device", device.Name)
+	if wg == nil {
+		e.Run(device.OnDisconnectActionID)
+		return
+	}
+	wg.Add(1)
+	if !e.run(device.OnDisconnectActionID, "", func(error) { wg.Done() }) {
+		wg.Done()
+	}
+}
+
+// runAppHook runs the app's startup or shutdown action (by ID, "" for none) for hook.
+// wg, if not nil, is released once it has finished. Failing actions are reported like
+// any other, so a failure never holds up launch.
+func (e *Engine) runAppHook(hook, id string, wg *sync.WaitGroup) {
+	if id == "" {
+		return
+	}
+	slog.Info("Running app hook", "hook", hook)
+	if wg != nil {
+		wg.Add(1)
+	}
+	started := e.runFor(id, "", actions.Trigger{Hook: hook}, func(err error) {
+		if err != nil {
+			slog.Warn("App hook failed", "hook", hook, "err", err)
+		}
+		if wg != nil {
+			wg.Done()
+		}
+	})
+	if !started {
+		slog.Warn("App hook action not started", "hook", hook, "action", id)
+		if wg != nil {
+			wg.Done()
+		}
+	}
+}
+
+// runShutdownHooks runs the app's shutdown action and the disconnect hooks of every
+// connected device, and waits for them, at most shutdownHookTimeout
+func (e *Engine) runShutdownHooks() {
+	var wg sync.WaitGroup
+	e.runAppHook("shutdown", e.cfg.ShutdownActionID, &wg)
+	for _, device := range e.cfg.Devices {
+		e.runDisconnectHook(device, &wg)
+	}
+	for id := range e.connected {
+		e.forgetConnection(id)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(shutdownHookTimeout):
+		slog.Warn("Shutdown and disconnect hooks still running at shutdown", "timeout", shutdownHookTimeout)
+	}
+}
@@ -0,0 +1,309 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// handlePadPress runs the pad's action or plays its MIDI thru note and sends pressed/unpressed
+// color to all devices showing the same menu. The device and its menu are looked up at the
+// time of the press, so menu changes apply without restarting listeners. row and col are
+// in the device's own coordinates, as its listener reports them.
+func (e *Engine) handlePadPress(deviceID string, row, col int, isNoteOn bool, velocity uint8) {
+	device := e.cfg.GetDevice(deviceID)
+	if device == nil {
+		return // Removed since its listener started
+	}
+
+	// Debounce and thru track the physical pad, like the listener does; the rest
+	// works in the logical positions the menu editor shows
+	pad := padKey{deviceID, row, col}
+	row, col = device.Orientation.FromDevice(row, col)
+
+	// Pressing any other pad of the device cancels a pad waiting for confirmation
+	if isNoteOn {
+		e.disarmOthers(pad)
+	}
+
+	// The shift pad switches layers instead of triggering anything itself.
+	// Grouped devices act as one surface, so the whole group follows.
+	if device.HasShiftLayer() && device.ShiftPad.Row == row && device.ShiftPad.Col == col {
+		for _, id := range e.cfg.SyncedDeviceIDs(device.ID) {
+			if member := e.cfg.GetDevice(id); member != nil && member.HasShiftLayer() {
+				e.setShiftHeld(*member, isNoteOn)
+			}
+		}
+		return
+	}
+
+	// Page-select pads switch pages on press and are otherwise inert
+	if page, ok := device.PageAt(row, col); ok {
+		if isNoteOn {
+			target := device.ID
+			if group := e.cfg.GroupOf(device.ID); group != nil {
+				target = group.ID
+			}
+			e.SwitchTargetMenu(target, device.Pages[page], "page pad on "+device.Name)
+		}
+		return
+	}
+
+	// The global top row replaces the menu's row 0 on devices that use it
+	if binding, ok := e.topRowBinding(*device, row, col); ok {
+		e.pressTopRow(*device, pad, col, binding, isNoteOn)
+		return
+	}
+
+	// Find the menu the device is showing (shift menu, current page or main menu)
+	menu := e.activeMenu(*device)
+	if menu == nil {
+		return
+	}
+
+	if isNoteOn {
+		usage.PadPressed(menu.ID, row, col) // Bounced presses too, they show a flaky pad
+	}
+
+	padColor := menu.EffectiveColor(row, col)
+
+	// Thru pads play a note while held. The release usually went out from the listener
+	// already; this catches one that arrived before its press was handled.
+	if isNoteOn && padColor.Thru.Enabled() {
+		e.pressThru(pad, padColor.Thru, velocity)
+	} else if !isNoteOn {
+		e.releaseThru(pad)
+	}
+
+	// Execute assigned action on Note On (pad pressed). A bouncing contact can repeat
+	// the press within milliseconds; the repeat only gets the LED feedback below.
+	// Pads with neither an action nor a thru note run the layout's fallback action.
+	// Actions that need confirmation run on the second press; the first arms the pad.
+	runsAction := padColor.ActionID != "" && !(padColor.Thru.Enabled() && padColor.Thru.Mode == config.PadThruReplace)
+	arming := false
+	if isNoteOn && !e.bounced(pad, e.debounceWindow(padColor)) {
+		switch {
+		case runsAction:
+			if e.needsConfirmation(padColor.ActionID) && !e.pressConfirmed(pad, busyPad{menu.ID, row, col}) {
+				arming = true
+				break
+			}
+			e.recordPress(padColor.ActionID)
+			if !e.recording || !e.recordSilent {
+				e.runFromPad(busyPad{menu.ID, row, col}, padColor)
+			}
+		case padColor.ActionID == "" && !padColor.Thru.Enabled() && (!e.recording || !e.recordSilent):
+			e.runFallback(menu.FallbackActionID, row, col)
+		}
+	}
+
+	// Show the pressed color, and on release the pad's own color (or its busy color
+	// while the action it started is still running, or the armed color)
+	e.sendPadFeedback(menu.ID, row, col, device.ID, func(deviceType midi.DeviceType) midi.PadColor {
+		if isNoteOn && !arming {
+			r, g, b := padColor.PressedFor(config.DeviceType(deviceType))
+			return midi.PadColor{R: r, G: g, B: b}
+		}
+		return e.restingColor(deviceType, busyPad{menu.ID, row, col}, padColor)
+	})
+}
+
+// runFallback runs a layout's fallback action for a pad without one, passing it the pad
+// as "row col". A fallback doesn't start again while it runs, so one whose MIDI comes
+// back as a press, or that switches to a layout with the same fallback, can't loop.
+func (e *Engine) runFallback(id string, row, col int) {
+	if id == "" || e.fallbackRunning[id] {
+		return
+	}
+	// Marked first: with an inline dispatcher a quick action could finish before run returns
+	e.fallbackRunning[id] = true
+	if !e.run(id, fmt.Sprintf("%d %d", row, col), func(error) {
+		e.dispatch(func() { delete(e.fallbackRunning, id) })
+	}) {
+		delete(e.fallbackRunning, id)
+	}
+}
+
+// sendPadFeedback lights a pad on all devices currently showing a menu, in the color
+// returned for each device's type. A device sharing the main menu but not in the same
+// shift state shows a different grid, so it's skipped. Feedback for a press names the
+// device pressed as fromID; it only reaches other devices if neither keeps presses
+// local. Feedback for anything else has an empty fromID and reaches them all. It is
+// sent with the next feedback batch, see queueFeedback.
+func (e *Engine) sendPadFeedback(menuID string, row, col int, fromID string, color func(midi.DeviceType) midi.PadColor) {
+	fromLocal := false
+	if from := e.cfg.GetDevice(fromID); from != nil {
+		fromLocal = from.PressedFeedbackScope == config.FeedbackLocal
+	}
+	for _, shown := range e.cfg.Devices {
+		if shown.OutPort == "" || shown.Disabled || !shown.SendPressedFeedback {
+			continue
+		}
+		if fromID != "" && shown.ID != fromID && (fromLocal || shown.PressedFeedbackScope == config.FeedbackLocal) {
+			continue // Someone else's press
+		}
+		if active := e.activeMenu(shown); active == nil || active.ID != menuID {
+			continue
+		}
+		if _, ok := shown.PageAt(row, col); ok {
+			continue // Keep the page indicator lit
+		}
+		if _, ok := e.topRowBinding(shown, row, col); ok {
+			continue // Covered by the global top row
+		}
+
+		deviceType := midi.DeviceType(shown.Type)
+		midiColor := color(deviceType).Scaled(e.brightnessOf(shown))
+		deviceRow, deviceCol := shown.Orientation.ToDevice(row, col)
+		e.queueFeedback(shown.ID, menuID, deviceRow, deviceCol, midiColor)
+	}
+}
+
+// thruNote is a note a MIDI thru pad is holding on an output port
+type thruNote struct {
+	port          string
+	channel, note uint8
+}
+
+// pressThru starts the pad's thru note on the configured device's output port
+func (e *Engine) pressThru(pad padKey, thru config.PadThruConfig, velocity uint8) {
+	target := e.cfg.GetDevice(thru.DeviceID)
+	if target == nil || target.Disabled || target.OutPort == "" {
+		slog.Warn("MIDI thru device unavailable", "device", thru.DeviceID)
+		return
+	}
+	note := thruNote{port: target.OutPort, channel: uint8(thru.Channel), note: uint8(thru.Note)}
+	if thru.Note == config.PadThruSameNote {
+		note.note = uint8((8-pad.row)*10 + pad.col + 11) // Programmer mode numbering, row 0 on top
+	}
+
+	e.releaseThru(pad) // A press without a release must not stack notes
+	if err := e.midiManager.SendNote(note.port, note.channel, note.note, max(velocity, 1)); err != nil {
+		slog.Warn("Failed to send MIDI thru note", "port", note.port, "err", err)
+		return
+	}
+	e.thruMu.Lock()
+	e.thruHeld[pad] = note
+	e.thruMu.Unlock()
+}
+
+// releaseThru ends the note the pad is holding, if any. Safe to call from listener goroutines.
+func (e *Engine) releaseThru(pad padKey) {
+	e.thruMu.Lock()
+	note, held := e.thruHeld[pad]
+	delete(e.thruHeld, pad)
+	e.thruMu.Unlock()
+
+	if held {
+		if err := e.midiManager.SendNote(note.port, note.channel, note.note, 0); err != nil {
+			slog.Warn("Failed to release MIDI thru note", "port", note.port, "err", err)
+		}
+	}
+}
+
+// releaseAllThru ends every note held by the device's pads
+func (e *Engine) releaseAllThru(deviceID string) {
+	e.thruMu.Lock()
+	var pads []padKey
+	for pad := range e.thruHeld {
+		if pad.deviceID == deviceID {
+			pads = append(pads, pad)
+		}
+	}
+	e.thruMu.Unlock()
+
+	for _, pad := range pads {
+		e.releaseThru(pad)
+	}
+}
+
+// padKey identifies one pad of one device
+type padKey struct {
+	deviceID string
+	row, col int
+}
+
+// debounceWindow returns how long after a press of the pad another press is ignored
+func (e *Engine) debounceWindow(padColor config.PadColorConfig) time.Duration {
+	ms := e.cfg.PadDebounceMs
+	if padColor.DebounceMs > 0 {
+		ms = padColor.DebounceMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// bounced records a press of the pad and reports whether it came within window of the previous one
+func (e *Engine) bounced(pad padKey, window time.Duration) bool {
+	now := e.now()
+	last, pressed := e.lastPress[pad]
+	e.lastPress[pad] = now
+	return pressed && window > 0 && now.Sub(last) < window
+}
+
+// handleGenericMIDIMessage handles MIDI messages from Generic devices for inter-app communication
+func (e *Engine) handleGenericMIDIMessage(portName, msgType string, channel, number, value int) {
+	// Only trigger on "on" events (velocity/value > 0)
+	if value == 0 {
+		return
+	}
+
+	// Find matching message mappings
+	var matched []config.MessageMapping
+	for _, mapping := range e.cfg.MessageMappings {
+		if mappingMatches(mapping, msgType, channel, number) {
+			matched = append(matched, mapping)
+			e.Run(mapping.ActionID)
+		}
+	}
+	e.warnOverlaps(matched)
+}
+
+// overlapWarnInterval is how often the same pair of overlapping mappings is logged
+const overlapWarnInterval = time.Minute
+
+// warnOverlaps logs the pairs of mappings with actions that a message matched, unless
+// allowed to overlap, once per overlapWarnInterval for each pair
+func (e *Engine) warnOverlaps(matched []config.MessageMapping) {
+	if len(matched) < 2 {
+		return
+	}
+	now := e.now()
+	for i, a := range matched {
+		for _, b := range matched[i+1:] {
+			key := config.MappingPairKey(a.ID, b.ID)
+			if a.ActionID == "" || b.ActionID == "" || slices.Contains(e.cfg.AllowedOverlaps, key) {
+				continue
+			}
+			if last, ok := e.overlapWarned[key]; ok && now.Sub(last) < overlapWarnInterval {
+				continue
+			}
+			e.overlapWarned[key] = now
+			slog.Warn("One message matched several mappings, running them all", "mapping", a.Name, "other", b.Name)
+		}
+	}
+}
+
+// mappingMatches checks if a MIDI message matches a mapping
+func mappingMatches(mapping config.MessageMapping, msgType string, channel, number int) bool {
+	// Check message type
+	if mapping.MessageType != msgType {
+		return false
+	}
+
+	// Check channel (-1 means any channel)
+	if mapping.Channel != -1 && mapping.Channel != channel {
+		return false
+	}
+
+	// Check number
+	if mapping.Number != number {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// padFeedback presses a pad through handlePadPress and returns the feedback sent to the
+// colorful device, in hex
+func (r *testRig) padFeedback(row, col int, on bool) string {
+	r.fake.Reset()
+	r.do(func() { r.e.handlePadPress("lpx", row, col, on, 127) })
+	r.do(r.e.flushFeedback)
+	return r.wire(colorfulOut)
+}
+
+func TestHandlePadPressFeedback(t *testing.T) {
+	tests := []struct {
+		name        string
+		row, col    int
+		press, free string // feedback on press and on release
+		action      bool
+	}{
+		// Pad 1,1: red, green while pressed
+		{"own pressed color", 1, 1, "F0002029020D030352007F00F7\n", "F0002029020D0303527F0000F7\n", true},
+		// Pad 2,3: blue without a pressed color, so it goes dark while held
+		{"no pressed color", 2, 3, "F0002029020D03034A000000F7\n", "F0002029020D03034A000020F7\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRig(t, testConfig(colorfulDevice()))
+			r.do(r.e.InitializeDevices)
+			if got := r.padFeedback(tt.row, tt.col, true); got != tt.press {
+				t.Errorf("press sent %q, want %q", got, tt.press)
+			}
+			if tt.action {
+				r.waitSent(t, synthOut, 1)
+			}
+			if got := r.padFeedback(tt.row, tt.col, false); got != tt.free {
+				t.Errorf("release sent %q, want %q", got, tt.free)
+			}
+		})
+	}
+}
+
+func TestHandlePadPressDefaultPressedColor(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.Menus[0].DefaultPressed = config.PadColorConfig{PressedR: 127, PressedB: 64}
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+
+	// Pad 2,3 has no pressed color of its own, so it shows the layout's
+	if got, want := r.padFeedback(2, 3, true), "F0002029020D03034A7F0020F7\n"; got != want {
+		t.Errorf("press sent %q, want %q", got, want)
+	}
+	// Pad 1,1 keeps its own
+	if got, want := r.padFeedback(1, 1, true), "F0002029020D030352007F00F7\n"; got != want {
+		t.Errorf("press sent %q, want %q", got, want)
+	}
+}
+
+func TestHandlePadPressRunsAction(t *testing.T) {
+	r := newRig(t, testConfig(colorfulDevice()))
+	r.do(r.e.InitializeDevices)
+
+	r.padFeedback(1, 1, true)
+	if sent := r.waitSent(t, synthOut, 1); len(sent) != 1 {
+		t.Fatalf("synth got %d messages, want 1", len(sent))
+	}
+
+	// The release doesn't run it again, and pads without an action run nothing
+	r.padFeedback(1, 1, false)
+	r.padFeedback(2, 3, true)
+	r.padFeedback(2, 3, false)
+	if got := r.wire(synthOut); got != "" {
+		t.Errorf("synth got %q after the first note, want nothing", got)
+	}
+}
+
+func TestHandlePadPressUnknownDevice(t *testing.T) {
+	r := newRig(t, testConfig(colorfulDevice()))
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	r.do(func() { r.e.handlePadPress("removed", 1, 1, true, 127) })
+	r.do(r.e.flushFeedback)
+	if sent := r.fake.Sent(); len(sent) != 0 {
+		t.Errorf("sent %v for a removed device, want nothing", sent)
+	}
+}
+
+// noteStep is a MIDI action in a group playing a note on the synth, waited for by the next one
+func noteStep(id, group string, order int, note uint8) actions.Action {
+	return actions.Action{ID: id, Name: id, Type: actions.ActionTypeMidi, ParentGroupID: group, Order: order,
+		WaitForCompletion: true,
+		Code:              fmt.Sprintf(`{"device_name": %q, "msg_type": "note_on", "channel": 1, "note": %d, "velocity": 100}`, synthOut, note)}
+}
+
+func TestHandlePadPressRunsNestedGroup(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.GetMenu("main").Colors[1][1].ActionID = "outer"
+	// Stored out of order; the pad runs them in the sorted tree's order: nested groups
+	// first, then the group's own actions, each by Order
+	cfg.ActionGroups = []actions.ActionGroup{
+		{ID: "inner", Name: "Inner", ParentGroupID: "outer", Order: 1},
+		{ID: "outer", Name: "Outer"},
+	}
+	cfg.Actions = []actions.Action{
+		noteStep("last", "outer", 2, 63),
+		noteStep("inner second", "inner", 1, 61),
+		noteStep("first", "outer", 0, 62),
+		noteStep("inner first", "inner", 0, 60),
+	}
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+
+	r.padFeedback(1, 1, true)
+	r.waitSent(t, synthOut, 4)
+	if got, want := r.wire(synthOut), "903C64\n903D64\n903E64\n903F64\n"; got != want {
+		t.Errorf("synth got\n%swant the notes in order\n%s", got, want)
+	}
+}
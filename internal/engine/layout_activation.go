@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// activateLayouts runs the activation action of each layout the given devices switched
+// to since they were last seen here, e.g. to load a matching preset on outboard gear,
+// after queueing the devices' own menu hooks. Devices switching to a layout in one call
+// share one run, unless one is set to run layout actions on its own. trigger says what
+// switched them, for the log.
+func (e *Engine) activateLayouts(trigger string, deviceIDs ...string) {
+	type activation struct {
+		actionID string
+		layout   string
+		devices  []string
+	}
+	var order []string
+	activations := map[string]*activation{}
+
+	for _, id := range deviceIDs {
+		device := e.cfg.GetDevice(id)
+		if device == nil || device.Disabled || e.pendingInit[id] {
+			continue // Switches to a layout once it is up
+		}
+		menu := e.baseMenu(*device)
+		if menu == nil {
+			delete(e.activeLayout, id)
+			continue
+		}
+		left := e.activeLayout[id]
+		if left == menu.ID {
+			continue
+		}
+		e.activeLayout[id] = menu.ID
+		e.queueMenuHooks(*device, left, menu)
+		if menu.OnActivateActionID == "" {
+			continue
+		}
+
+		key := menu.ID
+		if device.LayoutActionPerDevice {
+			key += "/" + id
+		}
+		a, ok := activations[key]
+		if !ok {
+			a = &activation{actionID: menu.OnActivateActionID, layout: menu.Name}
+			activations[key] = a
+			order = append(order, key)
+		}
+		a.devices = append(a.devices, device.Name)
+	}
+
+	// A scene run below can switch layouts itself; those switches are recorded but don't
+	// run actions, so two layouts whose scenes switch to each other can't loop
+	if e.activatingLayouts {
+		if len(order) > 0 {
+			slog.Warn("Not running layout activation actions of a switch by another one", "trigger", trigger)
+		}
+		return
+	}
+	e.activatingLayouts = true
+	defer func() { e.activatingLayouts = false }()
+
+	for _, key := range order {
+		a := activations[key]
+		slog.Info("Running layout activation action", "layout", a.layout, "trigger", trigger, "devices", a.devices)
+		if !e.run(a.actionID, "", nil) {
+			slog.Warn("Layout activation action not found", "layout", a.layout, "action", a.actionID)
+		}
+	}
+}
+
+// menuHook is a device's exit or enter action waiting to run
+type menuHook struct {
+	actionID string
+	trigger  actions.Trigger // Also describes the hook in the log
+}
+
+// queueMenuHooks queues a device's exit hook for the menu it left (by ID, "" for none)
+// and its enter hook for the menu it entered. Like layout activation actions, they
+// don't run for switches made by a hook or activation action.
+func (e *Engine) queueMenuHooks(device config.DeviceConfig, leftID string, entered *config.MenuLayout) {
+	var hooks []menuHook
+	if left := e.cfg.GetMenu(leftID); left != nil && device.OnExitActionID[left.ID] != "" {
+		hooks = append(hooks, menuHook{device.OnExitActionID[left.ID], actions.Trigger{Hook: "exit", Device: device.Name, Menu: left.Name}})
+	}
+	if id := device.OnEnterActionID[entered.ID]; id != "" {
+		hooks = append(hooks, menuHook{id, actions.Trigger{Hook: "enter", Device: device.Name, Menu: entered.Name}})
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	if e.activatingLayouts {
+		slog.Warn("Not running menu hooks of a switch by another action", "device", device.Name)
+		return
+	}
+
+	_, running := e.menuHooks[device.ID]
+	e.menuHooks[device.ID] = append(e.menuHooks[device.ID], hooks...)
+	if !running {
+		e.runNextMenuHook(device.ID)
+	}
+}
+
+// runNextMenuHook starts a device's next queued menu hook. Each starts once the one
+// before has finished, so hooks of rapid switches run in the order of the switches.
+func (e *Engine) runNextMenuHook(deviceID string) {
+	for {
+		queue := e.menuHooks[deviceID]
+		if len(queue) == 0 {
+			delete(e.menuHooks, deviceID)
+			return
+		}
+		hook := queue[0]
+		e.menuHooks[deviceID] = queue[1:]
+
+		t := hook.trigger
+		slog.Info("Running menu hook", "hook", t.Hook, "device", t.Device, "menu", t.Menu)
+		e.activatingLayouts = true // Switches the hook makes run no hooks or actions
+		started := e.runFor(hook.actionID, "", t, func(error) {
+			e.dispatch(func() { e.runNextMenuHook(deviceID) })
+		})
+		e.activatingLayouts = false
+		if started {
+			return
+		}
+		slog.Warn("Menu hook action not started", "hook", t.Hook, "device", t.Device, "action", hook.actionID)
+	}
+}
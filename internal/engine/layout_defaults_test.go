@@ -0,0 +1,38 @@
+package engine
+
+import "testing"
+
+// TestPressUsesLayoutDefaults checks that pads without colors of their own light up in
+// the layout defaults, and that a default change shows on the next press
+func TestPressUsesLayoutDefaults(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.Menus[0].DefaultStatic.G = 127
+	cfg.Menus[0].DefaultPressed.PressedR = 127
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	// Pad 2,3 (LED 74) is blue of its own and inherits the pressed color
+	r.press(colorfulIn, 2, 3, true)
+	r.press(colorfulIn, 2, 3, false)
+	if got, want := r.wire(colorfulOut), "F0002029020D03034A7F0000F7\nF0002029020D03034A000020F7\n"; got != want {
+		t.Errorf("pad 2,3 sent %q, want %q", got, want)
+	}
+
+	// Pad 5,5 (LED 46) sets nothing and rests in the default static color
+	r.fake.Reset()
+	r.press(colorfulIn, 5, 5, true)
+	r.press(colorfulIn, 5, 5, false)
+	if got, want := r.wire(colorfulOut), "F0002029020D03032E7F0000F7\nF0002029020D03032E007F00F7\n"; got != want {
+		t.Errorf("pad 5,5 sent %q, want %q", got, want)
+	}
+
+	// Pad 1,1 (LED 82) has its own pressed green
+	r.fake.Reset()
+	r.do(func() { r.cfg.Menus[0].DefaultPressed.PressedR = 0; r.cfg.Menus[0].DefaultPressed.PressedB = 127 })
+	r.press(colorfulIn, 1, 1, true)
+	r.press(colorfulIn, 5, 5, true)
+	if got, want := r.wire(colorfulOut), "F0002029020D030352007F00F7\nF0002029020D03032E00007FF7\n"; got != want {
+		t.Errorf("after changing the default sent %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// pressOnce presses and releases pad 1,1 on the colorful device and returns the notes its
+// action played on the synth
+func (r *testRig) pressOnce(t *testing.T) int {
+	t.Helper()
+	r.fake.Reset()
+	r.press(colorfulIn, 1, 1, true)
+	r.press(colorfulIn, 1, 1, false)
+	r.waitSent(t, synthOut, 1)
+	time.Sleep(20 * time.Millisecond) // A second run would have started by now
+	return len(r.fake.SentTo(synthOut))
+}
+
+// listening returns the device listening on each input port
+func (r *testRig) listening() map[string]string {
+	owners := map[string]string{}
+	r.do(func() {
+		for port, l := range r.e.listeners {
+			owners[port] = l.deviceID
+		}
+	})
+	return owners
+}
+
+func TestListenersFireOnce(t *testing.T) {
+	tests := []struct {
+		name  string
+		drive func(r *testRig)
+	}{
+		{"initialized again", func(r *testRig) {
+			r.do(r.e.InitializeDevices)
+			r.do(r.e.InitializeDevices)
+		}},
+		{"started again", func(r *testRig) {
+			r.do(r.e.StartListeners)
+			r.do(r.e.StartListeners)
+		}},
+		{"device started again", func(r *testRig) {
+			r.do(func() {
+				r.e.startDeviceListener(r.cfg.Devices[0])
+				r.e.ActivateDevice(r.cfg.Devices[0])
+			})
+		}},
+		{"device listed twice", func(r *testRig) {
+			twin := colorfulDevice()
+			twin.ID, twin.Name = "lpx-2", "Launchpad X again"
+			r.do(func() {
+				r.cfg.Devices = append(r.cfg.Devices, twin)
+				r.e.StartListeners()
+				r.e.startDeviceListener(twin)
+			})
+		}},
+		{"filter changed", func(r *testRig) {
+			r.do(func() {
+				r.cfg.Devices[0].InputFilter.IgnoreCC = true
+				r.e.StartListeners()
+				r.e.StartListeners()
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRig(t, testConfig(colorfulDevice()))
+			r.do(r.e.InitializeDevices)
+			tt.drive(r)
+
+			if got, want := r.listening(), map[string]string{colorfulIn: "lpx"}; !maps.Equal(got, want) {
+				t.Errorf("listening = %v, want %v", got, want)
+			}
+			if n := r.pressOnce(t); n != 1 {
+				t.Errorf("one press ran the action %d times", n)
+			}
+		})
+	}
+}
+
+func TestListenersFollowConfig(t *testing.T) {
+	r := newRig(t, testConfig(colorfulDevice(), classicDevice()))
+	r.do(r.e.InitializeDevices)
+
+	steps := []struct {
+		name string
+		edit func(cfg *config.Config)
+		want map[string]string
+	}{
+		{"both", func(cfg *config.Config) {}, map[string]string{colorfulIn: "lpx", classicIn: "lps"}},
+		{"disabled", func(cfg *config.Config) { cfg.Devices[1].Disabled = true }, map[string]string{colorfulIn: "lpx"}},
+		{"removed", func(cfg *config.Config) { cfg.Devices = []config.DeviceConfig{classicDevice()} }, map[string]string{classicIn: "lps"}},
+		{"moved", func(cfg *config.Config) { cfg.Devices[0].InPort = colorfulIn }, map[string]string{colorfulIn: "lps"}},
+	}
+	for _, step := range steps {
+		r.do(func() {
+			step.edit(r.cfg)
+			r.e.StartListeners()
+		})
+		if got := r.listening(); !maps.Equal(got, step.want) {
+			t.Errorf("%s: listening = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	r.do(r.e.StopListeners)
+	if got := r.listening(); len(got) != 0 {
+		t.Errorf("listening = %v after stopping, want nothing", got)
+	}
+}
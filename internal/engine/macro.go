@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// StartMacroRecording starts capturing the actions pads run, in order and with their
+// timing. Pads without an action aren't captured. When silent, captured presses don't
+// run their actions. Call it on the goroutine that owns the config.
+func (e *Engine) StartMacroRecording(silent bool) {
+	e.recording, e.recordSilent = true, silent
+	e.recorded = nil
+	slog.Info("Macro recording started", "silent", silent)
+}
+
+// StopMacroRecording ends recording and returns the captured presses
+func (e *Engine) StopMacroRecording() []actions.MacroStep {
+	steps := e.recorded
+	e.recording, e.recordSilent = false, false
+	e.recorded = nil
+	slog.Info("Macro recording stopped", "presses", len(steps))
+	return steps
+}
+
+// MacroRecording reports whether a macro is being recorded
+func (e *Engine) MacroRecording() bool {
+	return e.recording
+}
+
+// recordPress captures a pad press running actionID while recording
+func (e *Engine) recordPress(actionID string) {
+	if e.recording {
+		e.recorded = append(e.recorded, actions.MacroStep{ActionID: actionID, At: e.now()})
+	}
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// withOtherMenu adds a layout "Other" whose pad 1,1 is blue and plays note 61
+func withOtherMenu(cfg *config.Config) *config.Config {
+	other := config.NewMenuLayout()
+	other.ID, other.Name = "other", "Other"
+	other.Colors[1][1] = config.PadColorConfig{B: 127, PressedR: 127, ActionID: "other"}
+	cfg.Menus = append(cfg.Menus, other)
+	cfg.Actions = append(cfg.Actions, actions.Action{
+		ID: "other", Name: "Play other note", Type: actions.ActionTypeMidi,
+		Code: `{"device_name": "` + synthOut + `", "msg_type": "note_on", "channel": 1, "note": 61, "velocity": 100}`,
+	})
+	return cfg
+}
+
+func TestPressUsesReassignedMenu(t *testing.T) {
+	r := newRig(t, withOtherMenu(testConfig(colorfulDevice())))
+	r.do(r.e.InitializeDevices)
+	listener := r.listening()
+
+	// Reassigned the way the devices tab does it, then activated again
+	r.do(func() {
+		r.cfg.Devices[0].MainMenu = "Other"
+		r.e.InitializeDevices()
+	})
+	if got := r.listening(); len(got) != 1 || got[colorfulIn] != listener[colorfulIn] {
+		t.Errorf("listening = %v after the change, want %v", got, listener)
+	}
+	r.fake.Reset()
+
+	r.press(colorfulIn, 1, 1, true)
+	if got, want := r.wire(colorfulOut), "F0002029020D0303527F0000F7\n"; got != want {
+		t.Errorf("press sent %q, want Other's pressed color %q", got, want)
+	}
+	r.waitSent(t, synthOut, 1)
+	if got := r.wire(synthOut); got != "903D64\n" {
+		t.Errorf("synth got %q, want Other's note 903D64", got)
+	}
+
+	r.fake.Reset()
+	r.press(colorfulIn, 1, 1, false)
+	if got, want := r.wire(colorfulOut), "F0002029020D03035200007FF7\n"; got != want {
+		t.Errorf("release sent %q, want Other's color %q", got, want)
+	}
+}
+
+// TestPressUsesEditedMenu checks that a pad edited in the shown layout acts on the next
+// press, with nothing restarted
+func TestPressUsesEditedMenu(t *testing.T) {
+	r := newRig(t, withOtherMenu(testConfig(colorfulDevice())))
+	r.do(r.e.InitializeDevices)
+
+	r.do(func() { r.cfg.Menus[0].Colors[1][1].ActionID = "other" })
+	r.fake.Reset()
+	r.press(colorfulIn, 1, 1, true)
+	r.waitSent(t, synthOut, 1)
+	if got := r.wire(synthOut); got != "903D64\n" {
+		t.Errorf("synth got %q, want the edited action's note 903D64", got)
+	}
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// These resolve the names people type (e.g. on the command line) to IDs. IDs are
+// tried first, then names, case-insensitively. They are called from the control
+// socket's goroutine, so the lookups go through dispatchWait.
+
+// RunNamed runs an action, action group or scene by ID or name without waiting for it
+func (e *Engine) RunNamed(ref string) error {
+	var err error
+	e.dispatchWait(func() {
+		var id string
+		if id, err = e.resolveAction(ref); err == nil {
+			e.Run(id)
+		}
+	})
+	return err
+}
+
+// RunNamedAndWait runs an action, action group or scene by ID or name and waits for it to finish.
+// Used when there is no running instance to hand the request to.
+func (e *Engine) RunNamedAndWait(ref string) error {
+	var action *actions.Action
+	var steps []actions.Action
+	var budgets [][]groupBudget
+	var sceneDone chan error
+	var err error
+	e.dispatchWait(func() {
+		var id string
+		if id, err = e.resolveAction(ref); err != nil {
+			return
+		}
+		if e.cfg.GetScene(id) != nil {
+			sceneDone = make(chan error, 1)
+			if !e.run(id, "", func(err error) { sceneDone <- err }) {
+				sceneDone <- nil // Only switched devices
+			}
+		} else if a := e.actionStore.GetAction(id); a != nil {
+			snapshot := *a
+			action = &snapshot
+		} else {
+			steps = e.groupActions(e.actionStore.GetGroup(id))
+			budgets = e.stepBudgets(id, steps)
+		}
+		// Checked as for pads, before any of it runs
+		if action != nil && e.blockDangerous(action) {
+			err = fmt.Errorf("%s: %w", ref, ErrBlocked)
+		}
+		for i := range steps {
+			if e.blockDangerous(&steps[i]) {
+				err = fmt.Errorf("%s: %w", ref, ErrBlocked)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if sceneDone != nil {
+		return <-sceneDone
+	}
+	if action != nil {
+		_, err := e.executor.Execute(action)
+		return err
+	}
+	e.runSteps("action group", steps, stepHooks{budgets: budgets})
+	return nil
+}
+
+// ActionNames lists every action and group, indented to show nesting, then the scenes
+func (e *Engine) ActionNames() []string {
+	var names []string
+	e.dispatchWait(func() {
+		for _, item := range e.actionStore.GetFlatList() {
+			indent := strings.Repeat("  ", item.Depth)
+			if item.IsGroup {
+				names = append(names, indent+item.Group.Name+"/")
+			} else {
+				names = append(names, indent+item.Action.Name)
+			}
+		}
+		for _, scene := range e.cfg.Scenes {
+			names = append(names, scene.Name+" (scene)")
+		}
+	})
+	return names
+}
+
+// SwitchMenuNamed switches a device or device group (by ID or name) to a menu (by ID or name)
+func (e *Engine) SwitchMenuNamed(target, menu string) error {
+	var err error
+	e.dispatchWait(func() { err = e.switchMenuNamed(target, menu) })
+	return err
+}
+
+func (e *Engine) switchMenuNamed(target, menu string) error {
+	targetID := e.resolveTarget(target)
+	if targetID == "" {
+		return fmt.Errorf("no device or group named %q", target)
+	}
+
+	var menuID string
+	if m := e.cfg.GetMenu(menu); m != nil {
+		menuID = m.ID
+	} else {
+		for _, m := range e.cfg.Menus {
+			if strings.EqualFold(m.Name, menu) {
+				menuID = m.ID
+				break
+			}
+		}
+	}
+	if menuID == "" {
+		return fmt.Errorf("no menu named %q", menu)
+	}
+
+	e.SwitchTargetMenu(targetID, menuID, "control command")
+	return nil
+}
+
+// resolveAction finds the ID of an action, group or scene by ID or name
+func (e *Engine) resolveAction(ref string) (string, error) {
+	if e.actionStore.GetAction(ref) != nil || e.actionStore.GetGroup(ref) != nil || e.cfg.GetScene(ref) != nil {
+		return ref, nil
+	}
+	for _, scene := range e.cfg.Scenes {
+		if strings.EqualFold(scene.Name, ref) {
+			return scene.ID, nil
+		}
+	}
+
+	var matches []actions.TreeItem
+	for _, item := range e.actionStore.GetFlatList() {
+		name := ""
+		if item.IsGroup {
+			name = item.Group.Name
+		} else {
+			name = item.Action.Name
+		}
+		if strings.EqualFold(name, ref) {
+			matches = append(matches, item)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no action named %q", ref)
+	case 1:
+		if matches[0].IsGroup {
+			return matches[0].Group.ID, nil
+		}
+		return matches[0].Action.ID, nil
+	default:
+		return "", fmt.Errorf("%d actions are named %q, use its ID instead", len(matches), ref)
+	}
+}
+
+// resolveTarget finds the ID of a device or device group by ID or name
+func (e *Engine) resolveTarget(ref string) string {
+	if e.cfg.GetDevice(ref) != nil || e.cfg.GetDeviceGroup(ref) != nil {
+		return ref
+	}
+	for _, d := range e.cfg.Devices {
+		if strings.EqualFold(d.Name, ref) {
+			return d.ID
+		}
+	}
+	for _, g := range e.cfg.DeviceGroups {
+		if strings.EqualFold(g.Name, ref) {
+			return g.ID
+		}
+	}
+	return ""
+}
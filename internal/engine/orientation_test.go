@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// TestPressOnTurnedDevice checks that a press on a device turned by 90 degrees acts on
+// the logical pad in that place and lights the physical pad that was pressed
+func TestPressOnTurnedDevice(t *testing.T) {
+	device := colorfulDevice()
+	device.Orientation = 90
+	r := newRig(t, testConfig(device))
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	// Logical pad 1,1 plays the note; turned clockwise it is the device's pad 7,1 (LED 22)
+	r.press(colorfulIn, 7, 1, true)
+	if got, want := r.wire(colorfulOut), "F0002029020D030316007F00F7\n"; got != want {
+		t.Errorf("press sent %q, want the pressed color on LED 22 %q", got, want)
+	}
+	r.waitSent(t, synthOut, 1)
+	if got := r.wire(synthOut); got != "903C64\n" {
+		t.Errorf("synth got %q, want 903C64", got)
+	}
+
+	// The device's own pad 1,1 is logical pad 1,7, which does nothing
+	r.fake.Reset()
+	r.press(colorfulIn, 1, 1, true)
+	r.press(colorfulIn, 1, 1, false)
+	if got := r.wire(synthOut); got != "" {
+		t.Errorf("synth got %q from an unbound pad", got)
+	}
+}
+
+func TestOrientGrid(t *testing.T) {
+	var colors [9][9]midi.PadColor
+	colors[0][0] = midi.PadColor{R: 1}
+	colors[1][1] = midi.PadColor{G: 2}
+	colors[8][8] = midi.PadColor{B: 3}
+
+	tests := []struct {
+		o    config.Orientation
+		want map[[2]int]midi.PadColor // Lit pads in device coordinates
+	}{
+		{0, map[[2]int]midi.PadColor{{0, 0}: colors[0][0], {1, 1}: colors[1][1], {8, 8}: colors[8][8]}},
+		{90, map[[2]int]midi.PadColor{{8, 0}: colors[0][0], {7, 1}: colors[1][1], {0, 8}: colors[8][8]}},
+		{180, map[[2]int]midi.PadColor{{8, 8}: colors[0][0], {7, 7}: colors[1][1], {0, 0}: colors[8][8]}},
+		{270, map[[2]int]midi.PadColor{{0, 8}: colors[0][0], {1, 7}: colors[1][1], {8, 0}: colors[8][8]}},
+	}
+	for _, tt := range tests {
+		oriented := orientGrid(colors, tt.o)
+		for row := range 9 {
+			for col := range 9 {
+				if got, want := oriented[row][col], tt.want[[2]int{row, col}]; got != want {
+					t.Errorf("%d: device pad %d,%d = %+v, want %+v", tt.o, row, col, got, want)
+				}
+			}
+		}
+	}
+}
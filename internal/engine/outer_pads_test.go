@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// proDevice is a Launchpad Pro Mk3 on the colorful ports, showing Main
+func proDevice() config.DeviceConfig {
+	d := colorfulDevice()
+	d.ID, d.Name, d.Type = "lppro", "Launchpad Pro", config.DeviceTypePro
+	return d
+}
+
+func TestGridPushLightsOuterPads(t *testing.T) {
+	cfg := testConfig(proDevice())
+	menu := &cfg.Menus[0]
+	menu.TopLeftColor = config.PadColorConfig{R: 127}
+	menu.BottomRightColor = config.PadColorConfig{G: 127}
+	menu.LeftColors[0] = config.PadColorConfig{B: 127}
+	menu.ExtendedBottomColors[7] = config.PadColorConfig{R: 127, G: 127}
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	var err error
+	r.do(func() { err = r.e.SendGridToDevice(r.cfg.Devices[0]) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	wire := r.wire(colorfulOut)
+	for _, spec := range []string{"035A7F0000", "0309007F00", "035000007F", "036C7F7F00"} {
+		if !strings.Contains(wire, spec) {
+			t.Errorf("no colorspec %s in the grid push:\n%s", spec, wire)
+		}
+	}
+	if pad := "F0002029020E0303527F0000F7"; !strings.Contains(wire, pad) {
+		t.Errorf("grid pad 1,1 not lit with %s:\n%s", pad, wire)
+	}
+}
+
+func TestGridPushLeavesOuterPadsOffOtherDevices(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.Menus[0].TopLeftColor = config.PadColorConfig{R: 127}
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	var err error
+	r.do(func() { err = r.e.SendGridToDevice(r.cfg.Devices[0]) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent := r.fake.SentTo(colorfulOut); len(sent) != 81 {
+		t.Errorf("colorful device got %d messages, want the 81 grid pads", len(sent))
+	}
+}
+
+// TestBrightnessChangeBatchesPads checks that changing only a device's brightness
+// sends its lit pads in one batch, then the pads around the grid rescaled too, in as
+// many messages as they take
+func TestBrightnessChangeBatchesPads(t *testing.T) {
+	cfg := testConfig(proDevice())
+	cfg.Menus[0].TopLeftColor = config.PadColorConfig{R: 127}
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+	r.fake.Reset()
+
+	r.do(func() {
+		r.cfg.Devices[0].Brightness = 50
+		r.e.InitializeDevices()
+	})
+	sent := strings.Split(strings.TrimSuffix(r.wire(colorfulOut), "\n"), "\n")
+	if len(sent) < 2 {
+		t.Fatalf("got %d messages, want the lit pads then the pads around the grid:\n%s", len(sent), r.wire(colorfulOut))
+	}
+	// Pad 1,1 is LED 0x52, lit red, and pad 2,3 is LED 0x4A
+	if pads := sent[0]; !strings.Contains(pads, "0352") || !strings.Contains(pads, "034A") || strings.Contains(pads, "03527F") {
+		t.Errorf("batch %s doesn't light both pads at half brightness", pads)
+	}
+	if outer := strings.Join(sent[1:], ""); !strings.Contains(outer, "035A") || strings.Contains(outer, "035A7F") {
+		t.Errorf("pads around the grid %s don't rescale the top-left corner", outer)
+	}
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// actionTypePanic is run by a handler that panics, the way a buggy contributed type would
+const actionTypePanic actions.ActionType = "engine_test_panic"
+
+type panicHandler struct{}
+
+func (panicHandler) Execute(string) (string, error) { panic("boom") }
+func (panicHandler) Validate(string) error          { return nil }
+func (panicHandler) IsSupported() bool              { return true }
+
+func init() {
+	actions.RegisterHandler(actionTypePanic, actions.HandlerMeta{Name: "Panic"},
+		func(actions.Deps) actions.ActionHandler { return panicHandler{} })
+}
+
+// TestPanickingActionKeepsServicingPresses checks that a pad whose action panics is
+// reported as failed and later presses still run
+func TestPanickingActionKeepsServicingPresses(t *testing.T) {
+	cfg := testConfig(colorfulDevice())
+	cfg.Menus[0].Colors[2][3].ActionID = "boom"
+	cfg.Actions = append(cfg.Actions, actions.Action{ID: "boom", Name: "Buggy", Type: actionTypePanic, WaitForCompletion: true})
+	r := newRig(t, cfg)
+	r.do(r.e.InitializeDevices)
+
+	for range 2 {
+		r.press(colorfulIn, 2, 3, true)
+		r.press(colorfulIn, 2, 3, false)
+	}
+	r.do(func() {}) // Let the dispatched presses finish
+
+	r.fake.Reset()
+	r.press(colorfulIn, 1, 1, true)
+	if got, want := r.wire(colorfulOut), "F0002029020D030352007F00F7\n"; got != want {
+		t.Errorf("press after the panics sent %q, want %q", got, want)
+	}
+	r.waitSent(t, synthOut, 1)
+	if got := r.wire(synthOut); got != "903C64\n" {
+		t.Errorf("synth got %q after the panics, want 903C64", got)
+	}
+
+	// The failures are reported by the action's own goroutine
+	deadline := time.Now().Add(2 * time.Second)
+	s := r.e.Status().Snapshot()
+	for s.LastActionError == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		s = r.e.Status().Snapshot()
+	}
+	if s.LastActionName != "Buggy" || s.LastActionError == nil {
+		t.Errorf("status = %q, %v; want Buggy's failure", s.LastActionName, s.LastActionError)
+	}
+}
+
+// TestRecoverPanicReportsFailure checks what a panic in deferred-recovered work leaves behind
+func TestRecoverPanicReportsFailure(t *testing.T) {
+	r := newRig(t, testConfig())
+
+	r.do(func() {
+		defer r.e.recoverPanic("pad press on " + colorfulIn)
+		var grid [][]int
+		_ = grid[3][3] // Out of range
+	})
+	r.do(func() {}) // The dispatcher is still running
+
+	s := r.e.Status().Snapshot()
+	if s.LastActionName != "pad press on "+colorfulIn || s.LastActionError == nil {
+		t.Errorf("status = %q, %v; want the pad press's failure", s.LastActionName, s.LastActionError)
+	}
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// These back the remote interfaces (HTTP API), which address everything by ID. They
+// are called from request goroutines, so config access goes through dispatchWait.
+
+// ErrNotFound is returned when an action, device or menu ID doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// ActionInfo describes an action or action group for remote clients
+type ActionInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsGroup bool   `json:"is_group,omitempty"`
+	IsScene bool   `json:"is_scene,omitempty"`
+	Depth   int    `json:"depth,omitempty"` // Nesting level in the action tree
+}
+
+// RunResult reports how a remotely triggered action went
+type RunResult struct {
+	Waited bool   `json:"waited"`           // The action has WaitForCompletion set and has finished
+	Output string `json:"output,omitempty"` // Output of a finished action
+}
+
+// DeviceState is a device's configuration and runtime state for remote clients
+type DeviceState struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	Connected bool   `json:"connected"`      // All of the device's ports are present
+	Menu      string `json:"menu,omitempty"` // Name of the menu being shown
+}
+
+// ActionList returns every action and group in tree order, followed by the scenes
+func (e *Engine) ActionList() []ActionInfo {
+	var list []ActionInfo
+	e.dispatchWait(func() {
+		for _, item := range e.actionStore.GetFlatList() {
+			if item.IsGroup {
+				list = append(list, ActionInfo{ID: item.Group.ID, Name: item.Group.Name, IsGroup: true, Depth: item.Depth})
+			} else {
+				list = append(list, ActionInfo{ID: item.Action.ID, Name: item.Action.Name, Depth: item.Depth})
+			}
+		}
+		for _, scene := range e.cfg.Scenes {
+			list = append(list, ActionInfo{ID: scene.ID, Name: scene.Name, IsScene: true})
+		}
+	})
+	return list
+}
+
+// RunAction runs an action, group or scene by ID. Actions set to wait for completion are run
+// to the end so their output can be returned; everything else is started in the background.
+func (e *Engine) RunAction(id string) (RunResult, error) {
+	var waitFor *actions.Action
+	var err error
+	e.dispatchWait(func() {
+		if action := e.actionStore.GetAction(id); action != nil && action.WaitForCompletion {
+			snapshot := *action
+			if e.blockDangerous(&snapshot) {
+				err = fmt.Errorf("action %s: %w", id, ErrBlocked)
+				return
+			}
+			waitFor = &snapshot
+			return
+		}
+		if e.actionStore.GetAction(id) == nil && e.actionStore.GetGroup(id) == nil && e.cfg.GetScene(id) == nil {
+			err = fmt.Errorf("action %s: %w", id, ErrNotFound)
+			return
+		}
+		e.Run(id)
+	})
+	if waitFor == nil {
+		return RunResult{}, err
+	}
+
+	output, err := e.execute(context.Background(), waitFor)
+	return RunResult{Waited: true, Output: output}, err
+}
+
+// SwitchMenu switches a device or device group to a menu, both by ID
+func (e *Engine) SwitchMenu(targetID, menuID string) error {
+	var err error
+	e.dispatchWait(func() {
+		if e.cfg.GetDevice(targetID) == nil && e.cfg.GetDeviceGroup(targetID) == nil {
+			err = fmt.Errorf("device %s: %w", targetID, ErrNotFound)
+			return
+		}
+		if e.cfg.GetMenu(menuID) == nil {
+			err = fmt.Errorf("menu %s: %w", menuID, ErrNotFound)
+			return
+		}
+		e.SwitchTargetMenu(targetID, menuID, "HTTP API")
+	})
+	return err
+}
+
+// DeviceStates reports every configured device with its connection and current menu
+func (e *Engine) DeviceStates() []DeviceState {
+	inPorts := e.midiManager.ListInPorts()
+	outPorts := e.midiManager.ListOutPorts()
+
+	var states []DeviceState
+	e.dispatchWait(func() {
+		states = make([]DeviceState, 0, len(e.cfg.Devices))
+		for _, device := range e.cfg.Devices {
+			state := DeviceState{
+				ID:      device.ID,
+				Name:    device.Name,
+				Enabled: !device.Disabled,
+				Connected: (device.InPort == "" || slices.Contains(inPorts, device.InPort)) &&
+					(device.OutPort == "" || slices.Contains(outPorts, device.OutPort)),
+			}
+			if menu := e.activeMenu(device); menu != nil {
+				state.Menu = menu.Name
+			}
+			states = append(states, state)
+		}
+	})
+	return states
+}
@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// TestInitializeDevicesClearsSentinel checks that a finished initialization clears the
+// mark left by one that crashed, so the next launch starts normally
+func TestInitializeDevicesClearsSentinel(t *testing.T) {
+	r := newRig(t, testConfig(colorfulDevice()))
+	if err := config.BeginDeviceInit(); err != nil { // As left by a crash
+		t.Fatal(err)
+	}
+
+	r.do(r.e.InitializeDevices)
+	if config.DeviceInitInterrupted() {
+		t.Error("mark left after initialization finished")
+	}
+}
+
+// TestInitializeDevicesPanicLeavesSentinel checks that the mark stays when
+// initialization panics, the way a bad SysEx crashing the app would
+func TestInitializeDevicesPanicLeavesSentinel(t *testing.T) {
+	r := newRig(t, testConfig(colorfulDevice()))
+	r.do(func() {
+		defer func() { _ = recover() }()
+		r.e.cfg = nil // Panics at the first device lookup
+		defer func() { r.e.cfg = r.cfg }()
+		r.e.InitializeDevices()
+	})
+	if !config.DeviceInitInterrupted() {
+		t.Error("no mark after initialization panicked")
+	}
+}
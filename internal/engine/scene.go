@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// runScene switches the scene's devices to their menus and brightnesses, then starts its
+// action like run. It reports whether the action was started.
+func (e *Engine) runScene(scene *config.Scene, args string, trigger actions.Trigger, done func(error)) bool {
+	slog.Info("Running scene", "scene", scene.Name)
+	var switched []string // Devices switched, whose layouts are activated once after all states
+	for _, state := range scene.Devices {
+		ids := e.cfg.TargetDeviceIDs(state.TargetID)
+		if state.Brightness > 0 {
+			e.stateMu.Lock()
+			for _, id := range ids {
+				e.brightness[id] = min(state.Brightness, 100)
+			}
+			e.stateMu.Unlock()
+			e.scheduleSessionSave()
+		}
+
+		if state.MenuID != "" && e.cfg.GetMenu(state.MenuID) != nil {
+			switched = append(switched, e.switchTargetMenu(state.TargetID, state.MenuID)...)
+		}
+		if state.Brightness > 0 {
+			// Switching resends only devices whose menu changed
+			for _, id := range ids {
+				if device := e.cfg.GetDevice(id); device != nil && device.OutPort != "" {
+					if err := e.SendGridToDevice(*device); err != nil {
+						slog.Warn("Failed to send layout", "device", device.Name, "err", err)
+					}
+				}
+			}
+		}
+	}
+	e.activateLayouts("scene "+scene.Name, switched...)
+
+	if scene.ActionID == "" {
+		return false
+	}
+	return e.startSteps(scene.ActionID, args, trigger, done)
+}
+
+// brightnessOf returns the brightness a device's pads are sent with: what a scene set
+// since devices were last initialized, otherwise its configured brightness
+func (e *Engine) brightnessOf(device config.DeviceConfig) int {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+	if b, ok := e.brightness[device.ID]; ok {
+		return b
+	}
+	return device.Brightness
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// sessionFileName is the runtime state file in the config directory, apart from
+// config.json so writing it never touches what the user configured
+const sessionFileName = "session.json"
+
+// sessionSaveDelay is how long runtime state has to settle before it is written, so
+// paging through layouts writes the file once
+const sessionSaveDelay = time.Second
+
+// session is the runtime device state a restart starts from, as stored in session.json
+type session struct {
+	Menus      map[string]string `json:"menus,omitempty"`      // Device ID -> menu ID switched to at runtime
+	Brightness map[string]int    `json:"brightness,omitempty"` // Device ID -> brightness set by a scene
+}
+
+// sessionPath returns the full path to the session file
+func sessionPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionFileName), nil
+}
+
+// loadSession reads the session file; without one, the session is empty
+func loadSession() (session, error) {
+	var s session
+	path, err := sessionPath()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return session{}, fmt.Errorf("%s: %w", sessionFileName, err)
+	}
+	return s, nil
+}
+
+// restoreSession loads the runtime state the last run left, for the next
+// InitializeDevices to start the devices from, and saves changes to it from now on.
+// Only startup does this, so one-off runs from the command line leave the file alone.
+func (e *Engine) restoreSession() {
+	s, err := loadSession()
+	if err != nil {
+		slog.Warn("Devices start from their main layouts", "err", err)
+	}
+	e.restoredSession = &s
+
+	e.sessionMu.Lock()
+	e.sessionSaving = true
+	e.sessionMu.Unlock()
+}
+
+// applySession restores the menus and brightnesses of a loaded session; the caller
+// holds stateMu. Devices and menus deleted since are skipped.
+func (e *Engine) applySession(s session) {
+	for deviceID, menuID := range s.Menus {
+		if e.cfg.GetDevice(deviceID) == nil || e.cfg.GetMenu(menuID) == nil {
+			slog.Info("Not restoring a layout that no longer exists", "device", deviceID, "menu", menuID)
+			continue
+		}
+		e.selectedMenu[deviceID] = menuID
+	}
+	for deviceID, brightness := range s.Brightness {
+		if e.cfg.GetDevice(deviceID) != nil && brightness > 0 {
+			e.brightness[deviceID] = min(brightness, 100)
+		}
+	}
+}
+
+// scheduleSessionSave writes the runtime state to the session file once it has
+// settled for sessionSaveDelay
+func (e *Engine) scheduleSessionSave() {
+	e.sessionMu.Lock()
+	defer e.sessionMu.Unlock()
+	if !e.sessionSaving {
+		return
+	}
+	if e.sessionTimer != nil {
+		e.sessionTimer.Stop()
+	}
+	e.sessionTimer = time.AfterFunc(sessionSaveDelay, e.saveSession)
+}
+
+// flushSession writes a session save still waiting, now
+func (e *Engine) flushSession() {
+	e.sessionMu.Lock()
+	pending := e.sessionTimer != nil && e.sessionTimer.Stop()
+	e.sessionMu.Unlock()
+	if pending {
+		e.saveSession()
+	}
+}
+
+// saveSession writes the runtime state to the session file. It goes through a
+// temporary file, so a crash while writing leaves the last session intact.
+func (e *Engine) saveSession() {
+	e.stateMu.Lock()
+	s := session{Menus: map[string]string{}, Brightness: map[string]int{}}
+	for id, menuID := range e.selectedMenu {
+		s.Menus[id] = menuID
+	}
+	for id, brightness := range e.brightness {
+		s.Brightness[id] = brightness
+	}
+	e.stateMu.Unlock()
+
+	e.sessionMu.Lock() // One write at a time, a flush can overlap the timer
+	defer e.sessionMu.Unlock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err == nil {
+		var path string
+		if path, err = sessionPath(); err == nil {
+			if err = os.WriteFile(path+".tmp", data, 0644); err == nil {
+				err = os.Rename(path+".tmp", path)
+			}
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to save session", "err", err)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// simulatedDevice is the on-screen Launchpad, showing Main
+func simulatedDevice() config.DeviceConfig {
+	d := config.NewDeviceConfig()
+	d.ID, d.Name, d.Type = "sim", "Virtual Launchpad", config.DeviceTypeSimulated
+	d.InPort, d.OutPort, d.MainMenu = midi.SimulatorPortName, midi.SimulatorPortName, "Main"
+	d.InputFilter = config.DefaultInputFilter(d.Type)
+	return d
+}
+
+// waitPad waits for a pad of the simulator to show a color
+func waitPad(t *testing.T, sim *midi.Simulator, row, col int, want midi.PadColor) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for sim.Colors()[row][col] != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("pad %d,%d shows %+v, want %+v", row, col, sim.Colors()[row][col], want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestSimulatorPressLoop runs the whole press, action and light loop through the
+// on-screen Launchpad, beside a hardware device
+func TestSimulatorPressLoop(t *testing.T) {
+	cfg := testConfig(colorfulDevice(), simulatedDevice())
+	cfg.PadDebounceMs = 0
+	r := newRig(t, cfg)
+	sim := r.fake.OpenSimulator()
+	r.do(r.e.InitializeDevices)
+
+	waitPad(t, sim, 1, 1, midi.PadColor{R: 127})
+	waitPad(t, sim, 2, 3, midi.PadColor{B: 64})
+	r.fake.Reset()
+
+	sim.Press(1, 1, true)
+	waitPad(t, sim, 1, 1, midi.PadColor{G: 127}) // Pressed color
+	r.waitSent(t, synthOut, 1)
+	if got := r.wire(synthOut); got != "903C64\n" {
+		t.Errorf("synth wire = %q, want the pad's note %q", got, "903C64\n")
+	}
+	sim.Press(1, 1, false)
+	waitPad(t, sim, 1, 1, midi.PadColor{R: 127})
+
+	// The hardware device showing the same layout echoes the press
+	if wire := r.wire(colorfulOut); !strings.Contains(wire, "F0002029020D030352007F00F7") {
+		t.Errorf("the colorful device wasn't sent the pressed color:\n%s", wire)
+	}
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// startupRetryInterval is how often devices missing at launch are tried again
+const startupRetryInterval = 2 * time.Second
+
+// InitializeDevicesAtStartup initializes the devices after the configured startup delay,
+// then keeps bringing up devices whose ports show up late until all are up or the
+// retry time is over. Systems launching the app at login may not have listed USB MIDI
+// devices yet. Devices start from the pages and brightnesses of the last run, see
+// restoreSession. The app's startup action runs once the devices present at first are
+// up. It returns at once; the status bus reports Initializing until done.
+func (e *Engine) InitializeDevicesAtStartup() {
+	startup := e.cfg.StartupInit
+	e.status.SetInitializing(true)
+
+	go func() {
+		defer e.status.SetInitializing(false)
+		if startup.DelaySeconds > 0 {
+			slog.Info("Waiting before initializing devices", "delay", time.Duration(startup.DelaySeconds)*time.Second)
+			time.Sleep(time.Duration(startup.DelaySeconds) * time.Second)
+		}
+
+		e.dispatchWait(func() {
+			e.pendingInit = map[string]bool{} // Filled in by InitializeDevices
+			e.restoreSession()
+			e.InitializeDevices()
+			e.runAppHook("startup", e.cfg.StartupActionID, nil) // Devices still missing get their connect hooks
+		})
+		deadline := time.Now().Add(time.Duration(startup.RetrySeconds) * time.Second)
+		for attempt := 1; ; attempt++ {
+			var waiting []string
+			e.dispatchWait(func() {
+				waiting = e.pendingDeviceNames()
+				if len(waiting) == 0 || !time.Now().Before(deadline) {
+					e.pendingInit = nil // Hot-plugging no longer initializes devices
+				}
+			})
+			switch {
+			case len(waiting) == 0:
+				return
+			case !time.Now().Before(deadline):
+				slog.Warn("Gave up waiting for devices", "devices", waiting)
+				return
+			}
+
+			time.Sleep(startupRetryInterval)
+			slog.Info("Retrying device initialization", "attempt", attempt, "devices", waiting)
+			e.dispatchWait(e.activatePendingDevices)
+		}
+	}()
+}
+
+// markPendingDevices records the enabled devices whose ports are missing, while the
+// startup retries run
+func (e *Engine) markPendingDevices() {
+	if e.pendingInit == nil {
+		return
+	}
+	inPorts, outPorts := e.midiManager.ListInPorts(), e.midiManager.ListOutPorts()
+	for _, device := range e.cfg.Devices {
+		if !device.Disabled && (device.InPort != "" || device.OutPort != "") && !portsPresent(device, inPorts, outPorts) {
+			e.pendingInit[device.ID] = true
+		}
+	}
+}
+
+// activatePendingDevices activates the devices missing at startup whose ports are
+// there now. Each leaves the pending set before it is activated, so the retries and
+// hot-plug detection never both initialize one.
+func (e *Engine) activatePendingDevices() {
+	if len(e.pendingInit) == 0 {
+		return
+	}
+	inPorts, outPorts := e.midiManager.ListInPorts(), e.midiManager.ListOutPorts()
+	var ready []config.DeviceConfig
+	for id := range e.pendingInit {
+		device := e.cfg.GetDevice(id)
+		if device == nil || device.Disabled {
+			delete(e.pendingInit, id)
+		} else if portsPresent(*device, inPorts, outPorts) {
+			delete(e.pendingInit, id)
+			ready = append(ready, *device)
+		}
+	}
+	for _, device := range ready {
+		slog.Info("Device appeared, initializing", "device", device.Name)
+		e.ActivateDevice(device)
+	}
+}
+
+// pendingDeviceNames lists the devices still missing since startup
+func (e *Engine) pendingDeviceNames() []string {
+	var names []string
+	for id := range e.pendingInit {
+		if device := e.cfg.GetDevice(id); device != nil {
+			names = append(names, device.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// portsPresent reports whether every port a device uses is listed
+func portsPresent(device config.DeviceConfig, inPorts, outPorts []string) bool {
+	return (device.InPort == "" || slices.Contains(inPorts, device.InPort)) &&
+		(device.OutPort == "" || slices.Contains(outPorts, device.OutPort))
+}
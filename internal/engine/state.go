@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// activeMenu returns the menu a device is currently displaying: its shift menu while
+// the shift pad is held, otherwise the menu switched to at runtime, otherwise its
+// first page or main menu
+func (e *Engine) activeMenu(device config.DeviceConfig) *config.MenuLayout {
+	if shiftMenu := e.heldShiftMenu(device.ID); shiftMenu != nil {
+		return shiftMenu
+	}
+	return e.baseMenu(device)
+}
+
+// baseMenu returns the menu a device shows when its shift layer isn't engaged
+func (e *Engine) baseMenu(device config.DeviceConfig) *config.MenuLayout {
+	e.stateMu.Lock()
+	selectedID := e.selectedMenu[device.ID]
+	e.stateMu.Unlock()
+
+	if selectedID != "" {
+		if menu := e.cfg.GetMenu(selectedID); menu != nil {
+			return menu
+		}
+	}
+	if device.HasPages() {
+		return e.cfg.GetMenu(device.Pages[0])
+	}
+	if device.MainMenu == "" {
+		return nil
+	}
+	return e.cfg.GetMenuByName(device.MainMenu)
+}
+
+// heldShiftMenu returns the device's shift menu if its shift pad is currently held
+func (e *Engine) heldShiftMenu(deviceID string) *config.MenuLayout {
+	e.stateMu.Lock()
+	held := e.shiftHeld[deviceID]
+	e.stateMu.Unlock()
+	if !held {
+		return nil
+	}
+
+	device := e.cfg.GetDevice(deviceID)
+	if device == nil || !device.HasShiftLayer() {
+		return nil
+	}
+	return e.cfg.GetMenu(device.ShiftMenu)
+}
+
+// setShiftHeld switches a device between its main and shift layers and resends its grid.
+// Pads still held across the switch are simply repainted by the bulk send; their release
+// restores the color from whichever menu is showing at that point.
+func (e *Engine) setShiftHeld(device config.DeviceConfig, held bool) {
+	e.stateMu.Lock()
+	changed := e.shiftHeld[device.ID] != held
+	if held {
+		e.shiftHeld[device.ID] = true
+	} else {
+		delete(e.shiftHeld, device.ID)
+	}
+	e.stateMu.Unlock()
+
+	if !changed || device.OutPort == "" {
+		return
+	}
+	if err := e.SendGridToDevice(device); err != nil {
+		slog.Warn("Failed to switch layer", "device", device.Name, "err", err)
+	}
+}
+
+// switchDeviceMenu changes the menu a device shows and responds to, resending its grid.
+// Page-select pads use this (via SwitchTargetMenu), so anything else that switches a
+// device's menu should too.
+func (e *Engine) switchDeviceMenu(deviceID, menuID string) error {
+	device := e.cfg.GetDevice(deviceID)
+	if device == nil {
+		return nil
+	}
+
+	e.stateMu.Lock()
+	changed := e.selectedMenu[deviceID] != menuID
+	e.selectedMenu[deviceID] = menuID
+	e.stateMu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	e.scheduleSessionSave()
+	if device.PersistPage {
+		e.persistLastMenu(deviceID, menuID)
+	}
+	if device.OutPort == "" {
+		return nil
+	}
+	return e.SendGridToDevice(*device)
+}
+
+// SwitchTargetMenu switches a device, or every member of a device group, to a menu and
+// runs its activation action. trigger says what switched it, for the log.
+func (e *Engine) SwitchTargetMenu(targetID, menuID, trigger string) {
+	e.activateLayouts(trigger, e.switchTargetMenu(targetID, menuID)...)
+}
+
+// switchTargetMenu is SwitchTargetMenu without running the activation action, for
+// callers switching several targets at once. It returns the devices of the target.
+func (e *Engine) switchTargetMenu(targetID, menuID string) []string {
+	ids := e.cfg.TargetDeviceIDs(targetID)
+	for _, id := range ids {
+		if err := e.switchDeviceMenu(id, menuID); err != nil {
+			slog.Warn("Failed to switch menu", "err", err)
+		}
+	}
+	return ids
+}
+
+// ResetDeviceMenu forgets the layer and page a device was switched to at runtime, so it
+// shows its main menu again, e.g. after the main menu was reassigned, and queues a resend
+// of its grid (or blanking, if no menu is left)
+func (e *Engine) ResetDeviceMenu(deviceID string) {
+	device := e.cfg.GetDevice(deviceID)
+	if device == nil {
+		return
+	}
+
+	e.stateMu.Lock()
+	delete(e.shiftHeld, deviceID)
+	delete(e.selectedMenu, deviceID)
+	e.stateMu.Unlock()
+	e.scheduleSessionSave()
+	if _, ok := e.activeDevices[deviceID]; ok {
+		e.activeDevices[deviceID] = *device
+	}
+	e.activateLayouts("layout assigned", deviceID)
+
+	if device.OutPort == "" || device.Disabled || device.Type == config.DeviceTypeGeneric || !device.SendStaticLayout {
+		return
+	}
+	e.queueDeviceSend(deviceID, changeLayout)
+}
+
+// persistLastMenu records the device's current menu so it's restored on the next start.
+// Only the device entry on disk is touched, so unsaved edits aren't written out with it.
+func (e *Engine) persistLastMenu(deviceID, menuID string) {
+	if device := e.cfg.GetDevice(deviceID); device != nil {
+		device.LastMenu = menuID
+	}
+
+	go func() {
+		err := config.Update(func(saved *config.Config) {
+			if device := saved.GetDevice(deviceID); device != nil {
+				device.LastMenu = menuID
+			}
+		})
+		if err != nil {
+			slog.Error("Failed to save page", "err", err)
+		}
+	}()
+}
+
+// currentPage returns the index of the page a device is on, or -1 if it isn't on one of its pages
+func (e *Engine) currentPage(device config.DeviceConfig) int {
+	menu := e.baseMenu(device)
+	if menu == nil {
+		return -1
+	}
+	return slices.Index(device.Pages, menu.ID)
+}
+
+// applyPageIndicators lights the page-select pads over a device's outgoing grid
+func (e *Engine) applyPageIndicators(device config.DeviceConfig, colors *[9][9]midi.PadColor) {
+	if !device.HasPages() {
+		return
+	}
+
+	ps := device.PageSelect
+	current := e.currentPage(device)
+	brightness := e.brightnessOf(device)
+	for i := range device.Pages {
+		pad := ps.PadFor(i)
+		if pad.Row < 0 || pad.Row > 8 || pad.Col < 0 || pad.Col > 8 {
+			continue
+		}
+		c := midi.PadColor{R: ps.InactiveR, G: ps.InactiveG, B: ps.InactiveB}
+		if i == current {
+			c = midi.PadColor{R: ps.ActiveR, G: ps.ActiveG, B: ps.ActiveB}
+		}
+		colors[pad.Row][pad.Col] = c.Scaled(brightness)
+	}
+}
+
+// resetDeviceStates clears all runtime layer state and scene brightness, restoring persisted
+// pages, or at startup the pages and brightnesses of the last run's session
+func (e *Engine) resetDeviceStates() {
+	e.stateMu.Lock()
+	clear(e.shiftHeld)
+	clear(e.selectedMenu)
+	clear(e.brightness)
+	for _, device := range e.cfg.Devices {
+		if device.PersistPage && device.LastMenu != "" && e.cfg.GetMenu(device.LastMenu) != nil {
+			e.selectedMenu[device.ID] = device.LastMenu
+		}
+	}
+	if e.restoredSession != nil {
+		e.applySession(*e.restoredSession)
+		e.restoredSession = nil
+	}
+	e.stateMu.Unlock()
+	e.scheduleSessionSave()
+}
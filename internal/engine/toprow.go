@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// topRowPressed is what a global top-row pad shows while held
+var topRowPressed = midi.PadColor{R: 127, G: 127, B: 127}
+
+// topRowBinding returns the global binding that replaces the menu's pad at row/col on
+// the device, if the device uses the top row and the column is bound
+func (e *Engine) topRowBinding(device config.DeviceConfig, row, col int) (config.TopRowBinding, bool) {
+	if !device.UseTopRow || device.Type == config.DeviceTypeGeneric {
+		return config.TopRowBinding{}, false
+	}
+	return e.cfg.TopRow.At(row, col)
+}
+
+// pressTopRow runs a global top-row pad: its action or scene, or a switch of the device
+// (or its group) to its menu. It lights up while held on the device pressing it.
+func (e *Engine) pressTopRow(device config.DeviceConfig, pad padKey, col int, binding config.TopRowBinding, isNoteOn bool) {
+	if isNoteOn && !e.bounced(pad, e.debounceWindow(config.PadColorConfig{})) {
+		if binding.ActionID != "" {
+			e.Run(binding.ActionID)
+		}
+		if binding.MenuID != "" {
+			target := device.ID
+			if group := e.cfg.GroupOf(device.ID); group != nil {
+				target = group.ID
+			}
+			e.SwitchTargetMenu(target, binding.MenuID, "top row pad on "+device.Name)
+		}
+	}
+
+	if device.OutPort == "" || !device.SendPressedFeedback {
+		return
+	}
+	deviceType := midi.DeviceType(device.Type)
+	color := topRowColor(deviceType, binding)
+	if isNoteOn {
+		color = topRowPressed
+	}
+	deviceRow, deviceCol := device.Orientation.ToDevice(0, col)
+	if err := e.midiManager.SetPadColor(device.OutPort, deviceType, deviceRow, deviceCol, color.Scaled(e.brightnessOf(device))); err != nil {
+		slog.Warn("Failed to set pad color", "err", err)
+	}
+}
+
+// topRowColor is a binding's color, reduced to red/green levels for classic devices
+func topRowColor(deviceType midi.DeviceType, binding config.TopRowBinding) midi.PadColor {
+	if deviceType == midi.DeviceTypeClassic {
+		red, green := padcolor.ClassicLevels(binding.R, binding.G, binding.B)
+		return midi.PadColor{R: padcolor.LevelValue(red), G: padcolor.LevelValue(green)}
+	}
+	return midi.PadColor{R: binding.R, G: binding.G, B: binding.B}
+}
+
+// applyTopRow paints the global top-row pads over the menu's row 0 on devices using it
+func (e *Engine) applyTopRow(device config.DeviceConfig, colors *[9][9]midi.PadColor) {
+	deviceType := midi.DeviceType(device.Type)
+	for col := range colors[0] {
+		if binding, ok := e.topRowBinding(device, 0, col); ok {
+			colors[0][col] = topRowColor(deviceType, binding).Scaled(e.brightnessOf(device))
+		}
+	}
+}
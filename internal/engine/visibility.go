@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// Visibility flags why a pad's static color may be hard to make out on hardware
+type Visibility uint8
+
+const (
+	VisibilityDim    Visibility = 1 << iota // Lit, but so dim a colorful device shows it as about off
+	VisibilityMerged                        // A classic device shows it like a neighbor with a different color
+)
+
+// MenuVisibility checks the static colors of a menu's pads against the thresholds of v,
+// as the enabled devices show them: dim pads on colorful devices, neighbors that look
+// alike on classic ones. Each kind of device is checked at the lowest brightness one of
+// them is set to; kinds no device is are not checked.
+func MenuVisibility(devices []config.DeviceConfig, menu *config.MenuLayout, v config.VisibilityConfig) [9][9]Visibility {
+	colorful, classic := 0, 0 // Lowest brightness of each kind, 0 without such a device
+	for _, device := range devices {
+		if device.Disabled {
+			continue
+		}
+		brightness := min(max(device.Brightness, 1), 100)
+		switch device.Type {
+		case config.DeviceTypeColorful, config.DeviceTypePro:
+			colorful = lowest(colorful, brightness)
+		case config.DeviceTypeClassic:
+			classic = lowest(classic, brightness)
+		}
+	}
+
+	var issues [9][9]Visibility
+	for row := range 9 {
+		for col := range 9 {
+			c := menu.EffectiveColor(row, col)
+			if r, g, b := c.StaticFor(config.DeviceTypeColorful); colorful > 0 && max(r, g, b) > 0 &&
+				padcolor.SentBrightness(r, g, b, colorful) < v.MinBrightness {
+				issues[row][col] |= VisibilityDim
+			}
+			if classic == 0 {
+				continue
+			}
+			for _, n := range [][2]int{{row, col + 1}, {row + 1, col}} {
+				if n[0] < 9 && n[1] < 9 && classicMerged(c, menu.EffectiveColor(n[0], n[1]), classic, v.MinDistance) {
+					issues[row][col] |= VisibilityMerged
+					issues[n[0]][n[1]] |= VisibilityMerged
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// lowest returns the lower of two brightnesses, where 0 is none yet
+func lowest(current, brightness int) int {
+	if current == 0 {
+		return brightness
+	}
+	return min(current, brightness)
+}
+
+// classicMerged reports whether two pads whose RGB colors are at least minDistance apart
+// light a classic device at brightness the same, lit color
+func classicMerged(a, b config.PadColorConfig, brightness int, minDistance uint8) bool {
+	if padcolor.Distance(padcolor.RGB{R: a.R, G: a.G, B: a.B}, padcolor.RGB{R: b.R, G: b.G, B: b.B}) < int(minDistance) {
+		return false
+	}
+	redA, greenA := classicShown(a, brightness)
+	redB, greenB := classicShown(b, brightness)
+	return (redA > 0 || greenA > 0) && redA == redB && greenA == greenB
+}
+
+// classicShown returns the LED levels a classic device at brightness lights a pad with
+func classicShown(c config.PadColorConfig, brightness int) (red, green uint8) {
+	r, g, b := c.StaticFor(config.DeviceTypeClassic)
+	return padcolor.ClassicLevels(padcolor.Scale(r, brightness), padcolor.Scale(g, brightness), padcolor.Scale(b, brightness))
+}
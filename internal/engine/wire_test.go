@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// TestWire drives the engine through the fake ports and compares the bytes each
+// device receives with testdata/wire_*.golden; run with -update after a deliberate change
+func TestWire(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []config.DeviceConfig
+		port    string
+		reset   bool // drop what InitializeDevices sent before driving
+		drive   func(r *testRig)
+	}{
+		{"init_colorful", []config.DeviceConfig{colorfulDevice()}, colorfulOut, false, func(r *testRig) {}},
+		{"init_classic", []config.DeviceConfig{classicDevice()}, classicOut, false, func(r *testRig) {}},
+		{"press_colorful", []config.DeviceConfig{colorfulDevice()}, colorfulOut, true, func(r *testRig) {
+			r.press(colorfulIn, 2, 3, true)
+			r.press(colorfulIn, 2, 3, false)
+		}},
+		{"press_classic", []config.DeviceConfig{classicDevice()}, classicOut, true, func(r *testRig) {
+			r.press(classicIn, 2, 3, true)
+			r.press(classicIn, 2, 3, false)
+		}},
+		{"resend_layout", []config.DeviceConfig{colorfulDevice(), classicDevice()}, colorfulOut, true, func(r *testRig) {
+			var err error
+			r.do(func() { err = r.e.SendGridToDevice(r.cfg.Devices[0]) })
+			if err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRig(t, testConfig(tt.devices...))
+			r.do(r.e.InitializeDevices)
+			if tt.reset {
+				r.fake.Reset()
+			}
+			tt.drive(r)
+			checkGolden(t, "wire_"+tt.name, r.wire(tt.port))
+		})
+	}
+}
+
+// TestWireActionNote checks the note a pad's MIDI action plays on the synth
+func TestWireActionNote(t *testing.T) {
+	r := newRig(t, testConfig(colorfulDevice()))
+	r.do(r.e.InitializeDevices)
+
+	r.press(colorfulIn, 1, 1, true)
+	r.press(colorfulIn, 1, 1, false)
+
+	sent := r.waitSent(t, synthOut, 1)
+	if len(sent) != 1 {
+		t.Fatalf("synth got %d messages, want 1", len(sent))
+	}
+	if got := r.wire(synthOut); got != "903C64\n" {
+		t.Errorf("synth wire = %q, want %q", got, "903C64\n")
+	}
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+)
+
+// statusResponse is the body of GET /status
+type statusResponse struct {
+	Devices        []engine.DeviceState `json:"devices"`
+	RunningActions []string             `json:"running_actions"`
+	LastError      string               `json:"last_error,omitempty"`
+	LastErrorFrom  string               `json:"last_error_action,omitempty"`
+}
+
+// runResponse is the body of POST /actions/{id}/run
+type runResponse struct {
+	OK bool `json:"ok"`
+	engine.RunResult
+	Error string `json:"error,omitempty"`
+}
+
+// menuRequest is the body of POST /devices/{id}/menu
+type menuRequest struct {
+	MenuID string `json:"menu_id"`
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the API routes, all of which require the bearer token
+func NewHandler(backend Backend, token string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /actions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, backend.ActionList())
+	})
+
+	mux.HandleFunc("POST /actions/{id}/run", func(w http.ResponseWriter, r *http.Request) {
+		result, err := backend.RunAction(r.PathValue("id"))
+		switch {
+		case errors.Is(err, engine.ErrNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case err != nil:
+			// The action ran but failed; report it alongside its output
+			writeJSON(w, http.StatusOK, runResponse{RunResult: result, Error: err.Error()})
+		default:
+			writeJSON(w, http.StatusOK, runResponse{OK: true, RunResult: result})
+		}
+	})
+
+	mux.HandleFunc("POST /devices/{id}/menu", func(w http.ResponseWriter, r *http.Request) {
+		var req menuRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MenuID == "" {
+			writeError(w, http.StatusBadRequest, errors.New(`body must be {"menu_id": "..."}`))
+			return
+		}
+		if err := backend.SwitchMenu(r.PathValue("id"), req.MenuID); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		snapshot := backend.Status().Snapshot()
+		resp := statusResponse{
+			Devices:        backend.DeviceStates(),
+			RunningActions: backend.RunningActions(),
+		}
+		if snapshot.LastActionError != nil {
+			resp.LastError = snapshot.LastActionError.Error()
+			resp.LastErrorFrom = snapshot.LastActionName
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	return requireToken(token, mux)
+}
+
+// requireToken rejects requests without the bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		slog.Info("HTTP API request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Warn("Failed to write HTTP API response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,176 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/status"
+)
+
+const testToken = "s3cret"
+
+// fakeBackend answers API requests from fixed data and records what was asked of it
+type fakeBackend struct {
+	ran      []string
+	switched [][2]string
+	bus      *status.Bus
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{bus: status.NewBus()}
+}
+
+func (b *fakeBackend) ActionList() []engine.ActionInfo {
+	return []engine.ActionInfo{{ID: "a1", Name: "Toggle Mute"}, {ID: "g1", Name: "Lights", IsGroup: true}}
+}
+
+func (b *fakeBackend) RunAction(id string) (engine.RunResult, error) {
+	b.ran = append(b.ran, id)
+	switch id {
+	case "a1":
+		return engine.RunResult{Waited: true, Output: "muted"}, nil
+	case "failing":
+		return engine.RunResult{Waited: true, Output: "oops"}, errors.New("exit status 1")
+	}
+	return engine.RunResult{}, fmt.Errorf("action %q: %w", id, engine.ErrNotFound)
+}
+
+func (b *fakeBackend) SwitchMenu(targetID, menuID string) error {
+	if targetID != "d1" {
+		return fmt.Errorf("device %q: %w", targetID, engine.ErrNotFound)
+	}
+	b.switched = append(b.switched, [2]string{targetID, menuID})
+	return nil
+}
+
+func (b *fakeBackend) DeviceStates() []engine.DeviceState {
+	return []engine.DeviceState{{ID: "d1", Name: "Launchpad", Enabled: true, Connected: true, Menu: "Main"}}
+}
+
+func (b *fakeBackend) RunningActions() []string { return []string{"Toggle Mute"} }
+
+func (b *fakeBackend) Status() *status.Bus { return b.bus }
+
+// request sends a request with the test token, or with auth as the Authorization
+// header if it is set
+func request(t *testing.T, h http.Handler, method, path, body, auth string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if auth == "" {
+		auth = "Bearer " + testToken
+	}
+	req.Header.Set("Authorization", auth)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuth(t *testing.T) {
+	backend := newFakeBackend()
+	h := NewHandler(backend, testToken)
+
+	tests := []struct {
+		name   string
+		header string // "-" sends no Authorization header
+	}{
+		{"missing", "-"},
+		{"wrong token", "Bearer nope"},
+		{"token prefix", "Bearer s3cre"},
+		{"not bearer", "Basic " + testToken},
+		{"bare token", testToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/actions/a1/run", nil)
+			if tt.header != "-" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rec.Code)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want Bearer", got)
+			}
+		})
+	}
+	if len(backend.ran) > 0 {
+		t.Errorf("ran %q without a valid token", backend.ran)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string // Exact JSON, compact; empty skips the check
+	}{
+		{"list actions", "GET", "/actions", "", 200,
+			`[{"id":"a1","name":"Toggle Mute"},{"id":"g1","name":"Lights","is_group":true}]`},
+		{"run", "POST", "/actions/a1/run", "", 200, `{"ok":true,"waited":true,"output":"muted"}`},
+		{"run failing", "POST", "/actions/failing/run", "", 200,
+			`{"ok":false,"waited":true,"output":"oops","error":"exit status 1"}`},
+		{"run unknown", "POST", "/actions/nope/run", "", 404, `{"error":"action \"nope\": not found"}`},
+		{"run with GET", "GET", "/actions/a1/run", "", 405, ""},
+		{"switch menu", "POST", "/devices/d1/menu", `{"menu_id":"m2"}`, 204, ""},
+		{"switch unknown device", "POST", "/devices/nope/menu", `{"menu_id":"m2"}`, 404,
+			`{"error":"device \"nope\": not found"}`},
+		{"switch without menu", "POST", "/devices/d1/menu", `{}`, 400, `{"error":"body must be {\"menu_id\": \"...\"}"}`},
+		{"switch bad JSON", "POST", "/devices/d1/menu", `menu`, 400, ""},
+		{"status", "GET", "/status", "", 200,
+			`{"devices":[{"id":"d1","name":"Launchpad","enabled":true,"connected":true,"menu":"Main"}],"running_actions":["Toggle Mute"]}`},
+		{"unknown path", "GET", "/nope", "", 404, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(t, NewHandler(newFakeBackend(), testToken), tt.method, tt.path, tt.body, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %s\nwant   %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSwitchMenuReachesBackend(t *testing.T) {
+	backend := newFakeBackend()
+	rec := request(t, NewHandler(backend, testToken), "POST", "/devices/d1/menu", `{"menu_id":"m2"}`, "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+	if len(backend.switched) != 1 || backend.switched[0] != [2]string{"d1", "m2"} {
+		t.Errorf("switched %q, want d1 to m2", backend.switched)
+	}
+}
+
+func TestStatusReportsLastError(t *testing.T) {
+	backend := newFakeBackend()
+	backend.bus.ActionFailed("Toggle Mute", errors.New("exit status 1"))
+
+	rec := request(t, NewHandler(backend, testToken), "GET", "/status", "", "")
+	var got statusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.LastError != "exit status 1" || got.LastErrorFrom != "Toggle Mute" {
+		t.Errorf("last error = %q from %q, want exit status 1 from Toggle Mute", got.LastError, got.LastErrorFrom)
+	}
+}
@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/status"
+)
+
+// shutdownTimeout bounds how long in-flight requests may delay a stop or restart
+const shutdownTimeout = 5 * time.Second
+
+// Backend performs API requests in the running instance
+type Backend interface {
+	ActionList() []engine.ActionInfo
+	RunAction(id string) (engine.RunResult, error)
+	SwitchMenu(targetID, menuID string) error
+	DeviceStates() []engine.DeviceState
+	RunningActions() []string
+	Status() *status.Bus
+}
+
+// Server is the optional HTTP API. It can be started, stopped and reconfigured at runtime.
+type Server struct {
+	backend Backend
+
+	mu      sync.Mutex
+	srv     *http.Server
+	current config.HTTPAPIConfig // Settings the running server was started with
+}
+
+// New creates a stopped server for the given backend
+func New(backend Backend) *Server {
+	return &Server{backend: backend}
+}
+
+// Apply starts, stops or restarts the server to match the settings
+func (s *Server) Apply(settings config.HTTPAPIConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.srv != nil && settings == s.current {
+		return nil
+	}
+	s.stopLocked()
+
+	if !settings.Enabled {
+		return nil
+	}
+	if settings.Token == "" {
+		return errors.New("the HTTP API needs a token before it can be enabled")
+	}
+
+	listener, err := net.Listen("tcp", settings.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to start HTTP API: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           NewHandler(s.backend, settings.Token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP API stopped", "err", err)
+		}
+	}()
+
+	s.srv = srv
+	s.current = settings
+	slog.Info("HTTP API listening", "addr", listener.Addr().String())
+	return nil
+}
+
+// Stop shuts the server down if it is running
+func (s *Server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopLocked()
+}
+
+func (s *Server) stopLocked() {
+	if s.srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.srv.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP API did not shut down cleanly", "err", err)
+	}
+	s.srv = nil
+	s.current = config.HTTPAPIConfig{}
+	slog.Info("HTTP API stopped")
+}
+
+// NewToken returns a random token suitable for the Authorization header
+func NewToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
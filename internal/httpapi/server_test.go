@@ -0,0 +1,90 @@
+package httpapi
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// freeSettings returns enabled settings on a localhost port nothing listens on
+func freeSettings(t *testing.T, token string) config.HTTPAPIConfig {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return config.HTTPAPIConfig{Enabled: true, BindAddress: "127.0.0.1", Port: port, Token: token}
+}
+
+// getActions requests GET /actions from a running server with the token
+func getActions(settings config.HTTPAPIConfig, token string) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, "http://"+settings.Addr()+"/actions", nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestApplyStartsAndStops(t *testing.T) {
+	s := New(newFakeBackend())
+	defer s.Stop()
+
+	settings := freeSettings(t, testToken)
+	if err := s.Apply(settings); err != nil {
+		t.Fatal(err)
+	}
+	if code, err := getActions(settings, testToken); err != nil || code != http.StatusOK {
+		t.Fatalf("GET /actions = %d, %v; want 200", code, err)
+	}
+
+	// A new token takes effect at once, by restarting the server
+	changed := settings
+	changed.Token = "other"
+	if err := s.Apply(changed); err != nil {
+		t.Fatal(err)
+	}
+	if code, err := getActions(changed, testToken); err != nil || code != http.StatusUnauthorized {
+		t.Errorf("GET /actions with the old token = %d, %v; want 401", code, err)
+	}
+
+	changed.Enabled = false
+	if err := s.Apply(changed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getActions(settings, "other"); err == nil {
+		t.Error("server still answers after disabling")
+	}
+}
+
+func TestApplyNeedsToken(t *testing.T) {
+	s := New(newFakeBackend())
+	defer s.Stop()
+	if err := s.Apply(freeSettings(t, "")); err == nil {
+		t.Error("Apply enabled without a token succeeded")
+	}
+}
+
+func TestApplyReportsBusyPort(t *testing.T) {
+	settings := freeSettings(t, testToken)
+	taken, err := net.Listen("tcp", settings.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	s := New(newFakeBackend())
+	defer s.Stop()
+	if err := s.Apply(settings); err == nil {
+		t.Error("Apply on a port in use succeeded")
+	}
+}
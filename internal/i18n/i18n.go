@@ -0,0 +1,115 @@
+// Package i18n translates UI strings. Catalogs are flat JSON maps from message ID to
+// text, embedded from locales/<language>.json. Texts with arguments use fmt verbs.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language every message exists in and the fallback for missing ones
+const DefaultLanguage = "en"
+
+// languageNameKey is the catalog entry holding the language's own name
+const languageNameKey = "language.name"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]map[string]string{} // language code -> message ID -> text
+	language = DefaultLanguage
+)
+
+// Language is a selectable catalog
+type Language struct {
+	Code string // e.g. "de"
+	Name string // The language's own name, e.g. "Deutsch"
+}
+
+func init() {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+}
+
+// Languages lists the available catalogs, sorted by code
+func Languages() []Language {
+	var list []Language
+	for code, catalog := range catalogs {
+		list = append(list, Language{Code: code, Name: catalog[languageNameKey]})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// SetLanguage selects the catalog for a language code such as "de" or "de-AT".
+// Unknown languages fall back to English.
+func SetLanguage(code string) {
+	code = strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+	if _, ok := catalogs[code]; !ok {
+		code, _, _ = strings.Cut(code, "-")
+	}
+	if _, ok := catalogs[code]; !ok {
+		code = DefaultLanguage
+	}
+
+	mu.Lock()
+	language = code
+	mu.Unlock()
+}
+
+// CurrentLanguage returns the code of the selected catalog
+func CurrentLanguage() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the text for a message ID in the selected language, formatted with args.
+// Missing messages fall back to English, then to the ID itself.
+func T(id string, args ...any) string {
+	mu.RLock()
+	lang := language
+	mu.RUnlock()
+
+	text, ok := catalogs[lang][id]
+	if !ok {
+		slog.Debug("Missing translation", "id", id, "language", lang)
+		if text, ok = catalogs[DefaultLanguage][id]; !ok {
+			text = id
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N is T for messages that depend on a count: it uses id+".one" when n is 1 and
+// id+".other" otherwise. The count is not passed to the text automatically.
+func N(id string, n int, args ...any) string {
+	if n == 1 {
+		return T(id+".one", args...)
+	}
+	return T(id+".other", args...)
+}
@@ -0,0 +1,150 @@
+// Package importers turns layouts made in other tools into menu layouts
+package importers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// Skip names something in an imported file that a menu layout can't represent
+type Skip string
+
+// Skipped features, in the order they are reported
+const (
+	SkipCustomMode  Skip = "custom_mode"  // Note, CC and fader assignments of a custom mode
+	SkipFlashing    Skip = "flashing"     // Imported as the first color, the second becomes the pressed color
+	SkipPulsing     Skip = "pulsing"      // Imported as a steady color
+	SkipOutsideGrid Skip = "outside_grid" // LEDs that aren't on the 9x9 grid
+	SkipForeign     Skip = "foreign"      // Messages for another device or of another kind
+)
+
+// Skips lists every Skip in report order
+var Skips = []Skip{SkipCustomMode, SkipFlashing, SkipPulsing, SkipOutsideGrid, SkipForeign}
+
+// Result is a layout built from an imported file, with what couldn't be carried over
+type Result struct {
+	Layout  config.MenuLayout
+	Skipped map[Skip]int // How often each feature was found
+}
+
+// Header bytes (after F0) of the Launchpad Mini MK3 messages found in Components exports
+var (
+	miniMK3Header  = []byte{0x00, 0x20, 0x29, 0x02, 0x0D}
+	ledLightingCmd = byte(0x03) // Colorspecs, one per LED, as documented in the programmer's reference
+	customModeCmd  = byte(0x05) // Custom mode dump: note/CC assignments and faders, undocumented
+)
+
+// Colorspec types of an LED lighting message and how many data bytes follow the LED index
+const (
+	specStatic   = 0x00 // Palette index
+	specFlashing = 0x01 // Palette index B, palette index A
+	specPulsing  = 0x02 // Palette index
+	specRGB      = 0x03 // Red, green, blue (0-127)
+)
+
+var specLengths = map[byte]int{specStatic: 1, specFlashing: 2, specPulsing: 1, specRGB: 3}
+
+// ErrNoLayout is returned when a file holds no pad colors for a Launchpad Mini MK3
+var ErrNoLayout = errors.New("no Launchpad Mini MK3 pad colors found")
+
+// ImportComponents builds a layout named name from a SysEx file exported by Novation
+// Components or captured from a Launchpad Mini MK3. Pad colors are read from LED lighting
+// messages; custom mode assignments, flashing and pulsing have no equivalent and are
+// counted in Result.Skipped instead.
+func ImportComponents(name string, data []byte) (*Result, error) {
+	messages, err := splitSysEx(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Layout: config.NewMenuLayout(), Skipped: map[Skip]int{}}
+	result.Layout.Name = name
+	lit := 0
+
+	for _, msg := range messages {
+		body, ok := bytes.CutPrefix(msg, miniMK3Header)
+		if !ok || len(body) == 0 {
+			result.Skipped[SkipForeign]++
+			continue
+		}
+		switch body[0] {
+		case ledLightingCmd:
+			specs := body[1:]
+			for len(specs) >= 2 {
+				specType, led := specs[0], specs[1]
+				n, known := specLengths[specType]
+				if !known || len(specs) < 2+n {
+					return nil, fmt.Errorf("malformed LED lighting message: colorspec type %d", specType)
+				}
+				values := specs[2 : 2+n]
+				specs = specs[2+n:]
+
+				row, col, ok := ledToGrid(led)
+				if !ok {
+					result.Skipped[SkipOutsideGrid]++
+					continue
+				}
+				pad := &result.Layout.Colors[row][col]
+				switch specType {
+				case specRGB:
+					pad.R, pad.G, pad.B = values[0]&0x7F, values[1]&0x7F, values[2]&0x7F
+				case specFlashing:
+					// Shown as its first color, the second becomes the pressed color
+					pad.R, pad.G, pad.B = PaletteColor(values[0])
+					pad.PressedR, pad.PressedG, pad.PressedB = PaletteColor(values[1])
+					result.Skipped[SkipFlashing]++
+				case specPulsing:
+					pad.R, pad.G, pad.B = PaletteColor(values[0])
+					result.Skipped[SkipPulsing]++
+				default:
+					pad.R, pad.G, pad.B = PaletteColor(values[0])
+				}
+				lit++
+			}
+		case customModeCmd:
+			result.Skipped[SkipCustomMode]++
+		default:
+			result.Skipped[SkipForeign]++
+		}
+	}
+
+	if lit == 0 && result.Skipped[SkipCustomMode] == 0 {
+		return nil, ErrNoLayout
+	}
+	result.Layout.EnsureDefaultLinking() // Derive colors for classic devices
+	return result, nil
+}
+
+// splitSysEx returns the body (between F0 and F7) of every SysEx message in data
+func splitSysEx(data []byte) ([][]byte, error) {
+	var messages [][]byte
+	for {
+		start := bytes.IndexByte(data, 0xF0)
+		if start < 0 {
+			break
+		}
+		end := bytes.IndexByte(data[start:], 0xF7)
+		if end < 0 {
+			return nil, fmt.Errorf("truncated SysEx message at byte %d", start)
+		}
+		messages = append(messages, data[start+1:start+end])
+		data = data[start+end+1:]
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("not a SysEx file")
+	}
+	return messages, nil
+}
+
+// ledToGrid maps a programmer mode LED index (11 bottom-left to 99 the logo) to the
+// layout grid, whose row 0 is the top row
+func ledToGrid(led uint8) (row, col int, ok bool) {
+	tens, ones := int(led)/10, int(led)%10
+	if tens < 1 || tens > 9 || ones < 1 || ones > 9 {
+		return 0, 0, false
+	}
+	return 9 - tens, ones - 1, true
+}
@@ -0,0 +1,115 @@
+package importers
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"testing"
+)
+
+// rgb is a pad's color as stored in a layout
+type rgb struct{ r, g, b uint8 }
+
+func TestImportComponentsFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/mini_mk3.syx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportComponents("Imported", data)
+	if err != nil {
+		t.Fatalf("ImportComponents: %v", err)
+	}
+	if result.Layout.Name != "Imported" {
+		t.Errorf("name = %q, want Imported", result.Layout.Name)
+	}
+
+	colors := []struct {
+		name     string
+		row, col int
+		want     rgb
+		pressed  rgb
+	}{
+		{"static palette, LED 11", 8, 0, rgb{127, 0, 0}, rgb{}},
+		{"RGB, logo LED 99", 0, 8, rgb{127, 64, 0}, rgb{}},
+		{"flashing, LED 81", 1, 0, rgb{0, 127, 0}, rgb{0, 0, 127}},
+		{"pulsing, LED 55", 4, 4, rgb{127, 127, 0}, rgb{}},
+		{"unlit", 3, 3, rgb{}, rgb{}},
+	}
+	for _, c := range colors {
+		pad := result.Layout.Colors[c.row][c.col]
+		if got := (rgb{pad.R, pad.G, pad.B}); got != c.want {
+			t.Errorf("%s: pad %d,%d = %v, want %v", c.name, c.row, c.col, got, c.want)
+		}
+		if got := (rgb{pad.PressedR, pad.PressedG, pad.PressedB}); got != c.pressed {
+			t.Errorf("%s: pad %d,%d pressed = %v, want %v", c.name, c.row, c.col, got, c.pressed)
+		}
+	}
+
+	want := map[Skip]int{SkipCustomMode: 1, SkipFlashing: 1, SkipPulsing: 1, SkipOutsideGrid: 1, SkipForeign: 1}
+	if !maps.Equal(result.Skipped, want) {
+		t.Errorf("skipped = %v, want %v", result.Skipped, want)
+	}
+}
+
+func TestImportComponentsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error // nil to only expect some error
+	}{
+		{"not sysex", []byte("hello"), nil},
+		{"truncated", []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0D, 0x03}, nil},
+		{"unknown colorspec", []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0D, 0x03, 0x07, 11, 0xF7}, nil},
+		{"short colorspec", []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0D, 0x03, 0x03, 11, 127, 0xF7}, nil},
+		{"other device only", []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0C, 0x03, 0x00, 11, 5, 0xF7}, ErrNoLayout},
+		{"no lit pads", []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0D, 0x03, 0xF7}, ErrNoLayout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ImportComponents("x", tt.data)
+			if err == nil {
+				t.Fatalf("ImportComponents = %+v, want an error", result)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestImportComponentsCustomModeOnly checks that a file with only a custom mode still
+// imports, so the summary can tell what was dropped
+func TestImportComponentsCustomModeOnly(t *testing.T) {
+	result, err := ImportComponents("x", []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0D, 0x05, 0x00, 0xF7})
+	if err != nil {
+		t.Fatalf("ImportComponents: %v", err)
+	}
+	if result.Skipped[SkipCustomMode] != 1 {
+		t.Errorf("skipped = %v, want the custom mode", result.Skipped)
+	}
+}
+
+func TestLEDToGrid(t *testing.T) {
+	tests := []struct {
+		led      uint8
+		row, col int
+		ok       bool
+	}{
+		{11, 8, 0, true},
+		{18, 8, 7, true},
+		{19, 8, 8, true}, // Right column
+		{81, 1, 0, true},
+		{91, 0, 0, true}, // Top row
+		{99, 0, 8, true}, // Logo
+		{10, 0, 0, false},
+		{20, 0, 0, false},
+		{100, 0, 0, false},
+		{5, 0, 0, false},
+	}
+	for _, tt := range tests {
+		row, col, ok := ledToGrid(tt.led)
+		if row != tt.row || col != tt.col || ok != tt.ok {
+			t.Errorf("ledToGrid(%d) = %d, %d, %v; want %d, %d, %v", tt.led, row, col, ok, tt.row, tt.col, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package importers
+
+// mk3Palette is the 128-color palette of Launchpad Mini MK3 (shared with the X and Pro MK3),
+// as 0-255 RGB. Palette colors in files refer to it by index.
+var mk3Palette = [128][3]uint8{
+	{0x00, 0x00, 0x00}, {0x1E, 0x1E, 0x1E}, {0x7F, 0x7F, 0x7F}, {0xFF, 0xFF, 0xFF},
+	{0xFF, 0x4C, 0x4C}, {0xFF, 0x00, 0x00}, {0x59, 0x00, 0x00}, {0x19, 0x00, 0x00},
+	{0xFF, 0xBD, 0x6C}, {0xFF, 0x54, 0x00}, {0x59, 0x1D, 0x00}, {0x27, 0x1B, 0x00},
+	{0xFF, 0xFF, 0x4C}, {0xFF, 0xFF, 0x00}, {0x59, 0x59, 0x00}, {0x19, 0x19, 0x00},
+	{0x88, 0xFF, 0x4C}, {0x54, 0xFF, 0x00}, {0x1D, 0x59, 0x00}, {0x14, 0x2B, 0x00},
+	{0x4C, 0xFF, 0x4C}, {0x00, 0xFF, 0x00}, {0x00, 0x59, 0x00}, {0x00, 0x19, 0x00},
+	{0x4C, 0xFF, 0x5E}, {0x00, 0xFF, 0x19}, {0x00, 0x59, 0x0D}, {0x00, 0x19, 0x02},
+	{0x4C, 0xFF, 0x88}, {0x00, 0xFF, 0x55}, {0x00, 0x59, 0x1D}, {0x00, 0x1F, 0x12},
+	{0x4C, 0xFF, 0xB7}, {0x00, 0xFF, 0x99}, {0x00, 0x59, 0x35}, {0x00, 0x19, 0x12},
+	{0x4C, 0xC3, 0xFF}, {0x00, 0xA9, 0xFF}, {0x00, 0x41, 0x52}, {0x00, 0x10, 0x19},
+	{0x4C, 0x88, 0xFF}, {0x00, 0x55, 0xFF}, {0x00, 0x1D, 0x59}, {0x00, 0x08, 0x19},
+	{0x4C, 0x4C, 0xFF}, {0x00, 0x00, 0xFF}, {0x00, 0x00, 0x59}, {0x00, 0x00, 0x19},
+	{0x87, 0x4C, 0xFF}, {0x54, 0x00, 0xFF}, {0x19, 0x00, 0x64}, {0x0F, 0x00, 0x30},
+	{0xFF, 0x4C, 0xFF}, {0xFF, 0x00, 0xFF}, {0x59, 0x00, 0x59}, {0x19, 0x00, 0x19},
+	{0xFF, 0x4C, 0x87}, {0xFF, 0x00, 0x54}, {0x59, 0x00, 0x1D}, {0x22, 0x00, 0x13},
+	{0xFF, 0x15, 0x00}, {0x99, 0x35, 0x00}, {0x79, 0x51, 0x00}, {0x43, 0x64, 0x00},
+	{0x03, 0x39, 0x00}, {0x00, 0x57, 0x35}, {0x00, 0x54, 0x7F}, {0x00, 0x00, 0xFF},
+	{0x00, 0x45, 0x4F}, {0x25, 0x00, 0xCC}, {0x7F, 0x7F, 0x7F}, {0x20, 0x20, 0x20},
+	{0xFF, 0x00, 0x00}, {0xBD, 0xFF, 0x2D}, {0xAF, 0xED, 0x06}, {0x64, 0xFF, 0x09},
+	{0x10, 0x8B, 0x00}, {0x00, 0xFF, 0x87}, {0x00, 0xA9, 0xFF}, {0x00, 0x2A, 0xFF},
+	{0x3F, 0x00, 0xFF}, {0x7A, 0x00, 0xFF}, {0xB2, 0x1A, 0x7D}, {0x40, 0x21, 0x00},
+	{0xFF, 0x4A, 0x00}, {0x88, 0xE1, 0x06}, {0x72, 0xFF, 0x15}, {0x00, 0xFF, 0x00},
+	{0x3B, 0xFF, 0x26}, {0x59, 0xFF, 0x71}, {0x38, 0xFF, 0xCC}, {0x5B, 0x8A, 0xFF},
+	{0x31, 0x51, 0xC6}, {0x87, 0x7F, 0xE9}, {0xD3, 0x1D, 0xFF}, {0xFF, 0x00, 0x5D},
+	{0xFF, 0x7F, 0x00}, {0xB9, 0xB0, 0x00}, {0x90, 0xFF, 0x00}, {0x83, 0x5D, 0x07},
+	{0x39, 0x2B, 0x00}, {0x14, 0x4C, 0x10}, {0x0D, 0x50, 0x38}, {0x15, 0x15, 0x2A},
+	{0x16, 0x20, 0x5A}, {0x69, 0x3C, 0x1C}, {0xA8, 0x00, 0x0A}, {0xDE, 0x51, 0x3D},
+	{0xD8, 0x6A, 0x1C}, {0xFF, 0xE1, 0x26}, {0x9E, 0xE1, 0x2F}, {0x67, 0xB5, 0x0F},
+	{0x1E, 0x1E, 0x30}, {0xDC, 0xFF, 0x6B}, {0x80, 0xFF, 0xBD}, {0x9A, 0x99, 0xFF},
+	{0x8E, 0x66, 0xFF}, {0x40, 0x40, 0x40}, {0x75, 0x75, 0x75}, {0xE0, 0xFF, 0xFF},
+	{0xA0, 0x00, 0x00}, {0x35, 0x00, 0x00}, {0x1A, 0xD0, 0x00}, {0x07, 0x42, 0x00},
+	{0xB9, 0xB0, 0x00}, {0x3F, 0x31, 0x00}, {0xB3, 0x5F, 0x00}, {0x4B, 0x15, 0x02},
+}
+
+// PaletteColor returns a palette entry as 0-127 RGB, the range pad colors are stored in.
+// Indices past the palette are masked to it, as the device does with a 7-bit data byte.
+func PaletteColor(index uint8) (r, g, b uint8) {
+	c := mk3Palette[index&0x7F]
+	return c[0] >> 1, c[1] >> 1, c[2] >> 1
+}
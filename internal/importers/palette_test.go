@@ -0,0 +1,29 @@
+package importers
+
+import "testing"
+
+func TestPaletteColor(t *testing.T) {
+	tests := []struct {
+		index uint8
+		want  rgb
+	}{
+		{0, rgb{0, 0, 0}},
+		{3, rgb{127, 127, 127}},
+		{5, rgb{127, 0, 0}},
+		{13, rgb{127, 127, 0}},
+		{21, rgb{0, 127, 0}},
+		{45, rgb{0, 0, 127}},
+		{53, rgb{127, 0, 127}},
+		{9, rgb{127, 42, 0}},
+		{127, rgb{0x4B >> 1, 0x15 >> 1, 0x02 >> 1}},
+		{128, rgb{0, 0, 0}},          // Masked to 0
+		{133, rgb{127, 0, 0}},        // Masked to 5
+		{255, rgb{0x25, 0x0A, 0x01}}, // Masked to 127
+	}
+	for _, tt := range tests {
+		r, g, b := PaletteColor(tt.index)
+		if got := (rgb{r, g, b}); got != tt.want {
+			t.Errorf("PaletteColor(%d) = %v, want %v", tt.index, got, tt.want)
+		}
+	}
+}
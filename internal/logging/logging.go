@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// FileName is the name of the active log file in the config directory
+const FileName = "gopher-automate.log"
+
+// Levels lists the selectable log levels, most verbose first
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// level is shared by the handler so the level can change while running
+var level slog.LevelVar
+
+// Path returns the full path to the active log file
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Setup sends slog and the standard log package to stderr and the rotating log file.
+// The returned function closes the file. If the file can't be opened, logging
+// continues on stderr only and the error is returned.
+func Setup() (func(), error) {
+	var out io.Writer = os.Stderr
+	closeFn := func() {}
+
+	path, err := Path()
+	if err == nil {
+		var file *rotatingFile
+		if file, err = openRotatingFile(path); err == nil {
+			out = io.MultiWriter(os.Stderr, file)
+			closeFn = func() { _ = file.Close() }
+		}
+	}
+
+	handler := slog.NewTextHandler(out, &slog.HandlerOptions{Level: &level})
+	slog.SetDefault(slog.New(handler)) // Also routes log.Printf through the handler
+	return closeFn, err
+}
+
+// SetLevel changes the minimum level written; unknown names fall back to info
+func SetLevel(name string) {
+	level.Set(ParseLevel(name))
+}
+
+// LevelName normalizes a level name from the config to one of Levels
+func LevelName(name string) string {
+	return strings.ToLower(ParseLevel(name).String())
+}
+
+// ParseLevel converts a level name from the config to a slog level
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
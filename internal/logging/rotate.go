@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	maxFileSize = 5 << 20 // Rotate once the active file reaches 5 MB
+	maxBackups  = 3       // Keep gopher-automate.log.1 through .3
+)
+
+// rotatingFile is an append-only log file that rolls over to numbered backups
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// openRotatingFile opens path for appending, continuing an existing file
+func openRotatingFile(path string) (*rotatingFile, error) {
+	r := &rotatingFile{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if it would push the file past maxFileSize
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size+int64(len(p)) > maxFileSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups up by one and starts a new active file
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(backupPath(r.path, maxBackups))
+	for i := maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(backupPath(r.path, i), backupPath(r.path, i+1))
+	}
+	if err := os.Rename(r.path, backupPath(r.path, 1)); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// Close closes the active file
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// tailBytes caps how much of the log file Tail reads
+const tailBytes = 256 << 10
+
+// Tail returns the last lines of the active log file, oldest first
+func Tail() ([]string, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := max(info.Size()-tailBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(file, offset, info.Size()-offset))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(lines) > 1 {
+		lines = lines[1:] // The first line was cut in half
+	}
+	return lines, nil
+}
+
+// LineLevel returns the level name of a line written by the text handler ("" if none)
+func LineLevel(line string) string {
+	_, rest, ok := strings.Cut(line, " level=")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return strings.ToLower(name)
+}
@@ -0,0 +1,186 @@
+// Package metrics keeps always-on counters and timings for the MIDI and execution
+// paths, shown in the Diagnostics tab. Recording takes a mutex and a few additions,
+// so it is cheap enough to sit on every message and pad update.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// GridPush times full grid updates, keyed by output port
+	GridPush = NewTimingSet()
+	// PadUpdate times single pad color updates
+	PadUpdate = &Timing{}
+	// Dispatch times how long a received message waits before it is handled
+	Dispatch = &Timing{}
+	// ActionLatency times action executions
+	ActionLatency = &Timing{}
+
+	// Messages counts MIDI messages received
+	Messages = &Rate{}
+	// Errors counts failed sends, failed actions and recovered panics
+	Errors = &Rate{}
+	// CoalescedSends counts device updates merged into one already waiting to be sent
+	CoalescedSends = &Rate{}
+)
+
+// Record observes the time elapsed since start on t and counts err, if any, in Errors
+func Record(t *Timing, start time.Time, err error) {
+	t.Observe(time.Since(start))
+	if err != nil {
+		Errors.Add(1)
+	}
+}
+
+// rateBuckets is how many one-minute buckets a Rate keeps, an hour's worth
+const rateBuckets = 60
+
+// Rate counts events in one-minute buckets over the last hour
+type Rate struct {
+	mu      sync.Mutex
+	counts  [rateBuckets]uint64
+	minutes [rateBuckets]int64 // Minute each bucket was last reset for
+	total   uint64
+}
+
+// Add records n events
+func (r *Rate) Add(n uint64) {
+	minute := time.Now().Unix() / 60
+	i := minute % rateBuckets
+
+	r.mu.Lock()
+	if r.minutes[i] != minute {
+		r.minutes[i] = minute
+		r.counts[i] = 0
+	}
+	r.counts[i] += n
+	r.total += n
+	r.mu.Unlock()
+}
+
+// Last returns how many events were recorded in the last given minutes (at most 60),
+// counting the current partial minute
+func (r *Rate) Last(minutes int) uint64 {
+	minutes = min(max(minutes, 1), rateBuckets)
+	current := time.Now().Unix() / 60
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sum uint64
+	for i := range rateBuckets {
+		if current-r.minutes[i] < int64(minutes) {
+			sum += r.counts[i]
+		}
+	}
+	return sum
+}
+
+// Total returns how many events were recorded since start
+func (r *Rate) Total() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
+
+// Bounds are the upper edges of the histogram buckets of a Timing; the last
+// bucket holds everything slower
+var Bounds = [...]time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	20 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	2 * time.Second,
+}
+
+// Timing records durations as a count, running totals and a histogram over Bounds
+type Timing struct {
+	mu    sync.Mutex
+	stats TimingStats
+}
+
+// TimingStats is a copy of what a Timing has recorded
+type TimingStats struct {
+	Count   uint64
+	Total   time.Duration
+	Last    time.Duration
+	Max     time.Duration
+	At      time.Time               // When Last was recorded
+	Buckets [len(Bounds) + 1]uint64 // Counts per bucket of Bounds, slowest last
+}
+
+// Observe records one duration
+func (t *Timing) Observe(d time.Duration) {
+	bucket := len(Bounds)
+	for i, bound := range Bounds {
+		if d <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	t.mu.Lock()
+	t.stats.Count++
+	t.stats.Total += d
+	t.stats.Last = d
+	t.stats.Max = max(t.stats.Max, d)
+	t.stats.At = time.Now()
+	t.stats.Buckets[bucket]++
+	t.mu.Unlock()
+}
+
+// Stats returns what has been recorded so far
+func (t *Timing) Stats() TimingStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// Average returns the mean duration, 0 before anything is recorded
+func (s TimingStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// TimingSet keeps a Timing per key, such as a port name
+type TimingSet struct {
+	mu      sync.Mutex
+	timings map[string]*Timing
+}
+
+// NewTimingSet creates an empty set
+func NewTimingSet() *TimingSet {
+	return &TimingSet{timings: map[string]*Timing{}}
+}
+
+// Get returns the Timing for key, creating it on first use
+func (s *TimingSet) Get(key string) *Timing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.timings[key]
+	if !ok {
+		t = &Timing{}
+		s.timings[key] = t
+	}
+	return t
+}
+
+// Stats returns the recorded stats of every key
+func (s *TimingSet) Stats() map[string]TimingStats {
+	s.mu.Lock()
+	timings := make(map[string]*Timing, len(s.timings))
+	for key, t := range s.timings {
+		timings[key] = t
+	}
+	s.mu.Unlock()
+
+	stats := make(map[string]TimingStats, len(timings))
+	for key, t := range timings {
+		stats[key] = t.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,87 @@
+package midi
+
+import (
+	"slices"
+	"strings"
+)
+
+// DetectedDevice is a known controller found by scanning port names
+type DetectedDevice struct {
+	Name    string     // Model name, used as the default device name
+	Type    DeviceType // Device type to configure it as
+	InPort  string     // Matching input port ("" if none)
+	OutPort string     // Matching output port ("" if none)
+}
+
+// knownModel maps port-name patterns to a controller model
+type knownModel struct {
+	name     string
+	patterns []string // Case-insensitive substrings of the port name
+	devType  DeviceType
+}
+
+// knownModels lists the controllers that can be set up automatically
+var knownModels = []knownModel{
+	{name: "Launchpad Mini MK3", patterns: []string{"launchpad mini mk3", "lpminimk3"}, devType: DeviceTypeColorful},
+	{name: "Launchpad Pro MK3", patterns: []string{"launchpad pro mk3", "lppromk3"}, devType: DeviceTypePro},
+	{name: "Launchpad X", patterns: []string{"launchpad x", "lpx"}, devType: DeviceTypeColorful},
+	{name: "Launchpad S", patterns: []string{"launchpad s"}, devType: DeviceTypeClassic},
+}
+
+// DetectLaunchpads returns the known controllers found in the given port lists.
+// Newer Launchpads expose a DAW port alongside the MIDI port; only the MIDI port
+// accepts programmer-mode messages, so DAW ports are skipped.
+func DetectLaunchpads(inPorts, outPorts []string) []DetectedDevice {
+	var found []DetectedDevice
+
+	for _, out := range outPorts {
+		model, ok := matchModel(out)
+		if !ok || isDAWPort(out) {
+			continue
+		}
+
+		device := DetectedDevice{Name: model.name, Type: model.devType, OutPort: out}
+
+		// Prefer an input with the exact same name, then any input of the same model
+		if slices.Contains(inPorts, out) {
+			device.InPort = out
+		} else {
+			for _, in := range inPorts {
+				if m, ok := matchModel(in); ok && m.name == model.name && !isDAWPort(in) && !claimed(found, in) {
+					device.InPort = in
+					break
+				}
+			}
+		}
+		found = append(found, device)
+	}
+	return found
+}
+
+// matchModel returns the known model a port name belongs to
+func matchModel(port string) (knownModel, bool) {
+	lower := strings.ToLower(port)
+	for _, model := range knownModels {
+		for _, pattern := range model.patterns {
+			if strings.Contains(lower, pattern) {
+				return model, true
+			}
+		}
+	}
+	return knownModel{}, false
+}
+
+// isDAWPort reports whether a port is a Launchpad's DAW-mode port
+func isDAWPort(port string) bool {
+	return strings.Contains(strings.ToUpper(port), "DAW")
+}
+
+// claimed reports whether an input port was already paired with another detected device
+func claimed(found []DetectedDevice, inPort string) bool {
+	for _, d := range found {
+		if d.InPort == inPort {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,14 @@
 package midi
 
-import "gitlab.com/gomidi/midi/v2"
+import (
+	"fmt"
+	"image/color"
+	"log/slog"
+	"strings"
+
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+	"gitlab.com/gomidi/midi/v2"
+)
 
 // Device represents a MIDI device capable of grid-based interaction
 type Device interface {
@@ -16,4 +24,187 @@ type Device interface {
 	// HandleMessage parses a MIDI message and returns grid position and state
 	// Returns handled=true if the message corresponds to a valid grid event
 	HandleMessage(msg midi.Message) (row, col int, isNoteOn bool, handled bool)
+
+	// CanDisplay reports whether the pad at row, col has a light SetPadColor sets
+	CanDisplay(row, col int) bool
+
+	// ColorGamut is the range of colors the device's lights show
+	ColorGamut() Gamut
+}
+
+// Gamut is the range of colors a device's lights show
+type Gamut int
+
+const (
+	GamutNone     Gamut = iota // No lights
+	GamutRedGreen              // Red and green LEDs at four levels each
+	GamutRGB                   // Full RGB
+)
+
+// Fit is how faithfully a device shows a pad's color
+type Fit int
+
+const (
+	FitExact       Fit = iota // Shown as configured, or off as configured
+	FitApproximate            // Lit, but as a noticeably different color
+	FitDropped                // Not lit at all: no light there, or too dim to show
+)
+
+// CheckColor reports how a device shows the pad at row, col (in the device's own
+// coordinates) when sent sent. intended is the color sent stands for, like the RGB
+// color a classic color was derived from, or sent itself.
+func CheckColor(device Device, row, col int, intended, sent PadColor) Fit {
+	if sent.R == 0 && sent.G == 0 && sent.B == 0 {
+		return FitExact
+	}
+	if !device.CanDisplay(row, col) {
+		return FitDropped
+	}
+	switch device.ColorGamut() {
+	case GamutNone:
+		return FitDropped
+	case GamutRedGreen:
+		if red, green := padcolor.ClassicLevels(sent.R, sent.G, sent.B); red == 0 && green == 0 {
+			return FitDropped
+		}
+		// Blue has no LED of its own: it comes out green or amber
+		if intended.B > 0 && int(intended.B)*2 >= int(max(intended.R, intended.G)) {
+			return FitApproximate
+		}
+	}
+	return FitExact
+}
+
+// Flasher is implemented by devices that blink pads (PadColor.Flash) in a flash mode
+// of their own, which has to be turned on after each reset
+type Flasher interface {
+	EnableFlashing(send func(midi.Message) error) error
+}
+
+// ModeQuerier is implemented by devices that can be asked which mode they are in, so
+// it can be put back after the app has switched them to programmer mode
+type ModeQuerier interface {
+	// ModeQuery is the message asking for the current mode
+	ModeQuery() midi.Message
+	// ParseMode reads the mode from the device's reply; ok is false for other messages
+	// and mode is nil when the device reports no mode worth restoring
+	ParseMode(msg midi.Message) (mode []byte, ok bool)
+}
+
+// ModeRestorer is implemented by devices that can leave programmer mode: back to a mode
+// read with ModeQuerier, or to their default mode for nil
+type ModeRestorer interface {
+	RestoreMode(send func(midi.Message) error, mode []byte) error
+}
+
+// PadUpdate is the color of one pad, for lighting several at once
+type PadUpdate struct {
+	Row, Col int // In the device's own coordinates
+	Color    PadColor
+}
+
+// BatchLighter is implemented by devices that light several pads with one message
+type BatchLighter interface {
+	SetPadColors(send func(midi.Message) error, updates []PadUpdate) error
+}
+
+// WritePads lights the given pads, in one message on devices that can (BatchLighter)
+// and one by one on the others
+func WritePads(device Device, send func(midi.Message) error, updates []PadUpdate) error {
+	if b, ok := device.(BatchLighter); ok {
+		return b.SetPadColors(send, updates)
+	}
+	for _, u := range updates {
+		if err := device.SetPadColor(send, u.Row, u.Col, u.Color); err != nil {
+			return fmt.Errorf("failed to set pad (%d,%d): %w", u.Row, u.Col, err)
+		}
+	}
+	return nil
+}
+
+// Previewer is implemented by devices with lights, showing on screen what a pad looks
+// like when sent a color. It goes through the same conversions as SetPadColor.
+type Previewer interface {
+	Preview(c PadColor) color.RGBA
+}
+
+// Preview returns the screen color of a pad of a device type lit with c; ok is false
+// for device types without lights
+func Preview(deviceType DeviceType, c PadColor) (rgba color.RGBA, ok bool) {
+	p, ok := GetDevice(deviceType).(Previewer)
+	if !ok {
+		return color.RGBA{}, false
+	}
+	return p.Preview(c), true
+}
+
+// OuterPads are the colors of the pads around the 9x9 grid, which only the Launchpad
+// Pro has
+type OuterPads struct {
+	Left           [8]PadColor // Beside grid rows 1-8
+	Bottom         [8]PadColor // Below grid columns 0-7
+	ExtendedBottom [8]PadColor // Below the bottom row
+	TopLeft        PadColor
+	BottomLeft     PadColor
+	BottomRight    PadColor
+}
+
+// flashing reports whether one of the pads blinks
+func (o OuterPads) flashing() bool {
+	for i := range 8 {
+		if o.Left[i].Flash || o.Bottom[i].Flash || o.ExtendedBottom[i].Flash {
+			return true
+		}
+	}
+	return o.TopLeft.Flash || o.BottomLeft.Flash || o.BottomRight.Flash
+}
+
+// OuterLighter is implemented by devices with pads around the 9x9 grid
+type OuterLighter interface {
+	SetOuterPads(send func(midi.Message) error, pads OuterPads) error
+}
+
+// WriteOuterPads lights the pads around the grid on devices that have them (OuterLighter)
+func WriteOuterPads(device Device, send func(midi.Message) error, outer OuterPads) error {
+	if o, ok := device.(OuterLighter); ok {
+		return o.SetOuterPads(send, outer)
+	}
+	return nil
+}
+
+// WriteGrid lights every pad of the device, the pads around the grid on devices that
+// have them (OuterLighter), then turns on flashing if a pad blinks. Pads the device has
+// no light for are skipped.
+func WriteGrid(device Device, send func(midi.Message) error, colors [9][9]PadColor, outer OuterPads) error {
+	flashing := false
+	var skipped []string
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			if !device.CanDisplay(row, col) {
+				if c := colors[row][col]; c.R > 0 || c.G > 0 || c.B > 0 {
+					skipped = append(skipped, fmt.Sprintf("(%d,%d)", row, col))
+				}
+				continue
+			}
+			if err := device.SetPadColor(send, row, col, colors[row][col]); err != nil {
+				return fmt.Errorf("failed to set pad (%d,%d): %w", row, col, err)
+			}
+			flashing = flashing || colors[row][col].Flash
+		}
+	}
+	if o, ok := device.(OuterLighter); ok {
+		if err := o.SetOuterPads(send, outer); err != nil {
+			return fmt.Errorf("failed to set the pads around the grid: %w", err)
+		}
+		flashing = flashing || outer.flashing()
+	}
+	if len(skipped) > 0 {
+		slog.Debug("Skipped lit pads the device has no light for", "pads", strings.Join(skipped, " "))
+	}
+	if f, ok := device.(Flasher); ok && flashing {
+		if err := f.EnableFlashing(send); err != nil {
+			return fmt.Errorf("failed to enable flashing: %w", err)
+		}
+	}
+	return nil
 }
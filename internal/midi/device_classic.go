@@ -2,13 +2,27 @@ package midi
 
 import (
 	"fmt"
+	"image/color"
 
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
 	"gitlab.com/gomidi/midi/v2"
 )
 
 // ClassicDevice implements Device for Launchpad S
 type ClassicDevice struct{}
 
+// Launchpad S LED flags, between the green and red levels of a velocity: Copy writes
+// both buffers (steady), Clear alone writes one and blanks the other, which blinks
+// once flashing is on
+const (
+	classicFlagsSteady = 0x0C // Clear | Copy
+	classicFlagsFlash  = 0x08 // Clear
+)
+
+// classicAutoFlash is the CC 0 value (B0 00 28) that makes the device swap its
+// buffers by itself, so pads written with classicFlagsFlash blink
+const classicAutoFlash = 0x28
+
 func (d *ClassicDevice) ActivateProgrammerMode(send func(midi.Message) error) error {
 	// Launchpad S - reset to default state
 	// Send reset: B0 00 00 (CC 0 value 0)
@@ -18,12 +32,21 @@ func (d *ClassicDevice) ActivateProgrammerMode(send func(midi.Message) error) er
 	return nil
 }
 
+// RestoreMode resets the device, which is also its default mode; the Launchpad S
+// can't be asked for its mode, so mode is always nil
+func (d *ClassicDevice) RestoreMode(send func(midi.Message) error, _ []byte) error {
+	if err := send(midi.ControlChange(0, 0, 0)); err != nil {
+		return fmt.Errorf("failed to reset Launchpad S: %w", err)
+	}
+	return nil
+}
+
 func (d *ClassicDevice) SetPadColor(send func(midi.Message) error, row, col int, color PadColor) error {
 	// Launchpad S mapping logic
 	var mapping PadMapping
 
 	// Position mapping logic (formerly GetPadMapping)
-	if row == 0 && col == 8 {
+	if !d.CanDisplay(row, col) {
 		mapping = PadMapping{Exists: false}
 	} else if row == 0 {
 		// Top row: Control Change 104 + col
@@ -39,27 +62,14 @@ func (d *ClassicDevice) SetPadColor(send func(midi.Message) error, row, col int,
 		return nil
 	}
 
-	// Velocity calculation logic (formerly setColorLaunchpadS)
-	var velocity uint8
-
-	if color.R < 5 && color.G < 5 && color.B < 5 {
-		velocity = 0x0C // flags only, no color = off
-	} else {
-		effectiveR := int(color.R) + int(color.B)/4
-		effectiveG := int(color.G) + (int(color.B)*3)/4
-
-		if effectiveR > 127 {
-			effectiveR = 127
-		}
-		if effectiveG > 127 {
-			effectiveG = 127
-		}
-
-		redLevel := d.colorTo4Level(uint8(effectiveR))
-		greenLevel := d.colorTo4Level(uint8(effectiveG))
-
-		velocity = (greenLevel << 4) | 0x0C | redLevel
+	// Velocity packs the green and red LED levels around the copy/clear flags;
+	// colors too dim for the lowest level come out as just the flags, which is off
+	redLevel, greenLevel := padcolor.ClassicLevels(color.R, color.G, color.B)
+	flags := uint8(classicFlagsSteady)
+	if color.Flash {
+		flags = classicFlagsFlash
 	}
+	velocity := greenLevel<<4 | flags | redLevel
 
 	if mapping.IsCC {
 		return send(midi.ControlChange(0, mapping.Number, velocity))
@@ -67,15 +77,33 @@ func (d *ClassicDevice) SetPadColor(send func(midi.Message) error, row, col int,
 	return send(midi.NoteOn(0, mapping.Number, velocity))
 }
 
-func (d *ClassicDevice) colorTo4Level(value uint8) uint8 {
-	if value < 32 {
-		return 0
-	} else if value < 64 {
-		return 1
-	} else if value < 96 {
-		return 2
-	}
-	return 3
+// Preview returns the screen color of a pad lit with color, at the red and green
+// levels it is sent as
+func (d *ClassicDevice) Preview(c PadColor) color.RGBA {
+	return padcolor.LevelRGBA(padcolor.ClassicLevels(c.R, c.G, c.B))
+}
+
+// CanDisplay reports whether the pad has an LED: every pad but the top-right corner,
+// which the Launchpad S doesn't have
+func (d *ClassicDevice) CanDisplay(row, col int) bool {
+	return row >= 0 && row <= 8 && col >= 0 && col <= 8 && !(row == 0 && col == 8)
+}
+
+// ColorGamut is red and green LEDs at four levels each
+func (d *ClassicDevice) ColorGamut() Gamut {
+	return GamutRedGreen
+}
+
+// SetPadFlash lights a pad blinking between color and off. Blinking needs flashing
+// turned on, see EnableFlashing.
+func (d *ClassicDevice) SetPadFlash(send func(midi.Message) error, row, col int, color PadColor) error {
+	color.Flash = true
+	return d.SetPadColor(send, row, col, color)
+}
+
+// EnableFlashing turns on automatic buffer swapping (B0 00 28). A reset turns it off.
+func (d *ClassicDevice) EnableFlashing(send func(midi.Message) error) error {
+	return send(midi.ControlChange(0, 0, classicAutoFlash))
 }
 
 func (d *ClassicDevice) ClearAllPads(send func(midi.Message) error) error {
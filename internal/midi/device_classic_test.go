@@ -0,0 +1,83 @@
+package midi
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// wire formats messages in hex, one per line
+func wire(sent []midi.Message) string {
+	var b strings.Builder
+	for _, msg := range sent {
+		b.WriteString(strings.ToUpper(hex.EncodeToString(msg)))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// TestClassicFlashBytes pins the Launchpad S messages for blinking pads, as checked on
+// the device: the flash flags (0x08) instead of the steady ones (0x0C) and B0 00 28
+func TestClassicFlashBytes(t *testing.T) {
+	d := &ClassicDevice{}
+	red := PadColor{R: 127}
+	amber := PadColor{R: 127, G: 127}
+
+	tests := []struct {
+		name string
+		send func(send func(midi.Message) error) error
+		want string
+	}{
+		{"steady red grid pad", func(s func(midi.Message) error) error { return d.SetPadColor(s, 1, 1, red) }, "90010F\n"},
+		{"flashing red grid pad", func(s func(midi.Message) error) error { return d.SetPadFlash(s, 1, 1, red) }, "90010B\n"},
+		{"flash flag", func(s func(midi.Message) error) error {
+			return d.SetPadColor(s, 1, 1, PadColor{R: 127, Flash: true})
+		}, "90010B\n"},
+		{"flashing amber scene pad", func(s func(midi.Message) error) error { return d.SetPadFlash(s, 2, 8, amber) }, "90183B\n"},
+		{"flashing top row pad", func(s func(midi.Message) error) error { return d.SetPadFlash(s, 0, 2, amber) }, "B06A3B\n"},
+		{"flashing off", func(s func(midi.Message) error) error { return d.SetPadFlash(s, 1, 1, PadColor{}) }, "900108\n"},
+		{"enable flashing", d.EnableFlashing, "B00028\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []midi.Message
+			if err := tt.send(capture(&sent)); err != nil {
+				t.Fatal(err)
+			}
+			if got := wire(sent); got != tt.want {
+				t.Errorf("sent %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteGridEnablesFlashing(t *testing.T) {
+	var colors [9][9]PadColor
+	colors[3][4] = PadColor{G: 127}
+
+	for _, flash := range []bool{false, true} {
+		colors[5][5].Flash = flash
+		for _, device := range []Device{&ClassicDevice{}, &ColorfulDevice{}} {
+			var sent []midi.Message
+			if err := WriteGrid(device, capture(&sent), colors, OuterPads{}); err != nil {
+				t.Fatal(err)
+			}
+			enabled := wire(sent[len(sent)-1:]) == "B00028\n"
+			_, canFlash := device.(Flasher)
+			if want := flash && canFlash; enabled != want {
+				t.Errorf("%T with flashing pad %v: last message %q", device, flash, wire(sent[len(sent)-1:]))
+			}
+			if n := strings.Count(wire(sent), "B00028"); n > 1 {
+				t.Errorf("%T enabled flashing %d times", device, n)
+			}
+		}
+	}
+}
+
+func TestScaledKeepsFlash(t *testing.T) {
+	if c := (PadColor{R: 100, Flash: true}).Scaled(50); !c.Flash || c.R != 50 {
+		t.Errorf("Scaled = %+v, want R 50 still flashing", c)
+	}
+}
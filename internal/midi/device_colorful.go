@@ -1,8 +1,12 @@
 package midi
 
 import (
+	"bytes"
 	"fmt"
+	"image/color"
+	"slices"
 
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
 	"gitlab.com/gomidi/midi/v2"
 )
 
@@ -18,55 +22,151 @@ func (d *ColorfulDevice) ActivateProgrammerMode(send func(midi.Message) error) e
 	return nil
 }
 
+// layoutHeader starts a layout SysEx (without F0). Followed by a layout it selects that
+// layout; alone it asks for the current one, and the reply is the header and the layout.
+var layoutHeader = []byte{0x00, 0x20, 0x29, 0x02, 0x0D, 0x00}
+
+// layoutProgrammer is the layout the device reports while in programmer mode
+const layoutProgrammer = 0x7F
+
+func (d *ColorfulDevice) ModeQuery() midi.Message {
+	return midi.SysEx(layoutHeader)
+}
+
+func (d *ColorfulDevice) ParseMode(msg midi.Message) ([]byte, bool) {
+	var data []byte
+	if !msg.GetSysEx(&data) || len(data) != len(layoutHeader)+1 || !bytes.HasPrefix(data, layoutHeader) {
+		return nil, false
+	}
+	if data[len(layoutHeader)] == layoutProgrammer {
+		return nil, true // Left there by an earlier run, so what came before is unknown
+	}
+	return data[len(layoutHeader):], true
+}
+
+func (d *ColorfulDevice) RestoreMode(send func(midi.Message) error, mode []byte) error {
+	// SysEx for live mode, the default: 00 20 29 02 0D 0E 00
+	if err := send(midi.SysEx([]byte{0x00, 0x20, 0x29, 0x02, 0x0D, 0x0E, 0x00})); err != nil {
+		return fmt.Errorf("failed to send live mode message: %w", err)
+	}
+	if mode == nil {
+		return nil
+	}
+	if err := send(midi.SysEx(append(slices.Clone(layoutHeader), mode...))); err != nil {
+		return fmt.Errorf("failed to send layout message: %w", err)
+	}
+	return nil
+}
+
 func (d *ColorfulDevice) SetPadColor(send func(midi.Message) error, row, col int, color PadColor) error {
+	// SysEx for RGB LED: F0 00 20 29 02 0D 03 03 <led> <r> <g> <b> F7
+	return send(midi.SysEx(append(slices.Clone(ledLightingHeader), rgbSpec(row, col, color)...)))
+}
+
+// rgbSpecsPerMessage keeps each lighting SysEx about as short as a clearing one
+const rgbSpecsPerMessage = 16
+
+// SetPadColors lights several pads with one LED lighting SysEx, or a few for many pads
+func (d *ColorfulDevice) SetPadColors(send func(midi.Message) error, updates []PadUpdate) error {
+	specs := make([][]byte, len(updates))
+	for i, u := range updates {
+		specs[i] = rgbSpec(u.Row, u.Col, u.Color)
+	}
+	return lightLEDs(send, ledLightingHeader, specs)
+}
+
+// lightLEDs sends colorspecs after an LED lighting header, rgbSpecsPerMessage to a SysEx
+func lightLEDs(send func(midi.Message) error, header []byte, specs [][]byte) error {
+	for start := 0; start < len(specs); start += rgbSpecsPerMessage {
+		sysexContent := slices.Clone(header)
+		for _, spec := range specs[start:min(start+rgbSpecsPerMessage, len(specs))] {
+			sysexContent = append(sysexContent, spec...)
+		}
+		if err := send(midi.SysEx(sysexContent)); err != nil {
+			return fmt.Errorf("failed to set pads: %w", err)
+		}
+	}
+	return nil
+}
+
+// rgbSpec is the colorspec lighting one pad in an RGB color, after the gamma curve
+// that makes colors more distinct
+func rgbSpec(row, col int, color PadColor) []byte {
 	// Launchpad Mini Mk3 programmer mode layout:
 	// LED indices: bottom-left is 11, top-right is 99
 	// Row formula: LED = (9 - row) * 10 + (col + 1)
 	// Top row (row 0): 91-99
 	// Bottom row (row 8): 11-19
-	ledIndex := uint8((8-row)*10 + col + 11)
+	return ledSpec(gridLED(row, col), color)
+}
 
-	// Apply gamma scaling to make colors more distinct
-	r := d.scaleColor(color.R)
-	g := d.scaleColor(color.G)
-	b := d.scaleColor(color.B)
+// gridLED is the LED index of a 9x9 grid pad in programmer mode
+func gridLED(row, col int) uint8 {
+	return uint8((8-row)*10 + col + 11)
+}
 
-	// SysEx for RGB LED: F0 00 20 29 02 0D 03 03 <led> <r> <g> <b> F7
-	sysexContent := []byte{
-		0x00, 0x20, 0x29, 0x02, 0x0D, 0x03,
-		0x03,     // RGB mode
-		ledIndex, // LED index
-		r & 0x7F, // R
-		g & 0x7F, // G
-		b & 0x7F, // B
+// ledSpec is the colorspec lighting one LED in an RGB color, after the gamma curve
+func ledSpec(led uint8, color PadColor) []byte {
+	return []byte{
+		ledRGB,
+		led,
+		padcolor.Gamma(color.R) & 0x7F,
+		padcolor.Gamma(color.G) & 0x7F,
+		padcolor.Gamma(color.B) & 0x7F,
 	}
-	return send(midi.SysEx(sysexContent))
 }
 
-func (d *ColorfulDevice) scaleColor(value uint8) uint8 {
-	if value == 0 {
-		return 0
-	}
-	// Apply a slight curve to enhance color distinction
-	f := float64(value) / 127.0
-	// Use a power curve to enhance saturation
-	scaled := f * f * 127.0
-	if scaled < 1 && value > 0 {
-		scaled = 1 // Ensure non-zero input gives non-zero output
-	}
-	return uint8(scaled)
+// CanDisplay reports whether the pad has an LED; every pad of the 9x9 grid has, the
+// top-right one being the logo
+func (d *ColorfulDevice) CanDisplay(row, col int) bool {
+	return row >= 0 && row <= 8 && col >= 0 && col <= 8
 }
 
+// ColorGamut is full RGB
+func (d *ColorfulDevice) ColorGamut() Gamut {
+	return GamutRGB
+}
+
+// Preview returns the screen color of a pad lit with color, after the gamma curve
+func (d *ColorfulDevice) Preview(c PadColor) color.RGBA {
+	return padcolor.RGBA(padcolor.Gamma(c.R), padcolor.Gamma(c.G), padcolor.Gamma(c.B))
+}
+
+// ledLightingHeader starts an LED lighting SysEx (without F0); each colorspec after it lights one LED
+var ledLightingHeader = []byte{0x00, 0x20, 0x29, 0x02, 0x0D, 0x03}
+
+// ledStatic is the colorspec type for a palette color; it takes one data byte, the palette index
+const ledStatic = 0x00
+
+// ledRGB is the colorspec type for an RGB color; it takes three data bytes, red, green and blue
+const ledRGB = 0x03
+
+// clearSpecsPerMessage keeps each clearing SysEx short enough for small device buffers
+const clearSpecsPerMessage = 27
+
 func (d *ColorfulDevice) ClearAllPads(send func(midi.Message) error) error {
-	// Send SysEx to clear all LEDs
-	// Using static color 0 for all pads
-	sysexContent := []byte{0x00, 0x20, 0x29, 0x02, 0x0D, 0x03}
+	// Every LED, row by row: grid and right column (11-89), then the top row and logo (91-99)
+	var leds []uint8
 	for i := 11; i <= 99; i++ {
-		if i%10 >= 1 && i%10 <= 9 { // Valid LED indices
-			sysexContent = append(sysexContent, 0x00, uint8(i), 0x00) // Static off
+		if i%10 >= 1 && i%10 <= 9 {
+			leds = append(leds, uint8(i))
 		}
 	}
-	return send(midi.SysEx(sysexContent))
+	return clearLEDs(send, ledLightingHeader, leds)
+}
+
+// clearLEDs turns LEDs off after an LED lighting header, clearSpecsPerMessage to a SysEx
+func clearLEDs(send func(midi.Message) error, header []byte, leds []uint8) error {
+	for start := 0; start < len(leds); start += clearSpecsPerMessage {
+		sysexContent := slices.Clone(header)
+		for _, led := range leds[start:min(start+clearSpecsPerMessage, len(leds))] {
+			sysexContent = append(sysexContent, ledStatic, led, 0x00) // Palette color 0 is off
+		}
+		if err := send(midi.SysEx(sysexContent)); err != nil {
+			return fmt.Errorf("failed to clear pads: %w", err)
+		}
+	}
+	return nil
 }
 
 func (d *ColorfulDevice) HandleMessage(msg midi.Message) (row, col int, isNoteOn bool, handled bool) {
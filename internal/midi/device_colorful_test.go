@@ -0,0 +1,96 @@
+package midi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// clearMessage is the exact clearing SysEx for a run of LEDs
+func clearMessage(leds ...uint8) []byte {
+	msg := []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0D, 0x03}
+	for _, led := range leds {
+		msg = append(msg, 0x00, led, 0x00)
+	}
+	return append(msg, 0xF7)
+}
+
+// ledRange returns n LED indices from 11, skipping the ones ending in 0 as the device has no column 0
+func ledRange(n int) []uint8 {
+	var leds []uint8
+	for i := 11; len(leds) < n; i++ {
+		if i%10 != 0 {
+			leds = append(leds, uint8(i))
+		}
+	}
+	return leds
+}
+
+func TestClearLEDsChunks(t *testing.T) {
+	tests := []struct {
+		pads int
+		want [][]uint8 // LEDs of each message
+	}{
+		{0, nil},
+		{1, [][]uint8{{11}}},
+		{26, [][]uint8{ledRange(26)}},
+		{27, [][]uint8{ledRange(27)}},
+		{28, [][]uint8{ledRange(27), {ledRange(28)[27]}}},
+		{54, [][]uint8{ledRange(27), ledRange(54)[27:]}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.pads), func(t *testing.T) {
+			var sent []midi.Message
+			if err := clearLEDs(capture(&sent), ledLightingHeader, ledRange(tt.pads)); err != nil {
+				t.Fatal(err)
+			}
+			if len(sent) != len(tt.want) {
+				t.Fatalf("%d messages, want %d", len(sent), len(tt.want))
+			}
+			for i, leds := range tt.want {
+				if want := clearMessage(leds...); !bytes.Equal(sent[i], want) {
+					t.Errorf("message %d:\n got % X\nwant % X", i, []byte(sent[i]), want)
+				}
+			}
+		})
+	}
+}
+
+func TestColorfulClearAllPads(t *testing.T) {
+	var sent []midi.Message
+	if err := (&ColorfulDevice{}).ClearAllPads(capture(&sent)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 81 LEDs, bottom row first, in three full messages
+	want := [][]byte{
+		clearMessage(11, 12, 13, 14, 15, 16, 17, 18, 19, 21, 22, 23, 24, 25, 26, 27, 28, 29, 31, 32, 33, 34, 35, 36, 37, 38, 39),
+		clearMessage(41, 42, 43, 44, 45, 46, 47, 48, 49, 51, 52, 53, 54, 55, 56, 57, 58, 59, 61, 62, 63, 64, 65, 66, 67, 68, 69),
+		clearMessage(71, 72, 73, 74, 75, 76, 77, 78, 79, 81, 82, 83, 84, 85, 86, 87, 88, 89, 91, 92, 93, 94, 95, 96, 97, 98, 99),
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("%d messages, want %d", len(sent), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(sent[i], want[i]) {
+			t.Errorf("message %d:\n got % X\nwant % X", i, []byte(sent[i]), want[i])
+		}
+		if len(sent[i]) != 7+3*clearSpecsPerMessage+1 {
+			t.Errorf("message %d is %d bytes", i, len(sent[i]))
+		}
+	}
+}
+
+func TestColorfulClearAllPadsStopsOnError(t *testing.T) {
+	calls := 0
+	err := (&ColorfulDevice{}).ClearAllPads(func(midi.Message) error {
+		calls++
+		return errors.New("port closed")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("err = %v after %d sends, want an error after 1", err, calls)
+	}
+}
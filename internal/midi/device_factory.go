@@ -9,6 +9,10 @@ func GetDevice(deviceType DeviceType) Device {
 		return &ColorfulDevice{}
 	case DeviceTypeGeneric:
 		return &GenericDevice{}
+	case DeviceTypeSimulated:
+		return &SimulatedDevice{}
+	case DeviceTypePro:
+		return &ProDevice{}
 	default:
 		// Default to Colorful as fallback (matching previous behavior)
 		return &ColorfulDevice{}
@@ -14,6 +14,16 @@ func (d *GenericDevice) SetPadColor(send func(midi.Message) error, row, col int,
 	return nil
 }
 
+// CanDisplay is always false: generic devices have no lights the app sets
+func (d *GenericDevice) CanDisplay(row, col int) bool {
+	return false
+}
+
+// ColorGamut is GamutNone
+func (d *GenericDevice) ColorGamut() Gamut {
+	return GamutNone
+}
+
 func (d *GenericDevice) ClearAllPads(send func(midi.Message) error) error {
 	return nil
 }
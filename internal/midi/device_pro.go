@@ -0,0 +1,97 @@
+package midi
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// ProDevice implements Device for Launchpad Pro Mk3. Its programmer mode lays the 9x9
+// grid out as the Mini Mk3 does, and lights the pads around it (OuterPads) too.
+type ProDevice struct{}
+
+// proHeader starts every Launchpad Pro Mk3 SysEx (without F0)
+var proHeader = []byte{0x00, 0x20, 0x29, 0x02, 0x0E}
+
+// proLightingHeader starts a Pro LED lighting SysEx, taking the same colorspecs as the Mini's
+var proLightingHeader = append(slices.Clone(proHeader), 0x03)
+
+func (d *ProDevice) ActivateProgrammerMode(send func(midi.Message) error) error {
+	// SysEx for programmer mode: 00 20 29 02 0E 0E 01
+	if err := send(midi.SysEx(append(slices.Clone(proHeader), 0x0E, 0x01))); err != nil {
+		return fmt.Errorf("failed to send programmer mode message: %w", err)
+	}
+	return nil
+}
+
+func (d *ProDevice) RestoreMode(send func(midi.Message) error, _ []byte) error {
+	// SysEx for live mode: 00 20 29 02 0E 0E 00
+	if err := send(midi.SysEx(append(slices.Clone(proHeader), 0x0E, 0x00))); err != nil {
+		return fmt.Errorf("failed to send live mode message: %w", err)
+	}
+	return nil
+}
+
+func (d *ProDevice) SetPadColor(send func(midi.Message) error, row, col int, color PadColor) error {
+	return send(midi.SysEx(append(slices.Clone(proLightingHeader), rgbSpec(row, col, color)...)))
+}
+
+// SetPadColors lights several pads with one LED lighting SysEx, or a few for many pads
+func (d *ProDevice) SetPadColors(send func(midi.Message) error, updates []PadUpdate) error {
+	specs := make([][]byte, len(updates))
+	for i, u := range updates {
+		specs[i] = rgbSpec(u.Row, u.Col, u.Color)
+	}
+	return lightLEDs(send, proLightingHeader, specs)
+}
+
+// SetOuterPads lights the pads around the grid. Programmer mode numbers them on the same
+// plan as the grid: the left column 10-80, the bottom row 1-8 between the corners 0 and
+// 9, the top-left corner 90 and the extended bottom row 101-108.
+func (d *ProDevice) SetOuterPads(send func(midi.Message) error, pads OuterPads) error {
+	specs := [][]byte{ledSpec(90, pads.TopLeft), ledSpec(0, pads.BottomLeft), ledSpec(9, pads.BottomRight)}
+	for i := range 8 {
+		specs = append(specs,
+			ledSpec(uint8((8-i)*10), pads.Left[i]),
+			ledSpec(uint8(i+1), pads.Bottom[i]),
+			ledSpec(uint8(101+i), pads.ExtendedBottom[i]))
+	}
+	return lightLEDs(send, proLightingHeader, specs)
+}
+
+func (d *ProDevice) ClearAllPads(send func(midi.Message) error) error {
+	// Every LED of the 10x10 plan, then the extended bottom row
+	var leds []uint8
+	for i := 0; i <= 99; i++ {
+		leds = append(leds, uint8(i))
+	}
+	for i := 101; i <= 108; i++ {
+		leds = append(leds, uint8(i))
+	}
+	return clearLEDs(send, proLightingHeader, leds)
+}
+
+func (d *ProDevice) HandleMessage(msg midi.Message) (row, col int, isNoteOn bool, handled bool) {
+	// The grid, top row and right column send what the Mini's do; the pads around
+	// them only light up
+	return (&ColorfulDevice{}).HandleMessage(msg)
+}
+
+// CanDisplay reports whether the pad has an LED; every pad of the 9x9 grid has, the
+// top-right one being the logo
+func (d *ProDevice) CanDisplay(row, col int) bool {
+	return row >= 0 && row <= 8 && col >= 0 && col <= 8
+}
+
+// ColorGamut is full RGB
+func (d *ProDevice) ColorGamut() Gamut {
+	return GamutRGB
+}
+
+// Preview returns the screen color of a pad lit with color, after the gamma curve
+func (d *ProDevice) Preview(c PadColor) color.RGBA {
+	return padcolor.RGBA(padcolor.Gamma(c.R), padcolor.Gamma(c.G), padcolor.Gamma(c.B))
+}
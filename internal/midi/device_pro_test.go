@@ -0,0 +1,117 @@
+package midi
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+func TestProProgrammerMode(t *testing.T) {
+	var sent []midi.Message
+	if err := (&ProDevice{}).ActivateProgrammerMode(capture(&sent)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0E, 0x0E, 0x01, 0xF7}; len(sent) != 1 || !bytes.Equal(sent[0], want) {
+		t.Errorf("sent % X, want % X", sent, want)
+	}
+}
+
+func TestProSetPadColor(t *testing.T) {
+	var sent []midi.Message
+	if err := (&ProDevice{}).SetPadColor(capture(&sent), 1, 1, PadColor{R: 127}); err != nil {
+		t.Fatal(err)
+	}
+	// The grid is numbered as on the Mini, with the Pro's device ID
+	if want := []byte{0xF0, 0x00, 0x20, 0x29, 0x02, 0x0E, 0x03, 0x03, 0x52, 0x7F, 0x00, 0x00, 0xF7}; len(sent) != 1 || !bytes.Equal(sent[0], want) {
+		t.Errorf("sent % X, want % X", sent, want)
+	}
+}
+
+// specsOf returns the LED indices lit by LED lighting SysEx messages, and each one's colorspec
+func specsOf(t *testing.T, sent []midi.Message) map[uint8][]byte {
+	t.Helper()
+	specs := map[uint8][]byte{}
+	for _, msg := range sent {
+		var data []byte
+		if !msg.GetSysEx(&data) || !bytes.HasPrefix(data, proLightingHeader) {
+			t.Fatalf("not a Pro lighting message: % X", []byte(msg))
+		}
+		for data = data[len(proLightingHeader):]; len(data) >= 5; data = data[5:] {
+			if _, dup := specs[data[1]]; dup {
+				t.Errorf("LED %d lit twice", data[1])
+			}
+			specs[data[1]] = slices.Clone(data[:5])
+		}
+		if len(data) != 0 {
+			t.Errorf("trailing bytes % X", data)
+		}
+	}
+	return specs
+}
+
+func TestProSetOuterPads(t *testing.T) {
+	var pads OuterPads
+	pads.TopLeft = PadColor{R: 127}
+	pads.BottomLeft = PadColor{G: 127}
+	pads.BottomRight = PadColor{B: 127}
+	pads.Left[0] = PadColor{R: 127, G: 127}   // Beside grid row 1
+	pads.Bottom[7] = PadColor{G: 127, B: 127} // Below grid column 7
+	pads.ExtendedBottom[0] = PadColor{R: 127, B: 127}
+
+	var sent []midi.Message
+	if err := (&ProDevice{}).SetOuterPads(capture(&sent), pads); err != nil {
+		t.Fatal(err)
+	}
+	specs := specsOf(t, sent)
+	if len(specs) != 27 {
+		t.Errorf("%d LEDs lit, want the 27 around the grid", len(specs))
+	}
+	want := map[uint8][]byte{
+		90:  {ledRGB, 90, 0x7F, 0, 0},
+		0:   {ledRGB, 0, 0, 0x7F, 0},
+		9:   {ledRGB, 9, 0, 0, 0x7F},
+		80:  {ledRGB, 80, 0x7F, 0x7F, 0},
+		8:   {ledRGB, 8, 0, 0x7F, 0x7F},
+		101: {ledRGB, 101, 0x7F, 0, 0x7F},
+		10:  {ledRGB, 10, 0, 0, 0}, // Beside grid row 8
+		108: {ledRGB, 108, 0, 0, 0},
+	}
+	for led, spec := range want {
+		if !bytes.Equal(specs[led], spec) {
+			t.Errorf("LED %d: % X, want % X", led, specs[led], spec)
+		}
+	}
+}
+
+func TestWriteGridSendsOuterPads(t *testing.T) {
+	var colors [9][9]PadColor
+	colors[0][8] = PadColor{R: 127} // The logo, the plan's top-right corner
+	var outer OuterPads
+	outer.TopLeft = PadColor{G: 127}
+
+	var sent []midi.Message
+	if err := WriteGrid(&ProDevice{}, capture(&sent), colors, outer); err != nil {
+		t.Fatal(err)
+	}
+	specs := specsOf(t, sent)
+	if len(specs) != 81+27 {
+		t.Errorf("%d LEDs lit, want 108", len(specs))
+	}
+	if want := []byte{ledRGB, 99, 0x7F, 0, 0}; !bytes.Equal(specs[99], want) {
+		t.Errorf("logo: % X, want % X", specs[99], want)
+	}
+	if want := []byte{ledRGB, 90, 0, 0x7F, 0}; !bytes.Equal(specs[90], want) {
+		t.Errorf("top-left corner: % X, want % X", specs[90], want)
+	}
+
+	// Devices without the pads get only the grid
+	sent = nil
+	if err := WriteGrid(&ColorfulDevice{}, capture(&sent), colors, outer); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 81 {
+		t.Errorf("colorful device: %d messages, want 81", len(sent))
+	}
+}
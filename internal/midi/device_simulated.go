@@ -0,0 +1,77 @@
+package midi
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// SimulatedDevice implements Device for the on-screen Launchpad (see Simulator). Pads
+// are notes row*10+col on channel 1, and lights go out as SysEx under the
+// non-commercial manufacturer ID, so every pad of the 9x9 grid exists and shows full RGB.
+type SimulatedDevice struct{}
+
+// Simulator SysEx: F0 7D <command> ... F7
+const (
+	simulatedManufacturer = 0x7D
+	simulatedSetPad       = 0x01 // row col r g b flash
+	simulatedClear        = 0x02
+)
+
+func (d *SimulatedDevice) ActivateProgrammerMode(send func(midi.Message) error) error {
+	return nil // Always in programmer mode
+}
+
+func (d *SimulatedDevice) SetPadColor(send func(midi.Message) error, row, col int, color PadColor) error {
+	if row < 0 || row > 8 || col < 0 || col > 8 {
+		return fmt.Errorf("pad (%d,%d) is off the grid", row, col)
+	}
+	flash := byte(0)
+	if color.Flash {
+		flash = 1
+	}
+	return send(midi.SysEx([]byte{simulatedManufacturer, simulatedSetPad,
+		byte(row), byte(col), color.R & 0x7F, color.G & 0x7F, color.B & 0x7F, flash}))
+}
+
+// Preview returns the screen color of a pad lit with color; it shows full RGB as sent
+func (d *SimulatedDevice) Preview(c PadColor) color.RGBA {
+	return padcolor.RGBA(c.R, c.G, c.B)
+}
+
+// CanDisplay reports whether the pad is on the 9x9 grid, all of which the simulator draws
+func (d *SimulatedDevice) CanDisplay(row, col int) bool {
+	return row >= 0 && row <= 8 && col >= 0 && col <= 8
+}
+
+// ColorGamut is full RGB
+func (d *SimulatedDevice) ColorGamut() Gamut {
+	return GamutRGB
+}
+
+func (d *SimulatedDevice) ClearAllPads(send func(midi.Message) error) error {
+	return send(midi.SysEx([]byte{simulatedManufacturer, simulatedClear}))
+}
+
+func (d *SimulatedDevice) HandleMessage(msg midi.Message) (row, col int, isNoteOn bool, handled bool) {
+	var channel, key, velocity uint8
+	switch {
+	case msg.GetNoteStart(&channel, &key, &velocity):
+		isNoteOn = true
+	case msg.GetNoteEnd(&channel, &key):
+	default:
+		return 0, 0, false, false
+	}
+	row, col = int(key)/10, int(key)%10
+	if row > 8 || col > 8 {
+		return 0, 0, false, false
+	}
+	return row, col, isNoteOn, true
+}
+
+// simulatedPadNote is the note a pad of the simulated device sends
+func simulatedPadNote(row, col int) uint8 {
+	return uint8(row*10 + col)
+}
@@ -0,0 +1,63 @@
+package midi
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// capture returns a send function recording what it is given
+func capture(sent *[]midi.Message) func(midi.Message) error {
+	return func(msg midi.Message) error {
+		*sent = append(*sent, msg)
+		return nil
+	}
+}
+
+// TestClassicWireMatchesLevels checks that a classic color stored from levels is sent
+// as those levels and previewed as the color the editor shows for them
+func TestClassicWireMatchesLevels(t *testing.T) {
+	d := &ClassicDevice{}
+	for red := uint8(0); red <= padcolor.MaxLevel; red++ {
+		for green := uint8(0); green <= padcolor.MaxLevel; green++ {
+			c := PadColor{R: padcolor.LevelValue(red), G: padcolor.LevelValue(green)}
+			var sent []midi.Message
+			if err := d.SetPadColor(capture(&sent), 1, 0, c); err != nil {
+				t.Fatal(err)
+			}
+			var channel, key, velocity uint8
+			if len(sent) != 1 || !sent[0].GetNoteOn(&channel, &key, &velocity) {
+				t.Fatalf("levels %d, %d sent %v, want one note on", red, green, sent)
+			}
+			if velocity&0x03 != red || velocity>>4&0x03 != green || velocity&0x0C != classicFlagsSteady {
+				t.Errorf("levels %d, %d sent velocity %#02x", red, green, velocity)
+			}
+			if got, want := d.Preview(c), padcolor.LevelRGBA(red, green); got != want {
+				t.Errorf("levels %d, %d preview as %v, want %v", red, green, got, want)
+			}
+		}
+	}
+}
+
+// TestColorfulWireMatchesPreview checks that a colorful pad is previewed as the bytes it is sent
+func TestColorfulWireMatchesPreview(t *testing.T) {
+	d := &ColorfulDevice{}
+	for _, c := range []PadColor{{}, {R: 127}, {G: 64, B: 1}, {R: 127, G: 127, B: 127}, {R: 10, G: 90, B: 45}} {
+		var sent []midi.Message
+		if err := d.SetPadColor(capture(&sent), 8, 0, c); err != nil {
+			t.Fatal(err)
+		}
+		var data []byte
+		if len(sent) != 1 || !sent[0].GetSysEx(&data) {
+			t.Fatalf("%v sent %v, want one SysEx", c, sent)
+		}
+		spec := data[len(ledLightingHeader):]
+		if len(spec) != 5 || spec[0] != ledRGB || spec[1] != 11 {
+			t.Fatalf("%v sent colorspec % X, want RGB for LED 11", c, spec)
+		}
+		if got, want := d.Preview(c), padcolor.RGBA(spec[2], spec[3], spec[4]); got != want {
+			t.Errorf("%v previews as %v, but is sent as %v", c, got, want)
+		}
+	}
+}
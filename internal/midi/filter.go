@@ -0,0 +1,47 @@
+package midi
+
+import (
+	"log/slog"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// InputFilter drops incoming messages before a device decodes them as pad presses.
+// The zero value lets everything through.
+type InputFilter struct {
+	Channels    uint16 // Bit n allows channel n (0-based); 0 allows every channel
+	IgnoreCC    bool   // Drop Control Changes
+	IgnoreNotes bool   // Drop Note On/Off
+	LogFiltered bool   // Log dropped messages at debug level
+}
+
+// Allows reports whether a message gets through the filter. Messages without a
+// channel, such as SysEx, are left for the device to ignore.
+func (f InputFilter) Allows(msg midi.Message) bool {
+	var channel, key, value uint8
+	switch {
+	case msg.GetNoteOn(&channel, &key, &value), msg.GetNoteOff(&channel, &key, &value):
+		if f.IgnoreNotes {
+			return false
+		}
+	case msg.GetControlChange(&channel, &key, &value):
+		if f.IgnoreCC {
+			return false
+		}
+	case msg.GetChannel(&channel):
+	default:
+		return true
+	}
+	return f.Channels == 0 || f.Channels&(1<<channel) != 0
+}
+
+// Pass applies the filter to a message received on port, logging it if dropped
+func (f InputFilter) Pass(port string, msg midi.Message) bool {
+	if f.Allows(msg) {
+		return true
+	}
+	if f.LogFiltered {
+		slog.Debug("MIDI in filtered", "port", port, "msg", msg.String())
+	}
+	return false
+}
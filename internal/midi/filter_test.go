@@ -0,0 +1,46 @@
+package midi
+
+import (
+	"testing"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+func TestInputFilterAllows(t *testing.T) {
+	noteCh0 := midi.NoteOn(0, 60, 100)
+	noteCh2 := midi.NoteOn(2, 60, 100)
+	offCh2 := midi.NoteOff(2, 60)
+	ccCh0 := midi.ControlChange(0, 91, 127)
+	bendCh2 := midi.Pitchbend(2, 100)
+	sysex := midi.SysEx([]byte{0x00, 0x20, 0x29})
+
+	tests := []struct {
+		name   string
+		filter InputFilter
+		msg    midi.Message
+		want   bool
+	}{
+		{"zero value lets notes through", InputFilter{}, noteCh2, true},
+		{"zero value lets CC through", InputFilter{}, ccCh0, true},
+		{"allowed channel", InputFilter{Channels: 1}, noteCh0, true},
+		{"other channel", InputFilter{Channels: 1}, noteCh2, false},
+		{"note off on another channel", InputFilter{Channels: 1}, offCh2, false},
+		{"one of several channels", InputFilter{Channels: 1 | 1<<2}, noteCh2, true},
+		{"pitch bend on another channel", InputFilter{Channels: 1}, bendCh2, false},
+		{"ignore notes", InputFilter{IgnoreNotes: true}, noteCh0, false},
+		{"ignore notes keeps CC", InputFilter{IgnoreNotes: true}, ccCh0, true},
+		{"ignore CC", InputFilter{IgnoreCC: true}, ccCh0, false},
+		{"ignore CC keeps notes", InputFilter{IgnoreCC: true}, noteCh0, true},
+		{"sysex has no channel", InputFilter{Channels: 1 << 5, IgnoreNotes: true, IgnoreCC: true}, sysex, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.msg); got != tt.want {
+				t.Errorf("Allows(%s) = %v, want %v", tt.msg, got, tt.want)
+			}
+			if got := tt.filter.Pass("port", tt.msg); got != tt.want {
+				t.Errorf("Pass(%s) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,13 @@ package midi
 
 import (
 	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
 	"sync"
+	"time"
 
+	"github.com/PixPMusic/gopher-automate/internal/metrics"
 	"gitlab.com/gomidi/midi/v2"
 	"gitlab.com/gomidi/midi/v2/drivers"
 	_ "gitlab.com/gomidi/midi/v2/drivers/rtmididrv" // Register rtmidi driver
@@ -12,23 +17,51 @@ import (
 // Manager handles MIDI device discovery and management
 type Manager struct {
 	mu sync.RWMutex
+
+	// Virtual output port, see OpenVirtualOut
+	virtualName string      // Port to keep open, "" for none
+	virtualOut  drivers.Out // The open port, nil if opening failed
+	virtualSeen bool        // The port has shown up among the system's inputs
+
+	simulator *Simulator // See OpenSimulator, nil while closed
+
+	waiters waiters // See WaitForMessage
+
+	queues map[string]*sendQueue // Output ports with a SendLimit, see SetSendLimit
 }
 
 // NewManager creates a new MIDI manager
 func NewManager() *Manager {
-	return &Manager{}
+	m := &Manager{}
+	m.waiters = newWaiters(m.listenForWaiters)
+	return m
 }
 
-// Close cleans up the MIDI driver
+// Close writes what is still queued, removes the virtual output port and cleans up
+// the MIDI driver
 func (m *Manager) Close() {
+	m.closeQueues()
+	m.CloseVirtualOut()
 	midi.CloseDriver()
 }
 
-// ListInPorts returns the names of available MIDI input ports
+// ListInPorts returns the names of available MIDI input ports. The app's own virtual
+// output, which the system lists as an input, is left out.
 func (m *Manager) ListInPorts() []string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	names := slices.DeleteFunc(systemInPorts(), func(name string) bool {
+		return m.virtualName != "" && name == m.virtualName
+	})
+	if m.simulator != nil {
+		names = append(names, SimulatorPortName)
+	}
+	return names
+}
+
+// systemInPorts returns every input port the driver reports
+func systemInPorts() []string {
 	ins := midi.GetInPorts()
 	names := make([]string, 0, len(ins))
 	for _, in := range ins {
@@ -43,43 +76,86 @@ func (m *Manager) ListOutPorts() []string {
 	defer m.mu.RUnlock()
 
 	outs := midi.GetOutPorts()
-	names := make([]string, 0, len(outs))
+	names := make([]string, 0, len(outs)+2)
 	for _, out := range outs {
 		names = append(names, out.String())
 	}
+	if m.virtualOut != nil {
+		names = append(names, m.virtualName)
+	}
+	if m.simulator != nil {
+		names = append(names, SimulatorPortName)
+	}
 	return names
 }
 
-// GetInPort returns an input port by name
+// PortsChangedCallback is called with the current port names when the set of
+// available MIDI ports changes (e.g. a device was plugged in or removed)
+type PortsChangedCallback func(inPorts, outPorts []string)
+
+// WatchPorts polls the available ports every interval and calls onChange whenever
+// they differ from the previous poll. The callback runs on the polling goroutine.
+// Returns a function that stops watching.
+func (m *Manager) WatchPorts(interval time.Duration, onChange PortsChangedCallback) func() {
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		lastIn, lastOut := m.ListInPorts(), m.ListOutPorts()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.checkVirtualOut(systemInPorts())
+				ins, outs := m.ListInPorts(), m.ListOutPorts()
+				if slices.Equal(ins, lastIn) && slices.Equal(outs, lastOut) {
+					continue
+				}
+				lastIn, lastOut = ins, outs
+				onChange(ins, outs)
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// GetInPort returns an input port by name, or a *PortNotFoundError if there is none
 func (m *Manager) GetInPort(name string) (drivers.In, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	if m.simulator != nil && name == SimulatorPortName {
+		return m.simulator.InPort(), nil
+	}
 	ins := midi.GetInPorts()
+	names := make([]string, 0, len(ins))
 	for _, in := range ins {
 		if in.String() == name {
 			return in, nil
 		}
+		names = append(names, in.String())
 	}
-	return nil, nil
+	return nil, NewPortNotFoundError("input", name, names)
 }
 
-// GetOutPort returns an output port by name
+// GetOutPort returns an output port by name, or a *PortNotFoundError if there is none
 func (m *Manager) GetOutPort(name string) (drivers.Out, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	outs := midi.GetOutPorts()
-	for _, out := range outs {
-		if out.String() == name {
-			return out, nil
-		}
-	}
-	return nil, nil
+	return m.findOutPort(name)
 }
 
-// NoteCallback is called when a Note On/Off event is received
-type NoteCallback func(portName string, row, col int, isNoteOn bool)
+// NoteCallback is called when a Note On/Off event is received, with the velocity
+// (or CC value) of a press and 0 for a release
+type NoteCallback func(portName string, row, col int, isNoteOn bool, velocity uint8)
 
 // GenericMIDICallback is called for any MIDI message (for inter-app communication)
 // msgType: "note", "cc", "program_change"
@@ -88,6 +164,33 @@ type NoteCallback func(portName string, row, col int, isNoteOn bool)
 // value: velocity/CC value (0-127)
 type GenericMIDICallback func(portName string, msgType string, channel, number, value int)
 
+// DecodeGeneric extracts the fields a GenericMIDICallback receives from a message.
+// ok is false for message types generic listeners ignore.
+func DecodeGeneric(msg midi.Message) (msgType string, channel, number, value int, ok bool) {
+	var ch, key, velocity uint8
+
+	switch {
+	case msg.GetNoteOn(&ch, &key, &velocity):
+		return "note", int(ch), int(key), int(velocity), true
+	case msg.GetNoteOff(&ch, &key, &velocity):
+		return "note", int(ch), int(key), 0, true
+	case msg.GetControlChange(&ch, &key, &velocity):
+		return "cc", int(ch), int(key), int(velocity), true
+	case msg.GetProgramChange(&ch, &key):
+		return "program_change", int(ch), int(key), 127, true
+	}
+	return "", 0, 0, 0, false
+}
+
+// Velocity returns the velocity of a Note On or the value of a Control Change, 0 for anything else
+func Velocity(msg midi.Message) uint8 {
+	var channel, key, velocity uint8
+	if msg.GetNoteOn(&channel, &key, &velocity) || msg.GetControlChange(&channel, &key, &velocity) {
+		return velocity
+	}
+	return 0
+}
+
 // StartGenericListening listens for all MIDI messages on a port (for inter-app communication)
 func (m *Manager) StartGenericListening(inPortName string, callback GenericMIDICallback) (func(), error) {
 	if inPortName == "" {
@@ -96,60 +199,85 @@ func (m *Manager) StartGenericListening(inPortName string, callback GenericMIDIC
 
 	// Get input port
 	inPort, err := m.GetInPort(inPortName)
-	if inPort == nil || err != nil {
-		return nil, fmt.Errorf("input port not found: %s", inPortName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create listener for all message types
+	m.waiters.listening(inPortName, true)
 	stop, err := midi.ListenTo(inPort, func(msg midi.Message, timestampms int32) {
-		var channel, key, velocity uint8
-
-		switch {
-		case msg.GetNoteOn(&channel, &key, &velocity):
-			callback(inPortName, "note", int(channel), int(key), int(velocity))
-		case msg.GetNoteOff(&channel, &key, &velocity):
-			callback(inPortName, "note", int(channel), int(key), 0)
-		case msg.GetControlChange(&channel, &key, &velocity):
-			callback(inPortName, "cc", int(channel), int(key), int(velocity))
-		case msg.GetProgramChange(&channel, &key):
-			callback(inPortName, "program_change", int(channel), int(key), 127)
+		defer recoverListener(inPortName)
+		metrics.Messages.Add(1)
+		traceReceived(inPortName, msg)
+		m.waiters.deliver(inPortName, msg)
+
+		if msgType, channel, number, value, ok := DecodeGeneric(msg); ok {
+			callback(inPortName, msgType, channel, number, value)
 		}
-	})
+	}, midi.UseSysEx())
 
 	if err != nil {
+		m.waiters.listening(inPortName, false)
 		return nil, fmt.Errorf("failed to start generic listening: %w", err)
 	}
 
-	return stop, nil
+	return m.stopListening(inPortName, stop), nil
 }
 
-// StartListening begins listening for MIDI input on the specified port
-func (m *Manager) StartListening(inPortName string, deviceType DeviceType, callback NoteCallback) (func(), error) {
+// StartListening begins listening for MIDI input on the specified port; messages the
+// filter drops never reach the device's decoder
+func (m *Manager) StartListening(inPortName string, deviceType DeviceType, filter InputFilter, callback NoteCallback) (func(), error) {
 	if inPortName == "" {
 		return nil, nil
 	}
 
 	// Get input port
 	inPort, err := m.GetInPort(inPortName)
-	if inPort == nil || err != nil {
-		return nil, fmt.Errorf("input port not found: %s", inPortName)
+	if err != nil {
+		return nil, err
 	}
 
 	device := GetDevice(deviceType)
 
 	// Create listener
+	m.waiters.listening(inPortName, true)
 	stop, err := midi.ListenTo(inPort, func(msg midi.Message, timestampms int32) {
+		defer recoverListener(inPortName)
+		metrics.Messages.Add(1)
+		traceReceived(inPortName, msg)
+		m.waiters.deliver(inPortName, msg) // Unfiltered: waits pick their own messages
+		if !filter.Pass(inPortName, msg) {
+			return
+		}
+
 		row, col, isNoteOn, handled := device.HandleMessage(msg)
 		if handled {
-			callback(inPortName, row, col, isNoteOn)
+			callback(inPortName, row, col, isNoteOn, Velocity(msg))
 		}
-	})
+	}, midi.UseSysEx())
 
 	if err != nil {
+		m.waiters.listening(inPortName, false)
 		return nil, fmt.Errorf("failed to start listening: %w", err)
 	}
 
-	return stop, nil
+	return m.stopListening(inPortName, stop), nil
+}
+
+// stopListening wraps a listener's stop function to tell the waiters it is gone
+func (m *Manager) stopListening(port string, stop func()) func() {
+	return func() {
+		stop()
+		m.waiters.listening(port, false)
+	}
+}
+
+// recoverListener keeps a panic while handling one message from stopping the port's listener
+func recoverListener(port string) {
+	if r := recover(); r != nil {
+		slog.Error("MIDI listener panicked", "port", port, "panic", r, "stack", string(debug.Stack()))
+		metrics.Errors.Add(1)
+	}
 }
 
 // ActivateProgrammerMode sends the appropriate MIDI message to put the device in programmer mode
@@ -161,72 +289,261 @@ func (m *Manager) ActivateProgrammerMode(outPortName string, deviceType DeviceTy
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	outPort := m.findOutPort(outPortName)
-	if outPort == nil {
-		return fmt.Errorf("output port not found: %s", outPortName)
+	outPort, err := m.findOutPort(outPortName)
+	if err != nil {
+		return err
 	}
 
 	send, err := midi.SendTo(outPort)
 	if err != nil {
 		return fmt.Errorf("failed to create sender: %w", err)
 	}
+	send = TraceSender(outPortName, send)
 
 	device := GetDevice(deviceType)
 	return device.ActivateProgrammerMode(send)
 }
 
-// SetPadColor sets a pad color using the appropriate method for the device type
-func (m *Manager) SetPadColor(outPortName string, deviceType DeviceType, row, col int, color PadColor) error {
-	if outPortName == "" {
+// QueryDeviceMode asks a device which mode it is in, waiting up to timeout for the
+// reply on its input port. It returns nil if the device type can't be asked or
+// nothing answered in time; pass what it returns to RestoreDeviceMode.
+func (m *Manager) QueryDeviceMode(inPortName, outPortName string, deviceType DeviceType, timeout time.Duration) ([]byte, error) {
+	device, ok := GetDevice(deviceType).(ModeQuerier)
+	if !ok || inPortName == "" || outPortName == "" {
+		return nil, nil
+	}
+
+	// Listening starts before the query goes out, so a quick reply isn't missed
+	replies := make(chan []byte, 1)
+	remove, err := m.waiters.add(inPortName, func(msg midi.Message) {
+		if mode, ok := device.ParseMode(msg); ok {
+			select {
+			case replies <- mode:
+			default:
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the mode: %w", err)
+	}
+	defer remove()
+
+	m.mu.Lock()
+	outPort, err := m.findOutPort(outPortName)
+	var send func(midi.Message) error
+	if err == nil {
+		send, err = midi.SendTo(outPort)
+	}
+	if err == nil {
+		err = TraceSender(outPortName, send)(device.ModeQuery())
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to ask for the mode: %w", err)
+	}
+
+	select {
+	case mode := <-replies:
+		return mode, nil
+	case <-time.After(timeout):
+		return nil, nil
+	}
+}
+
+// RestoreDeviceMode takes a device out of programmer mode, into a mode returned by
+// QueryDeviceMode or its default mode for nil. Device types that can't are left alone.
+func (m *Manager) RestoreDeviceMode(outPortName string, deviceType DeviceType, mode []byte) error {
+	device, ok := GetDevice(deviceType).(ModeRestorer)
+	if !ok || outPortName == "" {
 		return nil
 	}
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	outPort := m.findOutPort(outPortName)
-	if outPort == nil {
-		return fmt.Errorf("output port not found: %s", outPortName)
+	outPort, err := m.findOutPort(outPortName)
+	if err != nil {
+		return err
 	}
 
 	send, err := midi.SendTo(outPort)
 	if err != nil {
 		return fmt.Errorf("failed to create sender: %w", err)
 	}
+	return device.RestoreMode(TraceSender(outPortName, send), mode)
+}
+
+// SetPadColor sets a pad color using the appropriate method for the device type
+func (m *Manager) SetPadColor(outPortName string, deviceType DeviceType, row, col int, color PadColor) (err error) {
+	if outPortName == "" {
+		return nil
+	}
+	defer func(start time.Time) { metrics.Record(metrics.PadUpdate, start, err) }(time.Now())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, false)
+	if err != nil {
+		return err
+	}
 
 	device := GetDevice(deviceType)
 	return device.SetPadColor(send, row, col, color)
 }
 
-// ClearAllPads turns off all LEDs on a device
-func (m *Manager) ClearAllPads(outPortName string, deviceType DeviceType) error {
+// SetPadColors lights several pads in as few messages as the device allows, after
+// anything queued for the port. See WritePads.
+func (m *Manager) SetPadColors(outPortName string, deviceType DeviceType, updates []PadUpdate) (err error) {
+	if outPortName == "" || len(updates) == 0 {
+		return nil
+	}
+	defer func(start time.Time) { metrics.Record(metrics.PadUpdate, start, err) }(time.Now())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, false)
+	if err != nil {
+		return err
+	}
+	return WritePads(GetDevice(deviceType), send, updates)
+}
+
+// SetPadColorsPriority lights several pads ahead of anything queued for the port, in
+// as few messages as the device allows, for feedback that should show at once. See WritePads.
+func (m *Manager) SetPadColorsPriority(outPortName string, deviceType DeviceType, updates []PadUpdate) (err error) {
+	if outPortName == "" || len(updates) == 0 {
+		return nil
+	}
+	defer func(start time.Time) { metrics.Record(metrics.PadUpdate, start, err) }(time.Now())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, true)
+	if err != nil {
+		return err
+	}
+	return WritePads(GetDevice(deviceType), send, updates)
+}
+
+// SetOuterPads lights the pads around the 9x9 grid on devices that have them
+func (m *Manager) SetOuterPads(outPortName string, deviceType DeviceType, outer OuterPads) (err error) {
 	if outPortName == "" {
 		return nil
 	}
+	defer func(start time.Time) { metrics.Record(metrics.PadUpdate, start, err) }(time.Now())
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	outPort := m.findOutPort(outPortName)
-	if outPort == nil {
-		return fmt.Errorf("output port not found: %s", outPortName)
+	send, err := m.sender(outPortName, false)
+	if err != nil {
+		return err
+	}
+	return WriteOuterPads(GetDevice(deviceType), send, outer)
+}
+
+// SendGrid sets every pad of the 9x9 grid, and the pads around it on devices that have
+// them, using a single sender, stopping at the first error
+func (m *Manager) SendGrid(outPortName string, deviceType DeviceType, colors [9][9]PadColor, outer OuterPads) (err error) {
+	if outPortName == "" {
+		return nil
 	}
+	defer func(start time.Time) { metrics.Record(metrics.GridPush.Get(outPortName), start, err) }(time.Now())
 
-	send, err := midi.SendTo(outPort)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, false)
 	if err != nil {
-		return fmt.Errorf("failed to create sender: %w", err)
+		return err
+	}
+
+	return WriteGrid(GetDevice(deviceType), send, colors, outer)
+}
+
+// SendNote sends a Note On to an output port, or a Note Off when velocity is 0
+func (m *Manager) SendNote(outPortName string, channel, note, velocity uint8) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, false)
+	if err != nil {
+		return err
+	}
+	return send(NoteMessage(channel, note, velocity))
+}
+
+// Send writes a message to an output port
+func (m *Manager) Send(outPortName string, msg midi.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, false)
+	if err != nil {
+		return err
+	}
+	return send(msg)
+}
+
+// NoteMessage builds a Note On, or a Note Off when velocity is 0
+func NoteMessage(channel, note, velocity uint8) midi.Message {
+	if velocity == 0 {
+		return midi.NoteOff(channel&0x0F, note&0x7F)
+	}
+	return midi.NoteOn(channel&0x0F, note&0x7F, velocity&0x7F)
+}
+
+// ClearAllPads turns off all LEDs on a device
+func (m *Manager) ClearAllPads(outPortName string, deviceType DeviceType) error {
+	if outPortName == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	send, err := m.sender(outPortName, false)
+	if err != nil {
+		return err
 	}
 
 	device := GetDevice(deviceType)
 	return device.ClearAllPads(send)
 }
 
-func (m *Manager) findOutPort(name string) drivers.Out {
+// sender returns a traced sender for an output port, going through the port's send
+// queue if it has one; the caller holds m.mu
+func (m *Manager) sender(outPortName string, priority bool) (func(midi.Message) error, error) {
+	outPort, err := m.findOutPort(outPortName)
+	if err != nil {
+		return nil, err
+	}
+
+	send, err := midi.SendTo(outPort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sender: %w", err)
+	}
+	return m.queuedSender(outPortName, TraceSender(outPortName, send), priority), nil
+}
+
+// findOutPort looks up an output port; the caller holds m.mu
+func (m *Manager) findOutPort(name string) (drivers.Out, error) {
+	if m.virtualOut != nil && name == m.virtualName {
+		return m.virtualOut, nil
+	}
+	if m.simulator != nil && name == SimulatorPortName {
+		return m.simulator.OutPort(), nil
+	}
 	outs := midi.GetOutPorts()
+	names := make([]string, 0, len(outs))
 	for _, out := range outs {
 		if out.String() == name {
-			return out
+			return out, nil
 		}
+		names = append(names, out.String())
 	}
-	return nil
+	return nil, NewPortNotFoundError("output", name, names)
 }
@@ -0,0 +1,506 @@
+// Package miditest provides an in-memory midi.Ports for exercising code that
+// talks to MIDI devices without a driver or hardware.
+package miditest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	gomidi "gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// Sent is one message written to an output port
+type Sent struct {
+	Port string
+	Msg  gomidi.Message
+}
+
+// Manager records every message sent through it and delivers injected input to
+// the registered listeners. The zero value has no ports; use NewManager.
+type Manager struct {
+	mu       sync.Mutex
+	inPorts  []string
+	outPorts []string
+	sent     []Sent
+	limits   map[string]midi.SendLimit // See SetSendLimit
+
+	nextID   int
+	notes    map[int]noteListener
+	generics map[int]genericListener
+	waits    map[int]genericWait
+	watchers map[int]midi.PortsChangedCallback
+
+	virtualName string // Output added by OpenVirtualOut
+
+	simulator     *midi.Simulator // Added by OpenSimulator
+	stopSimulator func()
+}
+
+type noteListener struct {
+	port       string
+	deviceType midi.DeviceType
+	filter     midi.InputFilter
+	callback   midi.NoteCallback
+}
+
+type genericListener struct {
+	port     string
+	callback midi.GenericMIDICallback
+}
+
+type genericWait struct {
+	port    string
+	deliver func(gomidi.Message)
+}
+
+var _ midi.Ports = (*Manager)(nil)
+
+// NewManager creates a fake with the given input and output port names
+func NewManager(inPorts, outPorts []string) *Manager {
+	return &Manager{
+		inPorts:  slices.Clone(inPorts),
+		outPorts: slices.Clone(outPorts),
+		notes:    map[int]noteListener{},
+		generics: map[int]genericListener{},
+		waits:    map[int]genericWait{},
+		watchers: map[int]midi.PortsChangedCallback{},
+		limits:   map[string]midi.SendLimit{},
+	}
+}
+
+// ListInPorts returns the fake input port names
+func (m *Manager) ListInPorts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.inPorts)
+}
+
+// ListOutPorts returns the fake output port names
+func (m *Manager) ListOutPorts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.outPorts)
+}
+
+// WatchPorts registers onChange to be called by SetPorts; interval is ignored
+func (m *Manager) WatchPorts(interval time.Duration, onChange midi.PortsChangedCallback) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.register()
+	m.watchers[id] = onChange
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.watchers, id)
+	}
+}
+
+// SetPorts replaces the port lists and notifies watchers, as if devices were plugged in or removed
+func (m *Manager) SetPorts(inPorts, outPorts []string) {
+	m.mu.Lock()
+	m.inPorts = slices.Clone(inPorts)
+	m.outPorts = slices.Clone(outPorts)
+	watchers := make([]midi.PortsChangedCallback, 0, len(m.watchers))
+	for _, w := range m.watchers {
+		watchers = append(watchers, w)
+	}
+	m.mu.Unlock()
+
+	for _, w := range watchers {
+		w(slices.Clone(inPorts), slices.Clone(outPorts))
+	}
+}
+
+// GetOutPort returns a recording port, or a *midi.PortNotFoundError if no output has that name
+func (m *Manager) GetOutPort(name string) (drivers.Out, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.Contains(m.outPorts, name) {
+		return nil, midi.NewPortNotFoundError("output", name, m.outPorts)
+	}
+	return &outPort{manager: m, name: name}, nil
+}
+
+// StartListening registers a pad listener that Inject feeds through the filter and the device's decoder
+func (m *Manager) StartListening(inPortName string, deviceType midi.DeviceType, filter midi.InputFilter, callback midi.NoteCallback) (func(), error) {
+	if inPortName == "" {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.Contains(m.inPorts, inPortName) {
+		return nil, midi.NewPortNotFoundError("input", inPortName, m.inPorts)
+	}
+	id := m.register()
+	m.notes[id] = noteListener{port: inPortName, deviceType: deviceType, filter: filter, callback: callback}
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.notes, id)
+	}, nil
+}
+
+// StartGenericListening registers a listener that Inject feeds every decodable message
+func (m *Manager) StartGenericListening(inPortName string, callback midi.GenericMIDICallback) (func(), error) {
+	if inPortName == "" {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.Contains(m.inPorts, inPortName) {
+		return nil, midi.NewPortNotFoundError("input", inPortName, m.inPorts)
+	}
+	id := m.register()
+	m.generics[id] = genericListener{port: inPortName, callback: callback}
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.generics, id)
+	}, nil
+}
+
+// ActivateProgrammerMode records the device's programmer-mode messages
+func (m *Manager) ActivateProgrammerMode(outPortName string, deviceType midi.DeviceType) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.GetDevice(deviceType).ActivateProgrammerMode(send)
+}
+
+// QueryDeviceMode records the device's mode query; no fake device answers it, so the
+// mode is always nil
+func (m *Manager) QueryDeviceMode(inPortName, outPortName string, deviceType midi.DeviceType, timeout time.Duration) ([]byte, error) {
+	device, ok := midi.GetDevice(deviceType).(midi.ModeQuerier)
+	if !ok || inPortName == "" {
+		return nil, nil
+	}
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return nil, err
+	}
+	return nil, send(device.ModeQuery())
+}
+
+// RestoreDeviceMode records the messages that take a device out of programmer mode
+func (m *Manager) RestoreDeviceMode(outPortName string, deviceType midi.DeviceType, mode []byte) error {
+	device, ok := midi.GetDevice(deviceType).(midi.ModeRestorer)
+	if !ok {
+		return nil
+	}
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return device.RestoreMode(send, mode)
+}
+
+// WaitForMessage blocks until Inject delivers a message for which match returns true
+// on the input port, or ctx ends
+func (m *Manager) WaitForMessage(ctx context.Context, inPortName string, match func(gomidi.Message) bool) (gomidi.Message, error) {
+	got := make(chan gomidi.Message, 1)
+	m.mu.Lock()
+	if !slices.Contains(m.inPorts, inPortName) {
+		m.mu.Unlock()
+		return nil, midi.NewPortNotFoundError("input", inPortName, m.inPorts)
+	}
+	id := m.register()
+	m.waits[id] = genericWait{port: inPortName, deliver: func(msg gomidi.Message) {
+		if match(msg) {
+			select {
+			case got <- msg:
+			default:
+			}
+		}
+	}}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.waits, id)
+	}()
+
+	select {
+	case msg := <-got:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SetPadColor records the messages that light one pad
+func (m *Manager) SetPadColor(outPortName string, deviceType midi.DeviceType, row, col int, color midi.PadColor) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.GetDevice(deviceType).SetPadColor(send, row, col, color)
+}
+
+// SetPadColors records the messages that light several pads, batched as the real
+// manager sends them
+func (m *Manager) SetPadColors(outPortName string, deviceType midi.DeviceType, updates []midi.PadUpdate) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.WritePads(midi.GetDevice(deviceType), send, updates)
+}
+
+// SetPadColorsPriority records the messages that light several pads, batched as the
+// real manager sends them; nothing is queued here
+func (m *Manager) SetPadColorsPriority(outPortName string, deviceType midi.DeviceType, updates []midi.PadUpdate) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.WritePads(midi.GetDevice(deviceType), send, updates)
+}
+
+// SetOuterPads records the messages that light the pads around the grid
+func (m *Manager) SetOuterPads(outPortName string, deviceType midi.DeviceType, outer midi.OuterPads) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.WriteOuterPads(midi.GetDevice(deviceType), send, outer)
+}
+
+// SendGrid records the messages that light the whole grid, in the order the real manager sends them
+func (m *Manager) SendGrid(outPortName string, deviceType midi.DeviceType, colors [9][9]midi.PadColor, outer midi.OuterPads) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.WriteGrid(midi.GetDevice(deviceType), send, colors, outer)
+}
+
+// ClearAllPads records the messages that turn every pad off
+func (m *Manager) ClearAllPads(outPortName string, deviceType midi.DeviceType) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return midi.GetDevice(deviceType).ClearAllPads(send)
+}
+
+// SendNote records a Note On, or a Note Off when velocity is 0
+func (m *Manager) SendNote(outPortName string, channel, note, velocity uint8) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return send(midi.NoteMessage(channel, note, velocity))
+}
+
+// Send records a message
+func (m *Manager) Send(outPortName string, msg gomidi.Message) error {
+	send, err := m.sender(outPortName)
+	if send == nil {
+		return err
+	}
+	return send(msg)
+}
+
+// SetSendLimit remembers a port's limit, see SendLimit; messages are still recorded at once
+func (m *Manager) SetSendLimit(outPortName string, limit midi.SendLimit) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit.Unlimited() {
+		delete(m.limits, outPortName)
+		return
+	}
+	m.limits[outPortName] = limit
+}
+
+// SendLimit returns the limit last set for a port
+func (m *Manager) SendLimit(outPortName string) midi.SendLimit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limits[outPortName]
+}
+
+// OpenVirtualOut adds name to the output ports, as the real manager lists its virtual port
+func (m *Manager) OpenVirtualOut(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeVirtualOut()
+	m.virtualName = name
+	m.outPorts = append(m.outPorts, name)
+	return nil
+}
+
+// CloseVirtualOut removes the port added by OpenVirtualOut
+func (m *Manager) CloseVirtualOut() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeVirtualOut()
+}
+
+// closeVirtualOut removes the virtual port from the outputs; the caller holds mu
+func (m *Manager) closeVirtualOut() {
+	if m.virtualName != "" {
+		m.outPorts = slices.DeleteFunc(m.outPorts, func(name string) bool { return name == m.virtualName })
+		m.virtualName = ""
+	}
+}
+
+// OpenSimulator adds the simulator's ports like the real manager. Its pad presses are
+// injected and messages sent to it light its pads, besides being recorded.
+func (m *Manager) OpenSimulator() *midi.Simulator {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.simulator != nil {
+		return m.simulator
+	}
+	sim := midi.NewSimulator()
+	m.simulator = sim
+	m.inPorts = append(m.inPorts, midi.SimulatorPortName)
+	m.outPorts = append(m.outPorts, midi.SimulatorPortName)
+	m.stopSimulator, _ = sim.InPort().Listen(func(data []byte, _ int32) {
+		m.Inject(midi.SimulatorPortName, gomidi.Message(data))
+	}, drivers.ListenConfig{})
+	return sim
+}
+
+// CloseSimulator removes the ports added by OpenSimulator
+func (m *Manager) CloseSimulator() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.simulator == nil {
+		return
+	}
+	m.stopSimulator()
+	m.simulator = nil
+	isSimulator := func(name string) bool { return name == midi.SimulatorPortName }
+	m.inPorts = slices.DeleteFunc(m.inPorts, isSimulator)
+	m.outPorts = slices.DeleteFunc(m.outPorts, isSimulator)
+}
+
+// Inject delivers an incoming message on an input port to its listeners, synchronously
+func (m *Manager) Inject(port string, msg gomidi.Message) {
+	m.mu.Lock()
+	var notes []noteListener
+	for _, l := range m.notes {
+		if l.port == port {
+			notes = append(notes, l)
+		}
+	}
+	var generics []genericListener
+	for _, l := range m.generics {
+		if l.port == port {
+			generics = append(generics, l)
+		}
+	}
+	var waits []genericWait
+	for _, w := range m.waits {
+		if w.port == port {
+			waits = append(waits, w)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, w := range waits {
+		w.deliver(msg)
+	}
+
+	for _, l := range notes {
+		if !l.filter.Pass(port, msg) {
+			continue
+		}
+		if row, col, isNoteOn, handled := midi.GetDevice(l.deviceType).HandleMessage(msg); handled {
+			l.callback(port, row, col, isNoteOn, midi.Velocity(msg))
+		}
+	}
+	for _, l := range generics {
+		if msgType, channel, number, value, ok := midi.DecodeGeneric(msg); ok {
+			l.callback(port, msgType, channel, number, value)
+		}
+	}
+}
+
+// Sent returns every message sent so far, in order
+func (m *Manager) Sent() []Sent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.sent)
+}
+
+// SentTo returns the messages sent to one output port, in order
+func (m *Manager) SentTo(port string) []gomidi.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var msgs []gomidi.Message
+	for _, s := range m.sent {
+		if s.Port == port {
+			msgs = append(msgs, s.Msg)
+		}
+	}
+	return msgs
+}
+
+// Reset forgets the messages sent so far
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
+
+// register returns a new listener ID; the caller holds mu
+func (m *Manager) register() int {
+	m.nextID++
+	return m.nextID
+}
+
+// sender returns a function recording messages for a port. Like the real manager an
+// empty port name is a no-op (nil sender, nil error) and an unknown port is an error.
+func (m *Manager) sender(port string) (func(gomidi.Message) error, error) {
+	if port == "" {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.Contains(m.outPorts, port) {
+		return nil, midi.NewPortNotFoundError("output", port, m.outPorts)
+	}
+	return func(msg gomidi.Message) error {
+		m.record(port, msg)
+		return nil
+	}, nil
+}
+
+func (m *Manager) record(port string, msg gomidi.Message) {
+	m.mu.Lock()
+	m.sent = append(m.sent, Sent{Port: port, Msg: slices.Clone(msg)})
+	sim := m.simulator
+	m.mu.Unlock()
+	if sim != nil && port == midi.SimulatorPortName {
+		_ = sim.OutPort().Send(msg)
+	}
+}
+
+// outPort is the drivers.Out handed out by GetOutPort
+type outPort struct {
+	manager *Manager
+	name    string
+	open    bool
+}
+
+func (p *outPort) Open() error             { p.open = true; return nil }
+func (p *outPort) Close() error            { p.open = false; return nil }
+func (p *outPort) IsOpen() bool            { return p.open }
+func (p *outPort) Number() int             { return slices.Index(p.manager.ListOutPorts(), p.name) }
+func (p *outPort) String() string          { return p.name }
+func (p *outPort) Underlying() interface{} { return nil }
+
+func (p *outPort) Send(data []byte) error {
+	if !p.open {
+		return drivers.ErrPortClosed
+	}
+	p.manager.record(p.name, gomidi.Message(data))
+	return nil
+}
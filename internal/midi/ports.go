@@ -0,0 +1,90 @@
+package midi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// Ports is the MIDI access the rest of the app needs. Manager implements it on top
+// of the system driver; miditest.Manager records traffic in memory for tests.
+type Ports interface {
+	ListInPorts() []string
+	ListOutPorts() []string
+	WatchPorts(interval time.Duration, onChange PortsChangedCallback) func()
+	GetOutPort(name string) (drivers.Out, error)
+	StartListening(inPortName string, deviceType DeviceType, filter InputFilter, callback NoteCallback) (func(), error)
+	StartGenericListening(inPortName string, callback GenericMIDICallback) (func(), error)
+	ActivateProgrammerMode(outPortName string, deviceType DeviceType) error
+	QueryDeviceMode(inPortName, outPortName string, deviceType DeviceType, timeout time.Duration) ([]byte, error)
+	RestoreDeviceMode(outPortName string, deviceType DeviceType, mode []byte) error
+	WaitForMessage(ctx context.Context, inPortName string, match func(midi.Message) bool) (midi.Message, error)
+	SetPadColor(outPortName string, deviceType DeviceType, row, col int, color PadColor) error
+	SetPadColors(outPortName string, deviceType DeviceType, updates []PadUpdate) error
+	SetPadColorsPriority(outPortName string, deviceType DeviceType, updates []PadUpdate) error
+	SetOuterPads(outPortName string, deviceType DeviceType, outer OuterPads) error
+	SendGrid(outPortName string, deviceType DeviceType, colors [9][9]PadColor, outer OuterPads) error
+	ClearAllPads(outPortName string, deviceType DeviceType) error
+	SendNote(outPortName string, channel, note, velocity uint8) error
+	Send(outPortName string, msg midi.Message) error
+	SetSendLimit(outPortName string, limit SendLimit)
+	OpenVirtualOut(name string) error
+	CloseVirtualOut()
+	OpenSimulator() *Simulator
+	CloseSimulator()
+}
+
+var _ Ports = (*Manager)(nil)
+
+// ErrPortNotFound is matched by every PortNotFoundError, for errors.Is
+var ErrPortNotFound = errors.New("port not found")
+
+// PortNotFoundError is returned when no MIDI port has the requested name
+type PortNotFoundError struct {
+	Direction  string   // "input" or "output"
+	Name       string   // The name that was requested
+	Candidates []string // Available ports with a similar name, see FindPorts
+}
+
+func (e *PortNotFoundError) Error() string {
+	msg := fmt.Sprintf("%s port not found: %s", e.Direction, e.Name)
+	if len(e.Candidates) > 0 {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", strings.Join(e.Candidates, "', '"))
+	}
+	return msg
+}
+
+func (e *PortNotFoundError) Is(target error) bool {
+	return target == ErrPortNotFound
+}
+
+// NewPortNotFoundError builds the error for a missing port, suggesting similar names from available
+func NewPortNotFoundError(direction, name string, available []string) *PortNotFoundError {
+	return &PortNotFoundError{Direction: direction, Name: name, Candidates: FindPorts(name, available)}
+}
+
+// FindPorts returns the ports whose name matches name loosely: equal ignoring case, or
+// one containing the other. Exact matches come first, so drivers that add or drop
+// suffixes like " MIDI 1" still turn up.
+func FindPorts(name string, ports []string) []string {
+	needle := strings.ToLower(strings.TrimSpace(name))
+	if needle == "" {
+		return nil
+	}
+	var exact, partial []string
+	for _, port := range ports {
+		haystack := strings.ToLower(port)
+		switch {
+		case haystack == needle:
+			exact = append(exact, port)
+		case strings.Contains(haystack, needle) || strings.Contains(needle, haystack):
+			partial = append(partial, port)
+		}
+	}
+	return append(exact, partial...)
+}
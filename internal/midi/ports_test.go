@@ -0,0 +1,78 @@
+package midi
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFindPorts(t *testing.T) {
+	ports := []string{
+		"Launchpad Mini MK3 LPMiniMK3 MIDI",
+		"Launchpad Mini MK3 LPMiniMK3 DAW",
+		"launchpad mini",
+		"IAC Driver Bus 1",
+	}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"IAC Driver Bus 1", []string{"IAC Driver Bus 1"}},
+		{"iac driver bus 1", []string{"IAC Driver Bus 1"}},
+		{"LPMiniMK3 MIDI", []string{"Launchpad Mini MK3 LPMiniMK3 MIDI"}},
+		// Exact (ignoring case) first, then names containing it
+		{"Launchpad Mini", []string{"launchpad mini", "Launchpad Mini MK3 LPMiniMK3 MIDI", "Launchpad Mini MK3 LPMiniMK3 DAW"}},
+		// A configured name with a suffix the driver dropped
+		{"IAC Driver Bus 1 MIDI 1", []string{"IAC Driver Bus 1"}},
+		{"Launchpad X", nil},
+		{"", nil},
+		{"   ", nil},
+	}
+	for _, tt := range tests {
+		if got := FindPorts(tt.name, ports); !slices.Equal(got, tt.want) {
+			t.Errorf("FindPorts(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPortNotFoundError(t *testing.T) {
+	err := error(NewPortNotFoundError("output", "launchpad", []string{"Launchpad Mini MK3", "Synth"}))
+	if want := "output port not found: launchpad (did you mean 'Launchpad Mini MK3'?)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(err, ErrPortNotFound) {
+		t.Error("not errors.Is ErrPortNotFound")
+	}
+	var notFound *PortNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "launchpad" || notFound.Direction != "output" {
+		t.Errorf("errors.As gave %+v", notFound)
+	}
+
+	err = NewPortNotFoundError("input", "Gone", []string{"Synth"})
+	if want := "input port not found: Gone"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestManagerMissingPorts(t *testing.T) {
+	m := NewManager()
+	if port, err := m.GetInPort("No Such Port 1234"); port != nil || !errors.Is(err, ErrPortNotFound) {
+		t.Errorf("GetInPort = %v, %v, want ErrPortNotFound", port, err)
+	}
+	if port, err := m.GetOutPort("No Such Port 1234"); port != nil || !errors.Is(err, ErrPortNotFound) {
+		t.Errorf("GetOutPort = %v, %v, want ErrPortNotFound", port, err)
+	}
+	if err := m.Send("No Such Port 1234", []byte{0x90, 60, 100}); !errors.Is(err, ErrPortNotFound) {
+		t.Errorf("Send = %v, want ErrPortNotFound", err)
+	}
+
+	// The simulator's ports are found by name like any other
+	m.OpenSimulator()
+	defer m.CloseSimulator()
+	if port, err := m.GetInPort(SimulatorPortName); port == nil || err != nil {
+		t.Errorf("GetInPort(%q) = %v, %v", SimulatorPortName, port, err)
+	}
+	if port, err := m.GetOutPort(SimulatorPortName); port == nil || err != nil {
+		t.Errorf("GetOutPort(%q) = %v, %v", SimulatorPortName, port, err)
+	}
+}
@@ -0,0 +1,203 @@
+package midi
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// SendLimit paces the messages written to an output port, for interfaces that drop
+// messages sent too fast. Up to Burst messages go out at once, then Rate per millisecond.
+// The zero value is unlimited.
+type SendLimit struct {
+	Rate  float64 // Messages per millisecond, 0 for unlimited
+	Burst int     // Messages sent without waiting, at least 1
+}
+
+// Unlimited reports whether messages are written as they are sent
+func (l SendLimit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+// queuedMessage is a message waiting to be written with the sender it was sent through
+type queuedMessage struct {
+	send func(midi.Message) error
+	msg  midi.Message
+}
+
+// sendQueue writes the messages for one output port at the port's SendLimit, on its own
+// goroutine. Priority messages go out before everything else waiting.
+type sendQueue struct {
+	port  string
+	limit SendLimit
+
+	mu       sync.Mutex
+	normal   []queuedMessage
+	priority []queuedMessage
+	closed   bool
+	wake     chan struct{} // Signalled when a message is queued or the queue closed
+	done     chan struct{} // Closed once everything queued has been written
+}
+
+// newSendQueue starts a queue for port. With after, nothing is written until after is
+// closed, so a queue replacing another writes after everything the old one still holds.
+func newSendQueue(port string, limit SendLimit, after <-chan struct{}) *sendQueue {
+	q := &sendQueue{
+		port:  port,
+		limit: limit,
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		if after != nil {
+			<-after
+		}
+		q.run()
+	}()
+	return q
+}
+
+// Add queues a message to be written through send
+func (q *sendQueue) Add(send func(midi.Message) error, msg midi.Message, priority bool) {
+	q.mu.Lock()
+	if priority {
+		q.priority = append(q.priority, queuedMessage{send, msg})
+	} else {
+		q.normal = append(q.normal, queuedMessage{send, msg})
+	}
+	q.mu.Unlock()
+	q.signal()
+}
+
+// Close stops the queue once everything queued has been written
+func (q *sendQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *sendQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pending reports whether a message is waiting, and whether the queue is closed
+func (q *sendQueue) pending() (waiting, closed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.priority)+len(q.normal) > 0, q.closed
+}
+
+// next takes the message to write now, priority messages first
+func (q *sendQueue) next() queuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.priority) > 0 {
+		m := q.priority[0]
+		q.priority = q.priority[1:]
+		return m
+	}
+	m := q.normal[0]
+	q.normal = q.normal[1:]
+	return m
+}
+
+// run writes the queued messages as tokens allow. A token is taken before the message
+// is picked, so priority messages queued during the wait still go first.
+func (q *sendQueue) run() {
+	defer close(q.done)
+	burst := float64(max(q.limit.Burst, 1))
+	tokens := burst
+	last := time.Now()
+	for {
+		waiting, closed := q.pending()
+		if !waiting {
+			if closed {
+				return
+			}
+			<-q.wake
+			continue
+		}
+
+		now := time.Now()
+		tokens = min(burst, tokens+float64(now.Sub(last))/float64(time.Millisecond)*q.limit.Rate)
+		last = now
+		if tokens < 1 {
+			time.Sleep(time.Duration((1 - tokens) / q.limit.Rate * float64(time.Millisecond)))
+			continue
+		}
+		tokens--
+
+		m := q.next()
+		if err := m.send(m.msg); err != nil {
+			slog.Warn("Failed to send queued MIDI message", "port", q.port, "err", err)
+		}
+	}
+}
+
+// SetSendLimit sets how fast messages are written to an output port. With a limit, pad
+// colors, grids, notes and Send queue their messages and return before they are written;
+// write errors are logged then. The zero limit writes at once again, after anything
+// still queued, and returns once that has been written. Messages sent while it drains
+// are written at once and may go out ahead of it.
+func (m *Manager) SetSendLimit(outPortName string, limit SendLimit) {
+	m.mu.Lock()
+	old := m.queues[outPortName]
+	if old != nil && old.limit == limit {
+		m.mu.Unlock()
+		return
+	}
+	var after <-chan struct{}
+	if old != nil {
+		old.Close()
+		delete(m.queues, outPortName)
+		after = old.done
+	}
+	if !limit.Unlimited() {
+		if m.queues == nil {
+			m.queues = map[string]*sendQueue{}
+		}
+		m.queues[outPortName] = newSendQueue(outPortName, limit, after)
+		slog.Info("Limiting MIDI output", "port", outPortName, "rate", limit.Rate, "burst", max(limit.Burst, 1))
+	}
+	m.mu.Unlock()
+
+	// Not under m.mu: the old queue's writes may need it, and a slow port would hold
+	// up every other port meanwhile
+	if old != nil {
+		<-old.done
+	}
+}
+
+// closeQueues stops every send queue once it has been written, and waits for that
+// without holding m.mu
+func (m *Manager) closeQueues() {
+	m.mu.Lock()
+	queues := m.queues
+	m.queues = nil
+	for _, q := range queues {
+		q.Close()
+	}
+	m.mu.Unlock()
+	for _, q := range queues {
+		<-q.done
+	}
+}
+
+// queuedSender wraps a port's sender to go through the port's queue, if it has one;
+// the caller holds m.mu
+func (m *Manager) queuedSender(outPortName string, send func(midi.Message) error, priority bool) func(midi.Message) error {
+	q := m.queues[outPortName]
+	if q == nil {
+		return send
+	}
+	return func(msg midi.Message) error {
+		q.Add(send, msg, priority)
+		return nil
+	}
+}
@@ -0,0 +1,200 @@
+package midi
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// recorder collects what a send queue writes, with when it was written
+type recorder struct {
+	mu    sync.Mutex
+	notes []uint8
+	times []time.Time
+}
+
+func (r *recorder) send(msg midi.Message) error {
+	var ch, key, vel uint8
+	msg.GetNoteOn(&ch, &key, &vel)
+	r.mu.Lock()
+	r.notes = append(r.notes, key)
+	r.times = append(r.times, time.Now())
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recorder) written() ([]uint8, []time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]uint8(nil), r.notes...), append([]time.Time(nil), r.times...)
+}
+
+func drain(t *testing.T, q *sendQueue) {
+	t.Helper()
+	q.Close()
+	select {
+	case <-q.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queue did not drain")
+	}
+}
+
+func TestSendQueueKeepsOrder(t *testing.T) {
+	var r recorder
+	q := newSendQueue("out", SendLimit{Rate: 10, Burst: 1}, nil)
+	for key := range uint8(50) {
+		q.Add(r.send, midi.NoteOn(0, key, 100), false)
+	}
+	drain(t, q)
+
+	notes, _ := r.written()
+	if len(notes) != 50 {
+		t.Fatalf("wrote %d messages, want 50", len(notes))
+	}
+	for i, key := range notes {
+		if key != uint8(i) {
+			t.Fatalf("message %d is note %d, order lost: %v", i, key, notes)
+		}
+	}
+}
+
+func TestSendQueuePriorityGoesFirst(t *testing.T) {
+	var r recorder
+	// One message every 20ms, so the later ones are still waiting when the priority one comes
+	q := newSendQueue("out", SendLimit{Rate: 0.05, Burst: 1}, nil)
+	for key := range uint8(4) {
+		q.Add(r.send, midi.NoteOn(0, key, 100), false)
+	}
+	q.Add(r.send, midi.NoteOn(0, 100, 100), true)
+	drain(t, q)
+
+	notes, _ := r.written()
+	if len(notes) != 5 {
+		t.Fatalf("wrote %v, want 5 messages", notes)
+	}
+	// The first may already be out before the priority message was queued
+	if notes[0] != 100 && notes[1] != 100 {
+		t.Errorf("priority message not written ahead of the queue: %v", notes)
+	}
+	var normal []uint8
+	for _, key := range notes {
+		if key != 100 {
+			normal = append(normal, key)
+		}
+	}
+	for i, key := range normal {
+		if key != uint8(i) {
+			t.Errorf("normal messages out of order: %v", notes)
+			break
+		}
+	}
+}
+
+func TestSendQueuePacing(t *testing.T) {
+	var r recorder
+	// 3 at once, then one every 10ms
+	q := newSendQueue("out", SendLimit{Rate: 0.1, Burst: 3}, nil)
+	start := time.Now()
+	for key := range uint8(8) {
+		q.Add(r.send, midi.NoteOn(0, key, 100), false)
+	}
+	drain(t, q)
+
+	_, times := r.written()
+	if len(times) != 8 {
+		t.Fatalf("wrote %d messages, want 8", len(times))
+	}
+	if burst := times[2].Sub(start); burst > 8*time.Millisecond {
+		t.Errorf("burst took %v, want the first 3 at once", burst)
+	}
+	// 5 more at 10ms each; allow for the timer running late, never early
+	if total := times[7].Sub(start); total < 45*time.Millisecond {
+		t.Errorf("8 messages took %v, want at least 50ms less rounding", total)
+	}
+	for i := 4; i < 8; i++ {
+		if gap := times[i].Sub(times[i-1]); gap < 8*time.Millisecond {
+			t.Errorf("message %d followed after %v, want about 10ms", i, gap)
+		}
+	}
+}
+
+func TestSendQueueWaitsForPrevious(t *testing.T) {
+	var r recorder
+	release := make(chan struct{})
+	blocked := func(msg midi.Message) error {
+		<-release
+		return r.send(msg)
+	}
+	old := newSendQueue("out", SendLimit{Rate: 1, Burst: 1}, nil)
+	old.Add(blocked, midi.NoteOn(0, 1, 100), false)
+	old.Close()
+	next := newSendQueue("out", SendLimit{Rate: 1, Burst: 1}, old.done)
+	next.Add(r.send, midi.NoteOn(0, 2, 100), false)
+
+	time.Sleep(20 * time.Millisecond)
+	if notes, _ := r.written(); len(notes) != 0 {
+		t.Fatalf("new queue wrote %v before the old one drained", notes)
+	}
+	close(release)
+	drain(t, next)
+	if notes, _ := r.written(); len(notes) != 2 || notes[0] != 1 || notes[1] != 2 {
+		t.Errorf("wrote %v, want [1 2]", notes)
+	}
+}
+
+func TestSetSendLimitDrainsWithoutLock(t *testing.T) {
+	m := NewManager()
+	var r recorder
+	release := make(chan struct{})
+	blocked := func(msg midi.Message) error {
+		<-release
+		return r.send(msg)
+	}
+	m.SetSendLimit("out", SendLimit{Rate: 1, Burst: 1})
+	m.mu.Lock()
+	queued := m.queuedSender("out", blocked, false)
+	m.mu.Unlock()
+	queued(midi.NoteOn(0, 1, 100))
+
+	returned := make(chan struct{})
+	go func() {
+		m.SetSendLimit("out", SendLimit{})
+		close(returned)
+	}()
+
+	// Other ports stay usable while the queue waits on its stuck write
+	locked := make(chan struct{})
+	go func() {
+		m.mu.Lock()
+		m.mu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatal("SetSendLimit holds the manager lock while the queue drains")
+	}
+	select {
+	case <-returned:
+		t.Fatal("SetSendLimit returned before the queue drained")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SetSendLimit did not return after the queue drained")
+	}
+	if notes, _ := r.written(); len(notes) != 1 {
+		t.Errorf("wrote %v, want the queued note", notes)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.queues) != 0 {
+		t.Errorf("queues left after removing the limit: %v", m.queues)
+	}
+}
@@ -0,0 +1,187 @@
+package midi
+
+import (
+	"sync"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// SimulatorPortName is the name of both ports of the on-screen Launchpad
+const SimulatorPortName = "Virtual Launchpad"
+
+// Simulator is the on-screen Launchpad's end of an in-process port pair. The app
+// listens to its input port and lights its pads through its output port like any other
+// device of type DeviceTypeSimulated; the window presses pads and shows the lights.
+type Simulator struct {
+	mu        sync.Mutex
+	colors    [9][9]PadColor
+	onChange  func(row, col int, color PadColor)
+	nextID    int
+	listeners map[int]func(data []byte, ms int32)
+	started   time.Time
+	presses   chan midi.Message // Delivered in order by the simulator's goroutine
+}
+
+// NewSimulator creates a simulator with every pad off
+func NewSimulator() *Simulator {
+	s := &Simulator{
+		listeners: map[int]func([]byte, int32){},
+		started:   time.Now(),
+		presses:   make(chan midi.Message, 64),
+	}
+	go func() {
+		for msg := range s.presses {
+			s.deliver(msg)
+		}
+	}()
+	return s
+}
+
+// Press sends a pad's press (down) or release to whatever listens to the input port.
+// Like a driver's input it arrives on another goroutine, so Press never waits for
+// the action it triggers, even when called from the UI.
+func (s *Simulator) Press(row, col int, down bool) {
+	msg := midi.NoteOff(0, simulatedPadNote(row, col))
+	if down {
+		msg = midi.NoteOn(0, simulatedPadNote(row, col), 127)
+	}
+	s.presses <- msg
+}
+
+// deliver hands an incoming message to the input port's listeners
+func (s *Simulator) deliver(msg midi.Message) {
+	s.mu.Lock()
+	listeners := make([]func([]byte, int32), 0, len(s.listeners))
+	for _, l := range s.listeners {
+		listeners = append(listeners, l)
+	}
+	ms := int32(time.Since(s.started).Milliseconds())
+	s.mu.Unlock()
+
+	for _, l := range listeners {
+		l(msg.Bytes(), ms)
+	}
+}
+
+// Colors returns what every pad shows
+func (s *Simulator) Colors() [9][9]PadColor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.colors
+}
+
+// OnChange sets the function told about each pad whose light changes. It runs on the
+// goroutine that sent the change.
+func (s *Simulator) OnChange(fn func(row, col int, color PadColor)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// receive applies a message the app sent to the output port; anything but the
+// simulator's SysEx is ignored, as a Launchpad ignores what it doesn't understand
+func (s *Simulator) receive(data []byte) {
+	var sysex []byte
+	if !midi.Message(data).GetSysEx(&sysex) || len(sysex) < 2 || sysex[0] != simulatedManufacturer {
+		return
+	}
+
+	type pad struct{ row, col int }
+	var changed []pad
+	s.mu.Lock()
+	switch sysex[1] {
+	case simulatedSetPad:
+		if len(sysex) < 8 || sysex[2] > 8 || sysex[3] > 8 {
+			break
+		}
+		row, col := int(sysex[2]), int(sysex[3])
+		color := PadColor{R: sysex[4], G: sysex[5], B: sysex[6], Flash: sysex[7] != 0}
+		if s.colors[row][col] != color {
+			s.colors[row][col] = color
+			changed = append(changed, pad{row, col})
+		}
+	case simulatedClear:
+		for row := range 9 {
+			for col := range 9 {
+				if s.colors[row][col] != (PadColor{}) {
+					s.colors[row][col] = PadColor{}
+					changed = append(changed, pad{row, col})
+				}
+			}
+		}
+	}
+	onChange, colors := s.onChange, s.colors
+	s.mu.Unlock()
+
+	if onChange != nil {
+		for _, p := range changed {
+			onChange(p.row, p.col, colors[p.row][p.col])
+		}
+	}
+}
+
+// InPort is the port the app receives the simulator's pad presses from
+func (s *Simulator) InPort() drivers.In {
+	return &simulatorIn{simulatorPort{sim: s}}
+}
+
+// OutPort is the port the app lights the simulator's pads through
+func (s *Simulator) OutPort() drivers.Out {
+	return &simulatorOut{simulatorPort{sim: s}}
+}
+
+// simulatorPort is what the simulator's ports have in common. They are in-process and
+// always open; Number is -1 since no driver lists them.
+type simulatorPort struct {
+	sim *Simulator
+}
+
+func (p *simulatorPort) Open() error             { return nil }
+func (p *simulatorPort) Close() error            { return nil }
+func (p *simulatorPort) IsOpen() bool            { return true }
+func (p *simulatorPort) Number() int             { return -1 }
+func (p *simulatorPort) String() string          { return SimulatorPortName }
+func (p *simulatorPort) Underlying() interface{} { return p.sim }
+
+type simulatorIn struct{ simulatorPort }
+
+type simulatorOut struct{ simulatorPort }
+
+func (p *simulatorIn) Listen(onMsg func(msg []byte, milliseconds int32), _ drivers.ListenConfig) (func(), error) {
+	s := p.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := s.nextID
+	s.listeners[id] = onMsg
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.listeners, id)
+	}, nil
+}
+
+func (p *simulatorOut) Send(data []byte) error {
+	p.sim.receive(data)
+	return nil
+}
+
+// OpenSimulator adds the on-screen Launchpad's ports, both named SimulatorPortName, to
+// the port lists and returns its simulator. Later calls return the same simulator.
+func (m *Manager) OpenSimulator() *Simulator {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.simulator == nil {
+		m.simulator = NewSimulator()
+	}
+	return m.simulator
+}
+
+// CloseSimulator removes the on-screen Launchpad's ports
+func (m *Manager) CloseSimulator() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.simulator = nil
+}
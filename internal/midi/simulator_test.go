@@ -0,0 +1,106 @@
+package midi
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/gomidi/midi/v2"
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// simulatorSend sends through the simulator's output port, as the app does
+func simulatorSend(s *Simulator) func(midi.Message) error {
+	out := s.OutPort()
+	return func(msg midi.Message) error { return out.Send(msg) }
+}
+
+func TestSimulatorLights(t *testing.T) {
+	s := NewSimulator()
+	var changes []PadUpdate
+	s.OnChange(func(row, col int, color PadColor) {
+		changes = append(changes, PadUpdate{Row: row, Col: col, Color: color})
+	})
+	device := &SimulatedDevice{}
+	send := simulatorSend(s)
+
+	red := PadColor{R: 127, Flash: true}
+	if err := device.SetPadColor(send, 8, 0, red); err != nil {
+		t.Fatal(err)
+	}
+	if err := device.SetPadColor(send, 8, 0, red); err != nil { // Unchanged: not reported again
+		t.Fatal(err)
+	}
+	if got := s.Colors()[8][0]; got != red {
+		t.Errorf("pad 8,0 shows %+v, want %+v", got, red)
+	}
+	if len(changes) != 1 || changes[0] != (PadUpdate{Row: 8, Col: 0, Color: red}) {
+		t.Errorf("changes = %+v, want pad 8,0 once", changes)
+	}
+
+	if err := device.SetPadColor(send, 9, 0, red); err == nil {
+		t.Error("a pad off the grid was sent")
+	}
+	// Other devices' messages are ignored, as a Launchpad ignores what it doesn't understand
+	if err := (&ColorfulDevice{}).SetPadColor(send, 0, 0, red); err != nil {
+		t.Fatal(err)
+	}
+	if err := send(midi.NoteOn(0, 1, 127)); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Colors()[0][0]; got != (PadColor{}) {
+		t.Errorf("pad 0,0 lit by a foreign message: %+v", got)
+	}
+
+	changes = nil
+	if err := device.ClearAllPads(send); err != nil {
+		t.Fatal(err)
+	}
+	if s.Colors() != ([9][9]PadColor{}) || len(changes) != 1 {
+		t.Errorf("after clearing: %d changes, colors %+v", len(changes), s.Colors()[8][0])
+	}
+}
+
+func TestSimulatorPresses(t *testing.T) {
+	s := NewSimulator()
+	received := make(chan midi.Message, 4)
+	stop, err := s.InPort().Listen(func(data []byte, _ int32) { received <- midi.Message(data) }, drivers.ListenConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device := &SimulatedDevice{}
+	s.Press(3, 7, true)
+	s.Press(3, 7, false)
+	for _, wantOn := range []bool{true, false} {
+		select {
+		case msg := <-received:
+			row, col, on, handled := device.HandleMessage(msg)
+			if !handled || row != 3 || col != 7 || on != wantOn {
+				t.Errorf("%v read as %d,%d on=%v handled=%v", msg, row, col, on, handled)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("press not delivered")
+		}
+	}
+
+	stop()
+	s.Press(0, 0, true)
+	select {
+	case msg := <-received:
+		t.Errorf("%v delivered after the listener stopped", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSimulatedHandleMessage(t *testing.T) {
+	device := &SimulatedDevice{}
+	if _, _, _, handled := device.HandleMessage(midi.NoteOn(0, 95, 127)); handled {
+		t.Error("note 95 (column 5 of row 9) handled")
+	}
+	if _, _, _, handled := device.HandleMessage(midi.ControlChange(0, 1, 127)); handled {
+		t.Error("a CC handled")
+	}
+	if row, col, on, handled := device.HandleMessage(midi.NoteOn(0, 88, 0)); !handled || on || row != 8 || col != 8 {
+		t.Errorf("NoteOn velocity 0 = %d,%d on=%v handled=%v, want a release of 8,8", row, col, on, handled)
+	}
+}
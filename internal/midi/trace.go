@@ -0,0 +1,34 @@
+package midi
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// traceTraffic enables debug logging of every MIDI message sent and received.
+// It is off by default because pad updates alone produce hundreds of messages.
+var traceTraffic atomic.Bool
+
+// SetTraceTraffic turns MIDI traffic logging on or off
+func SetTraceTraffic(on bool) {
+	traceTraffic.Store(on)
+}
+
+// TraceSender wraps a sender so each message is logged when traffic tracing is on
+func TraceSender(port string, send func(midi.Message) error) func(midi.Message) error {
+	return func(msg midi.Message) error {
+		if traceTraffic.Load() {
+			slog.Debug("MIDI out", "port", port, "msg", msg.String())
+		}
+		return send(msg)
+	}
+}
+
+// traceReceived logs an incoming message when traffic tracing is on
+func traceReceived(port string, msg midi.Message) {
+	if traceTraffic.Load() {
+		slog.Debug("MIDI in", "port", port, "msg", msg.String())
+	}
+}
@@ -1,17 +1,33 @@
 package midi
 
+import "github.com/PixPMusic/gopher-automate/internal/padcolor"
+
 // DeviceType represents the type of device
 type DeviceType string
 
 const (
-	DeviceTypeClassic  DeviceType = "classic"  // Launchpad S - no special programmer mode
-	DeviceTypeColorful DeviceType = "colorful" // Launchpad Mini Mk3 - requires SysEx
-	DeviceTypeGeneric  DeviceType = "generic"  // Generic MIDI for inter-app communication
+	DeviceTypeClassic   DeviceType = "classic"   // Launchpad S - no special programmer mode
+	DeviceTypeColorful  DeviceType = "colorful"  // Launchpad Mini Mk3 - requires SysEx
+	DeviceTypeGeneric   DeviceType = "generic"   // Generic MIDI for inter-app communication
+	DeviceTypeSimulated DeviceType = "simulated" // The on-screen Launchpad, see Simulator
+	DeviceTypePro       DeviceType = "pro"       // Launchpad Pro Mk3 - SysEx, with pads around the grid
 )
 
 // PadColor represents an RGB color for a pad
 type PadColor struct {
 	R, G, B uint8 // 0-127 for each channel
+	Flash   bool  // Blink between the color and off, on devices that can (see Flasher)
+}
+
+// Scaled returns the color with every channel scaled to the given brightness percentage
+func (c PadColor) Scaled(percent int) PadColor {
+	if percent >= 100 {
+		return c
+	}
+	if percent <= 0 {
+		return PadColor{}
+	}
+	return PadColor{R: padcolor.Scale(c.R, percent), G: padcolor.Scale(c.G, percent), B: padcolor.Scale(c.B, percent), Flash: c.Flash}
 }
 
 // PadMapping describes how to address a pad on a specific device
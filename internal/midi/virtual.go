@@ -0,0 +1,96 @@
+package midi
+
+import (
+	"errors"
+	"log/slog"
+	"runtime"
+	"slices"
+
+	"gitlab.com/gomidi/midi/v2/drivers"
+)
+
+// VirtualOutName is the name of the virtual output port other apps receive from
+const VirtualOutName = "GopherAutomate Out"
+
+// ErrVirtualPortsUnsupported is returned where the MIDI system can't create virtual ports
+var ErrVirtualPortsUnsupported = errors.New("virtual MIDI ports are not supported on this platform")
+
+// virtualOpener is implemented by drivers that can create virtual ports (rtmididrv)
+type virtualOpener interface {
+	OpenVirtualOut(name string) (drivers.Out, error)
+}
+
+// VirtualPortsSupported reports whether a virtual output port can be created. The
+// Windows MIDI API has no virtual ports; loopMIDI or similar is needed there.
+func VirtualPortsSupported() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, ok := drivers.Get().(virtualOpener)
+	return ok
+}
+
+// OpenVirtualOut creates an output port other apps see as an input named name. It is
+// listed with the output ports and replaces a virtual port opened before.
+func (m *Manager) OpenVirtualOut(name string) error {
+	if !VirtualPortsSupported() {
+		return ErrVirtualPortsUnsupported
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.virtualName = name
+	return m.reopenVirtualOut()
+}
+
+// CloseVirtualOut removes the virtual output port, if one is open
+func (m *Manager) CloseVirtualOut() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeVirtualOut()
+	m.virtualName = ""
+}
+
+// reopenVirtualOut (re)creates the port named virtualName; the caller holds m.mu
+func (m *Manager) reopenVirtualOut() error {
+	m.closeVirtualOut()
+	out, err := drivers.Get().(virtualOpener).OpenVirtualOut(m.virtualName)
+	if err != nil {
+		return err
+	}
+	m.virtualOut, m.virtualSeen = out, false
+	slog.Info("Opened virtual MIDI output", "port", m.virtualName)
+	return nil
+}
+
+// closeVirtualOut closes the open port but keeps virtualName; the caller holds m.mu
+func (m *Manager) closeVirtualOut() {
+	if m.virtualOut == nil {
+		return
+	}
+	if err := m.virtualOut.Close(); err != nil {
+		slog.Warn("Failed to close virtual MIDI output", "port", m.virtualName, "err", err)
+	}
+	m.virtualOut = nil
+}
+
+// checkVirtualOut recreates the virtual port if it vanished from the system's inputs, as
+// it does when the MIDI service restarts, or if opening it failed before. inPorts is the
+// unfiltered input list; a port that was never listed (some systems hide a client's own
+// ports) is left alone.
+func (m *Manager) checkVirtualOut(inPorts []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.virtualName == "" {
+		return
+	}
+	if m.virtualOut != nil && slices.Contains(inPorts, m.virtualName) {
+		m.virtualSeen = true
+		return
+	}
+	if m.virtualOut == nil || m.virtualSeen {
+		slog.Warn("Virtual MIDI output missing, recreating it", "port", m.virtualName)
+		if err := m.reopenVirtualOut(); err != nil {
+			slog.Error("Failed to recreate virtual MIDI output", "port", m.virtualName, "err", err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package midi
+
+import (
+	"context"
+	"sync"
+
+	"gitlab.com/gomidi/midi/v2"
+)
+
+// waiters hands incoming messages to WaitForMessage calls, by input port. A port takes
+// one listener, so they get messages through the port's regular listener, or through
+// one opened for them while the port has none.
+type waiters struct {
+	mu       sync.Mutex
+	nextID   int
+	byPort   map[string]map[int]func(midi.Message)
+	listened map[string]int    // Regular listeners, by port
+	own      map[string]func() // Stops the listeners opened for waiters, by port
+
+	// open starts a listener on a port that only feeds its waiters
+	open func(port string) (stop func(), err error)
+}
+
+// newWaiters creates waiters that open ports with open
+func newWaiters(open func(port string) (func(), error)) waiters {
+	return waiters{
+		byPort:   map[string]map[int]func(midi.Message){},
+		listened: map[string]int{},
+		own:      map[string]func(){},
+		open:     open,
+	}
+}
+
+// add registers deliver for the messages arriving on port, opening the port if nothing
+// listens to it yet. The returned function unregisters it.
+func (w *waiters) add(port string, deliver func(midi.Message)) (remove func(), err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.listened[port] == 0 && w.own[port] == nil {
+		stop, err := w.open(port)
+		if err != nil {
+			return nil, err
+		}
+		w.own[port] = stop
+	}
+	if w.byPort[port] == nil {
+		w.byPort[port] = map[int]func(midi.Message){}
+	}
+	w.nextID++
+	id := w.nextID
+	w.byPort[port][id] = deliver
+
+	return func() {
+		w.mu.Lock()
+		delete(w.byPort[port], id)
+		var stop func()
+		if len(w.byPort[port]) == 0 {
+			stop = w.own[port]
+			delete(w.own, port)
+		}
+		w.mu.Unlock()
+		if stop != nil {
+			stop() // Outside the lock: stopping waits for deliveries in progress
+		}
+	}, nil
+}
+
+// deliver hands a message that arrived on port to every waiter on it
+func (w *waiters) deliver(port string, msg midi.Message) {
+	w.mu.Lock()
+	var waiting []func(midi.Message)
+	for _, fn := range w.byPort[port] {
+		waiting = append(waiting, fn)
+	}
+	w.mu.Unlock()
+	for _, fn := range waiting {
+		fn(msg)
+	}
+}
+
+// listening is told when a regular listener starts (on) or stops on port. A started
+// one takes over from a listener opened for waiters; when the last one stops, waiters
+// still on the port get a listener of their own again.
+func (w *waiters) listening(port string, on bool) {
+	w.mu.Lock()
+	var stop func()
+	if on {
+		w.listened[port]++
+		stop = w.own[port]
+		delete(w.own, port)
+	} else if w.listened[port]--; w.listened[port] <= 0 {
+		delete(w.listened, port)
+		if len(w.byPort[port]) > 0 {
+			if own, err := w.open(port); err == nil {
+				w.own[port] = own
+			}
+		}
+	}
+	w.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// WaitForMessage blocks until a message for which match returns true arrives on an
+// input port, or ctx ends. Concurrent waits on a port each see every message.
+func (m *Manager) WaitForMessage(ctx context.Context, inPortName string, match func(midi.Message) bool) (midi.Message, error) {
+	got := make(chan midi.Message, 1)
+	remove, err := m.waiters.add(inPortName, func(msg midi.Message) {
+		if match(msg) {
+			select {
+			case got <- msg:
+			default:
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer remove()
+
+	select {
+	case msg := <-got:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// listenForWaiters opens a port only for the waiters on it
+func (m *Manager) listenForWaiters(port string) (func(), error) {
+	inPort, err := m.GetInPort(port)
+	if err != nil {
+		return nil, err
+	}
+	return midi.ListenTo(inPort, func(msg midi.Message, timestampms int32) {
+		defer recoverListener(port)
+		traceReceived(port, msg)
+		m.waiters.deliver(port, msg)
+	}, midi.UseSysEx())
+}
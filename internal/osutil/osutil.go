@@ -0,0 +1,43 @@
+// Package osutil hands files over to the desktop: showing them in the platform's
+// file manager
+package osutil
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// RevealCommand returns the command that shows path in the file manager of the given
+// GOOS. Finder and Explorer open a file's folder with the file selected; elsewhere
+// xdg-open can only open the folder.
+func RevealCommand(goos, path string, isDir bool) (name string, args []string) {
+	switch goos {
+	case "darwin":
+		if isDir {
+			return "open", []string{path}
+		}
+		return "open", []string{"-R", path}
+	case "windows":
+		if isDir {
+			return "explorer", []string{path}
+		}
+		return "explorer", []string{"/select," + path}
+	default:
+		if !isDir {
+			path = filepath.Dir(path)
+		}
+		return "xdg-open", []string{path}
+	}
+}
+
+// Reveal shows a file or folder in the platform's file manager without waiting for it
+func Reveal(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	name, args := RevealCommand(runtime.GOOS, path, info.IsDir())
+	return exec.Command(name, args...).Start()
+}
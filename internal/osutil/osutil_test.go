@@ -0,0 +1,38 @@
+package osutil
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRevealCommand(t *testing.T) {
+	tests := []struct {
+		goos, path string
+		isDir      bool
+		name       string
+		args       []string
+	}{
+		{"darwin", "/Users/me/config.json", false, "open", []string{"-R", "/Users/me/config.json"}},
+		{"darwin", "/Users/me/logs", true, "open", []string{"/Users/me/logs"}},
+		{"windows", `C:\Users\me\config.json`, false, "explorer", []string{`/select,C:\Users\me\config.json`}},
+		{"windows", `C:\Users\me`, true, "explorer", []string{`C:\Users\me`}},
+		// xdg-open can't select a file, so it opens the folder holding it
+		{"linux", "/home/me/.config/app/config.json", false, "xdg-open", []string{"/home/me/.config/app"}},
+		{"linux", "/home/me/.config/app", true, "xdg-open", []string{"/home/me/.config/app"}},
+		{"freebsd", "/home/me/a b/config.json", false, "xdg-open", []string{"/home/me/a b"}},
+	}
+	for _, tt := range tests {
+		name, args := RevealCommand(tt.goos, tt.path, tt.isDir)
+		if name != tt.name || !slices.Equal(args, tt.args) {
+			t.Errorf("RevealCommand(%s, %q, %v) = %s %q, want %s %q", tt.goos, tt.path, tt.isDir, name, args, tt.name, tt.args)
+		}
+	}
+}
+
+func TestRevealMissingPath(t *testing.T) {
+	if err := Reveal(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("Reveal of a missing file = %v, want it not found", err)
+	}
+}
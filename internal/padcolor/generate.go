@@ -0,0 +1,151 @@
+package padcolor
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// MinBrightness is the least a generated color's brightest channel is, so every
+// generated pad is visibly lit on hardware
+const MinBrightness = 48
+
+// RGB is a 0-127 pad color
+type RGB struct {
+	R, G, B uint8
+}
+
+// GenerateMode is how Generate picks colors
+type GenerateMode int
+
+const (
+	// GenerateRandom picks distinct hues at random
+	GenerateRandom GenerateMode = iota
+	// GenerateWheel spaces hues evenly around the color wheel
+	GenerateWheel
+	// GenerateShades runs from a base color down to MinBrightness
+	GenerateShades
+)
+
+// Generate returns n colors picked the given way. The same seed always gives the same
+// colors; only GenerateRandom uses it, and only GenerateShades uses base.
+func Generate(mode GenerateMode, n int, seed uint64, base RGB) []RGB {
+	switch mode {
+	case GenerateWheel:
+		return HueWheel(n)
+	case GenerateShades:
+		return Shades(base, n)
+	default:
+		return RandomHues(n, seed)
+	}
+}
+
+// goldenTurn is the fraction of the wheel between consecutive random hues. Stepping by
+// the golden ratio keeps any run of hues spread out, however many there are.
+const goldenTurn = 0.6180339887498949
+
+// RandomHues returns n colors with distinct hues, starting from a random hue, with
+// saturation and brightness varied a little
+func RandomHues(n int, seed uint64) []RGB {
+	if n <= 0 {
+		return nil
+	}
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	minValue := float64(MinBrightness) / MaxValue
+	hue := rng.Float64()
+	colors := make([]RGB, n)
+	for i := range colors {
+		saturation := 0.75 + rng.Float64()*0.25
+		value := max(0.7+rng.Float64()*0.3, minValue)
+		colors[i] = FromHSV(hue*360, saturation, value)
+		hue = math.Mod(hue+goldenTurn, 1)
+	}
+	return colors
+}
+
+// HueWheel returns n fully saturated colors with hues evenly spaced from red
+func HueWheel(n int) []RGB {
+	if n <= 0 {
+		return nil
+	}
+	colors := make([]RGB, n)
+	for i := range colors {
+		colors[i] = FromHSV(360*float64(i)/float64(n), 1, 1)
+	}
+	return colors
+}
+
+// Shades returns n colors with base's hue and saturation, from base's brightness (or
+// full brightness, for a base too dark to see) evenly down to MinBrightness
+func Shades(base RGB, n int) []RGB {
+	if n <= 0 {
+		return nil
+	}
+	hue, saturation, value := ToHSV(base)
+	minValue := float64(MinBrightness) / MaxValue
+	if value < minValue {
+		value = 1
+	}
+	colors := make([]RGB, n)
+	for i := range colors {
+		v := value
+		if n > 1 {
+			v = value - (value-minValue)*float64(i)/float64(n-1)
+		}
+		colors[i] = FromHSV(hue, saturation, v)
+	}
+	return colors
+}
+
+// FromHSV returns the 0-127 color of a hue in degrees and a saturation and value from 0 to 1
+func FromHSV(hue, saturation, value float64) RGB {
+	hue = math.Mod(math.Mod(hue, 360)+360, 360)
+	saturation = min(max(saturation, 0), 1)
+	value = min(max(value, 0), 1)
+
+	chroma := value * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g = chroma, x
+	case hue < 120:
+		r, g = x, chroma
+	case hue < 180:
+		g, b = chroma, x
+	case hue < 240:
+		g, b = x, chroma
+	case hue < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := value - chroma
+	channel := func(v float64) uint8 { return uint8(math.Round((v + m) * MaxValue)) }
+	return RGB{channel(r), channel(g), channel(b)}
+}
+
+// ToHSV returns a 0-127 color's hue in degrees and its saturation and value from 0 to 1
+func ToHSV(c RGB) (hue, saturation, value float64) {
+	hi, lo := max(c.R, c.G, c.B), min(c.R, c.G, c.B)
+	if hi == 0 {
+		return 0, 0, 0
+	}
+	value = float64(hi) / MaxValue
+	delta := float64(hi - lo)
+	saturation = delta / float64(hi)
+	if delta == 0 {
+		return 0, saturation, value
+	}
+	switch hi {
+	case c.R:
+		hue = 60 * (float64(c.G) - float64(c.B)) / delta
+	case c.G:
+		hue = 60*(float64(c.B)-float64(c.R))/delta + 120
+	default:
+		hue = 60*(float64(c.R)-float64(c.G))/delta + 240
+	}
+	if hue < 0 {
+		hue += 360
+	}
+	return hue, saturation, value
+}
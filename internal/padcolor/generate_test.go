@@ -0,0 +1,99 @@
+package padcolor
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func checkVisible(t *testing.T, name string, colors []RGB) {
+	t.Helper()
+	for i, c := range colors {
+		if hi := max(c.R, c.G, c.B); hi < MinBrightness || hi > MaxValue {
+			t.Errorf("%s color %d = %v, brightest channel outside %d-%d", name, i, c, MinBrightness, MaxValue)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	base := RGB{R: 100, G: 20, B: 60}
+	for _, mode := range []GenerateMode{GenerateRandom, GenerateWheel, GenerateShades} {
+		a, b := Generate(mode, 81, 42, base), Generate(mode, 81, 42, base)
+		if len(a) != 81 || !slices.Equal(a, b) {
+			t.Errorf("mode %d: same seed gave different colors", mode)
+		}
+		checkVisible(t, "generated", a)
+	}
+	if slices.Equal(Generate(GenerateRandom, 9, 1, base), Generate(GenerateRandom, 9, 2, base)) {
+		t.Error("different seeds gave the same random colors")
+	}
+	for _, n := range []int{0, -1} {
+		if got := Generate(GenerateRandom, n, 1, base); got != nil {
+			t.Errorf("Generate(%d pads) = %v, want nil", n, got)
+		}
+	}
+}
+
+func TestRandomHuesAreDistinct(t *testing.T) {
+	colors := RandomHues(9, 7)
+	checkVisible(t, "random", colors)
+	hues := make([]float64, len(colors))
+	for i, c := range colors {
+		hues[i], _, _ = ToHSV(c)
+	}
+	// Nine golden-ratio steps leave every pair of hues well apart
+	for i := range hues {
+		for j := i + 1; j < len(hues); j++ {
+			d := math.Abs(hues[i] - hues[j])
+			if d = min(d, 360-d); d < 15 {
+				t.Errorf("hues %d and %d only %.1f° apart: %v", i, j, d, colors)
+			}
+		}
+	}
+}
+
+func TestHueWheel(t *testing.T) {
+	colors := HueWheel(6)
+	want := []RGB{{127, 0, 0}, {127, 127, 0}, {0, 127, 0}, {0, 127, 127}, {0, 0, 127}, {127, 0, 127}}
+	if !slices.Equal(colors, want) {
+		t.Errorf("HueWheel(6) = %v, want %v", colors, want)
+	}
+	checkVisible(t, "wheel", HueWheel(81))
+}
+
+func TestShades(t *testing.T) {
+	base := RGB{R: 120, G: 60, B: 0}
+	colors := Shades(base, 5)
+	if colors[0] != base {
+		t.Errorf("first shade = %v, want the base %v", colors[0], base)
+	}
+	if hi := max(colors[4].R, colors[4].G, colors[4].B); hi != MinBrightness {
+		t.Errorf("last shade = %v, want brightest channel %d", colors[4], MinBrightness)
+	}
+	baseHue, _, _ := ToHSV(base)
+	for i := 1; i < len(colors); i++ {
+		if colors[i].R >= colors[i-1].R {
+			t.Errorf("shade %d = %v, not darker than %v", i, colors[i], colors[i-1])
+		}
+		if hue, _, _ := ToHSV(colors[i]); math.Abs(hue-baseHue) > 3 {
+			t.Errorf("shade %d hue %.1f, want the base's %.1f", i, hue, baseHue)
+		}
+	}
+	// A base too dark to see starts from full brightness
+	if dark := Shades(RGB{R: 10}, 2); dark[0] != (RGB{R: MaxValue}) {
+		t.Errorf("Shades of a dark base start at %v, want full red", dark[0])
+	}
+	if one := Shades(base, 1); len(one) != 1 || one[0] != base {
+		t.Errorf("Shades(base, 1) = %v, want just the base", one)
+	}
+}
+
+func TestHSVEdges(t *testing.T) {
+	if h, s, v := ToHSV(RGB{}); h != 0 || s != 0 || v != 0 {
+		t.Errorf("ToHSV(black) = %v, %v, %v, want zeros", h, s, v)
+	}
+	// Hues wrap around and out-of-range amounts are clamped
+	if FromHSV(-120, 2, 2) != FromHSV(240, 1, 1) {
+		t.Error("FromHSV does not wrap hues or clamp saturation and value")
+	}
+}
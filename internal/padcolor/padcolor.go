@@ -0,0 +1,156 @@
+// Package padcolor converts pad colors between the 0-127 channel values stored in the
+// config and sent to devices, the 0-255 values shown on screen, and the four brightness
+// levels (0-3) per LED of classic red/green Launchpads.
+//
+// Every conversion lives here so the editor preview, the stored config and the bytes on
+// the wire agree: a stored classic value always comes from LevelValue, and Level maps it
+// back to the same level the device is sent.
+package padcolor
+
+import "image/color"
+
+// MaxValue is the largest channel value, the top of the MIDI data byte range
+const MaxValue = 127
+
+// MaxLevel is the brightest level of a classic device LED
+const MaxLevel = 3
+
+// levelValues are the channel values stored for each classic level, a third of MaxValue apart
+var levelValues = [MaxLevel + 1]uint8{0, 42, 85, MaxValue}
+
+// ToDisplay scales a 0-127 channel value to 0-255 for the screen, so 127 is full brightness
+func ToDisplay(value uint8) uint8 {
+	value = min(value, MaxValue)
+	return uint8((int(value)*255 + MaxValue/2) / MaxValue)
+}
+
+// FromDisplay scales a 0-255 screen channel value to 0-127, the inverse of ToDisplay
+func FromDisplay(value uint8) uint8 {
+	return uint8((int(value)*MaxValue + 127) / 255)
+}
+
+// RGBA returns the opaque screen color for a 0-127 RGB pad color
+func RGBA(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: ToDisplay(r), G: ToDisplay(g), B: ToDisplay(b), A: 255}
+}
+
+// Gamma is the curve colorful devices get channel values through before they are sent,
+// squaring the value so colors look more distinct. Nonzero values stay nonzero.
+func Gamma(value uint8) uint8 {
+	if value == 0 {
+		return 0
+	}
+	f := float64(min(value, MaxValue)) / MaxValue
+	return max(uint8(f*f*MaxValue), 1)
+}
+
+// Scale dims a 0-127 channel value to a brightness percentage, as pads are sent to
+// devices set below full brightness
+func Scale(value uint8, percent int) uint8 {
+	return uint8(int(value) * min(max(percent, 0), 100) / 100)
+}
+
+// SentBrightness is how bright a 0-127 RGB color lights a colorful device at a
+// brightness percentage: its brightest channel, scaled and through Gamma
+func SentBrightness(r, g, b uint8, percent int) uint8 {
+	return Gamma(Scale(max(r, g, b), percent))
+}
+
+// Distance is how different two 0-127 RGB colors are: the largest difference of a channel
+func Distance(a, b RGB) int {
+	diff := func(x, y uint8) int { return max(int(x)-int(y), int(y)-int(x)) }
+	return max(diff(a.R, b.R), diff(a.G, b.G), diff(a.B, b.B))
+}
+
+// Level quantizes a 0-127 channel value to a classic LED level (0-3), in equal quarters
+func Level(value uint8) uint8 {
+	return min(value, MaxValue) / 32
+}
+
+// LevelValue returns the 0-127 channel value stored for a classic level; Level maps it back
+func LevelValue(level uint8) uint8 {
+	return levelValues[min(level, MaxLevel)]
+}
+
+// LevelRGBA returns the opaque screen color of a classic pad lit at the given red and green levels
+func LevelRGBA(red, green uint8) color.RGBA {
+	return RGBA(LevelValue(red), LevelValue(green), 0)
+}
+
+// ClassicLevels approximates a 0-127 RGB color on a classic red/green device, returning
+// the red and green LED levels. Blue leans toward green, since that's closer on the spectrum.
+func ClassicLevels(r, g, b uint8) (red, green uint8) {
+	effectiveR := min(int(r)+int(b)/4, MaxValue)
+	effectiveG := min(int(g)+int(b)*3/4, MaxValue)
+	return Level(uint8(effectiveR)), Level(uint8(effectiveG))
+}
+
+// Names are the color names Name returns
+var Names = []string{"off", "white", "gray", "red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink"}
+
+// Name returns a rough name for a 0-127 RGB pad color, one of Names, for describing
+// pads in words
+func Name(r, g, b uint8) string {
+	hi := max(r, g, b)
+	lo := min(r, g, b)
+	switch {
+	case hi < 8:
+		return "off"
+	case float64(hi-lo)/float64(hi) < 0.25:
+		if hi > 80 {
+			return "white"
+		}
+		return "gray"
+	}
+
+	// Hue in degrees, as in HSV
+	var hue float64
+	delta := float64(hi - lo)
+	switch hi {
+	case r:
+		hue = 60 * (float64(g) - float64(b)) / delta
+	case g:
+		hue = 60*(float64(b)-float64(r))/delta + 120
+	default:
+		hue = 60*(float64(r)-float64(g))/delta + 240
+	}
+	if hue < 0 {
+		hue += 360
+	}
+	switch {
+	case hue < 15 || hue >= 345:
+		return "red"
+	case hue < 45:
+		return "orange"
+	case hue < 70:
+		return "yellow"
+	case hue < 160:
+		return "green"
+	case hue < 200:
+		return "cyan"
+	case hue < 260:
+		return "blue"
+	case hue < 290:
+		return "purple"
+	default:
+		return "pink"
+	}
+}
+
+// HueCodes are the codes HueCode returns for lit colors: red, yellow, green, cyan,
+// blue, magenta and white
+var HueCodes = []string{"R", "Y", "G", "C", "B", "M", "W"}
+
+// HueCode returns a one-letter code for a 0-127 RGB pad color's hue, one of HueCodes,
+// or "" for off. Each code covers 60 degrees around its hue, so colors that look alike
+// to someone who can't tell red from green still get different codes.
+func HueCode(r, g, b uint8) string {
+	hue, saturation, _ := ToHSV(RGB{r, g, b})
+	switch {
+	case max(r, g, b) < 8: // As dark as Name's off
+		return ""
+	case saturation < 0.25:
+		return "W"
+	}
+	return HueCodes[int(hue+30)%360/60]
+}
@@ -0,0 +1,166 @@
+package padcolor
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestToDisplay(t *testing.T) {
+	if ToDisplay(0) != 0 || ToDisplay(MaxValue) != 255 || ToDisplay(255) != 255 {
+		t.Errorf("ToDisplay ends = %d, %d, %d, want 0, 255, 255", ToDisplay(0), ToDisplay(MaxValue), ToDisplay(255))
+	}
+	// Every stored value shows as its own, brighter, screen value
+	for v := 1; v <= MaxValue; v++ {
+		if ToDisplay(uint8(v)) <= ToDisplay(uint8(v-1)) {
+			t.Errorf("ToDisplay(%d) = %d, not above ToDisplay(%d) = %d", v, ToDisplay(uint8(v)), v-1, ToDisplay(uint8(v-1)))
+		}
+	}
+	if got, want := RGBA(127, 64, 0), (color.RGBA{255, 129, 0, 255}); got != want {
+		t.Errorf("RGBA(127, 64, 0) = %v, want %v", got, want)
+	}
+}
+
+func TestFromDisplay(t *testing.T) {
+	for v := 0; v <= MaxValue; v++ {
+		if got := FromDisplay(ToDisplay(uint8(v))); got != uint8(v) {
+			t.Errorf("FromDisplay(ToDisplay(%d)) = %d", v, got)
+		}
+	}
+	if FromDisplay(255) != MaxValue || FromDisplay(128) != 64 {
+		t.Errorf("FromDisplay(255), FromDisplay(128) = %d, %d, want 127, 64", FromDisplay(255), FromDisplay(128))
+	}
+}
+
+func TestLevelRoundTrip(t *testing.T) {
+	for level := uint8(0); level <= MaxLevel; level++ {
+		if got := Level(LevelValue(level)); got != level {
+			t.Errorf("Level(LevelValue(%d)) = %d", level, got)
+		}
+		if got, want := LevelRGBA(level, level), RGBA(LevelValue(level), LevelValue(level), 0); got != want {
+			t.Errorf("LevelRGBA(%d, %d) = %v, want %v", level, level, got, want)
+		}
+	}
+	// Stored values are quantized again to the level they came from, whatever is stored
+	for v := 0; v <= 255; v++ {
+		level := Level(uint8(v))
+		if level > MaxLevel {
+			t.Fatalf("Level(%d) = %d, above MaxLevel", v, level)
+		}
+		if again := Level(LevelValue(level)); again != level {
+			t.Errorf("value %d: level %d stores as %d, which is level %d", v, level, LevelValue(level), again)
+		}
+	}
+	if LevelValue(MaxLevel+1) != MaxValue {
+		t.Errorf("LevelValue(%d) = %d, want it clamped to %d", MaxLevel+1, LevelValue(MaxLevel+1), MaxValue)
+	}
+}
+
+func TestLevelBoundaries(t *testing.T) {
+	tests := []struct {
+		value, level uint8
+	}{
+		{0, 0}, {31, 0}, {32, 1}, {42, 1}, {63, 1}, {64, 2}, {85, 2}, {95, 2}, {96, 3}, {127, 3}, {200, 3},
+	}
+	for _, tt := range tests {
+		if got := Level(tt.value); got != tt.level {
+			t.Errorf("Level(%d) = %d, want %d", tt.value, got, tt.level)
+		}
+	}
+}
+
+func TestClassicLevels(t *testing.T) {
+	tests := []struct {
+		name       string
+		r, g, b    uint8
+		red, green uint8
+	}{
+		{"off", 0, 0, 0, 0, 0},
+		{"red", 127, 0, 0, 3, 0},
+		{"green", 0, 127, 0, 0, 3},
+		{"amber", 127, 127, 0, 3, 3},
+		{"blue leans green", 0, 0, 127, 0, 2},
+		{"purple", 64, 0, 127, 2, 2},
+		{"too dim to light", 20, 20, 0, 0, 0},
+		{"each level value", 42, 85, 0, 1, 2},
+	}
+	for _, tt := range tests {
+		red, green := ClassicLevels(tt.r, tt.g, tt.b)
+		if red != tt.red || green != tt.green {
+			t.Errorf("%s: ClassicLevels(%d, %d, %d) = %d, %d, want %d, %d", tt.name, tt.r, tt.g, tt.b, red, green, tt.red, tt.green)
+		}
+	}
+	// A classic color stored from levels reads back as the same levels
+	for red := uint8(0); red <= MaxLevel; red++ {
+		for green := uint8(0); green <= MaxLevel; green++ {
+			r, g := ClassicLevels(LevelValue(red), LevelValue(green), 0)
+			if r != red || g != green {
+				t.Errorf("levels %d, %d stored and read back as %d, %d", red, green, r, g)
+			}
+		}
+	}
+}
+
+func TestGammaAndScale(t *testing.T) {
+	if Gamma(0) != 0 || Gamma(MaxValue) != MaxValue || Gamma(255) != MaxValue {
+		t.Errorf("Gamma ends = %d, %d, %d, want 0, 127, 127", Gamma(0), Gamma(MaxValue), Gamma(255))
+	}
+	for v := 1; v <= MaxValue; v++ {
+		g := Gamma(uint8(v))
+		if g == 0 {
+			t.Errorf("Gamma(%d) = 0, a lit color went dark", v)
+		}
+		if g < Gamma(uint8(v-1)) {
+			t.Errorf("Gamma(%d) = %d, below Gamma(%d)", v, g, v-1)
+		}
+	}
+
+	tests := []struct {
+		value   uint8
+		percent int
+		want    uint8
+	}{
+		{127, 100, 127}, {127, 50, 63}, {127, 0, 0}, {100, 150, 100}, {100, -5, 0},
+	}
+	for _, tt := range tests {
+		if got := Scale(tt.value, tt.percent); got != tt.want {
+			t.Errorf("Scale(%d, %d) = %d, want %d", tt.value, tt.percent, got, tt.want)
+		}
+	}
+	if got := SentBrightness(10, 127, 30, 50); got != Gamma(63) {
+		t.Errorf("SentBrightness = %d, want %d", got, Gamma(63))
+	}
+}
+
+func TestHSVRoundTrip(t *testing.T) {
+	for _, c := range []RGB{{127, 0, 0}, {0, 127, 0}, {0, 0, 127}, {127, 127, 127}, {100, 50, 20}, {3, 90, 64}} {
+		h, s, v := ToHSV(c)
+		if got := FromHSV(h, s, v); Distance(got, c) > 1 {
+			t.Errorf("%v went through HSV as %v", c, got)
+		}
+	}
+}
+
+func TestNameAndHueCode(t *testing.T) {
+	tests := []struct {
+		c          RGB
+		name, code string
+	}{
+		{RGB{0, 0, 0}, "off", ""},
+		{RGB{127, 127, 127}, "white", "W"},
+		{RGB{40, 40, 40}, "gray", "W"},
+		{RGB{127, 0, 0}, "red", "R"},
+		{RGB{127, 127, 0}, "yellow", "Y"},
+		{RGB{0, 127, 0}, "green", "G"},
+		{RGB{0, 127, 127}, "cyan", "C"},
+		{RGB{0, 0, 127}, "blue", "B"},
+		{RGB{127, 0, 127}, "pink", "M"},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.c.R, tt.c.G, tt.c.B); got != tt.name {
+			t.Errorf("Name(%v) = %q, want %q", tt.c, got, tt.name)
+		}
+		if got := HueCode(tt.c.R, tt.c.G, tt.c.B); got != tt.code {
+			t.Errorf("HueCode(%v) = %q, want %q", tt.c, got, tt.code)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package profile moves the whole configuration between machines as a zip archive
+// holding the config and a manifest. Actions keep their scripts inline, so the
+// config is everything a profile needs, less the settings that belong to one machine.
+package profile
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// Names of the files inside a profile archive
+const (
+	manifestName = "manifest.json"
+	configName   = "config.json"
+)
+
+// FileExtension is the extension given to exported profiles
+const FileExtension = ".zip"
+
+// Manifest describes an exported profile
+type Manifest struct {
+	AppVersion    string    `json:"app_version"`
+	SchemaVersion int       `json:"schema_version"` // config.SchemaVersion of the exporting app
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// Export writes cfg, without its machine-local settings, and a manifest to w as a
+// profile archive
+func Export(w io.Writer, cfg *config.Config) error {
+	archive := zip.NewWriter(w)
+
+	manifest := Manifest{AppVersion: appVersion(), SchemaVersion: config.SchemaVersion, ExportedAt: time.Now()}
+	if err := writeJSON(archive, manifestName, manifest); err != nil {
+		return err
+	}
+	portable := *cfg
+	clearLocal(&portable)
+	if err := writeJSON(archive, configName, &portable); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// Import reads a profile archive written by Export. The config goes through the same
+// upgrade steps as one loaded from disk; IDs are kept as they are.
+func Import(r io.ReaderAt, size int64) (*config.Config, Manifest, error) {
+	var manifest Manifest
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, manifest, fmt.Errorf("not a profile archive: %w", err)
+	}
+
+	data, err := readFile(archive, manifestName)
+	if err != nil {
+		return nil, manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, manifest, fmt.Errorf("invalid %s: %w", manifestName, err)
+	}
+	if manifest.SchemaVersion > config.SchemaVersion {
+		return nil, manifest, fmt.Errorf("profile was exported by a newer version (%s, format %d); update GopherAutomate to import it",
+			manifest.AppVersion, manifest.SchemaVersion)
+	}
+
+	data, err = readFile(archive, configName)
+	if err != nil {
+		return nil, manifest, err
+	}
+	cfg, err := config.Parse(data)
+	if err != nil {
+		return nil, manifest, fmt.Errorf("invalid %s: %w", configName, err)
+	}
+	return cfg, manifest, nil
+}
+
+// KeepLocal gives an imported config the machine-local settings of local, the config it
+// replaces: the login item, the HTTP API with its token, the virtual port and the
+// ignored ports. Profiles don't carry them, so without this they'd be reset.
+func KeepLocal(imported, local *config.Config) {
+	imported.OpenAtStartup = local.OpenAtStartup
+	imported.StartupArgs = slices.Clone(local.StartupArgs)
+	imported.IgnoredStartupCommand = slices.Clone(local.IgnoredStartupCommand)
+	imported.HTTPAPI = local.HTTPAPI
+	imported.VirtualOutPort = local.VirtualOutPort
+	imported.IgnoredPorts = slices.Clone(local.IgnoredPorts)
+	imported.Revision = local.Revision
+}
+
+// clearLocal resets the settings KeepLocal carries over to what a new config has
+func clearLocal(cfg *config.Config) {
+	cfg.OpenAtStartup = false
+	cfg.StartupArgs = nil
+	cfg.IgnoredStartupCommand = nil
+	cfg.HTTPAPI = config.NewHTTPAPIConfig()
+	cfg.VirtualOutPort = false
+	cfg.IgnoredPorts = nil
+	cfg.Revision = 0
+}
+
+// MissingPort is a device port named in an imported profile that this machine doesn't have
+type MissingPort struct {
+	DeviceID   string
+	DeviceName string
+	Input      bool   // true for the input port, false for the output port
+	Port       string // Name in the profile
+	Pattern    string // The device's pattern for the port, see config.ResolvePort
+}
+
+// MissingPorts lists the ports of enabled devices that aren't among the available ones
+func MissingPorts(cfg *config.Config, inPorts, outPorts []string) []MissingPort {
+	var missing []MissingPort
+	for _, device := range cfg.Devices {
+		if device.Disabled {
+			continue
+		}
+		if device.InPort != "" && !slices.Contains(inPorts, device.InPort) {
+			missing = append(missing, MissingPort{DeviceID: device.ID, DeviceName: device.Name, Input: true, Port: device.InPort, Pattern: device.InPortMatch})
+		}
+		if device.OutPort != "" && !slices.Contains(outPorts, device.OutPort) {
+			missing = append(missing, MissingPort{DeviceID: device.ID, DeviceName: device.Name, Port: device.OutPort, Pattern: device.OutPortMatch})
+		}
+	}
+	return missing
+}
+
+// writeJSON adds a file holding v as indented JSON to the archive
+func writeJSON(archive *zip.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+// readFile returns the contents of a file in the archive
+func readFile(archive *zip.Reader, name string) ([]byte, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("profile archive has no %s: %w", name, err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// appVersion returns the module version the binary was built from, "(devel)" for local builds
+func appVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return "unknown"
+}
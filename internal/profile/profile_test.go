@@ -0,0 +1,218 @@
+package profile
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// testConfig is a config using every part a profile carries, and machine-local settings
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device := config.NewDeviceConfig()
+	device.ID, device.Name, device.Type = "lpx", "Launchpad X", config.DeviceTypeColorful
+	device.InPort, device.OutPort, device.MainMenu = "LPX In", "LPX Out", "Main"
+	cfg.Devices = []config.DeviceConfig{device}
+
+	menu := config.NewMenuLayout()
+	menu.ID, menu.Name = "main", "Main"
+	menu.Colors[2][3] = config.PadColorConfig{R: 127, PressedB: 64, ActionID: "hello"}
+	cfg.Menus = []config.MenuLayout{menu}
+	cfg.CurrentMenuID = menu.ID
+
+	cfg.ActionGroups = []actions.ActionGroup{{ID: "group", Name: "Group"}}
+	cfg.Actions = []actions.Action{{ID: "hello", Name: "Hello", Type: actions.ActionTypeShellCommand, Code: "echo hello", ParentGroupID: "group"}}
+	mapping := config.NewMessageMapping()
+	mapping.ID, mapping.ActionID = "mapping", "hello"
+	cfg.MessageMappings = []config.MessageMapping{mapping}
+	cfg.PadDebounceMs = 120
+	cfg.Language = "de"
+
+	// Machine-local
+	cfg.OpenAtStartup = true
+	cfg.StartupArgs = []string{"--headless"}
+	cfg.IgnoredStartupCommand = []string{"/old/gopher-automate"}
+	cfg.HTTPAPI = config.HTTPAPIConfig{Enabled: true, BindAddress: "0.0.0.0", Port: 9000, Token: "secret-token"}
+	cfg.VirtualOutPort = true
+	cfg.IgnoredPorts = []string{"Midi Through"}
+	cfg.Revision = 42
+	return cfg
+}
+
+// roundTrip exports cfg and imports the archive again
+func roundTrip(t *testing.T, cfg *config.Config) (*config.Config, []byte) {
+	t.Helper()
+	var archive bytes.Buffer
+	if err := Export(&archive, cfg); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	imported, manifest, err := Import(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if manifest.SchemaVersion != config.SchemaVersion {
+		t.Errorf("manifest schema = %d, want %d", manifest.SchemaVersion, config.SchemaVersion)
+	}
+	return imported, archive.Bytes()
+}
+
+// marshal returns cfg as saved
+func marshal(t *testing.T, cfg *config.Config) string {
+	t.Helper()
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRoundTrip(t *testing.T) {
+	cfg := testConfig(t)
+	imported, _ := roundTrip(t, cfg)
+
+	// Once this machine's settings are put back, nothing differs
+	KeepLocal(imported, cfg)
+	if got, want := marshal(t, imported), marshal(t, cfg); got != want {
+		t.Errorf("reimported profile differs:\n%s\nwant:\n%s", got, want)
+	}
+
+	// And a second trip changes nothing either
+	again, _ := roundTrip(t, imported)
+	KeepLocal(again, cfg)
+	if got, want := marshal(t, again), marshal(t, cfg); got != want {
+		t.Errorf("second round trip differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportLeavesOutLocalSettings(t *testing.T) {
+	cfg := testConfig(t)
+	imported, archive := roundTrip(t, cfg)
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range reader.File {
+		f, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(f)
+		f.Close()
+		if strings.Contains(string(data), "secret-token") {
+			t.Errorf("%s holds the HTTP API token", file.Name)
+		}
+	}
+
+	if imported.HTTPAPI != config.NewHTTPAPIConfig() {
+		t.Errorf("imported HTTP API = %+v, want the defaults", imported.HTTPAPI)
+	}
+	if imported.OpenAtStartup || imported.StartupArgs != nil || imported.IgnoredStartupCommand != nil {
+		t.Errorf("imported login item = %v %v %v, want none", imported.OpenAtStartup, imported.StartupArgs, imported.IgnoredStartupCommand)
+	}
+	if imported.VirtualOutPort || imported.IgnoredPorts != nil || imported.Revision != 0 {
+		t.Errorf("imported virtual port %v, ignored ports %v, revision %d; want none", imported.VirtualOutPort, imported.IgnoredPorts, imported.Revision)
+	}
+
+	// Exporting doesn't touch the config it was given
+	if cfg.HTTPAPI.Token != "secret-token" || !cfg.OpenAtStartup || cfg.Revision != 42 {
+		t.Error("Export changed the exported config")
+	}
+}
+
+// TestKeepLocal checks that importing another machine's profile keeps this one's settings
+func TestKeepLocal(t *testing.T) {
+	other := testConfig(t)
+	other.Actions[0].Code = "echo other"
+	imported, _ := roundTrip(t, other)
+
+	local, err := config.Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local.HTTPAPI = config.HTTPAPIConfig{Enabled: true, BindAddress: "127.0.0.1", Port: 8123, Token: "local-token"}
+	local.StartupArgs = []string{"--minimized"}
+	local.IgnoredPorts = []string{"Local Port"}
+	local.Revision = 7
+
+	KeepLocal(imported, local)
+	if imported.HTTPAPI != local.HTTPAPI {
+		t.Errorf("HTTP API = %+v, want this machine's %+v", imported.HTTPAPI, local.HTTPAPI)
+	}
+	if strings.Join(imported.StartupArgs, " ") != "--minimized" || strings.Join(imported.IgnoredPorts, " ") != "Local Port" || imported.Revision != 7 {
+		t.Errorf("startup args %v, ignored ports %v, revision %d; want this machine's", imported.StartupArgs, imported.IgnoredPorts, imported.Revision)
+	}
+	if imported.Actions[0].Code != "echo other" {
+		t.Errorf("action code = %q, want the profile's", imported.Actions[0].Code)
+	}
+
+	// The lists are copies
+	local.StartupArgs[0] = "changed"
+	if imported.StartupArgs[0] != "--minimized" {
+		t.Error("KeepLocal shares StartupArgs with the local config")
+	}
+}
+
+func TestImportRejects(t *testing.T) {
+	newer := func() []byte {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		_ = writeJSON(archive, manifestName, Manifest{AppVersion: "v99", SchemaVersion: config.SchemaVersion + 1})
+		_ = writeJSON(archive, configName, map[string]any{})
+		_ = archive.Close()
+		return buf.Bytes()
+	}
+	noConfig := func() []byte {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		_ = writeJSON(archive, manifestName, Manifest{SchemaVersion: config.SchemaVersion})
+		_ = archive.Close()
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not a zip", []byte("config"), "not a profile archive"},
+		{"newer schema", newer(), "newer version (v99"},
+		{"no config", noConfig(), "no config.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Import(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Import error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingPorts(t *testing.T) {
+	cfg := testConfig(t)
+	disabled := cfg.Devices[0]
+	disabled.ID, disabled.Disabled, disabled.InPort = "off", true, "Gone In"
+	cfg.Devices = append(cfg.Devices, disabled)
+
+	missing := MissingPorts(cfg, []string{"LPX In"}, []string{"Other Out"})
+	if len(missing) != 1 {
+		t.Fatalf("missing = %+v, want the output port of lpx", missing)
+	}
+	if m := missing[0]; m.DeviceID != "lpx" || m.Input || m.Port != "LPX Out" {
+		t.Errorf("missing = %+v, want lpx's output LPX Out", m)
+	}
+	if got := MissingPorts(cfg, []string{"LPX In"}, []string{"LPX Out"}); len(got) != 0 {
+		t.Errorf("missing = %+v with every port present", got)
+	}
+}
@@ -1,194 +1,70 @@
 package startup
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
-	"strings"
+	"slices"
 )
 
-// Enable registers the application to launch at system startup
-func Enable() error {
-	switch runtime.GOOS {
-	case "darwin":
-		return enableMacOS()
-	case "linux":
-		return enableLinux()
-	case "windows":
-		return enableWindows()
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+// AutostartFlag is added to the registered command so a copy launched at login while
+// the app is already running exits quietly instead of bringing the window forward
+const AutostartFlag = "--autostart"
+
+// Enable registers the application to launch at system startup with the given extra arguments
+func Enable(args []string) error {
+	command, err := Command(args)
+	if err != nil {
+		return err
 	}
+	return register(command)
 }
 
 // Disable removes the application from system startup
 func Disable() error {
-	switch runtime.GOOS {
-	case "darwin":
-		return disableMacOS()
-	case "linux":
-		return disableLinux()
-	case "windows":
-		return disableWindows()
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
+	return unregister()
 }
 
 // IsEnabled checks if the application is registered for startup
 func IsEnabled() bool {
-	switch runtime.GOOS {
-	case "darwin":
-		return isEnabledMacOS()
-	case "linux":
-		return isEnabledLinux()
-	case "windows":
-		return isEnabledWindows()
-	default:
-		return false
-	}
+	_, ok := registered()
+	return ok
 }
 
-// --- macOS Implementation ---
-
-const macOSPlistName = "com.gopher-automate.plist"
-
-func macOSPlistPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, "Library", "LaunchAgents", macOSPlistName)
-}
-
-func enableMacOS() error {
-	execPath, err := os.Executable()
-	if err != nil {
-		return err
+// Verify compares the registration with the command Enable would register now. When
+// they differ, e.g. after the app was moved or updated to a new path, it returns the
+// registered command and an error saying how; Enable repairs it. It returns nothing
+// when the app isn't registered.
+func Verify(args []string) ([]string, error) {
+	registeredCommand, ok := registered()
+	if !ok {
+		return nil, nil
 	}
-
-	plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
-<plist version="1.0">
-<dict>
-    <key>Label</key>
-    <string>com.gopher-automate</string>
-    <key>ProgramArguments</key>
-    <array>
-        <string>%s</string>
-    </array>
-    <key>RunAtLoad</key>
-    <true/>
-</dict>
-</plist>
-`, execPath)
-
-	// Ensure LaunchAgents directory exists
-	dir := filepath.Dir(macOSPlistPath())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	return os.WriteFile(macOSPlistPath(), []byte(plistContent), 0644)
-}
-
-func disableMacOS() error {
-	path := macOSPlistPath()
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil // Already disabled
-	}
-	return os.Remove(path)
-}
-
-func isEnabledMacOS() bool {
-	_, err := os.Stat(macOSPlistPath())
-	return err == nil
-}
-
-// --- Linux Implementation ---
-
-const linuxDesktopName = "gopher-automate.desktop"
-
-func linuxDesktopPath() string {
-	configHome := os.Getenv("XDG_CONFIG_HOME")
-	if configHome == "" {
-		home, _ := os.UserHomeDir()
-		configHome = filepath.Join(home, ".config")
-	}
-	return filepath.Join(configHome, "autostart", linuxDesktopName)
-}
-
-func enableLinux() error {
-	execPath, err := os.Executable()
+	command, err := Command(args)
 	if err != nil {
-		return err
+		return registeredCommand, err
 	}
-
-	desktopContent := fmt.Sprintf(`[Desktop Entry]
-Type=Application
-Name=GopherAutomate
-Exec=%s
-Hidden=false
-NoDisplay=false
-X-GNOME-Autostart-enabled=true
-`, execPath)
-
-	// Ensure autostart directory exists
-	dir := filepath.Dir(linuxDesktopPath())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	return os.WriteFile(linuxDesktopPath(), []byte(desktopContent), 0644)
-}
-
-func disableLinux() error {
-	path := linuxDesktopPath()
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil // Already disabled
+	switch {
+	case len(registeredCommand) == 0:
+		return registeredCommand, errors.New("the login item can't be read")
+	case registeredCommand[0] != command[0]:
+		return registeredCommand, fmt.Errorf("the login item launches %s, not %s", registeredCommand[0], command[0])
+	case !slices.Equal(registeredCommand, command):
+		return registeredCommand, fmt.Errorf("the login item passes %q, not %q", registeredCommand[1:], command[1:])
 	}
-	return os.Remove(path)
-}
-
-func isEnabledLinux() bool {
-	_, err := os.Stat(linuxDesktopPath())
-	return err == nil
+	return registeredCommand, nil
 }
 
-// --- Windows Implementation ---
-
-const windowsRegistryKey = `HKCU\Software\Microsoft\Windows\CurrentVersion\Run`
-const windowsAppName = "GopherAutomate"
-
-func enableWindows() error {
+// Command returns the command line that is registered: the executable, the autostart
+// flag and the extra arguments
+func Command(args []string) ([]string, error) {
 	execPath, err := os.Executable()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Use reg.exe to add the registry key
-	cmd := exec.Command("reg", "add", windowsRegistryKey,
-		"/v", windowsAppName,
-		"/t", "REG_SZ",
-		"/d", execPath,
-		"/f")
-	return cmd.Run()
-}
-
-func disableWindows() error {
-	cmd := exec.Command("reg", "delete", windowsRegistryKey,
-		"/v", windowsAppName,
-		"/f")
-	output, err := cmd.CombinedOutput()
-	// Ignore error if the key doesn't exist
-	if err != nil && !strings.Contains(string(output), "The system was unable to find the specified registry key or value") {
-		return err
+	if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+		execPath = resolved
 	}
-	return nil
-}
-
-func isEnabledWindows() bool {
-	cmd := exec.Command("reg", "query", windowsRegistryKey,
-		"/v", windowsAppName)
-	err := cmd.Run()
-	return err == nil
+	return append([]string{execPath, AutostartFlag}, args...), nil
 }
@@ -0,0 +1,132 @@
+package startup
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	macOSLabel     = "com.gopher-automate"
+	macOSPlistName = macOSLabel + ".plist"
+)
+
+func macOSPlistPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "LaunchAgents", macOSPlistName)
+}
+
+// launchdDomain is the per-user GUI domain the agent is loaded into
+func launchdDomain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func register(command []string) error {
+	var programArgs strings.Builder
+	for _, arg := range command {
+		programArgs.WriteString("        <string>")
+		if err := xml.EscapeText(&programArgs, []byte(arg)); err != nil {
+			return err
+		}
+		programArgs.WriteString("</string>\n")
+	}
+
+	plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>Label</key>
+    <string>%s</string>
+    <key>ProgramArguments</key>
+    <array>
+%s    </array>
+    <key>RunAtLoad</key>
+    <true/>
+</dict>
+</plist>
+`, macOSLabel, programArgs.String())
+
+	// Ensure LaunchAgents directory exists
+	path := macOSPlistPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(plistContent), 0644); err != nil {
+		return err
+	}
+
+	// Reload the agent so the change applies now rather than at next login. Loading it
+	// starts a copy, which sees this instance running and exits (see AutostartFlag).
+	_ = exec.Command("launchctl", "bootout", launchdDomain()+"/"+macOSLabel).Run()
+	if output, err := exec.Command("launchctl", "bootstrap", launchdDomain(), path).CombinedOutput(); err != nil {
+		return fmt.Errorf("launchctl bootstrap: %v: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
+
+func unregister() error {
+	path := macOSPlistPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil // Already disabled
+	}
+	_ = exec.Command("launchctl", "bootout", launchdDomain()+"/"+macOSLabel).Run() // Not loaded is fine
+	return os.Remove(path)
+}
+
+// registered reads ProgramArguments back from the plist
+func registered() ([]string, bool) {
+	data, err := os.ReadFile(macOSPlistPath())
+	if err != nil {
+		return nil, false
+	}
+	args, err := plistProgramArguments(data)
+	if err != nil {
+		return nil, true // Registered, but not by a version we can read
+	}
+	return args, true
+}
+
+// plistProgramArguments extracts the strings of the ProgramArguments array
+func plistProgramArguments(data []byte) ([]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		lastKey string
+		inArgs  bool
+		args    []string
+	)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			if inArgs {
+				return nil, err
+			}
+			return nil, errors.New("no ProgramArguments in plist")
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "key":
+				if err := decoder.DecodeElement(&lastKey, &t); err != nil {
+					return nil, err
+				}
+			case t.Name.Local == "array" && lastKey == "ProgramArguments":
+				inArgs = true
+			case t.Name.Local == "string" && inArgs:
+				var arg string
+				if err := decoder.DecodeElement(&arg, &t); err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "array" && inArgs {
+				return args, nil
+			}
+		}
+	}
+}
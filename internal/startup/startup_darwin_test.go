@@ -0,0 +1,64 @@
+package startup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakePlist points the home directory at a temp dir and writes a launch agent plist with
+// the given program arguments, as an older install or another path would have
+func fakePlist(t *testing.T, args ...string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	var b strings.Builder
+	for _, arg := range args {
+		b.WriteString("<string>" + arg + "</string>")
+	}
+	content := `<?xml version="1.0" encoding="UTF-8"?><plist version="1.0"><dict>` +
+		`<key>Label</key><string>` + macOSLabel + `</string>` +
+		`<key>ProgramArguments</key><array>` + b.String() + `</array>` +
+		`<key>RunAtLoad</key><true/></dict></plist>`
+	if err := os.MkdirAll(filepath.Dir(macOSPlistPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(macOSPlistPath(), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyPlist(t *testing.T) {
+	command, err := Command(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakePlist(t, command...)
+	if got, err := Verify(nil); err != nil || !slices.Equal(got, command) {
+		t.Errorf("current plist: %q, %v", got, err)
+	}
+
+	fakePlist(t, "/Applications/GopherAutomate.app/Contents/MacOS/gopher-automate", AutostartFlag)
+	if _, err := Verify(nil); err == nil || !strings.Contains(err.Error(), "/Applications/GopherAutomate.app") {
+		t.Errorf("moved app: err = %v, want the old path", err)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	if got, err := Verify(nil); got != nil || err != nil {
+		t.Errorf("no plist: %q, %v", got, err)
+	}
+}
+
+func TestPlistProgramArguments(t *testing.T) {
+	data := []byte(`<plist><dict><key>Label</key><string>x</string>` +
+		`<key>ProgramArguments</key><array><string>/a b/app</string><string>--autostart</string><string>&lt;x&gt;</string></array>` +
+		`</dict></plist>`)
+	if got, err := plistProgramArguments(data); err != nil || !slices.Equal(got, []string{"/a b/app", "--autostart", "<x>"}) {
+		t.Errorf("arguments = %q, %v", got, err)
+	}
+	if _, err := plistProgramArguments([]byte(`<plist><dict><key>Label</key><string>x</string></dict></plist>`)); err == nil {
+		t.Error("a plist without ProgramArguments parsed")
+	}
+}
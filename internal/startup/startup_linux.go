@@ -0,0 +1,122 @@
+package startup
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const linuxDesktopName = "gopher-automate.desktop"
+
+func linuxDesktopPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, _ := os.UserHomeDir()
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "autostart", linuxDesktopName)
+}
+
+func register(command []string) error {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = quoteExecArg(arg)
+	}
+
+	desktopContent := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=GopherAutomate
+Exec=%s
+Hidden=false
+NoDisplay=false
+X-GNOME-Autostart-enabled=true
+`, strings.Join(quoted, " "))
+
+	// Ensure autostart directory exists
+	dir := filepath.Dir(linuxDesktopPath())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Autostart entries are read at login; there is nothing to load now
+	return os.WriteFile(linuxDesktopPath(), []byte(desktopContent), 0644)
+}
+
+func unregister() error {
+	path := linuxDesktopPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil // Already disabled
+	}
+	return os.Remove(path)
+}
+
+// registered reads the Exec line back from the desktop entry
+func registered() ([]string, bool) {
+	data, err := os.ReadFile(linuxDesktopPath())
+	if err != nil {
+		return nil, false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if exec, ok := strings.CutPrefix(scanner.Text(), "Exec="); ok {
+			return splitExec(exec), true
+		}
+	}
+	return nil, true
+}
+
+// quoteExecArg quotes an argument for a desktop entry's Exec key when it needs it
+func quoteExecArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\`$;&|<>()*?#~") {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range arg {
+		if strings.ContainsRune("\"`$\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// splitExec splits an Exec value into arguments, undoing quoteExecArg
+func splitExec(exec string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		inQuote bool
+		escaped bool
+		started bool
+	)
+	for _, r := range exec {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case inQuote && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case !inQuote && (r == ' ' || r == '\t'):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,90 @@
+package startup
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeRegistration points the autostart directory at a temp dir and writes a desktop
+// entry with the given Exec line, as an older install or another path would have
+func fakeRegistration(t *testing.T, exec string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if exec == "" {
+		return
+	}
+	if err := os.MkdirAll(strings.TrimSuffix(linuxDesktopPath(), linuxDesktopName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "[Desktop Entry]\nType=Application\nExec=" + exec + "\n"
+	if err := os.WriteFile(linuxDesktopPath(), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	command, err := Command([]string{"--profile", "stage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := strings.Join(command, " ")
+
+	tests := []struct {
+		name string
+		exec string // "" for not registered
+		err  string // "" for a registration that matches
+	}{
+		{"not registered", "", ""},
+		{"current", current, ""},
+		{"moved", "/Applications/Old/gopher-automate --autostart --profile stage", "launches /Applications/Old/gopher-automate"},
+		{"other arguments", command[0] + " --autostart", "passes"},
+		{"empty Exec", " ", "can't be read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRegistration(t, tt.exec)
+			got, err := Verify([]string{"--profile", "stage"})
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("err = %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("err = %v, want one containing %q", err, tt.err)
+			}
+			if tt.exec == "" && got != nil {
+				t.Errorf("registered = %q without a registration", got)
+			}
+		})
+	}
+}
+
+func TestEnableRepairs(t *testing.T) {
+	fakeRegistration(t, "/old/path/gopher-automate --autostart")
+	if _, err := Verify(nil); err == nil {
+		t.Fatal("stale registration verified")
+	}
+	if err := Enable(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(nil); err != nil {
+		t.Errorf("after Enable: %v", err)
+	}
+	if err := Disable(); err != nil {
+		t.Fatal(err)
+	}
+	if IsEnabled() {
+		t.Error("still enabled after Disable")
+	}
+}
+
+func TestExecQuotingRoundTrip(t *testing.T) {
+	command := []string{"/home/me/My Apps/gopher-automate", "--autostart", `say "hi"`, "$HOME", `back\slash`, ""}
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = quoteExecArg(arg)
+	}
+	if got := splitExec(strings.Join(quoted, " ")); !slices.Equal(got, command) {
+		t.Errorf("round trip = %q, want %q", got, command)
+	}
+}
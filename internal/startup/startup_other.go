@@ -0,0 +1,20 @@
+//go:build !darwin && !linux && !windows
+
+package startup
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func register([]string) error {
+	return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+}
+
+func unregister() error {
+	return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+}
+
+func registered() ([]string, bool) {
+	return nil, false
+}
@@ -0,0 +1,55 @@
+package startup
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	windowsRunKey  = `Software\Microsoft\Windows\CurrentVersion\Run`
+	windowsAppName = "GopherAutomate"
+)
+
+func register(command []string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, windowsRunKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetStringValue(windowsAppName, windows.ComposeCommandLine(command))
+}
+
+func unregister() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, windowsRunKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	// Ignore error if the value doesn't exist
+	if err := key.DeleteValue(windowsAppName); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// registered reads the command line back from the Run key
+func registered() ([]string, bool) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, windowsRunKey, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, false
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(windowsAppName)
+	if err != nil {
+		return nil, false
+	}
+	args, err := windows.DecomposeCommandLine(value)
+	if err != nil {
+		return nil, true
+	}
+	return args, true
+}
@@ -0,0 +1,96 @@
+package status
+
+import (
+	"sync"
+	"time"
+)
+
+// Snapshot is the app's current health as shown outside the main window
+type Snapshot struct {
+	DevicesConnected int  // Enabled devices whose ports are all present
+	DevicesMissing   int  // Enabled devices with at least one port missing
+	Initializing     bool // Devices are still being brought up after launch
+
+	LastActionError    error     // Most recent action failure, nil if none
+	LastActionName     string    // Name of the action that failed
+	LastActionFailedAt time.Time // When it failed
+}
+
+// Bus publishes health changes from the MIDI and execution code to subscribers (e.g. the tray).
+// Subscribers are called on the publishing goroutine and must not block.
+type Bus struct {
+	mu          sync.Mutex
+	snapshot    Snapshot
+	subscribers []func(Snapshot)
+}
+
+// NewBus creates an empty status bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers fn for every change and immediately calls it with the current snapshot
+func (b *Bus) Subscribe(fn func(Snapshot)) {
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, fn)
+	snapshot := b.snapshot
+	b.mu.Unlock()
+
+	fn(snapshot)
+}
+
+// Snapshot returns the current health
+func (b *Bus) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshot
+}
+
+// SetDevices records how many devices are connected and missing
+func (b *Bus) SetDevices(connected, missing int) {
+	b.update(func(s *Snapshot) bool {
+		if s.DevicesConnected == connected && s.DevicesMissing == missing {
+			return false
+		}
+		s.DevicesConnected = connected
+		s.DevicesMissing = missing
+		return true
+	})
+}
+
+// SetInitializing records whether devices are still being brought up after launch
+func (b *Bus) SetInitializing(initializing bool) {
+	b.update(func(s *Snapshot) bool {
+		if s.Initializing == initializing {
+			return false
+		}
+		s.Initializing = initializing
+		return true
+	})
+}
+
+// ActionFailed records a failed action execution
+func (b *Bus) ActionFailed(name string, err error) {
+	b.update(func(s *Snapshot) bool {
+		s.LastActionError = err
+		s.LastActionName = name
+		s.LastActionFailedAt = time.Now()
+		return true
+	})
+}
+
+// update applies a change and notifies subscribers if it reports one
+func (b *Bus) update(change func(*Snapshot) bool) {
+	b.mu.Lock()
+	if !change(&b.snapshot) {
+		b.mu.Unlock()
+		return
+	}
+	snapshot := b.snapshot
+	subscribers := append([]func(Snapshot){}, b.subscribers...)
+	b.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(snapshot)
+	}
+}
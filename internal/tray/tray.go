@@ -2,73 +2,228 @@ package tray
 
 import (
 	_ "embed"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/driver/desktop"
 	"github.com/PixPMusic/gopher-automate/internal/config"
-	"github.com/PixPMusic/gopher-automate/internal/startup"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/status"
 )
 
 //go:embed icon-white.png
 var iconWhiteData []byte
 
+//go:embed icon-white-warning.png
+var iconWarningData []byte
+
+//go:embed icon-white-error.png
+var iconErrorData []byte
+
+// errorFlashDuration is how long the error icon is shown after an action fails
+const errorFlashDuration = 10 * time.Second
+
+var (
+	iconNormal  = fyne.NewStaticResource("icon.png", iconWhiteData)
+	iconWarning = fyne.NewStaticResource("icon-warning.png", iconWarningData)
+	iconError   = fyne.NewStaticResource("icon-error.png", iconErrorData)
+)
+
+// FavoriteItem is an action, action group or scene listed in the Run Action submenu
+type FavoriteItem struct {
+	ID      string
+	Name    string
+	IsGroup bool
+	IsScene bool
+}
+
 // Callbacks for tray menu actions
 type Callbacks struct {
 	OnOpen func()
 	OnQuit func()
+
+	// Favorites returns the items for the Run Action submenu
+	Favorites func() []FavoriteItem
+	// OnRunAction runs an action or group by ID. It must not block.
+	OnRunAction func(id string)
+	// OnStartupChanged applies the Open at Startup toggle; the item reflects cfg on Refresh
+	OnStartupChanged func(enabled bool)
+}
+
+// Tray is the installed system tray menu
+type Tray struct {
+	desk        desktop.App
+	menu        *fyne.Menu
+	statusItem  *fyne.MenuItem
+	runItem     *fyne.MenuItem
+	startupItem *fyne.MenuItem
+	callbacks   Callbacks
+	cfg         *config.Config
+
+	icon       fyne.Resource // Icon currently shown, to avoid resetting it needlessly
+	flashTimer *time.Timer   // Reverts the error icon once the flash is over
 }
 
-// Setup initializes the system tray using Fyne's built-in support
-func Setup(app fyne.App, cfg *config.Config, callbacks Callbacks) {
+// Setup initializes the system tray using Fyne's built-in support.
+// Returns nil if the app isn't running as a desktop app.
+func Setup(app fyne.App, cfg *config.Config, callbacks Callbacks) *Tray {
 	// Check if we're running as a desktop app
-	if desk, ok := app.(desktop.App); ok {
-		// Create menu items
-		openItem := fyne.NewMenuItem("Open GopherAutomate", func() {
-			if callbacks.OnOpen != nil {
-				callbacks.OnOpen()
-			}
-		})
+	desk, ok := app.(desktop.App)
+	if !ok {
+		return nil
+	}
 
-		startupItem := fyne.NewMenuItem("Open at Startup", nil)
-		if cfg.OpenAtStartup {
-			startupItem.Checked = true
+	t := &Tray{desk: desk, callbacks: callbacks, cfg: cfg}
+
+	// Status line, kept current by WatchStatus
+	t.statusItem = fyne.NewMenuItem(i18n.T("common.no_devices_configured"), nil)
+	t.statusItem.Disabled = true
+
+	// Create menu items
+	openItem := fyne.NewMenuItem(i18n.T("tray.open"), func() {
+		if callbacks.OnOpen != nil {
+			callbacks.OnOpen()
 		}
+	})
 
-		quitItem := fyne.NewMenuItem("Quit", func() {
-			if callbacks.OnQuit != nil {
-				callbacks.OnQuit()
-			}
-		})
-
-		menu := fyne.NewMenu("GopherAutomate",
-			openItem,
-			fyne.NewMenuItemSeparator(),
-			startupItem,
-			fyne.NewMenuItemSeparator(),
-			quitItem,
-		)
-
-		// Set the action after menu is created so we can refresh it
-		startupItem.Action = func() {
-			if startupItem.Checked {
-				startupItem.Checked = false
-				cfg.OpenAtStartup = false
-				_ = startup.Disable()
-			} else {
-				startupItem.Checked = true
-				cfg.OpenAtStartup = true
-				_ = startup.Enable()
-			}
-			_ = cfg.Save()
-			menu.Refresh()
+	t.runItem = fyne.NewMenuItem(i18n.T("common.run_action"), nil)
+	t.runItem.ChildMenu = fyne.NewMenu("")
+
+	t.startupItem = fyne.NewMenuItem(i18n.T("common.open_at_startup"), func() {
+		if callbacks.OnStartupChanged != nil {
+			callbacks.OnStartupChanged(!t.startupItem.Checked)
+		}
+	})
+	t.startupItem.Checked = cfg.OpenAtStartup
+
+	quitItem := fyne.NewMenuItem(i18n.T("tray.quit"), func() {
+		if callbacks.OnQuit != nil {
+			callbacks.OnQuit()
+		}
+	})
+
+	t.menu = fyne.NewMenu("GopherAutomate",
+		t.statusItem,
+		fyne.NewMenuItemSeparator(),
+		openItem,
+		fyne.NewMenuItemSeparator(),
+		t.runItem,
+		fyne.NewMenuItemSeparator(),
+		t.startupItem,
+		fyne.NewMenuItemSeparator(),
+		quitItem,
+	)
+
+	t.buildRunMenu()
+
+	// Set the system tray menu
+	desk.SetSystemTrayMenu(t.menu)
+
+	// User requested to use the white icon for both modes (macOS style)
+	t.setIcon(iconNormal)
+
+	return t
+}
+
+// WatchStatus keeps the tray icon and status line in sync with the app's health
+func (t *Tray) WatchStatus(bus *status.Bus) {
+	if t == nil {
+		return
+	}
+	bus.Subscribe(func(s status.Snapshot) {
+		fyne.Do(func() { t.applyStatus(s) })
+	})
+}
+
+// applyStatus updates the icon and status line. Must be called on the UI thread.
+func (t *Tray) applyStatus(s status.Snapshot) {
+	t.statusItem.Label = statusLine(s)
+	t.menu.Refresh()
+
+	if t.flashTimer != nil {
+		t.flashTimer.Stop()
+		t.flashTimer = nil
+	}
+
+	// A recent action failure takes priority, then reverts to the device state
+	if s.LastActionError != nil {
+		if remaining := errorFlashDuration - time.Since(s.LastActionFailedAt); remaining > 0 {
+			t.setIcon(iconError)
+			s.LastActionError = nil
+			t.flashTimer = time.AfterFunc(remaining, func() {
+				fyne.Do(func() { t.applyStatus(s) })
+			})
+			return
 		}
+	}
+
+	if s.DevicesMissing > 0 {
+		t.setIcon(iconWarning)
+	} else {
+		t.setIcon(iconNormal)
+	}
+}
+
+// setIcon swaps the tray icon if it differs from the one shown
+func (t *Tray) setIcon(icon fyne.Resource) {
+	if t.icon == icon {
+		return
+	}
+	t.icon = icon
+	t.desk.SetSystemTrayIcon(icon)
+}
 
-		// Set the system tray menu
-		desk.SetSystemTrayMenu(menu)
+// statusLine summarizes device health for the top of the menu
+func statusLine(s status.Snapshot) string {
+	switch {
+	case s.Initializing:
+		return i18n.T("common.initializing_devices")
+	case s.DevicesConnected == 0 && s.DevicesMissing == 0:
+		return i18n.T("common.no_devices_configured")
+	case s.DevicesMissing == 0:
+		return i18n.N("tray.devices_connected", s.DevicesConnected, s.DevicesConnected)
+	default:
+		return i18n.N("tray.devices_connected_missing", s.DevicesConnected, s.DevicesConnected, s.DevicesMissing)
+	}
+}
+
+// Refresh rebuilds the dynamic submenus. Must be called on the UI thread.
+func (t *Tray) Refresh() {
+	if t == nil {
+		return
+	}
+	t.buildRunMenu()
+	t.startupItem.Checked = t.cfg.OpenAtStartup
+	t.menu.Refresh()
+}
+
+// buildRunMenu fills the Run Action submenu from the current favorites
+func (t *Tray) buildRunMenu() {
+	var favorites []FavoriteItem
+	if t.callbacks.Favorites != nil {
+		favorites = t.callbacks.Favorites()
+	}
+
+	var items []*fyne.MenuItem
+	for _, fav := range favorites {
+		id := fav.ID
+		label := fav.Name
+		if fav.IsGroup {
+			label = "📁 " + label
+		} else if fav.IsScene {
+			label = "🎬 " + label
+		}
+		items = append(items, fyne.NewMenuItem(label, func() {
+			if t.callbacks.OnRunAction != nil {
+				t.callbacks.OnRunAction(id)
+			}
+		}))
+	}
 
-		// Create a resource from the embedded icon
-		// User requested to use the white icon for both modes (macOS style)
-		iconResource := fyne.NewStaticResource("icon.png", iconWhiteData)
-		desk.SetSystemTrayIcon(iconResource)
+	if len(items) == 0 {
+		empty := fyne.NewMenuItem(i18n.T("tray.no_favorites"), nil)
+		empty.Disabled = true
+		items = append(items, empty)
 	}
+	t.runItem.ChildMenu.Items = items
 }
@@ -0,0 +1,229 @@
+// Package usage counts pad presses and action runs for the usage statistics in the
+// Diagnostics tab and the menu editor's heat map. Counts are kept in memory, so
+// recording only takes a mutex, and are written to a file in the config directory
+// now and then from a goroutine of their own.
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// FileName is the name of the statistics file in the config directory
+const FileName = "usage.json"
+
+// flushInterval is how often changed statistics are written to disk
+const flushInterval = time.Minute
+
+// Counter counts uses of one pad or action
+type Counter struct {
+	Count    uint64 `json:"count"`
+	Failures uint64 `json:"failures,omitempty"` // Actions only
+
+	// Suppressed counts actions skipped by their cooldown; they aren't in Count
+	Suppressed uint64    `json:"suppressed,omitempty"`
+	Last       time.Time `json:"last"`
+}
+
+// stats is everything counted, as stored in the file
+type stats struct {
+	Pads    map[string]Counter `json:"pads"`    // By padKey
+	Actions map[string]Counter `json:"actions"` // By action ID
+}
+
+var (
+	enabled atomic.Bool
+
+	mu      sync.Mutex
+	current = newStats()
+	dirty   bool // Changed since the last flush
+)
+
+func newStats() stats {
+	return stats{Pads: map[string]Counter{}, Actions: map[string]Counter{}}
+}
+
+// SetEnabled turns counting on or off; what was counted so far is kept
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// padKey identifies a pad of a layout in the statistics
+func padKey(menuID string, row, col int) string {
+	return fmt.Sprintf("%s/%d/%d", menuID, row, col)
+}
+
+// PadPressed counts a press of the pad at row/col of a layout
+func PadPressed(menuID string, row, col int) {
+	if !enabled.Load() {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	key := padKey(menuID, row, col)
+	c := current.Pads[key]
+	c.Count++
+	c.Last = time.Now()
+	current.Pads[key] = c
+	dirty = true
+}
+
+// ActionRan counts a run of an action, and whether it failed
+func ActionRan(actionID string, failed bool) {
+	if !enabled.Load() {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	c := current.Actions[actionID]
+	c.Count++
+	if failed {
+		c.Failures++
+	}
+	c.Last = time.Now()
+	current.Actions[actionID] = c
+	dirty = true
+}
+
+// ActionSuppressed counts a run of an action skipped by its cooldown
+func ActionSuppressed(actionID string) {
+	if !enabled.Load() {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	c := current.Actions[actionID]
+	c.Suppressed++
+	current.Actions[actionID] = c
+	dirty = true
+}
+
+// Pad returns the counts of the pad at row/col of a layout
+func Pad(menuID string, row, col int) Counter {
+	mu.Lock()
+	defer mu.Unlock()
+	return current.Pads[padKey(menuID, row, col)]
+}
+
+// Actions returns the counts of every action run so far, by action ID
+func Actions() map[string]Counter {
+	mu.Lock()
+	defer mu.Unlock()
+	actions := make(map[string]Counter, len(current.Actions))
+	for id, c := range current.Actions {
+		actions[id] = c
+	}
+	return actions
+}
+
+// Reset clears all statistics, on disk too
+func Reset() error {
+	mu.Lock()
+	current = newStats()
+	dirty = true
+	mu.Unlock()
+	return Flush()
+}
+
+// path returns the full path to the statistics file
+func path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Start loads the saved statistics and writes changes to disk every flushInterval.
+// The returned function stops that and writes what is left.
+func Start() (stop func()) {
+	if err := load(); err != nil {
+		slog.Warn("Usage statistics start from zero", "err", err)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+			if err := Flush(); err != nil {
+				slog.Warn("Failed to save usage statistics", "err", err)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		if err := Flush(); err != nil {
+			slog.Warn("Failed to save usage statistics", "err", err)
+		}
+	}
+}
+
+// load reads the statistics file, if there is one
+func load() error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	loaded := newStats()
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("%s: %w", FileName, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = loaded
+	if current.Pads == nil {
+		current.Pads = map[string]Counter{}
+	}
+	if current.Actions == nil {
+		current.Actions = map[string]Counter{}
+	}
+	return nil
+}
+
+// Flush writes the statistics to disk if they changed since the last write
+func Flush() error {
+	mu.Lock()
+	if !dirty {
+		mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(current, "", "  ")
+	dirty = false
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0644)
+}
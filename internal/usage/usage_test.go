@@ -0,0 +1,31 @@
+package usage
+
+import "testing"
+
+func TestActionCounts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := Reset(); err != nil {
+		t.Fatal(err)
+	}
+	SetEnabled(true)
+	t.Cleanup(func() { SetEnabled(false) })
+
+	ActionRan("skip", false)
+	ActionRan("skip", true)
+	ActionSuppressed("skip")
+	ActionSuppressed("skip")
+	ActionSuppressed("skip")
+
+	c := Actions()["skip"]
+	if c.Count != 2 || c.Failures != 1 || c.Suppressed != 3 {
+		t.Errorf("counts = %+v, want 2 runs, 1 failure, 3 suppressed", c)
+	}
+
+	SetEnabled(false)
+	ActionSuppressed("skip")
+	if got := Actions()["skip"].Suppressed; got != 3 {
+		t.Errorf("suppressed = %d after counting was turned off, want 3", got)
+	}
+}
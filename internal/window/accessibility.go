@@ -0,0 +1,111 @@
+package window
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// hitTargetChoices are the minimum hit target sizes offered, in pixels; 0 keeps the defaults
+var hitTargetChoices = []int{0, 44, 56}
+
+// hitTargetTheme is the default theme with padding grown so buttons, selects and
+// checks are at least target pixels tall
+type hitTargetTheme struct {
+	fyne.Theme
+	target float32
+}
+
+func (t hitTargetTheme) Size(name fyne.ThemeSizeName) float32 {
+	size := t.Theme.Size(name)
+	if name == theme.SizeNameInnerPadding {
+		// Buttons are an inline icon tall plus inner padding above and below
+		size = max(size, (t.target-t.Theme.Size(theme.SizeNameInlineIcon))/2)
+	}
+	return size
+}
+
+// applyHitTarget switches to the theme for the minimum hit target preference
+func (mw *MainWindow) applyHitTarget() {
+	if mw.cfg.MinHitTarget > 0 {
+		mw.app.Settings().SetTheme(hitTargetTheme{Theme: theme.DefaultTheme(), target: float32(mw.cfg.MinHitTarget)})
+	} else {
+		mw.app.Settings().SetTheme(theme.DefaultTheme())
+	}
+	mw.applyPadSize()
+}
+
+// hitTargetLabel describes a minimum hit target choice
+func hitTargetLabel(size int) string {
+	if size == 0 {
+		return i18n.T("prefs.hit_target_default")
+	}
+	return fmt.Sprintf("%d px", size)
+}
+
+// padLabel describes a pad of the current layout in words: where it is, its color and its action
+func (mw *MainWindow) padLabel(row, col int) string {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return ""
+	}
+	c := menu.EffectiveColor(row, col)
+	colorName := i18n.T("pad_color." + padcolor.Name(c.R, c.G, c.B))
+	action := i18n.T("menu_editor.pad_label_no_action")
+	if id := menu.Colors[row][col].ActionID; id != "" {
+		if group := mw.actionStore.GetGroup(id); group != nil {
+			action = i18n.T("menu_editor.pad_label_group", group.Name)
+		} else {
+			action = i18n.T("menu_editor.pad_label_action", mw.actionOrGroupName(id))
+		}
+	}
+	return i18n.T("menu_editor.pad_label", row+1, col+1, colorName, action)
+}
+
+// updatePadLabel refreshes the description of a pad after its color or action changed,
+// and the badges marking pads that run a whole group or are hard to make out on hardware
+func (mw *MainWindow) updatePadLabel(row, col int) {
+	pad := mw.gridPads[row][col]
+	if pad == nil {
+		return
+	}
+	pad.SetLabel(mw.padLabel(row, col))
+	badge := ""
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil && mw.actionStore.GetGroup(menu.Colors[row][col].ActionID) != nil {
+		badge = "📁"
+	}
+	if mw.visibility[row][col] != 0 {
+		badge += "⚠"
+	}
+	pad.SetBadge(badge)
+}
+
+// showPadLabel shows the description of the focused or hovered pad under the grid
+func (mw *MainWindow) showPadLabel(label string) {
+	if mw.padLabelText != nil {
+		mw.padLabelText.SetText(label)
+	}
+}
+
+// movePadFocus moves keyboard focus to the neighboring pad for arrow keys
+func (mw *MainWindow) movePadFocus(row, col int, event *fyne.KeyEvent) {
+	switch event.Name {
+	case fyne.KeyUp:
+		row--
+	case fyne.KeyDown:
+		row++
+	case fyne.KeyLeft:
+		col--
+	case fyne.KeyRight:
+		col++
+	default:
+		return
+	}
+	if row < 0 || row > 8 || col < 0 || col > 8 {
+		return
+	}
+	mw.window.Canvas().Focus(mw.gridPads[row][col])
+}
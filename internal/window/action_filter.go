@@ -0,0 +1,196 @@
+package window
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// colorTagColors are the dot colors of actions.ColorTags
+var colorTagColors = map[string]color.Color{
+	"red":    color.RGBA{229, 57, 53, 255},
+	"orange": color.RGBA{251, 140, 0, 255},
+	"yellow": color.RGBA{253, 216, 53, 255},
+	"green":  color.RGBA{67, 160, 71, 255},
+	"blue":   color.RGBA{30, 136, 229, 255},
+	"purple": color.RGBA{142, 36, 170, 255},
+	"gray":   color.RGBA{117, 117, 117, 255},
+}
+
+// colorTagColor returns the dot color of a tag, transparent for untagged items
+func colorTagColor(tag string) color.Color {
+	if c, ok := colorTagColors[tag]; ok {
+		return c
+	}
+	return color.Transparent
+}
+
+// colorTagOptions returns the translated tag names, in actions.ColorTags order
+func colorTagOptions() []string {
+	options := make([]string, len(actions.ColorTags))
+	for i, tag := range actions.ColorTags {
+		options[i] = i18n.T("actions.color_tag." + tag)
+	}
+	return options
+}
+
+// actionFilter narrows the action list; zero fields don't filter
+type actionFilter struct {
+	query      string // Lower-cased name substring
+	colorTag   string
+	actionType actions.ActionType
+}
+
+func (f actionFilter) active() bool {
+	return f != actionFilter{}
+}
+
+// matches reports whether an item passes the filter. inMatchingGroup is set when an
+// enclosing group's name contains the query, which lists the group's contents.
+func (f actionFilter) matches(item actions.TreeItem, inMatchingGroup bool) bool {
+	name, tag := treeItemName(item), ""
+	if item.IsGroup {
+		tag = item.Group.ColorTag
+	} else {
+		tag = item.Action.ColorTag
+	}
+	if f.query != "" && !inMatchingGroup && !strings.Contains(strings.ToLower(name), f.query) {
+		return false
+	}
+	if f.colorTag != "" && tag != f.colorTag {
+		return false
+	}
+	if f.actionType != "" && (item.IsGroup || item.Action.Type != f.actionType) {
+		return false
+	}
+	return true
+}
+
+// treeItemName returns the name of the group or action an item holds
+func treeItemName(item actions.TreeItem) string {
+	if item.IsGroup {
+		return item.Group.Name
+	}
+	return item.Action.Name
+}
+
+// treeItemID returns the ID of the group or action an item holds
+func treeItemID(item actions.TreeItem) string {
+	if item.IsGroup {
+		return item.Group.ID
+	}
+	return item.Action.ID
+}
+
+// filterActionTree keeps the items passing the filter plus the groups enclosing them,
+// in tree order
+func filterActionTree(items []actions.TreeItem, f actionFilter) []actions.TreeItem {
+	if !f.active() {
+		return items
+	}
+
+	type ancestor struct {
+		item      actions.TreeItem
+		shown     bool
+		nameMatch bool // Its name, or an enclosing group's, contains the query
+	}
+	var visible []actions.TreeItem
+	var path []ancestor // Groups enclosing the current item, outermost first
+	for _, item := range items {
+		path = path[:min(item.Depth, len(path))]
+		inMatchingGroup := f.query != "" && slices.ContainsFunc(path, func(a ancestor) bool { return a.nameMatch })
+
+		if f.matches(item, inMatchingGroup) {
+			for i := range path {
+				if !path[i].shown {
+					visible = append(visible, path[i].item)
+					path[i].shown = true
+				}
+			}
+			visible = append(visible, item)
+		}
+		if item.IsGroup {
+			nameMatch := inMatchingGroup || f.query != "" && strings.Contains(strings.ToLower(item.Group.Name), f.query)
+			shown := len(visible) > 0 && visible[len(visible)-1].Group == item.Group
+			path = append(path, ancestor{item: item, shown: shown, nameMatch: nameMatch})
+		}
+	}
+	return visible
+}
+
+// visibleActionItems returns what the action list shows: the action tree narrowed by the filter bar
+func (mw *MainWindow) visibleActionItems() []actions.TreeItem {
+	return filterActionTree(mw.actionStore.GetFlatList(), mw.actionFilter)
+}
+
+// createActionFilterBar builds the search entry and tag and type filters above the action list
+func (mw *MainWindow) createActionFilterBar() fyne.CanvasObject {
+	search := widget.NewEntry()
+	search.SetPlaceHolder(i18n.T("actions.filter.search"))
+	search.OnChanged = func(s string) {
+		mw.actionFilter.query = strings.ToLower(strings.TrimSpace(s))
+		mw.applyActionFilter()
+	}
+
+	tagOptions := append([]string{i18n.T("actions.filter.all_tags")}, colorTagOptions()...)
+	tagSelect := widget.NewSelect(tagOptions, func(s string) {
+		mw.actionFilter.colorTag = ""
+		if i := slices.Index(tagOptions, s); i > 0 {
+			mw.actionFilter.colorTag = actions.ColorTags[i-1]
+		}
+		mw.applyActionFilter()
+	})
+	setSelectedSilently(tagSelect, tagOptions[0])
+
+	types, typeOptions := mw.actionTypeOptions()
+	types = append([]actions.ActionType{""}, types...)
+	typeOptions = append([]string{i18n.T("actions.filter.all_types")}, typeOptions...)
+	typeSelect := widget.NewSelect(typeOptions, func(s string) {
+		mw.actionFilter.actionType = types[max(slices.Index(typeOptions, s), 0)]
+		mw.applyActionFilter()
+	})
+	setSelectedSilently(typeSelect, typeOptions[0])
+
+	mw.clearActionFilter = func() {
+		mw.actionFilter = actionFilter{}
+		setTextSilently(search, "")
+		setSelectedSilently(tagSelect, tagOptions[0])
+		setSelectedSilently(typeSelect, typeOptions[0])
+		mw.actionList.Refresh()
+	}
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(tagSelect, typeSelect), search)
+}
+
+// applyActionFilter refreshes the action list after the filter changed. The selected
+// item keeps its selection at its new index, or is deselected if it was filtered out.
+func (mw *MainWindow) applyActionFilter() {
+	selectedID := ""
+	if mw.selectedAction != nil {
+		selectedID = mw.selectedAction.ID
+	} else if mw.selectedGroup != nil {
+		selectedID = mw.selectedGroup.ID
+	}
+
+	onSelected := mw.actionList.OnSelected
+	mw.actionList.OnSelected = nil
+	defer func() { mw.actionList.OnSelected = onSelected }()
+
+	mw.actionList.UnselectAll()
+	mw.actionList.Refresh()
+	if selectedID == "" {
+		return
+	}
+	if i := slices.IndexFunc(mw.visibleActionItems(), func(item actions.TreeItem) bool { return treeItemID(item) == selectedID }); i >= 0 {
+		mw.actionList.Select(i)
+		return
+	}
+	mw.selectedAction, mw.selectedGroup = nil, nil
+	mw.updateActionEditor()
+}
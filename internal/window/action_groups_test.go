@@ -0,0 +1,103 @@
+package window
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// addGroups adds a group Startup with a nested group Inner, each holding an action
+func addGroups(mw *MainWindow) {
+	mw.actionStore.AddGroup(&actions.ActionGroup{ID: "startup", Name: "Startup"})
+	mw.actionStore.AddGroup(&actions.ActionGroup{ID: "inner", Name: "Inner", ParentGroupID: "startup"})
+	mw.actionStore.AddAction(&actions.Action{ID: "a", Name: "A", Type: actions.ActionTypeSleep, Code: "1", ParentGroupID: "startup"})
+	mw.actionStore.AddAction(&actions.Action{ID: "b", Name: "B", Type: actions.ActionTypeSleep, Code: "1", ParentGroupID: "inner"})
+	mw.cfg.SyncActionStore(mw.actionStore)
+	mw.refreshPadActionOptions()
+}
+
+func TestAssignGroupToPad(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	addGroups(mw)
+	mw.selectPad(4, 4)
+
+	picker := mw.padActionPicker
+	i := slices.IndexFunc(picker.choices, func(c actionChoice) bool { return c.id == "inner" })
+	if i < 0 {
+		t.Fatalf("nested group missing from the pad actions %q", picker.dropdown.Options)
+	}
+	option := picker.dropdown.Options[i]
+	if want := groupOption("Inner"); strings.TrimSpace(option) != want || option == want {
+		t.Errorf("group option = %q, want %q indented", option, want)
+	}
+	picker.dropdown.SetSelected(option) // As the user picks it
+
+	if got := mw.cfg.GetCurrentMenu().Colors[4][4].ActionID; got != "inner" {
+		t.Errorf("pad runs %q, want the group's ID", got)
+	}
+	if got := mw.gridPads[4][4].badge.Text; got == "" {
+		t.Error("pad running a group has no badge")
+	}
+	if got, want := mw.padLabel(4, 4), i18n.T("menu_editor.pad_label_group", "Inner"); !strings.Contains(got, want) {
+		t.Errorf("pad label %q doesn't say it runs the group", got)
+	}
+
+	// Selecting the pad again shows the group, not none
+	mw.selectPad(0, 0)
+	mw.selectPad(4, 4)
+	if got := picker.dropdown.Selected; got != option || picker.Selected() != "inner" {
+		t.Errorf("dropdown shows %q for the pad, want %q", got, option)
+	}
+}
+
+func TestMappingPickerOffersGroups(t *testing.T) {
+	mw := newTestWindow(t)
+	addGroups(mw)
+	var picked string
+	picker := mw.newActionPicker(func(id string) { picked = id })
+	i := slices.IndexFunc(picker.choices, func(c actionChoice) bool { return c.id == "startup" })
+	if i < 0 || picker.choices[i].label != groupOption("Startup") {
+		t.Fatalf("mapping choices %+v lack the Startup group", picker.choices)
+	}
+	picker.dropdown.SetSelected(picker.dropdown.Options[i])
+	if picked != "startup" {
+		t.Errorf("picking the group gave %q, want its ID", picked)
+	}
+}
+
+func TestDeleteGroupClearsPadReferences(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	addGroups(mw)
+	menu := mw.cfg.GetCurrentMenu()
+	menu.Colors[1][1].ActionID = "startup"
+	menu.Colors[2][2].ActionID = "inner"
+	menu.Colors[3][3].ActionID = "b"
+	mapping := config.NewMessageMapping()
+	mapping.ActionID = "inner"
+	mw.cfg.MessageMappings = append(mw.cfg.MessageMappings, mapping)
+	mw.refreshGrid()
+
+	mw.selectedGroup = mw.actionStore.GetGroup("startup")
+	mw.deleteSelectedActionItem()
+	tapButton(t, mw, "Yes")
+	tapButton(t, mw, i18n.T("actions.references_clear"))
+
+	if mw.actionStore.GetGroup("startup") != nil || mw.actionStore.GetGroup("inner") != nil {
+		t.Error("groups not deleted")
+	}
+	for _, pos := range []config.PadPosition{{Row: 1, Col: 1}, {Row: 2, Col: 2}, {Row: 3, Col: 3}} {
+		if id := menu.Colors[pos.Row][pos.Col].ActionID; id != "" {
+			t.Errorf("pad %v still runs %q", pos, id)
+		}
+		if badge := mw.gridPads[pos.Row][pos.Col].badge.Text; badge != "" {
+			t.Errorf("pad %v still has the group badge", pos)
+		}
+	}
+	if mw.cfg.MessageMappings[0].ActionID != "" {
+		t.Error("mapping still runs the deleted group")
+	}
+}
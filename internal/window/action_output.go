@@ -0,0 +1,155 @@
+package window
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+const (
+	maxActionResults  = 10   // Results kept in the output, oldest dropped first
+	maxShownResultLen = 2000 // Characters of a result shown; copying includes them all
+)
+
+// actionResult is the outcome of testing or validating an action or group, with what
+// was tested so it can be copied along with it
+type actionResult struct {
+	at         time.Time
+	text       string
+	name       string
+	actionType string
+	code       string
+}
+
+// actionOutput is the Actions tab's feedback: a status line for prompts and progress,
+// and below it the last test and validation results, timestamped, in a selectable
+// monospace area that can be copied. Results belong to the selected action or group
+// and are cleared when another one is selected.
+type actionOutput struct {
+	clipboard   fyne.Clipboard
+	status      *widget.Label
+	view        *widget.Label
+	accordion   *widget.Accordion
+	includeCode *widget.Check
+
+	subjectID string // Action or group the results are about
+	results   []actionResult
+}
+
+func newActionOutput(clipboard fyne.Clipboard) *actionOutput {
+	o := &actionOutput{clipboard: clipboard}
+	o.status = widget.NewLabel("")
+	o.status.Wrapping = fyne.TextWrapWord
+
+	o.view = widget.NewLabel("")
+	o.view.Wrapping = fyne.TextWrapBreak
+	o.view.TextStyle = fyne.TextStyle{Monospace: true}
+	o.view.Selectable = true
+
+	o.includeCode = widget.NewCheck(i18n.T("actions.output.include_code"), nil)
+	copyBtn := widget.NewButtonWithIcon(i18n.T("actions.output.copy"), theme.ContentCopyIcon(), func() {
+		o.clipboard.SetContent(o.fullText())
+	})
+	detailsBtn := widget.NewButtonWithIcon(i18n.T("actions.output.copy_details"), theme.ContentCopyIcon(), func() {
+		o.clipboard.SetContent(o.details())
+	})
+
+	scroll := container.NewVScroll(o.view)
+	scroll.SetMinSize(fyne.NewSize(0, 120))
+	panel := container.NewBorder(nil, container.NewHBox(o.includeCode, layout.NewSpacer(), copyBtn, detailsBtn), nil, nil, scroll)
+	o.accordion = widget.NewAccordion(widget.NewAccordionItem(i18n.T("actions.output.title"), panel))
+	o.accordion.Open(0)
+	o.accordion.Hide()
+	return o
+}
+
+// Object is what the tab shows
+func (o *actionOutput) Object() fyne.CanvasObject {
+	return container.NewVBox(o.status, o.accordion)
+}
+
+// SetText shows a prompt or progress in the status line
+func (o *actionOutput) SetText(text string) {
+	o.status.SetText(text)
+}
+
+// Select makes the results about an action or group, by ID, clearing them when it
+// isn't the one they were about
+func (o *actionOutput) Select(subjectID string) {
+	if subjectID == o.subjectID {
+		return
+	}
+	o.subjectID = subjectID
+	o.results = nil
+	o.refresh()
+}
+
+// AddResult adds a result about an action or group, by ID. It is dropped when another
+// one was selected meanwhile, e.g. while a test ran.
+func (o *actionOutput) AddResult(subjectID string, result actionResult) {
+	if subjectID != o.subjectID {
+		return
+	}
+	result.at = time.Now()
+	o.results = append(o.results, result)
+	if len(o.results) > maxActionResults {
+		o.results = o.results[len(o.results)-maxActionResults:]
+	}
+	o.refresh()
+}
+
+// refresh shows the results, newest last, each cut to maxShownResultLen characters
+func (o *actionOutput) refresh() {
+	if len(o.results) == 0 {
+		o.view.SetText("")
+		o.accordion.Hide()
+		return
+	}
+	var b strings.Builder
+	for i, r := range o.results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		text := r.text
+		if n := utf8.RuneCountInString(text); n > maxShownResultLen {
+			text = string([]rune(text)[:maxShownResultLen]) + "\n" + i18n.T("actions.output.truncated", n-maxShownResultLen)
+		}
+		fmt.Fprintf(&b, "[%s] %s\n", r.at.Format(time.TimeOnly), text)
+	}
+	o.view.SetText(strings.TrimSuffix(b.String(), "\n"))
+	o.accordion.Show()
+}
+
+// fullText is every result in full, as copied
+func (o *actionOutput) fullText() string {
+	lines := make([]string, len(o.results))
+	for i, r := range o.results {
+		lines[i] = fmt.Sprintf("[%s] %s", r.at.Format(time.TimeOnly), r.text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// details describes the latest result for someone helping: what was tested, the code
+// if it is to be included, and the result in full
+func (o *actionOutput) details() string {
+	if len(o.results) == 0 {
+		return ""
+	}
+	r := o.results[len(o.results)-1]
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", i18n.T("common.action"), r.name)
+	fmt.Fprintf(&b, "%s: %s\n", i18n.T("common.type"), r.actionType)
+	if o.includeCode.Checked && r.code != "" {
+		fmt.Fprintf(&b, "%s:\n%s\n", i18n.T("actions.output.code"), r.code)
+	}
+	fmt.Fprintf(&b, "%s (%s):\n%s\n", i18n.T("actions.output.result"), r.at.Format(time.DateTime), r.text)
+	return b.String()
+}
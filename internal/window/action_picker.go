@@ -0,0 +1,304 @@
+package window
+
+import (
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// pickerThreshold is how many choices an action picker offers as a plain dropdown;
+// longer lists open a searchable dialog instead
+const pickerThreshold = 25
+
+// recentPickCount is how many recently picked actions the picker dialog lists first
+const recentPickCount = 5
+
+// actionChoice is an entry of an action picker: nothing, an action, a group or a scene
+type actionChoice struct {
+	id    string // "" for none
+	label string
+	depth int // Nesting in the action tree
+}
+
+// indented returns the choice's label indented as in the action tree
+func (c actionChoice) indented() string {
+	return strings.Repeat("  ", c.depth) + c.label
+}
+
+// actionChoices lists what a pad or mapping can run: nothing, the actions and groups in
+// tree order, then the scenes
+func (mw *MainWindow) actionChoices() []actionChoice {
+	choices := []actionChoice{{label: i18n.T("common.none")}}
+	for _, item := range mw.actionStore.GetFlatList() {
+		label := treeItemName(item)
+		if item.IsGroup {
+			label = groupOption(label)
+		}
+		choices = append(choices, actionChoice{id: treeItemID(item), label: label, depth: item.Depth})
+	}
+	for _, scene := range mw.cfg.Scenes {
+		choices = append(choices, actionChoice{id: scene.ID, label: sceneOption(scene.Name)})
+	}
+	return choices
+}
+
+// noteRecentPick puts an action, group or scene first among the recently picked ones
+func (mw *MainWindow) noteRecentPick(id string) {
+	if id == "" {
+		return
+	}
+	mw.recentPicks = slices.DeleteFunc(mw.recentPicks, func(recent string) bool { return recent == id })
+	mw.recentPicks = slices.Insert(mw.recentPicks, 0, id)
+	if len(mw.recentPicks) > recentPickCount {
+		mw.recentPicks = mw.recentPicks[:recentPickCount]
+	}
+}
+
+// ============ ACTION PICKER WIDGET ============
+
+// actionPicker selects an action, group or scene by ID: a dropdown for short lists,
+// a button opening a searchable dialog for long ones
+type actionPicker struct {
+	widget.BaseWidget
+	mw        *MainWindow
+	choices   []actionChoice
+	selected  string
+	dropdown  *widget.Select
+	button    *widget.Button
+	OnChanged func(id string) // Called for picks by the user only
+}
+
+func (mw *MainWindow) newActionPicker(onChanged func(id string)) *actionPicker {
+	p := &actionPicker{mw: mw, OnChanged: onChanged}
+	p.dropdown = widget.NewSelect(nil, func(string) {
+		if i := p.dropdown.SelectedIndex(); i >= 0 && i < len(p.choices) {
+			p.pick(p.choices[i].id)
+		}
+	})
+	p.dropdown.PlaceHolder = i18n.T("menu_editor.select_action")
+	p.button = widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		mw.showActionPickerDialog(p.choices, p.selected, p.pick)
+	})
+	p.button.Alignment = widget.ButtonAlignLeading
+	p.ExtendBaseWidget(p)
+	p.SetChoices(mw.actionChoices())
+	return p
+}
+
+func (p *actionPicker) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(p.dropdown, p.button))
+}
+
+// SetChoices replaces what can be picked, keeping the selection if it is still offered
+func (p *actionPicker) SetChoices(choices []actionChoice) {
+	p.choices = choices
+	options := make([]string, len(choices))
+	for i, choice := range choices {
+		options[i] = choice.indented()
+	}
+	p.dropdown.Options = options
+	if len(choices) > pickerThreshold {
+		p.dropdown.Hide()
+		p.button.Show()
+	} else {
+		p.button.Hide()
+		p.dropdown.Show()
+	}
+	p.SetSelected(p.selected)
+}
+
+// SetSelected shows an ID as picked without calling OnChanged; an ID that isn't
+// offered shows as none
+func (p *actionPicker) SetSelected(id string) {
+	i := max(slices.IndexFunc(p.choices, func(c actionChoice) bool { return c.id == id }), 0)
+	p.selected = ""
+	label := i18n.T("common.none")
+	if i < len(p.choices) {
+		p.selected, label = p.choices[i].id, p.choices[i].label
+	}
+
+	if i < len(p.dropdown.Options) {
+		setSelectedSilently(p.dropdown, p.dropdown.Options[i])
+	}
+	p.button.SetText(label)
+}
+
+// Selected returns the picked ID, "" for none
+func (p *actionPicker) Selected() string {
+	return p.selected
+}
+
+// pick selects an ID as the user picked it
+func (p *actionPicker) pick(id string) {
+	p.SetSelected(id)
+	p.mw.noteRecentPick(p.selected)
+	if p.OnChanged != nil {
+		p.OnChanged(p.selected)
+	}
+}
+
+// ============ ACTION PICKER DIALOG ============
+
+// pickerRow is a line of the picker dialog: a section header or a choice
+type pickerRow struct {
+	header string
+	choice actionChoice
+}
+
+// pickerRows lists the choices matching a filter. Without one, the recently picked
+// choices come first, then everything in tree order; with one, the matches are flat.
+func pickerRows(choices []actionChoice, recent []string, query string) []pickerRow {
+	var rows []pickerRow
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query != "" {
+		for _, choice := range choices {
+			if strings.Contains(strings.ToLower(choice.label), query) {
+				choice.depth = 0
+				rows = append(rows, pickerRow{choice: choice})
+			}
+		}
+		return rows
+	}
+
+	for _, id := range recent {
+		if i := slices.IndexFunc(choices, func(c actionChoice) bool { return c.id == id }); i >= 0 {
+			if len(rows) == 0 {
+				rows = append(rows, pickerRow{header: i18n.T("action_picker.recent")})
+			}
+			choice := choices[i]
+			choice.depth = 0
+			rows = append(rows, pickerRow{choice: choice})
+		}
+	}
+	if len(rows) > 0 {
+		rows = append(rows, pickerRow{header: i18n.T("action_picker.all")})
+	}
+	for _, choice := range choices {
+		rows = append(rows, pickerRow{choice: choice})
+	}
+	return rows
+}
+
+// pickerEntry is the filter entry of the picker dialog, passing the arrow keys and
+// Escape on to the dialog
+type pickerEntry struct {
+	widget.Entry
+	onKey func(*fyne.KeyEvent) bool // Reports whether it handled the key
+}
+
+func newPickerEntry() *pickerEntry {
+	e := &pickerEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *pickerEntry) TypedKey(key *fyne.KeyEvent) {
+	if e.onKey == nil || !e.onKey(key) {
+		e.Entry.TypedKey(key)
+	}
+}
+
+// showActionPickerDialog lets the user find a choice by typing part of its name. The
+// arrow keys move through the list and Return picks the highlighted choice.
+func (mw *MainWindow) showActionPickerDialog(choices []actionChoice, current string, onPick func(id string)) {
+	var dlg *dialog.CustomDialog
+	var rows []pickerRow
+	highlighted := -1
+	keyboard := false // The list is being moved through with the keys, not clicked
+
+	list := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			row := rows[id]
+			if row.header != "" {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.Importance = widget.LowImportance
+				label.SetText(row.header)
+				return
+			}
+			label.TextStyle = fyne.TextStyle{}
+			label.Importance = widget.MediumImportance
+			label.SetText(row.choice.indented())
+		},
+	)
+	pick := func(row int) {
+		if row < 0 || row >= len(rows) || rows[row].header != "" {
+			return
+		}
+		dlg.Hide()
+		onPick(rows[row].choice.id)
+	}
+	list.OnSelected = func(id widget.ListItemID) {
+		if rows[id].header != "" {
+			list.Unselect(id)
+			return
+		}
+		highlighted = id
+		if !keyboard {
+			pick(id)
+		}
+	}
+	highlight := func(row int) {
+		if row < 0 || row >= len(rows) {
+			list.UnselectAll()
+			highlighted = -1
+			return
+		}
+		keyboard = true
+		list.Select(row)
+		keyboard = false
+	}
+	// move highlights the next choice in a direction, skipping headers
+	move := func(step int) {
+		for row := highlighted + step; row >= 0 && row < len(rows); row += step {
+			if rows[row].header == "" {
+				highlight(row)
+				return
+			}
+		}
+	}
+
+	filter := newPickerEntry()
+	filter.SetPlaceHolder(i18n.T("action_picker.filter"))
+	update := func(query string) {
+		rows = pickerRows(choices, mw.recentPicks, query)
+		list.UnselectAll()
+		list.Refresh()
+		highlighted = -1
+		start := slices.IndexFunc(rows, func(r pickerRow) bool { return r.header == "" && r.choice.id == current })
+		if query != "" || start < 0 {
+			start = slices.IndexFunc(rows, func(r pickerRow) bool { return r.header == "" })
+		}
+		highlight(start)
+	}
+	filter.OnChanged = update
+	filter.OnSubmitted = func(string) { pick(highlighted) }
+	filter.onKey = func(key *fyne.KeyEvent) bool {
+		switch key.Name {
+		case fyne.KeyDown:
+			move(1)
+		case fyne.KeyUp:
+			move(-1)
+		case fyne.KeyEscape:
+			dlg.Hide()
+		default:
+			return false
+		}
+		return true
+	}
+
+	content := container.NewBorder(filter, nil, nil, nil, list)
+	dlg = dialog.NewCustom(i18n.T("action_picker.title"), i18n.T("common.cancel"), content, mw.window)
+	dlg.Resize(fyne.NewSize(420, 480))
+	dlg.Show()
+	update("")
+	mw.window.Canvas().Focus(filter)
+}
@@ -1,33 +1,44 @@
 package window
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"image/color"
+	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
 )
 
+// previewDelay is how long typing must pause before the code preview is regenerated
+const previewDelay = 250 * time.Millisecond
+
 // ============ ACTIONS TAB ============
 
 func (mw *MainWindow) createActionsTab() fyne.CanvasObject {
-	header := widget.NewLabel("Actions")
+	header := widget.NewLabel(i18n.T("common.actions"))
 	header.TextStyle = fyne.TextStyle{Bold: true}
 
-	subtitle := widget.NewLabel("Create and manage executable actions")
+	subtitle := widget.NewLabel(i18n.T("actions.subtitle"))
 
 	// Create the action list
 	mw.actionList = widget.NewList(
 		func() int {
-			return len(mw.actionStore.GetFlatList())
+			return len(mw.visibleActionItems())
 		},
 		func() fyne.CanvasObject {
 			return mw.createActionListItem()
@@ -41,13 +52,13 @@ func (mw *MainWindow) createActionsTab() fyne.CanvasObject {
 	}
 
 	// Action list toolbar
-	addGroupBtn := widget.NewButtonWithIcon("Add Group", theme.FolderNewIcon(), func() {
+	addGroupBtn := widget.NewButtonWithIcon(i18n.T("common.add_group"), theme.FolderNewIcon(), func() {
 		mw.addActionGroup()
 	})
-	addActionBtn := widget.NewButtonWithIcon("Add Action", theme.ContentAddIcon(), func() {
+	addActionBtn := widget.NewButtonWithIcon(i18n.T("actions.add_action"), theme.ContentAddIcon(), func() {
 		mw.addAction()
 	})
-	deleteBtn := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {
+	deleteBtn := widget.NewButtonWithIcon(i18n.T("common.delete"), theme.DeleteIcon(), func() {
 		mw.deleteSelectedActionItem()
 	})
 	moveUpBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
@@ -58,8 +69,11 @@ func (mw *MainWindow) createActionsTab() fyne.CanvasObject {
 	})
 	listToolbar := container.NewHBox(addGroupBtn, addActionBtn, deleteBtn, layout.NewSpacer(), moveUpBtn, moveDownBtn)
 
+	// Turns pad presses into a new group
+	macroBar := mw.createMacroRecorder()
+
 	listPanel := container.NewBorder(
-		listToolbar,
+		container.NewVBox(listToolbar, macroBar, mw.createActionFilterBar()),
 		nil, nil, nil,
 		mw.actionList,
 	)
@@ -72,65 +86,97 @@ func (mw *MainWindow) createActionsTab() fyne.CanvasObject {
 	split.Offset = 0.35
 
 	// Save button
-	saveBtn := widget.NewButtonWithIcon("Save Actions", theme.DocumentSaveIcon(), func() {
+	saveBtn := widget.NewButtonWithIcon(i18n.T("actions.save"), theme.DocumentSaveIcon(), func() {
 		mw.saveActions()
 	})
 	saveBtn.Importance = widget.HighImportance
 
+	mw.actionsRevertBtn = widget.NewButtonWithIcon(i18n.T("menu_editor.revert"), theme.ContentUndoIcon(), func() {
+		mw.confirmDiscard(i18n.T("actions.unsaved_lost"), mw.revertActions, nil)
+	})
+	mw.actionsRevertBtn.Disable()
+
+	mw.actionsUnsaved = widget.NewLabelWithStyle(i18n.T("menu_editor.unsaved"), fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	mw.actionsUnsaved.Importance = widget.WarningImportance
+	mw.actionsUnsaved.Hide()
+
 	return container.NewBorder(
 		container.NewVBox(header, subtitle, widget.NewSeparator()),
-		container.NewVBox(widget.NewSeparator(), container.NewHBox(saveBtn)),
+		container.NewVBox(widget.NewSeparator(), container.NewHBox(mw.actionsRevertBtn, saveBtn, mw.actionsUnsaved)),
 		nil, nil,
 		split,
 	)
 }
 
 func (mw *MainWindow) createActionListItem() fyne.CanvasObject {
+	dot := canvas.NewCircle(color.Transparent)
 	icon := widget.NewIcon(theme.DocumentIcon())
-	name := widget.NewLabel("Action Name")
+	name := widget.NewLabel(i18n.T("common.action_name"))
 	typeLabel := widget.NewLabel("")
 	typeLabel.TextStyle = fyne.TextStyle{Italic: true}
 
-	return container.NewHBox(icon, name, typeLabel)
+	return container.NewHBox(container.NewCenter(container.NewGridWrap(fyne.NewSize(10, 10), dot)), icon, name, typeLabel)
 }
 
 func (mw *MainWindow) updateActionListItem(id widget.ListItemID, obj fyne.CanvasObject) {
-	items := mw.actionStore.GetFlatList()
+	items := mw.visibleActionItems()
 	if id >= len(items) {
 		return
 	}
 
 	item := items[id]
 	row := obj.(*fyne.Container)
-	icon := row.Objects[0].(*widget.Icon)
-	name := row.Objects[1].(*widget.Label)
-	typeLabel := row.Objects[2].(*widget.Label)
+	dot := row.Objects[0].(*fyne.Container).Objects[0].(*fyne.Container).Objects[0].(*canvas.Circle)
+	icon := row.Objects[1].(*widget.Icon)
+	name := row.Objects[2].(*widget.Label)
+	typeLabel := row.Objects[3].(*widget.Label)
 
 	// Add indentation based on depth
 	indent := strings.Repeat("  ", item.Depth)
 
+	tag := ""
+	if item.IsGroup {
+		tag = item.Group.ColorTag
+	} else {
+		tag = item.Action.ColorTag
+	}
+	dot.FillColor = colorTagColor(tag)
+	dot.Refresh()
+
 	if item.IsGroup {
 		icon.SetResource(theme.FolderIcon())
-		name.SetText(indent + item.Group.Name)
+		name.SetText(indent + item.Group.Name + favoriteMarker(item.Group.Favorite))
 		typeLabel.SetText("")
 	} else {
 		icon.SetResource(theme.DocumentIcon())
-		name.SetText(indent + item.Action.Name)
+		name.SetText(indent + item.Action.Name + favoriteMarker(item.Action.Favorite))
 		switch item.Action.Type {
 		case actions.ActionTypeAppleScript:
-			typeLabel.SetText("(AppleScript)")
+			typeLabel.SetText(i18n.T("actions.list.applescript"))
 		case actions.ActionTypeShellCommand:
 			typeLabel.SetText("(" + mw.executor.GetShellName() + ")")
 		case actions.ActionTypeSleep:
-			typeLabel.SetText("(Sleep)")
+			typeLabel.SetText(i18n.T("actions.list.sleep"))
 		case actions.ActionTypeMidi:
-			typeLabel.SetText("(MIDI)")
+			typeLabel.SetText(i18n.T("actions.list.midi"))
+		case actions.ActionTypeWaitForMidi:
+			typeLabel.SetText(i18n.T("actions.list.wait_midi"))
+		default:
+			typeLabel.SetText("(" + actionTypeLabel(item.Action.Type) + ")")
 		}
 	}
 }
 
+// favoriteMarker returns the suffix shown after favorite items in the action list
+func favoriteMarker(favorite bool) string {
+	if favorite {
+		return " ★"
+	}
+	return ""
+}
+
 func (mw *MainWindow) selectActionItem(id widget.ListItemID) {
-	items := mw.actionStore.GetFlatList()
+	items := mw.visibleActionItems()
 	if id >= len(items) {
 		return
 	}
@@ -148,51 +194,105 @@ func (mw *MainWindow) selectActionItem(id widget.ListItemID) {
 }
 
 func (mw *MainWindow) createActionEditorPanel() *fyne.Container {
-	header := widget.NewLabel("Editor")
+	header := widget.NewLabel(i18n.T("actions.editor"))
 	header.TextStyle = fyne.TextStyle{Bold: true}
 
 	// Name entry
-	nameLabel := widget.NewLabel("Name:")
+	nameLabel := widget.NewLabel(i18n.T("actions.name_label"))
 	mw.actionNameEntry = widget.NewEntry()
-	mw.actionNameEntry.SetPlaceHolder("Action name")
+	mw.actionNameEntry.SetPlaceHolder(i18n.T("actions.name_placeholder"))
 	mw.actionNameEntry.OnChanged = func(s string) {
 		if mw.selectedAction != nil {
 			mw.selectedAction.Name = s
 			mw.actionStore.UpdateAction(mw.selectedAction)
 			mw.actionList.Refresh()
+			mw.notifyTray()
 		} else if mw.selectedGroup != nil {
 			mw.selectedGroup.Name = s
 			mw.actionStore.UpdateGroup(mw.selectedGroup)
 			mw.actionList.Refresh()
+			mw.notifyTray()
 		}
 	}
 
+	// Favorites are listed in the tray's Run Action menu
+	mw.favoriteCheck = widget.NewCheck(i18n.T("actions.favorite"), func(checked bool) {
+		if mw.selectedAction != nil {
+			mw.selectedAction.Favorite = checked
+			mw.actionStore.UpdateAction(mw.selectedAction)
+		} else if mw.selectedGroup != nil {
+			mw.selectedGroup.Favorite = checked
+			mw.actionStore.UpdateGroup(mw.selectedGroup)
+		}
+		mw.actionList.Refresh()
+		mw.notifyTray()
+	})
+
+	// Color tag, shown as a dot in the list and usable as a filter
+	tagOptions := append([]string{i18n.T("actions.color_tag.none")}, colorTagOptions()...)
+	mw.colorTagSelect = widget.NewSelect(tagOptions, func(s string) {
+		tag := ""
+		if i := slices.Index(tagOptions, s); i > 0 {
+			tag = actions.ColorTags[i-1]
+		}
+		if mw.selectedAction != nil {
+			mw.selectedAction.ColorTag = tag
+			mw.actionStore.UpdateAction(mw.selectedAction)
+		} else if mw.selectedGroup != nil {
+			mw.selectedGroup.ColorTag = tag
+			mw.actionStore.UpdateGroup(mw.selectedGroup)
+		}
+		mw.applyActionFilter() // The item may no longer pass a tag filter
+	})
+	colorTagRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("actions.color_tag_label")), nil, mw.colorTagSelect)
+
 	// Wait for Completion Checkbox
-	mw.waitForCompletionCheck = widget.NewCheck("Wait for completion", func(checked bool) {
+	mw.waitForCompletionCheck = widget.NewCheck(i18n.T("actions.wait_for_completion"), func(checked bool) {
 		if mw.selectedAction != nil {
 			mw.selectedAction.WaitForCompletion = checked
 			mw.actionStore.UpdateAction(mw.selectedAction)
 		}
 	})
 
-	// Type selector (only for actions)
-	typeLabel := widget.NewLabel("Type:")
-	typeOptions := []string{"Shell Command", "Sleep", "Send MIDI Message"}
-	if mw.executor.CanExecuteAppleScript() {
-		typeOptions = append([]string{"AppleScript"}, typeOptions...)
+	// Shell actions matching a danger pattern only run from pads once allowed here
+	mw.allowDangerousCheck = widget.NewCheck(i18n.T("actions.allow_dangerous"), func(checked bool) {
+		if mw.selectedAction != nil {
+			mw.selectedAction.AllowDangerous = checked
+			mw.actionStore.UpdateAction(mw.selectedAction)
+		}
+	})
+
+	// Pads arm on the first press and run the action on the second
+	mw.requireConfirmCheck = widget.NewCheck(i18n.T("actions.require_confirmation"), func(checked bool) {
+		if mw.selectedAction != nil {
+			mw.selectedAction.RequireConfirmation = checked
+			mw.actionStore.UpdateAction(mw.selectedAction)
+		}
+	})
+
+	// Triggers arriving within the cooldown of the last run are skipped
+	mw.cooldownEntry = widget.NewEntry()
+	mw.cooldownEntry.SetPlaceHolder(i18n.T("actions.cooldown_none"))
+	mw.cooldownEntry.Validator = func(s string) error {
+		if _, ok := parseCooldown(s); !ok {
+			return errors.New(i18n.T("actions.cooldown_invalid"))
+		}
+		return nil
+	}
+	mw.cooldownEntry.OnChanged = func(s string) {
+		if n, ok := parseCooldown(s); ok && mw.selectedAction != nil {
+			mw.selectedAction.CooldownSeconds = n
+			mw.actionStore.UpdateAction(mw.selectedAction)
+		}
 	}
+	mw.cooldownRow = container.NewBorder(nil, nil, widget.NewLabel(i18n.T("actions.cooldown")), nil, mw.cooldownEntry)
+
+	// Type selector (only for actions)
+	typeLabel := widget.NewLabel(i18n.T("actions.type_label"))
+	types, typeOptions := mw.actionTypeOptions()
 	mw.actionTypeSelect = widget.NewSelect(typeOptions, func(s string) {
-		if mw.selectedAction != nil {
-			switch s {
-			case "AppleScript":
-				mw.selectedAction.Type = actions.ActionTypeAppleScript
-			case "Shell Command":
-				mw.selectedAction.Type = actions.ActionTypeShellCommand
-			case "Sleep":
-				mw.selectedAction.Type = actions.ActionTypeSleep
-			case "Send MIDI Message":
-				mw.selectedAction.Type = actions.ActionTypeMidi
-			}
+		if i := slices.Index(typeOptions, s); i >= 0 && mw.selectedAction != nil {
+			mw.selectedAction.Type = types[i]
 			mw.actionStore.UpdateAction(mw.selectedAction)
 			// Re-update editor to show correct fields for new type
 			mw.updateActionEditor()
@@ -200,25 +300,24 @@ func (mw *MainWindow) createActionEditorPanel() *fyne.Container {
 	})
 
 	// --- Code Editor Fields (Scripting) ---
-	mw.actionCodeEntry = widget.NewMultiLineEntry()
-	mw.actionCodeEntry.SetPlaceHolder("Enter your script or command here...")
-	mw.actionCodeEntry.SetMinRowsVisible(8)
-	mw.actionCodeEntry.OnChanged = func(s string) {
+	mw.codeEditor = newCodeEditor(mw.codeEditorRows(), mw.cfg.CodeEditorWrap)
+	mw.codeEditor.SetPlaceHolder(i18n.T("actions.code_placeholder"))
+	mw.codeEditor.OnChanged = func(s string) {
 		if mw.selectedAction != nil {
 			mw.selectedAction.Code = s
 			mw.actionStore.UpdateAction(mw.selectedAction)
-			mw.updateCodePreview()
+			mw.scheduleCodePreview()
 		}
 	}
 
 	// Syntax-highlighted preview label
-	initialPreview := widget.NewRichText(&widget.TextSegment{Text: "(no code)"})
+	initialPreview := widget.NewRichText(&widget.TextSegment{Text: i18n.T("common.no_code")})
 	mw.codePreviewScroll = container.NewVScroll(initialPreview)
 	mw.codePreviewScroll.SetMinSize(fyne.NewSize(0, 100))
 
 	// --- Sleep Editor Fields ---
 	mw.sleepDurationEntry = widget.NewEntry()
-	mw.sleepDurationEntry.SetPlaceHolder("Duration in seconds (e.g. 2.5)")
+	mw.sleepDurationEntry.SetPlaceHolder(i18n.T("actions.sleep_placeholder"))
 	mw.sleepDurationEntry.OnChanged = func(s string) {
 		if mw.selectedAction != nil && mw.selectedAction.Type == actions.ActionTypeSleep {
 			mw.selectedAction.Code = s
@@ -228,16 +327,10 @@ func (mw *MainWindow) createActionEditorPanel() *fyne.Container {
 
 	// --- MIDI Editor Fields ---
 	// Devices: We need a way to refresh this list dynamically
-	mw.midiDeviceSelect = widget.NewSelect([]string{}, func(s string) {
-		mw.updateMidiJSON()
-	})
-	mw.midiDeviceSelect.PlaceHolder = "Select Target Device"
+	mw.midiDeviceSelect = widget.NewSelect([]string{}, nil)
+	mw.midiDeviceSelect.PlaceHolder = i18n.T("actions.midi.device_placeholder")
 
-	mw.midiMsgTypeSelect = widget.NewRadioGroup([]string{"Note On", "Note Off", "CC", "PC", "SysEx"}, func(s string) {
-		// Update visibility of params based on type
-		mw.updateMidiEditorVisibility(s)
-		mw.updateMidiJSON()
-	})
+	mw.midiMsgTypeSelect = widget.NewRadioGroup([]string{i18n.T("common.midi.note_on"), i18n.T("common.midi.note_off"), i18n.T("common.midi.cc"), i18n.T("common.midi.pc"), i18n.T("common.midi.sysex")}, nil)
 	mw.midiMsgTypeSelect.Horizontal = true
 
 	// Channels 1-16
@@ -245,67 +338,89 @@ func (mw *MainWindow) createActionEditorPanel() *fyne.Container {
 	for i := 0; i < 16; i++ {
 		channels[i] = fmt.Sprintf("%d", i+1)
 	}
-	mw.midiChannelSelect = widget.NewSelect(channels, func(s string) {
-		mw.updateMidiJSON()
-	})
-
-	mw.midiNoteEntry = widget.NewEntry()
-	mw.midiNoteEntry.SetPlaceHolder("0-127")
-	mw.midiNoteEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
+	mw.midiChannelSelect = widget.NewSelect(channels, nil)
 
-	mw.midiVelocityEntry = widget.NewEntry()
-	mw.midiVelocityEntry.SetPlaceHolder("0-127")
-	mw.midiVelocityEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
-
-	mw.midiProgramEntry = widget.NewEntry()
-	mw.midiProgramEntry.SetPlaceHolder("0-127")
-	mw.midiProgramEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
+	// Callbacks are bound to the selected action in showMidiEditor
+	mw.midiNoteEntry = newIntEntry(0, 127)
+	mw.midiVelocityEntry = newIntEntry(0, 127)
+	mw.midiProgramEntry = newIntEntry(0, 127)
 
 	mw.midiSysexEntry = widget.NewMultiLineEntry()
-	mw.midiSysexEntry.SetPlaceHolder("Hex bytes (e.g. F0 01 02 F7)")
-	mw.midiSysexEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
+	mw.midiSysexEntry.SetPlaceHolder(i18n.T("actions.midi.sysex_placeholder"))
+
+	mw.midiMinEntry = newIntEntry(0, 127)
+	mw.midiMaxEntry = newIntEntry(0, 127)
+	mw.midiTimeoutEntry = widget.NewEntry()
+	mw.midiTimeoutEntry.SetPlaceHolder(i18n.T("actions.wait_midi.timeout_none"))
 
 	// Main container that will hold the swappable content
 	mw.actionEditorContent = container.NewVBox()
 
-	// Feedback label
-	mw.actionFeedback = widget.NewLabel("")
-	mw.actionFeedback.Wrapping = fyne.TextWrapWord
+	// Prompts, progress and the results of tests and validation
+	mw.actionFeedback = newActionOutput(mw.app.Clipboard())
 
 	// Test button
-	testBtn := widget.NewButtonWithIcon("Test", theme.MediaPlayIcon(), func() {
+	testBtn := widget.NewButtonWithIcon(i18n.T("actions.test"), theme.MediaPlayIcon(), func() {
 		mw.testAction()
 	})
 
 	// Validate button
-	validateBtn := widget.NewButtonWithIcon("Validate", theme.ConfirmIcon(), func() {
+	validateBtn := widget.NewButtonWithIcon(i18n.T("actions.validate"), theme.ConfirmIcon(), func() {
 		mw.validateAction()
 	})
 
-	actionButtons := container.NewHBox(validateBtn, testBtn)
+	// Stop button, shown while a group test runs
+	mw.stopTestBtn = widget.NewButtonWithIcon(i18n.T("actions.stop_test"), theme.MediaStopIcon(), func() {
+		mw.stopTest()
+	})
+	mw.stopTestBtn.Hide()
+
+	actionButtons := container.NewHBox(validateBtn, testBtn, mw.stopTestBtn)
+
+	// Where the selected action or group is used, refreshed on selection
+	mw.usedByBox = container.NewVBox()
 
 	return container.NewVBox(
 		header,
 		widget.NewSeparator(),
 		container.NewBorder(nil, nil, nameLabel, nil, mw.actionNameEntry),
 		container.NewBorder(nil, nil, typeLabel, nil, mw.actionTypeSelect),
+		mw.favoriteCheck,
+		colorTagRow,
 		mw.waitForCompletionCheck,
+		mw.allowDangerousCheck,
+		mw.requireConfirmCheck,
+		mw.cooldownRow,
 		widget.NewSeparator(),
 		mw.actionEditorContent, // Dynamic content
 		widget.NewSeparator(),
 		actionButtons,
-		mw.actionFeedback,
+		mw.actionFeedback.Object(),
+		mw.usedByBox,
 	)
 }
 
+// scheduleCodePreview regenerates the code preview once typing pauses
+func (mw *MainWindow) scheduleCodePreview() {
+	if mw.previewTimer != nil {
+		mw.previewTimer.Stop()
+	}
+	mw.previewTimer = time.AfterFunc(previewDelay, func() {
+		fyne.Do(mw.updateCodePreview)
+	})
+}
+
 func (mw *MainWindow) updateCodePreview() {
+	if mw.previewTimer != nil {
+		mw.previewTimer.Stop() // Shown now, a pending refresh would only repeat it
+	}
 	if mw.codePreviewScroll == nil {
 		return
 	}
 
 	if mw.selectedAction == nil {
 		mw.codePreviewScroll.Content = widget.NewRichText(&widget.TextSegment{
-			Text:  "(no action selected)",
+			Text:  i18n.T("actions.preview_no_action"),
 			Style: widget.RichTextStyle{Inline: true, TextStyle: fyne.TextStyle{Italic: true}},
 		})
 	} else {
@@ -318,6 +433,14 @@ func (mw *MainWindow) updateCodePreview() {
 
 func (mw *MainWindow) updateActionEditor() {
 	mw.actionEditorContent.Objects = nil // Clear current content
+	switch {
+	case mw.selectedAction != nil:
+		mw.actionFeedback.Select(mw.selectedAction.ID)
+	case mw.selectedGroup != nil:
+		mw.actionFeedback.Select(mw.selectedGroup.ID)
+	default:
+		mw.actionFeedback.Select("")
+	}
 
 	if mw.selectedAction != nil {
 		mw.actionNameEntry.OnChanged = nil // Disable callback
@@ -327,43 +450,40 @@ func (mw *MainWindow) updateActionEditor() {
 				mw.selectedAction.Name = s
 				mw.actionStore.UpdateAction(mw.selectedAction)
 				mw.actionList.Refresh()
+				mw.notifyTray()
 			}
 		}
 		mw.actionNameEntry.Enable()
 		mw.actionTypeSelect.Enable()
+		mw.setFavoriteCheck(mw.selectedAction.Favorite)
+		mw.setColorTagSelect(mw.selectedAction.ColorTag)
 		mw.waitForCompletionCheck.Show()
 		mw.waitForCompletionCheck.SetChecked(mw.selectedAction.WaitForCompletion)
+		setCheckedSilently(mw.allowDangerousCheck, mw.selectedAction.AllowDangerous)
+		mw.requireConfirmCheck.Show()
+		setCheckedSilently(mw.requireConfirmCheck, mw.selectedAction.RequireConfirmation)
+		mw.cooldownRow.Show()
+		if mw.selectedAction.CooldownSeconds > 0 {
+			setTextSilently(mw.cooldownEntry, strconv.Itoa(mw.selectedAction.CooldownSeconds))
+		} else {
+			setTextSilently(mw.cooldownEntry, "")
+		}
+		if mw.selectedAction.Type == actions.ActionTypeShellCommand {
+			mw.allowDangerousCheck.Show()
+		} else {
+			mw.allowDangerousCheck.Hide()
+		}
 
-		mw.actionTypeSelect.OnChanged = nil // Disable callback
+		setSelectedSilently(mw.actionTypeSelect, actionTypeLabel(mw.selectedAction.Type))
 		switch mw.selectedAction.Type {
-		case actions.ActionTypeAppleScript:
-			mw.actionTypeSelect.SetSelected("AppleScript")
-			mw.showScriptEditor()
-		case actions.ActionTypeShellCommand:
-			mw.actionTypeSelect.SetSelected("Shell Command")
+		case actions.ActionTypeAppleScript, actions.ActionTypeShellCommand:
 			mw.showScriptEditor()
 		case actions.ActionTypeSleep:
-			mw.actionTypeSelect.SetSelected("Sleep")
 			mw.showSleepEditor()
-		case actions.ActionTypeMidi:
-			mw.actionTypeSelect.SetSelected("Send MIDI Message")
+		case actions.ActionTypeMidi, actions.ActionTypeWaitForMidi:
 			mw.showMidiEditor()
-		}
-		mw.actionTypeSelect.OnChanged = func(s string) {
-			if mw.selectedAction != nil {
-				switch s {
-				case "AppleScript":
-					mw.selectedAction.Type = actions.ActionTypeAppleScript
-				case "Shell Command":
-					mw.selectedAction.Type = actions.ActionTypeShellCommand
-				case "Sleep":
-					mw.selectedAction.Type = actions.ActionTypeSleep
-				case "Send MIDI Message":
-					mw.selectedAction.Type = actions.ActionTypeMidi
-				}
-				mw.actionStore.UpdateAction(mw.selectedAction)
-				mw.updateActionEditor()
-			}
+		default:
+			mw.showFieldsEditor()
 		}
 
 		mw.actionFeedback.SetText("")
@@ -376,13 +496,20 @@ func (mw *MainWindow) updateActionEditor() {
 				mw.selectedGroup.Name = s
 				mw.actionStore.UpdateGroup(mw.selectedGroup)
 				mw.actionList.Refresh()
+				mw.notifyTray()
 			}
 		}
 		mw.actionNameEntry.Enable()
 		mw.actionTypeSelect.Disable()
+		mw.setFavoriteCheck(mw.selectedGroup.Favorite)
+		mw.setColorTagSelect(mw.selectedGroup.ColorTag)
 		mw.waitForCompletionCheck.Hide()
+		mw.allowDangerousCheck.Hide()
+		mw.requireConfirmCheck.Hide()
+		mw.cooldownRow.Hide()
+		mw.showGroupChildren()
 
-		mw.actionFeedback.SetText("Groups contain actions. Select an action to edit.")
+		mw.actionFeedback.SetText(i18n.T("actions.group_selected"))
 	} else {
 		// Nothing selected
 		mw.actionNameEntry.OnChanged = nil
@@ -390,31 +517,218 @@ func (mw *MainWindow) updateActionEditor() {
 		mw.actionNameEntry.Disable()
 		mw.actionTypeSelect.Disable()
 		mw.waitForCompletionCheck.Hide()
+		mw.allowDangerousCheck.Hide()
+		mw.requireConfirmCheck.Hide()
+		mw.cooldownRow.Hide()
+		mw.favoriteCheck.Hide()
+		mw.colorTagSelect.Disable()
 
-		mw.actionFeedback.SetText("Select an action or group")
+		mw.actionFeedback.SetText(i18n.T("actions.select_prompt"))
 	}
 
 	mw.actionEditorContent.Refresh()
+	mw.refreshUsedBy()
+}
+
+// refreshUsedBy lists the pads, message mappings and groups that use the selected
+// action or group. Each entry links to where it is.
+func (mw *MainWindow) refreshUsedBy() {
+	if mw.usedByBox == nil {
+		return
+	}
+	mw.usedByBox.Objects = nil
+
+	var id, parentID string
+	switch {
+	case mw.selectedAction != nil:
+		id, parentID = mw.selectedAction.ID, mw.selectedAction.ParentGroupID
+	case mw.selectedGroup != nil:
+		id, parentID = mw.selectedGroup.ID, mw.selectedGroup.ParentGroupID
+	default:
+		mw.usedByBox.Refresh()
+		return
+	}
+
+	header := widget.NewLabel(i18n.T("actions.used_by"))
+	header.TextStyle = fyne.TextStyle{Bold: true}
+	mw.usedByBox.Add(widget.NewSeparator())
+	mw.usedByBox.Add(header)
+
+	// Pads come before mappings in FindActionReferences
+	for _, ref := range mw.cfg.FindActionReferences(id) {
+		link := widget.NewHyperlink(actionReferenceLabel(ref), nil)
+		link.OnTapped = func() { mw.showActionReference(ref) }
+		mw.usedByBox.Add(link)
+	}
+	// ...then the groups it runs as part of, innermost first
+	for group := mw.actionStore.GetGroup(parentID); group != nil; group = mw.actionStore.GetGroup(group.ParentGroupID) {
+		groupID := group.ID
+		link := widget.NewHyperlink(i18n.T("actions.reference_group", group.Name), nil)
+		link.OnTapped = func() { mw.selectActionListItem(groupID) }
+		mw.usedByBox.Add(link)
+	}
+
+	if len(mw.usedByBox.Objects) == 2 {
+		unused := widget.NewLabel(i18n.T("actions.used_by_none"))
+		unused.Importance = widget.LowImportance
+		mw.usedByBox.Add(unused)
+	}
+	mw.usedByBox.Refresh()
+}
+
+// showActionReference switches to the tab holding a pad or message mapping and selects it
+func (mw *MainWindow) showActionReference(ref config.ActionReference) {
+	if ref.MenuID == "" {
+		mw.tabs.Select(mw.mappingTab)
+		if i := slices.IndexFunc(mw.cfg.MessageMappings, func(m config.MessageMapping) bool { return m.ID == ref.MappingID }); i >= 0 {
+			mw.mappingList.ScrollTo(i)
+			mw.mappingList.Select(i)
+		}
+		return
+	}
+
+	mw.tabs.Select(mw.menuEditorTab)
+	if menu := mw.cfg.GetMenu(ref.MenuID); menu != nil {
+		mw.loadLayoutByName(menu.Name) // May ask about unsaved changes first
+	}
+	if current := mw.cfg.GetCurrentMenu(); current != nil && current.ID == ref.MenuID && ref.Area == config.PadAreaGrid {
+		mw.selectPad(ref.Row, ref.Col)
+	}
+}
+
+// selectActionListItem selects an action or group in the action list by ID, clearing
+// the filter if it hides the item
+func (mw *MainWindow) selectActionListItem(id string) {
+	isItem := func(item actions.TreeItem) bool { return treeItemID(item) == id }
+	i := slices.IndexFunc(mw.visibleActionItems(), isItem)
+	if i < 0 && mw.actionFilter.active() {
+		mw.clearActionFilter()
+		i = slices.IndexFunc(mw.visibleActionItems(), isItem)
+	}
+	if i >= 0 {
+		mw.actionList.ScrollTo(i)
+		mw.actionList.Select(i)
+	}
+}
+
+// setFavoriteCheck shows the favorite checkbox with the given state, without marking anything changed
+func (mw *MainWindow) setFavoriteCheck(checked bool) {
+	onChanged := mw.favoriteCheck.OnChanged
+	mw.favoriteCheck.OnChanged = nil
+	mw.favoriteCheck.SetChecked(checked)
+	mw.favoriteCheck.OnChanged = onChanged
+	mw.favoriteCheck.Show()
+}
+
+// setColorTagSelect enables the color tag select showing tag, without marking anything changed
+func (mw *MainWindow) setColorTagSelect(tag string) {
+	option := i18n.T("actions.color_tag.none")
+	if i := slices.Index(actions.ColorTags, tag); i >= 0 {
+		option = colorTagOptions()[i]
+	}
+	setSelectedSilently(mw.colorTagSelect, option)
+	mw.colorTagSelect.Enable()
 }
 
 func (mw *MainWindow) showScriptEditor() {
-	mw.actionCodeEntry.OnChanged = nil
-	mw.actionCodeEntry.SetText(mw.selectedAction.Code)
-	mw.actionCodeEntry.OnChanged = func(s string) {
+	mw.codeEditor.OnChanged = nil
+	mw.codeEditor.SetText(mw.selectedAction.Code)
+	mw.codeEditor.OnChanged = func(s string) {
 		if mw.selectedAction != nil {
 			mw.selectedAction.Code = s
 			mw.actionStore.UpdateAction(mw.selectedAction)
-			mw.updateCodePreview()
+			mw.scheduleCodePreview()
 		}
 	}
 	mw.updateCodePreview()
 
-	mw.actionEditorContent.Add(widget.NewLabel("Code:"))
-	mw.actionEditorContent.Add(mw.actionCodeEntry)
-	mw.actionEditorContent.Add(widget.NewLabel("Preview:"))
+	wrapCheck := widget.NewCheck(i18n.T("actions.soft_wrap"), func(checked bool) {
+		mw.cfg.CodeEditorWrap = checked
+		mw.codeEditor.SetWrap(checked)
+		mw.savePreferences()
+	})
+	wrapCheck.Checked = mw.cfg.CodeEditorWrap
+
+	mw.actionEditorContent.Add(container.NewBorder(nil, nil, widget.NewLabel(i18n.T("actions.code_label")), wrapCheck))
+	mw.actionEditorContent.Add(mw.codeEditor)
+	mw.actionEditorContent.Add(widget.NewLabel(i18n.T("actions.preview_label")))
 	mw.actionEditorContent.Add(mw.codePreviewScroll)
 }
 
+// codeEditorRows returns the script editor height set in preferences
+func (mw *MainWindow) codeEditorRows() int {
+	if mw.cfg.CodeEditorRows > 0 {
+		return mw.cfg.CodeEditorRows
+	}
+	return defaultCodeEditorRows
+}
+
+// actionTypeOptions returns the action types that run on this platform and their labels
+func (mw *MainWindow) actionTypeOptions() ([]actions.ActionType, []string) {
+	types := mw.executor.Types()
+	labels := make([]string, len(types))
+	for i, t := range types {
+		labels[i] = actionTypeLabel(t)
+	}
+	return types, labels
+}
+
+// showFieldsEditor edits an action of a registered type with a form built from the
+// fields it was registered with
+func (mw *MainWindow) showFieldsEditor() {
+	meta, ok := actions.Meta(mw.selectedAction.Type)
+	if !ok || len(meta.Fields) == 0 {
+		mw.actionEditorContent.Add(widget.NewLabel(i18n.T("actions.no_editor")))
+		return
+	}
+	values, err := actions.DecodeFields(mw.selectedAction.Code, meta.Fields)
+	if err != nil {
+		values, _ = actions.DecodeFields("", meta.Fields) // Code of another type; start over
+	}
+
+	action := mw.selectedAction
+	set := func(key, value string) {
+		if mw.selectedAction != action {
+			return
+		}
+		values[key] = value
+		action.Code = actions.EncodeFields(values)
+		mw.actionStore.UpdateAction(action)
+	}
+
+	form := widget.NewForm()
+	for _, field := range meta.Fields {
+		if field.Kind == actions.FieldSelect {
+			sel := widget.NewSelect(field.Options, func(s string) { set(field.Key, s) })
+			setSelectedSilently(sel, values[field.Key])
+			form.Append(field.Label, sel)
+			continue
+		}
+		entry := widget.NewEntry()
+		entry.SetText(values[field.Key])
+		valid := func(string) bool { return true }
+		if field.Kind == actions.FieldNumber {
+			valid = func(s string) bool {
+				_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+				return s == "" || err == nil
+			}
+			entry.Validator = func(s string) error {
+				if !valid(s) {
+					return errors.New(i18n.T("actions.field_number_invalid"))
+				}
+				return nil
+			}
+		}
+		entry.OnChanged = func(s string) {
+			if valid(s) {
+				set(field.Key, strings.TrimSpace(s))
+			}
+		}
+		form.Append(field.Label, entry)
+	}
+	mw.actionEditorContent.Add(form)
+}
+
 func (mw *MainWindow) showSleepEditor() {
 	mw.sleepDurationEntry.OnChanged = nil
 	mw.sleepDurationEntry.SetText(mw.selectedAction.Code)
@@ -424,18 +738,30 @@ func (mw *MainWindow) showSleepEditor() {
 			mw.actionStore.UpdateAction(mw.selectedAction)
 		}
 	}
-	mw.actionEditorContent.Add(container.NewBorder(nil, nil, widget.NewLabel("Delay (seconds):"), nil, mw.sleepDurationEntry))
+	mw.actionEditorContent.Add(container.NewBorder(nil, nil, widget.NewLabel(i18n.T("actions.delay_label")), nil, mw.sleepDurationEntry))
 }
 
 func (mw *MainWindow) showMidiEditor() {
-	// Need to check current Code value and populate fields
-	var data actions.MidiActionData
+	// Edits go into a draft of the stored data, one field at a time, so fields that
+	// are hidden for the current message type or mid-edit keep their values
+	mw.midiDraft = actions.WaitMidiData{}
 	if mw.selectedAction.Code != "" {
-		_ = json.Unmarshal([]byte(mw.selectedAction.Code), &data)
+		_ = json.Unmarshal([]byte(mw.selectedAction.Code), &mw.midiDraft)
+	}
+	if mw.midiDraft.MsgType == "" {
+		mw.midiDraft.MsgType = "note_on"
+	}
+	if mw.midiDraft.Channel < 1 || mw.midiDraft.Channel > 16 {
+		mw.midiDraft.Channel = 1
 	}
+	data := mw.midiDraft
+	waiting := mw.selectedAction.Type == actions.ActionTypeWaitForMidi
 
-	// Refresh device list
+	// Refresh device list; waits listen on an input port
 	devices := mw.midiManager.ListOutPorts()
+	if waiting {
+		devices = mw.midiManager.ListInPorts()
+	}
 	// Add configured named devices too if not present
 	for _, dev := range mw.cfg.Devices {
 		found := false
@@ -450,60 +776,54 @@ func (mw *MainWindow) showMidiEditor() {
 		}
 	}
 	mw.midiDeviceSelect.Options = devices
-	mw.midiDeviceSelect.OnChanged = nil
-	mw.midiDeviceSelect.SetSelected(data.DeviceName)
-	mw.midiDeviceSelect.OnChanged = func(s string) { mw.updateMidiJSON() }
-
-	// Set Msg Type
-	displayType := "Note On"
-	switch data.MsgType {
-	case "note_on":
-		displayType = "Note On"
-	case "note_off":
-		displayType = "Note Off"
-	case "cc":
-		displayType = "CC"
-	case "pc":
-		displayType = "PC"
-	case "sysex":
-		displayType = "SysEx"
+	setSelectedSilently(mw.midiDeviceSelect, data.DeviceName)
+	mw.midiDeviceSelect.OnChanged = func(s string) {
+		mw.setMidiField(func(d *actions.MidiActionData) { d.DeviceName = s })
 	}
 
+	// Set Msg Type
+	displayType := midiMsgTypeLabel(data.MsgType)
 	mw.midiMsgTypeSelect.OnChanged = nil
-	mw.midiMsgTypeSelect.SetSelected(displayType) // This triggers updateMidiEditorVisibility via OnChanged
+	mw.midiMsgTypeSelect.SetSelected(displayType)
 	mw.midiMsgTypeSelect.OnChanged = func(s string) {
+		msgType := midiMsgTypeFromLabel(s)
+		if msgType == "" {
+			return // Deselected
+		}
 		// Update visibility of params based on type
 		mw.updateMidiEditorVisibility(s)
-		mw.updateMidiJSON()
+		mw.setMidiField(func(d *actions.MidiActionData) { d.MsgType = msgType })
 	}
 
 	// Set Params
-	mw.midiChannelSelect.OnChanged = nil
-	if data.Channel > 0 {
-		mw.midiChannelSelect.SetSelected(fmt.Sprintf("%d", data.Channel))
-	} else {
-		mw.midiChannelSelect.SetSelected("1")
+	setSelectedSilently(mw.midiChannelSelect, fmt.Sprintf("%d", data.Channel))
+	mw.midiChannelSelect.OnChanged = func(s string) {
+		if c, err := strconv.Atoi(s); err == nil {
+			mw.setMidiField(func(d *actions.MidiActionData) { d.Channel = c })
+		}
 	}
-	mw.midiChannelSelect.OnChanged = func(s string) { mw.updateMidiJSON() }
 
-	mw.midiNoteEntry.OnChanged = nil
-	mw.midiNoteEntry.SetText(fmt.Sprintf("%d", data.Note))
-	mw.midiNoteEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
+	mw.bindMidiValueEntry(mw.midiNoteEntry, data.Note, func(d *actions.MidiActionData, v int) { d.Note = v })
+	mw.bindMidiValueEntry(mw.midiVelocityEntry, data.Velocity, func(d *actions.MidiActionData, v int) { d.Velocity = v })
+	mw.bindMidiValueEntry(mw.midiProgramEntry, data.Program, func(d *actions.MidiActionData, v int) { d.Program = v })
 
-	mw.midiVelocityEntry.OnChanged = nil
-	mw.midiVelocityEntry.SetText(fmt.Sprintf("%d", data.Velocity))
-	mw.midiVelocityEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
-
-	mw.midiProgramEntry.OnChanged = nil
-	mw.midiProgramEntry.SetText(fmt.Sprintf("%d", data.Program))
-	mw.midiProgramEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
+	setTextSilently(mw.midiSysexEntry, data.SysEx)
+	mw.midiSysexEntry.OnChanged = func(s string) {
+		mw.setMidiField(func(d *actions.MidiActionData) { d.SysEx = s })
+	}
 
-	mw.midiSysexEntry.OnChanged = nil
-	mw.midiSysexEntry.SetText(data.SysEx)
-	mw.midiSysexEntry.OnChanged = func(s string) { mw.updateMidiJSON() }
+	if waiting {
+		maxValue := data.MaxValue
+		if maxValue == 0 {
+			maxValue = 127 // No limit
+		}
+		mw.bindWaitValueEntry(mw.midiMinEntry, data.MinValue, func(d *actions.WaitMidiData, v int) { d.MinValue = v })
+		mw.bindWaitValueEntry(mw.midiMaxEntry, maxValue, func(d *actions.WaitMidiData, v int) { d.MaxValue = v })
+		mw.bindWaitTimeoutEntry(data.TimeoutSeconds)
+	}
 
 	// Add Components
-	mw.actionEditorContent.Add(container.NewBorder(nil, nil, widget.NewLabel("Device:"), nil, mw.midiDeviceSelect))
+	mw.actionEditorContent.Add(container.NewBorder(nil, nil, widget.NewLabel(i18n.T("actions.midi.device_label")), nil, mw.midiDeviceSelect))
 	mw.actionEditorContent.Add(mw.midiMsgTypeSelect)
 
 	// Create param container but don't add all children yet - handled by updateMidiEditorVisibility which adds/removes?
@@ -531,19 +851,19 @@ func (mw *MainWindow) showMidiEditor() {
 }
 
 func (mw *MainWindow) midiChannelRow(rowFunc func(string, fyne.CanvasObject) *fyne.Container) *fyne.Container {
-	return rowFunc("Channel:", mw.midiChannelSelect)
+	return rowFunc(i18n.T("common.channel_label"), mw.midiChannelSelect)
 }
 func (mw *MainWindow) midiNoteRow(rowFunc func(string, fyne.CanvasObject) *fyne.Container) *fyne.Container {
-	return rowFunc("Number/Note:", mw.midiNoteEntry)
+	return rowFunc(i18n.T("common.number_label"), mw.midiNoteEntry)
 }
 func (mw *MainWindow) midiVelocityRow(rowFunc func(string, fyne.CanvasObject) *fyne.Container) *fyne.Container {
-	return rowFunc("Value/Velocity:", mw.midiVelocityEntry)
+	return rowFunc(i18n.T("common.value_label"), mw.midiVelocityEntry)
 }
 func (mw *MainWindow) midiProgramRow(rowFunc func(string, fyne.CanvasObject) *fyne.Container) *fyne.Container {
-	return rowFunc("Program:", mw.midiProgramEntry)
+	return rowFunc(i18n.T("common.program_label"), mw.midiProgramEntry)
 }
 func (mw *MainWindow) midiSysexRow(rowFunc func(string, fyne.CanvasObject) *fyne.Container) *fyne.Container {
-	return rowFunc("Bytes:", mw.midiSysexEntry)
+	return rowFunc(i18n.T("common.bytes_label"), mw.midiSysexEntry)
 }
 
 func (mw *MainWindow) updateMidiEditorVisibility(displayType string) {
@@ -580,77 +900,143 @@ func (mw *MainWindow) updateMidiEditorVisibility(displayType string) {
 		return container.NewBorder(nil, nil, widget.NewLabel(label), nil, w)
 	}
 
+	// Waits match a range of values instead of sending one, and any note off
+	waiting := mw.selectedAction != nil && mw.selectedAction.Type == actions.ActionTypeWaitForMidi
 	switch displayType {
-	case "Note On", "Note Off", "CC":
-		mw.actionEditorContent.Add(row("Channel:", mw.midiChannelSelect))
-		mw.actionEditorContent.Add(row("Number/Note:", mw.midiNoteEntry))
-		mw.actionEditorContent.Add(row("Value/Velocity:", mw.midiVelocityEntry))
-	case "PC":
-		mw.actionEditorContent.Add(row("Channel:", mw.midiChannelSelect))
-		mw.actionEditorContent.Add(row("Program:", mw.midiProgramEntry))
-	case "SysEx":
-		mw.actionEditorContent.Add(row("Bytes:", mw.midiSysexEntry))
+	case i18n.T("common.midi.note_on"), i18n.T("common.midi.note_off"), i18n.T("common.midi.cc"):
+		mw.actionEditorContent.Add(row(i18n.T("common.channel_label"), mw.midiChannelSelect))
+		mw.actionEditorContent.Add(row(i18n.T("common.number_label"), mw.midiNoteEntry))
+		if !waiting {
+			mw.actionEditorContent.Add(row(i18n.T("common.value_label"), mw.midiVelocityEntry))
+		} else if displayType != i18n.T("common.midi.note_off") {
+			mw.actionEditorContent.Add(row(i18n.T("actions.wait_midi.min_value"), mw.midiMinEntry))
+			mw.actionEditorContent.Add(row(i18n.T("actions.wait_midi.max_value"), mw.midiMaxEntry))
+		}
+	case i18n.T("common.midi.pc"):
+		mw.actionEditorContent.Add(row(i18n.T("common.channel_label"), mw.midiChannelSelect))
+		mw.actionEditorContent.Add(row(i18n.T("common.program_label"), mw.midiProgramEntry))
+	case i18n.T("common.midi.sysex"):
+		label := i18n.T("common.bytes_label")
+		if waiting {
+			label = i18n.T("actions.wait_midi.sysex_prefix")
+		}
+		mw.actionEditorContent.Add(row(label, mw.midiSysexEntry))
+	}
+	if waiting {
+		mw.actionEditorContent.Add(row(i18n.T("actions.wait_midi.timeout"), mw.midiTimeoutEntry))
 	}
 
 	mw.actionEditorContent.Refresh()
 }
 
-func (mw *MainWindow) updateMidiJSON() {
-	if mw.selectedAction == nil || mw.selectedAction.Type != actions.ActionTypeMidi {
-		return
-	}
+// bindMidiValueEntry shows a stored value and commits edits to the draft only while they
+// are valid, so clearing the field mid-edit doesn't store a zero
+func (mw *MainWindow) bindMidiValueEntry(entry *intEntry, value int, apply func(d *actions.MidiActionData, v int)) {
+	mw.bindWaitValueEntry(entry, value, func(d *actions.WaitMidiData, v int) { apply(&d.MidiActionData, v) })
+}
 
-	// Gather data
-	data := actions.MidiActionData{
-		DeviceName: mw.midiDeviceSelect.Selected,
-		Channel:    1,
-		Note:       0,
-		Velocity:   0,
-		Program:    0,
-		SysEx:      mw.midiSysexEntry.Text,
+// bindWaitValueEntry is bindMidiValueEntry for the value range of a wait-for-MIDI action
+func (mw *MainWindow) bindWaitValueEntry(entry *intEntry, value int, apply func(d *actions.WaitMidiData, v int)) {
+	entry.OnValue, entry.OnInvalid = nil, nil // Rebinding may clamp what the previous action left
+	entry.SetValue(value)
+	entry.OnInvalid = func(err error) {
+		mw.actionFeedback.SetText(err.Error())
 	}
-
-	// Parse Type
-	switch mw.midiMsgTypeSelect.Selected {
-	case "Note On":
-		data.MsgType = "note_on"
-	case "Note Off":
-		data.MsgType = "note_off"
-	case "CC":
-		data.MsgType = "cc"
-	case "PC":
-		data.MsgType = "pc"
-	case "SysEx":
-		data.MsgType = "sysex"
+	entry.OnValue = func(v int) {
+		mw.actionFeedback.SetText("")
+		mw.setWaitMidiField(func(d *actions.WaitMidiData) { apply(d, v) })
 	}
+}
 
-	// Parse Ints
-	if c, err := strconv.Atoi(mw.midiChannelSelect.Selected); err == nil {
-		data.Channel = c
+// bindWaitTimeoutEntry shows a wait's timeout, empty for none, and commits valid edits
+func (mw *MainWindow) bindWaitTimeoutEntry(seconds float64) {
+	text := ""
+	if seconds > 0 {
+		text = strconv.FormatFloat(seconds, 'f', -1, 64)
 	}
-	if n, err := strconv.Atoi(mw.midiNoteEntry.Text); err == nil {
-		data.Note = n
+	setTextSilently(mw.midiTimeoutEntry, text)
+	mw.midiTimeoutEntry.OnChanged = func(s string) {
+		timeout := 0.0
+		if s = strings.TrimSpace(s); s != "" {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil || v < 0 {
+				mw.actionFeedback.SetText(i18n.T("actions.wait_midi.timeout_invalid"))
+				return
+			}
+			timeout = v
+		}
+		mw.actionFeedback.SetText("")
+		mw.setWaitMidiField(func(d *actions.WaitMidiData) { d.TimeoutSeconds = timeout })
 	}
-	if v, err := strconv.Atoi(mw.midiVelocityEntry.Text); err == nil {
-		data.Velocity = v
+}
+
+// setMidiField changes one field of the MIDI draft and stores the result as the action's code
+func (mw *MainWindow) setMidiField(apply func(d *actions.MidiActionData)) {
+	mw.setWaitMidiField(func(d *actions.WaitMidiData) { apply(&d.MidiActionData) })
+}
+
+// setWaitMidiField is setMidiField for the fields only wait-for-MIDI actions have
+func (mw *MainWindow) setWaitMidiField(apply func(d *actions.WaitMidiData)) {
+	if mw.selectedAction == nil {
+		return
 	}
-	if p, err := strconv.Atoi(mw.midiProgramEntry.Text); err == nil {
-		data.Program = p
+	var data any
+	switch mw.selectedAction.Type {
+	case actions.ActionTypeMidi:
+		apply(&mw.midiDraft)
+		data = mw.midiDraft.MidiActionData
+	case actions.ActionTypeWaitForMidi:
+		apply(&mw.midiDraft)
+		data = mw.midiDraft
+	default:
+		return
 	}
 
-	// Serialize
 	bytes, _ := json.Marshal(data)
 	mw.selectedAction.Code = string(bytes)
 	mw.actionStore.UpdateAction(mw.selectedAction)
 }
 
+// midiMsgTypeLabel returns the display name of a stored MIDI message type
+func midiMsgTypeLabel(msgType string) string {
+	switch msgType {
+	case "note_off":
+		return i18n.T("common.midi.note_off")
+	case "cc":
+		return i18n.T("common.midi.cc")
+	case "pc":
+		return i18n.T("common.midi.pc")
+	case "sysex":
+		return i18n.T("common.midi.sysex")
+	default:
+		return i18n.T("common.midi.note_on")
+	}
+}
+
+// midiMsgTypeFromLabel returns the stored MIDI message type for a display name
+func midiMsgTypeFromLabel(label string) string {
+	switch label {
+	case i18n.T("common.midi.note_on"):
+		return "note_on"
+	case i18n.T("common.midi.note_off"):
+		return "note_off"
+	case i18n.T("common.midi.cc"):
+		return "cc"
+	case i18n.T("common.midi.pc"):
+		return "pc"
+	case i18n.T("common.midi.sysex"):
+		return "sysex"
+	}
+	return ""
+}
+
 func (mw *MainWindow) addActionGroup() {
 	entry := widget.NewEntry()
-	entry.SetPlaceHolder("Group Name")
-	entry.SetText("New Group")
+	entry.SetPlaceHolder(i18n.T("common.group_name"))
+	entry.SetText(i18n.T("actions.new_group"))
 
-	dialog.ShowCustomConfirm("Create Action Group", "Create", "Cancel",
-		container.NewVBox(widget.NewLabel("Enter a name for the group:"), entry),
+	dialog.ShowCustomConfirm(i18n.T("actions.create_group_title"), i18n.T("common.create"), i18n.T("common.cancel"),
+		container.NewVBox(widget.NewLabel(i18n.T("actions.enter_group_name")), entry),
 		func(confirm bool) {
 			if confirm && entry.Text != "" {
 				group := actions.NewActionGroup(entry.Text)
@@ -666,11 +1052,11 @@ func (mw *MainWindow) addActionGroup() {
 
 func (mw *MainWindow) addAction() {
 	entry := widget.NewEntry()
-	entry.SetPlaceHolder("Action Name")
-	entry.SetText("New Action")
+	entry.SetPlaceHolder(i18n.T("common.action_name"))
+	entry.SetText(i18n.T("actions.new_action"))
 
-	dialog.ShowCustomConfirm("Create Action", "Create", "Cancel",
-		container.NewVBox(widget.NewLabel("Enter a name for the action:"), entry),
+	dialog.ShowCustomConfirm(i18n.T("actions.create_action_title"), i18n.T("common.create"), i18n.T("common.cancel"),
+		container.NewVBox(widget.NewLabel(i18n.T("actions.enter_action_name")), entry),
 		func(confirm bool) {
 			if confirm && entry.Text != "" {
 				action := actions.NewAction(entry.Text, actions.ActionTypeShellCommand)
@@ -687,28 +1073,80 @@ func (mw *MainWindow) addAction() {
 
 func (mw *MainWindow) deleteSelectedActionItem() {
 	if mw.selectedAction != nil {
-		dialog.ShowConfirm("Delete Action", "Are you sure you want to delete '"+mw.selectedAction.Name+"'?",
+		id := mw.selectedAction.ID
+		dialog.ShowConfirm(i18n.T("actions.delete_action_title"), i18n.T("common.confirm_delete", mw.selectedAction.Name),
 			func(confirm bool) {
 				if confirm {
-					mw.actionStore.RemoveAction(mw.selectedAction.ID)
-					mw.selectedAction = nil
-					mw.actionList.Refresh()
-					mw.updateActionEditor()
+					mw.confirmActionReferences([]string{id}, func() {
+						mw.actionStore.RemoveAction(id)
+						mw.selectedAction = nil
+						mw.actionList.Refresh()
+						mw.updateActionEditor()
+						mw.notifyTray()
+					})
 				}
 			}, mw.window)
 	} else if mw.selectedGroup != nil {
-		dialog.ShowConfirm("Delete Group", "Are you sure you want to delete '"+mw.selectedGroup.Name+"' and all its contents?",
+		id := mw.selectedGroup.ID
+		dialog.ShowConfirm(i18n.T("actions.delete_group_title"), i18n.T("actions.confirm_delete_group", mw.selectedGroup.Name),
 			func(confirm bool) {
 				if confirm {
-					mw.actionStore.RemoveGroup(mw.selectedGroup.ID)
-					mw.selectedGroup = nil
-					mw.actionList.Refresh()
-					mw.updateActionEditor()
+					mw.confirmActionReferences(mw.actionStore.GroupContents(id), func() {
+						mw.actionStore.RemoveGroup(id)
+						mw.selectedGroup = nil
+						mw.actionList.Refresh()
+						mw.updateActionEditor()
+						mw.notifyTray()
+					})
 				}
 			}, mw.window)
 	}
 }
 
+// confirmActionReferences runs remove once nothing triggers the given actions anymore.
+// If pads or message mappings do, it lists them and offers to unbind them first.
+func (mw *MainWindow) confirmActionReferences(ids []string, remove func()) {
+	refs := mw.cfg.FindActionReferences(ids...)
+	if len(refs) == 0 {
+		remove()
+		return
+	}
+
+	lines := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		lines = append(lines, "• "+actionReferenceLabel(ref))
+	}
+	scroll := container.NewVScroll(widget.NewLabel(strings.Join(lines, "\n")))
+	scroll.SetMinSize(fyne.NewSize(360, 140))
+
+	content := container.NewBorder(widget.NewLabel(i18n.T("actions.references_intro", len(refs))), nil, nil, nil, scroll)
+	dialog.ShowCustomConfirm(i18n.T("actions.references_title"), i18n.T("actions.references_clear"), i18n.T("actions.references_cancel"),
+		content, func(clear bool) {
+			if !clear {
+				return
+			}
+			mw.cfg.ClearActionReferences(ids...)
+			remove()
+			mw.refreshGrid()
+			mw.updatePadActionSelection()
+			mw.updatePadArgsEntry()
+			mw.mappingList.Refresh()
+			mw.setDirty(true)
+		}, mw.window)
+}
+
+// actionReferenceLabel describes where a pad or message mapping is, for listing it
+func actionReferenceLabel(ref config.ActionReference) string {
+	switch {
+	case ref.MenuID == "":
+		return i18n.T("actions.reference_mapping", ref.MappingName)
+	case ref.Area == config.PadAreaGrid:
+		return i18n.T("actions.reference_pad", ref.MenuName, ref.Row, ref.Col)
+	default:
+		return i18n.T("actions.reference_pro_pad", ref.MenuName, string(ref.Area), max(ref.Row, ref.Col))
+	}
+}
+
 func (mw *MainWindow) moveSelectedActionUp() {
 	if mw.selectedAction != nil {
 		mw.actionStore.MoveActionUp(mw.selectedAction.ID)
@@ -730,67 +1168,268 @@ func (mw *MainWindow) moveSelectedActionDown() {
 }
 
 func (mw *MainWindow) testAction() {
+	if mw.selectedGroup != nil {
+		mw.testGroup()
+		return
+	}
 	if mw.selectedAction == nil {
-		mw.actionFeedback.SetText("No action selected")
+		mw.actionFeedback.SetText(i18n.T("common.no_action_selected"))
 		return
 	}
 
-	mw.actionFeedback.SetText("Running...")
+	// Testing always asks first, whether or not the action is allowed to run from pads
+	action := *mw.selectedAction
+	if matches := actions.DangerMatches(&action, mw.cfg.DangerPatterns); len(matches) > 0 {
+		dialog.ShowConfirm(i18n.T("actions.dangerous_title"), i18n.T("actions.dangerous_test", action.Name, matches[0]), func(confirm bool) {
+			if confirm {
+				mw.runActionTest(action)
+			}
+		}, mw.window)
+		return
+	}
+	mw.runActionTest(action)
+}
 
-	// Use RunAction instead of direct Executor call to test async/group logic?
-	// The RunAction method is simpler, but for "Test" button usually we want feedback.
-	// Let's keep direct execution for Feedback, but maybe respect WaitForCompletion/Sleep?
-	// Sleep already blocks, so running in goroutine is good.
+// runActionTest runs an action and shows its result in the editor. Actions that report
+// progress (sleeps) show the time left and can be stopped.
+func (mw *MainWindow) runActionTest(action actions.Action) {
+	if mw.testStop != nil {
+		return
+	}
+	mw.actionFeedback.SetText(i18n.T("actions.running"))
 
+	stop := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
-		output, err := mw.executor.Execute(mw.selectedAction)
-		if err != nil {
-			mw.actionFeedback.SetText("Error: " + err.Error())
-		} else if output != "" {
-			mw.actionFeedback.SetText("Output: " + output)
-		} else {
-			mw.actionFeedback.SetText("Success (no output)")
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
 		}
 	}()
+	ctx = actions.WithProgress(ctx, func(p actions.Progress) {
+		fyne.Do(func() {
+			if mw.testStop == stop {
+				mw.actionFeedback.SetText(i18n.T("actions.running") + " " + remainingText(p.Remaining))
+			}
+		})
+	})
+	mw.testStop = stop
+	mw.stopTestBtn.Enable()
+	mw.stopTestBtn.Show()
+
+	// Run off the UI thread; sleeps and scripts can take a while
+	go func() {
+		output, err := mw.executor.ExecuteContext(ctx, &action)
+		cancel()
+		fyne.Do(func() {
+			if mw.testStop == stop || mw.testStop == nil { // Not if another test started after stopping
+				mw.testStop = nil
+				mw.stopTestBtn.Hide()
+			}
+			mw.actionFeedback.SetText("")
+			mw.actionFeedback.AddResult(action.ID, newActionResult(&action, testResultText(&action, output, err)))
+		})
+	}()
+}
+
+// testResultText describes how a test run of an action went
+func testResultText(action *actions.Action, output string, err error) string {
+	var result string
+	switch {
+	case errors.Is(err, context.Canceled):
+		result = i18n.T("actions.test_stopped")
+	case err != nil:
+		result = i18n.T("actions.test_error", err)
+	case output != "":
+		result = i18n.T("actions.test_output", output)
+	default:
+		result = i18n.T("actions.success_no_output")
+	}
+	if action.RequireConfirmation {
+		result += "\n" + i18n.T("actions.test_unconfirmed")
+	}
+	return result
+}
+
+// remainingText shows how long a running action has left, in whole seconds rounded up
+func remainingText(remaining time.Duration) string {
+	return i18n.T("actions.remaining", int((remaining+time.Second-1)/time.Second))
 }
 
 func (mw *MainWindow) validateAction() {
 	if mw.selectedAction == nil {
-		mw.actionFeedback.SetText("No action selected")
+		mw.actionFeedback.SetText(i18n.T("common.no_action_selected"))
 		return
 	}
 
-	var err error
-	switch mw.selectedAction.Type {
+	mw.actionFeedback.SetText(i18n.T("actions.validating"))
+
+	// Off the UI thread; a shell's syntax check starts a process
+	action := *mw.selectedAction
+	go func() {
+		result := i18n.T("actions.valid_syntax")
+		if err := mw.checkSyntax(&action); err != nil {
+			result = i18n.T("actions.validation_error", err)
+		}
+		fyne.Do(func() {
+			mw.actionFeedback.SetText("")
+			mw.actionFeedback.AddResult(action.ID, newActionResult(&action, result))
+		})
+	}()
+}
+
+// checkSyntax checks an action's code without running it
+func (mw *MainWindow) checkSyntax(action *actions.Action) error {
+	switch action.Type {
 	case actions.ActionTypeAppleScript:
-		err = mw.executor.ValidateAppleScript(mw.selectedAction.Code)
+		return mw.executor.ValidateAppleScript(action.Code)
 	case actions.ActionTypeShellCommand:
-		err = mw.executor.ValidateShellCommand(mw.selectedAction.Code)
+		return mw.executor.ValidateShellCommand(action.Code)
 	case actions.ActionTypeSleep:
 		// Basic number check
-		_, e := strconv.ParseFloat(mw.selectedAction.Code, 64)
-		err = e
+		_, err := strconv.ParseFloat(action.Code, 64)
+		return err
 	case actions.ActionTypeMidi:
 		// Check JSON validity
 		var data actions.MidiActionData
-		err = json.Unmarshal([]byte(mw.selectedAction.Code), &data)
+		return json.Unmarshal([]byte(action.Code), &data)
+	default:
+		return mw.executor.Validate(action.Type, action.Code)
 	}
+}
 
-	if err != nil {
-		mw.actionFeedback.SetText("Validation error: " + err.Error())
-	} else {
-		mw.actionFeedback.SetText("✓ Valid syntax")
-	}
+// newActionResult is a result about an action, for the Actions tab's output
+func newActionResult(action *actions.Action, text string) actionResult {
+	return actionResult{text: text, name: action.Name, actionType: actionTypeLabel(action.Type), code: action.Code}
 }
 
 func (mw *MainWindow) saveActions() {
+	// Actions that became dangerous since the last save are blocked from pads unless allowed
+	saved := map[string]string{}
+	if savedCfg, err := config.Load(); err == nil {
+		for _, a := range savedCfg.Actions {
+			saved[a.ID] = a.Code
+		}
+	}
+	var flagged, names []string
+	for _, a := range mw.actionStore.Actions {
+		if code, ok := saved[a.ID]; !a.AllowDangerous && (!ok || code != a.Code) && len(actions.DangerMatches(&a, mw.cfg.DangerPatterns)) > 0 {
+			flagged = append(flagged, a.ID)
+			names = append(names, "• "+a.Name)
+		}
+	}
+	if len(flagged) == 0 {
+		mw.doSaveActions()
+		return
+	}
+
+	message := i18n.T("actions.dangerous_save", strings.Join(names, "\n"))
+	dialog.ShowCustomConfirm(i18n.T("actions.dangerous_title"), i18n.T("actions.dangerous_allow"), i18n.T("actions.dangerous_keep_blocked"),
+		widget.NewLabel(message), func(allow bool) {
+			if allow {
+				for i := range mw.actionStore.Actions {
+					if slices.Contains(flagged, mw.actionStore.Actions[i].ID) {
+						mw.actionStore.Actions[i].AllowDangerous = true
+					}
+				}
+				if mw.selectedAction != nil && slices.Contains(flagged, mw.selectedAction.ID) {
+					mw.selectedAction.AllowDangerous = true
+					setCheckedSilently(mw.allowDangerousCheck, true)
+				}
+			}
+			mw.doSaveActions()
+		}, mw.window)
+}
+
+// doSaveActions writes the actions to disk, unbinding there whatever ran one that is
+// gone, without also saving other unsaved edits
+func (mw *MainWindow) doSaveActions() {
 	mw.cfg.SyncActionStore(mw.actionStore)
-	if err := mw.cfg.Save(); err != nil {
-		log.Printf("Failed to save actions: %v", err)
+	err := config.Update(func(saved *config.Config) {
+		saved.SyncActionStore(mw.actionStore)
+		var gone []string
+		for _, ref := range saved.OrphanedActionReferences() {
+			gone = append(gone, ref.ActionID)
+		}
+		saved.ClearActionReferences(gone...)
+	})
+	if err != nil {
+		slog.Error("Failed to save actions", "err", err)
 		dialog.ShowError(err, mw.window)
 	} else {
+		mw.actionStore.MarkSaved()
 		// Refresh the action dropdown in the Menu Editor
 		mw.refreshPadActionOptions()
-		dialog.ShowInformation("Saved", "Actions saved successfully.", mw.window)
+		dialog.ShowInformation(i18n.T("common.saved"), i18n.T("actions.saved"), mw.window)
+	}
+}
+
+// revertActions drops unsaved action edits by reloading actions and groups from disk
+func (mw *MainWindow) revertActions() {
+	savedCfg, err := config.Load()
+	if err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	mw.actionStore.Replace(savedCfg.Actions, savedCfg.ActionGroups)
+	mw.cfg.SyncActionStore(mw.actionStore)
+
+	mw.selectedAction, mw.selectedGroup = nil, nil
+	mw.actionList.UnselectAll()
+	mw.actionList.Refresh()
+	mw.updateActionEditor()
+	mw.refreshPadActionOptions()
+	mw.notifyTray()
+}
+
+// updateActionsDirty shows whether action edits are unsaved, next to Save and in the titles
+func (mw *MainWindow) updateActionsDirty() {
+	if mw.actionsTab == nil {
+		return // Still building the UI
+	}
+	dirty := mw.actionStore.Dirty()
+	title := i18n.T("common.actions")
+	if dirty {
+		title += dirtyMarker
+		mw.actionsRevertBtn.Enable()
+		mw.actionsUnsaved.Show()
+	} else {
+		mw.actionsRevertBtn.Disable()
+		mw.actionsUnsaved.Hide()
+	}
+	if mw.actionsTab.Text != title {
+		mw.actionsTab.Text = title
+		mw.tabs.Refresh()
+		mw.updateWindowTitle()
+	}
+}
+
+// confirmDiscardActions calls proceed, asking first if action edits are unsaved
+func (mw *MainWindow) confirmDiscardActions(proceed func()) {
+	if !mw.actionStore.Dirty() {
+		proceed()
+		return
+	}
+	mw.Show()
+	mw.confirmDiscard(i18n.T("actions.unsaved_lost"), proceed, nil)
+}
+
+// ConfirmQuit calls quit, asking first if layout or action edits are unsaved
+func (mw *MainWindow) ConfirmQuit(quit func()) {
+	if !mw.dirty && !mw.actionStore.Dirty() {
+		quit()
+		return
+	}
+	mw.Show()
+	mw.confirmDiscard(i18n.T("common.unsaved_quit"), quit, nil)
+}
+
+// parseCooldown reads the cooldown entry: whole seconds, empty for none
+func parseCooldown(s string) (int, bool) {
+	if s = strings.TrimSpace(s); s == "" {
+		return 0, true
 	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n >= 0
 }
@@ -0,0 +1,257 @@
+package window
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// selectNewAction adds an action to the store and selects it in the actions tab
+func selectNewAction(t *testing.T, mw *MainWindow, action actions.Action) *actions.Action {
+	t.Helper()
+	mw.actionStore.AddAction(&action)
+	mw.actionList.Refresh()
+	mw.selectActionListItem(action.ID)
+	if mw.selectedAction == nil || mw.selectedAction.ID != action.ID {
+		t.Fatalf("action %s not selected", action.ID)
+	}
+	return mw.selectedAction
+}
+
+// midiCode decodes a MIDI action's stored code
+func midiCode(t *testing.T, action *actions.Action) actions.MidiActionData {
+	t.Helper()
+	var data actions.MidiActionData
+	if err := json.Unmarshal([]byte(action.Code), &data); err != nil {
+		t.Fatalf("code %q: %v", action.Code, err)
+	}
+	return data
+}
+
+func TestMidiEditorKeepsValuesWhileTyping(t *testing.T) {
+	mw := newTestWindow(t)
+	action := selectNewAction(t, mw, actions.Action{ID: "midi", Name: "Note", Type: actions.ActionTypeMidi,
+		Code: `{"device_name":"Synth","msg_type":"note_on","channel":2,"note":60,"velocity":100,"program":0,"sysex":""}`})
+
+	// Retyping the note passes through an empty field, which stores nothing
+	mw.midiNoteEntry.SetText("6")
+	mw.midiNoteEntry.SetText("")
+	if got := midiCode(t, action).Note; got != 6 {
+		t.Errorf("note while the field is empty = %d, want 6", got)
+	}
+	mw.midiNoteEntry.SetText("64")
+
+	// Out of range values show why and aren't stored
+	mw.midiVelocityEntry.SetText("200")
+	if mw.actionFeedback.status.Text == "" {
+		t.Error("no message for velocity 200")
+	}
+	mw.midiVelocityEntry.SetText("90")
+	if mw.actionFeedback.status.Text != "" {
+		t.Errorf("message %q left after a valid velocity", mw.actionFeedback.status.Text)
+	}
+
+	// A round trip through program change keeps the note fields, and its program
+	mw.midiMsgTypeSelect.SetSelected(i18n.T("common.midi.pc"))
+	mw.midiProgramEntry.SetText("12")
+	mw.midiMsgTypeSelect.SetSelected(i18n.T("common.midi.note_on"))
+
+	want := `{"device_name":"Synth","msg_type":"note_on","channel":2,"note":64,"velocity":90,"program":12,"sysex":""}`
+	if action.Code != want {
+		t.Errorf("code = %s\nwant   %s", action.Code, want)
+	}
+}
+
+func TestMidiEditorDefaultsForNewAction(t *testing.T) {
+	mw := newTestWindow(t)
+	action := selectNewAction(t, mw, actions.Action{ID: "midi", Name: "New", Type: actions.ActionTypeMidi})
+
+	// Nothing is stored until something is edited, and then the defaults shown are
+	if action.Code != "" {
+		t.Errorf("code = %q before any edit, want none", action.Code)
+	}
+	mw.midiDeviceSelect.Options = append(mw.midiDeviceSelect.Options, "Synth")
+	mw.midiDeviceSelect.SetSelected("Synth")
+	want := actions.MidiActionData{DeviceName: "Synth", MsgType: "note_on", Channel: 1}
+	if got := midiCode(t, action); got != want {
+		t.Errorf("code = %+v, want %+v", got, want)
+	}
+}
+
+// referencedAction adds an action bound to pad 2,3 of the current layout and to a
+// message mapping, and selects it
+func referencedAction(t *testing.T, mw *MainWindow) {
+	t.Helper()
+	selectNewAction(t, mw, actions.Action{ID: "used", Name: "Used", Type: actions.ActionTypeSleep, Code: "1"})
+	mw.cfg.GetCurrentMenu().Colors[2][3].ActionID = "used"
+	mapping := config.NewMessageMapping()
+	mapping.ActionID = "used"
+	mw.cfg.MessageMappings = append(mw.cfg.MessageMappings, mapping)
+}
+
+func TestDeleteActionClearsReferences(t *testing.T) {
+	mw := newTestWindow(t)
+	referencedAction(t, mw)
+
+	mw.deleteSelectedActionItem()
+	tapButton(t, mw, "Yes")
+	tapButton(t, mw, "Clear references")
+
+	if mw.actionStore.GetAction("used") != nil {
+		t.Error("action not deleted")
+	}
+	if refs := mw.cfg.FindActionReferences("used"); len(refs) != 0 {
+		t.Errorf("references left: %+v", refs)
+	}
+}
+
+func TestDeleteActionCancelKeepsReferences(t *testing.T) {
+	mw := newTestWindow(t)
+	referencedAction(t, mw)
+
+	mw.deleteSelectedActionItem()
+	tapButton(t, mw, "Yes")
+	tapButton(t, mw, "Cancel deletion")
+
+	if mw.actionStore.GetAction("used") == nil {
+		t.Error("action deleted after cancelling")
+	}
+	if refs := mw.cfg.FindActionReferences("used"); len(refs) != 2 {
+		t.Errorf("%d references, want both kept", len(refs))
+	}
+}
+
+// TestSaveActionsLeavesOtherEditsUnsaved checks that saving the actions also unbinds on
+// disk what ran a deleted one, but doesn't save the layout edits pending
+func TestSaveActionsLeavesOtherEditsUnsaved(t *testing.T) {
+	mw := newTestWindow(t)
+	referencedAction(t, mw)
+	if err := mw.cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	mw.cfg.GetCurrentMenu().Colors[1][1].R = 99
+
+	mw.deleteSelectedActionItem()
+	tapButton(t, mw, "Yes")
+	tapButton(t, mw, "Clear references")
+	mw.doSaveActions()
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.GetAction("used") != nil {
+		t.Error("deleted action still saved")
+	}
+	if refs := saved.FindActionReferences("used"); len(refs) != 0 {
+		t.Errorf("references saved: %+v", refs)
+	}
+	if saved.GetCurrentMenu().Colors[1][1].R == 99 {
+		t.Error("unsaved layout edit saved with the actions")
+	}
+}
+
+// TestTestButtonConfirmsDangerous checks that testing an action matching a danger
+// pattern asks first, even when it is allowed to run from pads
+func TestTestButtonConfirmsDangerous(t *testing.T) {
+	mw := newTestWindow(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	selectNewAction(t, mw, actions.Action{ID: "danger", Name: "Danger", Type: actions.ActionTypeShellCommand,
+		Code: ": shutdown; touch " + marker, AllowDangerous: true})
+
+	mw.testAction()
+	tapButton(t, mw, "No")
+	if got := mw.actionFeedback.status.Text; got == i18n.T("actions.running") {
+		t.Error("action started after declining")
+	}
+
+	mw.testAction()
+	tapButton(t, mw, "Yes")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("action didn't run after confirming")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond) // Let the result reach the editor before the window closes
+}
+
+// actionTypeForm is a contributed type the Actions tab edits with a form built from its fields
+const actionTypeForm actions.ActionType = "window_test_form"
+
+func init() {
+	actions.RegisterHandler(actionTypeForm, actions.HandlerMeta{Name: "Form", Fields: []actions.Field{
+		{Key: "title", Label: "Title", Kind: actions.FieldText},
+		{Key: "count", Label: "Count", Kind: actions.FieldNumber, Default: "3"},
+		{Key: "queue", Label: "Queue", Kind: actions.FieldSelect, Options: []string{"ops", "dev"}, Default: "ops"},
+	}}, func(actions.Deps) actions.ActionHandler { return &actions.SleepHandler{} })
+}
+
+func TestFieldsEditorForRegisteredType(t *testing.T) {
+	mw := newTestWindow(t)
+	action := selectNewAction(t, mw, actions.Action{ID: "form", Name: "Form", Type: actionTypeForm,
+		Code: `{"title":"Deploy"}`})
+
+	var form *widget.Form
+	for _, obj := range mw.actionEditorContent.Objects {
+		if f, ok := obj.(*widget.Form); ok {
+			form = f
+		}
+	}
+	if form == nil || len(form.Items) != 3 {
+		t.Fatalf("no form with the 3 registered fields: %v", form)
+	}
+	title, count := form.Items[0].Widget.(*widget.Entry), form.Items[1].Widget.(*widget.Entry)
+	queue := form.Items[2].Widget.(*widget.Select)
+	if title.Text != "Deploy" || count.Text != "3" || queue.Selected != "ops" {
+		t.Errorf("form shows %q, %q, %q; want the stored title and the defaults", title.Text, count.Text, queue.Selected)
+	}
+
+	count.SetText("x") // Not a number: not stored
+	count.SetText("5")
+	queue.SetSelected("dev")
+	values, err := actions.DecodeFields(action.Code, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["title"] != "Deploy" || values["count"] != "5" || values["queue"] != "dev" {
+		t.Errorf("stored %v", values)
+	}
+	if count.SetText("x"); count.Validate() == nil {
+		t.Error("a number field accepted text")
+	}
+}
+
+// TestTestResultNotesSkippedConfirmation checks that testing an action needing
+// confirmation says it ran without it, whatever the outcome
+func TestTestResultNotesSkippedConfirmation(t *testing.T) {
+	careful := &actions.Action{Name: "Careful", RequireConfirmation: true}
+	plain := &actions.Action{Name: "Plain"}
+	note := i18n.T("actions.test_unconfirmed")
+	for _, err := range []error{nil, errors.New("failed"), context.Canceled} {
+		if got := testResultText(careful, "out", err); !strings.HasSuffix(got, "\n"+note) {
+			t.Errorf("result %q (err %v) doesn't note the skipped confirmation", got, err)
+		}
+		if got := testResultText(plain, "out", err); strings.Contains(got, note) {
+			t.Errorf("result %q notes a confirmation the action doesn't need", got)
+		}
+	}
+	if got, want := testResultText(careful, "", nil), i18n.T("actions.success_no_output")+"\n"+note; got != want {
+		t.Errorf("result = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package window
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// padPattern is a pattern drawn over a pad in the menu editor, one per hue, so pads can
+// be told apart without telling their colors apart
+type padPattern uint8
+
+const (
+	patternNone       padPattern = iota
+	patternDiagonal              // Red
+	patternHorizontal            // Yellow
+	patternDots                  // Green
+	patternGrid                  // Cyan
+	patternVertical              // Blue
+	patternBackslash             // Magenta
+)
+
+// hueCodePatterns are the patterns of padcolor.HueCodes; white has none
+var hueCodePatterns = map[string]padPattern{
+	"R": patternDiagonal,
+	"Y": patternHorizontal,
+	"G": patternDots,
+	"C": patternGrid,
+	"B": patternVertical,
+	"M": patternBackslash,
+}
+
+// covers reports whether the pattern inks a pixel, repeating every period pixels
+func (p padPattern) covers(x, y, period int) bool {
+	line := max(period/3, 1)
+	switch p {
+	case patternDiagonal:
+		return (x+y)%period < line
+	case patternHorizontal:
+		return y%period < line
+	case patternDots:
+		return x%period < period/2 && y%period < period/2
+	case patternGrid:
+		return x%period < line || y%period < line
+	case patternVertical:
+		return x%period < line
+	case patternBackslash:
+		return (x-y+period*1000)%period < line
+	}
+	return false
+}
+
+// maxSymbolRunes is how much of a pad's symbol fits on the smallest pads
+const maxSymbolRunes = 2
+
+// createAnnotationChecks returns the checks that label the grid's pads with color codes
+// and draw hue patterns over them
+func (mw *MainWindow) createAnnotationChecks() (codes, patterns *widget.Check) {
+	codes = widget.NewCheck(i18n.T("menu_editor.color_codes"), func(checked bool) {
+		mw.cfg.PadColorCodes = checked
+		mw.refreshGrid()
+		mw.savePreferences()
+	})
+	codes.Checked = mw.cfg.PadColorCodes
+	patterns = widget.NewCheck(i18n.T("menu_editor.pattern_fills"), func(checked bool) {
+		mw.cfg.PatternFills = checked
+		mw.refreshGrid()
+		mw.savePreferences()
+	})
+	patterns.Checked = mw.cfg.PatternFills
+	return codes, patterns
+}
+
+// updatePadAnnotation labels a pad with its symbol or the hue code of its static color
+// and draws the hue's pattern over it, as the preferences ask. Both follow the pad's
+// own color, so the heat map shows codes but no patterns.
+func (mw *MainWindow) updatePadAnnotation(row, col int) {
+	pad := mw.gridPads[row][col]
+	menu := mw.cfg.GetCurrentMenu()
+	if pad == nil || menu == nil {
+		return
+	}
+	c := menu.EffectiveColor(row, col)
+	hue := padcolor.HueCode(c.R, c.G, c.B)
+	ink := contrastingColor(mw.gridRects[row][col].FillColor)
+
+	code := ""
+	if mw.cfg.PadColorCodes {
+		code = hue
+		if symbol := []rune(menu.Colors[row][col].Symbol); len(symbol) > 0 {
+			code = string(symbol[:min(len(symbol), maxSymbolRunes)])
+		}
+	}
+	pad.SetCode(code, ink)
+
+	pattern := patternNone
+	if mw.cfg.PatternFills && !mw.heatMap {
+		pattern = hueCodePatterns[hue]
+	}
+	r, g, b, _ := ink.RGBA()
+	pad.SetPattern(pattern, color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 140})
+}
+
+// contrastingColor returns black or white, whichever reads better on c
+func contrastingColor(c color.Color) color.Color {
+	r, g, b, _ := c.RGBA()
+	if (299*r+587*g+114*b)/1000 > 0x7FFF {
+		return color.Black
+	}
+	return color.White
+}
@@ -0,0 +1,97 @@
+package window
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// showAssignLayout opens a checklist of devices to show the current layout as their main
+// menu. Devices already showing it start checked; unchecking one leaves it without a menu.
+func (mw *MainWindow) showAssignLayout() {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+
+	checks := map[string]*widget.Check{}
+	list := container.NewVBox()
+	for _, device := range mw.cfg.Devices {
+		if device.Type == config.DeviceTypeGeneric {
+			continue // Generic devices have no pads to show a layout on
+		}
+		check := widget.NewCheck(device.Name, nil)
+		check.Checked = device.MainMenu == menu.Name
+		var reason string
+		switch {
+		case device.OutPort == "":
+			reason = i18n.T("assign_layout.no_output")
+		case device.HasPages():
+			reason = i18n.T("assign_layout.uses_pages")
+		}
+		if reason != "" {
+			check.Disable()
+			note := widget.NewLabelWithStyle(reason, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+			list.Add(container.NewHBox(check, note))
+			continue
+		}
+		checks[device.ID] = check
+		list.Add(check)
+	}
+	if len(list.Objects) == 0 {
+		dialog.ShowInformation(i18n.T("assign_layout.title", menu.Name), i18n.T("assign_layout.no_devices"), mw.window)
+		return
+	}
+
+	content := container.NewBorder(widget.NewLabel(i18n.T("assign_layout.hint", menu.Name)), nil, nil, nil, container.NewVScroll(list))
+	dlg := dialog.NewCustomConfirm(i18n.T("assign_layout.title", menu.Name), i18n.T("assign_layout.assign"), i18n.T("common.cancel"), content, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		assigned := map[string]string{}
+		for id, check := range checks {
+			device := mw.cfg.GetDevice(id)
+			switch {
+			case check.Checked && device.MainMenu != menu.Name:
+				assigned[id] = menu.Name
+			case !check.Checked && device.MainMenu == menu.Name:
+				assigned[id] = ""
+			}
+		}
+		if err := mw.assignMainMenus(assigned); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	}, mw.window)
+	dlg.Resize(fyne.NewSize(420, 360))
+	dlg.Show()
+}
+
+// assignMainMenus sets the main menu of each device by ID, saves just those assignments
+// and shows each device its new menu
+func (mw *MainWindow) assignMainMenus(assigned map[string]string) error {
+	if len(assigned) == 0 {
+		return nil
+	}
+	assign := func(cfg *config.Config) {
+		for id, menuName := range assigned {
+			if device := cfg.GetDevice(id); device != nil {
+				device.MainMenu = menuName
+				device.LastMenu = "" // A page remembered from the old menu would hide the new one
+			}
+		}
+	}
+	assign(mw.cfg)
+	if err := config.Update(assign); err != nil {
+		return err
+	}
+
+	for id := range assigned {
+		mw.engine.ResetDeviceMenu(id)
+	}
+	mw.deviceList.Refresh()
+	mw.publishDeviceStatus()
+	return nil
+}
@@ -0,0 +1,152 @@
+package window
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// bulkAssignment is one pad a bulk assignment changes
+type bulkAssignment struct {
+	row, col int
+	args     string
+}
+
+// bulkAssignments lists the pads of the rectangle between two corners in row-major
+// order, with the argument template expanded for each
+func bulkAssignments(row1, col1, row2, col2 int, template string) []bulkAssignment {
+	var pads []bulkAssignment
+	for row := min(row1, row2); row <= max(row1, row2); row++ {
+		for col := min(col1, col2); col <= max(col1, col2); col++ {
+			args := config.ExpandPadArgs(template, row, col, len(pads)+1)
+			pads = append(pads, bulkAssignment{row: row, col: col, args: args})
+		}
+	}
+	return pads
+}
+
+// showBulkAssignDialog assigns one action to a rectangle of pads in the current layout,
+// each with its own arguments from a template. The change can be undone in one step.
+func (mw *MainWindow) showBulkAssignDialog() {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+
+	indices := make([]string, 9)
+	for i := range indices {
+		indices[i] = strconv.Itoa(i)
+	}
+	cornerSelects := func(row, col int) (*widget.Select, *widget.Select) {
+		rowSelect, colSelect := widget.NewSelect(indices, nil), widget.NewSelect(indices, nil)
+		rowSelect.SetSelected(indices[row])
+		colSelect.SetSelected(indices[col])
+		return rowSelect, colSelect
+	}
+	fromRow, fromCol := cornerSelects(mw.selectedRow, mw.selectedCol)
+	toRow, toCol := cornerSelects(mw.selectedRow, mw.selectedCol)
+
+	picker := mw.newActionPicker(nil)
+
+	template := widget.NewEntry()
+	template.SetPlaceHolder("{{pad.row}}-{{pad.col}}")
+
+	preview := widget.NewLabel("")
+	preview.Wrapping = fyne.TextWrapWord
+	previewScroll := container.NewVScroll(preview)
+	previewScroll.SetMinSize(fyne.NewSize(380, 160))
+
+	selected := func(s *widget.Select) int { return max(slices.Index(indices, s.Selected), 0) }
+	pads := func() []bulkAssignment {
+		return bulkAssignments(selected(fromRow), selected(fromCol), selected(toRow), selected(toCol), template.Text)
+	}
+	updatePreview := func() {
+		targets := pads()
+		lines := []string{i18n.T("menu_editor.bulk_preview_count", len(targets))}
+		for _, pad := range targets {
+			line := i18n.T("actions.reference_pad", menu.Name, pad.row, pad.col)
+			if pad.args != "" {
+				line += " – " + pad.args
+			}
+			if current := menu.Colors[pad.row][pad.col].ActionID; current != "" {
+				if action := mw.cfg.GetAction(current); action != nil {
+					line += " " + i18n.T("menu_editor.bulk_replaces", action.Name)
+				}
+			}
+			lines = append(lines, "• "+line)
+		}
+		preview.SetText(strings.Join(lines, "\n"))
+	}
+	for _, s := range []*widget.Select{fromRow, fromCol, toRow, toCol} {
+		s.OnChanged = func(string) { updatePreview() }
+	}
+	picker.OnChanged = func(string) { updatePreview() }
+	template.OnChanged = func(string) { updatePreview() }
+	updatePreview()
+
+	corner := func(row, col *widget.Select) fyne.CanvasObject {
+		return container.NewHBox(widget.NewLabel(i18n.T("common.row")), row, widget.NewLabel(i18n.T("common.col")), col)
+	}
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("menu_editor.bulk_from"), corner(fromRow, fromCol)),
+		widget.NewFormItem(i18n.T("menu_editor.bulk_to"), corner(toRow, toCol)),
+		widget.NewFormItem(i18n.T("common.action"), picker),
+		widget.NewFormItem(i18n.T("menu_editor.action_args"), template),
+	)
+	hint := widget.NewLabel(i18n.T("menu_editor.bulk_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(container.NewVBox(form, hint), nil, nil, nil, previewScroll)
+	dlg := dialog.NewCustomConfirm(i18n.T("menu_editor.bulk_assign"), i18n.T("menu_editor.bulk_apply"), i18n.T("common.cancel"), content,
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+			actionID := picker.Selected()
+			if actionID == "" {
+				dialog.ShowError(errors.New(i18n.T("menu_editor.bulk_no_action")), mw.window)
+				return
+			}
+			mw.applyBulkAssignment(menu, actionID, pads())
+		}, mw.window)
+	dlg.Resize(fyne.NewSize(520, 520))
+	dlg.Show()
+}
+
+// applyBulkAssignment sets the action and arguments of the given pads, then offers to
+// put the pads back as they were
+func (mw *MainWindow) applyBulkAssignment(menu *config.MenuLayout, actionID string, pads []bulkAssignment) {
+	previous := make([]config.PadColorConfig, len(pads))
+	for i, pad := range pads {
+		previous[i] = menu.Colors[pad.row][pad.col]
+		menu.Colors[pad.row][pad.col].ActionID = actionID
+		menu.Colors[pad.row][pad.col].ActionArgs = pad.args
+	}
+	mw.setDirty(true)
+	mw.updatePadActionSelection()
+	mw.updatePadArgsEntry()
+
+	// The dialog is modal, so nothing else can change the pads before an undo
+	var done *dialog.CustomDialog
+	undoBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.bulk_undo"), theme.ContentUndoIcon(), func() {
+		for i, pad := range pads {
+			menu.Colors[pad.row][pad.col] = previous[i]
+		}
+		mw.setDirty(true)
+		mw.updatePadActionSelection()
+		mw.updatePadArgsEntry()
+		done.Hide()
+	})
+	content := container.NewVBox(widget.NewLabel(i18n.T("menu_editor.bulk_done", len(pads))), container.NewHBox(undoBtn))
+	done = dialog.NewCustom(i18n.T("menu_editor.bulk_assign"), i18n.T("common.close"), content, mw.window)
+	done.Show()
+}
@@ -0,0 +1,198 @@
+package window
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// codeIndent is what the Tab key inserts in the code editor
+const codeIndent = "    "
+
+// defaultCodeEditorRows is the code editor height when none is set in preferences
+const defaultCodeEditorRows = 12
+
+// codeEditorRowChoices are the code editor heights offered in preferences
+var codeEditorRowChoices = []int{8, 12, 20, 30}
+
+// ============ CODE EDITOR WIDGET ============
+
+// codeEditor is a monospace multi-line entry with a line-number gutter. It wraps a
+// widget.Entry, so undo, selection and IME input work as in any other entry.
+type codeEditor struct {
+	widget.BaseWidget
+
+	OnChanged func(string)
+
+	entry   *codeEntry
+	gutter  *widget.Label
+	lines   int               // Line count the gutter shows
+	scroll  *container.Scroll // Scrolls gutter and entry together when not wrapping
+	content *fyne.Container
+	wrap    bool
+	rows    int
+}
+
+func newCodeEditor(rows int, wrap bool) *codeEditor {
+	c := &codeEditor{rows: rows, content: container.NewStack()}
+	c.gutter = widget.NewLabel("1")
+	c.gutter.TextStyle = fyne.TextStyle{Monospace: true}
+	c.gutter.Alignment = fyne.TextAlignTrailing
+	c.gutter.Importance = widget.LowImportance
+	c.lines = 1
+	c.ExtendBaseWidget(c)
+	c.SetWrap(wrap)
+	return c
+}
+
+func (c *codeEditor) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.content)
+}
+
+// Text returns the code being edited
+func (c *codeEditor) Text() string {
+	return c.entry.Text
+}
+
+// SetText replaces the code, firing OnChanged like typing would
+func (c *codeEditor) SetText(text string) {
+	c.entry.SetText(text)
+}
+
+// SetPlaceHolder sets the text shown while the editor is empty
+func (c *codeEditor) SetPlaceHolder(text string) {
+	c.entry.SetPlaceHolder(text)
+}
+
+// SetRows sets how many lines tall the editor is
+func (c *codeEditor) SetRows(rows int) {
+	c.rows = rows
+	c.applyRows()
+}
+
+// SetWrap switches soft wrapping on or off. Wrapped lines don't line up with line
+// numbers, so the gutter is only shown while wrapping is off. The entry is rebuilt
+// because its scrolling is fixed when it is first drawn.
+func (c *codeEditor) SetWrap(wrap bool) {
+	old := c.entry
+	c.wrap = wrap
+	c.entry = newCodeEntry(wrap)
+	if old != nil {
+		c.entry.SetPlaceHolder(old.PlaceHolder)
+		c.entry.SetText(old.Text)
+	}
+	c.entry.OnChanged = func(s string) {
+		c.updateGutter(s)
+		if c.OnChanged != nil {
+			c.OnChanged(s)
+		}
+	}
+	c.entry.OnCursorChanged = c.keepCursorVisible
+
+	if wrap {
+		c.scroll = nil
+		c.content.Objects = []fyne.CanvasObject{c.entry}
+	} else {
+		c.scroll = container.NewScroll(container.NewBorder(nil, nil, c.gutter, nil, c.entry))
+		c.content.Objects = []fyne.CanvasObject{c.scroll}
+	}
+	c.updateGutter(c.entry.Text)
+	c.applyRows()
+	c.content.Refresh()
+}
+
+// applyRows sizes the visible area to the configured number of lines
+func (c *codeEditor) applyRows() {
+	if c.wrap {
+		c.entry.SetMinRowsVisible(c.rows)
+		return
+	}
+	lineHeight := fyne.MeasureText("M", theme.TextSize(), c.entry.TextStyle).Height
+	padding := theme.Padding()*2 + theme.InputBorderSize()*2
+	c.scroll.SetMinSize(fyne.NewSize(0, lineHeight*float32(c.rows)+padding))
+}
+
+// updateGutter numbers the lines of text when the line count changes
+func (c *codeEditor) updateGutter(text string) {
+	lines := strings.Count(text, "\n") + 1
+	if lines == c.lines {
+		return
+	}
+	c.lines = lines
+
+	var numbers strings.Builder
+	for i := 1; i <= lines; i++ {
+		if i > 1 {
+			numbers.WriteByte('\n')
+		}
+		numbers.WriteString(strconv.Itoa(i))
+	}
+	c.gutter.SetText(numbers.String())
+}
+
+// keepCursorVisible scrolls the editor so the cursor stays in view while typing.
+// When wrapping, the entry scrolls itself.
+func (c *codeEditor) keepCursorVisible() {
+	if c.scroll == nil {
+		return
+	}
+	lineHeight := fyne.MeasureText("M", theme.TextSize(), c.entry.TextStyle).Height
+	cursor := c.entry.Position().Add(c.entry.CursorPosition())
+	offset := c.scroll.Offset
+	view := c.scroll.Size()
+
+	switch {
+	case cursor.Y < offset.Y:
+		offset.Y = cursor.Y
+	case cursor.Y+lineHeight+theme.Padding()*2 > offset.Y+view.Height:
+		offset.Y = cursor.Y + lineHeight + theme.Padding()*2 - view.Height
+	}
+	switch {
+	case cursor.X < offset.X+c.entry.Position().X:
+		offset.X = max(cursor.X-c.entry.Position().X, 0)
+	case cursor.X+theme.Padding()*2 > offset.X+view.Width:
+		offset.X = cursor.X + theme.Padding()*2 - view.Width
+	}
+	if offset != c.scroll.Offset {
+		c.scroll.ScrollToOffset(offset)
+	}
+}
+
+// codeEntry is a monospace multi-line entry that indents with spaces on Tab
+type codeEntry struct {
+	widget.Entry
+}
+
+func newCodeEntry(wrap bool) *codeEntry {
+	e := &codeEntry{}
+	e.MultiLine = true
+	e.TextStyle = fyne.TextStyle{Monospace: true}
+	if wrap {
+		e.Wrapping = fyne.TextWrapWord
+	} else {
+		// Grow to fit the code; the editor scrolls it together with the gutter
+		e.Wrapping = fyne.TextWrapOff
+		e.Scroll = fyne.ScrollNone
+	}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// TypedKey inserts spaces for Tab. Shift+Tab inserts nothing, as in a plain entry.
+func (e *codeEntry) TypedKey(key *fyne.KeyEvent) {
+	if key.Name != fyne.KeyTab {
+		e.Entry.TypedKey(key)
+		return
+	}
+	if d, ok := fyne.CurrentApp().Driver().(desktop.Driver); ok && d.CurrentKeyModifiers()&fyne.KeyModifierShift != 0 {
+		return
+	}
+	for _, r := range codeIndent {
+		e.TypedRune(r)
+	}
+}
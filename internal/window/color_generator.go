@@ -0,0 +1,166 @@
+package window
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// colorRegion is which pads the color generator fills
+type colorRegion int
+
+const (
+	colorRegionPad colorRegion = iota
+	colorRegionRow
+	colorRegionGrid
+)
+
+// regionPads lists the pads of a region around the selected pad, in row-major order
+func regionPads(region colorRegion, row, col int) []config.PadPosition {
+	switch region {
+	case colorRegionPad:
+		return []config.PadPosition{{Row: row, Col: col}}
+	case colorRegionRow:
+		pads := make([]config.PadPosition, 9)
+		for c := range pads {
+			pads[c] = config.PadPosition{Row: row, Col: c}
+		}
+		return pads
+	default:
+		pads := make([]config.PadPosition, 0, 81)
+		for r := range 9 {
+			for c := range 9 {
+				pads = append(pads, config.PadPosition{Row: r, Col: c})
+			}
+		}
+		return pads
+	}
+}
+
+// showColorGeneratorDialog fills pads of the current layout with generated colors, the
+// classic ones derived from them. The fill can be undone in one step.
+func (mw *MainWindow) showColorGeneratorDialog() {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+
+	modes := []string{
+		i18n.T("menu_editor.generate.random"),
+		i18n.T("menu_editor.generate.wheel"),
+		i18n.T("menu_editor.generate.shades"),
+	}
+	modeSelect := widget.NewSelect(modes, nil)
+	modeSelect.SetSelected(modes[0])
+
+	regions := []string{
+		i18n.T("menu_editor.generate.region_pad"),
+		i18n.T("menu_editor.generate.region_row"),
+		i18n.T("menu_editor.generate.region_grid"),
+	}
+	regionSelect := widget.NewSelect(regions, nil)
+	regionSelect.SetSelected(regions[2])
+
+	seedEntry := widget.NewEntry()
+	seedEntry.SetPlaceHolder(i18n.T("menu_editor.generate.seed_placeholder"))
+
+	// Shades start from the selected pad's color
+	selected := menu.Colors[mw.selectedRow][mw.selectedCol]
+	base := padcolor.RGB{R: selected.R, G: selected.G, B: selected.B}
+
+	hint := widget.NewLabel("")
+	hint.Wrapping = fyne.TextWrapWord
+	updateHint := func() {
+		switch padcolor.GenerateMode(slices.Index(modes, modeSelect.Selected)) {
+		case padcolor.GenerateShades:
+			hint.SetText(i18n.T("menu_editor.generate.shades_hint"))
+			seedEntry.Disable()
+		case padcolor.GenerateWheel:
+			hint.SetText(i18n.T("menu_editor.generate.wheel_hint"))
+			seedEntry.Disable()
+		default:
+			hint.SetText(i18n.T("menu_editor.generate.random_hint"))
+			seedEntry.Enable()
+		}
+	}
+	modeSelect.OnChanged = func(string) { updateHint() }
+	updateHint()
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("menu_editor.generate.mode"), modeSelect),
+		widget.NewFormItem(i18n.T("menu_editor.generate.region"), regionSelect),
+		widget.NewFormItem(i18n.T("menu_editor.generate.seed"), seedEntry),
+	)
+	content := container.NewVBox(form, hint)
+	dlg := dialog.NewCustomConfirm(i18n.T("menu_editor.generate.title"), i18n.T("menu_editor.generate.apply"), i18n.T("common.cancel"), content,
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+			mode := padcolor.GenerateMode(slices.Index(modes, modeSelect.Selected))
+			seed := rand.Uint64()
+			if text := strings.TrimSpace(seedEntry.Text); text != "" {
+				parsed, err := strconv.ParseUint(text, 10, 64)
+				if err != nil {
+					dialog.ShowError(errors.New(i18n.T("menu_editor.generate.bad_seed", text)), mw.window)
+					return
+				}
+				seed = parsed
+			}
+			pads := regionPads(colorRegion(slices.Index(regions, regionSelect.Selected)), mw.selectedRow, mw.selectedCol)
+			colors := padcolor.Generate(mode, len(pads), seed, base)
+			mw.applyGeneratedColors(menu, pads, colors, mode == padcolor.GenerateRandom, seed)
+		}, mw.window)
+	dlg.Resize(fyne.NewSize(440, 0))
+	dlg.Show()
+}
+
+// applyGeneratedColors sets the static colors of the given pads, then offers to put the
+// pads back as they were. The seed is shown when it decided the colors, so the fill can
+// be made again.
+func (mw *MainWindow) applyGeneratedColors(menu *config.MenuLayout, pads []config.PadPosition, colors []padcolor.RGB, seeded bool, seed uint64) {
+	previous := make([]config.PadColorConfig, len(pads))
+	for i, pos := range pads {
+		pad := &menu.Colors[pos.Row][pos.Col]
+		previous[i] = *pad
+		c := colors[i]
+		pad.R, pad.G, pad.B = c.R, c.G, c.B
+		rLevel, gLevel := padcolor.ClassicLevels(c.R, c.G, c.B)
+		pad.ClassicR, pad.ClassicG, pad.ClassicB = padcolor.LevelValue(rLevel), padcolor.LevelValue(gLevel), 0
+		pad.LinkButtonClassic, pad.ClassicInitialized = true, true
+		pad.OverrideStatic = true
+	}
+	mw.setDirty(true)
+	mw.refreshGrid()
+	mw.selectPad(mw.selectedRow, mw.selectedCol)
+
+	// The dialog is modal, so nothing else can change the pads before an undo
+	var done *dialog.CustomDialog
+	undoBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.bulk_undo"), theme.ContentUndoIcon(), func() {
+		for i, pos := range pads {
+			menu.Colors[pos.Row][pos.Col] = previous[i]
+		}
+		mw.setDirty(true)
+		mw.refreshGrid()
+		mw.selectPad(mw.selectedRow, mw.selectedCol)
+		done.Hide()
+	})
+	message := i18n.T("menu_editor.generate.done", len(pads))
+	if seeded {
+		message += "\n" + i18n.T("menu_editor.generate.done_seed", seed)
+	}
+	content := container.NewVBox(widget.NewLabel(message), container.NewHBox(undoBtn))
+	done = dialog.NewCustom(i18n.T("menu_editor.generate.title"), i18n.T("common.close"), content, mw.window)
+	done.Show()
+}
@@ -0,0 +1,61 @@
+package window
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+func TestRegionPads(t *testing.T) {
+	if got := regionPads(colorRegionPad, 3, 4); len(got) != 1 || got[0] != (config.PadPosition{Row: 3, Col: 4}) {
+		t.Errorf("pad region = %v, want just 3,4", got)
+	}
+	row := regionPads(colorRegionRow, 3, 4)
+	if len(row) != 9 || row[0] != (config.PadPosition{Row: 3}) || row[8] != (config.PadPosition{Row: 3, Col: 8}) {
+		t.Errorf("row region = %v, want row 3 left to right", row)
+	}
+	grid := regionPads(colorRegionGrid, 3, 4)
+	if len(grid) != 81 || grid[0] != (config.PadPosition{}) || grid[80] != (config.PadPosition{Row: 8, Col: 8}) {
+		t.Errorf("grid region has %d pads from %v to %v, want 81 in row-major order", len(grid), grid[0], grid[len(grid)-1])
+	}
+}
+
+func TestGeneratedColorsUndoInOneStep(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	menu := mw.cfg.GetCurrentMenu()
+	menu.Colors[2][0] = config.PadColorConfig{R: 5, ClassicR: 42, ClassicInitialized: true, OverrideStatic: true}
+	before := menu.Clone()
+	mw.selectPad(2, 0)
+
+	pads := regionPads(colorRegionRow, 2, 0)
+	colors := padcolor.Generate(padcolor.GenerateWheel, len(pads), 0, padcolor.RGB{})
+	mw.applyGeneratedColors(menu, pads, colors, false, 0)
+
+	for i, pos := range pads {
+		pad := menu.Colors[pos.Row][pos.Col]
+		c := colors[i]
+		if pad.R != c.R || pad.G != c.G || pad.B != c.B || !pad.OverrideStatic {
+			t.Errorf("pad %v = %+v, want generated %v", pos, pad, c)
+		}
+		rLevel, gLevel := padcolor.ClassicLevels(c.R, c.G, c.B)
+		if pad.ClassicR != padcolor.LevelValue(rLevel) || pad.ClassicG != padcolor.LevelValue(gLevel) || !pad.ClassicInitialized {
+			t.Errorf("pad %v classic = %d,%d, want derived from %v", pos, pad.ClassicR, pad.ClassicG, c)
+		}
+	}
+	if menu.Colors[3][0] != before.Colors[3][0] {
+		t.Error("pad outside the row changed")
+	}
+	if !mw.dirty {
+		t.Error("generating colors did not mark the layout dirty")
+	}
+
+	tapButton(t, mw, i18n.T("menu_editor.bulk_undo"))
+	if menu.Colors != before.Colors {
+		t.Error("undo did not put every pad back as it was")
+	}
+	if mw.window.Canvas().Overlays().Top() != nil {
+		t.Error("done dialog still open after undoing")
+	}
+}
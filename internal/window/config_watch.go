@@ -0,0 +1,82 @@
+package window
+
+import (
+	"log/slog"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// watchConfigFile asks whether to reload when the config file is edited outside the app
+func (mw *MainWindow) watchConfigFile() {
+	stop, err := config.Watch(func() {
+		fyne.Do(mw.promptConfigReload)
+	})
+	if err != nil {
+		slog.Warn("Not watching the config file for outside changes", "err", err)
+		return
+	}
+	mw.stopConfigWatch = stop
+}
+
+// promptConfigReload offers to replace the running configuration with the one on disk.
+// Keeping the in-app version means the next save overwrites the outside edit.
+func (mw *MainWindow) promptConfigReload() {
+	if mw.configPromptOpen {
+		return // The open prompt reloads whatever is on disk by then
+	}
+	mw.configPromptOpen = true
+
+	message := widget.NewLabel(i18n.T("config_watch.message"))
+	message.Wrapping = fyne.TextWrapWord
+	dialog.ShowCustomConfirm(i18n.T("config_watch.title"), i18n.T("config_watch.reload"), i18n.T("config_watch.keep"),
+		message, func(reload bool) {
+			mw.configPromptOpen = false
+			if !reload {
+				return
+			}
+			mw.confirmDiscardActions(func() {
+				loaded, err := config.Load()
+				if err != nil {
+					dialog.ShowError(err, mw.window)
+					return
+				}
+				mw.replaceConfig(loaded)
+				slog.Info("Reloaded the config file after an outside change")
+			})
+		}, mw.window)
+}
+
+// onConfigMerged brings the tabs up to date after a save merged in changes saved
+// meanwhile by someone else. Unsaved action edits are kept; the actions merged in show
+// once those are saved or reverted.
+func (mw *MainWindow) onConfigMerged(conflicts []string) {
+	if !mw.actionStore.Dirty() {
+		selectedAction, selectedGroup := mw.selectedAction, mw.selectedGroup
+		mw.actionStore.Replace(mw.cfg.Actions, mw.cfg.ActionGroups)
+		mw.selectedAction, mw.selectedGroup = nil, nil
+		if selectedAction != nil {
+			mw.selectedAction = mw.actionStore.GetAction(selectedAction.ID)
+		}
+		if selectedGroup != nil {
+			mw.selectedGroup = mw.actionStore.GetGroup(selectedGroup.ID)
+		}
+		mw.actionList.Refresh()
+		mw.updateActionEditor()
+	}
+	mw.deviceList.Refresh()
+	mw.publishDeviceStatus()
+	mw.refreshLayoutDropdown()
+	mw.refreshGrid()
+	mw.mappingList.Refresh()
+	mw.refreshPadActionOptions()
+	mw.notifyTray()
+	if len(conflicts) > 0 {
+		dialog.ShowInformation(i18n.T("config_watch.merged_title"),
+			i18n.T("config_watch.merged_conflicts", strings.Join(conflicts, "\n")), mw.window)
+	}
+}
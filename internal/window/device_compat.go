@@ -0,0 +1,63 @@
+package window
+
+import (
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// deviceMenus lists the menus a device shows: its main menu, then its pages
+func (mw *MainWindow) deviceMenus(device config.DeviceConfig) []*config.MenuLayout {
+	var menus []*config.MenuLayout
+	for _, id := range append([]string{device.MainMenu}, device.Pages...) {
+		if menu := mw.cfg.GetMenu(id); menu != nil && !slices.Contains(menus, menu) {
+			menus = append(menus, menu)
+		}
+	}
+	return menus
+}
+
+// showCompatibilityCheck lists the pads of the device's menus that the device won't
+// show as configured: pads it has no light for or that come out off, and colors it
+// can only approximate
+func (mw *MainWindow) showCompatibilityCheck(deviceID string) {
+	device := mw.cfg.GetDevice(deviceID)
+	if device == nil {
+		return
+	}
+
+	var lines []string
+	for _, menu := range mw.deviceMenus(*device) {
+		for _, issue := range engine.PadIssues(*device, menu, device.Brightness) {
+			c := menu.EffectiveColor(issue.Row, issue.Col)
+			line := i18n.T("actions.reference_pad", menu.Name, issue.Row, issue.Col) + " – "
+			if issue.Fit == midi.FitDropped {
+				line += i18n.T("devices.compat.dropped")
+			} else {
+				line += i18n.T("devices.compat.approximated", i18n.T("pad_color."+padcolor.Name(c.R, c.G, c.B)))
+			}
+			lines = append(lines, "• "+line)
+		}
+	}
+
+	var content fyne.CanvasObject
+	if len(lines) == 0 {
+		content = widget.NewLabel(i18n.T("devices.compat.none"))
+	} else {
+		list := widget.NewLabel(strings.Join(lines, "\n"))
+		list.Wrapping = fyne.TextWrapWord
+		scroll := container.NewVScroll(list)
+		scroll.SetMinSize(fyne.NewSize(460, 240))
+		content = container.NewBorder(widget.NewLabel(i18n.N("devices.compat.count", len(lines), len(lines))), nil, nil, nil, scroll)
+	}
+	dialog.ShowCustom(i18n.T("devices.compat.title", device.Name), i18n.T("common.close"), content, mw.window)
+}
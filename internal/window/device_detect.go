@@ -0,0 +1,114 @@
+package window
+
+import (
+	"log/slog"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// ============ NEW DEVICE DETECTION ============
+
+// OfferDetectedDevices offers to add any known controller whose ports aren't used by a
+// configured device. Each port is offered at most once per session. Must be called on the UI thread.
+func (mw *MainWindow) OfferDetectedDevices() {
+	for _, detected := range midi.DetectLaunchpads(mw.inPorts, mw.outPorts) {
+		port := detected.OutPort
+		if mw.offeredPorts[port] || mw.cfg.IsPortIgnored(port) || mw.cfg.IsPortAssigned(port) {
+			continue
+		}
+		if detected.InPort != "" && mw.cfg.IsPortAssigned(detected.InPort) {
+			continue
+		}
+		mw.offeredPorts[port] = true
+		mw.showDetectedDeviceDialog(detected)
+	}
+}
+
+// showDetectedDeviceDialog asks whether to add a detected controller, with everything but the menu pre-filled
+func (mw *MainWindow) showDetectedDeviceDialog(detected midi.DetectedDevice) {
+	device := mw.cfg.NewDevice()
+	device.Name = detected.Name
+	device.Type = config.DeviceType(detected.Type)
+	device.InPort = detected.InPort
+	device.OutPort = detected.OutPort
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(device.Name)
+
+	menuOptions := []string{i18n.T("common.none")}
+	for _, m := range mw.cfg.Menus {
+		menuOptions = append(menuOptions, m.Name)
+	}
+	menuSelect := widget.NewSelect(menuOptions, nil)
+	if len(mw.cfg.Menus) > 0 {
+		menuSelect.SetSelected(mw.cfg.Menus[0].Name)
+	} else {
+		menuSelect.SetSelected(i18n.T("common.none"))
+	}
+
+	dontAskCheck := widget.NewCheck(i18n.T("detect.dont_ask"), nil)
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("common.name"), nameEntry),
+		widget.NewFormItem(i18n.T("common.type"), widget.NewLabel(deviceTypeLabel(device.Type))),
+		widget.NewFormItem(i18n.T("common.input_port"), widget.NewLabel(portOption(device.InPort))),
+		widget.NewFormItem(i18n.T("common.output_port"), widget.NewLabel(portOption(device.OutPort))),
+		widget.NewFormItem(i18n.T("common.menu"), menuSelect),
+	)
+
+	var dlg dialog.Dialog
+	ignoreBtn := widget.NewButtonWithIcon(i18n.T("detect.not_now"), theme.CancelIcon(), func() {
+		dlg.Hide()
+		if dontAskCheck.Checked {
+			mw.cfg.IgnorePort(detected.OutPort)
+			if err := mw.saveDeviceSettings(); err != nil {
+				slog.Error("Failed to save ignored port", "err", err)
+			}
+		}
+	})
+	addBtn := widget.NewButtonWithIcon(i18n.T("common.add_device"), theme.ContentAddIcon(), func() {
+		dlg.Hide()
+		device.Name = nameEntry.Text
+		if menuSelect.Selected != i18n.T("common.none") {
+			device.MainMenu = menuSelect.Selected
+		}
+		mw.addDetectedDevice(device)
+	})
+	addBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		widget.NewLabel(i18n.T("detect.prompt", detected.Name)),
+		form,
+		dontAskCheck,
+		container.NewHBox(layout.NewSpacer(), ignoreBtn, addBtn),
+	)
+
+	dlg = dialog.NewCustomWithoutButtons(i18n.T("detect.title"), content, mw.window)
+	dlg.Resize(fyne.NewSize(460, 0))
+	dlg.Show()
+}
+
+// addDetectedDevice saves a newly detected device and brings it up straight away
+func (mw *MainWindow) addDetectedDevice(device config.DeviceConfig) {
+	if err := mw.cfg.ValidateDevice(device); err != nil {
+		dialog.ShowError(err, mw.window)
+		return
+	}
+
+	mw.cfg.AddDevice(device)
+	if mw.deviceList != nil {
+		mw.deviceList.Refresh()
+	}
+	if err := mw.saveDeviceSettings(); err != nil {
+		slog.Error("Failed to save config", "err", err)
+	}
+	mw.engine.ActivateDevice(device)
+}
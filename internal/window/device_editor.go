@@ -0,0 +1,586 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// ============ DEVICE EDITOR DIALOG ============
+
+// showDeviceEditor opens a modal editor for a working copy of a device.
+// onAccept is only called (with the edited copy) once the changes pass validation.
+func (mw *MainWindow) showDeviceEditor(title string, device config.DeviceConfig, onAccept func(config.DeviceConfig)) {
+	working := device
+
+	// Grouped devices take their menu and enabled state from the group
+	group := mw.cfg.GroupOf(working.ID)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(i18n.T("common.device_name"))
+	nameEntry.SetText(working.Name)
+	nameEntry.OnChanged = func(s string) { working.Name = s }
+
+	// --- Ports ---
+	var showPortMatches func()
+	inPortSelect := widget.NewSelect(nil, func(s string) {
+		working.InPort = portFromOption(s)
+		showPortMatches()
+	})
+	outPortSelect := widget.NewSelect(nil, func(s string) {
+		working.OutPort = portFromOption(s)
+		showPortMatches()
+	})
+	fillPorts := func() {
+		inPortSelect.Options = portOptions(mw.inPorts, working.InPort)
+		outPortSelect.Options = portOptions(mw.outPorts, working.OutPort)
+		setSelectedSilently(inPortSelect, portOption(working.InPort))
+		setSelectedSilently(outPortSelect, portOption(working.OutPort))
+	}
+	fillPorts()
+
+	// Patterns find the ports where this machine names them differently
+	portMatchStatus := widget.NewLabel("")
+	portMatchStatus.Wrapping = fyne.TextWrapWord
+	showPortMatches = func() {
+		portMatchStatus.SetText(strings.Join(slices.DeleteFunc([]string{
+			portMatchLine(i18n.T("common.input_port"), working.InPort, working.InPortMatch, mw.inPorts),
+			portMatchLine(i18n.T("common.output_port"), working.OutPort, working.OutPortMatch, mw.outPorts),
+		}, func(line string) bool { return line == "" }), "\n"))
+	}
+	inMatchEntry := widget.NewEntry()
+	inMatchEntry.SetPlaceHolder(i18n.T("device_editor.port_match_in"))
+	inMatchEntry.SetText(working.InPortMatch)
+	inMatchEntry.OnChanged = func(s string) {
+		working.InPortMatch = strings.TrimSpace(s)
+		showPortMatches()
+	}
+	outMatchEntry := widget.NewEntry()
+	outMatchEntry.SetPlaceHolder(i18n.T("device_editor.port_match_out"))
+	outMatchEntry.SetText(working.OutPortMatch)
+	outMatchEntry.OnChanged = func(s string) {
+		working.OutPortMatch = strings.TrimSpace(s)
+		showPortMatches()
+	}
+	portMatchHint := widget.NewLabel(i18n.T("device_editor.port_match_hint"))
+	portMatchHint.Wrapping = fyne.TextWrapWord
+	showPortMatches()
+
+	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		mw.refreshPorts()
+		fillPorts()
+		showPortMatches()
+	})
+
+	// --- Menu ---
+	menuOptions := []string{i18n.T("common.none")}
+	for _, m := range mw.cfg.Menus {
+		menuOptions = append(menuOptions, m.Name)
+	}
+	menuSelect := widget.NewSelect(menuOptions, func(s string) {
+		if s == i18n.T("common.none") {
+			working.MainMenu = ""
+		} else {
+			working.MainMenu = s
+		}
+	})
+	if working.MainMenu == "" {
+		setSelectedSilently(menuSelect, i18n.T("common.none"))
+	} else {
+		setSelectedSilently(menuSelect, working.MainMenu)
+	}
+
+	// --- Shift layer ---
+	// Shift menus are stored by ID so renaming a layout doesn't break the link
+	shiftMenuOptions := []string{i18n.T("common.none")}
+	shiftMenuIDs := map[string]string{}
+	shiftMenuOption := i18n.T("common.none")
+	for _, m := range mw.cfg.Menus {
+		shiftMenuOptions = append(shiftMenuOptions, m.Name)
+		shiftMenuIDs[m.Name] = m.ID
+		if m.ID == working.ShiftMenu {
+			shiftMenuOption = m.Name
+		}
+	}
+	shiftMenuSelect := widget.NewSelect(shiftMenuOptions, func(s string) { working.ShiftMenu = shiftMenuIDs[s] })
+	setSelectedSilently(shiftMenuSelect, shiftMenuOption)
+
+	padIndexOptions := make([]string, 9)
+	for i := range padIndexOptions {
+		padIndexOptions[i] = strconv.Itoa(i)
+	}
+	shiftRowSelect := widget.NewSelect(padIndexOptions, func(s string) { working.ShiftPad.Row, _ = strconv.Atoi(s) })
+	shiftColSelect := widget.NewSelect(padIndexOptions, func(s string) { working.ShiftPad.Col, _ = strconv.Atoi(s) })
+	setSelectedSilently(shiftRowSelect, strconv.Itoa(working.ShiftPad.Row))
+	setSelectedSilently(shiftColSelect, strconv.Itoa(working.ShiftPad.Col))
+	shiftPadBox := container.NewHBox(
+		widget.NewLabel(i18n.T("common.row")), shiftRowSelect,
+		widget.NewLabel(i18n.T("common.col")), shiftColSelect,
+	)
+
+	// --- Pages ---
+	// Pages are stored by menu ID and ordered by the order they were ticked in
+	menuNames := map[string]string{}
+	for _, m := range mw.cfg.Menus {
+		menuNames[m.ID] = m.Name
+	}
+	pageOrderLabel := widget.NewLabel("")
+	pageOrderLabel.Wrapping = fyne.TextWrapWord
+	pagesCheck := widget.NewCheckGroup(shiftMenuOptions[1:], nil)
+	pagesCheck.Horizontal = true
+	updatePageOrder := func() {
+		if len(pagesCheck.Selected) == 0 {
+			pageOrderLabel.SetText(i18n.T("device_editor.no_pages"))
+			return
+		}
+		pageOrderLabel.SetText(i18n.T("device_editor.page_order", strings.Join(pagesCheck.Selected, " → ")))
+	}
+	pagesCheck.OnChanged = func(selected []string) {
+		working.Pages = nil
+		for _, name := range selected {
+			working.Pages = append(working.Pages, shiftMenuIDs[name])
+		}
+		updatePageOrder()
+	}
+	var selectedPages []string
+	for _, id := range working.Pages {
+		if name, ok := menuNames[id]; ok {
+			selectedPages = append(selectedPages, name)
+		}
+	}
+	pagesCheck.Selected = selectedPages
+	updatePageOrder()
+
+	pageRowSelect := widget.NewSelect(padIndexOptions, func(s string) { working.PageSelect.Start.Row, _ = strconv.Atoi(s) })
+	pageColSelect := widget.NewSelect(padIndexOptions, func(s string) { working.PageSelect.Start.Col, _ = strconv.Atoi(s) })
+	setSelectedSilently(pageRowSelect, strconv.Itoa(working.PageSelect.Start.Row))
+	setSelectedSilently(pageColSelect, strconv.Itoa(working.PageSelect.Start.Col))
+	pageVerticalCheck := widget.NewCheck(i18n.T("device_editor.vertical"), func(checked bool) { working.PageSelect.Vertical = checked })
+	pageVerticalCheck.SetChecked(working.PageSelect.Vertical)
+	pagePadBox := container.NewHBox(
+		widget.NewLabel(i18n.T("common.row")), pageRowSelect,
+		widget.NewLabel(i18n.T("common.col")), pageColSelect,
+		pageVerticalCheck,
+	)
+
+	persistPageCheck := widget.NewCheck(i18n.T("device_editor.persist_page"), func(checked bool) {
+		working.PersistPage = checked
+	})
+	persistPageCheck.SetChecked(working.PersistPage)
+
+	// --- Input filter ---
+	// Channel checks list channels 1-16; none checked is stored as "every channel"
+	var channelChecks []fyne.CanvasObject
+	readChannels := func() {
+		var mask uint16
+		for channel, obj := range channelChecks {
+			if obj.(*widget.Check).Checked {
+				mask |= 1 << channel
+			}
+		}
+		if mask == 0xFFFF {
+			mask = 0
+		}
+		working.InputFilter.Channels = mask
+	}
+	for channel := range 16 {
+		channelChecks = append(channelChecks, widget.NewCheck(strconv.Itoa(channel+1), func(bool) { readChannels() }))
+	}
+	ignoreCCCheck := widget.NewCheck(i18n.T("device_editor.ignore_cc"), func(checked bool) { working.InputFilter.IgnoreCC = checked })
+	ignoreNotesCheck := widget.NewCheck(i18n.T("device_editor.ignore_notes"), func(checked bool) { working.InputFilter.IgnoreNotes = checked })
+	logFilteredCheck := widget.NewCheck(i18n.T("device_editor.log_filtered"), func(checked bool) { working.InputFilter.LogFiltered = checked })
+	updateInputFilter := func() {
+		for channel, obj := range channelChecks {
+			setCheckedSilently(obj.(*widget.Check), working.InputFilter.AllowsChannel(channel))
+		}
+		setCheckedSilently(ignoreCCCheck, working.InputFilter.IgnoreCC)
+		setCheckedSilently(ignoreNotesCheck, working.InputFilter.IgnoreNotes)
+		setCheckedSilently(logFilteredCheck, working.InputFilter.LogFiltered)
+	}
+	updateInputFilter()
+	inputHint := widget.NewLabel(i18n.T("device_editor.input_hint"))
+	inputHint.Wrapping = fyne.TextWrapWord
+
+	topRowCheck := widget.NewCheck(i18n.T("device_editor.use_top_row"), func(checked bool) { working.UseTopRow = checked })
+	topRowCheck.SetChecked(working.UseTopRow)
+
+	// --- Type ---
+	typeSelect := widget.NewSelect([]string{i18n.T("common.device_type.classic"), i18n.T("common.device_type.colorful"), i18n.T("common.device_type.pro"), i18n.T("common.device_type.generic"), i18n.T("common.device_type.simulated")}, nil)
+	updateMenuState := func() {
+		// Generic devices use message mapping instead of a pad layout
+		inputWidgets := []fyne.Disableable{ignoreCCCheck, ignoreNotesCheck, logFilteredCheck, topRowCheck}
+		for _, obj := range channelChecks {
+			inputWidgets = append(inputWidgets, obj.(*widget.Check))
+		}
+		if working.Type == config.DeviceTypeGeneric {
+			// Generic devices are filtered by their mappings instead, and have no top row
+			for _, w := range inputWidgets {
+				w.Disable()
+			}
+		} else {
+			for _, w := range inputWidgets {
+				w.Enable()
+			}
+		}
+		if working.Type == config.DeviceTypeGeneric {
+			working.MainMenu = ""
+			working.ShiftMenu = ""
+			setSelectedSilently(menuSelect, i18n.T("common.none"))
+			setSelectedSilently(shiftMenuSelect, i18n.T("common.none"))
+			working.Pages = nil
+			pagesCheck.Selected = nil
+			pagesCheck.Refresh()
+			updatePageOrder()
+			for _, w := range []fyne.Disableable{menuSelect, shiftMenuSelect, shiftRowSelect, shiftColSelect,
+				pagesCheck, pageRowSelect, pageColSelect, pageVerticalCheck, persistPageCheck} {
+				w.Disable()
+			}
+		} else {
+			for _, w := range []fyne.Disableable{menuSelect, shiftMenuSelect, shiftRowSelect, shiftColSelect,
+				pagesCheck, pageRowSelect, pageColSelect, pageVerticalCheck, persistPageCheck} {
+				w.Enable()
+			}
+			if group != nil {
+				menuSelect.Disable()
+			}
+		}
+	}
+	typeSelect.OnChanged = func(s string) {
+		previous := working.Type
+		working.Type = deviceTypeFromLabel(s)
+		if working.InputFilter == config.DefaultInputFilter(previous) {
+			working.InputFilter = config.DefaultInputFilter(working.Type)
+			updateInputFilter()
+		}
+		updateMenuState()
+	}
+	setSelectedSilently(typeSelect, deviceTypeLabel(working.Type))
+	updateMenuState()
+
+	// --- Advanced ---
+	enabledCheck := widget.NewCheck(i18n.T("common.enabled"), func(checked bool) { working.Disabled = !checked })
+	enabledCheck.SetChecked(!working.Disabled)
+	if group != nil {
+		enabledCheck.Disable()
+	}
+
+	sharedOutputCheck := widget.NewCheck(i18n.T("device_editor.shared_output"), func(checked bool) {
+		working.AllowSharedOutput = checked
+	})
+	sharedOutputCheck.SetChecked(working.AllowSharedOutput)
+
+	pressedFeedbackCheck := widget.NewCheck(i18n.T("common.show_pressed_colors"), func(checked bool) {
+		working.SendPressedFeedback = checked
+	})
+	pressedFeedbackCheck.SetChecked(working.SendPressedFeedback)
+
+	scopeOptions := []string{i18n.T("device_editor.feedback_mirrored"), i18n.T("device_editor.feedback_local")}
+	scopeSelect := widget.NewSelect(scopeOptions, func(s string) {
+		working.PressedFeedbackScope = config.FeedbackMirrored
+		if s == scopeOptions[1] {
+			working.PressedFeedbackScope = config.FeedbackLocal
+		}
+	})
+	if working.PressedFeedbackScope == config.FeedbackLocal {
+		setSelectedSilently(scopeSelect, scopeOptions[1])
+	} else {
+		setSelectedSilently(scopeSelect, scopeOptions[0])
+	}
+
+	restoreModeCheck := widget.NewCheck(i18n.T("device_editor.restore_mode"), func(checked bool) {
+		working.RestoreModeOnExit = checked
+	})
+	restoreModeCheck.SetChecked(working.RestoreModeOnExit)
+
+	staticLayoutCheck := widget.NewCheck(i18n.T("device_editor.send_layouts"), func(checked bool) {
+		working.SendStaticLayout = checked
+	})
+	staticLayoutCheck.SetChecked(working.SendStaticLayout)
+
+	brightnessLabel := widget.NewLabel("")
+	brightnessSlider := widget.NewSlider(10, 100)
+	brightnessSlider.Step = 5
+	brightnessSlider.Value = float64(working.Brightness)
+	brightnessSlider.OnChanged = func(v float64) {
+		working.Brightness = int(v)
+		brightnessLabel.SetText(fmt.Sprintf("%d%%", working.Brightness))
+	}
+	brightnessLabel.SetText(fmt.Sprintf("%d%%", working.Brightness))
+
+	// Empty is unlimited; the burst only matters with a rate
+	sendRateEntry := widget.NewEntry()
+	sendRateEntry.SetPlaceHolder(i18n.T("device_editor.send_unlimited"))
+	if working.SendRateLimit > 0 {
+		sendRateEntry.SetText(strconv.FormatFloat(working.SendRateLimit, 'g', -1, 64))
+	}
+	sendRateEntry.Validator = func(s string) error {
+		if _, err := parseSendRate(s); err != nil {
+			return errors.New(i18n.T("device_editor.send_rate_invalid"))
+		}
+		return nil
+	}
+	sendRateEntry.OnChanged = func(s string) {
+		if rate, err := parseSendRate(s); err == nil {
+			working.SendRateLimit = rate
+		}
+	}
+	sendBurstEntry := newIntEntry(1, 1000)
+	sendBurstEntry.SetValue(max(working.SendBurst, 1))
+	sendBurstEntry.OnValue = func(v int) { working.SendBurst = v }
+	sendLimitHint := widget.NewLabel(i18n.T("device_editor.send_limit_hint"))
+	sendLimitHint.Wrapping = fyne.TextWrapWord
+
+	// The menu editor keeps showing layouts upright; only the device is addressed turned
+	orientationOptions := make([]string, len(config.Orientations))
+	for i, o := range config.Orientations {
+		orientationOptions[i] = i18n.T(fmt.Sprintf("device_editor.orientation_%d", o))
+	}
+	orientationSelect := widget.NewSelect(orientationOptions, func(s string) {
+		working.Orientation = config.Orientations[max(slices.Index(orientationOptions, s), 0)]
+	})
+	if i := slices.Index(config.Orientations, working.Orientation); i >= 0 {
+		setSelectedSilently(orientationSelect, orientationOptions[i])
+	}
+	orientationHint := widget.NewLabel(i18n.T("device_editor.orientation_hint"))
+	orientationHint.Wrapping = fyne.TextWrapWord
+
+	// Hooks run an action or a whole group
+	connectHookSelect := mw.newActionOrGroupSelect(working.OnConnectActionID, func(id string) { working.OnConnectActionID = id })
+	disconnectHookSelect := mw.newActionOrGroupSelect(working.OnDisconnectActionID, func(id string) { working.OnDisconnectActionID = id })
+	layoutActionCheck := widget.NewCheck(i18n.T("device_editor.layout_action_per_device"), func(checked bool) {
+		working.LayoutActionPerDevice = checked
+	})
+	layoutActionCheck.SetChecked(working.LayoutActionPerDevice)
+
+	// Menu hooks, a row per menu: what runs on entering it, and on leaving it
+	working.OnEnterActionID = maps.Clone(working.OnEnterActionID)
+	working.OnExitActionID = maps.Clone(working.OnExitActionID)
+	setMenuHook := func(hooks *map[string]string, menuID string) func(string) {
+		return func(id string) {
+			if id == "" {
+				delete(*hooks, menuID)
+				return
+			}
+			if *hooks == nil {
+				*hooks = map[string]string{}
+			}
+			(*hooks)[menuID] = id
+		}
+	}
+	menuHookRows := container.NewVBox(container.NewGridWithColumns(3,
+		widget.NewLabelWithStyle(i18n.T("common.menu"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("device_editor.on_enter"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("device_editor.on_exit"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	))
+	for _, menu := range mw.cfg.Menus {
+		menuHookRows.Add(container.NewGridWithColumns(3,
+			widget.NewLabel(menu.Name),
+			mw.newActionOrGroupSelect(working.OnEnterActionID[menu.ID], setMenuHook(&working.OnEnterActionID, menu.ID)),
+			mw.newActionOrGroupSelect(working.OnExitActionID[menu.ID], setMenuHook(&working.OnExitActionID, menu.ID)),
+		))
+	}
+	menuHooksHint := widget.NewLabel(i18n.T("device_editor.menu_hooks_hint"))
+	menuHooksHint.Wrapping = fyne.TextWrapWord
+	hooksHint := widget.NewLabel(i18n.T("device_editor.hooks_hint"))
+	hooksHint.Wrapping = fyne.TextWrapWord
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("common.name"), nameEntry),
+		widget.NewFormItem(i18n.T("common.type"), typeSelect),
+		widget.NewFormItem(i18n.T("common.input_port"), container.NewBorder(nil, nil, nil, refreshBtn, inPortSelect)),
+		widget.NewFormItem(i18n.T("common.output_port"), outPortSelect),
+		widget.NewFormItem(i18n.T("device_editor.port_match"), container.NewVBox(inMatchEntry, outMatchEntry, portMatchStatus, portMatchHint)),
+		widget.NewFormItem(i18n.T("common.menu"), menuSelect),
+	)
+	if group != nil {
+		form.Append("", widget.NewLabel(i18n.T("device_editor.set_by_group", group.Name)))
+	}
+	form.AppendItem(widget.NewFormItem(i18n.T("device_editor.shift_menu"), shiftMenuSelect))
+	form.AppendItem(widget.NewFormItem(i18n.T("device_editor.shift_pad"), shiftPadBox))
+
+	pagesHeader := widget.NewLabel(i18n.T("device_editor.pages"))
+	pagesHeader.TextStyle = fyne.TextStyle{Bold: true}
+	pagesForm := widget.NewForm(
+		widget.NewFormItem(i18n.T("device_editor.menus"), container.NewVBox(pagesCheck, pageOrderLabel)),
+		widget.NewFormItem(i18n.T("device_editor.first_page_pad"), pagePadBox),
+		widget.NewFormItem("", persistPageCheck),
+	)
+
+	advancedHeader := widget.NewLabel(i18n.T("device_editor.advanced"))
+	advancedHeader.TextStyle = fyne.TextStyle{Bold: true}
+	advancedForm := widget.NewForm(
+		widget.NewFormItem("", enabledCheck),
+		widget.NewFormItem("", sharedOutputCheck),
+		widget.NewFormItem("", pressedFeedbackCheck),
+		widget.NewFormItem(i18n.T("device_editor.feedback_scope"), scopeSelect),
+		widget.NewFormItem("", staticLayoutCheck),
+		widget.NewFormItem("", restoreModeCheck),
+		widget.NewFormItem("", topRowCheck),
+		widget.NewFormItem(i18n.T("common.brightness"), container.NewBorder(nil, nil, nil, brightnessLabel, brightnessSlider)),
+		widget.NewFormItem(i18n.T("device_editor.orientation"), container.NewVBox(orientationSelect, orientationHint)),
+		widget.NewFormItem(i18n.T("device_editor.send_limit"), container.NewVBox(
+			container.NewBorder(nil, nil, nil, widget.NewLabel(i18n.T("device_editor.send_rate_unit")), sendRateEntry),
+			container.NewBorder(nil, nil, widget.NewLabel(i18n.T("device_editor.send_burst")), nil, sendBurstEntry),
+			sendLimitHint,
+		)),
+	)
+
+	inputHeader := widget.NewLabel(i18n.T("device_editor.input"))
+	inputHeader.TextStyle = fyne.TextStyle{Bold: true}
+	inputForm := widget.NewForm(
+		widget.NewFormItem(i18n.T("device_editor.channels"), container.NewGridWithColumns(8, channelChecks...)),
+		widget.NewFormItem("", ignoreCCCheck),
+		widget.NewFormItem("", ignoreNotesCheck),
+		widget.NewFormItem("", logFilteredCheck),
+		widget.NewFormItem("", inputHint),
+	)
+
+	hooksHeader := widget.NewLabel(i18n.T("device_editor.hooks"))
+	hooksHeader.TextStyle = fyne.TextStyle{Bold: true}
+	hooksForm := widget.NewForm(
+		widget.NewFormItem(i18n.T("device_editor.on_connect"), connectHookSelect),
+		widget.NewFormItem(i18n.T("device_editor.on_disconnect"), disconnectHookSelect),
+		widget.NewFormItem("", layoutActionCheck),
+		widget.NewFormItem("", hooksHint),
+		widget.NewFormItem(i18n.T("device_editor.menu_hooks"), menuHookRows),
+		widget.NewFormItem("", menuHooksHint),
+	)
+
+	// Validation problems are shown inline so the dialog stays open for fixing
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+
+	var dlg dialog.Dialog
+	cancelBtn := widget.NewButtonWithIcon(i18n.T("common.cancel"), theme.CancelIcon(), func() { dlg.Hide() })
+	okBtn := widget.NewButtonWithIcon(i18n.T("common.ok"), theme.ConfirmIcon(), func() {
+		if err := errors.Join(mw.cfg.ValidateDevice(working), portsAmbiguous(working, mw.inPorts, mw.outPorts)); err != nil {
+			errorLabel.SetText(err.Error())
+			errorLabel.Show()
+			return
+		}
+		dlg.Hide()
+		onAccept(working)
+	})
+	okBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		form,
+		widget.NewSeparator(),
+		pagesHeader,
+		pagesForm,
+		widget.NewSeparator(),
+		advancedHeader,
+		advancedForm,
+		widget.NewSeparator(),
+		inputHeader,
+		inputForm,
+		widget.NewSeparator(),
+		hooksHeader,
+		hooksForm,
+		errorLabel,
+		container.NewHBox(layout.NewSpacer(), cancelBtn, okBtn),
+	)
+
+	dlg = dialog.NewCustomWithoutButtons(title, content, mw.window)
+	dlg.Resize(fyne.NewSize(520, 0))
+	dlg.Show()
+}
+
+// newActionOrGroupSelect builds a picker for an action or group to run, e.g. as a device
+// hook. An ID that no longer exists shows as none.
+func (mw *MainWindow) newActionOrGroupSelect(current string, set func(id string)) *widget.Select {
+	options, ids := []string{i18n.T("common.none")}, []string{""}
+	for _, item := range mw.actionStore.GetFlatList() {
+		name := treeItemName(item)
+		if item.IsGroup {
+			name = "📁 " + name
+		}
+		options = append(options, strings.Repeat("  ", item.Depth)+name)
+		ids = append(ids, treeItemID(item))
+	}
+	hookSelect := widget.NewSelect(options, func(s string) {
+		set(ids[max(slices.Index(options, s), 0)])
+	})
+	setSelectedSilently(hookSelect, options[max(slices.Index(ids, current), 0)])
+	return hookSelect
+}
+
+// parseSendRate reads a send rate in messages per millisecond; empty is unlimited (0)
+func parseSendRate(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil || rate < 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return 0, fmt.Errorf("invalid send rate %q", s)
+	}
+	return rate, nil
+}
+
+// portOptions builds a port dropdown's options, keeping a configured port that is
+// currently missing so the user can see (and change) what is stored
+func portOptions(available []string, current string) []string {
+	options := append([]string{i18n.T("common.none")}, available...)
+	if current != "" && !slices.Contains(available, current) {
+		options = append(options, current)
+	}
+	return options
+}
+
+// portMatchLine describes what a port pattern resolves to among the available ports,
+// "" without a pattern
+func portMatchLine(direction, exact, pattern string, available []string) string {
+	if pattern == "" {
+		return ""
+	}
+	resolved, err := config.ResolvePort(exact, pattern, available)
+	switch {
+	case err != nil:
+		return i18n.T("device_editor.port_match_error", direction, err)
+	case slices.Contains(available, resolved) && resolved == exact:
+		return i18n.T("device_editor.port_match_exact", direction, exact)
+	case resolved != exact:
+		return i18n.T("device_editor.port_match_found", direction, resolved)
+	}
+	return i18n.T("device_editor.port_match_none", direction)
+}
+
+// portsAmbiguous reports port patterns of a device that match several of the available
+// ports where its exact port names are missing
+func portsAmbiguous(device config.DeviceConfig, inPorts, outPorts []string) error {
+	_, inErr := config.ResolvePort(device.InPort, device.InPortMatch, inPorts)
+	_, outErr := config.ResolvePort(device.OutPort, device.OutPortMatch, outPorts)
+	return errors.Join(inErr, outErr)
+}
+
+// portOption maps a stored port name to its dropdown option
+func portOption(port string) string {
+	if port == "" {
+		return i18n.T("common.none")
+	}
+	return port
+}
+
+// portFromOption maps a dropdown option back to a stored port name
+func portFromOption(option string) string {
+	if option == i18n.T("common.none") {
+		return ""
+	}
+	return option
+}
@@ -0,0 +1,181 @@
+package window
+
+import (
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// ============ DEVICE GROUPS ============
+
+// showDeviceGroups opens the list of device groups with add/edit/remove controls
+func (mw *MainWindow) showDeviceGroups() {
+	var groupList *widget.List
+	groupList = widget.NewList(
+		func() int { return len(mw.cfg.DeviceGroups) },
+		func() fyne.CanvasObject {
+			nameLabel := widget.NewLabel(i18n.T("common.group_name"))
+			nameLabel.Truncation = fyne.TextTruncateEllipsis
+			membersLabel := widget.NewLabel("")
+			membersLabel.Truncation = fyne.TextTruncateEllipsis
+			sendBtn := widget.NewButtonWithIcon("", theme.UploadIcon(), nil)
+			editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(sendBtn, editBtn, removeBtn),
+				container.NewGridWithColumns(2, nameLabel, membersLabel),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(mw.cfg.DeviceGroups) {
+				return
+			}
+			group := mw.cfg.DeviceGroups[id]
+			row := obj.(*fyne.Container)
+			labels := row.Objects[0].(*fyne.Container)
+			buttons := row.Objects[1].(*fyne.Container)
+
+			labels.Objects[0].(*widget.Label).SetText(group.Name)
+			labels.Objects[1].(*widget.Label).SetText(mw.groupMemberNames(group))
+
+			groupID := group.ID
+			buttons.Objects[0].(*widget.Button).OnTapped = func() {
+				if err := mw.engine.SendGridToTarget(groupID); err != nil {
+					dialog.ShowError(err, mw.window)
+				}
+			}
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				if g := mw.cfg.GetDeviceGroup(groupID); g != nil {
+					mw.showDeviceGroupEditor(i18n.T("groups.edit"), *g, func() { groupList.Refresh() })
+				}
+			}
+			buttons.Objects[2].(*widget.Button).OnTapped = func() {
+				mw.cfg.RemoveDeviceGroup(groupID)
+				groupList.Refresh()
+				mw.deviceList.Refresh()
+			}
+		},
+	)
+
+	addBtn := widget.NewButtonWithIcon(i18n.T("common.add_group"), theme.ContentAddIcon(), func() {
+		mw.showDeviceGroupEditor(i18n.T("common.add_group"), config.NewDeviceGroup(), func() { groupList.Refresh() })
+	})
+
+	hint := widget.NewLabel(i18n.T("groups.hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(hint, container.NewHBox(addBtn), nil, nil, groupList)
+	dlg := dialog.NewCustom(i18n.T("groups.title"), i18n.T("common.close"), content, mw.window)
+	dlg.Resize(fyne.NewSize(560, 400))
+	dlg.Show()
+}
+
+// groupMemberNames returns a comma-separated list of a group's device names
+func (mw *MainWindow) groupMemberNames(group config.DeviceGroup) string {
+	var names []string
+	for _, id := range group.DeviceIDs {
+		if device := mw.cfg.GetDevice(id); device != nil {
+			names = append(names, device.Name)
+		}
+	}
+	if len(names) == 0 {
+		return i18n.T("groups.no_devices")
+	}
+	return strings.Join(names, ", ")
+}
+
+// showDeviceGroupEditor edits a working copy of a group; onDone is called after it is stored
+func (mw *MainWindow) showDeviceGroupEditor(title string, group config.DeviceGroup, onDone func()) {
+	working := group
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(working.Name)
+	nameEntry.OnChanged = func(s string) { working.Name = s }
+
+	menuOptions := []string{i18n.T("common.none")}
+	for _, m := range mw.cfg.Menus {
+		menuOptions = append(menuOptions, m.Name)
+	}
+	menuSelect := widget.NewSelect(menuOptions, func(s string) {
+		if s == i18n.T("common.none") {
+			working.MainMenu = ""
+		} else {
+			working.MainMenu = s
+		}
+	})
+	if working.MainMenu == "" {
+		setSelectedSilently(menuSelect, i18n.T("common.none"))
+	} else {
+		setSelectedSilently(menuSelect, working.MainMenu)
+	}
+
+	enabledCheck := widget.NewCheck(i18n.T("common.enabled"), func(checked bool) { working.Disabled = !checked })
+	enabledCheck.SetChecked(!working.Disabled)
+
+	// Members are chosen by name; generic devices have no layout to share
+	var deviceNames []string
+	idsByName := map[string]string{}
+	var selected []string
+	for _, d := range mw.cfg.Devices {
+		if d.Type == config.DeviceTypeGeneric {
+			continue
+		}
+		deviceNames = append(deviceNames, d.Name)
+		idsByName[d.Name] = d.ID
+		if slices.Contains(working.DeviceIDs, d.ID) {
+			selected = append(selected, d.Name)
+		}
+	}
+	membersCheck := widget.NewCheckGroup(deviceNames, func(names []string) {
+		working.DeviceIDs = working.DeviceIDs[:0:0]
+		for _, name := range names {
+			working.DeviceIDs = append(working.DeviceIDs, idsByName[name])
+		}
+	})
+	membersCheck.Selected = selected
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("common.name"), nameEntry),
+		widget.NewFormItem(i18n.T("common.menu"), menuSelect),
+		widget.NewFormItem("", enabledCheck),
+		widget.NewFormItem(i18n.T("common.devices"), membersCheck),
+	)
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Hide()
+
+	var dlg dialog.Dialog
+	cancelBtn := widget.NewButtonWithIcon(i18n.T("common.cancel"), theme.CancelIcon(), func() { dlg.Hide() })
+	okBtn := widget.NewButtonWithIcon(i18n.T("common.ok"), theme.ConfirmIcon(), func() {
+		if strings.TrimSpace(working.Name) == "" {
+			errorLabel.SetText(i18n.T("groups.name_required"))
+			errorLabel.Show()
+			return
+		}
+		dlg.Hide()
+		mw.cfg.UpdateDeviceGroup(working)
+		mw.deviceList.Refresh()
+		onDone()
+	})
+	okBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		form,
+		widget.NewLabel(i18n.T("groups.membership_note")),
+		errorLabel,
+		container.NewHBox(layout.NewSpacer(), cancelBtn, okBtn),
+	)
+
+	dlg = dialog.NewCustomWithoutButtons(title, content, mw.window)
+	dlg.Resize(fyne.NewSize(460, 0))
+	dlg.Show()
+}
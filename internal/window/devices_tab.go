@@ -1,41 +1,69 @@
 package window
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
 )
 
 // ============ DEVICES TAB ============
 
 func (mw *MainWindow) createDevicesTab() fyne.CanvasObject {
-	devicesHeader := widget.NewLabel("MIDI Devices")
+	devicesHeader := widget.NewLabel(i18n.T("devices.header"))
 	devicesHeader.TextStyle = fyne.TextStyle{Bold: true}
 
-	addBtn := widget.NewButtonWithIcon("Add Device", theme.ContentAddIcon(), func() {
+	addBtn := widget.NewButtonWithIcon(i18n.T("common.add_device"), theme.ContentAddIcon(), func() {
 		mw.addDevice()
 	})
 
-	devicesToolbar := container.NewBorder(nil, nil, devicesHeader, addBtn)
+	refreshBtn := widget.NewButtonWithIcon(i18n.T("devices.refresh_ports"), theme.ViewRefreshIcon(), func() {
+		mw.refreshPorts()
+	})
+
+	groupsBtn := widget.NewButtonWithIcon(i18n.T("devices.groups"), theme.GridIcon(), func() {
+		mw.showDeviceGroups()
+	})
+
+	scenesBtn := widget.NewButtonWithIcon(i18n.T("devices.scenes"), theme.MediaPlayIcon(), func() {
+		mw.showScenes()
+	})
+
+	topRowBtn := widget.NewButtonWithIcon(i18n.T("devices.top_row"), theme.MenuIcon(), func() {
+		mw.showTopRowEditor()
+	})
+
+	virtualBtn := widget.NewButtonWithIcon(i18n.T("devices.virtual_launchpad"), theme.ComputerIcon(), func() {
+		mw.showVirtualLaunchpad()
+	})
+
+	// Shown while devices missing at launch are still being tried
+	mw.initializingLabel = widget.NewLabel(i18n.T("common.initializing_devices"))
+	mw.initializingLabel.Importance = widget.LowImportance
+	mw.initializingLabel.Hide()
+
+	devicesToolbar := container.NewBorder(nil, nil, container.NewHBox(devicesHeader, mw.initializingLabel), container.NewHBox(refreshBtn, groupsBtn, scenesBtn, topRowBtn, virtualBtn, addBtn))
 
-	headerName := widget.NewLabel("Name")
+	headerName := widget.NewLabel(i18n.T("common.name"))
 	headerName.TextStyle = fyne.TextStyle{Bold: true}
-	headerIn := widget.NewLabel("Input Port")
-	headerIn.TextStyle = fyne.TextStyle{Bold: true}
-	headerOut := widget.NewLabel("Output Port")
-	headerOut.TextStyle = fyne.TextStyle{Bold: true}
-	headerType := widget.NewLabel("Type")
+	headerType := widget.NewLabel(i18n.T("common.type"))
 	headerType.TextStyle = fyne.TextStyle{Bold: true}
-	headerMenu := widget.NewLabel("Menu")
+	headerMenu := widget.NewLabel(i18n.T("common.menu"))
 	headerMenu.TextStyle = fyne.TextStyle{Bold: true}
+	headerStatus := widget.NewLabel(i18n.T("devices.status"))
+	headerStatus.TextStyle = fyne.TextStyle{Bold: true}
 	headerActions := widget.NewLabel("")
 
-	columnHeaders := container.NewGridWithColumns(6,
-		headerName, headerIn, headerOut, headerType, headerMenu, headerActions,
+	columnHeaders := container.NewGridWithColumns(5,
+		headerName, headerType, headerMenu, headerStatus, headerActions,
 	)
 
 	mw.deviceList = widget.NewList(
@@ -44,7 +72,7 @@ func (mw *MainWindow) createDevicesTab() fyne.CanvasObject {
 		func(id widget.ListItemID, obj fyne.CanvasObject) { mw.updateDeviceRow(id, obj) },
 	)
 
-	saveBtn := widget.NewButtonWithIcon("Save & Activate Devices", theme.DocumentSaveIcon(), func() {
+	saveBtn := widget.NewButtonWithIcon(i18n.T("devices.save_activate"), theme.DocumentSaveIcon(), func() {
 		mw.saveAndActivate()
 	})
 	saveBtn.Importance = widget.HighImportance
@@ -63,26 +91,27 @@ func (mw *MainWindow) createDevicesTab() fyne.CanvasObject {
 }
 
 func (mw *MainWindow) createDeviceRow() fyne.CanvasObject {
-	nameEntry := widget.NewEntry()
-	nameEntry.SetPlaceHolder("Device Name")
-
-	inPortSelect := widget.NewSelect([]string{}, nil)
-	inPortSelect.PlaceHolder = "Select..."
-
-	outPortSelect := widget.NewSelect([]string{}, nil)
-	outPortSelect.PlaceHolder = "Select..."
-
-	typeSelect := widget.NewSelect([]string{"Classic", "Colorful", "Generic"}, nil)
-	typeSelect.PlaceHolder = "Type"
-
-	menuSelect := widget.NewSelect([]string{"(None)"}, nil)
-	menuSelect.PlaceHolder = "Menu"
-
+	nameLabel := widget.NewLabel(i18n.T("common.device_name"))
+	nameLabel.Truncation = fyne.TextTruncateEllipsis
+	typeLabel := widget.NewLabel("")
+	menuLabel := widget.NewLabel("")
+	menuLabel.Truncation = fyne.TextTruncateEllipsis
+
+	// Warning icon is shown when a configured port is not currently present on the system
+	warningIcon := widget.NewIcon(theme.WarningIcon())
+	warningIcon.Hide()
+	statusLabel := widget.NewLabel("")
+
+	sendBtn := widget.NewButtonWithIcon("", theme.UploadIcon(), nil)
+	testBtn := widget.NewButtonWithIcon("", theme.MediaPlayIcon(), nil)
+	checkBtn := widget.NewButtonWithIcon("", theme.VisibilityIcon(), nil)
+	editBtn := widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), nil)
 	removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 
-	return container.NewGridWithColumns(6,
-		nameEntry, inPortSelect, outPortSelect, typeSelect, menuSelect,
-		container.NewCenter(removeBtn),
+	return container.NewGridWithColumns(5,
+		nameLabel, typeLabel, menuLabel,
+		container.NewHBox(warningIcon, statusLabel),
+		container.NewCenter(container.NewHBox(sendBtn, testBtn, checkBtn, editBtn, removeBtn)),
 	)
 }
 
@@ -91,133 +120,289 @@ func (mw *MainWindow) updateDeviceRow(id widget.ListItemID, obj fyne.CanvasObjec
 		return
 	}
 
-	device := &mw.cfg.Devices[id]
+	device := mw.cfg.Devices[id]
 	grid := obj.(*fyne.Container)
 
-	nameEntry := grid.Objects[0].(*widget.Entry)
-	inPortSelect := grid.Objects[1].(*widget.Select)
-	outPortSelect := grid.Objects[2].(*widget.Select)
-	typeSelect := grid.Objects[3].(*widget.Select)
-	menuSelect := grid.Objects[4].(*widget.Select)
-	removeBtnContainer := grid.Objects[5].(*fyne.Container)
-	removeBtn := removeBtnContainer.Objects[0].(*widget.Button)
-
-	inPorts := mw.midiManager.ListInPorts()
-	outPorts := mw.midiManager.ListOutPorts()
-
-	inPortSelect.Options = append([]string{"(None)"}, inPorts...)
-	outPortSelect.Options = append([]string{"(None)"}, outPorts...)
-
-	nameEntry.SetText(device.Name)
-	nameEntry.OnChanged = func(s string) { device.Name = s }
-
-	if device.InPort == "" {
-		inPortSelect.SetSelected("(None)")
+	nameLabel := grid.Objects[0].(*widget.Label)
+	typeLabel := grid.Objects[1].(*widget.Label)
+	menuLabel := grid.Objects[2].(*widget.Label)
+	statusBox := grid.Objects[3].(*fyne.Container)
+	warningIcon := statusBox.Objects[0].(*widget.Icon)
+	statusLabel := statusBox.Objects[1].(*widget.Label)
+	buttons := grid.Objects[4].(*fyne.Container).Objects[0].(*fyne.Container)
+	sendBtn := buttons.Objects[0].(*widget.Button)
+	testBtn := buttons.Objects[1].(*widget.Button)
+	checkBtn := buttons.Objects[2].(*widget.Button)
+	editBtn := buttons.Objects[3].(*widget.Button)
+	removeBtn := buttons.Objects[4].(*widget.Button)
+
+	if group := mw.cfg.GroupOf(device.ID); group != nil {
+		nameLabel.SetText(device.Name + " [" + group.Name + "]")
 	} else {
-		inPortSelect.SetSelected(device.InPort)
+		nameLabel.SetText(device.Name)
 	}
-	inPortSelect.OnChanged = func(s string) {
-		if s == "(None)" {
-			device.InPort = ""
-		} else {
-			device.InPort = s
-		}
+	typeLabel.SetText(deviceTypeLabel(device.Type))
+
+	switch {
+	case device.Type == config.DeviceTypeGeneric:
+		menuLabel.SetText("—")
+	case device.HasPages():
+		menuLabel.SetText(i18n.N("devices.page_count", len(device.Pages), len(device.Pages)))
+	case device.MainMenu == "":
+		menuLabel.SetText(i18n.T("common.none"))
+	default:
+		menuLabel.SetText(device.MainMenu)
+	}
+
+	warningIcon.Hide()
+	switch {
+	case device.Disabled:
+		statusLabel.SetText(i18n.T("devices.status.disabled"))
+	case device.InPort == "" && device.OutPort == "":
+		statusLabel.SetText(i18n.T("devices.status.not_configured"))
+	case mw.deviceHasPortConflict(device.ID):
+		warningIcon.Show()
+		statusLabel.SetText(i18n.T("devices.status.port_conflict"))
+	case mw.deviceHasMissingPort(device):
+		warningIcon.Show()
+		statusLabel.SetText(i18n.T("devices.status.port_missing"))
+	default:
+		statusLabel.SetText(i18n.T("devices.status.connected"))
 	}
 
-	if device.OutPort == "" {
-		outPortSelect.SetSelected("(None)")
+	// Layout buttons need somewhere to send to
+	if device.OutPort == "" || device.Type == config.DeviceTypeGeneric {
+		sendBtn.Disable()
+		testBtn.Disable()
 	} else {
-		outPortSelect.SetSelected(device.OutPort)
+		sendBtn.Enable()
+		testBtn.Enable()
 	}
-	outPortSelect.OnChanged = func(s string) {
-		if s == "(None)" {
-			device.OutPort = ""
-		} else {
-			device.OutPort = s
-		}
+	if !device.SendStaticLayout {
+		sendBtn.Disable()
+	}
+	// Only devices with lights and something to show can be checked
+	if device.Type == config.DeviceTypeGeneric || len(mw.deviceMenus(device)) == 0 {
+		checkBtn.Disable()
+	} else {
+		checkBtn.Enable()
 	}
 
-	switch device.Type {
-	case config.DeviceTypeClassic:
-		typeSelect.SetSelected("Classic")
+	deviceID := device.ID
+	sendBtn.OnTapped = func() { mw.sendLayoutToDevice(deviceID) }
+	testBtn.OnTapped = func() { mw.testDevice(deviceID) }
+	checkBtn.OnTapped = func() { mw.showCompatibilityCheck(deviceID) }
+	editBtn.OnTapped = func() { mw.editDevice(deviceID) }
+	removeBtn.OnTapped = func() { mw.removeDevice(deviceID) }
+}
+
+// deviceTypeLabel returns the display name for a device type
+func deviceTypeLabel(t config.DeviceType) string {
+	switch t {
 	case config.DeviceTypeColorful:
-		typeSelect.SetSelected("Colorful")
+		return i18n.T("common.device_type.colorful")
+	case config.DeviceTypePro:
+		return i18n.T("common.device_type.pro")
 	case config.DeviceTypeGeneric:
-		typeSelect.SetSelected("Generic")
+		return i18n.T("common.device_type.generic")
+	case config.DeviceTypeSimulated:
+		return i18n.T("common.device_type.simulated")
 	default:
-		typeSelect.SetSelected("Classic")
-	}
-	typeSelect.OnChanged = func(s string) {
-		switch s {
-		case "Classic":
-			device.Type = config.DeviceTypeClassic
-			menuSelect.Enable()
-		case "Colorful":
-			device.Type = config.DeviceTypeColorful
-			menuSelect.Enable()
-		case "Generic":
-			device.Type = config.DeviceTypeGeneric
-			device.MainMenu = "" // Clear menu assignment
-			menuSelect.SetSelected("(None)")
-			menuSelect.Disable()
-		}
+		return i18n.T("common.device_type.classic")
 	}
+}
 
-	// Populate menu dropdown with available layouts
-	menuOptions := []string{"(None)"}
-	for _, m := range mw.cfg.Menus {
-		menuOptions = append(menuOptions, m.Name)
+// deviceTypeFromLabel is the inverse of deviceTypeLabel
+func deviceTypeFromLabel(label string) config.DeviceType {
+	switch label {
+	case i18n.T("common.device_type.colorful"):
+		return config.DeviceTypeColorful
+	case i18n.T("common.device_type.pro"):
+		return config.DeviceTypePro
+	case i18n.T("common.device_type.generic"):
+		return config.DeviceTypeGeneric
+	case i18n.T("common.device_type.simulated"):
+		return config.DeviceTypeSimulated
+	default:
+		return config.DeviceTypeClassic
 	}
-	menuSelect.Options = menuOptions
-	if device.MainMenu == "" {
-		menuSelect.SetSelected("(None)")
-	} else {
-		menuSelect.SetSelected(device.MainMenu)
+}
+
+// refreshPorts re-enumerates the system's MIDI ports and re-renders the device rows
+func (mw *MainWindow) refreshPorts() {
+	mw.setAvailablePorts(mw.midiManager.ListInPorts(), mw.midiManager.ListOutPorts())
+}
+
+// setAvailablePorts stores the known port lists and refreshes anything that displays them.
+// Must be called on the UI thread.
+func (mw *MainWindow) setAvailablePorts(inPorts, outPorts []string) {
+	mw.inPorts = inPorts
+	mw.outPorts = outPorts
+	if mw.deviceList != nil {
+		mw.deviceList.Refresh()
 	}
-	menuSelect.OnChanged = func(s string) {
-		if s == "(None)" {
-			device.MainMenu = ""
+	mw.publishDeviceStatus()
+	mw.OfferDetectedDevices()
+}
+
+// publishDeviceStatus reports how many enabled devices are connected or missing a port
+func (mw *MainWindow) publishDeviceStatus() {
+	connected, missing := 0, 0
+	for _, device := range mw.cfg.Devices {
+		if device.Disabled || (device.InPort == "" && device.OutPort == "") {
+			continue
+		}
+		if mw.deviceHasMissingPort(device) {
+			missing++
 		} else {
-			device.MainMenu = s
+			connected++
 		}
 	}
+	mw.engine.Status().SetDevices(connected, missing)
+}
 
-	// Disable menu dropdown for Generic devices
-	if device.Type == config.DeviceTypeGeneric {
-		menuSelect.Disable()
-	} else {
-		menuSelect.Enable()
+// deviceHasMissingPort reports whether a device references a port that isn't currently present
+func (mw *MainWindow) deviceHasMissingPort(device config.DeviceConfig) bool {
+	if device.InPort != "" && !slices.Contains(mw.inPorts, device.InPort) {
+		return true
+	}
+	if device.OutPort != "" && !slices.Contains(mw.outPorts, device.OutPort) {
+		return true
 	}
+	return false
+}
 
-	deviceID := device.ID
-	removeBtn.OnTapped = func() { mw.removeDevice(deviceID) }
+// deviceHasPortConflict reports whether a device shares a port with another device
+func (mw *MainWindow) deviceHasPortConflict(deviceID string) bool {
+	for _, conflict := range mw.cfg.FindPortConflicts() {
+		if slices.Contains(conflict.DeviceIDs, deviceID) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendLayoutToDevice pushes a single device's assigned menu using the unsaved device settings
+func (mw *MainWindow) sendLayoutToDevice(deviceID string) {
+	device := mw.cfg.GetDevice(deviceID)
+	if device == nil {
+		return
+	}
+	if err := mw.engine.SendGridToDevice(*device); err != nil {
+		dialog.ShowError(err, mw.window)
+	}
+}
+
+// testDevice lights every pad through a short sequence of colors, then restores the device's layout
+func (mw *MainWindow) testDevice(deviceID string) {
+	device := mw.cfg.GetDevice(deviceID)
+	if device == nil {
+		return
+	}
+	dev := *device // Snapshot so edits during the sweep don't affect it
+
+	go func() {
+		if err := mw.engine.TestDevice(dev); err != nil {
+			fyne.Do(func() {
+				dialog.ShowError(fmt.Errorf(i18n.T("devices.test_failed"), dev.Name, err), mw.window)
+			})
+		}
+	}()
 }
 
 func (mw *MainWindow) addDevice() {
-	newDevice := config.NewDeviceConfig()
-	mw.cfg.AddDevice(newDevice)
-	mw.deviceList.Refresh()
+	// The device is only added to the config if the editor is accepted
+	mw.showDeviceEditor(i18n.T("common.add_device"), mw.cfg.NewDevice(), func(device config.DeviceConfig) {
+		mw.cfg.AddDevice(device)
+		mw.deviceList.Refresh()
+		mw.refreshGrid() // Top-row markers and the grid shape follow the devices
+	})
+}
+
+func (mw *MainWindow) editDevice(id string) {
+	device := mw.cfg.GetDevice(id)
+	if device == nil {
+		return
+	}
+	mw.showDeviceEditor(i18n.T("devices.edit"), *device, func(edited config.DeviceConfig) {
+		mw.cfg.UpdateDevice(edited)
+		mw.deviceList.Refresh()
+		mw.refreshGrid() // Top-row markers and the grid shape follow the devices
+	})
 }
 
 func (mw *MainWindow) removeDevice(id string) {
-	mw.cfg.RemoveDevice(id)
-	mw.deviceList.Refresh()
+	device := mw.cfg.GetDevice(id)
+	if device == nil {
+		return
+	}
+	removed := *device
+
+	dialog.ShowConfirm(i18n.T("devices.remove_title"),
+		i18n.T("devices.remove_confirm", removed.Name),
+		func(confirm bool) {
+			if !confirm {
+				return
+			}
+			mw.engine.ReleaseDevice(id)
+			mw.cfg.RemoveDevice(id)
+			mw.deviceList.Refresh()
+			mw.refreshGrid() // Top-row markers and the grid shape follow the devices
+		}, mw.window)
 }
 
 func (mw *MainWindow) saveAndActivate() {
-	// Reload menus from disk to avoid saving unsaved layout changes
+	conflicts := mw.cfg.FindPortConflicts()
+	if len(conflicts) == 0 {
+		mw.doSaveAndActivate()
+		return
+	}
+
+	lines := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		lines[i] = "• " + c.String()
+	}
+	message := widget.NewLabel(strings.Join(lines, "\n"))
+	message.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(
+		widget.NewLabel(i18n.T("devices.conflicts_intro")),
+		message,
+		widget.NewLabel(i18n.T("devices.conflicts_note")),
+	)
+	dialog.ShowCustomConfirm(i18n.T("devices.conflicts_title"), i18n.T("devices.activate_anyway"), i18n.T("common.cancel"), content, func(confirm bool) {
+		if confirm {
+			mw.doSaveAndActivate()
+		}
+	}, mw.window)
+}
+
+// saveDeviceSettings writes the device list, device groups and ignored ports to disk
+// without also saving unsaved layout or action edits
+func (mw *MainWindow) saveDeviceSettings() error {
+	return config.Update(func(saved *config.Config) {
+		saved.Devices = mw.cfg.Devices
+		saved.DeviceGroups = mw.cfg.DeviceGroups
+		saved.IgnoredPorts = mw.cfg.IgnoredPorts
+	})
+}
+
+func (mw *MainWindow) doSaveAndActivate() {
+	// Devices are sent the layouts as saved, so unsaved layout changes are dropped
 	if savedCfg, err := config.Load(); err == nil {
 		mw.cfg.Menus = savedCfg.Menus
 	}
 
-	if err := mw.cfg.Save(); err != nil {
-		log.Printf("Failed to save config: %v", err)
+	if err := mw.saveDeviceSettings(); err != nil {
+		slog.Error("Failed to save config", "err", err)
 		return
 	}
 
 	// Initialize devices and send layout
 	mw.InitializeDevices()
 
+	mw.publishDeviceStatus()
+
 	// Also refresh the grid to show saved state
 	mw.setDirty(false)
 	mw.refreshGrid()
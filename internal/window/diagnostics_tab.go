@@ -0,0 +1,253 @@
+package window
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/logging"
+	"github.com/PixPMusic/gopher-automate/internal/metrics"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// diagnosticsRefreshInterval is how often the stats are redrawn while the tab is shown
+const diagnosticsRefreshInterval = 2 * time.Second
+
+// reportLogLines is how many recent log lines the diagnostics report includes
+const reportLogLines = 100
+
+// usageLines is how many of the most-run actions the usage section lists
+const usageLines = 20
+
+// ============ DIAGNOSTICS TAB ============
+
+func (mw *MainWindow) createDiagnosticsTab() fyne.CanvasObject {
+	header := widget.NewLabel(i18n.T("common.diagnostics"))
+	header.TextStyle = fyne.TextStyle{Bold: true}
+
+	mw.messagesLabel = widget.NewLabel("")
+	mw.errorsLabel = widget.NewLabel("")
+	mw.actionLatencyLabel = widget.NewLabel("")
+	mw.dispatchLabel = widget.NewLabel("")
+	mw.padUpdateLabel = widget.NewLabel("")
+	mw.coalescedLabel = widget.NewLabel("")
+	mw.gridPushLabel = widget.NewLabel("")
+	mw.gridPushLabel.TextStyle = fyne.TextStyle{Monospace: true}
+
+	stats := widget.NewForm(
+		widget.NewFormItem(i18n.T("diagnostics.messages"), mw.messagesLabel),
+		widget.NewFormItem(i18n.T("diagnostics.errors"), mw.errorsLabel),
+		widget.NewFormItem(i18n.T("diagnostics.action_latency"), mw.actionLatencyLabel),
+		widget.NewFormItem(i18n.T("diagnostics.dispatch"), mw.dispatchLabel),
+		widget.NewFormItem(i18n.T("diagnostics.pad_update"), mw.padUpdateLabel),
+		widget.NewFormItem(i18n.T("diagnostics.coalesced"), mw.coalescedLabel),
+	)
+
+	gridHeader := widget.NewLabel(i18n.T("diagnostics.grid_push"))
+	gridHeader.TextStyle = fyne.TextStyle{Bold: true}
+
+	copyBtn := widget.NewButtonWithIcon(i18n.T("diagnostics.copy_report"), theme.ContentCopyIcon(), func() {
+		mw.app.Clipboard().SetContent(mw.diagnosticsReport())
+		mw.diagnosticsFeedback.SetText(i18n.T("diagnostics.report_copied"))
+	})
+	mw.diagnosticsFeedback = widget.NewLabel("")
+
+	usageHeader := widget.NewLabel(i18n.T("diagnostics.usage"))
+	usageHeader.TextStyle = fyne.TextStyle{Bold: true}
+	mw.usageLabel = widget.NewLabel("")
+	mw.usageLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	resetUsageBtn := widget.NewButtonWithIcon(i18n.T("diagnostics.reset_usage"), theme.DeleteIcon(), func() {
+		dialog.ShowConfirm(i18n.T("diagnostics.reset_usage"), i18n.T("diagnostics.reset_usage_confirm"), func(confirm bool) {
+			if !confirm {
+				return
+			}
+			if err := usage.Reset(); err != nil {
+				dialog.ShowError(err, mw.window)
+			}
+			mw.refreshDiagnostics()
+			mw.refreshGrid()
+		}, mw.window)
+	})
+
+	mw.refreshDiagnostics()
+	go mw.watchDiagnostics()
+
+	return container.NewBorder(
+		container.NewVBox(header, container.NewHBox(copyBtn, mw.diagnosticsFeedback), widget.NewSeparator()),
+		nil, nil, nil,
+		container.NewVScroll(container.NewVBox(stats, widget.NewSeparator(), gridHeader, mw.gridPushLabel,
+			widget.NewSeparator(), container.NewHBox(usageHeader, resetUsageBtn), mw.usageLabel)),
+	)
+}
+
+// watchDiagnostics redraws the stats periodically while the Diagnostics tab is selected
+func (mw *MainWindow) watchDiagnostics() {
+	for range time.Tick(diagnosticsRefreshInterval) {
+		fyne.Do(func() {
+			if mw.tabs != nil && mw.tabs.Selected() == mw.diagnosticsTab {
+				mw.refreshDiagnostics()
+			}
+		})
+	}
+}
+
+// refreshDiagnostics shows the current metrics
+func (mw *MainWindow) refreshDiagnostics() {
+	mw.messagesLabel.SetText(i18n.T("diagnostics.per_minute", metrics.Messages.Last(1), metrics.Messages.Total()))
+	mw.errorsLabel.SetText(i18n.T("diagnostics.per_hour", metrics.Errors.Last(60), metrics.Errors.Total()))
+	mw.actionLatencyLabel.SetText(timingLabel(metrics.ActionLatency.Stats()))
+	mw.dispatchLabel.SetText(timingLabel(metrics.Dispatch.Stats()))
+	mw.padUpdateLabel.SetText(timingLabel(metrics.PadUpdate.Stats()))
+	mw.coalescedLabel.SetText(i18n.T("diagnostics.per_hour", metrics.CoalescedSends.Last(60), metrics.CoalescedSends.Total()))
+
+	lines := mw.gridPushLines()
+	if len(lines) == 0 {
+		lines = []string{i18n.T("diagnostics.no_data")}
+	}
+	mw.gridPushLabel.SetText(strings.Join(lines, "\n"))
+
+	lines = mw.usageSummaryLines()
+	if len(lines) == 0 {
+		lines = []string{i18n.T("diagnostics.no_data")}
+	}
+	mw.usageLabel.SetText(strings.Join(lines, "\n"))
+}
+
+// usageSummaryLines lists the most-run actions with their failures and last run
+func (mw *MainWindow) usageSummaryLines() []string {
+	counts := usage.Actions()
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if counts[ids[i]].Count != counts[ids[j]].Count {
+			return counts[ids[i]].Count > counts[ids[j]].Count
+		}
+		return ids[i] < ids[j]
+	})
+
+	var lines []string
+	for _, id := range ids[:min(len(ids), usageLines)] {
+		name := id
+		if action := mw.actionStore.GetAction(id); action != nil {
+			name = action.Name
+		}
+		c := counts[id]
+		line := i18n.T("diagnostics.usage_line", name, c.Count, c.Failures, c.Last.Format("2006-01-02 15:04"))
+		if c.Suppressed > 0 {
+			line += i18n.T("diagnostics.usage_suppressed", c.Suppressed)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// gridPushLines describes the last grid push of each configured device that has sent one
+func (mw *MainWindow) gridPushLines() []string {
+	stats := metrics.GridPush.Stats()
+	var lines []string
+	for _, device := range mw.cfg.Devices {
+		s, ok := stats[device.OutPort]
+		if !ok || device.OutPort == "" {
+			continue
+		}
+		lines = append(lines, i18n.T("diagnostics.grid_push_line", device.Name, formatDuration(s.Last), formatDuration(s.Average()), formatDuration(s.Max), s.Count))
+	}
+	return lines
+}
+
+// timingLabel summarizes a timing as average, maximum and count
+func timingLabel(s metrics.TimingStats) string {
+	if s.Count == 0 {
+		return i18n.T("diagnostics.no_data")
+	}
+	return i18n.T("diagnostics.timing", formatDuration(s.Average()), formatDuration(s.Max), s.Count)
+}
+
+// formatDuration rounds a duration for display, keeping sub-millisecond detail
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
+
+// diagnosticsReport bundles versions, a config summary, metrics and recent log lines
+// for bug reports. It is left untranslated so maintainers can read any report; secrets
+// such as the HTTP API token are never included.
+func (mw *MainWindow) diagnosticsReport() string {
+	var b strings.Builder
+	section := func(title string) { fmt.Fprintf(&b, "\n## %s\n", title) }
+
+	b.WriteString("# GopherAutomate diagnostics\n")
+	fmt.Fprintf(&b, "Generated: %s\n", time.Now().Format(time.RFC3339))
+
+	section("Versions")
+	fmt.Fprintf(&b, "Go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "App: %s\n", info.Main.Version)
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+				fmt.Fprintf(&b, "%s: %s\n", setting.Key, setting.Value)
+			}
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == "fyne.io/fyne/v2" || dep.Path == "gitlab.com/gomidi/midi/v2" {
+				fmt.Fprintf(&b, "%s: %s\n", dep.Path, dep.Version)
+			}
+		}
+	}
+
+	section("Config")
+	cfg := mw.cfg
+	for _, device := range cfg.Devices {
+		fmt.Fprintf(&b, "Device %q: type=%s in=%q out=%q disabled=%t\n", device.Name, device.Type, device.InPort, device.OutPort, device.Disabled)
+	}
+	fmt.Fprintf(&b, "Device groups: %d, layouts: %d, actions: %d, action groups: %d, mappings: %d\n",
+		len(cfg.DeviceGroups), len(cfg.Menus), len(cfg.Actions), len(cfg.ActionGroups), len(cfg.MessageMappings))
+	fmt.Fprintf(&b, "Log level: %s, MIDI traffic logged: %t, pad debounce: %d ms\n", logging.LevelName(cfg.LogLevel), cfg.LogMIDITraffic, cfg.PadDebounceMs)
+	fmt.Fprintf(&b, "HTTP API: enabled=%t bind=%s port=%d\n", cfg.HTTPAPI.Enabled, cfg.HTTPAPI.BindAddress, cfg.HTTPAPI.Port)
+	fmt.Fprintf(&b, "MIDI inputs: %s\n", strings.Join(mw.inPorts, ", "))
+	fmt.Fprintf(&b, "MIDI outputs: %s\n", strings.Join(mw.outPorts, ", "))
+
+	section("Metrics")
+	fmt.Fprintf(&b, "Messages: %d last minute, %d total\n", metrics.Messages.Last(1), metrics.Messages.Total())
+	fmt.Fprintf(&b, "Errors: %d last hour, %d total\n", metrics.Errors.Last(60), metrics.Errors.Total())
+	fmt.Fprintf(&b, "Merged device updates: %d last hour, %d total\n", metrics.CoalescedSends.Last(60), metrics.CoalescedSends.Total())
+	writeTiming := func(name string, s metrics.TimingStats) {
+		fmt.Fprintf(&b, "%s: count=%d last=%s avg=%s max=%s buckets=%v\n", name, s.Count, s.Last, s.Average(), s.Max, s.Buckets)
+	}
+	writeTiming("Action latency", metrics.ActionLatency.Stats())
+	writeTiming("Dispatch delay", metrics.Dispatch.Stats())
+	writeTiming("Pad update", metrics.PadUpdate.Stats())
+	for port, s := range metrics.GridPush.Stats() {
+		writeTiming(fmt.Sprintf("Grid push %q", port), s)
+	}
+	fmt.Fprintf(&b, "Histogram bounds: %v\n", metrics.Bounds)
+
+	section("Usage")
+	fmt.Fprintf(&b, "Recorded: %t\n", !cfg.DisableUsageStats)
+	for id, c := range usage.Actions() {
+		fmt.Fprintf(&b, "Action %s: runs=%d failures=%d last=%s\n", id, c.Count, c.Failures, c.Last.Format(time.RFC3339))
+	}
+
+	section("Recent log")
+	if lines, err := logging.Tail(); err != nil {
+		fmt.Fprintf(&b, "Log unavailable: %v\n", err)
+	} else {
+		b.WriteString(strings.Join(lines[max(len(lines)-reportLogLines, 0):], "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package window
+
+import (
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// padSizeChoices are the menu editor pad sizes offered, in pixels
+var padSizeChoices = []int{24, 32, 40, 48, 56, 64}
+
+// editorChromeHeight is roughly how much of the window height the tabs, toolbars and
+// hints around the menu editor grid take, for picking a pad size that fits
+const editorChromeHeight = 260
+
+// createEditorViewBar builds the menu editor's view controls: pad size, compact color
+// panel, heat map and color annotations
+func (mw *MainWindow) createEditorViewBar() fyne.CanvasObject {
+	options := []string{i18n.T("menu_editor.pad_size_auto")}
+	for _, size := range padSizeChoices {
+		options = append(options, fmt.Sprintf("%d px", size))
+	}
+	sizeSelect := widget.NewSelect(options, func(s string) {
+		mw.cfg.PadSize = 0
+		if i := slices.Index(options, s); i > 0 {
+			mw.cfg.PadSize = padSizeChoices[i-1]
+		}
+		mw.applyPadSize()
+		mw.savePreferences()
+	})
+	selected := options[0]
+	if i := slices.Index(padSizeChoices, mw.cfg.PadSize); i >= 0 {
+		selected = options[i+1]
+	}
+	setSelectedSilently(sizeSelect, selected)
+
+	compactCheck := widget.NewCheck(i18n.T("menu_editor.compact_panel"), func(checked bool) {
+		mw.cfg.CompactColorPanel = checked
+		mw.layoutColorSections()
+		mw.savePreferences()
+	})
+	compactCheck.Checked = mw.cfg.CompactColorPanel
+
+	codesCheck, patternsCheck := mw.createAnnotationChecks()
+	return container.NewHBox(widget.NewIcon(theme.ZoomInIcon()), sizeSelect, compactCheck, mw.createHeatMapCheck(),
+		codesCheck, patternsCheck)
+}
+
+// padSize returns the pad size set in preferences, or with Auto the largest offered size
+// that lets the grid fit the window height; never below the minimum hit target
+func (mw *MainWindow) padSize() float32 {
+	minimum := float32(mw.cfg.MinHitTarget)
+	if mw.cfg.PadSize > 0 {
+		return max(float32(mw.cfg.PadSize), minimum)
+	}
+	height := mw.window.Canvas().Size().Height
+	if height <= 0 {
+		return max(40, minimum) // Not shown yet
+	}
+	fit := (height-editorChromeHeight)/9 - theme.Padding()
+	size := padSizeChoices[0]
+	for _, choice := range padSizeChoices {
+		if float32(choice) <= fit {
+			size = choice
+		}
+	}
+	return max(float32(size), minimum)
+}
+
+// applyPadSize resizes the menu editor pads and lays the grid out again
+func (mw *MainWindow) applyPadSize() {
+	size := mw.padSize()
+	for row := range 9 {
+		for col := range 9 {
+			if rect := mw.gridRects[row][col]; rect != nil {
+				rect.SetMinSize(fyne.NewSize(size, size))
+			}
+		}
+	}
+	for _, rect := range mw.outerRects {
+		rect.SetMinSize(fyne.NewSize(size, size))
+		rect.CornerRadius = size / 2
+	}
+	if mw.gridContainer != nil {
+		mw.gridContainer.Refresh()
+	}
+}
+
+// layoutColorSections shows the static and pressed color sections one above the other,
+// or as tabs in compact mode
+func (mw *MainWindow) layoutColorSections() {
+	if mw.cfg.CompactColorPanel {
+		tabs := container.NewAppTabs(
+			container.NewTabItem(i18n.T("menu_editor.static"), mw.staticSection),
+			container.NewTabItem(i18n.T("menu_editor.pressed"), mw.pressedSection),
+		)
+		mw.colorSections.Objects = []fyne.CanvasObject{tabs}
+	} else {
+		mw.colorSections.Objects = []fyne.CanvasObject{mw.staticSection, widget.NewSeparator(), mw.pressedSection}
+	}
+	mw.colorSections.Refresh()
+}
@@ -0,0 +1,80 @@
+package window
+
+import (
+	"os"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/logging"
+	"github.com/PixPMusic/gopher-automate/internal/osutil"
+)
+
+// createFilesInfo builds the Preferences card listing where the config and log files
+// are, with buttons to copy their paths and show them in the file manager
+func (mw *MainWindow) createFilesInfo() fyne.CanvasObject {
+	configPath, configErr := config.ConfigPath()
+	logPath, logErr := logging.Path()
+
+	mw.configSizeLabel = widget.NewLabel("")
+	mw.configSavedLabel = widget.NewLabel("")
+	mw.refreshFilesInfo()
+
+	return widget.NewForm(
+		widget.NewFormItem(i18n.T("prefs.files.config"), mw.pathRow(configPath, configErr)),
+		widget.NewFormItem(i18n.T("prefs.files.size"), mw.configSizeLabel),
+		widget.NewFormItem(i18n.T("prefs.files.saved"), mw.configSavedLabel),
+		widget.NewFormItem(i18n.T("prefs.files.schema"), widget.NewLabel(strconv.Itoa(config.SchemaVersion))),
+		widget.NewFormItem(i18n.T("prefs.log_file"), mw.pathRow(logPath, logErr)),
+	)
+}
+
+// pathRow shows a file path with copy and reveal buttons, or that it is unavailable
+func (mw *MainWindow) pathRow(path string, err error) fyne.CanvasObject {
+	if err != nil {
+		return widget.NewLabel(i18n.T("prefs.unavailable"))
+	}
+	label := widget.NewLabel(path)
+	label.Selectable = true
+	label.Truncation = fyne.TextTruncateEllipsis
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		mw.app.Clipboard().SetContent(path)
+	})
+	revealBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		if err := osutil.Reveal(path); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	})
+	return container.NewBorder(nil, nil, nil, container.NewHBox(copyBtn, revealBtn), label)
+}
+
+// refreshFilesInfo shows the config file's current size and when it was last saved
+func (mw *MainWindow) refreshFilesInfo() {
+	if mw.configSizeLabel == nil {
+		return
+	}
+	size, saved := i18n.T("prefs.unavailable"), i18n.T("prefs.unavailable")
+	if path, err := config.ConfigPath(); err == nil {
+		if info, err := os.Stat(path); err == nil {
+			size = formatFileSize(info.Size())
+			saved = info.ModTime().Format("2006-01-02 15:04:05")
+		} else if os.IsNotExist(err) {
+			size, saved = i18n.T("prefs.files.not_saved"), i18n.T("prefs.files.not_saved")
+		}
+	}
+	mw.configSizeLabel.SetText(size)
+	mw.configSavedLabel.SetText(saved)
+}
+
+// formatFileSize returns a file size in bytes or kilobytes
+func formatFileSize(bytes int64) string {
+	if bytes < 1024 {
+		return i18n.T("prefs.files.bytes", bytes)
+	}
+	return i18n.T("prefs.files.kilobytes", float64(bytes)/1024)
+}
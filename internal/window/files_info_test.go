@@ -0,0 +1,67 @@
+package window
+
+import (
+	"os"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+func TestFormatFileSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, i18n.T("prefs.files.bytes", 0)},
+		{1023, i18n.T("prefs.files.bytes", 1023)},
+		{1024, i18n.T("prefs.files.kilobytes", 1.0)},
+		{3584, i18n.T("prefs.files.kilobytes", 3.5)},
+	}
+	for _, tt := range tests {
+		if got := formatFileSize(tt.bytes); got != tt.want {
+			t.Errorf("formatFileSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshFilesInfo(t *testing.T) {
+	mw := newTestWindow(t)
+	mw.createFilesInfo()
+	path, err := config.ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mw.configSizeLabel.Text, formatFileSize(info.Size()); got != want {
+		t.Errorf("size shows %q, want %q", got, want)
+	}
+	if got, want := mw.configSavedLabel.Text, info.ModTime().Format("2006-01-02 15:04:05"); got != want {
+		t.Errorf("saved shows %q, want %q", got, want)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	mw.refreshFilesInfo()
+	if notSaved := i18n.T("prefs.files.not_saved"); mw.configSizeLabel.Text != notSaved || mw.configSavedLabel.Text != notSaved {
+		t.Errorf("missing file shows %q and %q, want not saved", mw.configSizeLabel.Text, mw.configSavedLabel.Text)
+	}
+}
+
+func TestPathRowCopiesPath(t *testing.T) {
+	mw := newTestWindow(t)
+	row := mw.pathRow("/some/where/config.json", nil)
+	copyBtn := findButton(row, "") // The first of the icon buttons
+	if copyBtn == nil {
+		t.Fatal("no copy button")
+	}
+	test.Tap(copyBtn)
+	if got := mw.app.Clipboard().Content(); got != "/some/where/config.json" {
+		t.Errorf("clipboard has %q, want the path", got)
+	}
+}
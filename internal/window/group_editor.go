@@ -0,0 +1,270 @@
+package window
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// actionTypeLabel returns the translated name of an action type
+func actionTypeLabel(t actions.ActionType) string {
+	switch t {
+	case actions.ActionTypeAppleScript:
+		return i18n.T("common.action_type.applescript")
+	case actions.ActionTypeSleep:
+		return i18n.T("common.action_type.sleep")
+	case actions.ActionTypeMidi:
+		return i18n.T("common.action_type.midi")
+	case actions.ActionTypeWaitForMidi:
+		return i18n.T("common.action_type.wait_midi")
+	case actions.ActionTypeShellCommand:
+		return i18n.T("common.action_type.shell")
+	}
+	if meta, ok := actions.Meta(t); ok {
+		return meta.Name
+	}
+	return i18n.T("common.action_type.shell")
+}
+
+// showGroupChildren lists the selected group's direct children in the editor, with
+// buttons to reorder them or move them out, and a picker to move an action in
+func (mw *MainWindow) showGroupChildren() {
+	group := mw.selectedGroup
+	var children []actions.TreeItem
+	for _, item := range mw.actionStore.GetSortedTree(group.ID, 0) {
+		if item.Depth == 0 {
+			children = append(children, item)
+		}
+	}
+
+	// Everything nested, for the summary
+	total, blocked := 0, 0
+	for _, item := range mw.actionStore.GetSortedTree(group.ID, 0) {
+		if item.IsGroup {
+			continue
+		}
+		total++
+		if !item.Action.AllowDangerous && len(actions.DangerMatches(item.Action, mw.cfg.DangerPatterns)) > 0 {
+			blocked++
+		}
+	}
+	summary := widget.NewLabel(i18n.T("actions.group_summary", len(children), total))
+	mw.actionEditorContent.Add(summary)
+	mw.actionEditorContent.Add(mw.groupBudgetRow(group))
+	if blocked > 0 {
+		warning := widget.NewLabel(i18n.T("actions.group_blocked", blocked))
+		warning.Importance = widget.WarningImportance
+		mw.actionEditorContent.Add(warning)
+	}
+
+	rows := container.NewVBox()
+	for i, item := range children {
+		id, kind := treeItemID(item), i18n.T("actions.group_child_group")
+		if !item.IsGroup {
+			kind = actionTypeLabel(item.Action.Type)
+		}
+		name := widget.NewLabel(treeItemName(item))
+		name.Truncation = fyne.TextTruncateEllipsis
+
+		up := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+			if item.IsGroup {
+				mw.actionStore.MoveGroupUp(id)
+			} else {
+				mw.actionStore.MoveActionUp(id)
+			}
+			mw.refreshGroupEditor(group.ID)
+		})
+		down := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+			if item.IsGroup {
+				mw.actionStore.MoveGroupDown(id)
+			} else {
+				mw.actionStore.MoveActionDown(id)
+			}
+			mw.refreshGroupEditor(group.ID)
+		})
+		if i == 0 {
+			up.Disable()
+		}
+		if i == len(children)-1 {
+			down.Disable()
+		}
+		remove := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), func() {
+			mw.actionStore.MoveToEnd(id, "")
+			mw.refreshGroupEditor(group.ID)
+		})
+
+		buttons := container.NewHBox(widget.NewLabel(kind), up, down, remove)
+		rows.Add(container.NewBorder(nil, nil, nil, buttons, name))
+	}
+	if len(children) == 0 {
+		rows.Add(widget.NewLabel(i18n.T("actions.group_empty")))
+	}
+	mw.actionEditorContent.Add(rows)
+
+	// Actions outside this group that can be moved in
+	var candidates []actions.TreeItem
+	var options []string
+	for _, item := range mw.actionStore.GetFlatList() {
+		if !item.IsGroup && item.Action.ParentGroupID != group.ID {
+			candidates = append(candidates, item)
+			options = append(options, treeItemName(item))
+		}
+	}
+	addSelect := widget.NewSelect(options, nil)
+	addSelect.PlaceHolder = i18n.T("actions.group_add_placeholder")
+	addBtn := widget.NewButtonWithIcon(i18n.T("actions.group_add"), theme.ContentAddIcon(), func() {
+		if i := slices.Index(options, addSelect.Selected); i >= 0 {
+			mw.actionStore.MoveToEnd(candidates[i].Action.ID, group.ID)
+			mw.refreshGroupEditor(group.ID)
+		}
+	})
+	if len(candidates) == 0 {
+		addSelect.Disable()
+		addBtn.Disable()
+	}
+	mw.actionEditorContent.Add(container.NewBorder(nil, nil, nil, addBtn, addSelect))
+}
+
+// refreshGroupEditor shows a group's contents again after they changed, keeping it selected
+func (mw *MainWindow) refreshGroupEditor(groupID string) {
+	if group := mw.actionStore.GetGroup(groupID); group != nil {
+		updated := *group // Moves can shift the group's own order, so drop the stale copy
+		mw.selectedGroup = &updated
+	}
+	mw.applyActionFilter()
+	mw.updateActionEditor()
+	mw.notifyTray()
+}
+
+// testGroup runs the selected group, asking first if any of its actions match a danger pattern
+func (mw *MainWindow) testGroup() {
+	if mw.testStop != nil {
+		return // One at a time, so the progress lines stay readable
+	}
+	group := *mw.selectedGroup
+	for _, item := range mw.actionStore.GetSortedTree(group.ID, 0) {
+		if item.IsGroup {
+			continue
+		}
+		if matches := actions.DangerMatches(item.Action, mw.cfg.DangerPatterns); len(matches) > 0 {
+			dialog.ShowConfirm(i18n.T("actions.dangerous_title"), i18n.T("actions.dangerous_test", item.Action.Name, matches[0]), func(confirm bool) {
+				if confirm {
+					mw.runGroupTest(group)
+				}
+			}, mw.window)
+			return
+		}
+	}
+	mw.runGroupTest(group)
+}
+
+// runGroupTest runs a group and shows a line per action in the editor as it progresses,
+// then adds them to the output as its result
+func (mw *MainWindow) runGroupTest(group actions.ActionGroup) {
+	stop := make(chan struct{})
+	var lines []string
+	show := func(summary string) {
+		mw.actionFeedback.SetText(strings.Join(append(slices.Clone(lines), summary), "\n"))
+	}
+
+	progress := func(event engine.StepEvent) {
+		fyne.Do(func() {
+			for len(lines) < event.Total {
+				lines = append(lines, "")
+			}
+			line := i18n.T("actions.group_test_running", event.Index+1, event.Total, event.Name)
+			if event.Remaining > 0 {
+				line += " " + remainingText(event.Remaining)
+			}
+			if event.Finished {
+				ms := event.Duration.Milliseconds()
+				if event.Err != nil {
+					line = i18n.T("actions.group_test_failed", event.Index+1, event.Total, event.Name, ms, event.Err)
+				} else {
+					line = i18n.T("actions.group_test_ok", event.Index+1, event.Total, event.Name, ms)
+				}
+			}
+			lines[event.Index] = line
+			show(i18n.T("actions.running"))
+		})
+	}
+	done := func(err error) {
+		fyne.Do(func() {
+			if mw.testStop == stop || mw.testStop == nil { // Not if another test started after stopping
+				mw.testStop = nil
+				mw.stopTestBtn.Hide()
+			}
+			summary := i18n.T("actions.group_test_done")
+			switch {
+			case errors.Is(err, engine.ErrStopped):
+				summary = i18n.T("actions.group_test_stopped")
+			case err != nil:
+				summary = i18n.T("actions.test_error", err)
+			}
+			mw.actionFeedback.SetText("")
+			mw.actionFeedback.AddResult(group.ID, actionResult{
+				text:       strings.Join(append(lines, summary), "\n"),
+				name:       group.Name,
+				actionType: i18n.T("actions.output.group_type"),
+			})
+		})
+	}
+
+	if !mw.engine.TestGroup(group.ID, stop, progress, done) {
+		return
+	}
+	mw.testStop = stop
+	mw.stopTestBtn.Enable()
+	mw.stopTestBtn.Show()
+	show(i18n.T("actions.running"))
+}
+
+// stopTest stops the running test: sleeps end early and groups start no further actions
+func (mw *MainWindow) stopTest() {
+	if mw.testStop != nil {
+		close(mw.testStop)
+		mw.testStop = nil
+		mw.stopTestBtn.Disable()
+	}
+}
+
+// groupBudgetRow edits how many seconds a run of the group may take, empty or 0 for no limit
+func (mw *MainWindow) groupBudgetRow(group *actions.ActionGroup) fyne.CanvasObject {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(i18n.T("actions.time_budget_none"))
+	if group.TimeBudgetSeconds > 0 {
+		entry.SetText(strconv.Itoa(group.TimeBudgetSeconds))
+	}
+	entry.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err != nil || n < 0 {
+			return errors.New(i18n.T("actions.time_budget_invalid"))
+		}
+		return nil
+	}
+	entry.OnChanged = func(s string) {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if s != "" && (err != nil || n < 0) {
+			return
+		}
+		if mw.selectedGroup != nil && mw.selectedGroup.ID == group.ID {
+			mw.selectedGroup.TimeBudgetSeconds = n
+			mw.actionStore.UpdateGroup(mw.selectedGroup)
+		}
+	}
+	hint := widget.NewLabel(i18n.T("actions.time_budget_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(container.NewBorder(nil, nil, widget.NewLabel(i18n.T("actions.time_budget")), nil, entry), hint)
+}
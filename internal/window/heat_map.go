@@ -0,0 +1,79 @@
+package window
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// heatUnused is the heat map tint of pads that were never pressed
+var heatUnused = color.RGBA{R: 40, G: 40, B: 40, A: 255}
+
+// createHeatMapCheck returns the check that tints the grid by press count
+func (mw *MainWindow) createHeatMapCheck() *widget.Check {
+	check := widget.NewCheck(i18n.T("menu_editor.heat_map"), func(checked bool) {
+		mw.heatMap = checked
+		mw.refreshGrid()
+		mw.updatePadUsage()
+	})
+	mw.padUsageLabel = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	mw.padUsageLabel.Hide()
+	return check
+}
+
+// padFill returns the grid color of a pad: its own color, or its heat with the heat map on
+func (mw *MainWindow) padFill(row, col int, most uint64) color.Color {
+	menu := mw.cfg.GetCurrentMenu()
+	if !mw.heatMap {
+		c := menu.EffectiveColor(row, col)
+		return padcolor.RGBA(c.R, c.G, c.B)
+	}
+	return heatColor(usage.Pad(menu.ID, row, col).Count, most)
+}
+
+// mostPresses returns the highest press count of any pad of the current layout
+func (mw *MainWindow) mostPresses() uint64 {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil || !mw.heatMap {
+		return 0
+	}
+	most := uint64(0)
+	for row := range 9 {
+		for col := range 9 {
+			most = max(most, usage.Pad(menu.ID, row, col).Count)
+		}
+	}
+	return most
+}
+
+// heatColor blends from blue for rarely pressed pads to red for the most pressed one
+func heatColor(count, most uint64) color.Color {
+	if count == 0 || most == 0 {
+		return heatUnused
+	}
+	heat := float64(count) / float64(most)
+	return color.RGBA{R: uint8(40 + 215*heat), G: 40, B: uint8(40 + 215*(1-heat)), A: 255}
+}
+
+// updatePadUsage shows how often the selected pad was pressed while the heat map is on
+func (mw *MainWindow) updatePadUsage() {
+	menu := mw.cfg.GetCurrentMenu()
+	if mw.padUsageLabel == nil {
+		return
+	}
+	if !mw.heatMap || menu == nil {
+		mw.padUsageLabel.Hide()
+		return
+	}
+	c := usage.Pad(menu.ID, mw.selectedRow, mw.selectedCol)
+	if c.Count == 0 {
+		mw.padUsageLabel.SetText(i18n.T("menu_editor.pad_never_pressed"))
+	} else {
+		mw.padUsageLabel.SetText(i18n.T("menu_editor.pad_presses", c.Count, c.Last.Format("2006-01-02 15:04")))
+	}
+	mw.padUsageLabel.Show()
+}
@@ -0,0 +1,133 @@
+package window
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// newOverrideCheck builds the toggle between a pad's own static or pressed color and
+// the layout default, with the badge shown while it inherits
+func (mw *MainWindow) newOverrideCheck(pressed bool) (*widget.Check, *widget.Label) {
+	check := widget.NewCheck(i18n.T("menu_editor.override_default"), func(checked bool) {
+		mw.setPadOverride(pressed, checked)
+	})
+	badge := widget.NewLabel(i18n.T("menu_editor.inherited"))
+	badge.Importance = widget.LowImportance
+	return check, badge
+}
+
+// updateOverrideChecks shows whether the pad sets its own colors
+func (mw *MainWindow) updateOverrideChecks(pad config.PadColorConfig) {
+	if mw.overrideStaticCheck == nil {
+		return
+	}
+	show := func(check *widget.Check, badge *widget.Label, own bool) {
+		setCheckedSilently(check, own)
+		if own {
+			badge.Hide()
+		} else {
+			badge.Show()
+		}
+	}
+	show(mw.overrideStaticCheck, mw.staticInheritedLabel, pad.HasOwnStatic())
+	show(mw.overridePressedCheck, mw.pressedInheritedLabel, pad.HasOwnPressed())
+}
+
+// setPadOverride makes the selected pad keep its own static or pressed color, starting
+// from the inherited one, or clears it so the pad shows the layout default again
+func (mw *MainWindow) setPadOverride(pressed, override bool) {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+	effective := menu.EffectiveColor(mw.selectedRow, mw.selectedCol)
+	pad := &menu.Colors[mw.selectedRow][mw.selectedCol]
+	if pressed {
+		if !override {
+			effective = config.PadColorConfig{}
+		}
+		pad.PressedR, pad.PressedG, pad.PressedB = effective.PressedR, effective.PressedG, effective.PressedB
+		pad.ClassicPressedR, pad.ClassicPressedG, pad.ClassicPressedB = effective.ClassicPressedR, effective.ClassicPressedG, effective.ClassicPressedB
+		pad.OverridePressed = override
+	} else {
+		if !override {
+			effective = config.PadColorConfig{}
+		}
+		pad.R, pad.G, pad.B = effective.R, effective.G, effective.B
+		pad.ClassicR, pad.ClassicG, pad.ClassicB = effective.ClassicR, effective.ClassicG, effective.ClassicB
+		pad.OverrideStatic = override
+	}
+	mw.setDirty(true)
+	mw.selectPad(mw.selectedRow, mw.selectedCol)
+}
+
+// claimPadColor makes the selected pad keep its own static or pressed color before
+// the panel's sliders are stored, so editing an inherited color overrides it
+func (mw *MainWindow) claimPadColor(pressed bool) {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+	pad := &menu.Colors[mw.selectedRow][mw.selectedCol]
+	if pressed {
+		pad.OverridePressed = true
+	} else {
+		pad.OverrideStatic = true
+	}
+	mw.updateOverrideChecks(*pad)
+}
+
+// showLayoutDefaultsDialog edits the static and pressed colors of pads in the current
+// layout that don't set their own. Classic colors follow the RGB ones.
+func (mw *MainWindow) showLayoutDefaultsDialog() {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+
+	// colorEditor builds RGB sliders and a preview bound to one default color
+	colorEditor := func(r, g, b, classicR, classicG *uint8) fyne.CanvasObject {
+		preview := canvas.NewRectangle(padcolor.RGBA(*r, *g, *b))
+		preview.SetMinSize(fyne.NewSize(30, 15))
+		preview.CornerRadius = 3
+
+		var sliders []*widget.Slider
+		changed := func(float64) {
+			*r, *g, *b = uint8(sliders[0].Value), uint8(sliders[1].Value), uint8(sliders[2].Value)
+			rLevel, gLevel := padcolor.ClassicLevels(*r, *g, *b)
+			*classicR, *classicG = padcolor.LevelValue(rLevel), padcolor.LevelValue(gLevel)
+			preview.FillColor = padcolor.RGBA(*r, *g, *b)
+			preview.Refresh()
+			mw.setDirty(true)
+			mw.refreshGrid()
+			mw.selectPad(mw.selectedRow, mw.selectedCol) // Shows the new color if the pad inherits it
+		}
+		form := widget.NewForm()
+		for i, value := range []*uint8{r, g, b} {
+			slider := widget.NewSlider(0, 127)
+			slider.Value = float64(*value)
+			slider.OnChanged = changed
+			sliders = append(sliders, slider)
+			form.Append([]string{"R", "G", "B"}[i], slider)
+		}
+		return container.NewBorder(nil, nil, nil, container.NewCenter(preview), form)
+	}
+
+	static, pressed := &menu.DefaultStatic, &menu.DefaultPressed
+	hint := widget.NewLabel(i18n.T("menu_editor.layout_defaults_hint"))
+	hint.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(
+		hint,
+		widget.NewCard(i18n.T("menu_editor.static"), "", colorEditor(&static.R, &static.G, &static.B, &static.ClassicR, &static.ClassicG)),
+		widget.NewCard(i18n.T("menu_editor.pressed"), "", colorEditor(&pressed.PressedR, &pressed.PressedG, &pressed.PressedB, &pressed.ClassicPressedR, &pressed.ClassicPressedG)),
+	)
+	dlg := dialog.NewCustom(i18n.T("menu_editor.layout_defaults_title", menu.Name), i18n.T("common.close"), content, mw.window)
+	dlg.Resize(fyne.NewSize(420, 0))
+	dlg.Show()
+}
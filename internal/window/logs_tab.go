@@ -0,0 +1,105 @@
+package window
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/logging"
+)
+
+// logPollInterval is how often the log file is checked for new lines
+const logPollInterval = 2 * time.Second
+
+// ============ LOGS TAB ============
+
+func (mw *MainWindow) createLogsTab() fyne.CanvasObject {
+	header := widget.NewLabel(i18n.T("common.logs"))
+	header.TextStyle = fyne.TextStyle{Bold: true}
+
+	mw.logList = widget.NewList(
+		func() int { return len(mw.logLines) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < len(mw.logLines) {
+				obj.(*widget.Label).SetText(mw.logLines[id])
+			}
+		},
+	)
+
+	// Filter only changes what is shown; the log level in Preferences changes what is written
+	filterLevels := []string{"all", "info", "warn", "error"}
+	filterOptions := []string{i18n.T("logs.filter.all"), i18n.T("logs.filter.info"), i18n.T("logs.filter.warn"), i18n.T("logs.filter.error")}
+	filterSelect := widget.NewSelect(filterOptions, func(s string) {
+		mw.logFilter = filterLevels[slices.Index(filterOptions, s)]
+		mw.reloadLogs()
+	})
+	setSelectedSilently(filterSelect, filterOptions[0])
+
+	refreshBtn := widget.NewButtonWithIcon(i18n.T("logs.refresh"), theme.ViewRefreshIcon(), func() { mw.reloadLogs() })
+	copyBtn := widget.NewButtonWithIcon(i18n.T("logs.copy"), theme.ContentCopyIcon(), func() {
+		mw.app.Clipboard().SetContent(strings.Join(mw.logLines, "\n"))
+	})
+	toolbar := container.NewHBox(widget.NewLabel(i18n.T("logs.show_label")), filterSelect, refreshBtn, copyBtn)
+
+	mw.reloadLogs()
+	if logPath, err := logging.Path(); err == nil {
+		go mw.watchLogFile(logPath)
+	}
+
+	return container.NewBorder(
+		container.NewVBox(header, toolbar, widget.NewSeparator()),
+		nil, nil, nil,
+		mw.logList,
+	)
+}
+
+// reloadLogs reads the tail of the log file and applies the level filter
+func (mw *MainWindow) reloadLogs() {
+	lines, err := logging.Tail()
+	if err != nil {
+		lines = []string{i18n.T("logs.read_failed", err)}
+	}
+
+	mw.logLines = mw.logLines[:0]
+	for _, line := range lines {
+		if logLineVisible(line, mw.logFilter) {
+			mw.logLines = append(mw.logLines, line)
+		}
+	}
+	mw.logList.Refresh()
+	mw.logList.ScrollToBottom()
+}
+
+// logLineVisible reports whether a line meets the minimum level picked in the filter
+func logLineVisible(line, filter string) bool {
+	if filter == "" || filter == "all" {
+		return true
+	}
+	level := logging.LineLevel(line)
+	return level == "" || logging.ParseLevel(level) >= logging.ParseLevel(filter)
+}
+
+// watchLogFile reloads the view whenever the log file grows or is rotated
+func (mw *MainWindow) watchLogFile(path string) {
+	var lastSize int64
+	for range time.Tick(logPollInterval) {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == lastSize {
+			continue
+		}
+		lastSize = info.Size()
+		fyne.Do(mw.reloadLogs)
+	}
+}
@@ -0,0 +1,72 @@
+package window
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// createMacroRecorder builds the record button and its silent option for the action list toolbar
+func (mw *MainWindow) createMacroRecorder() fyne.CanvasObject {
+	silentCheck := widget.NewCheck(i18n.T("actions.macro_silent"), nil)
+
+	var recordBtn *widget.Button
+	recordBtn = widget.NewButtonWithIcon(i18n.T("actions.macro_record"), theme.MediaRecordIcon(), func() {
+		if !mw.engine.MacroRecording() {
+			mw.engine.StartMacroRecording(silentCheck.Checked)
+			recordBtn.SetText(i18n.T("actions.macro_stop"))
+			recordBtn.SetIcon(theme.MediaStopIcon())
+			recordBtn.Importance = widget.DangerImportance
+			recordBtn.Refresh()
+			silentCheck.Disable()
+			return
+		}
+
+		steps := mw.engine.StopMacroRecording()
+		recordBtn.SetText(i18n.T("actions.macro_record"))
+		recordBtn.SetIcon(theme.MediaRecordIcon())
+		recordBtn.Importance = widget.MediumImportance
+		recordBtn.Refresh()
+		silentCheck.Enable()
+		mw.saveMacro(steps)
+	})
+
+	return container.NewHBox(recordBtn, silentCheck)
+}
+
+// saveMacro asks for a name and turns recorded presses into a new action group
+func (mw *MainWindow) saveMacro(steps []actions.MacroStep) {
+	if len(steps) == 0 {
+		dialog.ShowInformation(i18n.T("actions.macro_title"), i18n.T("actions.macro_empty"), mw.window)
+		return
+	}
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(i18n.T("actions.macro_default_name", time.Now().Format("2006-01-02 15:04")))
+	keepDelaysCheck := widget.NewCheck(i18n.T("actions.macro_keep_delays"), nil)
+	keepDelaysCheck.SetChecked(true)
+
+	content := container.NewVBox(
+		widget.NewLabel(i18n.T("actions.macro_recorded", len(steps))),
+		nameEntry,
+		keepDelaysCheck,
+	)
+	dialog.ShowCustomConfirm(i18n.T("actions.macro_title"), i18n.T("common.create"), i18n.T("common.cancel"), content, func(confirm bool) {
+		if !confirm || nameEntry.Text == "" {
+			return
+		}
+		group := mw.actionStore.AddMacro(nameEntry.Text, steps, keepDelaysCheck.Checked)
+		if group == nil {
+			dialog.ShowInformation(i18n.T("actions.macro_title"), i18n.T("actions.macro_empty"), mw.window)
+			return
+		}
+		mw.actionList.Refresh()
+		mw.selectActionListItem(group.ID)
+	}, mw.window)
+}
@@ -2,7 +2,8 @@ package window
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -10,26 +11,27 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
 )
 
 // ============ MESSAGE MAPPING TAB ============
 
 func (mw *MainWindow) createMessageMappingTab() fyne.CanvasObject {
-	header := widget.NewLabel("Message Mapping")
+	header := widget.NewLabel(i18n.T("common.message_mapping"))
 	header.TextStyle = fyne.TextStyle{Bold: true}
 
-	subtitle := widget.NewLabel("Map MIDI messages from Generic devices to actions (inter-app communication)")
+	subtitle := widget.NewLabel(i18n.T("mapping.subtitle"))
 
 	// Column headers
-	headerName := widget.NewLabel("Name")
+	headerName := widget.NewLabel(i18n.T("common.name"))
 	headerName.TextStyle = fyne.TextStyle{Bold: true}
-	headerType := widget.NewLabel("Type")
+	headerType := widget.NewLabel(i18n.T("common.type"))
 	headerType.TextStyle = fyne.TextStyle{Bold: true}
-	headerChannel := widget.NewLabel("Channel")
+	headerChannel := widget.NewLabel(i18n.T("mapping.channel"))
 	headerChannel.TextStyle = fyne.TextStyle{Bold: true}
-	headerNumber := widget.NewLabel("Number")
+	headerNumber := widget.NewLabel(i18n.T("mapping.number"))
 	headerNumber.TextStyle = fyne.TextStyle{Bold: true}
-	headerAction := widget.NewLabel("Action")
+	headerAction := widget.NewLabel(i18n.T("common.action"))
 	headerAction.TextStyle = fyne.TextStyle{Bold: true}
 	headerDelete := widget.NewLabel("")
 
@@ -51,19 +53,23 @@ func (mw *MainWindow) createMessageMappingTab() fyne.CanvasObject {
 	)
 
 	// Toolbar
-	addBtn := widget.NewButtonWithIcon("Add Mapping", theme.ContentAddIcon(), func() {
+	addBtn := widget.NewButtonWithIcon(i18n.T("mapping.add"), theme.ContentAddIcon(), func() {
 		mw.addMessageMapping()
 	})
 	listToolbar := container.NewHBox(addBtn)
 
+	// Mappings that match the same messages, refreshed as mappings are edited
+	mw.mappingOverlaps = container.NewVBox()
+	mw.refreshMappingOverlaps()
+
 	// Save button
-	saveBtn := widget.NewButtonWithIcon("Save Mappings", theme.DocumentSaveIcon(), func() {
+	saveBtn := widget.NewButtonWithIcon(i18n.T("mapping.save"), theme.DocumentSaveIcon(), func() {
 		mw.saveMessageMappings()
 	})
 	saveBtn.Importance = widget.HighImportance
 
 	return container.NewBorder(
-		container.NewVBox(header, subtitle, widget.NewSeparator(), listToolbar, columnHeaders),
+		container.NewVBox(header, subtitle, widget.NewSeparator(), listToolbar, mw.mappingOverlaps, columnHeaders),
 		container.NewVBox(widget.NewSeparator(), container.NewHBox(saveBtn)),
 		nil, nil,
 		mw.mappingList,
@@ -72,23 +78,21 @@ func (mw *MainWindow) createMessageMappingTab() fyne.CanvasObject {
 
 func (mw *MainWindow) createMappingListItem() fyne.CanvasObject {
 	nameEntry := widget.NewEntry()
-	nameEntry.SetPlaceHolder("Mapping name")
+	nameEntry.SetPlaceHolder(i18n.T("mapping.name_placeholder"))
 
-	typeSelect := widget.NewSelect([]string{"Note", "CC", "Program Change"}, nil)
-	typeSelect.PlaceHolder = "Type"
+	typeSelect := widget.NewSelect([]string{i18n.T("common.midi.note"), i18n.T("common.midi.cc"), i18n.T("common.midi.program_change")}, nil)
+	typeSelect.PlaceHolder = i18n.T("common.type")
 
-	channelSelect := widget.NewSelect([]string{"Any", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15", "16"}, nil)
-	channelSelect.PlaceHolder = "Ch"
+	channelSelect := widget.NewSelect([]string{i18n.T("common.any"), "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15", "16"}, nil)
+	channelSelect.PlaceHolder = i18n.T("mapping.channel_placeholder")
 
-	numberEntry := widget.NewEntry()
-	numberEntry.SetPlaceHolder("0-127")
+	numberEntry := newIntEntry(0, 127)
 
-	actionSelect := widget.NewSelect([]string{"(None)"}, nil)
-	actionSelect.PlaceHolder = "Action"
+	picker := mw.newActionPicker(nil)
 
 	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
 
-	return container.NewGridWithColumns(6, nameEntry, typeSelect, channelSelect, numberEntry, actionSelect, deleteBtn)
+	return container.NewGridWithColumns(6, nameEntry, typeSelect, channelSelect, numberEntry, picker, deleteBtn)
 }
 
 func (mw *MainWindow) updateMappingListItem(id widget.ListItemID, obj fyne.CanvasObject) {
@@ -96,138 +100,164 @@ func (mw *MainWindow) updateMappingListItem(id widget.ListItemID, obj fyne.Canva
 		return
 	}
 
-	mapping := &mw.cfg.MessageMappings[id]
+	mapping := mw.cfg.MessageMappings[id]
 	row := obj.(*fyne.Container)
 
 	nameEntry := row.Objects[0].(*widget.Entry)
 	typeSelect := row.Objects[1].(*widget.Select)
 	channelSelect := row.Objects[2].(*widget.Select)
-	numberEntry := row.Objects[3].(*widget.Entry)
-	actionSelect := row.Objects[4].(*widget.Select)
+	numberEntry := row.Objects[3].(*intEntry)
+	picker := row.Objects[4].(*actionPicker)
 	deleteBtn := row.Objects[5].(*widget.Button)
 
-	// Set up delete button
+	// Edits look the mapping up by ID: rows are recycled and deleting a mapping shifts
+	// the slice, so a pointer taken here could end up pointing at a different mapping
 	mappingID := mapping.ID
+	update := func(apply func(m *config.MessageMapping)) {
+		if m := mw.cfg.GetMessageMapping(mappingID); m != nil {
+			apply(m)
+			mw.refreshMappingOverlaps()
+		}
+	}
+
+	// Set up delete button
 	deleteBtn.OnTapped = func() {
 		mw.deleteMappingByID(mappingID)
 	}
 
 	// Set up name entry
-	nameEntry.SetText(mapping.Name)
+	setTextSilently(nameEntry, mapping.Name)
 	nameEntry.OnChanged = func(s string) {
-		mapping.Name = s
+		update(func(m *config.MessageMapping) { m.Name = s })
 	}
 
 	// Set up message type
 	switch mapping.MessageType {
 	case "note":
-		typeSelect.SetSelected("Note")
+		setSelectedSilently(typeSelect, i18n.T("common.midi.note"))
 	case "cc":
-		typeSelect.SetSelected("CC")
+		setSelectedSilently(typeSelect, i18n.T("common.midi.cc"))
 	case "program_change":
-		typeSelect.SetSelected("Program Change")
+		setSelectedSilently(typeSelect, i18n.T("common.midi.program_change"))
 	}
 	typeSelect.OnChanged = func(s string) {
+		var msgType string
 		switch s {
-		case "Note":
-			mapping.MessageType = "note"
-		case "CC":
-			mapping.MessageType = "cc"
-		case "Program Change":
-			mapping.MessageType = "program_change"
+		case i18n.T("common.midi.note"):
+			msgType = "note"
+		case i18n.T("common.midi.cc"):
+			msgType = "cc"
+		case i18n.T("common.midi.program_change"):
+			msgType = "program_change"
+		default:
+			return
 		}
+		update(func(m *config.MessageMapping) { m.MessageType = msgType })
 	}
 
 	// Set up channel
 	if mapping.Channel == -1 {
-		channelSelect.SetSelected("Any")
+		setSelectedSilently(channelSelect, i18n.T("common.any"))
 	} else {
-		channelSelect.SetSelected(fmt.Sprintf("%d", mapping.Channel+1))
+		setSelectedSilently(channelSelect, fmt.Sprintf("%d", mapping.Channel+1))
 	}
 	channelSelect.OnChanged = func(s string) {
-		if s == "Any" {
-			mapping.Channel = -1
-		} else {
+		channel := -1
+		if s != i18n.T("common.any") {
 			var ch int
 			fmt.Sscanf(s, "%d", &ch)
-			mapping.Channel = ch - 1
+			channel = ch - 1
 		}
+		update(func(m *config.MessageMapping) { m.Channel = channel })
 	}
 
 	// Set up number
-	numberEntry.SetText(fmt.Sprintf("%d", mapping.Number))
-	numberEntry.OnChanged = func(s string) {
-		var num int
-		if _, err := fmt.Sscanf(s, "%d", &num); err == nil && num >= 0 && num <= 127 {
-			mapping.Number = num
-		}
-	}
-
-	// Set up action dropdown
-	mw.refreshMappingActionOptions(actionSelect)
-	if mapping.ActionID == "" {
-		actionSelect.SetSelected("(None)")
-	} else {
-		action := mw.cfg.GetAction(mapping.ActionID)
-		if action != nil {
-			actionSelect.SetSelected(action.Name)
-		} else {
-			actionSelect.SetSelected("(None)")
-		}
+	numberEntry.OnValue = nil
+	numberEntry.SetValue(mapping.Number)
+	numberEntry.OnValue = func(num int) {
+		update(func(m *config.MessageMapping) { m.Number = num })
 	}
-	actionSelect.OnChanged = func(s string) {
-		if s == "(None)" {
-			mapping.ActionID = ""
-		} else {
-			// Find action by name
-			for _, a := range mw.cfg.Actions {
-				if a.Name == s {
-					mapping.ActionID = a.ID
-					break
-				}
-			}
-		}
-	}
-}
 
-func (mw *MainWindow) refreshMappingActionOptions(actionSelect *widget.Select) {
-	options := []string{"(None)"}
-	for _, a := range mw.cfg.Actions {
-		options = append(options, a.Name)
+	// Set up action picker; an ID that no longer exists shows as none
+	picker.SetChoices(mw.actionChoices())
+	picker.SetSelected(mapping.ActionID)
+	picker.OnChanged = func(actionID string) {
+		update(func(m *config.MessageMapping) { m.ActionID = actionID })
 	}
-	actionSelect.Options = options
 }
 
 func (mw *MainWindow) addMessageMapping() {
 	mapping := config.NewMessageMapping()
 	mw.cfg.MessageMappings = append(mw.cfg.MessageMappings, mapping)
 	mw.mappingList.Refresh()
+	mw.refreshMappingOverlaps()
 }
 
 func (mw *MainWindow) deleteMappingByID(id string) {
-	// Find the mapping by ID
-	for i, m := range mw.cfg.MessageMappings {
-		if m.ID == id {
-			dialog.ShowConfirm("Delete Mapping", "Are you sure you want to delete '"+m.Name+"'?",
-				func(confirm bool) {
-					if confirm {
-						mw.cfg.MessageMappings = append(
-							mw.cfg.MessageMappings[:i],
-							mw.cfg.MessageMappings[i+1:]...,
-						)
-						mw.mappingList.Refresh()
-					}
-				}, mw.window)
-			return
-		}
+	m := mw.cfg.GetMessageMapping(id)
+	if m == nil {
+		return
 	}
+	dialog.ShowConfirm(i18n.T("mapping.delete_title"), i18n.T("common.confirm_delete", m.Name),
+		func(confirm bool) {
+			if confirm {
+				mw.cfg.MessageMappings = slices.DeleteFunc(mw.cfg.MessageMappings, func(m config.MessageMapping) bool {
+					return m.ID == id
+				})
+				mw.mappingList.Refresh()
+				mw.refreshMappingOverlaps()
+			}
+		}, mw.window)
 }
 
 func (mw *MainWindow) saveMessageMappings() {
-	if err := mw.cfg.Save(); err != nil {
-		log.Printf("Failed to save message mappings: %v", err)
+	mw.cfg.PruneAllowedOverlaps()
+	err := config.Update(func(saved *config.Config) {
+		saved.MessageMappings = mw.cfg.MessageMappings
+		saved.AllowedOverlaps = mw.cfg.AllowedOverlaps
+	})
+	if err != nil {
+		slog.Error("Failed to save message mappings", "err", err)
+		dialog.ShowError(err, mw.window)
+		return
+	}
+	message := i18n.T("mapping.saved")
+	if overlaps := mw.cfg.FindMappingOverlaps(); len(overlaps) > 0 {
+		message += "\n\n" + i18n.N("mapping.saved_overlaps", len(overlaps), len(overlaps))
+	}
+	dialog.ShowInformation(i18n.T("common.saved"), message, mw.window)
+}
+
+// refreshMappingOverlaps lists the pairs of mappings that match the same messages, each
+// with a button to allow it. Pairs running the same action are warnings, as a message
+// runs it twice; others may be meant to run several actions at once.
+func (mw *MainWindow) refreshMappingOverlaps() {
+	if mw.mappingOverlaps == nil {
+		return
+	}
+	mw.mappingOverlaps.RemoveAll()
+	for _, overlap := range mw.cfg.FindMappingOverlaps() {
+		icon, text := theme.InfoIcon(), i18n.T("mapping.overlap_fanout", overlap.A.Name, overlap.B.Name)
+		if overlap.SameAction() {
+			icon, text = theme.WarningIcon(), i18n.T("mapping.overlap_duplicate", overlap.A.Name, overlap.B.Name)
+		}
+		label := widget.NewLabel(text)
+		label.Wrapping = fyne.TextWrapWord
+		allowBtn := widget.NewButton(i18n.T("mapping.overlap_allow"), func() { mw.allowMappingOverlap(overlap) })
+		mw.mappingOverlaps.Add(container.NewBorder(nil, nil, widget.NewIcon(icon), allowBtn, label))
+	}
+	mw.mappingOverlaps.Refresh()
+}
+
+// allowMappingOverlap stops reporting a pair of overlapping mappings. The pair is saved
+// right away, without saving other unsaved edits.
+func (mw *MainWindow) allowMappingOverlap(overlap config.MappingOverlap) {
+	mw.cfg.AllowMappingOverlap(overlap)
+	mw.refreshMappingOverlaps()
+	err := config.Update(func(saved *config.Config) {
+		saved.AllowMappingOverlap(overlap)
+	})
+	if err != nil {
 		dialog.ShowError(err, mw.window)
-	} else {
-		dialog.ShowInformation("Saved", "Message mappings saved successfully.", mw.window)
 	}
 }
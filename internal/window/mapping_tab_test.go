@@ -0,0 +1,76 @@
+package window
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// mappingRow binds a fresh mapping row to the mapping at index id, as the list does
+func mappingRow(mw *MainWindow, id int) *fyne.Container {
+	row := mw.createMappingListItem().(*fyne.Container)
+	mw.updateMappingListItem(id, row)
+	return row
+}
+
+func TestMappingRowsEditByIDAfterDelete(t *testing.T) {
+	mw := newTestWindow(t)
+	for _, name := range []string{"A", "B", "C"} {
+		m := config.NewMessageMapping()
+		m.Name, m.MessageType = name, "note"
+		mw.cfg.MessageMappings = append(mw.cfg.MessageMappings, m)
+	}
+	rows := []*fyne.Container{mappingRow(mw, 0), mappingRow(mw, 1), mappingRow(mw, 2)}
+
+	// Delete B from its row; the rows keep their bindings until the list re-renders them
+	test.Tap(rows[1].Objects[5].(*widget.Button))
+	tapButton(t, mw, "Yes")
+	if n := len(mw.cfg.MessageMappings); n != 2 {
+		t.Fatalf("%d mappings after deleting one, want 2", n)
+	}
+
+	// C's row now sits past the end of the slice, B's row points at nothing
+	rows[2].Objects[0].(*widget.Entry).SetText("C edited")
+	rows[2].Objects[3].(*intEntry).SetText("42") // As typed; SetValue is silent
+	rows[0].Objects[0].(*widget.Entry).SetText("A edited")
+	rows[1].Objects[0].(*widget.Entry).SetText("B edited")
+
+	got := mw.cfg.MessageMappings
+	if got[0].Name != "A edited" || got[1].Name != "C edited" {
+		t.Errorf("names = %q, %q, want %q, %q", got[0].Name, got[1].Name, "A edited", "C edited")
+	}
+	if got[0].Number != 60 || got[1].Number != 42 {
+		t.Errorf("numbers = %d, %d, want 60, 42", got[0].Number, got[1].Number)
+	}
+}
+
+func TestDeviceRowsActByIDAfterRemove(t *testing.T) {
+	mw := newTestWindow(t)
+	for _, name := range []string{"A", "B", "C"} {
+		d := config.NewDeviceConfig()
+		d.Name = name
+		mw.cfg.Devices = append(mw.cfg.Devices, d)
+	}
+	rows := make([]*fyne.Container, 3)
+	for i := range rows {
+		rows[i] = mw.createDeviceRow().(*fyne.Container)
+		mw.updateDeviceRow(i, rows[i])
+	}
+	removeBtn := func(row *fyne.Container) *widget.Button {
+		buttons := row.Objects[4].(*fyne.Container).Objects[0].(*fyne.Container)
+		return buttons.Objects[4].(*widget.Button)
+	}
+
+	test.Tap(removeBtn(rows[1]))
+	tapButton(t, mw, "Yes")
+
+	// C's stale row still removes C, not whatever moved into its index
+	test.Tap(removeBtn(rows[2]))
+	tapButton(t, mw, "Yes")
+	if n := len(mw.cfg.Devices); n != 1 || mw.cfg.Devices[0].Name != "A" {
+		t.Errorf("devices left = %v, want only A", mw.cfg.Devices)
+	}
+}
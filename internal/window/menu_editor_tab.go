@@ -1,19 +1,28 @@
 package window
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
-	"log"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/PixPMusic/gopher-automate/internal/config"
-	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/importers"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
 	"github.com/golang/freetype"
 	"github.com/golang/freetype/truetype"
 )
@@ -21,82 +30,155 @@ import (
 // ============ MENU EDITOR TAB ============
 
 func (mw *MainWindow) createMenuEditorTab() fyne.CanvasObject {
-	header := widget.NewLabel("Menu Editor")
+	header := widget.NewLabel(i18n.T("common.menu_editor"))
 	header.TextStyle = fyne.TextStyle{Bold: true}
 
 	// Create grid container FIRST (before dropdown can trigger refresh)
 	mw.gridContainer = container.NewCenter(mw.createPadGrid())
 
-	// Layout dropdown - create without callback initially
-	layoutLabel := widget.NewLabel("Layout:")
-	mw.layoutDropdown = widget.NewSelect(mw.getLayoutNames(), nil)
-	mw.layoutDropdown.SetSelected(mw.getCurrentLayoutName())
-	// Now set the callback after initial selection
-	mw.layoutDropdown.OnChanged = func(selected string) {
-		mw.loadLayoutByName(selected)
-	}
+	// Layout dropdown - programmatic selection goes through refreshLayoutDropdown
+	layoutLabel := widget.NewLabel(i18n.T("menu_editor.layout_label"))
+	mw.layoutDropdown = widget.NewSelect(nil, func(selected string) {
+		mw.loadLayoutByName(layoutNameFromOption(selected))
+	})
+	mw.refreshLayoutDropdown()
 
 	// New layout button
-	newBtn := widget.NewButtonWithIcon("New", theme.ContentAddIcon(), func() {
+	newBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.new"), theme.ContentAddIcon(), func() {
 		mw.createNewLayout()
 	})
 
 	// Delete layout button
-	deleteBtn := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {
+	deleteBtn := widget.NewButtonWithIcon(i18n.T("common.delete"), theme.DeleteIcon(), func() {
 		mw.deleteCurrentLayout()
 	})
 
 	// Rename button
-	renameBtn := widget.NewButtonWithIcon("Rename", theme.DocumentCreateIcon(), func() {
+	renameBtn := widget.NewButtonWithIcon(i18n.T("common.rename"), theme.DocumentCreateIcon(), func() {
 		mw.renameCurrentLayout()
 	})
 
-	layoutBar := container.NewHBox(layoutLabel, mw.layoutDropdown, newBtn, renameBtn, deleteBtn)
+	// Import a layout made in Novation Components
+	importBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.import_components"), theme.FolderOpenIcon(), func() {
+		mw.importComponentsLayout()
+	})
+
+	// Colors for pads that don't set their own
+	defaultsBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.layout_defaults"), theme.ColorPaletteIcon(), func() {
+		mw.showLayoutDefaultsDialog()
+	})
+
+	// Pads that are hard to make out on the configured devices
+	visibilityBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.visibility.button"), theme.VisibilityIcon(), func() {
+		mw.showVisibilityCheck()
+	})
+
+	// Show this layout on devices without going through the Devices tab
+	assignBtn := widget.NewButtonWithIcon(i18n.T("assign_layout.button"), theme.UploadIcon(), func() {
+		mw.showAssignLayout()
+	})
+
+	// Runs when a pad without an action is pressed; offers what pads can be assigned
+	fallbackLabel := widget.NewLabel(i18n.T("menu_editor.fallback_label"))
+	mw.fallbackPicker = mw.newActionPicker(func(id string) {
+		mw.setLayoutAction(func(m *config.MenuLayout) { m.FallbackActionID = id })
+	})
+
+	// Runs when devices switch to the layout, e.g. to load a matching preset on outboard gear
+	activateLabel := widget.NewLabel(i18n.T("menu_editor.on_activate_label"))
+	mw.activatePicker = mw.newActionPicker(func(id string) {
+		mw.setLayoutAction(func(m *config.MenuLayout) { m.OnActivateActionID = id })
+	})
+
+	// Pad size, compact color panel and heat map
+	viewBar := mw.createEditorViewBar()
 
-	subtitle := widget.NewLabel("Click a pad to select it, then adjust colors in the panel.")
+	layoutBar := container.NewHBox(layoutLabel, mw.layoutDropdown, newBtn, renameBtn, deleteBtn, importBtn, defaultsBtn, visibilityBtn, assignBtn,
+		fallbackLabel, mw.fallbackPicker, activateLabel, mw.activatePicker)
+
+	subtitle := widget.NewLabel(i18n.T("menu_editor.hint"))
 
 	// Action buttons
-	mw.revertBtn = widget.NewButtonWithIcon("Revert", theme.ContentUndoIcon(), func() {
+	mw.revertBtn = widget.NewButtonWithIcon(i18n.T("menu_editor.revert"), theme.ContentUndoIcon(), func() {
 		mw.revertLayout()
 	})
 	mw.revertBtn.Disable() // Start disabled since no changes yet
 
-	clearBtn := widget.NewButtonWithIcon("Clear All", theme.ContentClearIcon(), func() {
+	clearBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.clear_all"), theme.ContentClearIcon(), func() {
 		mw.clearGrid()
 	})
 
-	saveBtn := widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+	saveBtn := widget.NewButtonWithIcon(i18n.T("common.save"), theme.DocumentSaveIcon(), func() {
 		mw.saveLayout()
 	})
 	saveBtn.Importance = widget.HighImportance
 
-	saveAsNewBtn := widget.NewButtonWithIcon("Save As New", theme.ContentAddIcon(), func() {
+	saveAsNewBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.save_as_new"), theme.ContentAddIcon(), func() {
 		mw.saveAsNewLayout()
 	})
 
-	actions := container.NewHBox(mw.revertBtn, clearBtn, saveBtn, saveAsNewBtn)
+	// Shown next to Save while the current layout has unsaved changes
+	mw.unsavedLabel = widget.NewLabelWithStyle(i18n.T("menu_editor.unsaved"), fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	mw.unsavedLabel.Importance = widget.WarningImportance
+	mw.unsavedLabel.Hide()
+
+	actions := container.NewHBox(mw.revertBtn, clearBtn, saveBtn, saveAsNewBtn, mw.unsavedLabel)
 
 	// Create color picker panel on right
 	mw.colorPanel = mw.createColorPickerPanel()
 
 	// Horizontal split: grid on left, color picker on right
-	split := container.NewHSplit(mw.gridContainer, container.NewVScroll(mw.colorPanel))
+	// The focused or hovered pad, described in words
+	mw.padLabelText = widget.NewLabel("")
+	mw.padLabelText.Wrapping = fyne.TextWrapWord
+	gridPane := container.NewBorder(nil, mw.padLabelText, nil, nil, mw.gridContainer)
+
+	split := container.NewHSplit(gridPane, container.NewVScroll(mw.colorPanel))
 	split.Offset = 0.50
 
 	return container.NewBorder(
-		container.NewVBox(header, layoutBar, subtitle, widget.NewSeparator()),
+		container.NewVBox(header, layoutBar, viewBar, subtitle, widget.NewSeparator()),
 		container.NewVBox(widget.NewSeparator(), actions),
 		nil, nil,
 		split,
 	)
 }
 
-func (mw *MainWindow) getLayoutNames() []string {
-	names := make([]string, len(mw.cfg.Menus))
+// dirtyMarker is appended to the current layout's name in the dropdown while it has unsaved changes
+const dirtyMarker = " *"
+
+// getLayoutOptions returns the dropdown options, marking the current layout when dirty
+func (mw *MainWindow) getLayoutOptions() []string {
+	current := mw.cfg.GetCurrentMenu()
+	options := make([]string, len(mw.cfg.Menus))
 	for i, m := range mw.cfg.Menus {
-		names[i] = m.Name
+		options[i] = m.Name
+		if mw.dirty && current != nil && m.ID == current.ID {
+			options[i] += dirtyMarker
+		}
+	}
+	return options
+}
+
+// layoutNameFromOption strips the dirty marker from a dropdown option
+func layoutNameFromOption(option string) string {
+	return strings.TrimSuffix(option, dirtyMarker)
+}
+
+// refreshLayoutDropdown rebuilds the layout options and selects the current layout
+// without firing the dropdown's OnChanged callback
+func (mw *MainWindow) refreshLayoutDropdown() {
+	if mw.layoutDropdown == nil {
+		return
+	}
+	mw.layoutDropdown.Options = mw.getLayoutOptions()
+
+	selected := mw.getCurrentLayoutName()
+	if mw.dirty && selected != "" {
+		selected += dirtyMarker
 	}
-	return names
+	setSelectedSilently(mw.layoutDropdown, selected)
+	mw.refreshLayoutActionSelects()
 }
 
 func (mw *MainWindow) getCurrentLayoutName() string {
@@ -114,41 +196,43 @@ func (mw *MainWindow) loadLayoutByName(name string) {
 	}
 
 	// Check if we have unsaved changes
-	if mw.dirty && !mw.cfg.SuppressUnsavedWarning {
-		mw.showUnsavedWarning(name)
+	if mw.dirty {
+		mw.confirmDiscard(i18n.T("menu_editor.unsaved_lost"), func() {
+			// Reload config from disk to discard unsaved changes
+			if newCfg, err := config.Load(); err == nil {
+				mw.cfg.Menus = newCfg.Menus
+			}
+			mw.setDirty(false)
+			mw.doLoadLayout(name)
+		}, mw.refreshLayoutDropdown) // Revert dropdown to the current (still dirty) layout
 		return
 	}
 	mw.doLoadLayout(name)
 }
 
-func (mw *MainWindow) showUnsavedWarning(targetName string) {
-	currentName := mw.getCurrentLayoutName()
-	dontShowAgain := widget.NewCheck("Don't show this warning again", nil)
+// confirmDiscard warns that the unsaved edits described by lost will be dropped, unless
+// the warning was turned off, and calls discard to go ahead or cancel (if set) to stay
+func (mw *MainWindow) confirmDiscard(lost string, discard, cancel func()) {
+	if mw.cfg.SuppressUnsavedWarning {
+		discard()
+		return
+	}
+	dontShowAgain := widget.NewCheck(i18n.T("menu_editor.dont_warn_again"), nil)
 
 	content := container.NewVBox(
-		widget.NewLabel("You have unsaved changes that will be lost."),
-		widget.NewLabel("Do you want to continue?"),
+		widget.NewLabel(lost),
+		widget.NewLabel(i18n.T("menu_editor.continue_question")),
 		dontShowAgain,
 	)
 
-	dialog.ShowCustomConfirm("Unsaved Changes", "Continue", "Cancel", content, func(confirm bool) {
+	dialog.ShowCustomConfirm(i18n.T("menu_editor.unsaved_title"), i18n.T("menu_editor.continue"), i18n.T("common.cancel"), content, func(confirm bool) {
 		if confirm {
 			if dontShowAgain.Checked {
-				mw.cfg.SuppressUnsavedWarning = true
-			}
-			// Reload config from disk to discard unsaved changes
-			if newCfg, err := config.Load(); err == nil {
-				mw.cfg.Menus = newCfg.Menus
-			}
-			mw.setDirty(false)
-			mw.doLoadLayout(targetName)
-		} else {
-			// Revert dropdown to current layout without triggering callback
-			mw.layoutDropdown.OnChanged = nil
-			mw.layoutDropdown.SetSelected(currentName)
-			mw.layoutDropdown.OnChanged = func(selected string) {
-				mw.loadLayoutByName(selected)
+				mw.unsavedWarningSuppressed()
 			}
+			discard()
+		} else if cancel != nil {
+			cancel()
 		}
 	}, mw.window)
 }
@@ -159,9 +243,10 @@ func (mw *MainWindow) doLoadLayout(name string) {
 			mw.cfg.CurrentMenuID = mw.cfg.Menus[i].ID
 
 			// Ensure legacy/uninitialized colors are linked and converted
-			mw.ensureDefaultLinking(&mw.cfg.Menus[i])
+			mw.cfg.Menus[i].EnsureDefaultLinking()
 
 			mw.setDirty(false)
+			mw.refreshLayoutDropdown() // Discarding edits showed the layout switched from
 			mw.refreshGrid()
 			return
 		}
@@ -170,28 +255,80 @@ func (mw *MainWindow) doLoadLayout(name string) {
 
 func (mw *MainWindow) createNewLayout() {
 	entry := widget.NewEntry()
-	entry.SetPlaceHolder("Layout Name")
-	entry.SetText("New Layout")
+	entry.SetPlaceHolder(i18n.T("common.layout_name"))
+	entry.SetText(i18n.T("menu_editor.new_layout"))
 
-	dialog.ShowCustomConfirm("Create New Layout", "Create", "Cancel",
-		container.NewVBox(widget.NewLabel("Enter a name for the new layout:"), entry),
+	dialog.ShowCustomConfirm(i18n.T("menu_editor.create_layout_title"), i18n.T("common.create"), i18n.T("common.cancel"),
+		container.NewVBox(widget.NewLabel(i18n.T("common.enter_layout_name")), entry),
 		func(confirm bool) {
 			if confirm && entry.Text != "" {
 				newMenu := config.NewMenuLayout()
 				newMenu.Name = entry.Text
 				mw.cfg.Menus = append(mw.cfg.Menus, newMenu)
 				mw.cfg.CurrentMenuID = newMenu.ID
-				mw.layoutDropdown.Options = mw.getLayoutNames()
-				mw.layoutDropdown.SetSelected(newMenu.Name)
+				mw.setDirty(false)
+				mw.refreshLayoutDropdown()
 				mw.refreshGrid()
-				mw.cfg.Save()
+				mw.saveLayoutsOrShow()
 			}
 		}, mw.window)
 }
 
+// importComponentsLayout asks for a SysEx file exported by Novation Components, adds its
+// pad colors as a new layout and lists what couldn't be imported
+func (mw *MainWindow) importComponentsLayout() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		// Layouts are picked by name, so a clash gets a number
+		base := strings.TrimSuffix(reader.URI().Name(), reader.URI().Extension())
+		name := base
+		for n := 2; mw.cfg.GetMenuByName(name) != nil; n++ {
+			name = fmt.Sprintf("%s (%d)", base, n)
+		}
+		result, err := importers.ImportComponents(name, data)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", reader.URI().Name(), err), mw.window)
+			return
+		}
+
+		mw.cfg.Menus = append(mw.cfg.Menus, result.Layout)
+		mw.cfg.CurrentMenuID = result.Layout.ID
+		mw.setDirty(false)
+		mw.refreshLayoutDropdown()
+		mw.refreshGrid()
+		mw.saveLayoutsOrShow()
+
+		lines := []string{i18n.T("menu_editor.import_done", result.Layout.Name)}
+		for _, skip := range importers.Skips {
+			if count := result.Skipped[skip]; count > 0 {
+				lines = append(lines, "• "+i18n.T("menu_editor.import_skipped."+string(skip), count))
+			}
+		}
+		if len(lines) > 1 {
+			lines = slices.Insert(lines, 1, i18n.T("menu_editor.import_not_carried_over"))
+		}
+		dialog.ShowInformation(i18n.T("menu_editor.import_title"), strings.Join(lines, "\n"), mw.window)
+	}, mw.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".syx"}))
+	open.Show()
+}
+
 func (mw *MainWindow) deleteCurrentLayout() {
 	if len(mw.cfg.Menus) <= 1 {
-		dialog.ShowInformation("Cannot Delete", "You must have at least one layout.", mw.window)
+		dialog.ShowInformation(i18n.T("menu_editor.cannot_delete"), i18n.T("menu_editor.need_one_layout"), mw.window)
 		return
 	}
 
@@ -200,7 +337,7 @@ func (mw *MainWindow) deleteCurrentLayout() {
 		return
 	}
 
-	dialog.ShowConfirm("Delete Layout", "Are you sure you want to delete '"+menu.Name+"'?",
+	dialog.ShowConfirm(i18n.T("menu_editor.delete_layout_title"), i18n.T("common.confirm_delete", menu.Name),
 		func(confirm bool) {
 			if confirm {
 				// Find and remove the menu
@@ -214,11 +351,11 @@ func (mw *MainWindow) deleteCurrentLayout() {
 				if len(mw.cfg.Menus) > 0 {
 					mw.cfg.CurrentMenuID = mw.cfg.Menus[0].ID
 				}
-				mw.layoutDropdown.Options = mw.getLayoutNames()
-				mw.layoutDropdown.SetSelected(mw.getCurrentLayoutName())
+				mw.setDirty(false)
+				mw.refreshLayoutDropdown()
 				mw.refreshGrid()
-				mw.cfg.Save()
-				mw.sendGridToDevices()
+				mw.saveLayoutsOrShow()
+				mw.engine.QueueLayoutSends()
 			}
 		}, mw.window)
 }
@@ -232,14 +369,14 @@ func (mw *MainWindow) renameCurrentLayout() {
 	entry := widget.NewEntry()
 	entry.SetText(menu.Name)
 
-	dialog.ShowCustomConfirm("Rename Layout", "Rename", "Cancel",
-		container.NewVBox(widget.NewLabel("Enter a new name:"), entry),
+	dialog.ShowCustomConfirm(i18n.T("common.rename_layout"), i18n.T("common.rename"), i18n.T("common.cancel"),
+		container.NewVBox(widget.NewLabel(i18n.T("menu_editor.enter_new_name")), entry),
 		func(confirm bool) {
 			if confirm && entry.Text != "" {
 				menu.Name = entry.Text
-				mw.layoutDropdown.Options = mw.getLayoutNames()
-				mw.layoutDropdown.SetSelected(menu.Name)
-				mw.cfg.Save()
+				mw.setDirty(false)
+				mw.refreshLayoutDropdown()
+				mw.saveLayoutsOrShow()
 			}
 		}, mw.window)
 }
@@ -251,58 +388,99 @@ func (mw *MainWindow) refreshGrid() {
 		return
 	}
 
+	if mw.gridContainer != nil && mw.cfg.EditorShape() != mw.gridShape {
+		mw.gridContainer.Objects = []fyne.CanvasObject{mw.createPadGrid()}
+		mw.gridContainer.Refresh()
+	}
+	most := mw.mostPresses()
 	for row := 0; row < 9; row++ {
 		for col := 0; col < 9; col++ {
-			c := menu.Colors[row][col]
-			mw.gridRects[row][col].FillColor = color.RGBA{
-				R: uint8(c.R * 2),
-				G: uint8(c.G * 2),
-				B: uint8(c.B * 2),
-				A: 255,
-			}
-			mw.gridRects[row][col].Refresh()
+			mw.refreshCell(row, col, most)
 		}
 	}
+	mw.refreshOuterPads()
+	mw.refreshVisibility()
 }
 
+// refreshCell redraws one pad of the grid. Redrawing all 81 is slow on small machines,
+// so only layout switches and reverts go through refreshGrid.
+func (mw *MainWindow) refreshCell(row, col int, most uint64) {
+	mw.gridRects[row][col].FillColor = mw.padFill(row, col, most)
+	mw.markPad(row, col)
+	mw.updatePadLabel(row, col)
+	mw.updatePadAnnotation(row, col)
+	mw.gridRects[row][col].Refresh()
+	mw.cellRefreshes++
+}
+
+// markPad outlines the selected pad, and pads that the global top row replaces on some device
+func (mw *MainWindow) markPad(row, col int) {
+	rect := mw.gridRects[row][col]
+	switch {
+	case row == mw.selectedRow && col == mw.selectedCol:
+		rect.StrokeColor = theme.Color(theme.ColorNamePrimary)
+		rect.StrokeWidth = 2
+	case mw.topRowReserved(row, col):
+		rect.StrokeColor = theme.Color(theme.ColorNameWarning)
+		rect.StrokeWidth = 2
+	default:
+		rect.StrokeWidth = 0
+	}
+}
+
+// createPadGrid builds the editor grid in the shape of the devices the layouts are
+// shown on: the 9x9 grid, with the Pro pads around it when a device has them
 func (mw *MainWindow) createPadGrid() fyne.CanvasObject {
-	grid := container.NewGridWithColumns(9)
+	mw.gridShape = mw.cfg.EditorShape()
+	mw.outerRects = map[config.PlanPad]*canvas.Rectangle{}
+	grid := container.NewGridWithColumns(mw.gridShape.Cols)
 
 	menu := mw.cfg.GetCurrentMenu()
 
-	for row := 0; row < 9; row++ {
-		for col := 0; col < 9; col++ {
-			r, c := row, col
-
-			var padColor config.PadColorConfig
-			if menu != nil {
-				padColor = menu.Colors[r][c]
+	for row := 0; row < mw.gridShape.Rows; row++ {
+		for col := 0; col < mw.gridShape.Cols; col++ {
+			pad, ok := mw.gridShape.At(row, col)
+			switch {
+			case !ok:
+				grid.Add(mw.newOuterSpacer())
+			case pad.Area != config.PadAreaGrid:
+				grid.Add(mw.newOuterPad(pad))
+			default:
+				grid.Add(mw.newGridPad(menu, pad.Row, pad.Col))
 			}
-
-			rect := canvas.NewRectangle(color.RGBA{
-				R: uint8(padColor.R * 2),
-				G: uint8(padColor.G * 2),
-				B: uint8(padColor.B * 2),
-				A: 255,
-			})
-			rect.SetMinSize(fyne.NewSize(40, 40))
-			rect.CornerRadius = 4
-			mw.gridRects[r][c] = rect
-
-			btn := newTappableRect(rect, func() {
-				mw.selectPad(r, c)
-			})
-
-			grid.Add(btn)
 		}
 	}
 
 	return grid
 }
 
+// newGridPad builds the editor pad of the 9x9 grid at r, c
+func (mw *MainWindow) newGridPad(menu *config.MenuLayout, r, c int) fyne.CanvasObject {
+	var padColor config.PadColorConfig
+	if menu != nil {
+		padColor = menu.EffectiveColor(r, c)
+	}
+
+	rect := canvas.NewRectangle(padcolor.RGBA(padColor.R, padColor.G, padColor.B))
+	rect.SetMinSize(fyne.NewSize(mw.padSize(), mw.padSize()))
+	rect.CornerRadius = 4
+	mw.gridRects[r][c] = rect
+	mw.markPad(r, c)
+
+	btn := newTappableRect(rect, func() {
+		mw.selectPad(r, c)
+	})
+	btn.onKey = func(event *fyne.KeyEvent) { mw.movePadFocus(r, c, event) }
+	btn.onFocus = mw.showPadLabel
+	mw.gridPads[r][c] = btn
+	mw.updatePadLabel(r, c)
+	mw.updatePadAnnotation(r, c)
+	return btn
+}
+
 func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 	// Header
-	header := widget.NewLabel("Pad Colors")
+	header := widget.NewLabel(i18n.T("menu_editor.pad_colors"))
 	header.TextStyle = fyne.TextStyle{Bold: true}
 
 	// Create sliders for Button Color (0-127 RGB)
@@ -422,7 +600,7 @@ func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 
 		f, err := freetype.ParseFont(fontBytes)
 		if err != nil {
-			log.Printf("Failed to parse font: %v", err)
+			slog.Error("Failed to parse font", "err", err)
 			// Fallback to empty image
 			return canvas.NewImageFromImage(image.NewRGBA(image.Rect(0, 0, 1, 1)))
 		}
@@ -474,7 +652,7 @@ func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 		pt := freetype.Pt(padding, padding+ascent)
 		_, err = c.DrawString(text, pt)
 		if err != nil {
-			log.Printf("Failed to draw string: %v", err)
+			slog.Error("Failed to draw string", "err", err)
 		}
 
 		// Create rotated image (90 deg CCW: w,h -> h,w)
@@ -495,8 +673,8 @@ func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 	}
 
 	// --- Headers ---
-	modernHeader := widget.NewLabelWithStyle("Modern", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	classicHeader := widget.NewLabelWithStyle("Classic", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	modernHeader := widget.NewLabelWithStyle(i18n.T("menu_editor.modern"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	classicHeader := widget.NewLabelWithStyle(i18n.T("common.device_type.classic"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
 	colHeaders := container.NewGridWithColumns(2, modernHeader, classicHeader)
 
 	// spacer for header row (approx same width as rotated label height)
@@ -529,8 +707,10 @@ func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 	staticContent := container.NewVBox(staticPreviewRow, staticSlidersRow)
 	// Use container.NewHBox for label + content to ensure label is on left
 	// Just wrapping the label in a center container to prevent stretch might act better
-	staticLabel := container.NewCenter(rotatedLabel("Static"))
+	staticLabel := container.NewCenter(rotatedLabel(i18n.T("menu_editor.static")))
 	staticRow := container.NewBorder(nil, nil, staticLabel, nil, staticContent)
+	mw.overrideStaticCheck, mw.staticInheritedLabel = mw.newOverrideCheck(false)
+	staticOverrideRow := container.NewHBox(mw.overrideStaticCheck, mw.staticInheritedLabel)
 
 	// --- Pressed Section ---
 
@@ -555,11 +735,13 @@ func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 	)
 
 	pressedContent := container.NewVBox(pressedPreviewRow, pressedSlidersRow)
-	pressedLabel := container.NewCenter(rotatedLabel("Pressed"))
+	pressedLabel := container.NewCenter(rotatedLabel(i18n.T("menu_editor.pressed")))
 	pressedRow := container.NewBorder(nil, nil, pressedLabel, nil, pressedContent)
+	mw.overridePressedCheck, mw.pressedInheritedLabel = mw.newOverrideCheck(true)
+	pressedOverrideRow := container.NewHBox(mw.overridePressedCheck, mw.pressedInheritedLabel)
 
 	// Presets
-	presetsLabel := widget.NewLabel("Presets")
+	presetsLabel := widget.NewLabel(i18n.T("menu_editor.presets"))
 	presetsLabel.TextStyle = fyne.TextStyle{Bold: true}
 	presets := container.NewGridWithColumns(5,
 		widget.NewButton("R", func() { mw.applyPreset(127, 0, 0) }),
@@ -568,127 +750,294 @@ func (mw *MainWindow) createColorPickerPanel() *fyne.Container {
 		widget.NewButton("O", func() { mw.applyPreset(127, 64, 0) }),
 		widget.NewButton("⊘", func() { mw.applyPreset(0, 0, 0) }),
 	)
+	generateBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.generate.button"), theme.ColorPaletteIcon(), func() {
+		mw.showColorGeneratorDialog()
+	})
 
 	// Action assignment section
-	actionLabel := widget.NewLabel("Action")
+	actionLabel := widget.NewLabel(i18n.T("common.action"))
 	actionLabel.TextStyle = fyne.TextStyle{Bold: true}
-	mw.padActionSelect = widget.NewSelect([]string{"(None)"}, func(s string) {
-		mw.onPadActionChanged(s)
+	mw.padActionPicker = mw.newActionPicker(mw.onPadActionChanged)
+
+	actionRow := container.NewBorder(nil, nil, actionLabel, nil, mw.padActionPicker)
+
+	// Pads the global top row replaces on some device
+	mw.topRowWarning = widget.NewLabel("")
+	mw.topRowWarning.Wrapping = fyne.TextWrapWord
+	mw.topRowWarning.Hide()
+
+	// Arguments the action finds in its environment, e.g. set per pad by bulk assignment
+	mw.padArgsEntry = widget.NewEntry()
+	mw.padArgsEntry.SetPlaceHolder(i18n.T("menu_editor.action_args_placeholder"))
+	mw.padArgsEntry.OnChanged = func(s string) {
+		if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+			menu.Colors[mw.selectedRow][mw.selectedCol].ActionArgs = s
+			mw.setDirty(true)
+		}
+	}
+	argsRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("menu_editor.action_args")), nil, mw.padArgsEntry)
+	bulkBtn := widget.NewButtonWithIcon(i18n.T("menu_editor.bulk_assign"), theme.ViewFullScreenIcon(), func() {
+		mw.showBulkAssignDialog()
 	})
-	mw.padActionSelect.PlaceHolder = "Select action..."
-	mw.refreshPadActionOptions()
 
-	actionRow := container.NewBorder(nil, nil, actionLabel, nil, mw.padActionSelect)
+	// Per-pad debounce, for pads whose action must not run twice from a bouncing contact
+	debounceTitle := widget.NewLabel(i18n.T("menu_editor.debounce"))
+	debounceTitle.TextStyle = fyne.TextStyle{Bold: true}
+	debounceOptions := []string{i18n.T("menu_editor.debounce_default")}
+	for _, ms := range padDebounceChoices[1:] {
+		debounceOptions = append(debounceOptions, debounceLabel(ms))
+	}
+	mw.padDebounceSelect = widget.NewSelect(debounceOptions, func(s string) {
+		menu := mw.cfg.GetCurrentMenu()
+		if menu == nil {
+			return
+		}
+		ms := 0
+		if i := slices.Index(debounceOptions, s); i > 0 {
+			ms = padDebounceChoices[i]
+		}
+		menu.Colors[mw.selectedRow][mw.selectedCol].DebounceMs = ms
+		mw.setDirty(true)
+	})
+	debounceRow := container.NewBorder(nil, nil, debounceTitle, nil, mw.padDebounceSelect)
+
+	// Per-pad light mode; flashing is done by the device, so only Launchpad S blinks
+	lightTitle := widget.NewLabel(i18n.T("menu_editor.light_mode"))
+	lightTitle.TextStyle = fyne.TextStyle{Bold: true}
+	lightOptions := lightModeOptions()
+	mw.padLightSelect = widget.NewSelect(lightOptions, func(s string) {
+		if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+			menu.Colors[mw.selectedRow][mw.selectedCol].LightMode = config.LightModes[max(slices.Index(lightOptions, s), 0)]
+			mw.setDirty(true)
+		}
+	})
+	lightRow := container.NewBorder(nil, nil, lightTitle, nil, mw.padLightSelect)
+
+	// Per-pad symbol, shown instead of the hue code with color codes on; editor only
+	symbolTitle := widget.NewLabel(i18n.T("menu_editor.symbol"))
+	symbolTitle.TextStyle = fyne.TextStyle{Bold: true}
+	mw.padSymbolEntry = widget.NewEntry()
+	mw.padSymbolEntry.SetPlaceHolder(i18n.T("menu_editor.symbol_placeholder"))
+	mw.padSymbolEntry.Validator = func(s string) error {
+		if utf8.RuneCountInString(s) > maxSymbolRunes {
+			return errors.New(i18n.N("menu_editor.symbol_too_long", maxSymbolRunes, maxSymbolRunes))
+		}
+		return nil
+	}
+	mw.padSymbolEntry.OnChanged = func(s string) {
+		if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+			menu.Colors[mw.selectedRow][mw.selectedCol].Symbol = s
+			mw.setDirty(true)
+			mw.refreshCell(mw.selectedRow, mw.selectedCol, mw.mostPresses())
+		}
+	}
+	symbolRow := container.NewBorder(nil, nil, symbolTitle, nil, mw.padSymbolEntry)
+
+	// Per-pad busy color, shown while the pad's action runs when busy feedback is on
+	busyTitle := widget.NewLabel(i18n.T("menu_editor.busy_color"))
+	busyTitle.TextStyle = fyne.TextStyle{Bold: true}
+	busyOptions := append([]string{i18n.T("menu_editor.busy_default")}, busyColorOptions()...)
+	mw.padBusySelect = widget.NewSelect(busyOptions, func(s string) {
+		menu := mw.cfg.GetCurrentMenu()
+		if menu == nil {
+			return
+		}
+		pad := &menu.Colors[mw.selectedRow][mw.selectedCol]
+		pad.BusyR, pad.BusyG, pad.BusyB = 0, 0, 0
+		if i := slices.Index(busyOptions, s); i > 0 {
+			preset := busyColorPresets[i-1]
+			pad.BusyR, pad.BusyG, pad.BusyB = preset.r, preset.g, preset.b
+		}
+		mw.setDirty(true)
+	})
+	busyRow := container.NewBorder(nil, nil, busyTitle, nil, mw.padBusySelect)
+
+	// Static and pressed colors, stacked or as tabs in compact mode
+	mw.staticSection = container.NewVBox(staticOverrideRow, staticRow, lightRow, symbolRow)
+	mw.pressedSection = container.NewVBox(pressedOverrideRow, pressedRow)
+	mw.colorSections = container.NewVBox()
+	mw.layoutColorSections()
+	mw.sentPreview = newSentPreview()
+	mw.visibilityNote = widget.NewLabel("")
+	mw.visibilityNote.Wrapping = fyne.TextWrapWord
+	mw.visibilityNote.Importance = widget.WarningImportance
+	mw.visibilityNote.Hide()
 
 	return container.NewVBox(
 		header,
 		widget.NewSeparator(),
 		headerRow,
-		staticRow,
-		widget.NewSeparator(),
-		pressedRow,
+		mw.colorSections,
+		mw.sentPreview.box,
+		mw.visibilityNote,
 		widget.NewSeparator(),
 		presets,
+		generateBtn,
 		widget.NewSeparator(),
 		actionRow,
+		mw.topRowWarning,
+		mw.padUsageLabel,
+		argsRow,
+		bulkBtn,
+		debounceRow,
+		busyRow,
+		widget.NewSeparator(),
+		mw.createPadThruSection(),
 	)
 }
 
-func (mw *MainWindow) ensureDefaultLinking(menu *config.MenuLayout) {
-	if menu == nil {
-		return
-	}
-	for r := 0; r < 9; r++ {
-		for c := 0; c < 9; c++ {
-			padColor := menu.Colors[r][c]
-			changed := false
-
-			// Check button color
-			buttonHasColor := padColor.R > 0 || padColor.G > 0 || padColor.B > 0
-			classicIsBlack := padColor.ClassicR == 0 && padColor.ClassicG == 0 && padColor.ClassicB == 0
-
-			if buttonHasColor && classicIsBlack {
-				padColor.LinkButtonClassic = true
-				rLevel, gLevel := config.CalculateClassicLevel(padColor.R, padColor.G, padColor.B)
-				padColor.ClassicR = config.LevelTo127(rLevel)
-				padColor.ClassicG = config.LevelTo127(gLevel)
-				changed = true
-			}
+// padThruModes are the MIDI thru modes in the order the mode select lists them
+var padThruModes = []config.PadThruMode{config.PadThruOff, config.PadThruReplace, config.PadThruAlso}
 
-			// Check pressed color
-			pressedHasColor := padColor.PressedR > 0 || padColor.PressedG > 0 || padColor.PressedB > 0
-			classicPressedIsBlack := padColor.ClassicPressedR == 0 && padColor.ClassicPressedG == 0 && padColor.ClassicPressedB == 0
+// createPadThruSection builds the controls that make the selected pad play a MIDI note
+func (mw *MainWindow) createPadThruSection() fyne.CanvasObject {
+	title := widget.NewLabel(i18n.T("menu_editor.thru"))
+	title.TextStyle = fyne.TextStyle{Bold: true}
 
-			if pressedHasColor && classicPressedIsBlack {
-				padColor.LinkPressedClassic = true
-				rLevel, gLevel := config.CalculateClassicLevel(padColor.PressedR, padColor.PressedG, padColor.PressedB)
-				padColor.ClassicPressedR = config.LevelTo127(rLevel)
-				padColor.ClassicPressedG = config.LevelTo127(gLevel)
-				changed = true
+	modeOptions := []string{i18n.T("menu_editor.thru_off"), i18n.T("menu_editor.thru_replace"), i18n.T("menu_editor.thru_also")}
+	mw.padThruModeSelect = widget.NewSelect(modeOptions, func(s string) {
+		mode := padThruModes[slices.Index(modeOptions, s)]
+		mw.editPadThru(func(thru *config.PadThruConfig) {
+			if thru.Mode == config.PadThruOff && thru.DeviceID == "" && thru.Note == 0 {
+				thru.Note = config.PadThruSameNote // Start out mirroring the pad
 			}
+			thru.Mode = mode
+		})
+		mw.updatePadThruSelection() // Enables the other controls
+	})
 
-			if changed {
-				menu.Colors[r][c] = padColor
+	mw.padThruDeviceSelect = widget.NewSelect(nil, func(s string) {
+		for _, device := range mw.cfg.Devices {
+			if device.Type == config.DeviceTypeGeneric && device.Name == s {
+				mw.editPadThru(func(thru *config.PadThruConfig) { thru.DeviceID = device.ID })
+				return
 			}
 		}
+	})
+	mw.padThruDeviceSelect.PlaceHolder = i18n.T("menu_editor.thru_device_placeholder")
+
+	var channels []string
+	for ch := 1; ch <= 16; ch++ {
+		channels = append(channels, strconv.Itoa(ch))
 	}
-}
+	mw.padThruChannelSelect = widget.NewSelect(channels, func(s string) {
+		ch, _ := strconv.Atoi(s)
+		mw.editPadThru(func(thru *config.PadThruConfig) { thru.Channel = ch - 1 })
+	})
 
-func (mw *MainWindow) selectPad(row, col int) {
-	mw.selectedRow = row
-	mw.selectedCol = col
+	notes := []string{i18n.T("menu_editor.thru_same_note")}
+	for n := 0; n <= 127; n++ {
+		notes = append(notes, strconv.Itoa(n))
+	}
+	mw.padThruNoteSelect = widget.NewSelect(notes, func(s string) {
+		note := config.PadThruSameNote
+		if n, err := strconv.Atoi(s); err == nil {
+			note = n
+		}
+		mw.editPadThru(func(thru *config.PadThruConfig) { thru.Note = note })
+	})
 
-	// Load colors into sliders
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("menu_editor.thru_mode"), mw.padThruModeSelect),
+		widget.NewFormItem(i18n.T("menu_editor.thru_device"), mw.padThruDeviceSelect),
+		widget.NewFormItem(i18n.T("common.channel_label"), mw.padThruChannelSelect),
+		widget.NewFormItem(i18n.T("menu_editor.thru_note"), mw.padThruNoteSelect),
+	)
+	return container.NewVBox(title, form)
+}
+
+// editPadThru changes the selected pad's MIDI thru settings
+func (mw *MainWindow) editPadThru(change func(thru *config.PadThruConfig)) {
 	menu := mw.cfg.GetCurrentMenu()
 	if menu == nil {
 		return
 	}
+	change(&menu.Colors[mw.selectedRow][mw.selectedCol].Thru)
+	mw.setDirty(true)
+}
 
-	// Ensure default linking for this pad (and others)
-	// As discussed in window.go, reusing the logic here ensures single-pad consistency
-	padColor := menu.Colors[row][col]
-	changed := false
-
-	buttonHasColor := padColor.R > 0 || padColor.G > 0 || padColor.B > 0
-	classicIsBlack := padColor.ClassicR == 0 && padColor.ClassicG == 0 && padColor.ClassicB == 0
+// updatePadThruSelection shows the selected pad's MIDI thru settings
+func (mw *MainWindow) updatePadThruSelection() {
+	if mw.padThruModeSelect == nil {
+		return
+	}
+	var thru config.PadThruConfig
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		thru = menu.Colors[mw.selectedRow][mw.selectedCol].Thru
+	}
 
-	if buttonHasColor && classicIsBlack {
-		padColor.LinkButtonClassic = true
-		rLevel, gLevel := config.CalculateClassicLevel(padColor.R, padColor.G, padColor.B)
-		padColor.ClassicR = config.LevelTo127(rLevel)
-		padColor.ClassicG = config.LevelTo127(gLevel)
-		changed = true
+	// Notes go to generic devices, which stand for other apps and virtual ports
+	var devices []string
+	selectedDevice := ""
+	for _, device := range mw.cfg.Devices {
+		if device.Type != config.DeviceTypeGeneric {
+			continue
+		}
+		devices = append(devices, device.Name)
+		if device.ID == thru.DeviceID {
+			selectedDevice = device.Name
+		}
+	}
+	mw.padThruDeviceSelect.Options = devices
+	if selectedDevice != "" {
+		setSelectedSilently(mw.padThruDeviceSelect, selectedDevice)
+	} else {
+		onChanged := mw.padThruDeviceSelect.OnChanged
+		mw.padThruDeviceSelect.OnChanged = nil
+		mw.padThruDeviceSelect.ClearSelected()
+		mw.padThruDeviceSelect.OnChanged = onChanged
 	}
 
-	pressedHasColor := padColor.PressedR > 0 || padColor.PressedG > 0 || padColor.PressedB > 0
-	classicPressedIsBlack := padColor.ClassicPressedR == 0 && padColor.ClassicPressedG == 0 && padColor.ClassicPressedB == 0
+	setSelectedSilently(mw.padThruModeSelect, mw.padThruModeSelect.Options[max(slices.Index(padThruModes, thru.Mode), 0)])
+	setSelectedSilently(mw.padThruChannelSelect, strconv.Itoa(min(max(thru.Channel, 0), 15)+1))
+	note := mw.padThruNoteSelect.Options[0]
+	if thru.Note >= 0 && thru.Note <= 127 {
+		note = strconv.Itoa(thru.Note)
+	}
+	setSelectedSilently(mw.padThruNoteSelect, note)
 
-	if pressedHasColor && classicPressedIsBlack {
-		padColor.LinkPressedClassic = true
-		rLevel, gLevel := config.CalculateClassicLevel(padColor.PressedR, padColor.PressedG, padColor.PressedB)
-		padColor.ClassicPressedR = config.LevelTo127(rLevel)
-		padColor.ClassicPressedG = config.LevelTo127(gLevel)
-		changed = true
+	if thru.Mode == config.PadThruOff {
+		mw.padThruDeviceSelect.Disable()
+		mw.padThruChannelSelect.Disable()
+		mw.padThruNoteSelect.Disable()
+	} else {
+		mw.padThruDeviceSelect.Enable()
+		mw.padThruChannelSelect.Enable()
+		mw.padThruNoteSelect.Enable()
 	}
+}
 
-	if changed {
-		menu.Colors[row][col] = padColor
+func (mw *MainWindow) selectPad(row, col int) {
+	prevRow, prevCol := mw.selectedRow, mw.selectedCol
+	mw.selectedRow = row
+	mw.selectedCol = col
+
+	// Load colors into sliders
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
 	}
 
+	// Pads whose classic colors were never set get them derived first
+	menu.Colors[row][col].LinkClassicDefaults()
+	padColor := menu.Colors[row][col]
+	mw.updateOverrideChecks(padColor)
+	padColor = menu.EffectiveColor(row, col) // Inherited colors show as they are sent
+
 	// Suppress callbacks while setting values
 	mw.setSliderValues(mw.buttonRSlider, mw.buttonGSlider, mw.buttonBSlider,
 		float64(padColor.R), float64(padColor.G), float64(padColor.B))
 
-	// Use Level127To4 for classic sliders (0-127 -> 0-3)
+	// Use Level for classic sliders (0-127 -> 0-3)
 	mw.setClassicSliderValues(mw.classicRSlider, mw.classicGSlider,
-		float64(config.Level127To4(padColor.ClassicR)), float64(config.Level127To4(padColor.ClassicG)))
+		float64(padcolor.Level(padColor.ClassicR)), float64(padcolor.Level(padColor.ClassicG)))
 
 	mw.setSliderValues(mw.pressedRSlider, mw.pressedGSlider, mw.pressedBSlider,
 		float64(padColor.PressedR), float64(padColor.PressedG), float64(padColor.PressedB))
 
-	// Use Level127To4 for classic pressed sliders (0-127 -> 0-3)
+	// Use Level for classic pressed sliders (0-127 -> 0-3)
 	mw.setClassicSliderValues(mw.classicPressedRSlider, mw.classicPressedGSlider,
-		float64(config.Level127To4(padColor.ClassicPressedR)), float64(config.Level127To4(padColor.ClassicPressedG)))
+		float64(padcolor.Level(padColor.ClassicPressedR)), float64(padcolor.Level(padColor.ClassicPressedG)))
 
 	// Update link checkboxes
 	mw.linkButtonClassic.Checked = padColor.LinkButtonClassic
@@ -701,10 +1050,17 @@ func (mw *MainWindow) selectPad(row, col int) {
 
 	// Update action selection for this pad
 	mw.updatePadActionSelection()
-
-	// Visual selection indicator - highlight the selected pad
-	// (Simple approach: refresh grid to show selection)
-	mw.refreshGridSelection()
+	mw.updatePadArgsEntry()
+	mw.updatePadDebounceSelection()
+	mw.updatePadLightSelection()
+	mw.updatePadSymbolEntry()
+	mw.updatePadBusySelection()
+	mw.updatePadThruSelection()
+	mw.updateTopRowWarning()
+	mw.updateVisibilityNote()
+	mw.updatePadUsage()
+
+	mw.refreshGridSelection(prevRow, prevCol)
 }
 
 func (mw *MainWindow) setSliderValues(r, g, b *widget.Slider, rv, gv, bv float64) {
@@ -745,48 +1101,55 @@ func (mw *MainWindow) updateAllPreviews() {
 }
 
 func (mw *MainWindow) updateButtonPreview() {
-	mw.buttonPreview.FillColor = color.RGBA{
-		R: uint8(mw.buttonRSlider.Value * 2),
-		G: uint8(mw.buttonGSlider.Value * 2),
-		B: uint8(mw.buttonBSlider.Value * 2),
-		A: 255,
-	}
+	mw.buttonPreview.FillColor = padcolor.RGBA(
+		uint8(mw.buttonRSlider.Value),
+		uint8(mw.buttonGSlider.Value),
+		uint8(mw.buttonBSlider.Value),
+	)
 	mw.buttonPreview.Refresh()
+	mw.updateSentPreview()
 }
 
 func (mw *MainWindow) updateClassicPreview() {
-	// Convert 0-3 levels to display RGB (0, 85, 170, 255)
-	mw.classicPreview.FillColor = color.RGBA{
-		R: uint8(mw.classicRSlider.Value * 85),
-		G: uint8(mw.classicGSlider.Value * 85),
-		B: 0, // No blue for classic
-		A: 255,
-	}
+	mw.classicPreview.FillColor = padcolor.LevelRGBA(uint8(mw.classicRSlider.Value), uint8(mw.classicGSlider.Value))
 	mw.classicPreview.Refresh()
+	mw.updateSentPreview()
 }
 
 func (mw *MainWindow) updatePressedPreview() {
-	mw.pressedPreview.FillColor = color.RGBA{
-		R: uint8(mw.pressedRSlider.Value * 2),
-		G: uint8(mw.pressedGSlider.Value * 2),
-		B: uint8(mw.pressedBSlider.Value * 2),
-		A: 255,
-	}
+	mw.pressedPreview.FillColor = padcolor.RGBA(
+		uint8(mw.pressedRSlider.Value),
+		uint8(mw.pressedGSlider.Value),
+		uint8(mw.pressedBSlider.Value),
+	)
 	mw.pressedPreview.Refresh()
+	mw.updateSentPreview()
 }
 
 func (mw *MainWindow) updateClassicPressedPreview() {
-	// Convert 0-3 levels to display RGB (0, 85, 170, 255)
-	mw.classicPressedPreview.FillColor = color.RGBA{
-		R: uint8(mw.classicPressedRSlider.Value * 85),
-		G: uint8(mw.classicPressedGSlider.Value * 85),
-		B: 0, // No blue for classic
-		A: 255,
-	}
+	mw.classicPressedPreview.FillColor = padcolor.LevelRGBA(uint8(mw.classicPressedRSlider.Value), uint8(mw.classicPressedGSlider.Value))
 	mw.classicPressedPreview.Refresh()
+	mw.updateSentPreview()
+}
+
+// updateSentPreview shows what the panel's colors look like on each kind of configured device
+func (mw *MainWindow) updateSentPreview() {
+	if mw.sentPreview == nil {
+		return
+	}
+	mw.sentPreview.Update(mw.cfg.Devices, mw.selectedRow, mw.selectedCol, config.PadColorConfig{
+		R: uint8(mw.buttonRSlider.Value), G: uint8(mw.buttonGSlider.Value), B: uint8(mw.buttonBSlider.Value),
+		LinkButtonClassic: mw.linkButtonClassic.Checked,
+		ClassicR:          padcolor.LevelValue(uint8(mw.classicRSlider.Value)),
+		ClassicG:          padcolor.LevelValue(uint8(mw.classicGSlider.Value)),
+		PressedR:          uint8(mw.pressedRSlider.Value), PressedG: uint8(mw.pressedGSlider.Value), PressedB: uint8(mw.pressedBSlider.Value),
+		ClassicPressedR: padcolor.LevelValue(uint8(mw.classicPressedRSlider.Value)),
+		ClassicPressedG: padcolor.LevelValue(uint8(mw.classicPressedGSlider.Value)),
+	})
 }
 
 func (mw *MainWindow) onButtonColorChanged() {
+	mw.claimPadColor(false)
 	mw.updateButtonPreview()
 	mw.saveCurrentPadColors()
 
@@ -810,12 +1173,18 @@ func (mw *MainWindow) onClassicColorChanged() {
 			menu.Colors[mw.selectedRow][mw.selectedCol].LinkButtonClassic = false
 		}
 	}
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		menu.Colors[mw.selectedRow][mw.selectedCol].ClassicInitialized = true // Black stays black
+	}
+	mw.claimPadColor(false)
 	mw.updateClassicPreview()
 	mw.saveCurrentPadColors()
+	mw.refreshVisibility()
 	mw.setDirty(true)
 }
 
 func (mw *MainWindow) onPressedColorChanged() {
+	mw.claimPadColor(true)
 	mw.updatePressedPreview()
 	mw.saveCurrentPadColors()
 
@@ -836,13 +1205,17 @@ func (mw *MainWindow) onClassicPressedColorChanged() {
 			menu.Colors[mw.selectedRow][mw.selectedCol].LinkPressedClassic = false
 		}
 	}
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		menu.Colors[mw.selectedRow][mw.selectedCol].ClassicPressedInitialized = true
+	}
+	mw.claimPadColor(true)
 	mw.updateClassicPressedPreview()
 	mw.saveCurrentPadColors()
 	mw.setDirty(true)
 }
 
 func (mw *MainWindow) syncClassicFromButton() {
-	rLevel, gLevel := config.CalculateClassicLevel(
+	rLevel, gLevel := padcolor.ClassicLevels(
 		uint8(mw.buttonRSlider.Value),
 		uint8(mw.buttonGSlider.Value),
 		uint8(mw.buttonBSlider.Value),
@@ -854,7 +1227,7 @@ func (mw *MainWindow) syncClassicFromButton() {
 }
 
 func (mw *MainWindow) syncClassicPressedFromPressed() {
-	rLevel, gLevel := config.CalculateClassicLevel(
+	rLevel, gLevel := padcolor.ClassicLevels(
 		uint8(mw.pressedRSlider.Value),
 		uint8(mw.pressedGSlider.Value),
 		uint8(mw.pressedBSlider.Value),
@@ -871,28 +1244,33 @@ func (mw *MainWindow) saveCurrentPadColors() {
 		return
 	}
 
-	menu.Colors[mw.selectedRow][mw.selectedCol] = config.PadColorConfig{
-		R: uint8(mw.buttonRSlider.Value),
-		G: uint8(mw.buttonGSlider.Value),
-		B: uint8(mw.buttonBSlider.Value),
+	// Only the colors come from the panel; the pad's action and debounce are kept.
+	// Colors the pad inherits from the layout defaults stay unset.
+	padColor := &menu.Colors[mw.selectedRow][mw.selectedCol]
+	if padColor.HasOwnStatic() {
+		padColor.R = uint8(mw.buttonRSlider.Value)
+		padColor.G = uint8(mw.buttonGSlider.Value)
+		padColor.B = uint8(mw.buttonBSlider.Value)
 
 		// Convert 0-3 levels to 0-127 for storage
-		ClassicR: config.LevelTo127(uint8(mw.classicRSlider.Value)),
-		ClassicG: config.LevelTo127(uint8(mw.classicGSlider.Value)),
-		ClassicB: 0, // No blue for classic
+		padColor.ClassicR = padcolor.LevelValue(uint8(mw.classicRSlider.Value))
+		padColor.ClassicG = padcolor.LevelValue(uint8(mw.classicGSlider.Value))
+		padColor.ClassicB = 0 // No blue for classic
+	}
 
-		PressedR: uint8(mw.pressedRSlider.Value),
-		PressedG: uint8(mw.pressedGSlider.Value),
-		PressedB: uint8(mw.pressedBSlider.Value),
+	if padColor.HasOwnPressed() {
+		padColor.PressedR = uint8(mw.pressedRSlider.Value)
+		padColor.PressedG = uint8(mw.pressedGSlider.Value)
+		padColor.PressedB = uint8(mw.pressedBSlider.Value)
 
 		// Convert 0-3 levels to 0-127 for storage
-		ClassicPressedR: config.LevelTo127(uint8(mw.classicPressedRSlider.Value)),
-		ClassicPressedG: config.LevelTo127(uint8(mw.classicPressedGSlider.Value)),
-		ClassicPressedB: 0, // No blue for classic
-
-		LinkButtonClassic:  mw.linkButtonClassic.Checked,
-		LinkPressedClassic: mw.linkPressedClassic.Checked,
+		padColor.ClassicPressedR = padcolor.LevelValue(uint8(mw.classicPressedRSlider.Value))
+		padColor.ClassicPressedG = padcolor.LevelValue(uint8(mw.classicPressedGSlider.Value))
+		padColor.ClassicPressedB = 0 // No blue for classic
 	}
+
+	padColor.LinkButtonClassic = mw.linkButtonClassic.Checked
+	padColor.LinkPressedClassic = mw.linkPressedClassic.Checked
 }
 
 func (mw *MainWindow) applyPreset(r, g, b float64) {
@@ -900,10 +1278,13 @@ func (mw *MainWindow) applyPreset(r, g, b float64) {
 	mw.onButtonColorChanged()
 }
 
-func (mw *MainWindow) refreshGridSelection() {
-	// Highlight selected pad by updating its visual appearance
-	// For now, just refresh the grid - selection is implicit from color panel content
-	mw.refreshGrid()
+// refreshGridSelection moves the selection outline from the previously selected pad
+// to the selected one, redrawing only those two
+func (mw *MainWindow) refreshGridSelection(prevRow, prevCol int) {
+	if prevRow != mw.selectedRow || prevCol != mw.selectedCol {
+		mw.updateGridRect(prevRow, prevCol)
+	}
+	mw.updateGridRect(mw.selectedRow, mw.selectedCol)
 }
 
 func (mw *MainWindow) updateGridRect(row, col int) {
@@ -911,17 +1292,12 @@ func (mw *MainWindow) updateGridRect(row, col int) {
 	if menu == nil {
 		return
 	}
-	c := menu.Colors[row][col]
-	mw.gridRects[row][col].FillColor = color.RGBA{
-		R: uint8(c.R * 2),
-		G: uint8(c.G * 2),
-		B: uint8(c.B * 2),
-		A: 255,
-	}
-	mw.gridRects[row][col].Refresh()
+	mw.refreshCell(row, col, mw.mostPresses())
+	mw.refreshVisibility()
 }
 
 func (mw *MainWindow) setDirty(dirty bool) {
+	changed := mw.dirty != dirty
 	mw.dirty = dirty
 	if mw.revertBtn != nil {
 		if dirty {
@@ -930,6 +1306,29 @@ func (mw *MainWindow) setDirty(dirty bool) {
 			mw.revertBtn.Disable()
 		}
 	}
+	if mw.unsavedLabel != nil {
+		if dirty {
+			mw.unsavedLabel.Show()
+		} else {
+			mw.unsavedLabel.Hide()
+		}
+	}
+
+	// Title and dropdown only need updating when the state flips
+	if !changed {
+		return
+	}
+	mw.updateWindowTitle()
+	mw.refreshLayoutDropdown()
+}
+
+// updateWindowTitle marks the window title with an asterisk while there are unsaved changes
+func (mw *MainWindow) updateWindowTitle() {
+	title := "GopherAutomate"
+	if mw.dirty || mw.actionStore.Dirty() {
+		title += dirtyMarker
+	}
+	mw.window.SetTitle(title)
 }
 
 func (mw *MainWindow) revertLayout() {
@@ -946,23 +1345,38 @@ func (mw *MainWindow) clearGrid() {
 	if menu == nil {
 		return
 	}
-	for row := 0; row < 9; row++ {
-		for col := 0; col < 9; col++ {
-			menu.Colors[row][col] = config.PadColorConfig{R: 0, G: 0, B: 0}
-			mw.updateGridRect(row, col)
-		}
-	}
+	menu.ClearPads()
+	mw.refreshGrid()
 	mw.setDirty(true)
 }
 
+// saveLayouts writes the layouts, which one is current and the top row to disk without
+// also saving unsaved device, action or mapping edits
+func (mw *MainWindow) saveLayouts() error {
+	return config.Update(func(saved *config.Config) {
+		saved.Menus = mw.cfg.Menus
+		saved.CurrentMenuID = mw.cfg.CurrentMenuID
+		saved.TopRow = mw.cfg.TopRow
+	})
+}
+
+// saveLayoutsOrShow is saveLayouts for changes to the layout list, which are saved
+// as they are made
+func (mw *MainWindow) saveLayoutsOrShow() {
+	if err := mw.saveLayouts(); err != nil {
+		slog.Error("Failed to save layouts", "err", err)
+		dialog.ShowError(err, mw.window)
+	}
+}
+
 func (mw *MainWindow) saveLayout() {
-	if err := mw.cfg.Save(); err != nil {
-		log.Printf("Failed to save layout: %v", err)
+	if err := mw.saveLayouts(); err != nil {
+		slog.Error("Failed to save layout", "err", err)
 	} else {
-		log.Printf("Layout saved")
+		slog.Info("Layout saved")
 		mw.setDirty(false)
 		// Apply to devices after save
-		mw.sendGridToDevices()
+		mw.engine.QueueLayoutSends()
 	}
 }
 
@@ -973,158 +1387,217 @@ func (mw *MainWindow) saveAsNewLayout() {
 	}
 
 	entry := widget.NewEntry()
-	entry.SetPlaceHolder("Layout Name")
-	entry.SetText(currentMenu.Name + " Copy")
+	entry.SetPlaceHolder(i18n.T("common.layout_name"))
+	entry.SetText(i18n.T("menu_editor.copy_name", currentMenu.Name))
 
-	dialog.ShowCustomConfirm("Save As New Layout", "Save", "Cancel",
-		container.NewVBox(widget.NewLabel("Enter a name for the new layout:"), entry),
+	dialog.ShowCustomConfirm(i18n.T("menu_editor.save_as_new_title"), i18n.T("common.save"), i18n.T("common.cancel"),
+		container.NewVBox(widget.NewLabel(i18n.T("common.enter_layout_name")), entry),
 		func(confirm bool) {
 			if confirm && entry.Text != "" {
-				// Create new layout with copied colors
-				newMenu := config.NewMenuLayout()
+				// Copy every pad area, actions included
+				newMenu := currentMenu.Clone()
 				newMenu.Name = entry.Text
-				newMenu.Colors = currentMenu.Colors // Copy colors
 
 				mw.cfg.Menus = append(mw.cfg.Menus, newMenu)
 				mw.cfg.CurrentMenuID = newMenu.ID
-				mw.layoutDropdown.Options = mw.getLayoutNames()
-				mw.layoutDropdown.SetSelected(newMenu.Name)
-				mw.cfg.Save()
-				mw.sendGridToDevices()
+				mw.setDirty(false)
+				mw.refreshLayoutDropdown()
+				mw.saveLayoutsOrShow()
+				mw.engine.QueueLayoutSends()
 			}
 		}, mw.window)
 }
 
-func (mw *MainWindow) sendGridToDevices() {
-	for _, device := range mw.cfg.Devices {
-		if device.OutPort == "" {
-			continue
-		}
-
-		// Find the menu assigned to this device
-		var menu *config.MenuLayout
-		if device.MainMenu == "" {
-			// No menu assigned, skip this device
-			continue
-		}
-		for i := range mw.cfg.Menus {
-			if mw.cfg.Menus[i].Name == device.MainMenu {
-				menu = &mw.cfg.Menus[i]
-				break
-			}
-		}
-		if menu == nil {
-			log.Printf("Menu '%s' not found for device %s", device.MainMenu, device.Name)
-			continue
-		}
-
-		// Ensure legacy/uninitialized colors are linked and converted before sending
-		mw.ensureDefaultLinking(menu)
-
-		deviceType := midi.DeviceType(device.Type)
-
-		for row := 0; row < 9; row++ {
-			for col := 0; col < 9; col++ {
-				c := menu.Colors[row][col]
-
-				// Use classic colors for classic devices, button colors for colorful devices
-				var padColor midi.PadColor
-				if deviceType == midi.DeviceTypeClassic {
-					padColor = midi.PadColor{R: c.ClassicR, G: c.ClassicG, B: c.ClassicB}
-				} else {
-					padColor = midi.PadColor{R: c.R, G: c.G, B: c.B}
-				}
-
-				if err := mw.midiManager.SetPadColor(device.OutPort, deviceType, row, col, padColor); err != nil {
-					log.Printf("Failed to set pad color: %v", err)
-				}
-			}
-		}
-		log.Printf("Sent layout '%s' to %s", menu.Name, device.Name)
+// refreshPadActionOptions updates what the action pickers of the menu editor and
+// Preferences offer
+func (mw *MainWindow) refreshPadActionOptions() {
+	mw.refreshAppHookPickers()
+	if mw.padActionPicker == nil {
+		return
 	}
+	mw.padActionPicker.SetChoices(mw.actionChoices())
+	mw.refreshLayoutActionSelects()
 }
 
-// refreshPadActionOptions updates the action dropdown options
-func (mw *MainWindow) refreshPadActionOptions() {
-	if mw.padActionSelect == nil {
+// refreshLayoutActionSelects shows the current layout's fallback and activation
+// actions, offering what pads can be assigned
+func (mw *MainWindow) refreshLayoutActionSelects() {
+	if mw.fallbackPicker == nil || mw.padActionPicker == nil {
 		return
 	}
-
-	options := []string{"(None)"}
-	items := mw.actionStore.GetFlatList()
-	for _, item := range items {
-		if item.IsGroup {
-			// Add group header (indented)
-			indent := strings.Repeat("  ", item.Depth)
-			options = append(options, indent+"📁 "+item.Group.Name)
-		} else {
-			// Add action (indented)
-			indent := strings.Repeat("  ", item.Depth)
-			options = append(options, indent+item.Action.Name)
-		}
+	var fallbackID, activateID string
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		fallbackID, activateID = menu.FallbackActionID, menu.OnActivateActionID
+	}
+	show := func(picker *actionPicker, id string) {
+		picker.SetChoices(mw.padActionPicker.choices)
+		picker.SetSelected(id)
 	}
-	mw.padActionSelect.Options = options
+	show(mw.fallbackPicker, fallbackID)
+	show(mw.activatePicker, activateID)
 }
 
-// onPadActionChanged handles when the user selects an action for a pad
-func (mw *MainWindow) onPadActionChanged(s string) {
+// setLayoutAction changes one of the current layout's own actions
+func (mw *MainWindow) setLayoutAction(apply func(m *config.MenuLayout)) {
 	menu := mw.cfg.GetCurrentMenu()
 	if menu == nil {
 		return
 	}
+	apply(menu)
+	mw.setDirty(true)
+}
 
-	padColor := &menu.Colors[mw.selectedRow][mw.selectedCol]
-
-	if s == "(None)" || strings.HasPrefix(strings.TrimSpace(s), "📁") {
-		// None selected or a group header (which can't be assigned)
-		padColor.ActionID = ""
-	} else {
-		// Find action by name (trimmed of indentation)
-		actionName := strings.TrimSpace(s)
-		items := mw.actionStore.GetFlatList()
-		for _, item := range items {
-			if !item.IsGroup && item.Action.Name == actionName {
-				padColor.ActionID = item.Action.ID
-				break
-			}
-		}
+// onPadActionChanged handles when the user picks an action for a pad
+func (mw *MainWindow) onPadActionChanged(id string) {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
 	}
+
+	menu.Colors[mw.selectedRow][mw.selectedCol].ActionID = id
 	mw.setDirty(true)
+	mw.updateTopRowWarning()
+	mw.updatePadLabel(mw.selectedRow, mw.selectedCol)
 }
 
-// updatePadActionSelection updates the action dropdown when a pad is selected
+// groupOption is the label of a group among actions, set apart from them
+func groupOption(name string) string {
+	return "📁 " + i18n.T("menu_editor.group_option", name)
+}
+
+// updatePadActionSelection updates the action picker when a pad is selected
 func (mw *MainWindow) updatePadActionSelection() {
-	if mw.padActionSelect == nil {
+	if mw.padActionPicker == nil {
 		return
 	}
 
 	menu := mw.cfg.GetCurrentMenu()
 	if menu == nil {
-		mw.padActionSelect.SetSelected("(None)")
+		mw.padActionPicker.SetSelected("")
 		return
 	}
 
-	padColor := menu.Colors[mw.selectedRow][mw.selectedCol]
-	if padColor.ActionID == "" {
-		mw.padActionSelect.SetSelected("(None)")
+	mw.padActionPicker.SetSelected(menu.Colors[mw.selectedRow][mw.selectedCol].ActionID)
+}
+
+// updatePadArgsEntry shows the selected pad's action arguments
+func (mw *MainWindow) updatePadArgsEntry() {
+	if mw.padArgsEntry == nil {
 		return
 	}
+	args := ""
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		args = menu.Colors[mw.selectedRow][mw.selectedCol].ActionArgs
+	}
+	setTextSilently(mw.padArgsEntry, args)
+}
+
+// busyColorPreset is a busy color the pad and preferences selects offer
+type busyColorPreset struct {
+	key     string // i18n key of its name
+	r, g, b uint8
+}
+
+// busyColorPresets are the offered busy colors; the first is the default one
+var busyColorPresets = []busyColorPreset{
+	{"menu_editor.busy_orange", 127, 64, 0},
+	{"menu_editor.busy_yellow", 127, 127, 0},
+	{"menu_editor.busy_blue", 0, 0, 127},
+	{"menu_editor.busy_purple", 64, 0, 127},
+	{"menu_editor.busy_white", 127, 127, 127},
+}
+
+// busyColorOptions returns the translated preset names, in busyColorPresets order
+func busyColorOptions() []string {
+	options := make([]string, len(busyColorPresets))
+	for i, preset := range busyColorPresets {
+		options[i] = i18n.T(preset.key)
+	}
+	return options
+}
+
+// busyColorIndex returns the preset with the given color, or -1
+func busyColorIndex(r, g, b uint8) int {
+	return slices.IndexFunc(busyColorPresets, func(p busyColorPreset) bool { return p.r == r && p.g == g && p.b == b })
+}
 
-	// Find the action and select it
-	action := mw.cfg.GetAction(padColor.ActionID)
-	if action == nil {
-		mw.padActionSelect.SetSelected("(None)")
+// updatePadBusySelection shows the selected pad's busy color
+func (mw *MainWindow) updatePadBusySelection() {
+	if mw.padBusySelect == nil {
+		return
+	}
+	label := i18n.T("menu_editor.busy_default")
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		pad := menu.Colors[mw.selectedRow][mw.selectedCol]
+		if pad.BusyR > 0 || pad.BusyG > 0 || pad.BusyB > 0 {
+			label = i18n.T("menu_editor.busy_custom")
+			if i := busyColorIndex(pad.BusyR, pad.BusyG, pad.BusyB); i >= 0 {
+				label = i18n.T(busyColorPresets[i].key)
+			}
+		}
+	}
+	if slices.Contains(mw.padBusySelect.Options, label) {
+		setSelectedSilently(mw.padBusySelect, label)
 		return
 	}
 
-	// Find the option that matches this action
-	items := mw.actionStore.GetFlatList()
-	for _, item := range items {
-		if !item.IsGroup && item.Action.ID == padColor.ActionID {
-			indent := strings.Repeat("  ", item.Depth)
-			mw.padActionSelect.SetSelected(indent + item.Action.Name)
-			return
+	// A color set by hand in the config file is shown without selecting an option
+	onChanged := mw.padBusySelect.OnChanged
+	mw.padBusySelect.OnChanged = nil
+	mw.padBusySelect.PlaceHolder = label
+	mw.padBusySelect.ClearSelected()
+	mw.padBusySelect.OnChanged = onChanged
+}
+
+// updatePadDebounceSelection shows the selected pad's debounce override
+// lightModeOptions returns the translated light modes, in config.LightModes order
+func lightModeOptions() []string {
+	return []string{i18n.T("menu_editor.light_static"), i18n.T("menu_editor.light_flash")}
+}
+
+// updatePadLightSelection shows the selected pad's light mode
+func (mw *MainWindow) updatePadLightSelection() {
+	if mw.padLightSelect == nil {
+		return
+	}
+	i := 0
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		i = max(slices.Index(config.LightModes, menu.Colors[mw.selectedRow][mw.selectedCol].LightMode), 0)
+	}
+	setSelectedSilently(mw.padLightSelect, mw.padLightSelect.Options[i])
+}
+
+// updatePadSymbolEntry shows the selected pad's symbol
+func (mw *MainWindow) updatePadSymbolEntry() {
+	if mw.padSymbolEntry == nil {
+		return
+	}
+	symbol := ""
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		symbol = menu.Colors[mw.selectedRow][mw.selectedCol].Symbol
+	}
+	setTextSilently(mw.padSymbolEntry, symbol)
+}
+
+func (mw *MainWindow) updatePadDebounceSelection() {
+	if mw.padDebounceSelect == nil {
+		return
+	}
+	label := i18n.T("menu_editor.debounce_default")
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		if ms := menu.Colors[mw.selectedRow][mw.selectedCol].DebounceMs; ms > 0 {
+			label = debounceLabel(ms)
 		}
 	}
-	mw.padActionSelect.SetSelected("(None)")
+	if slices.Contains(mw.padDebounceSelect.Options, label) {
+		setSelectedSilently(mw.padDebounceSelect, label)
+		return
+	}
+
+	// A value set by hand in the config file is shown without selecting an option
+	onChanged := mw.padDebounceSelect.OnChanged
+	mw.padDebounceSelect.OnChanged = nil
+	mw.padDebounceSelect.PlaceHolder = label
+	mw.padDebounceSelect.ClearSelected()
+	mw.padDebounceSelect.OnChanged = onChanged
 }
@@ -0,0 +1,183 @@
+package window
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+func TestSwitchLayoutCancelKeepsDirtyState(t *testing.T) {
+	mw := newTestWindow(t, "First", "Second")
+	mw.setDirty(true)
+	if got := mw.layoutDropdown.Selected; got != "First"+dirtyMarker {
+		t.Fatalf("dropdown shows %q while dirty, want %q", got, "First"+dirtyMarker)
+	}
+
+	mw.layoutDropdown.SetSelected("Second") // As the user picks it
+	tapButton(t, mw, i18n.T("common.cancel"))
+
+	if got := mw.cfg.GetCurrentMenu().Name; got != "First" {
+		t.Errorf("current layout = %q after cancelling, want First", got)
+	}
+	if got := mw.layoutDropdown.Selected; got != "First"+dirtyMarker {
+		t.Errorf("dropdown shows %q after cancelling, want %q", got, "First"+dirtyMarker)
+	}
+	if !mw.dirty || !mw.unsavedLabel.Visible() {
+		t.Error("unsaved changes no longer shown after cancelling")
+	}
+	if got := mw.window.Title(); got != "GopherAutomate"+dirtyMarker {
+		t.Errorf("title = %q after cancelling, want it marked dirty", got)
+	}
+}
+
+func TestSwitchLayoutDiscardClearsDirtyState(t *testing.T) {
+	mw := newTestWindow(t, "First", "Second")
+	mw.setDirty(true)
+
+	mw.layoutDropdown.SetSelected("Second")
+	tapButton(t, mw, i18n.T("menu_editor.continue"))
+
+	if got := mw.cfg.GetCurrentMenu().Name; got != "Second" {
+		t.Errorf("current layout = %q after discarding, want Second", got)
+	}
+	if got := mw.layoutDropdown.Selected; got != "Second" {
+		t.Errorf("dropdown shows %q after discarding, want Second", got)
+	}
+	if mw.dirty || mw.unsavedLabel.Visible() {
+		t.Error("unsaved changes still shown after discarding")
+	}
+	if got := mw.window.Title(); got != "GopherAutomate" {
+		t.Errorf("title = %q after discarding, want it unmarked", got)
+	}
+}
+
+func TestRefreshLayoutDropdownDoesNotSwitch(t *testing.T) {
+	mw := newTestWindow(t, "First", "Second")
+	mw.cfg.CurrentMenuID = mw.cfg.Menus[1].ID
+	mw.refreshLayoutDropdown() // Must not load anything through OnChanged
+
+	if got := mw.layoutDropdown.Selected; got != "Second" {
+		t.Errorf("dropdown shows %q, want Second", got)
+	}
+	if mw.dirty {
+		t.Error("programmatic selection marked the layout dirty")
+	}
+}
+
+// TestPadOverrideOfLayoutDefault checks the override toggle: on, the pad keeps a copy
+// of the inherited color; off, it goes back to the default
+func TestPadOverrideOfLayoutDefault(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	menu := mw.cfg.GetCurrentMenu()
+	menu.DefaultStatic.R = 90
+	menu.DefaultPressed.PressedB = 60
+	mw.selectPad(4, 4)
+
+	if mw.overrideStaticCheck.Checked || !mw.staticInheritedLabel.Visible() {
+		t.Error("pad without colors not shown as inheriting its static color")
+	}
+
+	mw.overrideStaticCheck.SetChecked(true)
+	pad := menu.Colors[4][4]
+	if !pad.OverrideStatic || pad.R != 90 {
+		t.Errorf("pad after overriding = %+v, want its own copy of R 90", pad)
+	}
+	if pad.HasOwnPressed() || !mw.pressedInheritedLabel.Visible() {
+		t.Error("overriding the static color also overrode the pressed one")
+	}
+	if mw.staticInheritedLabel.Visible() {
+		t.Error("inherited badge still shown after overriding")
+	}
+
+	// The default changes no longer reach the pad
+	menu.DefaultStatic.R = 10
+	if got := menu.EffectiveColor(4, 4).R; got != 90 {
+		t.Errorf("overridden pad R = %d after changing the default, want 90", got)
+	}
+
+	mw.overrideStaticCheck.SetChecked(false)
+	if pad := menu.Colors[4][4]; pad.HasOwnStatic() {
+		t.Errorf("pad after clearing the override = %+v, want it inheriting", pad)
+	}
+	if got := menu.EffectiveColor(4, 4).R; got != 10 {
+		t.Errorf("pad R = %d after clearing the override, want the default 10", got)
+	}
+	if !mw.dirty {
+		t.Error("overriding didn't mark the layout dirty")
+	}
+}
+
+func TestSelectionRedrawsOnlyTouchedPads(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	mw.selectPad(2, 3)
+
+	mw.cellRefreshes = 0
+	mw.selectPad(5, 6)
+	if mw.cellRefreshes > 2 {
+		t.Errorf("selecting another pad redrew %d pads, want at most 2", mw.cellRefreshes)
+	}
+
+	mw.cellRefreshes = 0
+	mw.selectPad(5, 6)
+	if mw.cellRefreshes > 1 {
+		t.Errorf("selecting the same pad redrew %d pads, want at most 1", mw.cellRefreshes)
+	}
+
+	mw.cellRefreshes = 0
+	mw.buttonRSlider.SetValue(100)
+	if mw.cellRefreshes != 1 {
+		t.Errorf("a color change redrew %d pads, want the selected one", mw.cellRefreshes)
+	}
+
+	mw.cellRefreshes = 0
+	mw.refreshGrid()
+	if mw.cellRefreshes != 81 {
+		t.Errorf("refreshGrid redrew %d pads, want all 81", mw.cellRefreshes)
+	}
+}
+
+func TestSelectPadKeepsIntentionallyBlackClassic(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	menu := mw.cfg.GetCurrentMenu()
+	menu.Colors[4][4] = config.PadColorConfig{R: 127, ClassicInitialized: true} // Lit on RGB devices only
+	menu.Colors[4][5] = config.PadColorConfig{R: 127}                           // Classic color never set
+
+	mw.selectPad(4, 4)
+	if p := menu.Colors[4][4]; p.ClassicR != 0 || p.ClassicG != 0 || mw.classicRSlider.Value != 0 {
+		t.Errorf("black classic color replaced: %+v, slider %v", p, mw.classicRSlider.Value)
+	}
+	mw.selectPad(4, 5)
+	if p := menu.Colors[4][5]; p.ClassicR != 127 || !p.ClassicInitialized || mw.classicRSlider.Value != 3 {
+		t.Errorf("classic color not derived: %+v, slider %v", p, mw.classicRSlider.Value)
+	}
+
+	// Setting a classic color to black by hand keeps it black
+	mw.classicRSlider.SetValue(0)
+	mw.selectPad(4, 4)
+	mw.selectPad(4, 5)
+	if p := menu.Colors[4][5]; p.ClassicR != 0 || !p.ClassicInitialized || p.LinkButtonClassic {
+		t.Errorf("classic color set to black came back: %+v", p)
+	}
+}
+
+// TestSaveLayoutLeavesOtherEditsUnsaved checks that saving the layout doesn't save the
+// device edits pending
+func TestSaveLayoutLeavesOtherEditsUnsaved(t *testing.T) {
+	mw := newTestWindow(t, "First")
+	mw.cfg.GetCurrentMenu().Colors[1][1].R = 99
+	mw.cfg.Devices = append(mw.cfg.Devices, mw.cfg.NewDevice())
+
+	mw.saveLayout()
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.GetCurrentMenu().Colors[1][1].R != 99 {
+		t.Error("layout edit not saved")
+	}
+	if len(saved.Devices) != len(mw.cfg.Devices)-1 {
+		t.Errorf("%d devices saved, want the unsaved one left out", len(saved.Devices))
+	}
+}
@@ -0,0 +1,88 @@
+package window
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/dialog"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// The pads around the 9x9 grid are only lit: the Pro sends nothing the engine handles
+// for them, so they take a color and none of the grid pads' settings.
+
+// newOuterPad builds the editor pad for one of the Pro pads around the grid; tapping it
+// picks its color
+func (mw *MainWindow) newOuterPad(pad config.PlanPad) fyne.CanvasObject {
+	rect := canvas.NewRectangle(color.Black)
+	rect.SetMinSize(fyne.NewSize(mw.padSize(), mw.padSize()))
+	rect.CornerRadius = mw.padSize() / 2 // Round, unlike the grid pads
+	mw.outerRects[pad] = rect
+
+	btn := newTappableRect(rect, func() { mw.pickOuterPadColor(pad) })
+	btn.onFocus = mw.showPadLabel
+	btn.SetLabel(outerPadLabel(pad))
+	mw.refreshOuterPad(pad)
+	return btn
+}
+
+// outerPadLabel describes a pad around the grid: its area, and its place there unless
+// it is a corner
+func outerPadLabel(pad config.PlanPad) string {
+	if n := max(pad.Row, pad.Col); n > 0 {
+		return i18n.T("menu_editor.outer_pad", string(pad.Area), n)
+	}
+	return i18n.T("menu_editor.outer_corner", string(pad.Area))
+}
+
+// newOuterSpacer fills a cell of the Pro plan without a pad; the grid sizes it like the pads
+func (mw *MainWindow) newOuterSpacer() fyne.CanvasObject {
+	return canvas.NewRectangle(color.Transparent)
+}
+
+// refreshOuterPads redraws the pads around the grid with the current layout's colors
+func (mw *MainWindow) refreshOuterPads() {
+	for pad := range mw.outerRects {
+		mw.refreshOuterPad(pad)
+	}
+}
+
+func (mw *MainWindow) refreshOuterPad(pad config.PlanPad) {
+	rect := mw.outerRects[pad]
+	rect.FillColor = color.Black
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		if c := menu.Pad(pad); c != nil {
+			rect.FillColor = padcolor.RGBA(c.R, c.G, c.B)
+		}
+	}
+	rect.Refresh()
+}
+
+// pickOuterPadColor asks for the color of a pad around the grid
+func (mw *MainWindow) pickOuterPadColor(pad config.PlanPad) {
+	picker := dialog.NewColorPicker(i18n.T("menu_editor.outer_pad_title"),
+		outerPadLabel(pad),
+		func(c color.Color) { mw.setOuterPadColor(pad, c) }, mw.window)
+	picker.Advanced = true
+	picker.SetColor(mw.outerRects[pad].FillColor)
+	picker.Show()
+}
+
+// setOuterPadColor sets the color of a pad around the grid in the current layout
+func (mw *MainWindow) setOuterPadColor(pad config.PlanPad, c color.Color) {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+	target := menu.Pad(pad)
+	if target == nil {
+		return
+	}
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	target.R, target.G, target.B = padcolor.FromDisplay(rgba.R), padcolor.FromDisplay(rgba.G), padcolor.FromDisplay(rgba.B)
+	mw.refreshOuterPad(pad)
+	mw.setDirty(true)
+}
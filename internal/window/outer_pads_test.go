@@ -0,0 +1,81 @@
+package window
+
+import (
+	"image/color"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// gridColumns returns the number of columns of the menu editor grid
+func gridColumns(t *testing.T, mw *MainWindow) int {
+	t.Helper()
+	grid := mw.gridContainer.Objects[0].(*fyne.Container)
+	cols := mw.gridShape.Cols
+	if len(grid.Objects) != mw.gridShape.Rows*cols {
+		t.Fatalf("grid has %d cells for a %dx%d shape", len(grid.Objects), mw.gridShape.Rows, cols)
+	}
+	return cols
+}
+
+func TestEditorGridFollowsDeviceShape(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	if cols := gridColumns(t, mw); cols != 9 || len(mw.outerRects) != 0 {
+		t.Fatalf("without a Pro: %d columns and %d outer pads, want 9 and none", cols, len(mw.outerRects))
+	}
+
+	device := mw.cfg.NewDevice()
+	device.Type = config.DeviceTypePro
+	mw.cfg.AddDevice(device)
+	mw.refreshGrid()
+	if cols := gridColumns(t, mw); cols != 10 || len(mw.outerRects) != 27 {
+		t.Fatalf("with a Pro: %d columns and %d outer pads, want 10 and 27", cols, len(mw.outerRects))
+	}
+	// The grid pads are still the editor's 9x9 pads, beside the left column
+	grid := mw.gridContainer.Objects[0].(*fyne.Container)
+	if grid.Objects[1] != mw.gridPads[0][0] || grid.Objects[10+9] != mw.gridPads[1][8] {
+		t.Error("grid pads are not at their place on the Pro plan")
+	}
+
+	mw.cfg.RemoveDevice(device.ID)
+	mw.refreshGrid()
+	if cols := gridColumns(t, mw); cols != 9 || len(mw.outerRects) != 0 {
+		t.Errorf("after removing the Pro: %d columns and %d outer pads", cols, len(mw.outerRects))
+	}
+}
+
+func TestSetOuterPadColor(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	device := mw.cfg.NewDevice()
+	device.Type = config.DeviceTypePro
+	mw.cfg.AddDevice(device)
+	mw.refreshGrid()
+
+	corner := config.PlanPad{Area: config.PadAreaBottomLeft}
+	bottom := config.PlanPad{Area: config.PadAreaBottom, Col: 2}
+	mw.setOuterPadColor(corner, color.RGBA{R: 255, A: 255})
+	mw.setOuterPadColor(bottom, color.RGBA{G: 128, B: 255, A: 255})
+
+	menu := mw.cfg.GetCurrentMenu()
+	if c := menu.BottomLeftColor; c.R != 127 || c.G != 0 || c.B != 0 {
+		t.Errorf("bottom-left corner = %d,%d,%d, want 127,0,0", c.R, c.G, c.B)
+	}
+	if c := menu.BottomColors[1]; c.R != 0 || c.G != 64 || c.B != 127 {
+		t.Errorf("bottom pad 2 = %d,%d,%d, want 0,64,127", c.R, c.G, c.B)
+	}
+	if fill := mw.outerRects[corner].FillColor; fill != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("corner shown as %v", fill)
+	}
+	if !mw.dirty {
+		t.Error("layout not marked unsaved")
+	}
+
+	mw.clearGrid()
+	if menu.BottomLeftColor != (config.PadColorConfig{}) || menu.BottomColors[1] != (config.PadColorConfig{}) {
+		t.Error("Clear All kept the outer pads")
+	}
+	if fill := mw.outerRects[corner].FillColor; fill != (color.RGBA{A: 255}) {
+		t.Errorf("cleared corner shown as %v", fill)
+	}
+}
@@ -0,0 +1,580 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/httpapi"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/logging"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/startup"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// ============ PREFERENCES TAB ============
+
+func (mw *MainWindow) createPreferencesTab() fyne.CanvasObject {
+	header := widget.NewLabel(i18n.T("common.preferences"))
+	header.TextStyle = fyne.TextStyle{Bold: true}
+
+	mw.prefsFeedback = widget.NewLabel("")
+
+	// General
+	mw.startupCheck = widget.NewCheck(i18n.T("prefs.open_at_startup"), func(checked bool) {
+		if err := mw.SetOpenAtStartup(checked); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	})
+	mw.startupCheck.Checked = mw.cfg.OpenAtStartup
+
+	startupArgsEntry := widget.NewEntry()
+	startupArgsEntry.SetPlaceHolder(i18n.T("prefs.startup_args_placeholder"))
+	startupArgsEntry.SetText(strings.Join(mw.cfg.StartupArgs, " "))
+	startupArgsEntry.OnSubmitted = func(s string) {
+		mw.cfg.StartupArgs = strings.Fields(s)
+		if mw.cfg.OpenAtStartup {
+			// Re-register so the new arguments are used
+			if err := mw.SetOpenAtStartup(true); err != nil {
+				dialog.ShowError(err, mw.window)
+			}
+			return
+		}
+		mw.savePreferences()
+	}
+
+	var resetWarningBtn *widget.Button
+	resetWarningBtn = widget.NewButton(i18n.T("prefs.reset_unsaved_warning"), func() {
+		mw.cfg.SuppressUnsavedWarning = false
+		resetWarningBtn.Disable()
+		mw.savePreferences()
+	})
+	if !mw.cfg.SuppressUnsavedWarning {
+		resetWarningBtn.Disable()
+	}
+	mw.resetWarningBtn = resetWarningBtn
+
+	// Language; the UI is built once, so a change applies after a restart
+	languageOptions := []string{i18n.T("prefs.language.system")}
+	languageCodes := []string{""}
+	for _, language := range i18n.Languages() {
+		languageOptions = append(languageOptions, language.Name)
+		languageCodes = append(languageCodes, language.Code)
+	}
+	languageSelect := widget.NewSelect(languageOptions, func(s string) {
+		mw.cfg.Language = languageCodes[slices.Index(languageOptions, s)]
+		mw.savePreferences()
+		mw.prefsFeedback.SetText(i18n.T("prefs.language.restart"))
+	})
+	if i := slices.Index(languageCodes, mw.cfg.Language); i >= 0 {
+		setSelectedSilently(languageSelect, languageOptions[i])
+	} else {
+		setSelectedSilently(languageSelect, languageOptions[0])
+	}
+
+	// Script editor height, applied to the open editor right away
+	rowOptions := []string{}
+	for _, rows := range codeEditorRowChoices {
+		rowOptions = append(rowOptions, i18n.T("prefs.code_editor_rows_option", rows))
+	}
+	rowsSelect := widget.NewSelect(rowOptions, func(s string) {
+		mw.cfg.CodeEditorRows = codeEditorRowChoices[slices.Index(rowOptions, s)]
+		mw.codeEditor.SetRows(mw.cfg.CodeEditorRows)
+		mw.savePreferences()
+	})
+	if i := slices.Index(codeEditorRowChoices, mw.codeEditorRows()); i >= 0 {
+		setSelectedSilently(rowsSelect, rowOptions[i])
+	}
+
+	// Larger pads and buttons for touch screens and motor impairments
+	hitOptions := []string{}
+	for _, size := range hitTargetChoices {
+		hitOptions = append(hitOptions, hitTargetLabel(size))
+	}
+	hitSelect := widget.NewSelect(hitOptions, func(s string) {
+		mw.cfg.MinHitTarget = hitTargetChoices[slices.Index(hitOptions, s)]
+		mw.applyHitTarget()
+		mw.savePreferences()
+	})
+	if i := slices.Index(hitTargetChoices, mw.cfg.MinHitTarget); i >= 0 {
+		setSelectedSilently(hitSelect, hitOptions[i])
+	} else {
+		hitSelect.PlaceHolder = hitTargetLabel(mw.cfg.MinHitTarget) // Set by hand in the config file
+	}
+
+	// Pad debounce, read on every press so it applies right away
+	debounceOptions := []string{}
+	for _, ms := range padDebounceChoices {
+		debounceOptions = append(debounceOptions, debounceLabel(ms))
+	}
+	debounceSelect := widget.NewSelect(debounceOptions, func(s string) {
+		mw.cfg.PadDebounceMs = padDebounceChoices[slices.Index(debounceOptions, s)]
+		mw.savePreferences()
+	})
+	if i := slices.Index(padDebounceChoices, mw.cfg.PadDebounceMs); i >= 0 {
+		setSelectedSilently(debounceSelect, debounceOptions[i])
+	} else {
+		debounceSelect.PlaceHolder = debounceLabel(mw.cfg.PadDebounceMs) // Set by hand in the config file
+	}
+
+	// Device startup at launch, used from the next launch on
+	delayOptions := []string{}
+	for _, s := range startupDelayChoices {
+		delayOptions = append(delayOptions, secondsLabel(s))
+	}
+	delaySelect := widget.NewSelect(delayOptions, func(s string) {
+		mw.cfg.StartupInit.DelaySeconds = startupDelayChoices[slices.Index(delayOptions, s)]
+		mw.savePreferences()
+	})
+	retryOptions := []string{}
+	for _, s := range startupRetryChoices {
+		retryOptions = append(retryOptions, secondsLabel(s))
+	}
+	retrySelect := widget.NewSelect(retryOptions, func(s string) {
+		mw.cfg.StartupInit.RetrySeconds = startupRetryChoices[slices.Index(retryOptions, s)]
+		mw.savePreferences()
+	})
+	for _, pick := range []struct {
+		sel     *widget.Select
+		choices []int
+		value   int
+	}{
+		{delaySelect, startupDelayChoices, mw.cfg.StartupInit.DelaySeconds},
+		{retrySelect, startupRetryChoices, mw.cfg.StartupInit.RetrySeconds},
+	} {
+		if i := slices.Index(pick.choices, pick.value); i >= 0 {
+			setSelectedSilently(pick.sel, pick.sel.Options[i])
+		} else {
+			pick.sel.PlaceHolder = secondsLabel(pick.value) // Set by hand in the config file
+		}
+	}
+
+	// Actions the app runs at launch, once devices are up, and when it quits
+	mw.startupActionPicker = mw.newActionPicker(func(id string) {
+		mw.cfg.StartupActionID = id
+		mw.savePreferences()
+	})
+	mw.shutdownActionPicker = mw.newActionPicker(func(id string) {
+		mw.cfg.ShutdownActionID = id
+		mw.savePreferences()
+	})
+	mw.refreshAppHookPickers()
+
+	// Virtual output for other apps, only offered where the MIDI system can create one
+	virtualOutCheck := widget.NewCheck(i18n.T("prefs.virtual_out", midi.VirtualOutName), func(checked bool) {
+		mw.setVirtualOutPort(checked)
+	})
+	virtualOutCheck.Checked = mw.cfg.VirtualOutPort
+	if !midi.VirtualPortsSupported() {
+		virtualOutCheck.Hide()
+	}
+
+	general := widget.NewForm(
+		widget.NewFormItem(i18n.T("prefs.language"), languageSelect),
+		widget.NewFormItem(i18n.T("prefs.code_editor_rows"), rowsSelect),
+		widget.NewFormItem(i18n.T("prefs.hit_target"), hitSelect),
+		widget.NewFormItem(i18n.T("prefs.pad_debounce"), debounceSelect),
+		widget.NewFormItem("", virtualOutCheck),
+		widget.NewFormItem("", mw.startupCheck),
+		widget.NewFormItem(i18n.T("prefs.startup_args"), startupArgsEntry),
+		widget.NewFormItem(i18n.T("prefs.startup_delay"), delaySelect),
+		widget.NewFormItem(i18n.T("prefs.startup_retry"), retrySelect),
+		widget.NewFormItem(i18n.T("prefs.startup_action"), mw.startupActionPicker),
+		widget.NewFormItem(i18n.T("prefs.shutdown_action"), mw.shutdownActionPicker),
+		widget.NewFormItem("", container.NewHBox(resetWarningBtn)),
+	)
+
+	// Defaults for new devices
+	defaults := &mw.cfg.DeviceDefaults
+	brightnessLabel := widget.NewLabel(fmt.Sprintf("%d%%", defaults.Brightness))
+	brightnessSlider := widget.NewSlider(10, 100)
+	brightnessSlider.Step = 5
+	brightnessSlider.Value = float64(defaults.Brightness)
+	brightnessSlider.OnChanged = func(v float64) {
+		defaults.Brightness = int(v)
+		brightnessLabel.SetText(fmt.Sprintf("%d%%", defaults.Brightness))
+	}
+	brightnessSlider.OnChangeEnded = func(float64) { mw.savePreferences() }
+
+	feedbackCheck := widget.NewCheck(i18n.T("common.show_pressed_colors"), func(checked bool) {
+		defaults.SendPressedFeedback = checked
+		mw.savePreferences()
+	})
+	feedbackCheck.Checked = defaults.SendPressedFeedback
+
+	layoutCheck := widget.NewCheck(i18n.T("prefs.send_layouts"), func(checked bool) {
+		defaults.SendStaticLayout = checked
+		mw.savePreferences()
+	})
+	layoutCheck.Checked = defaults.SendStaticLayout
+
+	deviceDefaults := widget.NewForm(
+		widget.NewFormItem(i18n.T("common.brightness"), container.NewBorder(nil, nil, nil, brightnessLabel, brightnessSlider)),
+		widget.NewFormItem("", feedbackCheck),
+		widget.NewFormItem("", layoutCheck),
+	)
+
+	// Logging
+	levelSelect := widget.NewSelect(logging.Levels, func(s string) {
+		mw.cfg.LogLevel = s
+		logging.SetLevel(s)
+		mw.savePreferences()
+	})
+	setSelectedSilently(levelSelect, logging.LevelName(mw.cfg.LogLevel))
+
+	trafficCheck := widget.NewCheck(i18n.T("prefs.log_midi"), func(checked bool) {
+		mw.cfg.LogMIDITraffic = checked
+		midi.SetTraceTraffic(checked)
+		mw.savePreferences()
+	})
+	trafficCheck.Checked = mw.cfg.LogMIDITraffic
+
+	usageCheck := widget.NewCheck(i18n.T("prefs.usage_stats"), func(checked bool) {
+		mw.cfg.DisableUsageStats = !checked
+		usage.SetEnabled(checked)
+		mw.savePreferences()
+	})
+	usageCheck.Checked = !mw.cfg.DisableUsageStats
+
+	logSettings := widget.NewForm(
+		widget.NewFormItem(i18n.T("prefs.log_level"), levelSelect),
+		widget.NewFormItem("", trafficCheck),
+		widget.NewFormItem("", usageCheck),
+	)
+
+	return container.NewBorder(
+		header,
+		container.NewVBox(widget.NewSeparator(), mw.prefsFeedback),
+		nil, nil,
+		container.NewVScroll(container.NewVBox(
+			widget.NewCard(i18n.T("prefs.general"), "", general),
+			widget.NewCard(i18n.T("prefs.new_devices"), i18n.T("prefs.new_devices_subtitle"), deviceDefaults),
+			widget.NewCard(i18n.T("prefs.logging"), "", logSettings),
+			widget.NewCard(i18n.T("prefs.files"), i18n.T("prefs.files_subtitle"), mw.createFilesInfo()),
+			widget.NewCard(i18n.T("prefs.http_api"), i18n.T("prefs.http_api_subtitle"), mw.createHTTPAPISettings()),
+			widget.NewCard(i18n.T("prefs.busy"), i18n.T("prefs.busy_subtitle"), mw.createBusyFeedbackSettings()),
+			widget.NewCard(i18n.T("prefs.danger"), i18n.T("prefs.danger_subtitle"), mw.createDangerPatternSettings()),
+			widget.NewCard(i18n.T("prefs.profile"), i18n.T("prefs.profile_subtitle"), container.NewHBox(
+				widget.NewButtonWithIcon(i18n.T("prefs.profile_export"), theme.DocumentSaveIcon(), mw.exportProfile),
+				widget.NewButtonWithIcon(i18n.T("prefs.profile_import"), theme.FolderOpenIcon(), mw.importProfile),
+			)),
+		)),
+	)
+}
+
+// createHTTPAPISettings builds the HTTP API controls. The toggle starts and stops the
+// server immediately; address, port and token changes take effect with Apply.
+func (mw *MainWindow) createHTTPAPISettings() fyne.CanvasObject {
+	settings := &mw.cfg.HTTPAPI
+
+	addressSelect := widget.NewSelect([]string{"127.0.0.1", "0.0.0.0"}, nil)
+	setSelectedSilently(addressSelect, settings.BindAddress)
+
+	portEntry := widget.NewEntry()
+	portEntry.SetText(strconv.Itoa(settings.Port))
+
+	tokenEntry := widget.NewPasswordEntry()
+	tokenEntry.SetText(settings.Token)
+	generateBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		tokenEntry.SetText(httpapi.NewToken())
+	})
+	copyTokenBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		mw.app.Clipboard().SetContent(tokenEntry.Text)
+	})
+
+	// apply stores the fields into the config and restarts the server to match
+	apply := func(enabled bool) error {
+		port, err := strconv.Atoi(portEntry.Text)
+		if err != nil || port < 1 || port > 65535 {
+			return errors.New(i18n.T("prefs.invalid_port"))
+		}
+		if enabled && tokenEntry.Text == "" {
+			tokenEntry.SetText(httpapi.NewToken())
+		}
+
+		settings.Enabled = enabled
+		settings.BindAddress = addressSelect.Selected
+		settings.Port = port
+		settings.Token = tokenEntry.Text
+		mw.savePreferences()
+
+		if mw.httpAPI == nil {
+			return nil
+		}
+		return mw.httpAPI.Apply(*settings)
+	}
+
+	var enabledCheck *widget.Check
+	enabledCheck = widget.NewCheck(i18n.T("prefs.http_enable"), func(checked bool) {
+		if err := apply(checked); err != nil && checked {
+			// Leave it off rather than showing a server that isn't running
+			settings.Enabled = false
+			mw.savePreferences()
+			enabledCheck.Checked = false
+			enabledCheck.Refresh()
+			dialog.ShowError(err, mw.window)
+		}
+	})
+	enabledCheck.Checked = settings.Enabled
+
+	applyBtn := widget.NewButtonWithIcon(i18n.T("prefs.apply"), theme.ConfirmIcon(), func() {
+		if err := apply(enabledCheck.Checked); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	})
+
+	return widget.NewForm(
+		widget.NewFormItem("", enabledCheck),
+		widget.NewFormItem(i18n.T("prefs.listen_on"), addressSelect),
+		widget.NewFormItem(i18n.T("prefs.port"), portEntry),
+		widget.NewFormItem(i18n.T("prefs.token"), container.NewBorder(nil, nil, nil, container.NewHBox(generateBtn, copyTokenBtn), tokenEntry)),
+		widget.NewFormItem("", container.NewHBox(applyBtn)),
+	)
+}
+
+// createBusyFeedbackSettings builds the toggle and default color for pads showing their action running
+func (mw *MainWindow) createBusyFeedbackSettings() fyne.CanvasObject {
+	settings := &mw.cfg.BusyFeedback
+
+	enabledCheck := widget.NewCheck(i18n.T("prefs.busy_enable"), func(checked bool) {
+		settings.Enabled = checked
+		mw.savePreferences()
+	})
+	enabledCheck.Checked = settings.Enabled
+
+	options := busyColorOptions()
+	colorSelect := widget.NewSelect(options, func(s string) {
+		preset := busyColorPresets[max(slices.Index(options, s), 0)]
+		settings.R, settings.G, settings.B = preset.r, preset.g, preset.b
+		mw.savePreferences()
+	})
+	if i := busyColorIndex(settings.R, settings.G, settings.B); i >= 0 {
+		setSelectedSilently(colorSelect, options[i])
+	} else {
+		colorSelect.PlaceHolder = i18n.T("menu_editor.busy_custom")
+	}
+
+	rejectedCheck := widget.NewCheck(i18n.T("prefs.busy_flash_rejected"), func(checked bool) {
+		settings.FlashRejected = checked
+		mw.savePreferences()
+	})
+	rejectedCheck.Checked = settings.FlashRejected
+
+	return widget.NewForm(
+		widget.NewFormItem("", enabledCheck),
+		widget.NewFormItem(i18n.T("prefs.busy_color"), colorSelect),
+		widget.NewFormItem("", rejectedCheck),
+	)
+}
+
+// createDangerPatternSettings builds the editor for the danger patterns, one regular
+// expression per line, applied with Apply
+func (mw *MainWindow) createDangerPatternSettings() fyne.CanvasObject {
+	patternsEntry := widget.NewMultiLineEntry()
+	patternsEntry.SetMinRowsVisible(5)
+	patternsEntry.TextStyle = fyne.TextStyle{Monospace: true}
+	patternsEntry.SetText(strings.Join(mw.cfg.DangerPatterns, "\n"))
+
+	applyBtn := widget.NewButtonWithIcon(i18n.T("prefs.apply"), theme.ConfirmIcon(), func() {
+		var patterns []string
+		for _, line := range strings.Split(patternsEntry.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				patterns = append(patterns, line)
+			}
+		}
+		if err := actions.ValidateDangerPatterns(patterns); err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		mw.cfg.DangerPatterns = append([]string{}, patterns...) // Empty, not nil, so no patterns stays saved
+		mw.savePreferences()
+	})
+	resetBtn := widget.NewButtonWithIcon(i18n.T("prefs.danger_reset"), theme.ContentUndoIcon(), func() {
+		patternsEntry.SetText(strings.Join(actions.DefaultDangerPatterns, "\n"))
+	})
+
+	return container.NewBorder(nil, container.NewHBox(applyBtn, resetBtn), nil, nil, patternsEntry)
+}
+
+// SetOpenAtStartup registers or unregisters the app as a login item and saves the choice.
+// The preferences toggle and the tray item both go through here so they stay in sync.
+func (mw *MainWindow) SetOpenAtStartup(enabled bool) error {
+	var err error
+	if enabled {
+		err = startup.Enable(mw.cfg.StartupArgs)
+	} else {
+		err = startup.Disable()
+	}
+	if err != nil {
+		enabled = startup.IsEnabled()
+	}
+
+	mw.cfg.OpenAtStartup = enabled
+	mw.cfg.IgnoredStartupCommand = nil
+	if mw.startupCheck != nil && mw.startupCheck.Checked != enabled {
+		mw.startupCheck.Checked = enabled
+		mw.startupCheck.Refresh()
+	}
+	mw.savePreferences()
+	mw.notifyTray()
+	return err
+}
+
+// CheckStartupRegistration offers to update or turn off a login item that no longer
+// launches this copy of the app with the configured arguments, e.g. after the app was
+// moved. A login item the user chose to keep isn't asked about again.
+func (mw *MainWindow) CheckStartupRegistration() {
+	if !mw.cfg.OpenAtStartup {
+		return
+	}
+	registered, err := startup.Verify(mw.cfg.StartupArgs)
+	if err == nil || (registered != nil && slices.Equal(registered, mw.cfg.IgnoredStartupCommand)) {
+		return
+	}
+	slog.Warn("Login item is out of date", "err", err)
+
+	var dlg dialog.Dialog
+	keepBtn := widget.NewButton(i18n.T("prefs.startup_keep"), func() {
+		dlg.Hide()
+		mw.cfg.IgnoredStartupCommand = registered
+		mw.savePreferences()
+	})
+	disableBtn := widget.NewButton(i18n.T("prefs.startup_turn_off"), func() {
+		dlg.Hide()
+		if err := mw.SetOpenAtStartup(false); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	})
+	updateBtn := widget.NewButton(i18n.T("prefs.startup_update"), func() {
+		dlg.Hide()
+		if err := mw.SetOpenAtStartup(true); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	})
+	updateBtn.Importance = widget.HighImportance
+
+	message := widget.NewLabel(i18n.T("prefs.repair_startup", err))
+	message.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(message, container.NewHBox(layout.NewSpacer(), keepBtn, disableBtn, updateBtn))
+	dlg = dialog.NewCustomWithoutButtons(i18n.T("common.open_at_startup"), content, mw.window)
+	dlg.Resize(fyne.NewSize(460, 0))
+	dlg.Show()
+}
+
+// unsavedWarningSuppressed records that the unsaved-changes warning was turned off
+func (mw *MainWindow) unsavedWarningSuppressed() {
+	mw.cfg.SuppressUnsavedWarning = true
+	if mw.resetWarningBtn != nil {
+		mw.resetWarningBtn.Enable()
+	}
+	mw.savePreferences()
+}
+
+// padDebounceChoices are the pad debounce windows offered, in milliseconds
+var padDebounceChoices = []int{0, 10, 30, 50, 100, 200}
+
+// Startup delays and retry times offered in preferences, in seconds
+var (
+	startupDelayChoices = []int{0, 5, 10, 20, 30}
+	startupRetryChoices = []int{0, 30, 60, 120, 300}
+)
+
+// secondsLabel describes a startup delay or retry time of s seconds
+func secondsLabel(s int) string {
+	if s <= 0 {
+		return i18n.T("prefs.startup_none")
+	}
+	return i18n.T("prefs.startup_seconds", s)
+}
+
+// debounceLabel describes a debounce window of ms milliseconds
+func debounceLabel(ms int) string {
+	if ms <= 0 {
+		return i18n.T("prefs.pad_debounce_off")
+	}
+	return i18n.T("prefs.pad_debounce_option", ms)
+}
+
+// setVirtualOutPort creates or removes the virtual output port and lists the change
+// wherever ports are offered
+func (mw *MainWindow) setVirtualOutPort(enabled bool) {
+	mw.cfg.VirtualOutPort = enabled
+	if enabled {
+		if err := mw.midiManager.OpenVirtualOut(midi.VirtualOutName); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	} else {
+		mw.midiManager.CloseVirtualOut()
+	}
+	mw.refreshPorts()
+	mw.savePreferences()
+}
+
+// refreshAppHookPickers shows the app's startup and shutdown actions, offering what
+// pads can be assigned
+func (mw *MainWindow) refreshAppHookPickers() {
+	if mw.startupActionPicker == nil {
+		return
+	}
+	choices := mw.actionChoices()
+	for _, hook := range []struct {
+		picker *actionPicker
+		id     string
+	}{
+		{mw.startupActionPicker, mw.cfg.StartupActionID},
+		{mw.shutdownActionPicker, mw.cfg.ShutdownActionID},
+	} {
+		hook.picker.SetChoices(choices)
+		hook.picker.SetSelected(hook.id)
+	}
+}
+
+// savePreferences stores the app-level settings without saving other unsaved edits
+func (mw *MainWindow) savePreferences() {
+	err := config.Update(func(saved *config.Config) {
+		saved.OpenAtStartup = mw.cfg.OpenAtStartup
+		saved.StartupArgs = mw.cfg.StartupArgs
+		saved.SuppressUnsavedWarning = mw.cfg.SuppressUnsavedWarning
+		saved.IgnoredStartupCommand = mw.cfg.IgnoredStartupCommand
+		saved.Language = mw.cfg.Language
+		saved.LogLevel = mw.cfg.LogLevel
+		saved.LogMIDITraffic = mw.cfg.LogMIDITraffic
+		saved.DisableUsageStats = mw.cfg.DisableUsageStats
+		saved.VirtualOutPort = mw.cfg.VirtualOutPort
+		saved.CodeEditorRows = mw.cfg.CodeEditorRows
+		saved.CodeEditorWrap = mw.cfg.CodeEditorWrap
+		saved.PadSize = mw.cfg.PadSize
+		saved.CompactColorPanel = mw.cfg.CompactColorPanel
+		saved.PadColorCodes = mw.cfg.PadColorCodes
+		saved.PatternFills = mw.cfg.PatternFills
+		saved.MinHitTarget = mw.cfg.MinHitTarget
+		saved.PadDebounceMs = mw.cfg.PadDebounceMs
+		saved.DeviceDefaults = mw.cfg.DeviceDefaults
+		saved.HTTPAPI = mw.cfg.HTTPAPI
+		saved.DangerPatterns = mw.cfg.DangerPatterns
+		saved.BusyFeedback = mw.cfg.BusyFeedback
+		saved.StartupInit = mw.cfg.StartupInit
+		saved.StartupActionID = mw.cfg.StartupActionID
+		saved.ShutdownActionID = mw.cfg.ShutdownActionID
+	})
+	if err != nil {
+		mw.prefsFeedback.Importance = widget.DangerImportance
+		mw.prefsFeedback.SetText(i18n.T("prefs.save_failed", err))
+		return
+	}
+	mw.prefsFeedback.Importance = widget.SuccessImportance
+	mw.prefsFeedback.SetText(i18n.T("prefs.saved"))
+	mw.refreshFilesInfo()
+}
@@ -0,0 +1,195 @@
+package window
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/logging"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/profile"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
+)
+
+// exportProfile saves the config as last saved to a profile archive picked by the user
+func (mw *MainWindow) exportProfile() {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+
+		// Unsaved edits aren't part of the profile, as with preferences
+		savedCfg, err := config.Load()
+		if err == nil {
+			err = profile.Export(writer, savedCfg)
+		}
+		if err != nil {
+			mw.prefsFeedback.Importance = widget.DangerImportance
+			mw.prefsFeedback.SetText(i18n.T("prefs.profile_export_failed", err))
+			return
+		}
+		mw.prefsFeedback.Importance = widget.SuccessImportance
+		mw.prefsFeedback.SetText(i18n.T("prefs.profile_exported", writer.URI().Name()))
+	}, mw.window)
+	save.SetFileName("gopher-automate-profile-" + time.Now().Format("2006-01-02") + profile.FileExtension)
+	save.SetFilter(storage.NewExtensionFileFilter([]string{profile.FileExtension}))
+	save.Show()
+}
+
+// importProfile reads a profile archive and, once confirmed and its ports are mapped to
+// this machine's, replaces the whole configuration with it
+func (mw *MainWindow) importProfile() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(err, mw.window)
+			return
+		}
+		imported, manifest, err := profile.Import(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", reader.URI().Name(), err), mw.window)
+			return
+		}
+
+		summary := i18n.T("prefs.profile_import_summary", manifest.ExportedAt.Local().Format("2006-01-02 15:04"), manifest.AppVersion,
+			len(imported.Devices), len(imported.Menus), len(imported.Actions), len(imported.MessageMappings))
+		dialog.ShowConfirm(i18n.T("prefs.profile_import_title"), summary, func(confirm bool) {
+			if !confirm {
+				return
+			}
+			if missing := profile.MissingPorts(imported, mw.inPorts, mw.outPorts); len(missing) > 0 {
+				mw.remapProfilePorts(imported, missing)
+				return
+			}
+			mw.applyProfile(imported)
+		}, mw.window)
+	}, mw.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{profile.FileExtension}))
+	open.Show()
+}
+
+// remapProfilePorts asks which of this machine's ports replace the ones an imported
+// profile names but that aren't present, then applies the profile
+func (mw *MainWindow) remapProfilePorts(imported *config.Config, missing []profile.MissingPort) {
+	keep := func(port string) string { return i18n.T("prefs.profile_keep_port", port) }
+
+	form := widget.NewForm()
+	selects := make([]*widget.Select, len(missing))
+	for i, port := range missing {
+		available, direction := mw.outPorts, i18n.T("prefs.profile_output")
+		if port.Input {
+			available, direction = mw.inPorts, i18n.T("prefs.profile_input")
+		}
+		selects[i] = widget.NewSelect(append([]string{keep(port.Port)}, available...), nil)
+		selects[i].SetSelected(keep(port.Port))
+		if resolved, err := config.ResolvePort(port.Port, port.Pattern, available); err == nil && resolved != port.Port {
+			selects[i].SetSelected(resolved) // The one port the device's pattern matches
+		} else if candidates := midi.FindPorts(port.Port, available); len(candidates) > 0 {
+			selects[i].SetSelected(candidates[0]) // Likely the same device under a slightly different name
+		}
+		form.Append(fmt.Sprintf("%s (%s)", port.DeviceName, direction), selects[i])
+	}
+
+	content := container.NewVBox(widget.NewLabel(i18n.T("prefs.profile_remap_hint")), form)
+	dialog.ShowCustomConfirm(i18n.T("prefs.profile_remap_title"), i18n.T("prefs.profile_import"), i18n.T("common.cancel"), content, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		for i, port := range missing {
+			chosen := selects[i].Selected
+			device := imported.GetDevice(port.DeviceID)
+			if device == nil || chosen == "" || chosen == keep(port.Port) {
+				continue
+			}
+			if port.Input {
+				device.InPort = chosen
+			} else {
+				device.OutPort = chosen
+			}
+		}
+		mw.applyProfile(imported)
+	}, mw.window)
+}
+
+// applyProfile replaces the running configuration with an imported one and saves it.
+// Machine-local settings stay as they are, see profile.KeepLocal.
+func (mw *MainWindow) applyProfile(imported *config.Config) {
+	mw.confirmDiscardActions(func() { mw.doApplyProfile(imported) })
+}
+
+// doApplyProfile applies an imported profile once unsaved action edits may be dropped
+func (mw *MainWindow) doApplyProfile(imported *config.Config) {
+	profile.KeepLocal(imported, mw.cfg)
+	imported.FirstLaunchCompleted = true
+
+	mw.replaceConfig(imported)
+	if err := mw.cfg.Save(); err != nil {
+		dialog.ShowError(err, mw.window)
+	}
+	mw.prefsFeedback.Importance = widget.SuccessImportance
+	mw.prefsFeedback.SetText(i18n.T("prefs.profile_imported"))
+}
+
+// replaceConfig swaps the running configuration for another one and brings devices,
+// the tray and every tab up to date. Unsaved edits are dropped.
+func (mw *MainWindow) replaceConfig(replacement *config.Config) {
+	mw.cfg.Replace(replacement)
+	mw.actionStore.Replace(mw.cfg.Actions, mw.cfg.ActionGroups) // The engine holds the store, so update it in place
+
+	logging.SetLevel(mw.cfg.LogLevel)
+	midi.SetTraceTraffic(mw.cfg.LogMIDITraffic)
+	usage.SetEnabled(!mw.cfg.DisableUsageStats)
+	if mw.cfg.VirtualOutPort && midi.VirtualPortsSupported() {
+		if err := mw.midiManager.OpenVirtualOut(midi.VirtualOutName); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	} else {
+		mw.midiManager.CloseVirtualOut()
+	}
+	mw.refreshPorts()
+	if mw.httpAPI != nil {
+		if err := mw.httpAPI.Apply(mw.cfg.HTTPAPI); err != nil {
+			dialog.ShowError(err, mw.window)
+		}
+	}
+	mw.InitializeDevices()
+
+	mw.setDirty(false)
+	mw.deviceList.Refresh()
+	mw.publishDeviceStatus()
+	mw.refreshLayoutDropdown()
+	mw.refreshGrid()
+	mw.selectedAction, mw.selectedGroup = nil, nil
+	mw.actionList.UnselectAll()
+	mw.actionList.Refresh()
+	mw.updateActionEditor()
+	mw.mappingList.Refresh()
+	mw.refreshPadActionOptions()
+	mw.notifyTray()
+
+	// Preferences are rebuilt so every control shows the new values
+	mw.preferencesTab.Content = mw.createPreferencesTab()
+	mw.tabs.Refresh()
+}
@@ -0,0 +1,44 @@
+package window
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+)
+
+// TestApplyProfileKeepsLocalSettings checks that an imported profile replaces the
+// layouts but not this machine's HTTP API and login item, in memory and on disk
+func TestApplyProfileKeepsLocalSettings(t *testing.T) {
+	mw := newTestWindow(t, "Local")
+	local := config.HTTPAPIConfig{BindAddress: "127.0.0.1", Port: 8123, Token: "local-token"}
+	mw.cfg.HTTPAPI = local
+	mw.cfg.StartupArgs = []string{"--headless"}
+
+	imported, err := config.Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	menu := config.NewMenuLayout()
+	menu.Name = "Imported"
+	imported.Menus = []config.MenuLayout{menu}
+	imported.CurrentMenuID = menu.ID
+	imported.HTTPAPI = config.HTTPAPIConfig{Enabled: true, BindAddress: "0.0.0.0", Port: 9000, Token: "other-token"}
+
+	mw.doApplyProfile(imported)
+
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, cfg := range map[string]*config.Config{"running": mw.cfg, "saved": saved} {
+		if cfg.HTTPAPI != local {
+			t.Errorf("%s HTTP API = %+v, want this machine's %+v", name, cfg.HTTPAPI, local)
+		}
+		if len(cfg.StartupArgs) != 1 || cfg.StartupArgs[0] != "--headless" {
+			t.Errorf("%s startup args = %v, want this machine's", name, cfg.StartupArgs)
+		}
+		if got := cfg.GetCurrentMenu(); got == nil || got.Name != "Imported" {
+			t.Errorf("%s current menu = %+v, want the imported one", name, got)
+		}
+	}
+}
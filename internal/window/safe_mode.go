@@ -0,0 +1,33 @@
+package window
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// createSafeModeBanner builds the banner shown above the tabs while devices are left
+// uninitialized after a crash
+func (mw *MainWindow) createSafeModeBanner() fyne.CanvasObject {
+	message := widget.NewLabel(i18n.T("safe_mode.banner"))
+	message.Wrapping = fyne.TextWrapWord
+	message.Importance = widget.WarningImportance
+
+	retryBtn := widget.NewButtonWithIcon(i18n.T("safe_mode.retry"), theme.ViewRefreshIcon(), func() {
+		mw.InitializeDevices()
+	})
+	retryBtn.Importance = widget.HighImportance
+
+	mw.safeModeBanner = container.NewVBox(container.NewBorder(nil, nil, widget.NewIcon(theme.WarningIcon()), retryBtn, message), widget.NewSeparator())
+	mw.safeModeBanner.Hide()
+	return mw.safeModeBanner
+}
+
+// EnterSafeMode shows the window with the safe mode banner instead of initializing devices,
+// so device settings can be fixed before trying again
+func (mw *MainWindow) EnterSafeMode() {
+	mw.safeModeBanner.Show()
+	mw.Show()
+}
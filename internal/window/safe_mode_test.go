@@ -0,0 +1,38 @@
+package window
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// TestSafeModeRetry checks the banner flow: shown in safe mode, gone once a retry
+// initializes the devices, which also clears the crash mark
+func TestSafeModeRetry(t *testing.T) {
+	mw := newTestWindow(t)
+	if mw.safeModeBanner.Visible() {
+		t.Fatal("safe mode banner shown on a normal start")
+	}
+	if err := config.BeginDeviceInit(); err != nil { // As left by a crash
+		t.Fatal(err)
+	}
+
+	mw.EnterSafeMode()
+	if !mw.safeModeBanner.Visible() {
+		t.Fatal("safe mode banner not shown")
+	}
+
+	retry := findButton(mw.safeModeBanner, i18n.T("safe_mode.retry"))
+	if retry == nil {
+		t.Fatal("no retry button on the banner")
+	}
+	test.Tap(retry)
+	if mw.safeModeBanner.Visible() {
+		t.Error("banner still shown after initializing")
+	}
+	if config.DeviceInitInterrupted() {
+		t.Error("crash mark left after initializing")
+	}
+}
@@ -0,0 +1,231 @@
+package window
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// ============ SCENES ============
+
+// sceneOptionPrefix marks scenes among the actions in action dropdowns
+const sceneOptionPrefix = "🎬 "
+
+// sceneOption returns a scene's entry in action dropdowns
+func sceneOption(name string) string {
+	return sceneOptionPrefix + name
+}
+
+// sceneBrightnessChoices are the brightnesses a scene can set; 0 keeps the device's own
+var sceneBrightnessChoices = []int{0, 10, 25, 50, 75, 100}
+
+// showScenes opens the list of scenes with run/edit/remove controls
+func (mw *MainWindow) showScenes() {
+	var sceneList *widget.List
+	changed := func() {
+		sceneList.Refresh()
+		mw.refreshPadActionOptions()
+		mw.updatePadActionSelection()
+		if mw.mappingList != nil {
+			mw.mappingList.Refresh()
+		}
+		mw.notifyTray()
+	}
+
+	sceneList = widget.NewList(
+		func() int { return len(mw.cfg.Scenes) },
+		func() fyne.CanvasObject {
+			nameLabel := widget.NewLabel(i18n.T("common.name"))
+			nameLabel.Truncation = fyne.TextTruncateEllipsis
+			summaryLabel := widget.NewLabel("")
+			summaryLabel.Truncation = fyne.TextTruncateEllipsis
+			runBtn := widget.NewButtonWithIcon("", theme.MediaPlayIcon(), nil)
+			editBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil)
+			removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(runBtn, editBtn, removeBtn),
+				container.NewGridWithColumns(2, nameLabel, summaryLabel),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(mw.cfg.Scenes) {
+				return
+			}
+			scene := mw.cfg.Scenes[id]
+			row := obj.(*fyne.Container)
+			labels := row.Objects[0].(*fyne.Container)
+			buttons := row.Objects[1].(*fyne.Container)
+
+			labels.Objects[0].(*widget.Label).SetText(scene.Name)
+			labels.Objects[1].(*widget.Label).SetText(mw.sceneSummary(scene))
+
+			sceneID := scene.ID
+			buttons.Objects[0].(*widget.Button).OnTapped = func() { mw.engine.Run(sceneID) }
+			buttons.Objects[1].(*widget.Button).OnTapped = func() {
+				if s := mw.cfg.GetScene(sceneID); s != nil {
+					mw.showSceneEditor(i18n.T("scenes.edit"), *s, changed)
+				}
+			}
+			buttons.Objects[2].(*widget.Button).OnTapped = func() {
+				dialog.ShowConfirm(i18n.T("scenes.delete_title"), i18n.T("common.confirm_delete", scene.Name), func(confirm bool) {
+					if confirm {
+						mw.cfg.RemoveScene(sceneID)
+						changed()
+					}
+				}, mw.window)
+			}
+		},
+	)
+
+	addBtn := widget.NewButtonWithIcon(i18n.T("scenes.add"), theme.ContentAddIcon(), func() {
+		mw.showSceneEditor(i18n.T("scenes.add"), config.NewScene(i18n.T("scenes.new_name")), changed)
+	})
+
+	hint := widget.NewLabel(i18n.T("scenes.hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewBorder(hint, container.NewHBox(addBtn), nil, nil, sceneList)
+	dlg := dialog.NewCustom(i18n.T("scenes.title"), i18n.T("common.close"), content, mw.window)
+	dlg.Resize(fyne.NewSize(560, 400))
+	dlg.Show()
+}
+
+// sceneSummary describes what a scene does in a few words
+func (mw *MainWindow) sceneSummary(scene config.Scene) string {
+	parts := []string{i18n.T("scenes.device_count", len(scene.Devices))}
+	if action := mw.actionOrGroupName(scene.ActionID); action != "" {
+		parts = append(parts, action)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// actionOrGroupName returns the name of an action or group, or "" if there is none
+func (mw *MainWindow) actionOrGroupName(id string) string {
+	if action := mw.actionStore.GetAction(id); action != nil {
+		return action.Name
+	}
+	if group := mw.actionStore.GetGroup(id); group != nil {
+		return group.Name
+	}
+	return ""
+}
+
+// showSceneEditor edits a working copy of a scene; onDone is called after it is stored
+func (mw *MainWindow) showSceneEditor(title string, scene config.Scene, onDone func()) {
+	working := scene
+	working.Devices = slices.Clone(scene.Devices)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(working.Name)
+	nameEntry.OnChanged = func(s string) { working.Name = s }
+
+	// Devices and groups, by name
+	targetOptions, targetIDs := []string{}, []string{}
+	for _, d := range mw.cfg.Devices {
+		if d.Type != config.DeviceTypeGeneric {
+			targetOptions, targetIDs = append(targetOptions, d.Name), append(targetIDs, d.ID)
+		}
+	}
+	for _, g := range mw.cfg.DeviceGroups {
+		targetOptions, targetIDs = append(targetOptions, "▦ "+g.Name), append(targetIDs, g.ID)
+	}
+	menuOptions, menuIDs := []string{i18n.T("scenes.keep")}, []string{""}
+	for _, m := range mw.cfg.Menus {
+		menuOptions, menuIDs = append(menuOptions, m.Name), append(menuIDs, m.ID)
+	}
+	brightnessOptions := []string{i18n.T("scenes.keep")}
+	for _, b := range sceneBrightnessChoices[1:] {
+		brightnessOptions = append(brightnessOptions, strconv.Itoa(b)+"%")
+	}
+
+	// One row per device; rows are rebuilt when one is added or removed
+	rows := container.NewVBox()
+	var rebuild func()
+	rebuild = func() {
+		rows.Objects = nil
+		for i := range working.Devices {
+			state := &working.Devices[i]
+			targetSelect := widget.NewSelect(targetOptions, func(s string) {
+				state.TargetID = targetIDs[max(slices.Index(targetOptions, s), 0)]
+			})
+			targetSelect.PlaceHolder = i18n.T("scenes.device")
+			if j := slices.Index(targetIDs, state.TargetID); j >= 0 {
+				setSelectedSilently(targetSelect, targetOptions[j])
+			}
+			menuSelect := widget.NewSelect(menuOptions, func(s string) {
+				state.MenuID = menuIDs[max(slices.Index(menuOptions, s), 0)]
+			})
+			setSelectedSilently(menuSelect, menuOptions[max(slices.Index(menuIDs, state.MenuID), 0)])
+			brightnessSelect := widget.NewSelect(brightnessOptions, func(s string) {
+				state.Brightness = sceneBrightnessChoices[max(slices.Index(brightnessOptions, s), 0)]
+			})
+			setSelectedSilently(brightnessSelect, brightnessOptions[max(slices.Index(sceneBrightnessChoices, state.Brightness), 0)])
+			removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				working.Devices = slices.Delete(working.Devices, i, i+1)
+				rebuild()
+			})
+			rows.Add(container.NewBorder(nil, nil, nil, removeBtn,
+				container.NewGridWithColumns(3, targetSelect, menuSelect, brightnessSelect)))
+		}
+		if len(working.Devices) == 0 {
+			rows.Add(widget.NewLabel(i18n.T("scenes.no_devices")))
+		}
+		rows.Refresh()
+	}
+	rebuild()
+	addDeviceBtn := widget.NewButtonWithIcon(i18n.T("scenes.add_device"), theme.ContentAddIcon(), func() {
+		working.Devices = append(working.Devices, config.SceneDevice{})
+		rebuild()
+	})
+	if len(targetOptions) == 0 {
+		addDeviceBtn.Disable()
+	}
+
+	actionSelect := mw.newActionOrGroupSelect(working.ActionID, func(id string) { working.ActionID = id })
+
+	form := widget.NewForm(
+		widget.NewFormItem(i18n.T("common.name"), nameEntry),
+		widget.NewFormItem(i18n.T("common.devices"), container.NewVBox(rows, container.NewHBox(addDeviceBtn))),
+		widget.NewFormItem(i18n.T("scenes.then_run"), actionSelect),
+	)
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Hide()
+
+	var dlg dialog.Dialog
+	cancelBtn := widget.NewButtonWithIcon(i18n.T("common.cancel"), theme.CancelIcon(), func() { dlg.Hide() })
+	okBtn := widget.NewButtonWithIcon(i18n.T("common.ok"), theme.ConfirmIcon(), func() {
+		working.Name = strings.TrimSpace(working.Name)
+		if working.Name == "" {
+			errorLabel.SetText(i18n.T("scenes.name_required"))
+			errorLabel.Show()
+			return
+		}
+		// Rows left without a device do nothing
+		working.Devices = slices.DeleteFunc(working.Devices, func(d config.SceneDevice) bool { return d.TargetID == "" })
+		dlg.Hide()
+		mw.cfg.UpdateScene(working)
+		onDone()
+	})
+	okBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		form,
+		errorLabel,
+		container.NewHBox(layout.NewSpacer(), cancelBtn, okBtn),
+	)
+
+	dlg = dialog.NewCustomWithoutButtons(title, content, mw.window)
+	dlg.Resize(fyne.NewSize(560, 0))
+	dlg.Show()
+}
@@ -0,0 +1,155 @@
+package window
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// sentPreviewTarget is a device type and brightness some configured device is lit with
+type sentPreviewTarget struct {
+	deviceType config.DeviceType
+	brightness int
+}
+
+// sentPreview shows, for each kind of configured device, what the color panel's static
+// and pressed colors look like once sent: the color each device type is sent, scaled
+// to the brightness and converted the way the device converts it. A note says when a
+// device drops the selected pad or approximates its color, as its compatibility check does.
+type sentPreview struct {
+	box     *fyne.Container
+	targets []sentPreviewTarget
+	static  []*canvas.Rectangle
+	pressed []*canvas.Rectangle
+	notes   []*widget.Label
+}
+
+func newSentPreview() *sentPreview {
+	return &sentPreview{box: container.NewVBox()}
+}
+
+// sentPreviewTargets lists the device types and brightnesses of the enabled devices
+// with lights, in a stable order
+func sentPreviewTargets(devices []config.DeviceConfig) []sentPreviewTarget {
+	var targets []sentPreviewTarget
+	for _, device := range devices {
+		if device.Disabled || device.Type == config.DeviceTypeGeneric {
+			continue
+		}
+		t := sentPreviewTarget{device.Type, min(max(device.Brightness, 1), 100)}
+		if !slices.Contains(targets, t) {
+			targets = append(targets, t)
+		}
+	}
+	slices.SortFunc(targets, func(a, b sentPreviewTarget) int {
+		return cmp.Or(cmp.Compare(a.deviceType, b.deviceType), b.brightness-a.brightness)
+	})
+	return targets
+}
+
+// sentPreviewLabel names a target with how its device type converts colors
+func sentPreviewLabel(t sentPreviewTarget) string {
+	var label string
+	switch t.deviceType {
+	case config.DeviceTypeClassic:
+		label = i18n.T("menu_editor.sent_preview.classic", deviceTypeLabel(t.deviceType))
+	case config.DeviceTypeSimulated:
+		label = deviceTypeLabel(t.deviceType)
+	default:
+		label = i18n.T("menu_editor.sent_preview.colorful", deviceTypeLabel(t.deviceType))
+	}
+	if t.brightness < 100 {
+		label = fmt.Sprintf("%s, %d%%", label, t.brightness)
+	}
+	return label
+}
+
+// Update shows the colors of the pad at row, col for the devices configured now,
+// rebuilding the rows when the kinds of devices changed
+func (p *sentPreview) Update(devices []config.DeviceConfig, row, col int, pad config.PadColorConfig) {
+	if targets := sentPreviewTargets(devices); !slices.Equal(targets, p.targets) {
+		p.rebuild(targets)
+	}
+	for i, t := range p.targets {
+		r, g, b := pad.StaticFor(t.deviceType)
+		p.fill(p.static[i], t, midi.PadColor{R: r, G: g, B: b})
+		r, g, b = pad.PressedFor(t.deviceType)
+		p.fill(p.pressed[i], t, midi.PadColor{R: r, G: g, B: b})
+
+		switch targetFit(devices, t, pad, row, col) {
+		case midi.FitDropped:
+			p.notes[i].SetText(i18n.T("menu_editor.sent_preview.dropped"))
+			p.notes[i].Show()
+		case midi.FitApproximate:
+			p.notes[i].SetText(i18n.T("menu_editor.sent_preview.approximated"))
+			p.notes[i].Show()
+		default:
+			p.notes[i].Hide()
+		}
+	}
+}
+
+// targetFit is the worst fit of the pad on the devices of a target; devices turned
+// differently can disagree about a pad
+func targetFit(devices []config.DeviceConfig, t sentPreviewTarget, pad config.PadColorConfig, row, col int) midi.Fit {
+	fit := midi.FitExact
+	for _, device := range devices {
+		if device.Disabled || device.Type != t.deviceType || min(max(device.Brightness, 1), 100) != t.brightness {
+			continue
+		}
+		fit = max(fit, engine.PadFit(device, pad, row, col, t.brightness))
+	}
+	return fit
+}
+
+// fill colors a swatch as a device of the target's kind would show c
+func (p *sentPreview) fill(rect *canvas.Rectangle, t sentPreviewTarget, c midi.PadColor) {
+	shown, ok := midi.Preview(midi.DeviceType(t.deviceType), c.Scaled(t.brightness))
+	if !ok || shown == padcolor.RGBA(0, 0, 0) {
+		rect.FillColor = virtualPadColor(midi.PadColor{}) // Unlit, drawn so the swatch stays visible
+	} else {
+		rect.FillColor = shown
+	}
+	rect.Refresh()
+}
+
+func (p *sentPreview) rebuild(targets []sentPreviewTarget) {
+	p.targets = targets
+	p.static = make([]*canvas.Rectangle, len(targets))
+	p.pressed = make([]*canvas.Rectangle, len(targets))
+	p.notes = make([]*widget.Label, len(targets))
+	p.box.Objects = nil
+	if len(targets) > 0 {
+		title := widget.NewLabel(i18n.T("menu_editor.sent_preview.title"))
+		title.TextStyle = fyne.TextStyle{Bold: true}
+		p.box.Add(title)
+	}
+	for i, t := range targets {
+		p.static[i] = newSwatch()
+		p.pressed[i] = newSwatch()
+		p.notes[i] = widget.NewLabel("")
+		p.notes[i].Importance = widget.WarningImportance
+		p.notes[i].Hide()
+		p.box.Add(container.NewBorder(nil, nil, nil, container.NewHBox(p.static[i], p.pressed[i]), widget.NewLabel(sentPreviewLabel(t))))
+		p.box.Add(p.notes[i])
+	}
+	p.box.Refresh()
+}
+
+// newSwatch is a small color sample, as the color panel's previews are drawn
+func newSwatch() *canvas.Rectangle {
+	rect := canvas.NewRectangle(padcolor.RGBA(0, 0, 0))
+	rect.SetMinSize(fyne.NewSize(30, 15))
+	rect.CornerRadius = 3
+	return rect
+}
@@ -1,26 +1,117 @@
 package window
 
 import (
+	"sort"
 	"strings"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/PixPMusic/gopher-automate/internal/actions"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
 )
 
 // SyntaxHighlighter provides basic syntax highlighting for code
-type SyntaxHighlighter struct{}
+type SyntaxHighlighter struct {
+	appleScript *syntax
+	shell       *syntax
+}
+
+// syntax describes the tokens highlighted for one scripting language
+type syntax struct {
+	keywords      []string // Longest first, so multi-word keywords win over their first word
+	caseSensitive bool
+	commentPrefix string
+	quotes        string // Characters that open a string literal
+	escape        byte   // Escapes the next character inside double-quoted strings
+	variables     bool   // Highlight $NAME, ${NAME} and $1-style parameters
+}
+
+// tokenKind is the kind of a highlighted run of text
+type tokenKind int
+
+const (
+	tokenPlain tokenKind = iota
+	tokenKeyword
+	tokenString
+	tokenComment
+	tokenNumber
+	tokenVariable
+)
+
+// NewSyntaxHighlighter creates a new syntax highlighter for shell commands run by shellName
+func NewSyntaxHighlighter(shellName string) *SyntaxHighlighter {
+	h := &SyntaxHighlighter{
+		appleScript: newSyntax(syntax{
+			keywords: []string{
+				"tell", "end tell", "if", "then", "else", "end if",
+				"repeat", "end repeat", "set", "to", "get", "return",
+				"on", "end", "try", "on error", "end try",
+				"true", "false", "application", "property", "handler",
+				"with timeout", "giving up after", "as", "of", "the", "a",
+				"do shell script", "display dialog", "display notification",
+			},
+			commentPrefix: "--",
+			quotes:        `"`,
+			escape:        '\\',
+		}),
+		shell: newSyntax(syntax{
+			keywords: []string{
+				"if", "then", "else", "elif", "fi", "for", "do", "done",
+				"while", "until", "case", "esac", "function",
+				"return", "exit", "break", "continue",
+				"export", "local", "readonly", "declare",
+				"echo", "read", "source", "eval",
+				"true", "false", "in",
+			},
+			caseSensitive: true,
+			commentPrefix: "#",
+			quotes:        `"'`,
+			escape:        '\\',
+			variables:     true,
+		}),
+	}
+	if shellName == "PowerShell" {
+		h.shell = powerShellSyntax()
+	}
+	return h
+}
 
-// NewSyntaxHighlighter creates a new syntax highlighter
-func NewSyntaxHighlighter() *SyntaxHighlighter {
-	return &SyntaxHighlighter{}
+// powerShellSyntax highlights PowerShell keywords and common cmdlets
+func powerShellSyntax() *syntax {
+	return newSyntax(syntax{
+		keywords: []string{
+			"if", "elseif", "else", "switch", "foreach", "for", "in", "while", "do", "until",
+			"break", "continue", "return", "exit", "throw", "try", "catch", "finally", "trap",
+			"function", "filter", "param", "begin", "process", "end", "class", "enum",
+			"using",
+			"Get-ChildItem", "Get-Content", "Set-Content", "Add-Content", "Get-Item", "Set-Location",
+			"Get-Process", "Start-Process", "Stop-Process", "Get-Service", "Start-Service", "Stop-Service",
+			"Write-Host", "Write-Output", "Write-Error", "Read-Host", "Start-Sleep",
+			"New-Item", "Remove-Item", "Copy-Item", "Move-Item", "Test-Path", "Join-Path",
+			"Invoke-WebRequest", "Invoke-RestMethod", "Invoke-Expression", "New-Object",
+			"Where-Object", "ForEach-Object", "Select-Object", "Sort-Object", "Out-File", "Out-Null",
+		},
+		commentPrefix: "#",
+		quotes:        `"'`,
+		escape:        '`',
+		variables:     true,
+	})
+}
+
+// newSyntax orders a syntax's keywords longest first
+func newSyntax(s syntax) *syntax {
+	sort.SliceStable(s.keywords, func(i, j int) bool {
+		return len(s.keywords[i]) > len(s.keywords[j])
+	})
+	return &s
 }
 
 // HighlightCode returns a RichText widget with syntax-highlighted code
 func (h *SyntaxHighlighter) HighlightCode(code string, actionType actions.ActionType) *widget.RichText {
 	if code == "" {
 		return widget.NewRichText(&widget.TextSegment{
-			Text:  "(no code)",
+			Text:  i18n.T("common.no_code"),
 			Style: widget.RichTextStyle{Inline: true, TextStyle: fyne.TextStyle{Italic: true}},
 		})
 	}
@@ -29,9 +120,9 @@ func (h *SyntaxHighlighter) HighlightCode(code string, actionType actions.Action
 
 	switch actionType {
 	case actions.ActionTypeAppleScript:
-		segments = h.highlightAppleScript(code)
+		segments = h.appleScript.highlight(code)
 	case actions.ActionTypeShellCommand:
-		segments = h.highlightShell(code)
+		segments = h.shell.highlight(code)
 	default:
 		segments = []widget.RichTextSegment{
 			&widget.TextSegment{Text: code},
@@ -41,77 +132,28 @@ func (h *SyntaxHighlighter) HighlightCode(code string, actionType actions.Action
 	return widget.NewRichText(segments...)
 }
 
-// highlightAppleScript applies basic AppleScript syntax highlighting
-func (h *SyntaxHighlighter) highlightAppleScript(code string) []widget.RichTextSegment {
-	// AppleScript keywords
-	keywords := []string{
-		"tell", "end tell", "if", "then", "else", "end if",
-		"repeat", "end repeat", "set", "to", "get", "return",
-		"on", "end", "try", "on error", "end try",
-		"true", "false", "application", "property", "handler",
-		"with timeout", "giving up after", "as", "of", "the", "a",
-		"do shell script", "display dialog", "display notification",
-	}
-
-	return h.highlightWithKeywords(code, keywords, "--")
-}
-
-// highlightShell applies basic shell syntax highlighting
-func (h *SyntaxHighlighter) highlightShell(code string) []widget.RichTextSegment {
-	// Shell keywords
-	keywords := []string{
-		"if", "then", "else", "elif", "fi", "for", "do", "done",
-		"while", "until", "case", "esac", "function",
-		"return", "exit", "break", "continue",
-		"export", "local", "readonly", "declare",
-		"echo", "read", "source", "eval",
-		"true", "false", "in",
-	}
-
-	return h.highlightWithKeywords(code, keywords, "#")
-}
-
-// highlightWithKeywords applies highlighting for keywords, strings, comments
-func (h *SyntaxHighlighter) highlightWithKeywords(code string, keywords []string, commentPrefix string) []widget.RichTextSegment {
+// highlight splits code into styled segments, one per run of same-kind text
+func (s *syntax) highlight(code string) []widget.RichTextSegment {
 	var segments []widget.RichTextSegment
-
-	// Process line by line to handle comments properly
 	lines := strings.Split(code, "\n")
 
 	for lineIdx, line := range lines {
-		// Check for comment
-		commentStart := strings.Index(line, commentPrefix)
-		codePart := line
-		commentPart := ""
-
-		if commentStart >= 0 {
-			// Check if it's inside a string (simple heuristic)
-			beforeComment := line[:commentStart]
-			singleQuotes := strings.Count(beforeComment, "'") - strings.Count(beforeComment, "\\'")
-			doubleQuotes := strings.Count(beforeComment, "\"") - strings.Count(beforeComment, "\\\"")
-
-			if singleQuotes%2 == 0 && doubleQuotes%2 == 0 {
-				codePart = line[:commentStart]
-				commentPart = line[commentStart:]
+		var last tokenKind
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				segments = append(segments, &widget.TextSegment{Text: run.String(), Style: tokenStyle(last)})
+				run.Reset()
 			}
 		}
-
-		// Highlight the code part
-		if codePart != "" {
-			segments = append(segments, h.highlightCodePart(codePart, keywords)...)
-		}
-
-		// Add comment in gray/italic
-		if commentPart != "" {
-			segments = append(segments, &widget.TextSegment{
-				Text: commentPart,
-				Style: widget.RichTextStyle{
-					Inline:    true,
-					TextStyle: fyne.TextStyle{Italic: true},
-					ColorName: "disabled",
-				},
-			})
-		}
+		s.tokenize(line, func(kind tokenKind, text string) {
+			if kind != last {
+				flush()
+				last = kind
+			}
+			run.WriteString(text)
+		})
+		flush()
 
 		// Add newline if not last line
 		if lineIdx < len(lines)-1 {
@@ -122,121 +164,167 @@ func (h *SyntaxHighlighter) highlightWithKeywords(code string, keywords []string
 	return segments
 }
 
-// highlightCodePart highlights keywords and strings in a code fragment
-func (h *SyntaxHighlighter) highlightCodePart(code string, keywords []string) []widget.RichTextSegment {
-	var segments []widget.RichTextSegment
-	remaining := code
-
-	for len(remaining) > 0 {
-		// Check for string literals (start with " or ')
-		quote := ""
-		if strings.HasPrefix(remaining, "\"") {
-			quote = "\""
-		} else if strings.HasPrefix(remaining, "'") {
-			quote = "'"
-		}
+// tokenize scans one line, calling emit for each token in order
+func (s *syntax) tokenize(line string, emit func(kind tokenKind, text string)) {
+	for i := 0; i < len(line); {
+		c := line[i]
+		wordStart := i == 0 || !isWordChar(line[i-1])
+
+		switch {
+		case s.startsComment(line, i):
+			emit(tokenComment, line[i:])
+			return
+
+		case strings.IndexByte(s.quotes, c) >= 0:
+			end := s.findStringEnd(line[i+1:], c)
+			if end < 0 {
+				emit(tokenString, line[i:]) // Unterminated, e.g. while typing
+				return
+			}
+			emit(tokenString, line[i:i+end+2])
+			i += end + 2
+
+		case s.variables && c == '$':
+			n := variableLength(line[i:])
+			emit(tokenVariable, line[i:i+n])
+			i += n
+
+		case wordStart && isDigit(c):
+			if n := numberLength(line[i:]); n < len(line)-i && isWordChar(line[i+n]) {
+				n = wordLength(line[i:]) // e.g. 2nd, not a number
+				emit(tokenPlain, line[i:i+n])
+				i += n
+			} else {
+				emit(tokenNumber, line[i:i+n])
+				i += n
+			}
 
-		if quote != "" {
-			end := h.findStringEnd(remaining[1:], quote)
-			if end > 0 {
-				length := end + 2 // quote + content + quote
-				segments = append(segments, &widget.TextSegment{
-					Text: remaining[:length],
-					Style: widget.RichTextStyle{
-						Inline:    true,
-						ColorName: "success",
-					},
-				})
-				remaining = remaining[length:]
-				continue
+		case wordStart && isWordChar(c):
+			if kw := s.keywordAt(line[i:]); kw > 0 {
+				emit(tokenKeyword, line[i:i+kw])
+				i += kw
+			} else {
+				n := wordLength(line[i:])
+				emit(tokenPlain, line[i:i+n])
+				i += n
 			}
+
+		default:
+			emit(tokenPlain, line[i:i+1])
+			i++
 		}
+	}
+}
 
-		// Check for keywords
-		foundKeyword := false
-		for _, kw := range keywords {
-			// Regex to match keyword at start with boundary
-			// optimization: check prefix first
-			if len(remaining) >= len(kw) && strings.EqualFold(remaining[:len(kw)], kw) {
-				// check boundary
-				if len(remaining) == len(kw) || isBoundary(remaining[len(kw)]) {
-					kwLen := len(kw)
-					segments = append(segments, &widget.TextSegment{
-						Text: remaining[:kwLen],
-						Style: widget.RichTextStyle{
-							Inline:    true,
-							TextStyle: fyne.TextStyle{Bold: true},
-							ColorName: "primary",
-						},
-					})
-					remaining = remaining[kwLen:]
-					foundKeyword = true
-					break
-				}
-			}
+// startsComment reports whether a comment begins at line[i]. A shell # only starts
+// a comment at the beginning of a word, so ${#array} and a#b are left alone.
+func (s *syntax) startsComment(line string, i int) bool {
+	if !strings.HasPrefix(line[i:], s.commentPrefix) {
+		return false
+	}
+	if s.commentPrefix == "#" && i > 0 && line[i-1] != ' ' && line[i-1] != '\t' {
+		return false
+	}
+	return true
+}
+
+// keywordAt returns the length of the keyword text starts with, or 0
+func (s *syntax) keywordAt(text string) int {
+	for _, kw := range s.keywords {
+		if len(text) < len(kw) {
+			continue
 		}
-		if foundKeyword {
+		prefix := text[:len(kw)]
+		if s.caseSensitive && prefix != kw || !s.caseSensitive && !strings.EqualFold(prefix, kw) {
 			continue
 		}
-
-		// It's plain text.
-		// Consume until next potential keyword start (alpha) or string start (quote)
-		// To keep it simple and correct, we can just consume one char, or better, consume run of non-special chars.
-		// For now, let's just properly set Inline on the single char or chunk.
-
-		// Optimization: Find next special char
-		nextSpecial := -1
-		for i, r := range remaining {
-			if i == 0 {
-				continue
-			} // always consume at least 1 or we loop
-			if r == '"' || r == '\'' || isBoundary(byte(r)) { // Boundary check is loose here
-				nextSpecial = i
-				break
-			}
+		if len(text) == len(kw) || !isWordChar(text[len(kw)]) && text[len(kw)] != '-' {
+			return len(kw)
 		}
+	}
+	return 0
+}
 
-		chunk := ""
-		if nextSpecial == -1 {
-			chunk = remaining
-			remaining = ""
-		} else {
-			// Actually, we should only break on things that COULD start a keyword or string
-			// But for now, let's just emit 1 char with Inline=true to be safe and fix the bug.
-			// Optimizing to chunk is better but regex matching above expects start of string.
-			// If we define "plain" as anything until next whitespace or punctuation...
-
-			// Let's stick to 1-char consumption for safety but set Inline=true
-			// To improve perf slightly, verify if it's a letter.
-			chunk = string(remaining[0])
-			remaining = remaining[1:]
+// tokenStyle returns how a kind of token is shown
+func tokenStyle(kind tokenKind) widget.RichTextStyle {
+	style := widget.RichTextStyle{Inline: true}
+	switch kind {
+	case tokenKeyword:
+		style.TextStyle = fyne.TextStyle{Bold: true}
+		style.ColorName = theme.ColorNamePrimary
+	case tokenString:
+		style.ColorName = theme.ColorNameSuccess
+	case tokenComment:
+		style.TextStyle = fyne.TextStyle{Italic: true}
+		style.ColorName = theme.ColorNameDisabled
+	case tokenNumber:
+		style.ColorName = theme.ColorNameWarning
+	case tokenVariable:
+		style.ColorName = theme.ColorNameHyperlink
+	}
+	return style
+}
+
+// variableLength returns the length of the shell variable reference text starts with
+func variableLength(text string) int {
+	if len(text) < 2 {
+		return 1
+	}
+	switch c := text[1]; {
+	case c == '{':
+		if end := strings.IndexByte(text, '}'); end > 0 {
+			return end + 1
 		}
+		return len(text)
+	case isDigit(c) || strings.IndexByte("@*#?$!-", c) >= 0:
+		return 2 // Positional and special parameters are a single character
+	case isWordChar(c):
+		return 1 + wordLength(text[1:])
+	}
+	return 1
+}
 
-		segments = append(segments, &widget.TextSegment{
-			Text: chunk,
-			Style: widget.RichTextStyle{
-				Inline: true, // This was missing!
-			},
-		})
+// wordLength returns the length of the run of word characters text starts with
+func wordLength(text string) int {
+	n := 0
+	for n < len(text) && isWordChar(text[n]) {
+		n++
 	}
+	return n
+}
 
-	return segments
+// numberLength returns the length of the decimal number text starts with (digits with an optional fraction)
+func numberLength(text string) int {
+	n := 0
+	for n < len(text) && isDigit(text[n]) {
+		n++
+	}
+	if n+1 < len(text) && text[n] == '.' && isDigit(text[n+1]) {
+		n++
+		for n < len(text) && isDigit(text[n]) {
+			n++
+		}
+	}
+	return n
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
 }
 
-func isBoundary(b uint8) bool {
-	return b == ' ' || b == '\t' || b == '\n' || b == '\r' ||
-		b == '(' || b == ')' || b == '[' || b == ']' || b == '{' || b == '}' ||
-		b == ';' || b == ',' || b == '.'
+// isWordChar reports whether b can be part of an identifier, keyword or number
+func isWordChar(b byte) bool {
+	return b == '_' || isDigit(b) || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
 }
 
-// findStringEnd finds the end of a string literal, handling escapes
-func (h *SyntaxHighlighter) findStringEnd(s string, quote string) int {
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\\' && i+1 < len(s) {
-			i++ // Skip escaped character
+// findStringEnd finds the closing quote of a string literal, handling escapes
+func (s *syntax) findStringEnd(text string, quote byte) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == s.escape && quote != '\'' && i+1 < len(text) {
+			i++ // Skip escaped character (single-quoted shell strings have no escapes)
 			continue
 		}
-		if string(s[i]) == quote {
+		if text[i] == quote {
 			return i
 		}
 	}
@@ -0,0 +1,129 @@
+package window
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/actions"
+)
+
+// describe writes segments as kind"text" pairs, one line per line of code
+func describe(segments []widget.RichTextSegment) string {
+	kinds := map[string]string{
+		string(theme.ColorNamePrimary):   "kw",
+		string(theme.ColorNameSuccess):   "str",
+		string(theme.ColorNameDisabled):  "cmt",
+		string(theme.ColorNameWarning):   "num",
+		string(theme.ColorNameHyperlink): "var",
+	}
+	var b strings.Builder
+	for _, seg := range segments {
+		text := seg.(*widget.TextSegment)
+		if text.Text == "\n" {
+			b.WriteString("\n")
+			continue
+		}
+		kind, ok := kinds[string(text.Style.ColorName)]
+		if !ok {
+			kind = "txt"
+		}
+		fmt.Fprintf(&b, "%s%q ", kind, text.Text)
+	}
+	return strings.ReplaceAll(strings.TrimSpace(b.String()), " \n", "\n")
+}
+
+func TestHighlightSegments(t *testing.T) {
+	h := NewSyntaxHighlighter("sh")
+	tests := []struct {
+		name   string
+		syntax *syntax
+		code   string
+		want   string
+	}{
+		{"plain text is one run", h.shell, "ls -la /tmp",
+			`txt"ls -la /tmp"`},
+		{"keywords and variables", h.shell, `for f in $FILES; do echo "$f"; done`,
+			`kw"for" txt" f " kw"in" txt" " var"$FILES" txt"; " kw"do" txt" " kw"echo" txt" " str"\"$f\"" txt"; " kw"done"`},
+		{"keywords inside strings", h.shell, `echo "if then fi" # done`,
+			`kw"echo" txt" " str"\"if then fi\"" txt" " cmt"# done"`},
+		{"escaped quote", h.shell, `echo "a \" b" x`,
+			`kw"echo" txt" " str"\"a \\\" b\"" txt" x"`},
+		{"single quotes have no escapes", h.shell, `echo 'a\' b`,
+			`kw"echo" txt" " str"'a\\'" txt" b"`},
+		{"numbers", h.shell, "sleep 1.5 && head -n 20 2nd",
+			`txt"sleep " num"1.5" txt" && head -n " num"20" txt" 2nd"`},
+		{"special parameters", h.shell, `exit ${#args} $1 $? ${HOME}x`,
+			`kw"exit" txt" " var"${#args}" txt" " var"$1" txt" " var"$?" txt" " var"${HOME}" txt"x"`},
+		{"hash inside a word", h.shell, "a#b #c",
+			`txt"a#b " cmt"#c"`},
+		{"unterminated string", h.shell, `echo "still typing`,
+			`kw"echo" txt" " str"\"still typing"`},
+		{"multi-word keywords", h.appleScript, "tell application \"Finder\"\n\tdo shell script \"ls\"\nend tell",
+			`kw"tell" txt" " kw"application" txt" " str"\"Finder\""` + "\n" +
+				`txt"\t" kw"do shell script" txt" " str"\"ls\""` + "\n" +
+				`kw"end tell"`},
+		{"AppleScript is case insensitive", h.appleScript, "TELL app -- note",
+			`kw"TELL" txt" app " cmt"-- note"`},
+		{"keyword prefixes", h.appleScript, "telling tell-x",
+			`txt"telling tell-x"`},
+		{"PowerShell cmdlets", powerShellSyntax(), "Get-Process | Where-Object { $_.CPU -gt 10 }",
+			`kw"Get-Process" txt" | " kw"Where-Object" txt" { " var"$_" txt".CPU -gt " num"10" txt" }"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(tt.syntax.highlight(tt.code)); got != tt.want {
+				t.Errorf("segments:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHighlightRunsAreContiguous checks that long plain stretches stay one segment each
+func TestHighlightRunsAreContiguous(t *testing.T) {
+	code := strings.Repeat("cp -r /some/long/path/here /another/long/path/there\n", 200)
+	segments := NewSyntaxHighlighter("sh").shell.highlight(code)
+	if lines := 201; len(segments) > 2*lines {
+		t.Errorf("%d segments for %d lines, want at most 2 per line", len(segments), lines)
+	}
+}
+
+func TestCodePreviewWaitsForTypingToPause(t *testing.T) {
+	mw := newTestWindow(t)
+	selectNewAction(t, mw, actions.Action{ID: "sh", Name: "Shell", Type: actions.ActionTypeShellCommand, Code: "echo one"})
+	preview := func() string {
+		return mw.codePreviewScroll.Content.(*widget.RichText).String()
+	}
+
+	for _, text := range []string{"echo t", "echo tw", "echo two"} {
+		mw.codeEditor.SetText(text)
+	}
+	if got := preview(); got != "echo one" {
+		t.Errorf("preview = %q while typing, want the old code", got)
+	}
+
+	// One refresh is pending for the three edits. It is shown here rather than from the
+	// timer, as the test driver would run it off the test goroutine.
+	if mw.previewTimer == nil || !mw.previewTimer.Stop() {
+		t.Fatal("no preview refresh pending after typing")
+	}
+	mw.updateCodePreview()
+	if got := preview(); got != "echo two" {
+		t.Errorf("preview = %q after typing paused, want %q", got, "echo two")
+	}
+}
+
+func BenchmarkHighlightShell(b *testing.B) {
+	var code strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&code, "if [ -f \"$DIR/file%d\" ]; then cp \"$DIR/file%d\" /backup/ # copy %d\nfi\n", i, i, i)
+	}
+	h := NewSyntaxHighlighter("sh")
+	b.ReportAllocs()
+	for b.Loop() {
+		segments := h.shell.highlight(code.String())
+		b.ReportMetric(float64(len(segments)), "segments/op")
+	}
+}
@@ -0,0 +1,127 @@
+package window
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// ============ GLOBAL TOP ROW ============
+
+// menuOptionPrefix marks menu switches among the actions in the top-row dropdowns
+const menuOptionPrefix = "☰ "
+
+// showTopRowEditor edits the global top-row bindings that devices with UseTopRow show
+// over row 0 of every menu
+func (mw *MainWindow) showTopRowEditor() {
+	working := mw.cfg.TopRow
+
+	// What a pad can do: nothing, run an action, group or scene, or switch menus
+	options, targets := []string{i18n.T("common.none")}, []config.TopRowBinding{{}}
+	for _, item := range mw.actionStore.GetFlatList() {
+		name := treeItemName(item)
+		if item.IsGroup {
+			name = "📁 " + name
+		}
+		options = append(options, strings.Repeat("  ", item.Depth)+name)
+		targets = append(targets, config.TopRowBinding{ActionID: treeItemID(item)})
+	}
+	for _, scene := range mw.cfg.Scenes {
+		options = append(options, sceneOption(scene.Name))
+		targets = append(targets, config.TopRowBinding{ActionID: scene.ID})
+	}
+	for _, menu := range mw.cfg.Menus {
+		options = append(options, menuOptionPrefix+menu.Name)
+		targets = append(targets, config.TopRowBinding{MenuID: menu.ID})
+	}
+	targetIndex := func(b config.TopRowBinding) int {
+		return max(slices.IndexFunc(targets, func(t config.TopRowBinding) bool {
+			return t.ActionID == b.ActionID && t.MenuID == b.MenuID
+		}), 0)
+	}
+
+	colorOptions := busyColorOptions()
+	rows := container.NewVBox()
+	for col := range working {
+		binding := &working[col]
+		colorSelect := widget.NewSelect(colorOptions, func(s string) {
+			preset := busyColorPresets[max(slices.Index(colorOptions, s), 0)]
+			binding.R, binding.G, binding.B = preset.r, preset.g, preset.b
+		})
+		if i := busyColorIndex(binding.R, binding.G, binding.B); i >= 0 {
+			setSelectedSilently(colorSelect, colorOptions[i])
+		} else if !binding.Bound() {
+			setSelectedSilently(colorSelect, colorOptions[0])
+			binding.R, binding.G, binding.B = busyColorPresets[0].r, busyColorPresets[0].g, busyColorPresets[0].b
+		}
+		targetSelect := widget.NewSelect(options, func(s string) {
+			target := targets[max(slices.Index(options, s), 0)]
+			binding.ActionID, binding.MenuID = target.ActionID, target.MenuID
+		})
+		setSelectedSilently(targetSelect, options[targetIndex(*binding)])
+		rows.Add(container.NewBorder(nil, nil, widget.NewLabel(strconv.Itoa(col+1)), nil,
+			container.NewGridWithColumns(2, targetSelect, colorSelect)))
+	}
+
+	hint := widget.NewLabel(i18n.T("top_row.hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	var dlg dialog.Dialog
+	cancelBtn := widget.NewButtonWithIcon(i18n.T("common.cancel"), theme.CancelIcon(), func() { dlg.Hide() })
+	okBtn := widget.NewButtonWithIcon(i18n.T("common.ok"), theme.ConfirmIcon(), func() {
+		dlg.Hide()
+		mw.cfg.TopRow = working
+		mw.engine.QueueLayoutSends()
+		mw.refreshGrid()
+		mw.updateTopRowWarning()
+	})
+	okBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		hint,
+		rows,
+		container.NewHBox(layout.NewSpacer(), cancelBtn, okBtn),
+	)
+
+	dlg = dialog.NewCustomWithoutButtons(i18n.T("top_row.title"), content, mw.window)
+	dlg.Resize(fyne.NewSize(520, 0))
+	dlg.Show()
+}
+
+// topRowReserved reports whether the global top row replaces the current menu's pad
+// at row/col on some device
+func (mw *MainWindow) topRowReserved(row, col int) bool {
+	_, ok := mw.cfg.TopRow.At(row, col)
+	return ok && mw.cfg.UsesTopRow()
+}
+
+// updateTopRowWarning shows, for a selected pad the global top row replaces, that the
+// pad is reserved, and warns when the layout binds an action to it anyway
+func (mw *MainWindow) updateTopRowWarning() {
+	if mw.topRowWarning == nil {
+		return
+	}
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil || !mw.topRowReserved(mw.selectedRow, mw.selectedCol) {
+		mw.topRowWarning.Hide()
+		return
+	}
+	if slices.Contains(mw.cfg.TopRowConflicts(menu.ID), mw.selectedCol) {
+		mw.topRowWarning.SetText(i18n.T("menu_editor.top_row_conflict"))
+		mw.topRowWarning.Importance = widget.WarningImportance
+	} else {
+		mw.topRowWarning.SetText(i18n.T("menu_editor.top_row_reserved"))
+		mw.topRowWarning.Importance = widget.MediumImportance
+	}
+	mw.topRowWarning.Show()
+	mw.topRowWarning.Refresh()
+}
@@ -0,0 +1,105 @@
+package window
+
+import (
+	"image/color"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/padcolor"
+)
+
+// virtualPadSize is the side of a Virtual Launchpad pad
+const virtualPadSize = 48
+
+// showVirtualLaunchpad opens the on-screen Launchpad, adding a device for it the first
+// time so layouts can be assigned to it like to real hardware
+func (mw *MainWindow) showVirtualLaunchpad() {
+	if mw.virtualLaunchpad != nil {
+		mw.virtualLaunchpad.RequestFocus()
+		return
+	}
+
+	sim := mw.midiManager.OpenSimulator()
+	mw.refreshPorts()
+	if !slices.ContainsFunc(mw.cfg.Devices, func(d config.DeviceConfig) bool { return d.Type == config.DeviceTypeSimulated }) {
+		device := mw.cfg.NewDevice()
+		device.Name = i18n.T("virtual_launchpad.title")
+		device.Type = config.DeviceTypeSimulated
+		device.InPort, device.OutPort = midi.SimulatorPortName, midi.SimulatorPortName
+		device.SendStaticLayout = true // Lights are all it has
+		mw.addDetectedDevice(device)
+	}
+
+	var pads [9][9]*canvas.Rectangle
+	grid := container.NewGridWithColumns(9)
+	colors := sim.Colors()
+	for row := range 9 {
+		for col := range 9 {
+			rect := canvas.NewRectangle(virtualPadColor(colors[row][col]))
+			rect.CornerRadius = 4
+			rect.SetMinSize(fyne.NewSize(virtualPadSize, virtualPadSize))
+			pads[row][col] = rect
+			grid.Add(newVirtualPad(rect, func(down bool) { sim.Press(row, col, down) }))
+		}
+	}
+	sim.OnChange(func(row, col int, c midi.PadColor) {
+		fyne.Do(func() {
+			pads[row][col].FillColor = virtualPadColor(c)
+			pads[row][col].Refresh()
+		})
+	})
+
+	hint := widget.NewLabel(i18n.T("virtual_launchpad.hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	win := mw.app.NewWindow(i18n.T("virtual_launchpad.title"))
+	win.SetContent(container.NewBorder(nil, hint, nil, nil, container.NewPadded(grid)))
+	win.SetOnClosed(func() {
+		sim.OnChange(nil) // The device stays, with the pads lit where the window can't see
+		mw.virtualLaunchpad = nil
+	})
+	mw.virtualLaunchpad = win
+	win.Show()
+}
+
+// virtualPadColor is how a pad shows a color. Unlit pads take the input background so
+// the grid stays visible; flashing pads show steady.
+func virtualPadColor(c midi.PadColor) color.Color {
+	if c.R == 0 && c.G == 0 && c.B == 0 {
+		return theme.Color(theme.ColorNameInputBackground)
+	}
+	return padcolor.RGBA(c.R, c.G, c.B)
+}
+
+// virtualPad is a pad of the Virtual Launchpad, reporting presses and releases
+type virtualPad struct {
+	widget.BaseWidget
+	rect    *canvas.Rectangle
+	onPress func(down bool)
+}
+
+func newVirtualPad(rect *canvas.Rectangle, onPress func(down bool)) *virtualPad {
+	p := &virtualPad{rect: rect, onPress: onPress}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+func (p *virtualPad) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(p.rect)
+}
+
+func (p *virtualPad) MouseDown(_ *desktop.MouseEvent) {
+	p.onPress(true)
+}
+
+func (p *virtualPad) MouseUp(_ *desktop.MouseEvent) {
+	p.onPress(false)
+}
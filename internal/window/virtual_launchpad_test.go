@@ -0,0 +1,46 @@
+package window
+
+import (
+	"testing"
+
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi"
+)
+
+// simulatedDevices returns the configured Virtual Launchpad devices
+func simulatedDevices(mw *MainWindow) []config.DeviceConfig {
+	var devices []config.DeviceConfig
+	for _, d := range mw.cfg.Devices {
+		if d.Type == config.DeviceTypeSimulated {
+			devices = append(devices, d)
+		}
+	}
+	return devices
+}
+
+func TestVirtualLaunchpadAddsOneDevice(t *testing.T) {
+	mw := newTestWindow(t, "Main")
+	mw.showVirtualLaunchpad()
+	devices := simulatedDevices(mw)
+	if len(devices) != 1 {
+		t.Fatalf("%d Virtual Launchpad devices, want 1", len(devices))
+	}
+	if d := devices[0]; d.InPort != midi.SimulatorPortName || d.OutPort != midi.SimulatorPortName || !d.SendStaticLayout {
+		t.Errorf("device = %+v, want the simulator's ports and its layout sent", d)
+	}
+
+	win := mw.virtualLaunchpad
+	mw.showVirtualLaunchpad() // Already open: focused, not opened again
+	if mw.virtualLaunchpad != win {
+		t.Error("a second window was opened")
+	}
+	win.Close()
+	if mw.virtualLaunchpad != nil {
+		t.Error("window still tracked after closing")
+	}
+
+	mw.showVirtualLaunchpad()
+	if n := len(simulatedDevices(mw)); n != 1 {
+		t.Errorf("reopening left %d Virtual Launchpad devices, want 1", n)
+	}
+}
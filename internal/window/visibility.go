@@ -0,0 +1,92 @@
+package window
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+)
+
+// refreshVisibility checks the current layout's pads for colors that are hard to make
+// out on the configured devices, updating the pads whose warning changed and the note
+// for the selected pad. A color change can affect the pad's neighbors, so every pad is
+// checked again.
+func (mw *MainWindow) refreshVisibility() {
+	var issues [9][9]engine.Visibility
+	if menu := mw.cfg.GetCurrentMenu(); menu != nil {
+		issues = engine.MenuVisibility(mw.cfg.Devices, menu, mw.cfg.Visibility)
+	}
+	previous := mw.visibility
+	mw.visibility = issues
+	for row := range 9 {
+		for col := range 9 {
+			if issues[row][col] != previous[row][col] {
+				mw.updatePadLabel(row, col)
+			}
+		}
+	}
+	mw.updateVisibilityNote()
+}
+
+// visibilityReasons explains in words why a pad is hard to make out
+func visibilityReasons(v engine.Visibility) []string {
+	var reasons []string
+	if v&engine.VisibilityDim != 0 {
+		reasons = append(reasons, i18n.T("menu_editor.visibility.dim"))
+	}
+	if v&engine.VisibilityMerged != 0 {
+		reasons = append(reasons, i18n.T("menu_editor.visibility.merged"))
+	}
+	return reasons
+}
+
+// updateVisibilityNote explains under the sent preview why the selected pad is hard to
+// make out, if it is
+func (mw *MainWindow) updateVisibilityNote() {
+	if mw.visibilityNote == nil {
+		return
+	}
+	reasons := visibilityReasons(mw.visibility[mw.selectedRow][mw.selectedCol])
+	if len(reasons) == 0 {
+		mw.visibilityNote.Hide()
+		return
+	}
+	mw.visibilityNote.SetText("⚠ " + strings.Join(reasons, "\n⚠ "))
+	mw.visibilityNote.Show()
+}
+
+// showVisibilityCheck lists the pads of the current layout that are hard to make out
+// on the configured devices
+func (mw *MainWindow) showVisibilityCheck() {
+	menu := mw.cfg.GetCurrentMenu()
+	if menu == nil {
+		return
+	}
+
+	var lines []string
+	issues := engine.MenuVisibility(mw.cfg.Devices, menu, mw.cfg.Visibility)
+	for row := range 9 {
+		for col := range 9 {
+			if reasons := visibilityReasons(issues[row][col]); len(reasons) > 0 {
+				pad := i18n.T("actions.reference_pad", menu.Name, row, col)
+				lines = append(lines, "• "+pad+" – "+strings.Join(reasons, " "))
+			}
+		}
+	}
+
+	var content fyne.CanvasObject
+	if len(lines) == 0 {
+		content = widget.NewLabel(i18n.T("menu_editor.visibility.none"))
+	} else {
+		list := widget.NewLabel(strings.Join(lines, "\n"))
+		list.Wrapping = fyne.TextWrapWord
+		scroll := container.NewVScroll(list)
+		scroll.SetMinSize(fyne.NewSize(520, 260))
+		content = container.NewBorder(widget.NewLabel(i18n.N("menu_editor.visibility.count", len(lines), len(lines))), nil, nil, nil, scroll)
+	}
+	dialog.ShowCustom(i18n.T("menu_editor.visibility.title", menu.Name), i18n.T("common.close"), content, mw.window)
+}
@@ -1,27 +1,108 @@
 package window
 
 import (
+	"errors"
+	"fmt"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
 )
 
 // ============ TAPPABLE RECTANGLE WIDGET ============
 
 type tappableRect struct {
 	widget.BaseWidget
-	rect  *canvas.Rectangle
-	onTap func()
+	rect    *canvas.Rectangle
+	ring    *canvas.Rectangle // Focus ring, drawn over rect while focused
+	badge   *canvas.Text      // Marker in the top-left corner, see SetBadge
+	code    *canvas.Text      // Color code in the middle, see SetCode
+	pattern *canvas.Raster    // Drawn over rect's fill, see SetPattern
+	fill    padPattern        // What pattern draws
+	ink     color.Color       // Color the pattern is drawn in
+	onTap   func()            // Also called for Space and Return while focused
+	onKey   func(*fyne.KeyEvent)
+	onFocus func(label string) // Called when focused or hovered, to show the label
+	label   string             // Describes the pad in words, see SetLabel
 }
 
 func newTappableRect(rect *canvas.Rectangle, onTap func()) *tappableRect {
-	t := &tappableRect{rect: rect, onTap: onTap}
+	ring := canvas.NewRectangle(color.Transparent)
+	ring.StrokeWidth = 3
+	ring.CornerRadius = rect.CornerRadius
+	ring.Hide()
+	badge := canvas.NewText("", theme.Color(theme.ColorNameForeground))
+	badge.TextSize = theme.CaptionTextSize()
+	code := canvas.NewText("", theme.Color(theme.ColorNameForeground))
+	code.TextStyle = fyne.TextStyle{Bold: true}
+	code.Alignment = fyne.TextAlignCenter
+	t := &tappableRect{rect: rect, ring: ring, badge: badge, code: code, onTap: onTap, ink: color.Transparent}
+	t.pattern = canvas.NewRasterWithPixels(t.patternPixel)
+	t.pattern.Hide()
 	t.ExtendBaseWidget(t)
 	return t
 }
 
 func (t *tappableRect) CreateRenderer() fyne.WidgetRenderer {
-	return widget.NewSimpleRenderer(t.rect)
+	return widget.NewSimpleRenderer(container.NewStack(t.rect, t.pattern, container.NewCenter(t.code),
+		container.NewVBox(container.NewHBox(t.badge)), t.ring))
+}
+
+// SetCode sets the text drawn in the middle of the pad and its color, "" for none
+func (t *tappableRect) SetCode(text string, c color.Color) {
+	if t.code.Text != text || t.code.Color != c {
+		t.code.Text = text
+		t.code.Color = c
+		t.code.Refresh()
+	}
+}
+
+// SetPattern sets the pattern drawn over the pad's fill and its color
+func (t *tappableRect) SetPattern(p padPattern, ink color.Color) {
+	if t.fill == p && t.ink == ink {
+		return
+	}
+	t.fill, t.ink = p, ink
+	if p == patternNone {
+		t.pattern.Hide()
+		return
+	}
+	t.pattern.Show()
+	t.pattern.Refresh()
+}
+
+// patternPixel draws the pad's pattern, leaving a margin clear so the outline and the
+// rounded corners stay as they are
+func (t *tappableRect) patternPixel(x, y, w, h int) color.Color {
+	margin := max(w/10, 3)
+	if x < margin || y < margin || x >= w-margin || y >= h-margin {
+		return color.Transparent
+	}
+	if t.fill.covers(x, y, max(w/6, 4)) {
+		return t.ink
+	}
+	return color.Transparent
+}
+
+// SetBadge sets the marker drawn in the pad's corner, "" for none
+func (t *tappableRect) SetBadge(text string) {
+	if t.badge.Text != text {
+		t.badge.Text = text
+		t.badge.Refresh()
+	}
+}
+
+// SetLabel sets the description shown for the pad while it is focused or hovered
+func (t *tappableRect) SetLabel(label string) {
+	t.label = label
 }
 
 func (t *tappableRect) Tapped(_ *fyne.PointEvent) {
@@ -31,3 +112,149 @@ func (t *tappableRect) Tapped(_ *fyne.PointEvent) {
 }
 
 func (t *tappableRect) TappedSecondary(_ *fyne.PointEvent) {}
+
+func (t *tappableRect) FocusGained() {
+	// The foreground color contrasts with the background in light and dark themes alike
+	t.ring.StrokeColor = theme.Color(theme.ColorNameForeground)
+	t.ring.Show()
+	t.ring.Refresh()
+	t.showLabel()
+}
+
+func (t *tappableRect) FocusLost() {
+	t.ring.Hide()
+}
+
+func (t *tappableRect) TypedRune(_ rune) {}
+
+func (t *tappableRect) TypedKey(event *fyne.KeyEvent) {
+	switch event.Name {
+	case fyne.KeySpace, fyne.KeyReturn, fyne.KeyEnter:
+		t.Tapped(nil)
+	default:
+		if t.onKey != nil {
+			t.onKey(event)
+		}
+	}
+}
+
+func (t *tappableRect) MouseIn(_ *desktop.MouseEvent) {
+	t.showLabel()
+}
+
+func (t *tappableRect) MouseMoved(_ *desktop.MouseEvent) {}
+
+func (t *tappableRect) MouseOut() {}
+
+// showLabel passes the label to onFocus
+func (t *tappableRect) showLabel() {
+	if t.onFocus != nil {
+		t.onFocus(t.label)
+	}
+}
+
+// ============ SILENT UPDATE HELPERS ============
+
+// setSelectedSilently changes a Select's selection without firing its OnChanged callback.
+// Use it for programmatic updates so handlers only see user-initiated changes. Like
+// SetSelected, it ignores an option the Select doesn't offer.
+func setSelectedSilently(s *widget.Select, option string) {
+	if !slices.Contains(s.Options, option) || s.Selected == option {
+		return
+	}
+	s.Selected = option
+	s.Refresh()
+}
+
+// setCheckedSilently changes a Check's state without firing its OnChanged callback
+func setCheckedSilently(c *widget.Check, checked bool) {
+	if c.Checked == checked {
+		return
+	}
+	c.Checked = checked
+	c.Refresh()
+}
+
+// setTextSilently changes an Entry's text without firing its OnChanged callback.
+// List rows are recycled, so the callback may still be bound to the previous item.
+func setTextSilently(e *widget.Entry, text string) {
+	onChanged := e.OnChanged
+	e.OnChanged = nil
+	defer func() { e.OnChanged = onChanged }()
+
+	e.SetText(text)
+}
+
+// ============ NUMBER ENTRY WIDGET ============
+
+// intEntry is an entry for a whole number from min to max. Anything else is marked
+// invalid as it is typed; pressing Return or leaving the field clamps it into range.
+type intEntry struct {
+	widget.Entry
+	min, max int
+	value    int  // Last valid value, put back when the text isn't a number
+	silent   bool // Set while SetValue changes the text
+
+	OnValue   func(v int)     // Called with each valid value, typed or clamped
+	OnInvalid func(err error) // Called while the text is not a valid value
+}
+
+func newIntEntry(lo, hi int) *intEntry {
+	e := &intEntry{min: lo, max: hi, value: lo}
+	e.ExtendBaseWidget(e)
+	e.SetPlaceHolder(fmt.Sprintf("%d-%d", lo, hi))
+	e.Validator = e.validate
+	e.Entry.OnChanged = e.changed
+	e.Entry.OnSubmitted = func(string) { e.clamp() }
+	return e
+}
+
+// SetValue shows a value without calling OnValue. Stored values out of range show as
+// invalid until edited.
+func (e *intEntry) SetValue(v int) {
+	e.value = max(e.min, min(v, e.max))
+	e.silent = true
+	defer func() { e.silent = false }()
+	e.SetText(strconv.Itoa(v))
+}
+
+func (e *intEntry) FocusLost() {
+	e.clamp()
+	e.Entry.FocusLost()
+}
+
+// validate accepts whole numbers from min to max
+func (e *intEntry) validate(s string) error {
+	if v, err := strconv.Atoi(strings.TrimSpace(s)); err != nil || v < e.min || v > e.max {
+		return errors.New(i18n.T("common.value_range", e.min, e.max))
+	}
+	return nil
+}
+
+func (e *intEntry) changed(s string) {
+	if e.silent {
+		return
+	}
+	if err := e.validate(s); err != nil {
+		if e.OnInvalid != nil {
+			e.OnInvalid(err)
+		}
+		return
+	}
+	e.value, _ = strconv.Atoi(strings.TrimSpace(s))
+	if e.OnValue != nil {
+		e.OnValue(e.value)
+	}
+}
+
+// clamp replaces a number out of range with the nearest valid one, and text that isn't
+// a number with the last valid value
+func (e *intEntry) clamp() {
+	v, err := strconv.Atoi(strings.TrimSpace(e.Text))
+	if err != nil {
+		v = e.value
+	}
+	if text := strconv.Itoa(max(e.min, min(v, e.max))); text != e.Text {
+		e.SetText(text)
+	}
+}
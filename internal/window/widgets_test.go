@@ -0,0 +1,55 @@
+package window
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestIntEntry(t *testing.T) {
+	test.NewTempApp(t)
+	e := newIntEntry(0, 127)
+	var values []int
+	invalid := 0
+	e.OnValue = func(v int) { values = append(values, v) }
+	e.OnInvalid = func(error) { invalid++ }
+
+	for _, tt := range []struct {
+		text  string
+		valid bool
+	}{{"0", true}, {"127", true}, {" 64 ", true}, {"128", false}, {"-5", false}, {"12a", false}, {"", false}} {
+		values, invalid = nil, 0
+		e.SetText(tt.text)
+		if valid := e.Validate() == nil; valid != tt.valid {
+			t.Errorf("%q: valid = %v, want %v", tt.text, valid, tt.valid)
+		}
+		if tt.valid && len(values) != 1 {
+			t.Errorf("%q: OnValue called %d times", tt.text, len(values))
+		}
+		if !tt.valid && (len(values) != 0 || invalid != 1) {
+			t.Errorf("%q: OnValue %v, OnInvalid %d times", tt.text, values, invalid)
+		}
+	}
+
+	// Committing clamps into range, and puts the last valid value back for text
+	for _, tt := range []struct {
+		text, want string
+	}{{"128", "127"}, {"-5", "0"}, {"300", "127"}, {"abc", "127"}} {
+		e.SetText(tt.text)
+		e.OnSubmitted(e.Text)
+		if e.Text != tt.want {
+			t.Errorf("%q committed as %q, want %q", tt.text, e.Text, tt.want)
+		}
+	}
+
+	// SetValue shows stored values without reporting them, even out of range
+	values = nil
+	e.SetValue(200)
+	if e.Text != "200" || len(values) != 0 || e.Validate() == nil {
+		t.Errorf("SetValue(200): text %q, OnValue %v, valid %v", e.Text, values, e.Validate() == nil)
+	}
+	e.FocusLost()
+	if e.Text != "127" {
+		t.Errorf("leaving the field kept %q, want 127", e.Text)
+	}
+}
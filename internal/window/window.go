@@ -1,7 +1,7 @@
 package window
 
 import (
-	"log"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -9,29 +9,65 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"github.com/PixPMusic/gopher-automate/internal/actions"
 	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/engine"
+	"github.com/PixPMusic/gopher-automate/internal/httpapi"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
 	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/status"
 )
 
+// portWatchInterval is how often the MIDI port list is polled for hot-plug changes
+const portWatchInterval = 2 * time.Second
+
 // MainWindow manages the main application window
 type MainWindow struct {
 	window      fyne.Window
 	app         fyne.App
 	cfg         *config.Config
-	midiManager *midi.Manager
+	midiManager midi.Ports
 	deviceList  *widget.List
 	onSave      func()
+	refreshTray func()          // Rebuilds the tray's dynamic menus, set by SetTrayRefresh
+	httpAPI     *httpapi.Server // Reconfigured from preferences, set by SetHTTPAPI
+	engine      *engine.Engine  // Device and action runtime shared with headless mode
+
+	// Tabs that other tabs navigate to or rebuild
+	tabs           *container.AppTabs
+	safeModeBanner *fyne.Container // Shown while devices are left uninitialized after a crash
+	menuEditorTab  *container.TabItem
+	actionsTab     *container.TabItem
+	mappingTab     *container.TabItem
+	preferencesTab *container.TabItem
+
+	// Last known MIDI ports, kept current by the port watcher
+	inPorts       []string
+	outPorts      []string
+	stopPortWatch func()
+
+	// Outside edits of the config file
+	stopConfigWatch  func()
+	configPromptOpen bool // A reload prompt is showing
 
 	// Menu editor state
 	gridRects      [9][9]*canvas.Rectangle
+	gridPads       [9][9]*tappableRect                  // Focusable pads holding gridRects
+	gridShape      config.LayoutShape                   // The shape the grid was built in, see EditorShape
+	outerRects     map[config.PlanPad]*canvas.Rectangle // The Pro pads around the 9x9 grid
+	cellRefreshes  int                                  // Pads refreshCell redrew, for tests to count
+	padLabelText   *widget.Label                        // Describes the focused or hovered pad
 	layoutDropdown *widget.Select
 	gridContainer  *fyne.Container
 	revertBtn      *widget.Button
+	unsavedLabel   *widget.Label
 	dirty          bool // true if current layout has unsaved changes
 
 	// Color picker panel state
-	selectedRow int
-	selectedCol int
-	colorPanel  *fyne.Container
+	selectedRow    int
+	selectedCol    int
+	colorPanel     *fyne.Container
+	colorSections  *fyne.Container // Holds the static and pressed sections, see layoutColorSections
+	staticSection  fyne.CanvasObject
+	pressedSection fyne.CanvasObject
 
 	// Color sliders (0-127 range)
 	buttonRSlider, buttonGSlider, buttonBSlider                         *widget.Slider
@@ -41,64 +77,176 @@ type MainWindow struct {
 
 	// Color previews
 	buttonPreview, classicPreview, pressedPreview, classicPressedPreview *canvas.Rectangle
+	sentPreview                                                          *sentPreview // What the colors look like on each kind of device
 
 	// Link checkboxes
 	linkButtonClassic, linkPressedClassic *widget.Check
 
-	// MIDI input listeners
-	midiStopFuncs []func()
+	// Output ports already offered as new devices this session
+	offeredPorts map[string]bool
 
 	// Action system
-	executor         *actions.Executor
-	actionStore      *actions.ActionStore
-	actionList       *widget.List
-	actionEditor     *fyne.Container
-	selectedAction   *actions.Action
-	selectedGroup    *actions.ActionGroup
-	actionNameEntry  *widget.Entry
-	actionTypeSelect *widget.Select
-	actionCodeEntry  *widget.Entry
-	actionFeedback   *widget.Label
-	padActionSelect  *widget.Select // Action selector in color picker panel
+	executor          *actions.Executor
+	actionStore       *actions.ActionStore
+	actionList        *widget.List
+	actionFilter      actionFilter // Narrows what actionList shows, see visibleActionItems
+	clearActionFilter func()       // Resets the filter bar and shows every item
+	actionEditor      *fyne.Container
+	actionsRevertBtn  *widget.Button
+	actionsUnsaved    *widget.Label // Shown next to Save while action edits are unsaved
+	selectedAction    *actions.Action
+	selectedGroup     *actions.ActionGroup
+	actionNameEntry   *widget.Entry
+	actionTypeSelect  *widget.Select
+	codeEditor        *codeEditor
+	actionFeedback    *actionOutput
+	stopTestBtn       *widget.Button
+	testStop          chan struct{}   // Closed by the stop button, nil unless a test runs
+	usedByBox         *fyne.Container // Pads, mappings and groups using the selected item
+	padActionPicker   *actionPicker   // Action selector in color picker panel
+	fallbackPicker    *actionPicker   // Action for the layout's pads without one, in the layout bar
+	activatePicker    *actionPicker   // Action run when devices switch to the layout, in the layout bar
+	recentPicks       []string        // IDs picked lately in action pickers, most recent first
+	padArgsEntry      *widget.Entry   // Action arguments in color picker panel
+	padDebounceSelect *widget.Select  // Debounce override in color picker panel
+	padLightSelect    *widget.Select  // Light mode (steady or flashing) in color picker panel
+	padSymbolEntry    *widget.Entry   // Symbol shown with color codes in color picker panel
+	padBusySelect     *widget.Select  // Busy color override in color picker panel
+	topRowWarning     *widget.Label   // Shown when the selected pad is replaced by the global top row
+	visibilityNote    *widget.Label   // Why the selected pad is hard to make out on hardware, see refreshVisibility
+	padUsageLabel     *widget.Label   // Press count of the selected pad, shown with the heat map
+	heatMap           bool            // Whether the grid is tinted by press count
+
+	// Pads of the current layout hard to make out on hardware, see refreshVisibility
+	visibility [9][9]engine.Visibility
+
+	// Whether the selected pad sets its own colors or inherits the layout defaults
+	overrideStaticCheck   *widget.Check
+	overridePressedCheck  *widget.Check
+	staticInheritedLabel  *widget.Label
+	pressedInheritedLabel *widget.Label
+
+	// MIDI thru settings of the selected pad in color picker panel
+	padThruModeSelect, padThruDeviceSelect, padThruChannelSelect, padThruNoteSelect *widget.Select
 
 	// Specialized editor fields
 	sleepDurationEntry     *widget.Entry
 	waitForCompletionCheck *widget.Check
+	favoriteCheck          *widget.Check
+	allowDangerousCheck    *widget.Check
+	requireConfirmCheck    *widget.Check
+	cooldownEntry          *widget.Entry
+	cooldownRow            *fyne.Container
+	colorTagSelect         *widget.Select
 
 	// MIDI Action Editor fields
 	midiDeviceSelect  *widget.Select
 	midiMsgTypeSelect *widget.RadioGroup
 	midiChannelSelect *widget.Select
-	midiNoteEntry     *widget.Entry
-	midiVelocityEntry *widget.Entry
-	midiProgramEntry  *widget.Entry
+	midiNoteEntry     *intEntry
+	midiVelocityEntry *intEntry
+	midiProgramEntry  *intEntry
 	midiSysexEntry    *widget.Entry
+	midiMinEntry      *intEntry // Value range and timeout of wait-for-MIDI actions
+	midiMaxEntry      *intEntry
+	midiTimeoutEntry  *widget.Entry
+	midiDraft         actions.WaitMidiData // Data of the selected MIDI action, edited field by field
 
 	actionEditorContent *fyne.Container // Container for swapping editor content
 
+	virtualLaunchpad  fyne.Window   // Open Virtual Launchpad window, nil while closed
+	initializingLabel *widget.Label // Devices tab note while startup initialization runs
+
 	syntaxHighlighter *SyntaxHighlighter
 	codePreviewScroll *container.Scroll
+	previewTimer      *time.Timer // Pending preview refresh while typing
 
 	// Message Mapping system
-	mappingList *widget.List
+	mappingList     *widget.List
+	mappingOverlaps *fyne.Container // Pairs of mappings matching the same messages
+
+	// Preferences
+	startupCheck     *widget.Check
+	resetWarningBtn  *widget.Button
+	prefsFeedback    *widget.Label
+	configSizeLabel  *widget.Label
+	configSavedLabel *widget.Label
+
+	// The app's startup and shutdown actions, see refreshAppHookPickers
+	startupActionPicker  *actionPicker
+	shutdownActionPicker *actionPicker
+
+	// Log viewer
+	logList   *widget.List
+	logLines  []string // Lines shown after filtering
+	logFilter string   // Minimum level shown ("all" shows everything)
+
+	// Diagnostics
+	diagnosticsTab      *container.TabItem
+	messagesLabel       *widget.Label
+	errorsLabel         *widget.Label
+	actionLatencyLabel  *widget.Label
+	dispatchLabel       *widget.Label
+	padUpdateLabel      *widget.Label
+	coalescedLabel      *widget.Label
+	gridPushLabel       *widget.Label
+	usageLabel          *widget.Label
+	diagnosticsFeedback *widget.Label
 }
 
 // NewMainWindow creates the main application window
-func NewMainWindow(app fyne.App, cfg *config.Config, midiManager *midi.Manager, onSave func()) *MainWindow {
+func NewMainWindow(app fyne.App, cfg *config.Config, midiManager midi.Ports, onSave func()) *MainWindow {
 	win := app.NewWindow("GopherAutomate")
 
+	actionStore := cfg.GetActionStore()
+	eng := engine.New(cfg, midiManager, actionStore)
+	eng.SetDispatcher(fyne.Do, fyne.DoAndWait) // The UI owns the config
+
 	mw := &MainWindow{
 		window:            win,
 		app:               app,
 		cfg:               cfg,
 		midiManager:       midiManager,
 		onSave:            onSave,
-		executor:          actions.NewExecutor(midiManager),
-		actionStore:       cfg.GetActionStore(),
-		syntaxHighlighter: NewSyntaxHighlighter(),
+		engine:            eng,
+		executor:          eng.Executor(),
+		actionStore:       actionStore,
+		syntaxHighlighter: NewSyntaxHighlighter(eng.Executor().GetShellName()),
+		offeredPorts:      map[string]bool{},
 	}
 
+	mw.inPorts = midiManager.ListInPorts()
+	mw.outPorts = midiManager.ListOutPorts()
+	mw.publishDeviceStatus()
+
+	if mw.cfg.MinHitTarget > 0 {
+		mw.applyHitTarget() // Before building the UI, so it is laid out for the larger theme
+	}
 	mw.setupUI()
+	actionStore.OnChange(mw.updateActionsDirty)
+	eng.Status().Subscribe(func(s status.Snapshot) {
+		fyne.Do(func() {
+			if s.Initializing {
+				mw.initializingLabel.Show()
+			} else {
+				mw.initializingLabel.Hide()
+				mw.deviceList.Refresh() // Late devices are up now
+			}
+		})
+	})
+
+	// Pick up devices that are plugged in or removed while the app is running
+	mw.stopPortWatch = midiManager.WatchPorts(portWatchInterval, func(inPorts, outPorts []string) {
+		mw.engine.PortsChanged(inPorts, outPorts)
+		fyne.Do(func() {
+			mw.setAvailablePorts(inPorts, outPorts)
+		})
+	})
+
+	// Offer to reload when config.json is edited by hand or synced in, and catch up when
+	// a save merged in changes saved meanwhile
+	mw.watchConfigFile()
+	mw.cfg.SetMergeHook(mw.onConfigMerged)
 
 	win.Resize(fyne.NewSize(950, 660))
 	win.CenterOnScreen()
@@ -110,185 +258,95 @@ func NewMainWindow(app fyne.App, cfg *config.Config, midiManager *midi.Manager,
 	return mw
 }
 
-// InitializeDevices puts all devices in programmer mode and sends current layout
-func (mw *MainWindow) InitializeDevices() {
-	for _, device := range mw.cfg.Devices {
-		if device.OutPort == "" {
-			continue
-		}
-		deviceType := midi.DeviceType(device.Type)
-		if err := mw.midiManager.ActivateProgrammerMode(device.OutPort, deviceType); err != nil {
-			log.Printf("Failed to activate programmer mode for %s: %v", device.Name, err)
-		} else {
-			log.Printf("Activated programmer mode for %s", device.Name)
-		}
-	}
-	// Send current layout to all devices
-	mw.sendGridToDevices()
-
-	// Start MIDI input listeners
-	mw.StartMIDIListeners()
-}
-
-// StartMIDIListeners begins listening for MIDI input from all configured devices
-func (mw *MainWindow) StartMIDIListeners() {
-	mw.StopMIDIListeners() // Stop any existing listeners
-
-	for _, device := range mw.cfg.Devices {
-		if device.InPort == "" {
-			continue
+func (mw *MainWindow) setupUI() {
+	devicesTab := container.NewTabItem(i18n.T("common.devices"), mw.createDevicesTab())
+	menuEditorTab := container.NewTabItem(i18n.T("common.menu_editor"), mw.createMenuEditorTab())
+	actionsTab := container.NewTabItem(i18n.T("common.actions"), mw.createActionsTab())
+	messageMappingTab := container.NewTabItem(i18n.T("common.message_mapping"), mw.createMessageMappingTab())
+	logsTab := container.NewTabItem(i18n.T("common.logs"), mw.createLogsTab())
+	diagnosticsTab := container.NewTabItem(i18n.T("common.diagnostics"), mw.createDiagnosticsTab())
+	preferencesTab := container.NewTabItem(i18n.T("common.preferences"), mw.createPreferencesTab())
+
+	mw.menuEditorTab = menuEditorTab
+	mw.actionsTab = actionsTab
+	mw.mappingTab = messageMappingTab
+	mw.diagnosticsTab = diagnosticsTab
+	mw.preferencesTab = preferencesTab
+
+	mw.tabs = container.NewAppTabs(devicesTab, menuEditorTab, actionsTab, messageMappingTab, logsTab, diagnosticsTab, preferencesTab)
+	mw.tabs.SetTabLocation(container.TabLocationTop)
+	mw.tabs.OnSelected = func(tab *container.TabItem) {
+		if tab == actionsTab {
+			mw.refreshUsedBy() // Assignments may have changed in the other tabs
 		}
-
-		deviceType := midi.DeviceType(device.Type)
-		menuName := device.MainMenu
-
-		var stop func()
-		var err error
-
-		if device.Type == config.DeviceTypeGeneric {
-			// Generic devices use message mapping instead of pad layout
-			stop, err = mw.midiManager.StartGenericListening(device.InPort, func(portName, msgType string, channel, number, value int) {
-				mw.handleGenericMIDIMessage(portName, msgType, channel, number, value)
-			})
-		} else {
-			// Launchpad devices use pad layout
-			stop, err = mw.midiManager.StartListening(device.InPort, deviceType, func(portName string, row, col int, isNoteOn bool) {
-				mw.handlePadPress(menuName, row, col, isNoteOn)
-			})
+		if tab == diagnosticsTab {
+			mw.refreshDiagnostics()
 		}
-
-		if err != nil {
-			log.Printf("Failed to start listener for %s: %v", device.Name, err)
-			continue
+		if tab == menuEditorTab {
+			mw.applyPadSize() // The window may have been resized since
 		}
-
-		if stop != nil {
-			mw.midiStopFuncs = append(mw.midiStopFuncs, stop)
-			log.Printf("Started listening on %s", device.InPort)
+		if tab == preferencesTab {
+			mw.refreshFilesInfo() // Other tabs may have saved since
 		}
 	}
-}
 
-// StopMIDIListeners stops all MIDI input listeners
-func (mw *MainWindow) StopMIDIListeners() {
-	for _, stop := range mw.midiStopFuncs {
-		if stop != nil {
-			stop()
-		}
-	}
-	mw.midiStopFuncs = nil
+	mw.window.SetContent(container.NewBorder(mw.createSafeModeBanner(), nil, nil, nil, mw.tabs))
 }
 
-// handlePadPress sends pressed/unpressed color to all devices with the same menu
-func (mw *MainWindow) handlePadPress(menuName string, row, col int, isNoteOn bool) {
-	if menuName == "" {
-		return
-	}
-
-	// Find the menu
-	var menu *config.MenuLayout
-	for i := range mw.cfg.Menus {
-		if mw.cfg.Menus[i].Name == menuName {
-			menu = &mw.cfg.Menus[i]
-			break
-		}
-	}
-	if menu == nil {
-		return
-	}
-
-	padColor := menu.Colors[row][col]
-
-	// Execute assigned action on Note On (pad pressed)
-	// Execute assigned action on Note On (pad pressed)
-	if isNoteOn && padColor.ActionID != "" {
-		mw.resolveAndRun(padColor.ActionID)
-	}
-
-	// Send to all devices with this menu
-	for _, device := range mw.cfg.Devices {
-		if device.OutPort == "" || device.MainMenu != menuName {
-			continue
-		}
-
-		deviceType := midi.DeviceType(device.Type)
-		var midiColor midi.PadColor
-
-		if isNoteOn {
-			// Use pressed color
-			if deviceType == midi.DeviceTypeClassic {
-				midiColor = midi.PadColor{R: padColor.ClassicPressedR, G: padColor.ClassicPressedG, B: padColor.ClassicPressedB}
-			} else {
-				midiColor = midi.PadColor{R: padColor.PressedR, G: padColor.PressedG, B: padColor.PressedB}
-			}
-		} else {
-			// Restore button color
-			if deviceType == midi.DeviceTypeClassic {
-				midiColor = midi.PadColor{R: padColor.ClassicR, G: padColor.ClassicG, B: padColor.ClassicB}
-			} else {
-				midiColor = midi.PadColor{R: padColor.R, G: padColor.G, B: padColor.B}
-			}
-		}
+// Show displays the window
+func (mw *MainWindow) Show() {
+	mw.deviceList.Refresh()
+	mw.refreshLayoutDropdown()
+	mw.window.Show()
+	mw.applyPadSize()
+}
 
-		if err := mw.midiManager.SetPadColor(device.OutPort, deviceType, row, col, midiColor); err != nil {
-			log.Printf("Failed to set pad color: %v", err)
-		}
-	}
+// Status returns the bus that publishes device and action health
+func (mw *MainWindow) Status() *status.Bus {
+	return mw.engine.Status()
 }
 
-// handleGenericMIDIMessage handles MIDI messages from Generic devices for inter-app communication
-func (mw *MainWindow) handleGenericMIDIMessage(portName, msgType string, channel, number, value int) {
-	// Only trigger on "on" events (velocity/value > 0)
-	if value == 0 {
-		return
-	}
+// Engine returns the device and action runtime behind the window
+func (mw *MainWindow) Engine() *engine.Engine {
+	return mw.engine
+}
 
-	// Find matching message mappings
-	for _, mapping := range mw.cfg.MessageMappings {
-		if mw.mappingMatches(mapping, msgType, channel, number) {
-			mw.resolveAndRun(mapping.ActionID)
-		}
-	}
+// InitializeDevices puts all devices in programmer mode, sends the current layout and starts listening
+func (mw *MainWindow) InitializeDevices() {
+	mw.engine.InitializeDevices()
+	mw.safeModeBanner.Hide() // Initialization got through, so safe mode is over
 }
 
-// mappingMatches checks if a MIDI message matches a mapping
-func (mw *MainWindow) mappingMatches(mapping config.MessageMapping, msgType string, channel, number int) bool {
-	// Check message type
-	if mapping.MessageType != msgType {
-		return false
-	}
+// SetTrayRefresh registers the function that rebuilds the tray's dynamic menus
+func (mw *MainWindow) SetTrayRefresh(refresh func()) {
+	mw.refreshTray = refresh
+}
 
-	// Check channel (-1 means any channel)
-	if mapping.Channel != -1 && mapping.Channel != channel {
-		return false
-	}
+// SetHTTPAPI registers the HTTP API server so preferences can start and stop it
+func (mw *MainWindow) SetHTTPAPI(server *httpapi.Server) {
+	mw.httpAPI = server
+}
 
-	// Check number
-	if mapping.Number != number {
-		return false
+// notifyTray rebuilds the tray menus after something they list has changed
+func (mw *MainWindow) notifyTray() {
+	if mw.refreshTray != nil {
+		mw.refreshTray()
 	}
-
-	return true
 }
 
-func (mw *MainWindow) setupUI() {
-	devicesTab := container.NewTabItem("Devices", mw.createDevicesTab())
-	menuEditorTab := container.NewTabItem("Menu Editor", mw.createMenuEditorTab())
-	actionsTab := container.NewTabItem("Actions", mw.createActionsTab())
-	messageMappingTab := container.NewTabItem("Message Mapping", mw.createMessageMappingTab())
-
-	tabs := container.NewAppTabs(devicesTab, menuEditorTab, actionsTab, messageMappingTab)
-	tabs.SetTabLocation(container.TabLocationTop)
+// FavoriteActions returns the actions and groups marked as favorites
+func (mw *MainWindow) FavoriteActions() []actions.TreeItem {
+	return mw.actionStore.GetFavorites()
+}
 
-	mw.window.SetContent(tabs)
+// Scenes returns the configured scenes
+func (mw *MainWindow) Scenes() []config.Scene {
+	return mw.cfg.Scenes
 }
 
-// Show displays the window
-func (mw *MainWindow) Show() {
-	mw.deviceList.Refresh()
-	mw.layoutDropdown.Options = mw.getLayoutNames()
-	mw.layoutDropdown.SetSelected(mw.getCurrentLayoutName())
-	mw.window.Show()
+// RunAction runs an action or action group by ID without blocking the caller
+func (mw *MainWindow) RunAction(id string) {
+	mw.engine.Run(id)
 }
 
 // Hide hides the window
@@ -0,0 +1,77 @@
+package window
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/midi/miditest"
+)
+
+// newTestWindow builds the main window on the fyne test app, with the config directory
+// in a temporary one. The config starts from defaults with the given layouts, saved so
+// reverting and discarding read them back.
+func newTestWindow(t *testing.T, layouts ...string) *MainWindow {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) > 0 {
+		cfg.Menus = nil
+		for _, name := range layouts {
+			menu := config.NewMenuLayout()
+			menu.Name = name
+			cfg.Menus = append(cfg.Menus, menu)
+		}
+		cfg.CurrentMenuID = cfg.Menus[0].ID
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	mw := NewMainWindow(test.NewTempApp(t), cfg, miditest.NewManager(nil, nil), nil)
+	t.Cleanup(mw.window.Close)
+	return mw
+}
+
+// tapButton taps the button labelled text among the window's dialogs, failing the test
+// if there is none
+func tapButton(t *testing.T, mw *MainWindow, text string) {
+	t.Helper()
+	top := mw.window.Canvas().Overlays().Top()
+	if top == nil {
+		t.Fatalf("no dialog open to tap %q in", text)
+	}
+	if button := findButton(top, text); button != nil {
+		test.Tap(button)
+		return
+	}
+	t.Fatalf("no %q button in the open dialog", text)
+}
+
+// findButton searches an object tree for a button labelled text
+func findButton(obj fyne.CanvasObject, text string) *widget.Button {
+	if button, ok := obj.(*widget.Button); ok && button.Text == text {
+		return button
+	}
+	var children []fyne.CanvasObject
+	switch o := obj.(type) {
+	case *fyne.Container:
+		children = o.Objects
+	case fyne.Widget:
+		children = test.WidgetRenderer(o).Objects()
+	}
+	for _, child := range children {
+		if button := findButton(child, text); button != nil {
+			return button
+		}
+	}
+	return nil
+}
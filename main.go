@@ -4,29 +4,100 @@ package main
 
 import (
 	"flag"
-	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
 
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/lang"
 	"github.com/PixPMusic/gopher-automate/internal/config"
+	"github.com/PixPMusic/gopher-automate/internal/httpapi"
+	"github.com/PixPMusic/gopher-automate/internal/i18n"
+	"github.com/PixPMusic/gopher-automate/internal/logging"
 	"github.com/PixPMusic/gopher-automate/internal/midi"
+	"github.com/PixPMusic/gopher-automate/internal/startup"
 	"github.com/PixPMusic/gopher-automate/internal/tray"
+	"github.com/PixPMusic/gopher-automate/internal/usage"
 	"github.com/PixPMusic/gopher-automate/internal/window"
 )
 
 func main() {
 	// Flag strictly to allow argument, though ignored in this build
 	_ = flag.String("ui", "fyne", "UI mode (ignored in non-native build)")
+	headless := flag.Bool("headless", false, "Run without a window or tray (devices, layouts and mappings only)")
+	autostart := flag.Bool(strings.TrimPrefix(startup.AutostartFlag, "--"), false, "Launched at login; exit quietly if already running")
 	flag.Parse()
 
+	// Subcommands talk to the running instance and exit
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
+
+	// Only one instance may own the MIDI ports and config; a second launch
+	// brings the first one's window forward instead
+	server, first := claimInstance(!*headless && !*autostart)
+	if !first {
+		slog.Info("GopherAutomate is already running")
+		return
+	}
+
+	// Log to a file as well, since a tray app started at login has no visible stdout
+	closeLog, err := logging.Setup()
+	if err != nil {
+		slog.Warn("Logging to stderr only", "err", err)
+	}
+	defer closeLog()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		slog.Error("Failed to load config", "err", err)
+		os.Exit(1)
+	}
+	logging.SetLevel(cfg.LogLevel)
+	midi.SetTraceTraffic(cfg.LogMIDITraffic)
+	usage.SetEnabled(!cfg.DisableUsageStats)
+	stopUsage := usage.Start()
+	defer stopUsage()
+	for _, ref := range cfg.OrphanedActionReferences() {
+		if ref.MenuID != "" {
+			slog.Warn("Pad is bound to a missing action", "layout", ref.MenuName, "area", ref.Area, "row", ref.Row, "col", ref.Col, "action", ref.ActionID)
+		} else {
+			slog.Warn("Message mapping is bound to a missing action", "mapping", ref.MappingName, "action", ref.ActionID)
+		}
 	}
 
 	// Initialize MIDI manager
 	midiManager := midi.NewManager()
-	defer midiManager.Close()
+	defer midiManager.Close() // Also removes the virtual port
+
+	// Other apps receive MIDI actions through the virtual port, so it exists before any run
+	if cfg.VirtualOutPort && midi.VirtualPortsSupported() {
+		if err := midiManager.OpenVirtualOut(midi.VirtualOutName); err != nil {
+			slog.Error("Failed to create virtual MIDI output", "port", midi.VirtualOutName, "err", err)
+		}
+	}
+
+	if *headless {
+		runHeadless(cfg, midiManager, server)
+		return
+	}
+
+	// The Virtual Launchpad's ports exist whenever a device uses them, window open or not
+	if slices.ContainsFunc(cfg.Devices, func(d config.DeviceConfig) bool { return d.Type == config.DeviceTypeSimulated }) {
+		midiManager.OpenSimulator()
+	}
+
+	// Pick the UI language before anything builds strings
+	language := cfg.Language
+	if language == "" {
+		language = lang.SystemLocale().LanguageString()
+	}
+	i18n.SetLanguage(language)
+
+	// The login item can be removed outside the app, so trust the system over the config
+	cfg.OpenAtStartup = startup.IsEnabled()
 
 	// Create Fyne app
 	fyneApp := app.NewWithID("com.pixpmusic.gopherautomate")
@@ -37,27 +108,78 @@ func main() {
 	})
 
 	// Setup system tray
-	tray.Setup(fyneApp, cfg, tray.Callbacks{
+	systemTray := tray.Setup(fyneApp, cfg, tray.Callbacks{
 		OnOpen: func() {
 			mainWindow.Show()
 		},
 		OnQuit: func() {
-			fyneApp.Quit()
+			mainWindow.ConfirmQuit(fyneApp.Quit)
+		},
+		Favorites: func() []tray.FavoriteItem {
+			var items []tray.FavoriteItem
+			for _, fav := range mainWindow.FavoriteActions() {
+				if fav.IsGroup {
+					items = append(items, tray.FavoriteItem{ID: fav.Group.ID, Name: fav.Group.Name, IsGroup: true})
+				} else {
+					items = append(items, tray.FavoriteItem{ID: fav.Action.ID, Name: fav.Action.Name})
+				}
+			}
+			for _, scene := range mainWindow.Scenes() {
+				items = append(items, tray.FavoriteItem{ID: scene.ID, Name: scene.Name, IsScene: true})
+			}
+			return items
+		},
+		OnRunAction: func(id string) {
+			mainWindow.RunAction(id) // Runs asynchronously, so the tray never blocks
+		},
+		OnStartupChanged: func(enabled bool) {
+			if err := mainWindow.SetOpenAtStartup(enabled); err != nil {
+				slog.Error("Failed to change open at startup", "err", err)
+			}
 		},
 	})
+	mainWindow.SetTrayRefresh(systemTray.Refresh)
+	systemTray.WatchStatus(mainWindow.Status())
 
-	// Initialize devices on startup (activate programmer mode and send current layout)
-	mainWindow.InitializeDevices()
+	// Initialize devices on startup (activate programmer mode and send current layout),
+	// unless that crashed last time; then the user gets to fix device settings first
+	if config.DeviceInitInterrupted() {
+		slog.Warn("Last session stopped during device initialization, starting in safe mode")
+		mainWindow.EnterSafeMode()
+	} else {
+		mainWindow.Engine().InitializeDevicesAtStartup()
+	}
+
+	// Accept requests from the command line (gopher-automate run ...)
+	stopControl := serveControl(server, windowControl{Engine: mainWindow.Engine(), mainWindow: mainWindow})
+	defer stopControl()
+
+	// Optional HTTP API for remote triggers, reconfigured from preferences
+	api := httpapi.New(mainWindow.Engine())
+	if err := api.Apply(cfg.HTTPAPI); err != nil {
+		slog.Error("HTTP API unavailable", "err", err)
+	}
+	defer api.Stop()
+	mainWindow.SetHTTPAPI(api)
+
+	// Offer to fix a login item left pointing at an old copy of the app
+	mainWindow.CheckStartupRegistration()
+
+	// Offer to set up any controllers that aren't configured yet
+	mainWindow.OfferDetectedDevices()
 
 	// Show window if first launch, otherwise run in background
 	if !cfg.FirstLaunchCompleted {
 		cfg.FirstLaunchCompleted = true
 		if err := cfg.Save(); err != nil {
-			log.Printf("Failed to save config: %v", err)
+			slog.Error("Failed to save config", "err", err)
 		}
 		mainWindow.Show()
 	}
 
 	// Run the Fyne app (this blocks until app.Quit is called)
 	fyneApp.Run()
+
+	// Give disconnect hooks their time and clear the pads before the MIDI manager closes its ports
+	mainWindow.Engine().Shutdown()
 }